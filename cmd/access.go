@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"gitstuff/internal/access"
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var auditAccessCmd = &cobra.Command{
+	Use:   "access [group]",
+	Short: "Snapshot who has access to each repository and report what changed",
+	Long: `List the members of every repository (or every repository in a group) with
+their permission, compare them with the snapshot taken by the previous run
+and save the new snapshot. Members who became admin and external members who
+were given access are marked with 🚨: outside collaborators on GitHub, and
+members of a GitLab project who are not in its top-level group.
+
+The snapshot is kept in the base directory, or in --snapshot. The first run
+only takes the snapshot; repositories new to it are recorded without being
+compared. The command exits with a non-zero status when any change is marked.
+
+Examples:
+  gitstuff audit access                    # Audit every repository
+  gitstuff audit access platform -j 8      # Audit a group, 8 repositories at a time
+  gitstuff audit access --no-save          # Report without updating the snapshot`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAuditAccess,
+}
+
+func init() {
+	auditCmd.AddCommand(auditAccessCmd)
+	auditAccessCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to query in parallel")
+	auditAccessCmd.Flags().String("provider", "", "Only repositories from the provider with this name or type")
+	auditAccessCmd.Flags().String("snapshot", "", "Snapshot file (default: "+access.FileName+" in the base directory)")
+	auditAccessCmd.Flags().Bool("no-save", false, "Compare with the snapshot without updating it")
+	addRepoFilterFlags(auditAccessCmd)
+}
+
+// accessResult is the access audit of a single repository
+type accessResult struct {
+	Repo        *scm.Repository
+	Members     []scm.Member
+	Changes     []access.Change
+	New         bool // Not in the previous snapshot
+	Unsupported bool
+	Err         error
+}
+
+func runAuditAccess(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	provider, _ := cmd.Flags().GetString("provider")
+	noSave, _ := cmd.Flags().GetBool("no-save")
+	snapshotPath := accessSnapshotPath(cmd, cfg)
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+	snapshot, err := access.Load(snapshotPath)
+	if err != nil {
+		return err
+	}
+	first := snapshot.TakenAt.IsZero()
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+	ctx := commandContext(cmd)
+	var results []accessResult
+	var tasks []runner.Task
+	for _, collected := range collectProviderRepositories(ctx, selected, groupPath, filter) {
+		lister, _ := scm.Unwrap(collected.client).(scm.MemberLister)
+		for _, repo := range collected.repos {
+			i := len(results)
+			results = append(results, accessResult{Repo: repo, Unsupported: lister == nil})
+			if lister == nil {
+				continue
+			}
+			tasks = append(tasks, func(w io.Writer) error {
+				results[i].Members, results[i].Err = lister.ListMembers(ctx, repo)
+				return results[i].Err
+			})
+		}
+	}
+	if len(results) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+	runner.New(jobs).Run(tasks, io.Discard)
+	verbosity.DebugTiming(start, "Listed the members of %d repositories", len(tasks))
+
+	for i := range results {
+		result := &results[i]
+		if result.Unsupported || result.Err != nil {
+			continue
+		}
+		previous, known := snapshot.Members(result.Repo)
+		if known {
+			result.Changes = access.Diff(previous, result.Members)
+		} else {
+			result.New = !first
+		}
+		snapshot.Record(result.Repo, result.Members)
+	}
+
+	alarming, failed := displayAccessResults(stdout, results, snapshot.TakenAt, first)
+	if !noSave {
+		snapshot.TakenAt = time.Now()
+		if err := snapshot.Save(snapshotPath); err != nil {
+			return err
+		}
+		if first {
+			fmt.Fprintln(stdout, i18n.T("access.first_snapshot", snapshotPath))
+		}
+	}
+
+	switch {
+	case failed > 0:
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to list the members of %d repositories", failed)
+	case alarming > 0:
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d access changes need review", alarming)
+	}
+	return nil
+}
+
+// accessSnapshotPath returns the snapshot file from --snapshot, or the one
+// in the base directory
+func accessSnapshotPath(cmd *cobra.Command, cfg *config.Config) string {
+	if path, _ := cmd.Flags().GetString("snapshot"); path != "" {
+		return expandHome(path)
+	}
+	return filepath.Join(cfg.Local.BaseDir, access.FileName)
+}
+
+// displayAccessResults lists the repositories whose members changed and
+// returns the number of changes marked for review and of failed
+// repositories
+func displayAccessResults(w io.Writer, results []accessResult, takenAt time.Time, first bool) (alarming, failed int) {
+	if first {
+		fmt.Fprintf(w, "%s\n\n", i18n.T("access.header_first", len(results)))
+	} else {
+		fmt.Fprintf(w, "%s\n\n", i18n.T("access.header", len(results), takenAt.Local().Format("2006-01-02 15:04")))
+	}
+
+	changed, added, unsupported := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Unsupported:
+			unsupported++
+			continue
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(w, "❌ %s [%s] - %v\n", result.Repo.FullPath, result.Repo.Provider, redact.Error(result.Err))
+			continue
+		case result.New:
+			added++
+		}
+		if len(result.Changes) == 0 {
+			continue
+		}
+
+		changed++
+		fmt.Fprintf(w, "🔐 %s [%s]\n", result.Repo.FullPath, result.Repo.Provider)
+		for _, change := range result.Changes {
+			line := accessChangeLine(change)
+			switch {
+			case change.Alarming():
+				alarming++
+				fmt.Fprintf(w, "   🚨 %s\n", line)
+			case change.Added():
+				fmt.Fprintf(w, "   ➕ %s\n", line)
+			case change.Removed():
+				fmt.Fprintf(w, "   ➖ %s\n", line)
+			default:
+				fmt.Fprintf(w, "   🔄 %s\n", line)
+			}
+		}
+	}
+
+	if !first {
+		if changed == 0 {
+			fmt.Fprintln(w, i18n.T("access.unchanged"))
+		}
+		fmt.Fprintf(w, "\n%s\n", i18n.T("access.summary", changed, alarming, added, unsupported, failed))
+	}
+	return alarming, failed
+}
+
+func accessChangeLine(change access.Change) string {
+	var line string
+	switch {
+	case change.Added():
+		line = i18n.T("access.added", change.Username, change.To)
+	case change.Removed():
+		line = i18n.T("access.removed", change.Username, change.From)
+	default:
+		line = i18n.T("access.changed", change.Username, change.From, change.To)
+	}
+	if change.External {
+		line += " " + i18n.T("access.external")
+	}
+	return line
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/access"
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+// mockMemberClient lists the members of each repository by full path
+type mockMemberClient struct {
+	mockSCMClient
+	members map[string][]scm.Member
+}
+
+func (m *mockMemberClient) ListMembers(ctx context.Context, repo *scm.Repository) ([]scm.Member, error) {
+	return m.members[repo.FullPath], nil
+}
+
+func TestCommand_AuditAccess(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+	repo := func(id, path string) *scm.Repository {
+		return &scm.Repository{ID: id, FullPath: path, Provider: "gitlab", WebURL: "https://gitlab.example.com/" + path}
+	}
+	client := &mockMemberClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{repo("1", "team/api"), repo("2", "team/web")}},
+		members: map[string][]scm.Member{
+			"team/api": {{Username: "lead", Permission: "admin"}, {Username: "dev", Permission: "write"}, {Username: "leaver", Permission: "read"}},
+			"team/web": {{Username: "lead", Permission: "admin"}},
+		},
+	}
+	clients := map[string]scm.Client{"work": client}
+
+	out, err := runCommand(t, cfg, clients, "audit", "access")
+	if err != nil {
+		t.Fatalf("audit access failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "first access snapshot of 2 repositories") || !strings.Contains(out, "Saved the access snapshot to "+filepath.Join(cfg.Local.BaseDir, access.FileName)) {
+		t.Errorf("Unexpected first run output:\n%s", out)
+	}
+
+	client.members["team/api"] = []scm.Member{
+		{Username: "lead", Permission: "admin"},
+		{Username: "dev", Permission: "admin"},
+		{Username: "contractor", Permission: "read", External: true},
+	}
+	client.repos = append(client.repos, repo("3", "team/docs"))
+	out, err = runCommand(t, cfg, clients, "audit", "access", "--no-save")
+	if err == nil || !strings.Contains(err.Error(), "2 access changes need review") {
+		t.Errorf("Expected the alarming changes to fail the audit, got %v", err)
+	}
+	for _, want := range []string{
+		"🔐 team/api [gitlab]",
+		"   🚨 contractor: added as read (external)",
+		"   🚨 dev: write → admin",
+		"   ➖ leaver: removed (was read)",
+		"1 repositories changed, 2 changes need review, 1 new repositories, 0 unsupported, 0 failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "team/web") {
+		t.Errorf("Expected unchanged repositories to be left out, got:\n%s", out)
+	}
+
+	// --no-save kept the old snapshot, so saving now reports the same
+	// changes, and the next run none
+	snapshot := filepath.Join(tempDir, "access.json")
+	if _, err := runCommand(t, cfg, clients, "audit", "access", "--no-save=false", "--snapshot", snapshot); err != nil {
+		t.Fatalf("audit access failed: %v", err)
+	}
+	if _, err := os.Stat(snapshot); err != nil {
+		t.Errorf("Expected the snapshot to be written to --snapshot: %v", err)
+	}
+	if _, err := runCommand(t, cfg, clients, "audit", "access", "--snapshot", ""); err == nil {
+		t.Error("Expected the base directory snapshot to still report the changes")
+	}
+	out, err = runCommand(t, cfg, clients, "audit", "access")
+	if err != nil || !strings.Contains(out, "No access changes") {
+		t.Errorf("Expected no changes once saved, got %v:\n%s", err, out)
+	}
+
+	plain := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: client.repos}}
+	out, err = runCommand(t, cfg, plain, "audit", "access")
+	if err != nil || !strings.Contains(out, "3 unsupported") {
+		t.Errorf("Expected providers without member listing to be counted, got %v:\n%s", err, out)
+	}
+}
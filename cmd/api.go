@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var apiCmd = &cobra.Command{
+	Use:   "api",
+	Short: "Answer JSON-RPC requests read from stdin, one per line",
+	Long: `Run as a long-lived process that reads JSON-RPC 2.0 requests from stdin, one
+per line, and writes each response to stdout as a single line of JSON, so
+editors and other tools can drive gitstuff without starting a process per
+query. The config file is read and the provider clients are created once,
+when the process starts. Requests are answered in order; requests without
+an id are notifications and get no response.
+
+Methods:
+  list    {"group": "", "provider": ""}                 Repositories on the providers
+  status  {"path": ""}                                  Local repositories and their status
+  clone   {"repo": "group/name", "https": false, "update": false}
+                                                        Clone one repository, or update its clone
+
+Example:
+  echo '{"jsonrpc": "2.0", "id": 1, "method": "list", "params": {"group": "backend"}}' | gitstuff api`,
+	Args: cobra.NoArgs,
+	RunE: runAPI,
+}
+
+func init() {
+	rootCmd.AddCommand(apiCmd)
+	addRepoFilterFlags(apiCmd)
+}
+
+// Error codes defined by JSON-RPC 2.0, plus apiFailed for methods that fail
+const (
+	apiParseError     = -32700
+	apiInvalidRequest = -32600
+	apiMethodNotFound = -32601
+	apiInvalidParams  = -32602
+	apiFailed         = -32000
+)
+
+// apiMaxRequest bounds the size of one request line
+const apiMaxRequest = 1 << 20
+
+type apiRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type apiResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *apiError       `json:"error,omitempty"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// apiServer answers requests with the config, clients and filter it was
+// created with
+type apiServer struct {
+	cfg     *config.Config
+	clients []scm.Client
+	filter  repoFilter
+	pull    git.PullOptions
+}
+
+func runAPI(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	pull, err := pullStrategyFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	server := &apiServer{cfg: cfg, clients: clients, filter: filter, pull: pull}
+	return server.serve(commandContext(cmd), cmd.InOrStdin(), stdout)
+}
+
+// serve answers the requests read from in until it ends or ctx is done
+func (s *apiServer) serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), apiMaxRequest)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if response := s.handle(ctx, line); response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read requests: %w", err)
+	}
+	return nil
+}
+
+// handle answers a single request line, returning nil for notifications
+func (s *apiServer) handle(ctx context.Context, line []byte) *apiResponse {
+	var request apiRequest
+	if err := json.Unmarshal(line, &request); err != nil {
+		return &apiResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &apiError{Code: apiParseError, Message: err.Error()}}
+	}
+	response := &apiResponse{JSONRPC: "2.0", ID: request.ID}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		response.Error = &apiError{Code: apiInvalidRequest, Message: "expected a JSON-RPC 2.0 request with a method"}
+		if response.ID == nil {
+			response.ID = json.RawMessage("null")
+		}
+		return response
+	}
+
+	verbosity.Debug("API request: %s", request.Method)
+	result, err := s.call(ctx, request.Method, request.Params)
+	if request.ID == nil {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *apiError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &apiError{Code: apiFailed, Message: redact.Error(err).Error()}
+		}
+		response.Error = rpcErr
+		return response
+	}
+	response.Result = result
+	return response
+}
+
+func (s *apiServer) call(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "list":
+		var p struct {
+			Group    string `json:"group"`
+			Provider string `json:"provider"`
+		}
+		if err := decodeAPIParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.list(ctx, p.Group, p.Provider)
+	case "status":
+		var p struct {
+			Path string `json:"path"`
+		}
+		if err := decodeAPIParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.status(p.Path)
+	case "clone":
+		var p struct {
+			Repo   string `json:"repo"`
+			HTTPS  bool   `json:"https"`
+			Update bool   `json:"update"`
+		}
+		if err := decodeAPIParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Repo == "" {
+			return nil, &apiError{Code: apiInvalidParams, Message: "missing repo"}
+		}
+		return s.clone(ctx, p.Repo, !p.HTTPS, p.Update)
+	default:
+		return nil, &apiError{Code: apiMethodNotFound, Message: fmt.Sprintf("unknown method %q", method)}
+	}
+}
+
+// decodeAPIParams reads the named params of a request, which may be left out
+func decodeAPIParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &apiError{Code: apiInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// apiRepository is a repository as returned by the list method and by
+// list --json
+type apiRepository struct {
+	Provider      string     `json:"provider"`
+	FullPath      string     `json:"full_path"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	DefaultBranch string     `json:"default_branch,omitempty"`
+	WebURL        string     `json:"web_url"`
+	CloneURL      string     `json:"clone_url"`
+	SSHCloneURL   string     `json:"ssh_clone_url"`
+	Archived      bool       `json:"archived,omitempty"`
+	Topics        []string   `json:"topics,omitempty"`
+	Language      string     `json:"language,omitempty"`
+	Size          int64      `json:"size_bytes,omitempty"`
+	Stars         int        `json:"stars"`
+	LastActivity  *time.Time `json:"last_activity,omitempty"`
+	Path          string     `json:"path"`
+	Cloned        bool       `json:"cloned"`
+
+	// Stale is set when the provider was unavailable and the repository
+	// comes from an older cached listing
+	Stale bool `json:"stale,omitempty"`
+}
+
+func newAPIRepository(cfg *config.Config, repo *scm.Repository) apiRepository {
+	localPath := paths.ResolveRepositoryPath(cfg, repo)
+	result := apiRepository{
+		Provider:      repo.Provider,
+		FullPath:      repo.FullPath,
+		Name:          repo.Name,
+		Description:   repo.Description,
+		DefaultBranch: repo.DefaultBranch,
+		WebURL:        repo.WebURL,
+		CloneURL:      repo.CloneURL,
+		SSHCloneURL:   repo.SSHCloneURL,
+		Archived:      repo.Archived,
+		Topics:        repo.Topics,
+		Language:      repo.Language,
+		Size:          repo.Size,
+		Stars:         repo.Stars,
+		Path:          localPath,
+		Cloned:        pathExists(localPath),
+	}
+	if !repo.LastActivity.IsZero() {
+		result.LastActivity = &repo.LastActivity
+	}
+	return result
+}
+
+func (s *apiServer) list(ctx context.Context, groupPath, provider string) ([]apiRepository, error) {
+	selected, err := selectClients(s.cfg, s.clients, provider)
+	if err != nil {
+		return nil, err
+	}
+	repos := make([]apiRepository, 0)
+	fetched, errs := fetchProviderRepositories(ctx, selected, strings.Trim(groupPath, "/"))
+	for i, client := range selected {
+		if errs[i] != nil {
+			return nil, &providerError{provider: client.GetProviderType(), err: errs[i]}
+		}
+		stale := reportStaleListing(io.Discard, client)
+		for _, repo := range s.filter.applyFor(client, fetched[i]) {
+			result := newAPIRepository(s.cfg, repo)
+			result.Stale = stale
+			repos = append(repos, result)
+		}
+	}
+	return repos, nil
+}
+
+// apiStatus is a local repository as returned by the status method
+type apiStatus struct {
+	Path       string `json:"path"`
+	Branch     string `json:"branch,omitempty"`
+	Upstream   string `json:"upstream,omitempty"`
+	Ahead      int    `json:"ahead"`
+	Behind     int    `json:"behind"`
+	HasChanges bool   `json:"has_changes"`
+	Stashes    int    `json:"stashes"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (s *apiServer) status(root string) ([]apiStatus, error) {
+	if root == "" {
+		root = s.cfg.Local.BaseDir
+	}
+	repoPaths, err := git.FindRepositories(expandHome(root))
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]apiStatus, 0, len(repoPaths))
+	for _, local := range collectLocalStatuses(repoPaths, 4) {
+		status := apiStatus{Path: local.Path}
+		if local.Err != nil {
+			status.Error = redact.Error(local.Err).Error()
+		} else {
+			status.Branch = local.Status.CurrentBranch
+			status.Upstream = local.Status.Upstream
+			status.Ahead = local.Status.Ahead
+			status.Behind = local.Status.Behind
+			status.HasChanges = local.Status.HasChanges
+			status.Stashes = local.Status.StashCount
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// apiClone is the result of the clone method. Output holds what clone
+// would have printed.
+type apiClone struct {
+	Provider string `json:"provider"`
+	FullPath string `json:"full_path"`
+	Path     string `json:"path"`
+	Outcome  string `json:"outcome"` // cloned, updated, fetched, skipped, dirty or protected
+	Output   string `json:"output"`
+}
+
+func (s *apiServer) clone(ctx context.Context, repoPath string, useSSH, update bool) (*apiClone, error) {
+	if err := checkWritable("clone repositories"); err != nil {
+		return nil, err
+	}
+	var repo *scm.Repository
+	for _, client := range s.clients {
+		if found, err := findRepositoryByPath(ctx, client, repoPath); err == nil && found != nil {
+			repo = found
+			break
+		}
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("repository '%s' not found in any configured provider", repoPath)
+	}
+
+	var out bytes.Buffer
+	opts, err := configCloneOptions(s.cfg, s.pull, &out)
+	if err != nil {
+		return nil, err
+	}
+	opts.useSSH, opts.protocolSet, opts.update = useSSH, !useSSH, update
+	summary := processRepositories(ctx, []*scm.Repository{repo}, s.cfg, opts, &out)
+	result := &apiClone{Provider: repo.Provider, FullPath: repo.FullPath, Path: paths.ResolveRepositoryPath(s.cfg, repo), Output: redact.String(out.String())}
+	switch {
+	case len(summary.Failures) > 0:
+		return nil, summary.Failures[0].Err
+	case len(summary.Interrupted) > 0:
+		return nil, fmt.Errorf("interrupted")
+	case summary.Cloned > 0:
+		result.Outcome = "cloned"
+	case summary.Updated > 0:
+		result.Outcome = "updated"
+	case summary.Fetched > 0:
+		result.Outcome = "fetched"
+	case len(summary.Dirty) > 0:
+		result.Outcome = "dirty"
+	case len(summary.Protected) > 0:
+		result.Outcome = "protected"
+	default:
+		result.Outcome = "skipped"
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_API(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}}
+
+	requests := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "list", "params": {"provider": "work"}}`,
+		`{"jsonrpc": "2.0", "method": "list"}`,
+		`{"jsonrpc": "2.0", "id": "two", "method": "clone", "params": {"repo": "group/missing", "https": true}}`,
+		``,
+		`{"jsonrpc": "2.0", "id": 3, "method": "status"}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "clone", "params": {"repo": "group/unknown"}}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "clone", "params": {"name": "group/clean"}}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "delete"}`,
+		`not json`,
+	}, "\n")
+	rootCmd.SetIn(strings.NewReader(requests))
+	t.Cleanup(func() { rootCmd.SetIn(nil) })
+
+	out, err := runCommand(t, cfg, clients, "api")
+	if err != nil {
+		t.Fatalf("api failed: %v\n%s", err, out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected a response per request with an id, got:\n%s", out)
+	}
+	responses := make([]struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *apiError       `json:"error"`
+	}, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &responses[i]); err != nil {
+			t.Fatalf("Response %d is not JSON: %v\n%s", i, err, line)
+		}
+	}
+
+	var listed []apiRepository
+	if err := json.Unmarshal(responses[0].Result, &listed); err != nil || len(listed) != 3 {
+		t.Fatalf("Expected 3 repositories, got %v: %s", err, responses[0].Result)
+	}
+	if !listed[0].Cloned || listed[2].Cloned || listed[2].Path != filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing") {
+		t.Errorf("Unexpected repositories: %+v", listed)
+	}
+
+	var cloned apiClone
+	if err := json.Unmarshal(responses[1].Result, &cloned); err != nil || string(responses[1].ID) != `"two"` || cloned.Outcome != "cloned" || !pathExists(cloned.Path) {
+		t.Errorf("Expected group/missing to be cloned, got %v: %s", err, lines[1])
+	}
+
+	var statuses []apiStatus
+	if err := json.Unmarshal(responses[2].Result, &statuses); err != nil || len(statuses) != 3 {
+		t.Fatalf("Expected the status of 3 clones, got %v: %s", err, responses[2].Result)
+	}
+	for _, status := range statuses {
+		if dirty := strings.HasSuffix(status.Path, "dirty"); status.HasChanges != dirty {
+			t.Errorf("Unexpected status: %+v", status)
+		}
+	}
+
+	for i, code := range map[int]int{3: apiFailed, 4: apiInvalidParams, 5: apiMethodNotFound, 6: apiParseError} {
+		if responses[i].Error == nil || responses[i].Error.Code != code {
+			t.Errorf("Expected error %d, got %s", code, lines[i])
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply [group]",
+	Short: "Apply a script or patch to many repositories and open merge requests",
+	Long: `Run a script or apply a patch in every cloned repository (or every repository
+in a group), commit the result to a new branch, push it and open a merge
+request (GitLab) or pull request (GitHub) against the default branch.
+
+Each repository is changed in a temporary worktree started from its default
+branch, so checkouts and uncommitted changes are not touched. Scripts run
+with the worktree as working directory and with GITSTUFF_REPO,
+GITSTUFF_REPO_NAME, GITSTUFF_PROVIDER and GITSTUFF_DEFAULT_BRANCH set.
+Repositories the script or patch leaves unchanged are skipped.
+
+The commit message is a Go template over the repository (for example
+{{.Name}}, {{.FullPath}} or {{.DefaultBranch}}). Its first line is also the
+merge request title and the remaining lines its description.
+
+Examples:
+  gitstuff apply --script ./bump-go.sh --branch bump-go -m "Bump Go to 1.22"
+  gitstuff apply backend-team --patch fix.patch --branch fix-ci -m "Fix CI for {{.Name}}"
+  gitstuff apply --script 'sed -i s/foo/bar/ README.md' --branch foo-bar -m "Rename foo" --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runApply,
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().String("script", "", "Shell command to run in each repository")
+	applyCmd.Flags().String("patch", "", "Patch file to apply to each repository")
+	applyCmd.Flags().String("branch", "", "Branch to commit the changes to")
+	applyCmd.Flags().StringP("message", "m", "", "Commit message template; the first line is the merge request title")
+	applyCmd.Flags().Bool("dry-run", false, "Show what would change without committing anything")
+	applyCmd.Flags().Bool("no-push", false, "Commit the branch locally without pushing it")
+	applyCmd.Flags().Bool("no-mr", false, "Push the branch without opening merge requests")
+	applyCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to process in parallel")
+	applyCmd.MarkFlagsMutuallyExclusive("script", "patch")
+	applyCmd.MarkFlagsOneRequired("script", "patch")
+	_ = applyCmd.MarkFlagRequired("branch")
+	addRepoFilterFlags(applyCmd)
+}
+
+type applyOptions struct {
+	script  string
+	patch   string // absolute path
+	branch  string
+	message *template.Template
+	dryRun  bool
+	push    bool
+	openMR  bool
+}
+
+type applyResult struct {
+	Repo      *scm.Repository
+	NotCloned bool
+	Changed   bool
+	DiffStat  string
+	URL       string
+	Err       error
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	// Even a dry run fetches and checks out worktrees in the clones
+	if err := checkWritable("apply changes to repositories"); err != nil {
+		return err
+	}
+
+	opts, err := applyOptionsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+	providers := collectProviderRepositories(commandContext(cmd), clients, groupPath, filter)
+
+	var repos []*scm.Repository
+	creators := make(map[*scm.Repository]scm.ChangeRequestCreator)
+	for _, provider := range providers {
+		creator, _ := scm.Unwrap(provider.client).(scm.ChangeRequestCreator)
+		for _, repo := range provider.repos {
+			repos = append(repos, repo)
+			if creator != nil {
+				creators[repo] = creator
+			}
+		}
+	}
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("apply.header", opts.branch, len(repos)))
+	results := applyRepositories(commandContext(cmd), cfg, repos, creators, opts, jobs, stdout)
+	verbosity.DebugTiming(start, "Applied changes to %d repositories", len(repos))
+
+	if failed := displayApplySummary(stdout, results, opts); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to apply changes to %d repositories", failed)
+	}
+	return nil
+}
+
+func applyOptionsFromFlags(cmd *cobra.Command) (applyOptions, error) {
+	script, _ := cmd.Flags().GetString("script")
+	patch, _ := cmd.Flags().GetString("patch")
+	branch, _ := cmd.Flags().GetString("branch")
+	message, _ := cmd.Flags().GetString("message")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	noPush, _ := cmd.Flags().GetBool("no-push")
+	noMR, _ := cmd.Flags().GetBool("no-mr")
+
+	if message == "" && !dryRun {
+		return applyOptions{}, fmt.Errorf("a commit message is required (use -m)")
+	}
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(message)
+	if err != nil {
+		return applyOptions{}, fmt.Errorf("invalid message template: %w", err)
+	}
+
+	if patch != "" {
+		patch, err = filepath.Abs(expandHome(patch))
+		if err != nil {
+			return applyOptions{}, err
+		}
+		if _, err := os.Stat(patch); err != nil {
+			return applyOptions{}, fmt.Errorf("failed to read patch: %w", err)
+		}
+	}
+
+	return applyOptions{
+		script:  script,
+		patch:   patch,
+		branch:  branch,
+		message: tmpl,
+		dryRun:  dryRun,
+		push:    !noPush,
+		openMR:  !noPush && !noMR,
+	}, nil
+}
+
+// applyRepositories runs applyRepository for every repository, printing the
+// outcome of each as it finishes
+func applyRepositories(ctx context.Context, cfg *config.Config, repos []*scm.Repository, creators map[*scm.Repository]scm.ChangeRequestCreator, opts applyOptions, jobs int, out io.Writer) []applyResult {
+	results := make([]applyResult, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			results[i] = applyRepository(ctx, cfg, repo, creators[repo], opts)
+			displayApplyResult(w, results[i], opts)
+			return results[i].Err
+		}
+	}
+	runner.New(jobs).Run(tasks, out)
+	return results
+}
+
+func applyRepository(ctx context.Context, cfg *config.Config, repo *scm.Repository, creator scm.ChangeRequestCreator, opts applyOptions) applyResult {
+	result := applyResult{Repo: repo}
+	repoPath := paths.ResolveRepositoryPath(cfg, repo)
+
+	status, err := git.GetRepositoryStatus(repoPath)
+	if err != nil || !status.Exists || !status.IsGitRepo {
+		result.NotCloned = true
+		return result
+	}
+
+	var output bytes.Buffer
+	if err := git.Fetch(repoPath, &output, &output); err != nil {
+		// Offline or no remote: fall back to what is known locally
+		verbosity.WithRepo(repo.FullPath, repo.Provider).Debug("Fetch failed: %v", err)
+	}
+	output.Reset()
+
+	refs := []string{"HEAD"}
+	if repo.DefaultBranch != "" {
+		refs = []string{"origin/" + repo.DefaultBranch, repo.DefaultBranch, "HEAD"}
+	}
+	base := git.ResolveRef(repoPath, refs...)
+	if base == "" {
+		result.Err = fmt.Errorf("no commit to start %s from", opts.branch)
+		return result
+	}
+
+	worktree, err := git.AddWorktree(repoPath, opts.branch, base)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	keepBranch := false
+	defer func() {
+		if err := worktree.Remove(!keepBranch); err != nil {
+			verbosity.WithRepo(repo.FullPath, repo.Provider).Debug("Cleanup failed: %v", err)
+		}
+	}()
+
+	if opts.patch != "" {
+		err = worktree.ApplyPatch(opts.patch, &output, &output)
+	} else {
+		err = runApplyScript(worktree.Path, repo, opts.script, &output)
+	}
+	if err != nil {
+		result.Err = withOutput(err, output.String())
+		return result
+	}
+
+	changed, err := worktree.HasChanges()
+	if err != nil || !changed {
+		result.Err = err
+		return result
+	}
+	result.Changed = true
+
+	if opts.dryRun {
+		result.DiffStat, result.Err = worktree.DiffStat()
+		return result
+	}
+
+	message, err := renderApplyMessage(opts.message, repo)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if err := worktree.CommitAll(message); err != nil {
+		result.Err = err
+		return result
+	}
+	keepBranch = true
+
+	if !opts.push {
+		return result
+	}
+	output.Reset()
+	if err := git.PushBranch(repoPath, opts.branch, &output, &output); err != nil {
+		result.Err = withOutput(err, output.String())
+		return result
+	}
+
+	if opts.openMR && creator != nil {
+		title, description, _ := strings.Cut(message, "\n")
+		result.URL, err = creator.CreateChangeRequest(ctx, repo, scm.ChangeRequest{
+			SourceBranch: opts.branch,
+			TargetBranch: repo.DefaultBranch,
+			Title:        title,
+			Description:  strings.TrimSpace(description),
+		})
+		if err != nil {
+			result.Err = fmt.Errorf("failed to open merge request: %w", err)
+		}
+	}
+	return result
+}
+
+// runApplyScript runs script through the shell in dir
+func runApplyScript(dir string, repo *scm.Repository, script string, output io.Writer) error {
+	cmd := shellCommand(dir, repo, script)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("script failed: %w", err)
+	}
+	return nil
+}
+
+func renderApplyMessage(tmpl *template.Template, repo *scm.Repository) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, repo); err != nil {
+		return "", fmt.Errorf("failed to render commit message: %w", err)
+	}
+	message := strings.TrimSpace(b.String())
+	if message == "" {
+		return "", fmt.Errorf("commit message is empty")
+	}
+	return message, nil
+}
+
+// withOutput appends the last lines of a command's output to err
+func withOutput(err error, output string) error {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return err
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > 5 {
+		lines = lines[len(lines)-5:]
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(lines, "\n"))
+}
+
+func displayApplyResult(w io.Writer, result applyResult, opts applyOptions) {
+	switch {
+	case result.Err != nil:
+		fmt.Fprintf(w, "❌ %s - %s\n", result.Repo.FullPath, i18n.T("apply.failed", redact.Error(result.Err)))
+	case result.NotCloned:
+		verbosity.WithRepo(result.Repo.FullPath, result.Repo.Provider).Info("Skipping: not cloned")
+	case !result.Changed:
+		verbosity.WithRepo(result.Repo.FullPath, result.Repo.Provider).Info("No changes")
+	case opts.dryRun:
+		fmt.Fprintf(w, "📝 %s - %s\n", result.Repo.FullPath, i18n.T("apply.would_change"))
+		for _, line := range strings.Split(result.DiffStat, "\n") {
+			fmt.Fprintf(w, "   %s\n", line)
+		}
+	case result.URL != "":
+		fmt.Fprintf(w, "✅ %s - %s\n", result.Repo.FullPath, i18n.T("apply.opened", result.URL))
+	case opts.push:
+		fmt.Fprintf(w, "✅ %s - %s\n", result.Repo.FullPath, i18n.T("apply.pushed", opts.branch))
+	default:
+		fmt.Fprintf(w, "✅ %s - %s\n", result.Repo.FullPath, i18n.T("apply.committed", opts.branch))
+	}
+}
+
+// displayApplySummary prints the totals and returns how many repositories
+// failed
+func displayApplySummary(w io.Writer, results []applyResult, opts applyOptions) int {
+	changed, unchanged, notCloned, failed := 0, 0, 0, 0
+	var urls []string
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.NotCloned:
+			notCloned++
+		case result.Changed:
+			changed++
+			if result.URL != "" {
+				urls = append(urls, result.URL)
+			}
+		default:
+			unchanged++
+		}
+	}
+
+	key := "apply.summary"
+	if opts.dryRun {
+		key = "apply.summary_dry_run"
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T(key, changed, unchanged, notCloned, failed))
+	if len(urls) > 0 {
+		fmt.Fprintln(w, i18n.T("apply.requests_header", len(urls)))
+		for _, url := range urls {
+			fmt.Fprintf(w, "   %s\n", url)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+type fakeChangeRequestCreator struct {
+	requests []scm.ChangeRequest
+}
+
+func (f *fakeChangeRequestCreator) CreateChangeRequest(ctx context.Context, repo *scm.Repository, request scm.ChangeRequest) (string, error) {
+	f.requests = append(f.requests, request)
+	return "https://example.com/" + repo.FullPath + "/-/merge_requests/1", nil
+}
+
+func TestApplyRepository(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	cfg, repos := setupSyncFixture(t)
+	clean, dirty, missing := repos[0], repos[1], repos[2]
+	cleanPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	status, err := git.GetRepositoryStatus(cleanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clean.DefaultBranch = status.CurrentBranch
+	dirty.DefaultBranch = status.CurrentBranch
+
+	opts := applyOptions{
+		script:  `echo "$GITSTUFF_REPO_NAME" > NAME`,
+		branch:  "add-name",
+		message: template.Must(template.New("message").Parse("Add NAME to {{.Name}}\n\nGenerated file")),
+		dryRun:  true,
+	}
+
+	result := applyRepository(context.Background(), cfg, clean, nil, opts)
+	if result.Err != nil || !result.Changed || !strings.Contains(result.DiffStat, "NAME") {
+		t.Fatalf("Unexpected dry run result: %+v", result)
+	}
+	if ref := git.ResolveRef(cleanPath, "add-name"); ref != "" {
+		t.Error("Expected the dry run to delete its branch")
+	}
+
+	if result := applyRepository(context.Background(), cfg, missing, nil, opts); !result.NotCloned {
+		t.Errorf("Expected missing repository to be skipped, got %+v", result)
+	}
+
+	unchanged := opts
+	unchanged.script = "true"
+	if result := applyRepository(context.Background(), cfg, clean, nil, unchanged); result.Err != nil || result.Changed {
+		t.Errorf("Expected no changes, got %+v", result)
+	}
+
+	failing := opts
+	failing.script = "echo broken >&2; exit 3"
+	if result := applyRepository(context.Background(), cfg, clean, nil, failing); result.Err == nil || !strings.Contains(result.Err.Error(), "broken") {
+		t.Errorf("Expected the script failure with its output, got %+v", result)
+	}
+
+	opts.dryRun = false
+	opts.push = true
+	opts.openMR = true
+	creator := &fakeChangeRequestCreator{}
+	result = applyRepository(context.Background(), cfg, dirty, creator, opts)
+	if result.Err != nil || result.URL == "" {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if len(creator.requests) != 1 {
+		t.Fatalf("Expected one merge request, got %d", len(creator.requests))
+	}
+	request := creator.requests[0]
+	if request.SourceBranch != "add-name" || request.TargetBranch != dirty.DefaultBranch || request.Title != "Add NAME to dirty" || request.Description != "Generated file" {
+		t.Errorf("Unexpected merge request: %+v", request)
+	}
+
+	out, err := exec.Command("git", "-C", dirty.CloneURL, "show", "add-name:NAME").Output()
+	if err != nil || string(out) != "dirty\n" {
+		t.Errorf("Expected the branch to be pushed, got %q, %v", out, err)
+	}
+	status, err = git.GetRepositoryStatus(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty"))
+	if err != nil || !status.HasChanges || status.CurrentBranch != dirty.DefaultBranch {
+		t.Errorf("Expected the checkout to be untouched, got %+v, %v", status, err)
+	}
+}
+
+func TestRenderApplyMessage(t *testing.T) {
+	repo := &scm.Repository{Name: "api", FullPath: "team/api"}
+
+	tmpl := template.Must(template.New("message").Parse("Update {{.FullPath}}\n"))
+	if message, err := renderApplyMessage(tmpl, repo); err != nil || message != "Update team/api" {
+		t.Errorf("renderApplyMessage() = %q, %v", message, err)
+	}
+
+	empty := template.Must(template.New("message").Parse("  "))
+	if _, err := renderApplyMessage(empty, repo); err == nil {
+		t.Error("Expected an error for an empty message")
+	}
+}
+
+func TestDisplayApplySummary(t *testing.T) {
+	results := []applyResult{
+		{Repo: &scm.Repository{FullPath: "a"}, Changed: true, URL: "https://example.com/a/pull/1"},
+		{Repo: &scm.Repository{FullPath: "b"}},
+		{Repo: &scm.Repository{FullPath: "c"}, NotCloned: true},
+		{Repo: &scm.Repository{FullPath: "d"}, Err: errors.New("push rejected")},
+	}
+
+	var buf bytes.Buffer
+	failed := displayApplySummary(&buf, results, applyOptions{push: true})
+	if failed != 1 {
+		t.Errorf("Expected 1 failure, got %d", failed)
+	}
+	output := buf.String()
+	for _, want := range []string{"1 changed, 1 unchanged, 1 not cloned, 1 failed", "https://example.com/a/pull/1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitstuff/internal/cache"
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <repo>...",
+	Short: "Archive repositories on their provider",
+	Long: `Archive repositories through the provider API, making them read-only for
+everyone. Repositories are given by their full path, as shown by list.
+
+With --local, the clone is also moved out of the base directory into the
+archive directory (local.archive_dir, by default the base directory with an
+"-archived" suffix), keeping its path, so status and sync stop showing it.
+
+Examples:
+  gitstuff archive team/legacy-api
+  gitstuff archive team/old-site team/old-docs --local --yes`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetArchived(cmd, args, true)
+	},
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive <repo>...",
+	Short: "Unarchive repositories on their provider",
+	Long: `Unarchive repositories through the provider API so they can be changed
+again. With --local, clones moved away by 'archive --local' are moved back
+into the base directory.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetArchived(cmd, args, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd, unarchiveCmd)
+	for _, cmd := range []*cobra.Command{archiveCmd, unarchiveCmd} {
+		cmd.Flags().String("provider", "", "Only look for the repositories on the provider with this name or type")
+		cmd.Flags().Bool("local", false, "Also move the local clone to or from the archive directory")
+	}
+	archiveCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+}
+
+// archiveTarget is a repository to archive or unarchive with its provider
+type archiveTarget struct {
+	repo     *scm.Repository
+	client   scm.Client
+	archiver scm.RepositoryArchiver
+}
+
+func runSetArchived(cmd *cobra.Command, args []string, archived bool) error {
+	provider, _ := cmd.Flags().GetString("provider")
+	local, _ := cmd.Flags().GetBool("local")
+	verb := "unarchive"
+	if archived {
+		verb = "archive"
+	}
+	if err := checkWritable(verb + " repositories"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+
+	// Look every repository up first, so a typo does not leave the others
+	// half done
+	ctx := commandContext(cmd)
+	targets := make([]archiveTarget, 0, len(args))
+	for _, repoPath := range args {
+		target, err := findArchiveTarget(ctx, selected, strings.Trim(repoPath, "/"))
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+
+	if archived {
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes && !confirm(os.Stdin, stdout, i18n.T("archive.confirm", len(targets))) {
+			fmt.Fprintln(stdout, i18n.T("prune.cancelled"))
+			return nil
+		}
+	}
+
+	failed := 0
+	changed := make(map[scm.Client]bool)
+	for _, target := range targets {
+		repo := target.repo
+		if err := target.archiver.SetArchived(ctx, repo, archived); err != nil {
+			fmt.Fprintf(stdout, "❌ [%s] %s: %v\n", repo.Provider, repo.FullPath, redact.Error(err))
+			failed++
+			continue
+		}
+		changed[target.client] = true
+		if archived {
+			fmt.Fprintf(stdout, "🗄️  %s\n", i18n.T("archive.archived", repo.FullPath, repo.Provider))
+		} else {
+			fmt.Fprintf(stdout, "📂 %s\n", i18n.T("archive.unarchived", repo.FullPath, repo.Provider))
+		}
+		if !local {
+			continue
+		}
+
+		moved, err := moveArchivedClone(cfg, repo, archived)
+		switch {
+		case err != nil:
+			fmt.Fprintf(stdout, "   ❌ %v\n", err)
+			failed++
+		case moved != "":
+			fmt.Fprintf(stdout, "   %s\n", i18n.T("archive.moved", moved))
+		default:
+			fmt.Fprintf(stdout, "   %s\n", i18n.T("archive.no_clone"))
+		}
+	}
+	refreshCachedListings(ctx, changed)
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to %s %d of %d repositories", verb, failed, len(targets))
+	}
+	return nil
+}
+
+// findArchiveTarget looks repoPath up on the providers and returns it with
+// the provider that can archive it
+func findArchiveTarget(ctx context.Context, clients []scm.Client, repoPath string) (archiveTarget, error) {
+	for _, client := range clients {
+		repo, err := findRepositoryByPath(ctx, client, repoPath)
+		if err != nil {
+			continue
+		}
+		archiver, ok := scm.Unwrap(client).(scm.RepositoryArchiver)
+		if !ok {
+			return archiveTarget{}, fmt.Errorf("provider type %s does not support archiving repositories", client.GetProviderType())
+		}
+		return archiveTarget{repo: repo, client: client, archiver: archiver}, nil
+	}
+	return archiveTarget{}, fmt.Errorf("repository '%s' not found in any configured provider", repoPath)
+}
+
+// refreshCachedListings refreshes the cached repository listings of the
+// clients, so sync sees the new archived state right away
+func refreshCachedListings(ctx context.Context, clients map[scm.Client]bool) {
+	for client := range clients {
+		if cached, ok := client.(*cache.Client); ok {
+			if _, err := cached.Refresh(ctx); err != nil {
+				verbosity.Debug("Failed to refresh cached %s repositories: %v", client.GetProviderType(), err)
+			}
+		}
+	}
+}
+
+// archiveDir returns where archived clones are kept
+func archiveDir(cfg *config.Config) string {
+	if cfg.Local.ArchiveDir != "" {
+		return expandHome(cfg.Local.ArchiveDir)
+	}
+	return filepath.Clean(cfg.Local.BaseDir) + "-archived"
+}
+
+// moveArchivedClone moves the clone of repo into the archive directory, or
+// back into the base directory when unarchiving, and returns where it went.
+// It returns an empty path when there is no clone to move.
+func moveArchivedClone(cfg *config.Config, repo *scm.Repository, archived bool) (string, error) {
+	clonePath := paths.GetClonePath(cfg, repo)
+	archivedPath := filepath.Join(archiveDir(cfg), repo.Provider, filepath.FromSlash(repo.FullPath))
+	from, to := archivedPath, clonePath
+	if archived {
+		from, to = paths.ResolveRepositoryPath(cfg, repo), archivedPath
+	}
+	if !pathExists(from) {
+		return "", nil
+	}
+	if pathExists(to) {
+		return "", fmt.Errorf("%s already exists", to)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return "", fmt.Errorf("failed to move clone: %w", err)
+	}
+	if archived {
+		removeEmptyParents(filepath.Dir(from), cfg.Local.BaseDir)
+	} else {
+		removeEmptyParents(filepath.Dir(from), archiveDir(cfg))
+	}
+	return to, nil
+}
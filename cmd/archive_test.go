@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+// mockArchiveClient records archived state changes and fails for paths in
+// fail
+type mockArchiveClient struct {
+	mockSCMClient
+	changes []string
+	fail    map[string]bool
+}
+
+func (m *mockArchiveClient) SetArchived(ctx context.Context, repo *scm.Repository, archived bool) error {
+	if m.fail[repo.FullPath] {
+		return errors.New("forbidden")
+	}
+	state := "unarchived"
+	if archived {
+		state = "archived"
+	}
+	m.changes = append(m.changes, repo.FullPath+" "+state)
+	return nil
+}
+
+func TestCommand_Archive(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "src")
+	clonePath := filepath.Join(baseDir, "gitlab", "team", "legacy")
+	if err := os.MkdirAll(filepath.Join(clonePath, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: baseDir},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+	client := &mockArchiveClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+			{Name: "legacy", FullPath: "team/legacy", Provider: "gitlab"},
+			{Name: "locked", FullPath: "team/locked", Provider: "gitlab"},
+		}},
+		fail: map[string]bool{"team/locked": true},
+	}
+	clients := map[string]scm.Client{"work": client}
+
+	out, err := runCommand(t, cfg, clients, "archive", "team/legacy", "--local", "--yes")
+	if err != nil {
+		t.Fatalf("archive failed: %v\n%s", err, out)
+	}
+	archivedPath := filepath.Join(baseDir+"-archived", "gitlab", "team", "legacy")
+	for _, want := range []string{"Archived team/legacy [gitlab]", "Moved the clone to " + archivedPath} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if !pathExists(archivedPath) || pathExists(filepath.Join(baseDir, "gitlab", "team")) {
+		t.Errorf("Expected the clone to move to %s and its empty parents to be removed", archivedPath)
+	}
+
+	out, err = runCommand(t, cfg, clients, "unarchive", "team/legacy", "--local")
+	if err != nil {
+		t.Fatalf("unarchive failed: %v\n%s", err, out)
+	}
+	if !pathExists(clonePath) || pathExists(archivedPath) {
+		t.Errorf("Expected the clone to move back to %s:\n%s", clonePath, out)
+	}
+	if want := "team/legacy archived,team/legacy unarchived"; strings.Join(client.changes, ",") != want {
+		t.Errorf("Unexpected changes %v, want %s", client.changes, want)
+	}
+
+	out, err = runCommand(t, cfg, clients, "unarchive", "team/locked", "--local=false")
+	if err == nil || !strings.Contains(err.Error(), "failed to unarchive 1 of 1 repositories") || !strings.Contains(out, "❌ [gitlab] team/locked: forbidden") {
+		t.Errorf("Expected the provider failure to be reported, got %v\n%s", err, out)
+	}
+	if _, err := runCommand(t, cfg, clients, "unarchive", "team/nope"); err == nil || !strings.Contains(err.Error(), "repository 'team/nope' not found") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
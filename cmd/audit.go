@@ -0,0 +1,331 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit cloned repositories against organisation standards",
+}
+
+var auditFilesCmd = &cobra.Command{
+	Use:   "files [group]",
+	Short: "Check repositories for required files and template drift",
+	Long: `Check that every cloned repository (or every repository in a group) contains
+the files listed under audit.files in the config, and that files with a
+template match it. Line endings are ignored when comparing.
+
+With --fix, a branch containing the missing and drifted files is committed
+in each affected repository, started from the default branch. The commit is
+made in a temporary worktree, so checkouts and uncommitted changes are not
+touched. --push also pushes the branch to origin so a merge request can be
+opened from it.
+
+The command exits with a non-zero status when any repository has drifted.
+
+Examples:
+  gitstuff audit files                     # Audit every repository
+  gitstuff audit files backend-team        # Audit a single group
+  gitstuff audit files --fix --push        # Commit and push fix-up branches`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAuditFiles,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditFilesCmd)
+	auditFilesCmd.Flags().Bool("fix", false, "Commit missing and drifted files to a branch in each affected repository")
+	auditFilesCmd.Flags().String("branch", "gitstuff/audit-files", "Branch name for --fix")
+	auditFilesCmd.Flags().Bool("push", false, "Push fix-up branches to origin (requires --fix)")
+	addRepoFilterFlags(auditFilesCmd)
+}
+
+// auditCheck is a compiled audit.files entry
+type auditCheck struct {
+	path     string
+	template []byte // nil when only presence is checked
+	include  patternSet
+}
+
+type fileState int
+
+const (
+	fileOK fileState = iota
+	fileMissing
+	fileDrifted
+)
+
+type fileDrift struct {
+	Path  string
+	State fileState
+	check auditCheck
+}
+
+type repoAudit struct {
+	Repo     *scm.Repository
+	Path     string
+	Cloned   bool
+	Drift    []fileDrift
+	FixError error
+	Fixed    bool
+}
+
+func runAuditFiles(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	fix, _ := cmd.Flags().GetBool("fix")
+	branch, _ := cmd.Flags().GetString("branch")
+	push, _ := cmd.Flags().GetBool("push")
+	if push && !fix {
+		return fmt.Errorf("--push requires --fix")
+	}
+	if fix {
+		if err := checkWritable("commit fix-up branches"); err != nil {
+			return err
+		}
+	}
+
+	configPath, err := config.FilePath()
+	if err != nil {
+		return err
+	}
+	checks, err := compileAuditChecks(cfg.Audit.Files, filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return fmt.Errorf("no files to audit (add audit.files to %s)", configPath)
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+	repos := collectRepositories(commandContext(cmd), clients, groupPath, filter)
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+
+	var audits []repoAudit
+	for _, repo := range repos {
+		audits = append(audits, auditRepository(cfg, repo, checks))
+	}
+	verbosity.DebugTiming(start, "Audited %d repositories", len(audits))
+
+	if fix {
+		for i := range audits {
+			fixRepository(&audits[i], branch, push)
+		}
+	}
+
+	drifted := displayAudit(stdout, audits, branch)
+	if fix {
+		failed := 0
+		for _, audit := range audits {
+			if audit.FixError != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("failed to create fix-up branches in %d repositories", failed)
+		}
+		return nil
+	}
+	if drifted > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d repositories do not match the audit templates", drifted)
+	}
+	return nil
+}
+
+// compileAuditChecks reads the template of each audit file; relative
+// templates are resolved against baseDir
+func compileAuditChecks(files []config.AuditFile, baseDir string) ([]auditCheck, error) {
+	checks := make([]auditCheck, 0, len(files))
+	for _, file := range files {
+		if file.Path == "" {
+			return nil, fmt.Errorf("audit file: path is required")
+		}
+		check := auditCheck{path: filepath.ToSlash(filepath.Clean(file.Path))}
+
+		include, err := compilePatternSet(file.Include, nil)
+		if err != nil {
+			return nil, fmt.Errorf("audit file %s: %w", file.Path, err)
+		}
+		check.include = include
+
+		if file.Template != "" {
+			template := expandHome(file.Template)
+			if !filepath.IsAbs(template) {
+				template = filepath.Join(baseDir, template)
+			}
+			content, err := os.ReadFile(template)
+			if err != nil {
+				return nil, fmt.Errorf("audit file %s: failed to read template: %w", file.Path, err)
+			}
+			check.template = content
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func auditRepository(cfg *config.Config, repo *scm.Repository, checks []auditCheck) repoAudit {
+	repoPath := paths.ResolveRepositoryPath(cfg, repo)
+	audit := repoAudit{Repo: repo, Path: repoPath}
+
+	status, err := git.GetRepositoryStatus(repoPath)
+	if err != nil || !status.Exists || !status.IsGitRepo {
+		return audit
+	}
+	audit.Cloned = true
+
+	for _, check := range checks {
+		if !check.include.matches(repo.FullPath) {
+			continue
+		}
+		state := auditFile(filepath.Join(repoPath, filepath.FromSlash(check.path)), check.template)
+		if state != fileOK {
+			audit.Drift = append(audit.Drift, fileDrift{Path: check.path, State: state, check: check})
+		}
+	}
+	return audit
+}
+
+func auditFile(path string, template []byte) fileState {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fileMissing
+	}
+	if template != nil && contentHash(content) != contentHash(template) {
+		return fileDrifted
+	}
+	return fileOK
+}
+
+// contentHash hashes content with line endings normalised, so checkouts
+// with core.autocrlf do not show as drift
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// fixRepository commits the template of every fixable drifted file to
+// branch, starting from the repository's default branch
+func fixRepository(audit *repoAudit, branch string, push bool) {
+	files := make(map[string][]byte)
+	for _, drift := range audit.Drift {
+		if drift.check.template != nil {
+			files[drift.Path] = drift.check.template
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	base := git.ResolveRef(audit.Path, "origin/"+audit.Repo.DefaultBranch, audit.Repo.DefaultBranch, "HEAD")
+	if base == "" {
+		audit.FixError = fmt.Errorf("no commit to start %s from", branch)
+		return
+	}
+
+	message := "Update files to match organisation templates"
+	if err := git.CommitFilesOnBranch(audit.Path, branch, base, files, message); err != nil {
+		audit.FixError = err
+		return
+	}
+	if push {
+		var output bytes.Buffer
+		if err := git.PushBranch(audit.Path, branch, &output, &output); err != nil {
+			audit.FixError = fmt.Errorf("%w: %s", err, strings.TrimSpace(output.String()))
+			return
+		}
+	}
+	audit.Fixed = true
+}
+
+// displayAudit reports each drifted repository and returns how many there
+// are
+func displayAudit(w io.Writer, audits []repoAudit, branch string) int {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("audit.header", len(audits)))
+
+	drifted, notCloned := 0, 0
+	for _, audit := range audits {
+		if !audit.Cloned {
+			notCloned++
+			continue
+		}
+		if len(audit.Drift) == 0 {
+			continue
+		}
+
+		drifted++
+		fmt.Fprintf(w, "⚠️  %s\n", audit.Repo.FullPath)
+		for _, drift := range audit.Drift {
+			switch drift.State {
+			case fileMissing:
+				fmt.Fprintf(w, "   %s\n", i18n.T("audit.missing", drift.Path))
+			case fileDrifted:
+				fmt.Fprintf(w, "   %s\n", i18n.T("audit.drifted", drift.Path))
+			}
+		}
+		switch {
+		case audit.FixError != nil:
+			fmt.Fprintf(w, "   ❌ %s\n", i18n.T("audit.fix_failed", redact.Error(audit.FixError)))
+		case audit.Fixed:
+			fmt.Fprintf(w, "   🔧 %s\n", i18n.T("audit.fixed", branch))
+		}
+	}
+
+	if drifted == 0 {
+		fmt.Fprintln(w, i18n.T("audit.clean"))
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("audit.summary", len(audits)-notCloned, drifted, notCloned))
+	return drifted
+}
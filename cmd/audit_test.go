@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+)
+
+func TestCompileAuditChecks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "LICENSE"), []byte("MIT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks, err := compileAuditChecks([]config.AuditFile{
+		{Path: "LICENSE", Template: "LICENSE"},
+		{Path: "./SECURITY.md", Include: []string{"team/*"}},
+	}, dir)
+	if err != nil {
+		t.Fatalf("compileAuditChecks failed: %v", err)
+	}
+	if string(checks[0].template) != "MIT\n" {
+		t.Errorf("Expected template relative to the config directory, got %q", checks[0].template)
+	}
+	if checks[1].path != "SECURITY.md" || checks[1].template != nil {
+		t.Errorf("Unexpected presence-only check: %+v", checks[1])
+	}
+	if checks[1].include.matches("other/repo") {
+		t.Error("Expected include pattern to limit the check")
+	}
+
+	for _, files := range [][]config.AuditFile{
+		{{Template: "LICENSE"}},
+		{{Path: "LICENSE", Template: "missing"}},
+		{{Path: "LICENSE", Include: []string{"re:("}}},
+	} {
+		if _, err := compileAuditChecks(files, dir); err == nil {
+			t.Errorf("Expected error for %+v", files)
+		}
+	}
+}
+
+func TestAuditRepository(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cleanPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	if err := os.WriteFile(filepath.Join(cleanPath, "LICENSE"), []byte("MIT\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cleanPath, "CONTRIBUTING.md"), []byte("old rules\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []auditCheck{
+		{path: "LICENSE", template: []byte("MIT\n")},
+		{path: "CONTRIBUTING.md", template: []byte("new rules\n")},
+		{path: "SECURITY.md"},
+	}
+
+	audit := auditRepository(cfg, repos[0], checks)
+	if !audit.Cloned || len(audit.Drift) != 2 {
+		t.Fatalf("Expected 2 drifted files, got %+v", audit)
+	}
+	if audit.Drift[0].Path != "CONTRIBUTING.md" || audit.Drift[0].State != fileDrifted {
+		t.Errorf("Expected CONTRIBUTING.md to have drifted, got %+v", audit.Drift[0])
+	}
+	if audit.Drift[1].Path != "SECURITY.md" || audit.Drift[1].State != fileMissing {
+		t.Errorf("Expected SECURITY.md to be missing, got %+v", audit.Drift[1])
+	}
+
+	missing := auditRepository(cfg, repos[2], checks)
+	if missing.Cloned {
+		t.Errorf("Expected missing repository not to be audited, got %+v", missing)
+	}
+
+	var buf bytes.Buffer
+	drifted := displayAudit(&buf, []repoAudit{audit, missing}, "fix")
+	output := buf.String()
+	if drifted != 1 {
+		t.Errorf("Expected 1 drifted repository, got %d", drifted)
+	}
+	for _, want := range []string{"⚠️  group/clean", "differs from template: CONTRIBUTING.md", "missing: SECURITY.md", "1 repositories audited, 1 with drift, 1 not cloned"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestFixRepository(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	cfg, repos := setupSyncFixture(t)
+	repo := repos[1] // Uncommitted changes must not get in the way
+	repoPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")
+	branch, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse failed: %v", err)
+	}
+	repo.DefaultBranch = strings.TrimSpace(string(branch))
+
+	audit := auditRepository(cfg, repo, []auditCheck{
+		{path: "LICENSE", template: []byte("MIT\n")},
+		{path: "SECURITY.md"},
+	})
+	fixRepository(&audit, "gitstuff/audit-files", true)
+	if audit.FixError != nil || !audit.Fixed {
+		t.Fatalf("Expected fix to succeed, got %+v", audit)
+	}
+
+	out, err := exec.Command("git", "-C", repo.CloneURL, "show", "--name-only", "--format=", "gitstuff/audit-files").Output()
+	if err != nil {
+		t.Fatalf("Expected branch to be pushed to origin: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "LICENSE" {
+		t.Errorf("Expected only the templated file to be committed, got %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "wip.txt")); err != nil {
+		t.Errorf("Expected uncommitted work to be left alone: %v", err)
+	}
+}
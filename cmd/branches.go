@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var branchesCmd = &cobra.Command{
+	Use:   "branches [path]",
+	Short: "List the local branches of all local repositories",
+	Long: `Scan the base directory (or the given path) for git repositories and list
+every local branch with its upstream, the age of its last commit and whether it
+is merged into the default branch.
+
+The default branch is the one origin/HEAD points at, or else main or master.
+Like 'gitstuff status', this only looks at the local filesystem, so run
+'gitstuff sync --fetch-only' first for up-to-date merged states.
+
+Examples:
+  gitstuff branches                      # List all branches
+  gitstuff branches --stale 90d          # Branches without commits for 90 days
+  gitstuff branches --unmerged           # Work that never made it into the default branch
+  gitstuff branches --stale 6w --unmerged ~/src/work`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBranches,
+}
+
+func init() {
+	rootCmd.AddCommand(branchesCmd)
+	branchesCmd.Flags().String("stale", "", "Only branches whose last commit is older than this, e.g. 90d, 6w or 720h")
+	branchesCmd.Flags().Bool("unmerged", false, "Only branches not merged into the default branch")
+	branchesCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to inspect in parallel")
+}
+
+type localRepoBranches struct {
+	Path       string
+	DefaultRef string
+	Branches   []git.Branch
+	Err        error
+}
+
+// branchFilter selects the branches to show; the default branch is never
+// shown while filtering
+type branchFilter struct {
+	staleAfter time.Duration
+	unmerged   bool
+}
+
+func (f branchFilter) active() bool {
+	return f.staleAfter > 0 || f.unmerged
+}
+
+func (f branchFilter) matches(branch git.Branch, isDefault bool, now time.Time) bool {
+	if !f.active() {
+		return true
+	}
+	if isDefault {
+		return false
+	}
+	if f.unmerged && branch.Merged {
+		return false
+	}
+	return f.staleAfter == 0 || now.Sub(branch.LastCommit) >= f.staleAfter
+}
+
+func runBranches(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	var root string
+	if len(args) == 1 {
+		root = args[0]
+	} else {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first or pass a path)", err)
+		}
+		root = cfg.Local.BaseDir
+	}
+
+	stale, _ := cmd.Flags().GetString("stale")
+	staleAfter, err := parseAge(stale)
+	if err != nil {
+		return fmt.Errorf("invalid --stale: %w", err)
+	}
+	unmerged, _ := cmd.Flags().GetBool("unmerged")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	verbosity.Debug("Scanning %s for git repositories", root)
+	repoPaths, err := git.FindRepositories(root)
+	if err != nil {
+		return err
+	}
+	verbosity.DebugTiming(start, "Found %d repositories", len(repoPaths))
+
+	repos := collectLocalBranches(repoPaths, jobs)
+	displayLocalBranches(stdout, root, repos, branchFilter{staleAfter: staleAfter, unmerged: unmerged}, time.Now())
+
+	verbosity.DebugTiming(start, "Branch scan completed")
+	return nil
+}
+
+// parseAge parses a duration that may also be given in days or weeks,
+// e.g. "90d" or "6w". An empty string is zero.
+func parseAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("%q is not a number of days or weeks", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if age < 0 {
+		return 0, fmt.Errorf("%q is negative", value)
+	}
+	return age, nil
+}
+
+func collectLocalBranches(repoPaths []string, jobs int) []localRepoBranches {
+	repos := make([]localRepoBranches, len(repoPaths))
+	tasks := make([]runner.Task, len(repoPaths))
+	for i, repoPath := range repoPaths {
+		tasks[i] = func(w io.Writer) error {
+			defaultRef := git.DefaultBranchRef(repoPath)
+			branches, err := git.Branches(repoPath, defaultRef)
+			repos[i] = localRepoBranches{Path: repoPath, DefaultRef: defaultRef, Branches: branches, Err: err}
+			return err
+		}
+	}
+
+	runner.New(jobs).Run(tasks, io.Discard)
+	return repos
+}
+
+// defaultBranchName returns the local name of the default branch ref, e.g.
+// "main" for "origin/main"
+func defaultBranchName(defaultRef string) string {
+	return strings.TrimPrefix(defaultRef, "origin/")
+}
+
+func displayLocalBranches(w io.Writer, root string, repos []localRepoBranches, filter branchFilter, now time.Time) {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("status.found_local", len(repos), root))
+
+	total, withBranches, unmerged := 0, 0, 0
+	for _, repo := range repos {
+		name := repo.Path
+		if rel, err := filepath.Rel(root, repo.Path); err == nil {
+			name = rel
+		}
+
+		if repo.Err != nil {
+			fmt.Fprintf(w, "📁 %s - ❌ %s\n\n", name, i18n.T("status.error", redact.Error(repo.Err)))
+			continue
+		}
+
+		defaultName := defaultBranchName(repo.DefaultRef)
+		var shown []git.Branch
+		for _, branch := range repo.Branches {
+			if filter.matches(branch, branch.Name == defaultName, now) {
+				shown = append(shown, branch)
+			}
+		}
+		if len(shown) == 0 {
+			continue
+		}
+
+		defaultLabel := i18n.T("branches.no_default")
+		if repo.DefaultRef != "" {
+			defaultLabel = i18n.T("branches.default", defaultName)
+		}
+		fmt.Fprintf(w, "📁 %s (%s)\n", name, defaultLabel)
+
+		nameWidth, upstreamWidth := 0, 0
+		for _, branch := range shown {
+			nameWidth = max(nameWidth, utf8.RuneCountInString(branch.Name))
+			upstreamWidth = max(upstreamWidth, utf8.RuneCountInString(formatUpstream(branch)))
+		}
+		for _, branch := range shown {
+			marker := " "
+			if branch.Current {
+				marker = "*"
+			}
+			state := formatMergeState(branch, branch.Name == defaultName, repo.DefaultRef != "")
+			if repo.DefaultRef != "" && !branch.Merged && branch.Name != defaultName {
+				unmerged++
+			}
+			age := i18n.T("branches.age", formatAge(now.Sub(branch.LastCommit)))
+			line := fmt.Sprintf("   %s %-*s  %-*s  %-10s  %s", marker, nameWidth, branch.Name, upstreamWidth, formatUpstream(branch), age, state)
+			fmt.Fprintln(w, strings.TrimRight(line, " "))
+		}
+		fmt.Fprintln(w)
+		total += len(shown)
+		withBranches++
+	}
+
+	if total == 0 && filter.active() {
+		fmt.Fprintf(w, "%s\n\n", i18n.T("branches.none"))
+	}
+	fmt.Fprintln(w, i18n.T("branches.summary", total, withBranches, unmerged))
+}
+
+func formatUpstream(branch git.Branch) string {
+	switch {
+	case branch.UpstreamGone:
+		return i18n.T("branches.upstream_gone")
+	case branch.Upstream == "":
+		return i18n.T("status.no_upstream")
+	}
+	parts := []string{branch.Upstream}
+	if branch.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", branch.Ahead))
+	}
+	if branch.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", branch.Behind))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatMergeState(branch git.Branch, isDefault, hasDefault bool) string {
+	switch {
+	case isDefault:
+		return i18n.T("branches.is_default")
+	case !hasDefault:
+		return ""
+	case branch.Merged:
+		return i18n.T("branches.merged")
+	default:
+		return i18n.T("branches.unmerged")
+	}
+}
+
+// formatAge formats a duration in its largest whole unit, e.g. "3d" or "5mo"
+func formatAge(age time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age/time.Minute))
+	case age < day:
+		return fmt.Sprintf("%dh", int(age/time.Hour))
+	case age < 30*day:
+		return fmt.Sprintf("%dd", int(age/day))
+	case age < 365*day:
+		return fmt.Sprintf("%dmo", int(age/(30*day)))
+	default:
+		return fmt.Sprintf("%dy", int(age/(365*day)))
+	}
+}
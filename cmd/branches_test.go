@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/git"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "90d", want: 90 * 24 * time.Hour},
+		{value: "6w", want: 42 * 24 * time.Hour},
+		{value: "720h", want: 720 * time.Hour},
+		{value: "xd", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseAge(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	day := 24 * time.Hour
+	tests := map[time.Duration]string{
+		5 * time.Minute: "5m",
+		3 * time.Hour:   "3h",
+		12 * day:        "12d",
+		95 * day:        "3mo",
+		800 * day:       "2y",
+	}
+	for age, want := range tests {
+		if got := formatAge(age); got != want {
+			t.Errorf("formatAge(%v) = %q, want %q", age, got, want)
+		}
+	}
+}
+
+func TestDisplayLocalBranches(t *testing.T) {
+	root := "/repos"
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	repos := []localRepoBranches{
+		{
+			Path:       filepath.Join(root, "gitlab", "group", "api"),
+			DefaultRef: "origin/main",
+			Branches: []git.Branch{
+				{Name: "feature/login", Current: true, Upstream: "origin/feature/login", Ahead: 2, LastCommit: now.Add(-3 * day)},
+				{Name: "main", Upstream: "origin/main", Merged: true, LastCommit: now.Add(-1 * day)},
+				{Name: "old-fix", UpstreamGone: true, Merged: true, LastCommit: now.Add(-200 * day)},
+				{Name: "spike", LastCommit: now.Add(-120 * day)},
+			},
+		},
+		{
+			Path:     filepath.Join(root, "github", "org", "scratch"),
+			Branches: []git.Branch{{Name: "wip", Current: true, LastCommit: now.Add(-2 * time.Hour)}},
+		},
+		{Path: filepath.Join(root, "github", "org", "broken"), Err: errors.New("corrupt")},
+	}
+
+	tests := []struct {
+		name    string
+		filter  branchFilter
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "all branches",
+			want: []string{
+				"📁 gitlab/group/api (default: main)",
+				"   * feature/login  origin/feature/login ↑2  3d ago      unmerged",
+				"     main           origin/main              1d ago      default",
+				"     old-fix        upstream gone            6mo ago     merged",
+				"📁 github/org/scratch (no default branch found)",
+				"   * wip  no upstream  2h ago",
+				"📁 github/org/broken - ❌ Error: corrupt",
+				"Summary: 5 branches in 2 repositories, 2 not merged into the default branch",
+			},
+		},
+		{
+			name:    "stale",
+			filter:  branchFilter{staleAfter: 90 * day},
+			want:    []string{"old-fix", "spike", "Summary: 2 branches in 1 repositories, 1 not merged"},
+			notWant: []string{"feature/login", "main ", "scratch"},
+		},
+		{
+			name:    "stale and unmerged",
+			filter:  branchFilter{staleAfter: 90 * day, unmerged: true},
+			want:    []string{"spike", "Summary: 1 branches in 1 repositories, 1 not merged"},
+			notWant: []string{"old-fix", "feature/login"},
+		},
+		{
+			name:   "nothing matches",
+			filter: branchFilter{staleAfter: 1000 * day},
+			want:   []string{"No branches match the filters", "Summary: 0 branches in 0 repositories"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			displayLocalBranches(&buf, root, repos, tt.filter, now)
+			output := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", notWant, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/theme"
+	"gitstuff/internal/tui"
+	"gitstuff/internal/verbosity"
+	"gitstuff/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse [group]",
+	Short: "Interactively browse, select and act on repositories",
+	Long: `Show the provider, group and repository tree with local status in a full
+screen browser. Type / to filter as you type, select repositories with space
+(on a group, space selects everything below it) and press c to clone, p to
+pull or o to open the selection in your web browser. Without a selection the
+action applies to the repository or group under the cursor. The status of
+clones is refreshed as their files change.
+
+Examples:
+  gitstuff browse               # Browse every provider
+  gitstuff browse backend-team  # Browse a single group
+  gitstuff browse --https -j 4  # Clone over HTTPS, 4 repositories at a time`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBrowse,
+}
+
+func init() {
+	rootCmd.AddCommand(browseCmd)
+	browseCmd.Flags().Bool("https", false, "Use HTTPS instead of SSH when cloning")
+	browseCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to clone/pull in parallel")
+	browseCmd.Flags().Bool("watch", true, "Refresh the status of clones as their files change")
+	addRepoFilterFlags(browseCmd)
+	addLimitRateFlag(browseCmd)
+}
+
+// openURL opens a URL in the default web browser
+var openURL = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+func runBrowse(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+
+	groupFilter := ""
+	if len(args) == 1 {
+		groupFilter = args[0]
+	}
+
+	fmt.Fprintln(stdout, i18n.T("browse.loading", len(clients)))
+	var rows []tui.Row
+	fetchers := make(readmeFetchers)
+	annotations := loadState(cfg, stdout)
+	for _, client := range clients {
+		tree, err := client.BuildRepositoryTree(commandContext(cmd))
+		if err != nil {
+			fmt.Fprintln(stdout, i18n.T("list.tree_error", client.GetProviderType(), providerErrorText(err)))
+			continue
+		}
+		filter.applyTree(client, tree)
+		clientRows := browseRows(client.GetProviderType(), tree, groupFilter, cfg, annotations)
+		for _, row := range clientRows {
+			if row.Repo != nil {
+				fetchers.add(client, []*scm.Repository{row.Repo})
+			}
+		}
+		rows = append(rows, clientRows...)
+	}
+	verbosity.DebugTiming(start, "Loaded %d browse rows", len(rows))
+
+	if len(rows) == 0 {
+		fmt.Fprintln(stdout, i18n.T("browse.none"))
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(commandContext(cmd))
+	defer cancel()
+	var updates <-chan tui.StatusUpdate
+	if watchClones, _ := cmd.Flags().GetBool("watch"); watchClones {
+		updates = watchBrowseRows(ctx, cfg, rows, annotations)
+	}
+	result, err := tui.Run(rows, func(repo *scm.Repository) ([]string, error) {
+		return fetchers.preview(ctx, repo)
+	}, updates)
+	if err != nil {
+		return err
+	}
+	return runBrowseAction(cmd, cfg, result)
+}
+
+func runBrowseAction(cmd *cobra.Command, cfg *config.Config, result tui.Result) error {
+	switch result.Action {
+	case tui.ActionOpen:
+		for _, repo := range result.Repos {
+			fmt.Fprintln(stdout, i18n.T("browse.opening", repo.WebURL))
+			if err := openURL(repo.WebURL); err != nil {
+				fmt.Fprintln(stdout, i18n.T("browse.open_failed", repo.WebURL, redact.Error(err)))
+			}
+		}
+		return nil
+	case tui.ActionClone, tui.ActionPull:
+	default:
+		return nil
+	}
+
+	if err := checkWritable("clone or pull repositories"); err != nil {
+		return err
+	}
+
+	repos := result.Repos
+	if result.Action == tui.ActionPull {
+		repos = clonedRepositories(cfg, repos)
+		if skipped := len(result.Repos) - len(repos); skipped > 0 {
+			fmt.Fprintf(stdout, "%s\n\n", i18n.T("browse.not_cloned", skipped))
+		}
+	}
+
+	stopLimit, err := startTransferLimit(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopLimit()
+
+	useHTTPS, _ := cmd.Flags().GetBool("https")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	remotes, err := compileRemoteRules(cfg.Remotes)
+	if err != nil {
+		return err
+	}
+	pullRules, err := compilePullRules(cfg.PullRules)
+	if err != nil {
+		return err
+	}
+	opts := cloneOptions{
+		useSSH:      !useHTTPS,
+		protocolSet: useHTTPS,
+		update:      result.Action == tui.ActionPull,
+		skipDirty:   true,
+		jobs:        jobs,
+		remotes:     remotes,
+		pullRules:   pullRules,
+	}
+
+	summary := processRepositories(commandContext(cmd), repos, cfg, opts, stdout)
+	displayCloneSummary(stdout, summary)
+	return nil
+}
+
+// clonedRepositories returns the repositories that exist locally
+func clonedRepositories(cfg *config.Config, repos []*scm.Repository) []*scm.Repository {
+	var cloned []*scm.Repository
+	for _, repo := range repos {
+		status, err := git.GetRepositoryStatus(paths.ResolveRepositoryPath(cfg, repo))
+		if err == nil && status.Exists && status.IsGitRepo {
+			cloned = append(cloned, repo)
+		}
+	}
+	return cloned
+}
+
+// browseRows flattens a provider's tree, or the group at groupFilter within
+// it, into browser rows with the local status and annotation of each
+// repository
+func browseRows(providerType string, tree *scm.RepositoryTree, groupFilter string, cfg *config.Config, annotations *state.State) []tui.Row {
+	rows := []tui.Row{{Kind: tui.RowProvider, Label: strings.ToUpper(providerType)}}
+
+	if groupFilter != "" {
+		group := findGroupInTree(tree, groupFilter)
+		if group == nil {
+			return nil
+		}
+		return appendGroupRows(rows, group, 1, cfg, annotations)
+	}
+
+	rows = appendRepoRows(rows, tree.Repositories, 1, cfg, annotations)
+	for _, name := range sortedGroupNames(tree.Groups) {
+		rows = appendGroupRows(rows, tree.Groups[name], 1, cfg, annotations)
+	}
+	return rows
+}
+
+func appendGroupRows(rows []tui.Row, group *scm.GroupNode, depth int, cfg *config.Config, annotations *state.State) []tui.Row {
+	rows = append(rows, tui.Row{Kind: tui.RowGroup, Depth: depth, Label: group.Group.Name})
+	rows = appendRepoRows(rows, group.Repositories, depth+1, cfg, annotations)
+	for _, name := range sortedGroupNames(group.SubGroups) {
+		rows = appendGroupRows(rows, group.SubGroups[name], depth+1, cfg, annotations)
+	}
+	return rows
+}
+
+func appendRepoRows(rows []tui.Row, repos []*scm.Repository, depth int, cfg *config.Config, annotations *state.State) []tui.Row {
+	sorted := append([]*scm.Repository(nil), repos...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	for _, repo := range sorted {
+		localStatus, err := git.GetRepositoryStatus(paths.ResolveRepositoryPath(cfg, repo))
+		status := browseStatus(repo, localStatus, err, annotations)
+		rows = append(rows, tui.Row{Kind: tui.RowRepository, Depth: depth, Label: repo.Name, Repo: repo, Status: status})
+	}
+	return rows
+}
+
+// browseStatus is the status shown next to a repository in the browser
+func browseStatus(repo *scm.Repository, localStatus *git.Status, err error, annotations *state.State) string {
+	var status string
+	if err != nil {
+		status = theme.Get().Error + " " + i18n.T("status.error", redact.Error(err))
+	} else {
+		status = getCompactStatus(localStatus, repo.DefaultBranch)
+	}
+	if summary := annotationSummary(annotations.Annotation(repo)); summary != "" {
+		status += "  " + summary
+	}
+	return status
+}
+
+// watchBrowseRows watches the clones of the repositories in rows until ctx
+// is done, sending their new status whenever their files change. It
+// returns nil when the clones cannot be watched.
+func watchBrowseRows(ctx context.Context, cfg *config.Config, rows []tui.Row, annotations *state.State) <-chan tui.StatusUpdate {
+	byPath := make(map[string][]*scm.Repository)
+	var clonePaths []string
+	for _, row := range rows {
+		if row.Repo == nil {
+			continue
+		}
+		path := filepath.Clean(paths.ResolveRepositoryPath(cfg, row.Repo))
+		if len(byPath[path]) == 0 {
+			clonePaths = append(clonePaths, path)
+		}
+		byPath[path] = append(byPath[path], row.Repo)
+	}
+
+	watcher, err := watch.New(clonePaths)
+	if err != nil {
+		verbosity.Debug("Not watching clones: %v", err)
+		return nil
+	}
+	changes := make(chan watch.Change)
+	updates := make(chan tui.StatusUpdate)
+	go func() {
+		defer watcher.Close()
+		watcher.Run(ctx, changes)
+	}()
+	go func() {
+		for {
+			select {
+			case change := <-changes:
+				for _, repo := range byPath[change.Path] {
+					select {
+					case updates <- tui.StatusUpdate{Repo: repo, Status: browseStatus(repo, change.Status, change.Err, annotations)}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return updates
+}
+
+func sortedGroupNames(groups map[string]*scm.GroupNode) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"gitstuff/internal/scm"
+	"gitstuff/internal/tui"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBrowseRows(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	tree := &scm.RepositoryTree{
+		Repositories: []*scm.Repository{{Name: "root", FullPath: "root", Provider: "gitlab"}},
+		Groups: map[string]*scm.GroupNode{
+			"group": {
+				Group:        &scm.Group{Name: "group", FullPath: "group"},
+				Repositories: []*scm.Repository{repos[2], repos[0], repos[1]},
+				SubGroups: map[string]*scm.GroupNode{
+					"sub": {Group: &scm.Group{Name: "sub", FullPath: "group/sub"}},
+				},
+			},
+		},
+	}
+
+	rows := browseRows("gitlab", tree, "", cfg, nil)
+	var labels []string
+	for _, row := range rows {
+		labels = append(labels, strings.Repeat(".", row.Depth)+row.Label)
+	}
+	want := "GITLAB,.root,.group,..clean,..dirty,..missing,..sub"
+	if got := strings.Join(labels, ","); got != want {
+		t.Errorf("browseRows() = %s, want %s", got, want)
+	}
+	if !strings.Contains(rows[3].Status, "✅") || !strings.Contains(rows[5].Status, "❌") {
+		t.Errorf("Expected local status for clean and missing, got %q and %q", rows[3].Status, rows[5].Status)
+	}
+
+	rows = browseRows("gitlab", tree, "group/sub", cfg, nil)
+	if len(rows) != 2 || rows[1].Label != "sub" {
+		t.Errorf("Expected provider and subgroup only, got %+v", rows)
+	}
+	if rows := browseRows("gitlab", tree, "unknown", cfg, nil); rows != nil {
+		t.Errorf("Expected no rows for an unknown group, got %+v", rows)
+	}
+}
+
+func TestRunBrowseAction_Open(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	repos[0].WebURL = "https://gitlab.example.com/group/clean"
+
+	var opened []string
+	original := openURL
+	openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	defer func() { openURL = original }()
+
+	cmd := &cobra.Command{Use: "test"}
+	err := runBrowseAction(cmd, cfg, tui.Result{Action: tui.ActionOpen, Repos: repos[:1]})
+	if err != nil {
+		t.Fatalf("runBrowseAction failed: %v", err)
+	}
+	if len(opened) != 1 || opened[0] != repos[0].WebURL {
+		t.Errorf("Expected %s to be opened, got %v", repos[0].WebURL, opened)
+	}
+}
+
+func TestClonedRepositories(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+
+	cloned := clonedRepositories(cfg, repos)
+	if len(cloned) != 2 || cloned[0].Name != "clean" || cloned[1].Name != "dirty" {
+		t.Errorf("Expected clean and dirty, got %v", cloned)
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var checkoutDefaultCmd = &cobra.Command{
+	Use:   "checkout-default [group-path]",
+	Short: "Switch clean clones back to their default branch and pull",
+	Long: `Return cloned repositories to their baseline: every clean clone that is on
+another branch is switched to the default branch reported by its provider,
+and every clean clone is pulled. Repositories with uncommitted changes are
+left alone, and repositories that are not cloned are not cloned.
+
+Examples:
+  gitstuff checkout-default                # All cloned repositories
+  gitstuff checkout-default backend -j 8   # One group, 8 at a time`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheckoutDefault,
+}
+
+func init() {
+	rootCmd.AddCommand(checkoutDefaultCmd)
+	checkoutDefaultCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to process in parallel")
+	addRepoFilterFlags(checkoutDefaultCmd)
+	addLimitRateFlag(checkoutDefaultCmd)
+}
+
+func runCheckoutDefault(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	if err := checkWritable("switch branches"); err != nil {
+		return err
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	stopLimit, err := startTransferLimit(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopLimit()
+
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	pullRules, err := compilePullRules(cfg.PullRules)
+	if err != nil {
+		return err
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+
+	ctx := commandContext(cmd)
+	repos := collectRepositories(ctx, clients, groupPath, filter)
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+	cloned := clonedRepositories(cfg, repos)
+	if len(cloned) == 0 {
+		fmt.Fprintln(stdout, i18n.T("checkout.none_cloned"))
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("checkout.switching", len(cloned)))
+	opts := cloneOptions{update: true, skipDirty: true, checkoutDefault: true, jobs: jobs, filter: filter, pullRules: pullRules}
+	summary := processRepositories(ctx, cloned, cfg, opts, stdout)
+	displayCheckoutSummary(stdout, summary)
+
+	verbosity.DebugTiming(start, "Checkout of default branches completed")
+	return nil
+}
+
+func displayCheckoutSummary(w io.Writer, summary *processSummary) {
+	fmt.Fprintln(w, i18n.T("checkout.summary_header"))
+	fmt.Fprintf(w, "  🔀 %s\n", i18n.T("sync.summary_switched", summary.Switched))
+	fmt.Fprintf(w, "  🔄 %s\n", i18n.T("checkout.summary_pulled", summary.Updated-summary.Switched))
+	fmt.Fprintf(w, "  ⚠️  %s\n", i18n.T("sync.summary_skipped", len(summary.Dirty)))
+	if len(summary.Protected) > 0 {
+		fmt.Fprintf(w, "  ⚠️  %s\n", i18n.T("sync.summary_protected", len(summary.Protected)))
+	}
+	fmt.Fprintf(w, "  ❌ %s\n", i18n.T("sync.summary_failed", summary.Failed()))
+	if len(summary.Interrupted) > 0 {
+		fmt.Fprintf(w, "  ⏹️  %s\n", i18n.T("sync.summary_interrupted", len(summary.Interrupted)))
+	}
+	displaySummaryDetails(w, summary)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_CheckoutDefault(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+
+	cleanPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	dirtyPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")
+	status, err := git.GetRepositoryStatus(cleanPath)
+	if err != nil {
+		t.Fatalf("GetRepositoryStatus failed: %v", err)
+	}
+	defaultBranch := status.CurrentBranch
+	for _, repo := range repos {
+		repo.DefaultBranch = defaultBranch
+	}
+	for _, path := range []string{cleanPath, dirtyPath} {
+		if out, err := exec.Command("git", "-C", path, "checkout", "-q", "-b", "feature").CombinedOutput(); err != nil {
+			t.Fatalf("git checkout failed: %v\n%s", err, out)
+		}
+	}
+
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "checkout-default")
+	if err != nil {
+		t.Fatalf("checkout-default failed: %v", err)
+	}
+
+	for path, want := range map[string]string{cleanPath: defaultBranch, dirtyPath: "feature"} {
+		status, err := git.GetRepositoryStatus(path)
+		if err != nil {
+			t.Fatalf("GetRepositoryStatus failed: %v", err)
+		}
+		if status.CurrentBranch != want {
+			t.Errorf("Expected %s on %s, got %s", path, want, status.CurrentBranch)
+		}
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing")) {
+		t.Error("Expected repositories that are not cloned to stay that way")
+	}
+	for _, line := range []string{"Returning 2 repositories", "Switching from feature to the default branch " + defaultBranch, "Switched to default branch: 1", "Skipped (uncommitted changes): 1"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+}
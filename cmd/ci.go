@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// pipelineJobs is how many CI statuses are fetched at a time for list output
+const pipelineJobs = 8
+
+// pipelineResult is the CI status of one repository. Unsupported is set when
+// its provider cannot fetch pipelines.
+type pipelineResult struct {
+	Pipeline    *scm.Pipeline
+	Unsupported bool
+	Err         error
+}
+
+// pipelineStatuses holds the CI status of the repositories shown by list
+// --ci, and is nil without the flag
+type pipelineStatuses map[*scm.Repository]pipelineResult
+
+// fetch adds the latest pipelines of repos, fetched in parallel. Failures are
+// kept so they can be told apart from repositories without pipelines.
+func (s pipelineStatuses) fetch(ctx context.Context, client scm.Client, repos []*scm.Repository) {
+	fetcher, ok := scm.Unwrap(client).(scm.PipelineFetcher)
+	if !ok {
+		for _, repo := range repos {
+			s[repo] = pipelineResult{Unsupported: true}
+		}
+		return
+	}
+
+	results := make([]pipelineResult, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			results[i].Pipeline, results[i].Err = fetcher.LatestPipeline(ctx, repo)
+			if results[i].Err != nil {
+				verbosity.WithRepo(repo.FullPath, repo.Provider).Debug("Could not fetch CI status: %v", results[i].Err)
+			}
+			return results[i].Err
+		}
+	}
+	runner.New(pipelineJobs).RunContext(ctx, tasks, io.Discard)
+	for i, repo := range repos {
+		s[repo] = results[i]
+	}
+}
+
+// icon returns the indicator shown for the CI status of repo
+func (s pipelineStatuses) icon(repo *scm.Repository) string {
+	result := s[repo]
+	switch {
+	case result.Unsupported:
+		return "❔"
+	case result.Err != nil:
+		return "⚠️"
+	case result.Pipeline == nil:
+		return "➖"
+	}
+	switch result.Pipeline.Status {
+	case scm.PipelineSuccess:
+		return "✅"
+	case scm.PipelineFailed:
+		return "❌"
+	case scm.PipelineRunning:
+		return "⏳"
+	default:
+		return "⏹️"
+	}
+}
+
+// describe returns the icon and a short description of the CI status of repo
+func (s pipelineStatuses) describe(repo *scm.Repository) string {
+	result := s[repo]
+	var text string
+	switch {
+	case result.Unsupported:
+		text = i18n.T("ci.unsupported")
+	case result.Err != nil:
+		text = i18n.T("status.error", redact.Error(result.Err))
+	case result.Pipeline == nil:
+		text = i18n.T("ci.none")
+	default:
+		text = i18n.T("ci." + result.Pipeline.Status)
+	}
+	return fmt.Sprintf("%s %s", s.icon(repo), text)
+}
+
+// treeRepositories returns every repository in tree, at the root and in
+// groups, or only those in groupFilter when it is set
+func treeRepositories(tree *scm.RepositoryTree, groupFilter string) []*scm.Repository {
+	var repos []*scm.Repository
+	var walk func(group *scm.GroupNode)
+	walk = func(group *scm.GroupNode) {
+		repos = append(repos, group.Repositories...)
+		for _, subGroup := range group.SubGroups {
+			walk(subGroup)
+		}
+	}
+	if groupFilter != "" {
+		if group := findGroupInTree(tree, groupFilter); group != nil {
+			walk(group)
+		}
+		return repos
+	}
+	repos = append(repos, tree.Repositories...)
+	for _, group := range tree.Groups {
+		walk(group)
+	}
+	return repos
+}
+
+// displayPipelineSummary counts the CI statuses shown by list, so broken
+// builds stand out in long lists
+func displayPipelineSummary(pipelines pipelineStatuses) {
+	counts := make(map[string]int)
+	for _, result := range pipelines {
+		if result.Pipeline != nil {
+			counts[result.Pipeline.Status]++
+		}
+	}
+	fmt.Fprintln(stdout, i18n.T("ci.summary", counts[scm.PipelineSuccess], counts[scm.PipelineFailed], counts[scm.PipelineRunning]))
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+// mockPipelineClient returns the pipeline of each repository by full path
+type mockPipelineClient struct {
+	mockSCMClient
+	pipelines map[string]*scm.Pipeline
+}
+
+func (m *mockPipelineClient) LatestPipeline(ctx context.Context, repo *scm.Repository) (*scm.Pipeline, error) {
+	if repo.FullPath == "team/broken" {
+		return nil, errors.New("forbidden")
+	}
+	return m.pipelines[repo.FullPath], nil
+}
+
+func TestDisplayRepositoryList_CI(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: t.TempDir()}}
+	repos := []*scm.Repository{
+		{Name: "api", FullPath: "team/api", Provider: "gitlab"},
+		{Name: "web", FullPath: "team/web", Provider: "gitlab"},
+		{Name: "docs", FullPath: "team/docs", Provider: "gitlab"},
+		{Name: "broken", FullPath: "team/broken", Provider: "gitlab"},
+	}
+	gitlabClient := &mockPipelineClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: repos},
+		pipelines: map[string]*scm.Pipeline{
+			"team/api": {Status: scm.PipelineSuccess},
+			"team/web": {Status: scm.PipelineFailed},
+		},
+	}
+	githubClient := &mockSCMClient{providerType: "github", repos: []*scm.Repository{
+		{Name: "tool", FullPath: "octo/tool", Provider: "github"},
+	}}
+
+	out := captureOutput(func() {
+		_ = displayRepositoryList(context.Background(), []scm.Client{gitlabClient, githubClient}, cfg, false, true, "", "", repoFilter{})
+	})
+	for _, want := range []string{
+		"team/api\n   CI: ✅ passing",
+		"team/web\n   CI: ❌ failing",
+		"team/docs\n   CI: ➖ no pipelines",
+		"team/broken\n   CI: ⚠️ Error: forbidden",
+		"octo/tool\n   CI: ❔ not supported by this provider",
+		"CI: 1 passing, 1 failing, 0 running",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out = captureOutput(func() {
+		_ = displayRepositoryList(context.Background(), []scm.Client{gitlabClient}, cfg, false, false, "", "", repoFilter{})
+	})
+	if strings.Contains(out, "CI:") {
+		t.Errorf("Expected no CI status without --ci, got:\n%s", out)
+	}
+}
+
+func TestDisplayRepositoryTree_CI(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: t.TempDir()}}
+	api := &scm.Repository{Name: "api", FullPath: "team/backend/api", Provider: "gitlab"}
+	site := &scm.Repository{Name: "site", FullPath: "team/site", Provider: "gitlab"}
+	tree := &scm.RepositoryTree{Groups: map[string]*scm.GroupNode{
+		"team": {
+			Group:        &scm.Group{Name: "team", FullPath: "team"},
+			Repositories: []*scm.Repository{site},
+			SubGroups: map[string]*scm.GroupNode{
+				"backend": {
+					Group:        &scm.Group{Name: "backend", FullPath: "team/backend"},
+					Repositories: []*scm.Repository{api},
+				},
+			},
+		},
+	}}
+	client := &mockPipelineClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", tree: tree},
+		pipelines: map[string]*scm.Pipeline{
+			"team/backend/api": {Status: scm.PipelineRunning},
+			"team/site":        {Status: scm.PipelineCanceled},
+		},
+	}
+
+	out := captureOutput(func() {
+		_ = displayRepositoryTree(context.Background(), []scm.Client{client}, cfg, false, true, "", repoFilter{})
+	})
+	for _, want := range []string{"📁 site - CI ⏹️", "📁 api - CI ⏳"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if got := treeRepositories(tree, "team/backend"); len(got) != 1 || got[0] != api {
+		t.Errorf("Expected only the repositories of the filtered group, got %v", got)
+	}
+}
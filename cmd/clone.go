@@ -1,17 +1,27 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"gitstuff/internal/cache"
 	"gitstuff/internal/config"
 	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
 	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
 	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
 	"gitstuff/internal/verbosity"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 var cloneCmd = &cobra.Command{
@@ -25,6 +35,14 @@ Examples:
   gitstuff clone group --all          # Clone all repositories in a group (SSH)
   gitstuff clone group/subgroup --all # Clone all repositories in a subgroup (SSH)
   gitstuff clone owner/repo --https   # Clone specific repository using HTTPS
+  gitstuff clone --all --update -j 8  # Clone/update all repositories, 8 at a time
+  gitstuff clone --all --fetch-only   # Clone missing repositories, fetch the others
+  gitstuff clone --all -u --ff-only   # Update only where it is a fast-forward
+  gitstuff clone --all --fail-fast --summary-json report.json  # For CI
+
+When cloning a group or all repositories, the exit status tells how the run
+went: 0 when every repository succeeded, 2 when some failed and 3 when all
+of them failed. Other errors exit with 1.
 
 Repository/group path format: 'owner/repo' or 'group' or 'group/subgroup'`,
 	RunE: runClone,
@@ -36,39 +54,73 @@ func init() {
 	cloneCmd.Flags().BoolP("ssh", "s", true, "Use SSH for cloning (default: SSH)")
 	cloneCmd.Flags().Bool("https", false, "Use HTTPS for cloning")
 	cloneCmd.Flags().BoolP("update", "u", false, "Pull latest changes for already cloned repositories")
+	cloneCmd.Flags().Bool("fetch-only", false, "Update already cloned repositories with git fetch instead of pull (implies --update)")
+	cloneCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to clone/update in parallel")
+	cloneCmd.Flags().Bool("move-renamed", false, "Move clones of renamed or transferred repositories without asking")
+	cloneCmd.Flags().Bool("protocol-fallback", false, "Retry failed clones over the other protocol (default: git.protocol_fallback)")
+	cloneCmd.Flags().String("reference", "", "Borrow objects from this local repository instead of downloading them again")
+	cloneCmd.Flags().Bool("reference-forks", false, "Borrow the objects of forks from the clone of their parent (default: git.reference_forks)")
+	cloneCmd.Flags().Bool("fail-fast", false, "Stop starting new clones and updates after the first repository fails")
+	cloneCmd.Flags().String("summary-json", "", "Write a JSON summary of the run to this file, as in .gitstuff-last-run.json")
+	addPullStrategyFlags(cloneCmd)
+	addRetryFlags(cloneCmd)
+	cloneCmd.MarkFlagsMutuallyExclusive("fetch-only", "rebase", "ff-only")
+	addRepoFilterFlags(cloneCmd)
+	addLimitRateFlag(cloneCmd)
 }
 
 func runClone(cmd *cobra.Command, args []string) error {
 	start := time.Now()
 	verbosity.Debug("Starting clone operation with args: %v", args)
 
-	cfg, err := config.Load()
+	cfg, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
 	}
 	verbosity.Debug("Loaded configuration with %d providers", len(cfg.Providers))
+	if err := checkWritable("clone repositories"); err != nil {
+		return err
+	}
 
 	if len(cfg.Providers) == 0 {
 		return fmt.Errorf("no providers configured")
 	}
 
-	// Create clients for all providers
-	clients := make([]scm.Client, 0, len(cfg.Providers))
-	for _, providerConfig := range cfg.Providers {
-		verbosity.Debug("Creating client for provider: %s (%s)", providerConfig.Name, providerConfig.Type)
-		client, err := createClient(providerConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create client for provider %s: %w", providerConfig.Name, err)
-		}
-		clients = append(clients, client)
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+
+	stopLimit, err := startTransferLimit(cmd, cfg)
+	if err != nil {
+		return err
 	}
+	defer stopLimit()
 
 	cloneAll, _ := cmd.Flags().GetBool("all")
 	useSSH, _ := cmd.Flags().GetBool("ssh")
 	useHTTPS, _ := cmd.Flags().GetBool("https")
 	update, _ := cmd.Flags().GetBool("update")
+	fetchOnly, _ := cmd.Flags().GetBool("fetch-only")
+	update = update || fetchOnly
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	moveRenamed, _ := cmd.Flags().GetBool("move-renamed")
+	protocolFallback := protocolFallbackFromFlags(cmd, cfg)
+	reference, _ := cmd.Flags().GetString("reference")
+	referenceForks := cfg.Git.ReferenceForks
+	if cmd.Flags().Changed("reference-forks") {
+		referenceForks, _ = cmd.Flags().GetBool("reference-forks")
+	}
+	pullStrategy, err := pullStrategyFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	retry, err := retryPolicyFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
 
-	verbosity.Debug("Clone flags: all=%t, ssh=%t, https=%t, update=%t", cloneAll, useSSH, useHTTPS, update)
+	verbosity.Debug("Clone flags: all=%t, ssh=%t, https=%t, update=%t, jobs=%d", cloneAll, useSSH, useHTTPS, update, jobs)
 
 	// If --https is explicitly set, override SSH default
 	if useHTTPS {
@@ -78,205 +130,631 @@ func runClone(cmd *cobra.Command, args []string) error {
 		verbosity.Debug("Using SSH for cloning")
 	}
 
-	if cloneAll && len(args) == 0 {
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	remotes, err := compileRemoteRules(cfg.Remotes)
+	if err != nil {
+		return err
+	}
+	pullRules, err := compilePullRules(cfg.PullRules)
+	if err != nil {
+		return err
+	}
+	setup, err := compileSetupRules(cfg.Setup)
+	if err != nil {
+		return err
+	}
+	managed, err := loadManagedInfo(cfg)
+	if err != nil {
+		return err
+	}
+	opts := cloneOptions{useSSH: useSSH, protocolSet: cmd.Flags().Changed("ssh") || cmd.Flags().Changed("https"), update: update, jobs: jobs, filter: filter, remotes: remotes, pullRules: pullRules,
+		state: loadState(cfg, stdout), moveRenamed: moveRenamed, protocolFallback: protocolFallback, fetchOnly: fetchOnly, pull: pullStrategy, setup: setup, maintenance: cfg.Git.Maintenance, managed: managed,
+		reference: expandHome(reference), referenceForks: referenceForks, retry: retry}
+	opts.failFast, _ = cmd.Flags().GetBool("fail-fast")
+	summaryPath, _ := cmd.Flags().GetString("summary-json")
+
+	ctx := commandContext(cmd)
+	var summary *processSummary
+	switch {
+	case cloneAll && len(args) == 0:
 		verbosity.Info("Cloning all repositories from all providers")
-		result := cloneAllRepositories(clients, cfg, useSSH, update)
+		summary, err = cloneAllRepositories(ctx, clients, cfg, opts)
 		verbosity.DebugTiming(start, "Clone all operation completed")
-		return result
-	}
-
-	if cloneAll && len(args) == 1 {
+	case cloneAll && len(args) == 1:
 		verbosity.Info("Cloning all repositories in group: %s", args[0])
-		result := cloneGroupRepositories(clients, cfg, args[0], useSSH, update)
+		summary, err = cloneGroupRepositories(ctx, clients, cfg, args[0], opts)
 		verbosity.DebugTiming(start, "Clone group operation completed")
-		return result
-	}
-
-	if len(args) == 0 {
+	case len(args) == 0:
 		verbosity.Info("No specific repository specified, cloning all repositories")
-		result := cloneAllRepositories(clients, cfg, useSSH, update)
+		summary, err = cloneAllRepositories(ctx, clients, cfg, opts)
 		verbosity.DebugTiming(start, "Clone all operation completed")
+	default:
+		verbosity.Info("Cloning single repository: %s", args[0])
+		result := cloneSingleRepository(ctx, clients, cfg, args[0], opts)
+		verbosity.DebugTiming(start, "Clone single operation completed")
 		return result
 	}
-
-	verbosity.Info("Cloning single repository: %s", args[0])
-	result := cloneSingleRepository(clients, cfg, args[0], useSSH, update)
-	verbosity.DebugTiming(start, "Clone single operation completed")
-	return result
+	// Runs over many repositories are the ones left unattended
+	run := newLastRun("clone", args, start, summary, err)
+	if err == nil {
+		err = runFailure(summary)
+	}
+	run.ExitCode = exitCode(err)
+	recordLastRun(cfg, run)
+	if summaryPath != "" {
+		if saveErr := state.SaveRunFile(expandHome(summaryPath), run); saveErr != nil {
+			fmt.Fprintf(stderr, "⚠️  %s\n", i18n.T("clone.summary_unwritable", redact.Error(saveErr)))
+		}
+	}
+	return err
 }
 
-func cloneAllRepositories(clients []scm.Client, cfg *config.Config, useSSH, update bool) error {
-	start := time.Now()
-	verbosity.Debug("Collecting repositories from %d providers", len(clients))
-	var allRepos []*scm.Repository
+type cloneOptions struct {
+	useSSH bool
 
-	// Collect all repositories from all providers
-	for _, client := range clients {
-		clientStart := time.Now()
-		verbosity.Debug("Fetching repositories from %s provider", client.GetProviderType())
-		repos, err := client.ListAllRepositories()
-		if err != nil {
-			fmt.Printf("❌ Error getting repositories from %s provider: %v\n", client.GetProviderType(), err)
-			continue
-		}
-		verbosity.DebugTiming(clientStart, "Fetched %d repositories from %s provider", len(repos), client.GetProviderType())
-		allRepos = append(allRepos, repos...)
+	// protocolSet is whether useSSH comes from --ssh or --https rather
+	// than the default, so it wins over the protocol set for a provider
+	protocolSet bool
+
+	update    bool
+	skipDirty bool
+	jobs      int
+	filter    repoFilter
+	remotes   []remoteRule
+	pullRules []pullRule
+
+	// state records where repositories are cloned so renamed ones can be
+	// moved instead of cloned again; nil disables tracking
+	state       *state.State
+	moveRenamed bool
+
+	// protocolFallback retries clones that fail to authenticate or connect
+	// over the other protocol
+	protocolFallback bool
+
+	// checkoutDefault switches clean repositories to their default branch
+	// before pulling
+	checkoutDefault bool
+
+	// fetchOnly updates existing clones with a fetch instead of a pull, so
+	// their branches and working trees are left as they are
+	fetchOnly bool
+
+	// pull chooses how pulls integrate upstream changes; with Autostash,
+	// dirty clones are pulled instead of skipped
+	pull git.PullOptions
+
+	// setup runs commands in repositories after they are first cloned
+	setup []setupRule
+
+	// maintenance registers new clones for git's background maintenance
+	maintenance bool
+
+	// reference is a local repository every new clone borrows objects
+	// from, and referenceForks makes forks borrow them from the clone of
+	// their parent
+	reference      string
+	referenceForks bool
+
+	// managed holds the ignore and attribute patterns kept up to date in
+	// every processed clone
+	managed managedInfo
+
+	// failFast stops starting repositories once one has failed
+	failFast bool
+
+	// retry tries clones, fetches and pulls that fail on a network error
+	// again, counting the retries for a repository in retried when set
+	retry   retryPolicy
+	retried *int
+}
+
+// configCloneOptions returns the options to clone or update one repository
+// at a time following cfg alone, for commands without the clone flags
+func configCloneOptions(cfg *config.Config, pull git.PullOptions, w io.Writer) (cloneOptions, error) {
+	remotes, err := compileRemoteRules(cfg.Remotes)
+	if err != nil {
+		return cloneOptions{}, err
+	}
+	pullRules, err := compilePullRules(cfg.PullRules)
+	if err != nil {
+		return cloneOptions{}, err
+	}
+	setup, err := compileSetupRules(cfg.Setup)
+	if err != nil {
+		return cloneOptions{}, err
 	}
+	managed, err := loadManagedInfo(cfg)
+	if err != nil {
+		return cloneOptions{}, err
+	}
+	retry, err := configRetryPolicy(cfg)
+	if err != nil {
+		return cloneOptions{}, err
+	}
+	return cloneOptions{useSSH: true, skipDirty: true, jobs: 1, remotes: remotes, pullRules: pullRules, state: loadState(cfg, w),
+		protocolFallback: cfg.Git.ProtocolFallback, pull: pull, setup: setup, maintenance: cfg.Git.Maintenance, managed: managed,
+		referenceForks: cfg.Git.ReferenceForks, retry: retry}, nil
+}
 
-	verbosity.DebugTiming(start, "Repository collection completed")
-	fmt.Printf("Found %d repositories to clone/update\n\n", len(allRepos))
+func cloneAllRepositories(ctx context.Context, clients []scm.Client, cfg *config.Config, opts cloneOptions) (*processSummary, error) {
+	allRepos := collectRepositories(ctx, clients, "", opts.filter)
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("clone.found_all", len(allRepos)))
 
-	successful := 0
-	failed := 0
+	allRepos = relocateMovedRepositories(cfg, allRepos, opts, stdout)
+	summary := processRepositories(ctx, allRepos, cfg, opts, stdout)
 
-	for i, repo := range allRepos {
-		repoStart := time.Now()
-		fmt.Printf("[%d/%d] Processing %s [%s]...\n", i+1, len(allRepos), repo.FullPath, repo.Provider)
+	displayCloneSummary(stdout, summary)
+	return summary, nil
+}
 
-		// Check if repo exists in either location (new or legacy structure)
-		checkPath := paths.ResolveRepositoryPath(cfg, repo)
-		verbosity.Debug("Checking repository status at: %s", checkPath)
-		status, err := git.GetRepositoryStatus(checkPath)
-		if err != nil {
-			fmt.Printf("❌ Error checking status: %v\n\n", err)
-			failed++
-			continue
-		}
+func displayCloneSummary(w io.Writer, summary *processSummary) {
+	fmt.Fprintln(w, i18n.T("clone.summary", summary.Successful(), summary.Failed()))
+	switch {
+	case len(summary.Interrupted) > 0 && summary.Stopped:
+		fmt.Fprintf(w, "⚠️  %s\n", i18n.T("clone.summary_stopped", len(summary.Interrupted)))
+	case len(summary.Interrupted) > 0:
+		fmt.Fprintf(w, "⚠️  %s\n", i18n.T("clone.summary_interrupted", len(summary.Interrupted)))
+	}
+	displayVisibilityChanges(w, summary.VisibilityChanges)
+	displayRetried(w, summary.Retried)
+}
 
-		if status.Exists && status.IsGitRepo {
-			if update {
-				verbosity.Debug("Repository exists, pulling latest changes")
-				fmt.Printf("🔄 Pulling latest changes...\n")
-				pullStart := time.Now()
-				if err := git.PullRepository(checkPath); err != nil {
-					fmt.Printf("❌ Failed to pull: %v\n\n", err)
-					failed++
-				} else {
-					verbosity.DebugTiming(pullStart, "Pull completed for %s", repo.FullPath)
-					fmt.Printf("✅ Updated successfully\n\n")
-					successful++
-				}
+// collectRepositories lists repositories from every client, optionally
+// restricted to groupPath. Provider errors are reported and skipped so one
+// failing provider does not block the others.
+func collectRepositories(ctx context.Context, clients []scm.Client, groupPath string, filter repoFilter) []*scm.Repository {
+	var allRepos []*scm.Repository
+	for _, provider := range collectProviderRepositories(ctx, clients, groupPath, filter) {
+		allRepos = append(allRepos, provider.repos...)
+	}
+	return allRepos
+}
+
+// providerRepositories are the repositories collected from one client
+type providerRepositories struct {
+	client scm.Client
+	repos  []*scm.Repository
+}
+
+// collectProviderRepositories is collectRepositories keeping track of which
+// client each repository came from. Nothing more is collected once ctx is
+// done.
+func collectProviderRepositories(ctx context.Context, clients []scm.Client, groupPath string, filter repoFilter) []providerRepositories {
+	start := time.Now()
+	verbosity.Debug("Collecting repositories from %d providers", len(clients))
+	var collected []providerRepositories
+
+	fetched, errs := fetchProviderRepositories(ctx, clients, groupPath)
+	if ctx.Err() != nil {
+		verbosity.Debug("Stopped collecting repositories: %v", ctx.Err())
+	}
+	for i, client := range clients {
+		if err := errs[i]; err != nil {
+			if ctx.Err() != nil {
+				// Interrupted rather than failed
+				continue
+			}
+			if groupPath != "" {
+				// The group usually only exists on one of the providers
+				verbosity.Debug("Group %s not available from %s provider: %v", groupPath, client.GetProviderType(), err)
 			} else {
-				verbosity.Debug("Repository already exists, skipping (no update flag)")
-				fmt.Printf("⏭️  Already cloned (use --update to pull latest changes)\n\n")
-				successful++
+				fmt.Fprintf(stdout, "❌ %s\n", i18n.T("clone.provider_error", client.GetProviderType(), providerErrorText(err)))
 			}
-			verbosity.DebugTiming(repoStart, "Processed existing repository: %s", repo.FullPath)
 			continue
 		}
+		reportStaleListing(stdout, client)
+		collected = append(collected, providerRepositories{client: client, repos: filter.applyFor(client, fetched[i])})
+	}
 
-		cloneURL := repo.CloneURL
-		if useSSH {
-			cloneURL = repo.SSHCloneURL
-		}
+	verbosity.DebugTiming(start, "Repository collection completed")
+	return collected
+}
 
-		verbosity.Debug("Cloning repository using %s protocol: %s", map[bool]string{true: "SSH", false: "HTTPS"}[useSSH], cloneURL)
-		fmt.Printf("📥 Cloning from %s...\n", cloneURL)
-		cloneStart := time.Now()
-		if err := git.CloneRepository(cloneURL, paths.GetClonePath(cfg, repo), useSSH); err != nil {
-			fmt.Printf("❌ Failed to clone: %v\n\n", err)
-			failed++
-		} else {
-			verbosity.DebugTiming(cloneStart, "Clone completed for %s", repo.FullPath)
-			fmt.Printf("✅ Cloned successfully\n\n")
-			successful++
-		}
-		verbosity.DebugTiming(repoStart, "Processed new repository: %s", repo.FullPath)
+// fetchProviderRepositories lists the repositories of every client at the
+// same time, in groupPath when given. The results and errors are in client
+// order, so the merged listing does not depend on which provider answered
+// first.
+func fetchProviderRepositories(ctx context.Context, clients []scm.Client, groupPath string) ([][]*scm.Repository, []error) {
+	repos := make([][]*scm.Repository, len(clients))
+	errs := make([]error, len(clients))
+
+	var g errgroup.Group
+	for i, client := range clients {
+		g.Go(func() error {
+			clientStart := time.Now()
+			if groupPath != "" {
+				verbosity.Debug("Fetching repositories from %s provider in group: %s", client.GetProviderType(), groupPath)
+				repos[i], errs[i] = client.ListRepositoriesInGroup(ctx, groupPath)
+			} else {
+				verbosity.Debug("Fetching repositories from %s provider", client.GetProviderType())
+				repos[i], errs[i] = client.ListAllRepositories(ctx)
+			}
+			if errs[i] == nil {
+				verbosity.DebugTiming(clientStart, "Fetched %d repositories from %s provider", len(repos[i]), client.GetProviderType())
+			}
+			// Errors are per provider and left to the caller
+			return nil
+		})
 	}
-
-	fmt.Printf("Summary: %d successful, %d failed\n", successful, failed)
-	return nil
+	_ = g.Wait()
+	return repos, errs
 }
 
-func cloneGroupRepositories(clients []scm.Client, cfg *config.Config, groupPath string, useSSH, update bool) error {
+func cloneGroupRepositories(ctx context.Context, clients []scm.Client, cfg *config.Config, groupPath string, opts cloneOptions) (*processSummary, error) {
 	var allRepos []*scm.Repository
 
 	// Collect repositories from the specified group across all providers
-	for _, client := range clients {
-		repos, err := client.ListRepositoriesInGroup(groupPath)
-		if err != nil {
+	fetched, errs := fetchProviderRepositories(ctx, clients, groupPath)
+	for i, client := range clients {
+		if errs[i] != nil {
 			continue
 		}
+		reportStaleListing(stdout, client)
+		repos := opts.filter.applyFor(client, fetched[i])
 		if len(repos) > 0 {
-			fmt.Printf("✅ Found %d repositories in %s provider\n", len(repos), client.GetProviderType())
+			fmt.Fprintf(stdout, "✅ %s\n", i18n.T("clone.found_provider", len(repos), client.GetProviderType()))
 		}
 		allRepos = append(allRepos, repos...)
 	}
 
 	if len(allRepos) == 0 {
-		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+		return nil, fmt.Errorf("no repositories found in group '%s'", groupPath)
 	}
 
-	fmt.Printf("Found %d repositories in group '%s' to clone/update\n\n", len(allRepos), groupPath)
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("clone.found_group", len(allRepos), groupPath))
 
-	successful := 0
-	failed := 0
+	allRepos = relocateMovedRepositories(cfg, allRepos, opts, stdout)
+	summary := processRepositories(ctx, allRepos, cfg, opts, stdout)
 
-	for i, repo := range allRepos {
-		repoStart := time.Now()
-		fmt.Printf("[%d/%d] Processing %s [%s]...\n", i+1, len(allRepos), repo.FullPath, repo.Provider)
+	displayCloneSummary(stdout, summary)
+	return summary, nil
+}
 
-		checkPath := paths.ResolveRepositoryPath(cfg, repo)
-		verbosity.Debug("Checking repository status at: %s", checkPath)
-		status, err := git.GetRepositoryStatus(checkPath)
-		if err != nil {
-			fmt.Printf("❌ Error checking status: %v\n\n", err)
-			failed++
-			continue
-		}
+type repoOutcome int
+
+const (
+	outcomeCloned repoOutcome = iota
+	outcomeUpdated
+	outcomeSwitched // Updated after switching to the default branch
+	outcomeFetched
+	outcomeSkipped
+	outcomeDirty
+	outcomeProtected
+	outcomeFailed
+)
+
+// repoFailure records why processing a repository failed
+type repoFailure struct {
+	Repo *scm.Repository
+	Err  error
+}
+
+// processSummary tallies the outcome of processing a set of repositories
+type processSummary struct {
+	Cloned   int
+	Updated  int
+	Skipped  int
+	Switched int // Of the updated, those switched to their default branch first
+	Fetched  int
+	Dirty    []*scm.Repository
+	Failures []repoFailure
+
+	// Protected repositories were not pulled because their default branch
+	// has local commits
+	Protected []*scm.Repository
+
+	// Interrupted repositories were not processed, or not completely,
+	// because the run was stopped
+	Interrupted []*scm.Repository
+
+	// Stopped is set when the run was stopped by the first failure, with
+	// --fail-fast, rather than interrupted
+	Stopped bool
+
+	// VisibilityChanges are the repositories whose visibility changed since
+	// they were last synced
+	VisibilityChanges []state.VisibilityChange
+
+	// Retention holds the clones and backups sync found due for removal
+	Retention []retentionAction
+
+	// Durations is how long each processed repository took
+	Durations []repoDuration
+
+	// Retried are the processed repositories that took more than one
+	// attempt, in the order they were processed
+	Retried []repoRetries
+}
+
+type repoDuration struct {
+	Repo     *scm.Repository
+	Duration time.Duration
+}
+
+func (s *processSummary) Successful() int {
+	return s.Cloned + s.Updated + s.Fetched + s.Skipped + len(s.Dirty) + len(s.Protected)
+}
+
+func (s *processSummary) Failed() int {
+	return len(s.Failures)
+}
+
+// processRepositories clones or updates each repository using up to
+// opts.jobs parallel workers and returns a summary of the outcomes. Once ctx
+// is done no further repositories are started, and those left out or cut
+// short are reported as interrupted.
+func processRepositories(ctx context.Context, repos []*scm.Repository, cfg *config.Config, opts cloneOptions, out io.Writer) *processSummary {
+	interruptible.Store(true)
+	defer interruptible.Store(false)
+
+	if opts.referenceForks {
+		repos = forksLast(repos)
+	}
+	r := runner.New(opts.jobs)
+	verbosity.Debug("Processing %d repositories with %d parallel jobs", len(repos), r.Jobs())
+
+	bar := newProgressBar(out, len(repos))
+	runOut := out
+	if bar != nil {
+		// Tasks report to the bar instead
+		runOut = io.Discard
+	}
 
-		if status.Exists && status.IsGitRepo {
-			if update {
-				verbosity.Debug("Repository exists, pulling latest changes")
-				fmt.Printf("🔄 Pulling latest changes...\n")
-				pullStart := time.Now()
-				if err := git.PullRepository(checkPath); err != nil {
-					fmt.Printf("❌ Failed to pull: %v\n\n", err)
-					failed++
-				} else {
-					verbosity.DebugTiming(pullStart, "Pull completed for %s", repo.FullPath)
-					fmt.Printf("✅ Updated successfully\n\n")
-					successful++
-				}
+	// With failFast the first failure stops new tasks, like an interrupt.
+	// Repositories already being processed keep the parent context and
+	// finish, so they are not counted as failed.
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	outcomes := make([]repoOutcome, len(repos))
+	durations := make([]time.Duration, len(repos))
+	retried := make([]int, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		label := fmt.Sprintf("[%d/%d]", i+1, len(repos))
+		repoOpts := opts
+		repoOpts.retried = &retried[i]
+		process := func(w io.Writer) (repoOutcome, error) {
+			return processRepository(ctx, w, label, repo, cfg, repoOpts)
+		}
+		tasks[i] = func(w io.Writer) error {
+			taskStart := time.Now()
+			var outcome repoOutcome
+			var err error
+			if bar != nil {
+				outcome, err = processWithProgress(bar, process, repo.FullPath)
 			} else {
-				verbosity.Debug("Repository already exists, skipping (no update flag)")
-				fmt.Printf("⏭️  Already cloned (use --update to pull latest changes)\n\n")
-				successful++
+				outcome, err = process(w)
+			}
+			outcomes[i], durations[i] = outcome, time.Since(taskStart)
+			if err != nil && opts.failFast {
+				stop()
 			}
-			verbosity.DebugTiming(repoStart, "Processed existing repository: %s", repo.FullPath)
+			return err
+		}
+	}
+
+	results := r.RunContext(runCtx, tasks, runOut)
+	if bar != nil {
+		bar.Close()
+	}
+
+	// Compare before recording, which stores the new visibilities
+	summary := &processSummary{VisibilityChanges: opts.state.VisibilityChanges(repos), Stopped: runCtx.Err() != nil && ctx.Err() == nil}
+	for _, result := range results {
+		repo := repos[result.Index]
+		if result.Skipped || (result.Err != nil && ctx.Err() != nil) {
+			summary.Interrupted = append(summary.Interrupted, repo)
+			continue
+		}
+		summary.Durations = append(summary.Durations, repoDuration{Repo: repo, Duration: durations[result.Index]})
+		if retried[result.Index] > 0 {
+			summary.Retried = append(summary.Retried, repoRetries{Repo: repo, Attempts: retried[result.Index] + 1})
+		}
+		if result.Err != nil {
+			summary.Failures = append(summary.Failures, repoFailure{Repo: repo, Err: result.Err})
 			continue
 		}
+		opts.state.Record(repo, paths.ResolveRepositoryPath(cfg, repo))
+		switch outcomes[result.Index] {
+		case outcomeCloned:
+			summary.Cloned++
+		case outcomeUpdated:
+			summary.Updated++
+		case outcomeSwitched:
+			summary.Updated++
+			summary.Switched++
+		case outcomeFetched:
+			summary.Fetched++
+		case outcomeSkipped:
+			summary.Skipped++
+		case outcomeDirty:
+			summary.Dirty = append(summary.Dirty, repo)
+		case outcomeProtected:
+			summary.Protected = append(summary.Protected, repo)
+		}
+	}
+
+	if !opts.managed.empty() {
+		var processed []string
+		for _, result := range results {
+			if result.Err == nil && !result.Skipped {
+				processed = append(processed, paths.ResolveRepositoryPath(cfg, repos[result.Index]))
+			}
+		}
+		applyManagedInfo(out, opts.managed, processed)
+	}
+	if opts.maintenance {
+		var newClones []string
+		for _, result := range results {
+			if result.Err == nil && !result.Skipped && outcomes[result.Index] == outcomeCloned {
+				newClones = append(newClones, paths.ResolveRepositoryPath(cfg, repos[result.Index]))
+			}
+		}
+		maintainNewClones(out, newClones)
+	}
+
+	if err := opts.state.Save(); err != nil {
+		fmt.Fprintf(out, "⚠️  %s\n", i18n.T("relocate.state_unwritable", redact.Error(err)))
+	}
+	return summary
+}
+
+func processRepository(ctx context.Context, w io.Writer, label string, repo *scm.Repository, cfg *config.Config, opts cloneOptions) (repoOutcome, error) {
+	repoStart := time.Now()
+	log := verbosity.WithRepo(repo.FullPath, repo.Provider)
+	fmt.Fprintf(w, "%s %s\n", label, i18n.T("clone.processing", repo.FullPath, repo.Provider))
 
-		cloneURL := repo.CloneURL
-		if useSSH {
-			cloneURL = repo.SSHCloneURL
+	// Check if repo exists in either location (new or legacy structure)
+	checkPath := paths.ResolveRepositoryPath(cfg, repo)
+	log.Debug("Checking repository status at: %s", checkPath)
+	status, err := git.GetRepositoryStatus(checkPath)
+	if err != nil {
+		fmt.Fprintf(w, "❌ %s\n\n", i18n.T("status.error_checking", redact.Error(err)))
+		return outcomeFailed, err
+	}
+
+	if status.Exists && status.IsGitRepo {
+		defer log.DebugTiming(repoStart, "Processed existing repository")
+		if !opts.update {
+			log.Debug("Repository already exists, skipping (no update flag)")
+			fmt.Fprintf(w, "⏭️  %s\n", i18n.T("clone.already_cloned"))
+			applyRemoteRules(w, checkPath, repo, opts.remotes)
+			fmt.Fprintln(w)
+			return outcomeSkipped, nil
 		}
 
-		verbosity.Debug("Cloning repository using %s protocol: %s", map[bool]string{true: "SSH", false: "HTTPS"}[useSSH], cloneURL)
-		fmt.Printf("📥 Cloning from %s...\n", cloneURL)
-		cloneStart := time.Now()
-		if err := git.CloneRepository(cloneURL, paths.GetClonePath(cfg, repo), useSSH); err != nil {
-			fmt.Printf("❌ Failed to clone: %v\n\n", err)
-			failed++
+		if opts.fetchOnly {
+			// A fetch leaves the working tree alone, so even dirty clones
+			// can be fetched
+			fmt.Fprintf(w, "📡 %s\n", i18n.T("clone.fetching"))
+			err := opts.retry.run(ctx, w, w, opts.retried, func(stderr io.Writer) error {
+				return git.FetchRepositoryWithAuth(checkPath, originAuthFor(cfg, repo, checkPath), w, stderr)
+			})
+			if err != nil {
+				fmt.Fprintf(w, "❌ %s\n\n", i18n.T("clone.fetch_failed", redact.Error(err)))
+				return outcomeFailed, err
+			}
+			fmt.Fprintf(w, "✅ %s\n", i18n.T("clone.fetched"))
+			applyRemoteRules(w, checkPath, repo, opts.remotes)
+			fmt.Fprintln(w)
+			return outcomeFetched, nil
+		}
+
+		if opts.skipDirty && status.HasChanges && !opts.pull.Autostash {
+			log.Debug("Repository has uncommitted changes, skipping pull")
+			fmt.Fprintf(w, "⚠️  %s\n", i18n.T("clone.skipped_dirty"))
+			applyRemoteRules(w, checkPath, repo, opts.remotes)
+			fmt.Fprintln(w)
+			return outcomeDirty, nil
+		}
+
+		switched := false
+		if opts.checkoutDefault && !status.HasChanges && repo.DefaultBranch != "" && status.CurrentBranch != repo.DefaultBranch {
+			log.Debug("Switching from %s to default branch %s", status.CurrentBranch, repo.DefaultBranch)
+			fmt.Fprintf(w, "🔀 %s\n", i18n.T("clone.switching", status.CurrentBranch, repo.DefaultBranch))
+			if err := git.CheckoutBranch(checkPath, repo.DefaultBranch, w, w); err != nil {
+				fmt.Fprintf(w, "❌ %s\n\n", i18n.T("clone.switch_failed", redact.Error(err)))
+				return outcomeFailed, err
+			}
+			status.CurrentBranch = repo.DefaultBranch
+			switched = true
+		}
+
+		action, ahead, err := planPull(checkPath, status, repo, opts.pullRules)
+		if err != nil {
+			fmt.Fprintf(w, "❌ %s\n\n", i18n.T("status.error_checking", redact.Error(err)))
+			return outcomeFailed, err
+		}
+		if action == pullRefuse {
+			log.Debug("Default branch has %d local commits, refusing to merge", ahead)
+			fmt.Fprintf(w, "⚠️  %s\n", i18n.T("clone.protected_refused", status.CurrentBranch, ahead))
+			fmt.Fprintf(w, "   %s\n", i18n.T("clone.protected_hint", status.CurrentBranch))
+			applyRemoteRules(w, checkPath, repo, opts.remotes)
+			fmt.Fprintln(w)
+			return outcomeProtected, nil
+		}
+
+		log.Debug("Repository exists, pulling latest changes")
+		if action == pullRebase && ahead > 0 {
+			fmt.Fprintf(w, "🔄 %s\n", i18n.T("clone.rebasing", ahead))
 		} else {
-			verbosity.DebugTiming(cloneStart, "Clone completed for %s", repo.FullPath)
-			fmt.Printf("✅ Cloned successfully\n\n")
-			successful++
+			fmt.Fprintf(w, "🔄 %s\n", i18n.T("clone.pulling"))
+		}
+		pull := opts.pull
+		pull.Auth = originAuthFor(cfg, repo, checkPath)
+		pullStart := time.Now()
+		err = opts.retry.run(ctx, w, w, opts.retried, func(stderr io.Writer) error {
+			return pullWithAction(checkPath, action, pull, w, stderr)
+		})
+		if err != nil {
+			fmt.Fprintf(w, "❌ %s\n\n", i18n.T("clone.pull_failed", redact.Error(err)))
+			return outcomeFailed, err
+		}
+		log.DebugTiming(pullStart, "Pull completed")
+		fmt.Fprintf(w, "✅ %s\n", i18n.T("clone.updated"))
+		applyRemoteRules(w, checkPath, repo, opts.remotes)
+		fmt.Fprintln(w)
+		if switched {
+			return outcomeSwitched, nil
 		}
-		verbosity.DebugTiming(repoStart, "Processed new repository: %s", repo.FullPath)
+		return outcomeUpdated, nil
 	}
 
-	fmt.Printf("Summary: %d successful, %d failed\n", successful, failed)
-	return nil
+	useSSH := cloneUsesSSH(cfg, repo, opts)
+	cloneURL := cloneURLFor(cfg, repo, useSSH)
+
+	log.Debug("Cloning repository using %s protocol: %s", map[bool]string{true: "SSH", false: "HTTPS"}[useSSH], cloneURL)
+	fmt.Fprintf(w, "📥 %s\n", i18n.T("clone.cloning", redact.String(cloneURL)))
+	cloneStart := time.Now()
+	defer log.DebugTiming(repoStart, "Processed new repository")
+	clonePath := paths.GetClonePath(cfg, repo)
+	existed := pathExists(clonePath)
+	err = opts.retry.run(ctx, w, w, opts.retried, func(stderr io.Writer) error {
+		err := cloneWithFallback(cfg, repo, clonePath, useSSH, opts, w, stderr)
+		if err != nil && !existed {
+			// Retries need the clone path empty again
+			removePartialClone(cfg, clonePath)
+		}
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(w, "❌ %s\n\n", i18n.T("clone.clone_failed", redact.Error(err)))
+		return outcomeFailed, err
+	}
+	log.DebugTiming(cloneStart, "Clone completed")
+	fmt.Fprintf(w, "✅ %s\n", i18n.T("clone.cloned"))
+	applyRemoteRules(w, clonePath, repo, opts.remotes)
+	if err := runSetup(w, clonePath, repo, opts.setup); err != nil {
+		fmt.Fprintf(w, "❌ %s\n\n", i18n.T("setup.failed", redact.Error(err)))
+		return outcomeFailed, err
+	}
+	fmt.Fprintln(w)
+	return outcomeCloned, nil
+}
+
+// removePartialClone deletes what a failed or killed clone left at path,
+// along with the directories created for it
+func removePartialClone(cfg *config.Config, path string) {
+	verbosity.Debug("Removing partial clone at %s", path)
+	if err := os.RemoveAll(path); err != nil {
+		verbosity.Debug("Failed to remove partial clone: %v", err)
+		return
+	}
+	removeEmptyParents(filepath.Dir(path), cfg.Local.BaseDir)
 }
 
-func cloneSingleRepository(clients []scm.Client, cfg *config.Config, repoPath string, useSSH, update bool) error {
+func cloneSingleRepository(ctx context.Context, clients []scm.Client, cfg *config.Config, repoPath string, opts cloneOptions) error {
 	// Search for the repository across all providers
 	var foundRepo *scm.Repository
 
 	for _, client := range clients {
 		// Try to find the repository in this provider
-		repo, err := findRepositoryByPath(client, repoPath)
+		repo, err := findRepositoryByPath(ctx, client, repoPath)
 		if err == nil && repo != nil {
 			foundRepo = repo
 			break
@@ -287,7 +765,19 @@ func cloneSingleRepository(clients []scm.Client, cfg *config.Config, repoPath st
 		return fmt.Errorf("repository '%s' not found in any configured provider", repoPath)
 	}
 
-	fmt.Printf("Found repository: %s [%s]\n", foundRepo.FullPath, foundRepo.Provider)
+	fmt.Fprintln(stdout, i18n.T("clone.found_repository", foundRepo.FullPath, foundRepo.Provider))
+	if len(relocateMovedRepositories(cfg, []*scm.Repository{foundRepo}, opts, stdout)) == 0 {
+		return fmt.Errorf("the clone of %s is still at its old path", foundRepo.FullPath)
+	}
+	defer func() {
+		if pathExists(paths.ResolveRepositoryPath(cfg, foundRepo)) {
+			opts.state.Record(foundRepo, paths.ResolveRepositoryPath(cfg, foundRepo))
+			applyManagedInfo(stdout, opts.managed, []string{paths.ResolveRepositoryPath(cfg, foundRepo)})
+			if err := opts.state.Save(); err != nil {
+				fmt.Fprintf(stdout, "⚠️  %s\n", i18n.T("relocate.state_unwritable", redact.Error(err)))
+			}
+		}
+	}()
 
 	checkPath := paths.ResolveRepositoryPath(cfg, foundRepo)
 	status, err := git.GetRepositoryStatus(checkPath)
@@ -296,16 +786,38 @@ func cloneSingleRepository(clients []scm.Client, cfg *config.Config, repoPath st
 	}
 
 	if status.Exists && status.IsGitRepo {
-		if update {
-			fmt.Printf("🔄 Pulling latest changes...\n")
-			if err := git.PullRepository(checkPath); err != nil {
+		if opts.update && opts.fetchOnly {
+			fmt.Fprintf(stdout, "📡 %s\n", i18n.T("clone.fetching"))
+			err := opts.retry.run(ctx, stdout, stderr, nil, func(stderr io.Writer) error {
+				return git.FetchRepositoryWithAuth(checkPath, originAuthFor(cfg, foundRepo, checkPath), stdout, stderr)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to fetch repository: %w", err)
+			}
+			fmt.Fprintf(stdout, "✅ %s\n", i18n.T("clone.fetched"))
+		} else if opts.update {
+			action, ahead, err := planPull(checkPath, status, foundRepo, opts.pullRules)
+			if err != nil {
+				return fmt.Errorf("error checking repository status: %w", err)
+			}
+			if action == pullRefuse {
+				return fmt.Errorf("%s\n%s", i18n.T("clone.protected_refused", status.CurrentBranch, ahead), i18n.T("clone.protected_hint", status.CurrentBranch))
+			}
+			pull := opts.pull
+			pull.Auth = originAuthFor(cfg, foundRepo, checkPath)
+			fmt.Fprintf(stdout, "🔄 %s\n", i18n.T("clone.pulling"))
+			err = opts.retry.run(ctx, stdout, stderr, nil, func(stderr io.Writer) error {
+				return pullWithAction(checkPath, action, pull, stdout, stderr)
+			})
+			if err != nil {
 				return fmt.Errorf("failed to pull repository: %w", err)
 			}
-			fmt.Printf("✅ Repository updated successfully\n")
+			fmt.Fprintf(stdout, "✅ %s\n", i18n.T("clone.repo_updated"))
 		} else {
-			fmt.Printf("⏭️  Repository already cloned at: %s\n", checkPath)
-			fmt.Printf("   Use --update flag to pull latest changes\n")
+			fmt.Fprintf(stdout, "⏭️  %s\n", i18n.T("clone.repo_exists", checkPath))
+			fmt.Fprintf(stdout, "   %s\n", i18n.T("clone.use_update"))
 		}
+		applyRemoteRules(stdout, checkPath, foundRepo, opts.remotes)
 		return nil
 	}
 
@@ -313,35 +825,64 @@ func cloneSingleRepository(clients []scm.Client, cfg *config.Config, repoPath st
 		return fmt.Errorf("directory %s exists but is not a git repository", checkPath)
 	}
 
-	cloneURL := foundRepo.CloneURL
-	if useSSH {
-		cloneURL = foundRepo.SSHCloneURL
-	}
+	useSSH := cloneUsesSSH(cfg, foundRepo, opts)
+	cloneURL := cloneURLFor(cfg, foundRepo, useSSH)
 
 	clonePath := paths.GetClonePath(cfg, foundRepo)
-	fmt.Printf("📥 Cloning from %s to %s...\n", cloneURL, clonePath)
-	if err := git.CloneRepository(cloneURL, clonePath, useSSH); err != nil {
+	fmt.Fprintf(stdout, "📥 %s\n", i18n.T("clone.cloning_to", redact.String(cloneURL), clonePath))
+	err = opts.retry.run(ctx, stdout, stderr, nil, func(stderr io.Writer) error {
+		err := cloneWithFallback(cfg, foundRepo, clonePath, useSSH, opts, stdout, stderr)
+		if err != nil {
+			removePartialClone(cfg, clonePath)
+		}
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
 
-	fmt.Printf("✅ Repository cloned successfully\n")
-	return nil
+	fmt.Fprintf(stdout, "✅ %s\n", i18n.T("clone.repo_cloned"))
+	applyRemoteRules(stdout, clonePath, foundRepo, opts.remotes)
+	if opts.maintenance {
+		maintainNewClones(stdout, []string{clonePath})
+	}
+	return runSetup(stdout, clonePath, foundRepo, opts.setup)
 }
 
 // findRepositoryByPath searches for a repository by its path (owner/repo format)
-func findRepositoryByPath(client scm.Client, repoPath string) (*scm.Repository, error) {
+func findRepositoryByPath(ctx context.Context, client scm.Client, repoPath string) (*scm.Repository, error) {
 	// Get all repositories from this provider
-	repos, err := client.ListAllRepositories()
+	repos, err := client.ListAllRepositories(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Search for exact match or partial match
-	for _, repo := range repos {
-		if repo.FullPath == repoPath || strings.HasSuffix(repo.FullPath, repoPath) {
+	if repo := matchRepositoryPath(repos, repoPath); repo != nil {
+		return repo, nil
+	}
+
+	// A repository created since the listing was cached will not be in it
+	if cached, ok := client.(*cache.Client); ok && cached.ServedFromCache() {
+		verbosity.Debug("Repository %s not in cached metadata, refreshing %s provider", repoPath, client.GetProviderType())
+		repos, err := cached.Refresh(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if repo := matchRepositoryPath(repos, repoPath); repo != nil {
 			return repo, nil
 		}
 	}
 
 	return nil, fmt.Errorf("repository not found")
 }
+
+// matchRepositoryPath returns the repository whose full path equals or ends
+// with repoPath
+func matchRepositoryPath(repos []*scm.Repository, repoPath string) *scm.Repository {
+	for _, repo := range repos {
+		if repo.FullPath == repoPath || strings.HasSuffix(repo.FullPath, repoPath) {
+			return repo
+		}
+	}
+	return nil
+}
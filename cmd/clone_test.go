@@ -1,10 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"gitstuff/internal/cache"
+	"gitstuff/internal/config"
 	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
 )
 
 func TestFindRepositoryByPath_ExactMatch(t *testing.T) {
@@ -28,7 +39,7 @@ func TestFindRepositoryByPath_ExactMatch(t *testing.T) {
 		repos:        repos,
 	}
 
-	repo, err := findRepositoryByPath(mockClient, "group/exact-repo")
+	repo, err := findRepositoryByPath(context.Background(), mockClient, "group/exact-repo")
 	if err != nil {
 		t.Errorf("findRepositoryByPath failed: %v", err)
 	}
@@ -55,7 +66,7 @@ func TestFindRepositoryByPath_PartialMatch(t *testing.T) {
 		repos:        repos,
 	}
 
-	repo, err := findRepositoryByPath(mockClient, "partial-repo")
+	repo, err := findRepositoryByPath(context.Background(), mockClient, "partial-repo")
 	if err != nil {
 		t.Errorf("findRepositoryByPath failed: %v", err)
 	}
@@ -82,7 +93,7 @@ func TestFindRepositoryByPath_NotFound(t *testing.T) {
 		repos:        repos,
 	}
 
-	repo, err := findRepositoryByPath(mockClient, "nonexistent-repo")
+	repo, err := findRepositoryByPath(context.Background(), mockClient, "nonexistent-repo")
 	if err == nil {
 		t.Error("Expected error for nonexistent repository")
 	}
@@ -94,6 +105,39 @@ func TestFindRepositoryByPath_NotFound(t *testing.T) {
 	}
 }
 
+func TestFindRepositoryByPath_RefreshesStaleCache(t *testing.T) {
+	mockClient := &mockSCMClient{
+		providerType: "gitlab",
+		repos:        []*scm.Repository{{ID: "1", FullPath: "group/old-repo", Provider: "gitlab"}},
+	}
+	store := cache.NewStore(t.TempDir(), time.Hour)
+
+	warm := cache.Wrap(mockClient, store, "gitlab", false)
+	if _, err := warm.ListAllRepositories(context.Background()); err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+
+	// A repository created after the listing was cached
+	mockClient.repos = append(mockClient.repos, &scm.Repository{ID: "2", FullPath: "group/new-repo", Provider: "gitlab"})
+
+	cached := cache.Wrap(mockClient, store, "gitlab", false)
+	repo, err := findRepositoryByPath(context.Background(), cached, "group/old-repo")
+	if err != nil || repo.ID != "1" {
+		t.Fatalf("Expected cached repository to be found, got %v, %v", repo, err)
+	}
+	if !cached.ServedFromCache() {
+		t.Error("Expected known repository to be served from the cache")
+	}
+
+	repo, err = findRepositoryByPath(context.Background(), cached, "group/new-repo")
+	if err != nil {
+		t.Fatalf("Expected new repository to be found after refresh: %v", err)
+	}
+	if repo.ID != "2" {
+		t.Errorf("Expected repository 2, got %s", repo.ID)
+	}
+}
+
 func TestGroupRepositoryFiltering(t *testing.T) {
 	groupRepos := []*scm.Repository{
 		{
@@ -119,7 +163,7 @@ func TestGroupRepositoryFiltering(t *testing.T) {
 	}
 
 	// Test that the client can return group-specific repositories
-	repos, err := mockClient.ListRepositoriesInGroup("testgroup")
+	repos, err := mockClient.ListRepositoriesInGroup(context.Background(), "testgroup")
 	if err != nil {
 		t.Errorf("ListRepositoriesInGroup failed: %v", err)
 	}
@@ -145,7 +189,7 @@ func TestEmptyGroupFiltering(t *testing.T) {
 	}
 
 	// Test that empty group returns empty list
-	repos, err := mockClient.ListRepositoriesInGroup("nonexistent")
+	repos, err := mockClient.ListRepositoriesInGroup(context.Background(), "nonexistent")
 	if err != nil {
 		t.Errorf("ListRepositoriesInGroup failed: %v", err)
 	}
@@ -173,7 +217,7 @@ func TestSubgroupFiltering(t *testing.T) {
 	}
 
 	// Test that subgroup filtering works
-	repos, err := mockClient.ListRepositoriesInGroup("group/subgroup")
+	repos, err := mockClient.ListRepositoriesInGroup(context.Background(), "group/subgroup")
 	if err != nil {
 		t.Errorf("ListRepositoriesInGroup failed: %v", err)
 	}
@@ -227,7 +271,7 @@ func TestMultipleProviderSupport(t *testing.T) {
 	// Test finding repository across providers
 	var foundRepo *scm.Repository
 	for _, client := range clients {
-		repo, err := findRepositoryByPath(client, "gitlab-repo")
+		repo, err := findRepositoryByPath(context.Background(), client, "gitlab-repo")
 		if err == nil && repo != nil {
 			foundRepo = repo
 			break
@@ -244,7 +288,7 @@ func TestMultipleProviderSupport(t *testing.T) {
 	// Test group filtering across providers
 	var allGroupRepos []*scm.Repository
 	for _, client := range clients {
-		repos, err := client.ListRepositoriesInGroup("gitlab-group")
+		repos, err := client.ListRepositoriesInGroup(context.Background(), "gitlab-group")
 		if err != nil {
 			t.Errorf("ListRepositoriesInGroup failed for %s: %v", client.GetProviderType(), err)
 			continue
@@ -260,3 +304,310 @@ func TestMultipleProviderSupport(t *testing.T) {
 		t.Errorf("Expected GitLab provider in gitlab-group, got: %s", allGroupRepos[0].Provider)
 	}
 }
+
+func TestProcessRepositories_ParallelOrderedOutput(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Local: config.LocalConfig{
+			BaseDir: filepath.Join(tempDir, "repos"),
+		},
+	}
+
+	var repos []*scm.Repository
+	for i := 0; i < 5; i++ {
+		source := createRemoteRepo(t, filepath.Join(tempDir, "remotes", fmt.Sprintf("repo%d.git", i)))
+		repos = append(repos, &scm.Repository{
+			Name:     fmt.Sprintf("repo%d", i),
+			FullPath: fmt.Sprintf("group/repo%d", i),
+			CloneURL: source,
+			Provider: "gitlab",
+		})
+	}
+	repos = append(repos, &scm.Repository{
+		Name:     "broken",
+		FullPath: "group/broken",
+		CloneURL: filepath.Join(tempDir, "remotes", "missing.git"),
+		Provider: "gitlab",
+	})
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 3}, &out)
+
+	if summary.Cloned != 5 || summary.Failed() != 1 {
+		t.Errorf("Expected 5 cloned and 1 failed, got %d and %d", summary.Cloned, summary.Failed())
+	}
+	if summary.Failed() == 1 && summary.Failures[0].Repo.FullPath != "group/broken" {
+		t.Errorf("Expected group/broken to fail, got %s", summary.Failures[0].Repo.FullPath)
+	}
+
+	output := out.String()
+	last := -1
+	for i := 1; i <= len(repos); i++ {
+		idx := strings.Index(output, fmt.Sprintf("[%d/%d] Processing", i, len(repos)))
+		if idx == -1 {
+			t.Fatalf("Missing progress line for repository %d in output:\n%s", i, output)
+		}
+		if idx < last {
+			t.Errorf("Progress line for repository %d is out of order", i)
+		}
+		last = idx
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := os.Stat(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", fmt.Sprintf("repo%d", i), ".git")); err != nil {
+			t.Errorf("Expected repo%d to be cloned: %v", i, err)
+		}
+	}
+
+	// A second run without update should skip everything that exists
+	out.Reset()
+	summary = processRepositories(context.Background(), repos[:5], cfg, cloneOptions{jobs: 3}, &out)
+	if summary.Skipped != 5 || summary.Failed() != 0 {
+		t.Errorf("Expected 5 skipped repositories, got %d skipped and %d failed", summary.Skipped, summary.Failed())
+	}
+	if strings.Count(out.String(), "Already cloned") != 5 {
+		t.Errorf("Expected 5 'Already cloned' lines, got:\n%s", out.String())
+	}
+}
+
+// createRemoteRepo creates a bare repository with a single commit at path
+func createRemoteRepo(t *testing.T, path string) string {
+	t.Helper()
+
+	work := path + ".work"
+	commands := [][]string{
+		{"git", "init", work},
+		{"git", "-C", work, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "Initial commit"},
+		{"git", "clone", "--bare", work, path},
+	}
+	for _, args := range commands {
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("%v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	return path
+}
+
+func TestProcessRepositories_Interrupted(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	repos := []*scm.Repository{
+		{Name: "one", FullPath: "group/one", CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "one.git")), Provider: "gitlab"},
+		{Name: "two", FullPath: "group/two", CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "two.git")), Provider: "gitlab"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	summary := processRepositories(ctx, repos, cfg, cloneOptions{jobs: 2}, &out)
+
+	if len(summary.Interrupted) != 2 || summary.Cloned != 0 || summary.Failed() != 0 {
+		t.Errorf("Expected both repositories to be interrupted, got %+v", summary)
+	}
+	if pathExists(cfg.Local.BaseDir) {
+		t.Error("Expected nothing to be written once interrupted")
+	}
+
+	out.Reset()
+	displayCloneSummary(&out, summary)
+	if !strings.Contains(out.String(), "Interrupted: 2 repositories") {
+		t.Errorf("Expected the summary to report the interrupted repositories, got:\n%s", out.String())
+	}
+}
+
+func TestProcessRepositories_RemovesFailedClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	if err := os.MkdirAll(cfg.Local.BaseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	repos := []*scm.Repository{
+		{Name: "broken", FullPath: "group/sub/broken", CloneURL: filepath.Join(tempDir, "missing.git"), Provider: "gitlab"},
+	}
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 1}, &out)
+
+	if summary.Failed() != 1 {
+		t.Fatalf("Expected the clone to fail, got %+v", summary)
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab")) {
+		t.Error("Expected the directories created for the failed clone to be removed")
+	}
+	if !pathExists(cfg.Local.BaseDir) {
+		t.Error("Expected the base directory to be kept")
+	}
+}
+
+func TestProcessRepositories_FailFast(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	repos := []*scm.Repository{
+		{Name: "broken", FullPath: "group/broken", CloneURL: filepath.Join(tempDir, "missing.git"), Provider: "gitlab"},
+		{Name: "one", FullPath: "group/one", CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "one.git")), Provider: "gitlab"},
+		{Name: "two", FullPath: "group/two", CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "two.git")), Provider: "gitlab"},
+	}
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 1, failFast: true}, &out)
+	if summary.Failed() != 1 || len(summary.Interrupted) != 2 || !summary.Stopped {
+		t.Errorf("Expected one failure to stop the other repositories, got %+v", summary)
+	}
+
+	out.Reset()
+	displayCloneSummary(&out, summary)
+	if !strings.Contains(out.String(), "Stopped after the first failure: 2 repositories") {
+		t.Errorf("Expected the summary to report the stopped run, got:\n%s", out.String())
+	}
+}
+
+func TestCommand_CloneAllExitCodes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	good := &scm.Repository{Name: "api", FullPath: "team/api", Provider: "gitlab", CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "api.git"))}
+	broken := &scm.Repository{Name: "web", FullPath: "team/web", Provider: "gitlab", CloneURL: filepath.Join(tempDir, "remotes", "missing.git")}
+	other := &scm.Repository{Name: "worker", FullPath: "team/worker", Provider: "gitlab", CloneURL: filepath.Join(tempDir, "remotes", "missing.git")}
+	tests := []struct {
+		name  string
+		repos []*scm.Repository
+		args  []string
+		want  int
+	}{
+		{"all succeed", []*scm.Repository{good}, nil, 0},
+		{"some fail", []*scm.Repository{good, broken}, nil, exitPartialFailure},
+		{"all fail", []*scm.Repository{broken}, nil, exitTotalFailure},
+		// The repository after the first failure never ran, so not every
+		// repository failed
+		{"fail fast", []*scm.Repository{broken, other}, []string{"--fail-fast", "--jobs", "1"}, exitPartialFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetFlags(rootCmd)
+			cfg := testConfig(t.TempDir(), "work")
+			clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: tt.repos}}
+			report := filepath.Join(t.TempDir(), "report.json")
+
+			args := append([]string{"clone", "--all", "--https", "--summary-json", report}, tt.args...)
+			_, err := runCommand(t, cfg, clients, args...)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d (%v)", tt.want, got, err)
+			}
+			run, loadErr := state.LoadRun(cfg.Local.BaseDir)
+			if loadErr != nil {
+				t.Fatalf("Failed to load the last run: %v", loadErr)
+			}
+			data, readErr := os.ReadFile(report)
+			if readErr != nil {
+				t.Fatalf("Expected a summary at %s: %v", report, readErr)
+			}
+			if run.ExitCode != tt.want || !strings.Contains(string(data), fmt.Sprintf(`"exit_code": %d`, tt.want)) {
+				t.Errorf("Expected exit_code %d in the summary, got:\n%s", tt.want, data)
+			}
+		})
+	}
+}
+
+// blockingClient lists its repositories only once every client has started
+// listing, so it deadlocks when providers are fetched one after another
+type blockingClient struct {
+	mockSCMClient
+	started *sync.WaitGroup
+	err     error
+}
+
+func (b *blockingClient) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
+	b.started.Done()
+	b.started.Wait()
+	return b.repos, b.err
+}
+
+func TestFetchProviderRepositories_Concurrent(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+	clients := []scm.Client{
+		&blockingClient{mockSCMClient: mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{{FullPath: "group/one"}}}, started: &started},
+		&blockingClient{mockSCMClient: mockSCMClient{providerType: "github"}, started: &started, err: fmt.Errorf("unauthorized")},
+	}
+
+	type result struct {
+		repos [][]*scm.Repository
+		errs  []error
+	}
+	done := make(chan result)
+	go func() {
+		repos, errs := fetchProviderRepositories(context.Background(), clients, "")
+		done <- result{repos, errs}
+	}()
+
+	select {
+	case got := <-done:
+		if len(got.repos[0]) != 1 || got.repos[0][0].FullPath != "group/one" || got.errs[0] != nil {
+			t.Errorf("first provider = %v, %v; want group/one", got.repos[0], got.errs[0])
+		}
+		if got.errs[1] == nil {
+			t.Error("expected the second provider's error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("providers were not fetched concurrently")
+	}
+}
+
+func TestProcessRepositories_Parallel(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	// Run with -race: parallel clones write git's output and errors to
+	// their own buffers from several goroutines at once
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	var repos []*scm.Repository
+	for _, name := range []string{"api", "web", "docs", "tools"} {
+		repos = append(repos, &scm.Repository{Name: name, FullPath: "group/" + name, Provider: "gitlab",
+			CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", name+".git"))})
+	}
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 4, retry: retryPolicy{retries: 1}}, &out)
+	if summary.Cloned != len(repos) || summary.Failed() != 0 {
+		t.Fatalf("Expected every repository to be cloned, got %+v:\n%s", summary, out.String())
+	}
+
+	// Pulling new commits writes to both stdout and stderr
+	for _, repo := range repos {
+		commit, err := exec.Command("git", "-C", repo.CloneURL, "-c", "user.name=Test User", "-c", "user.email=test@example.com",
+			"commit-tree", "HEAD^{tree}", "-p", "HEAD", "-m", "Upstream change").Output()
+		if err != nil {
+			t.Fatalf("Failed to commit to %s: %v", repo.CloneURL, err)
+		}
+		if out, err := exec.Command("git", "-C", repo.CloneURL, "update-ref", "HEAD", strings.TrimSpace(string(commit))).CombinedOutput(); err != nil {
+			t.Fatalf("Failed to update %s: %v\n%s", repo.CloneURL, err, out)
+		}
+	}
+	out.Reset()
+	summary = processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 4, update: true, retry: retryPolicy{retries: 1}}, &out)
+	if summary.Updated != len(repos) || summary.Failed() != 0 {
+		t.Errorf("Expected every repository to be updated, got %+v:\n%s", summary, out.String())
+	}
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitstuff/internal/cache"
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// flagCompletions complete the values of flags that name something in the
+// config file or on the providers. They only read the config file and the
+// metadata cache, so completion stays instant and never calls the APIs.
+var flagCompletions = map[string]cobra.CompletionFunc{
+	"group":     completeGroups,
+	"provider":  completeProviders,
+	"workspace": completeWorkspaces,
+	"topic":     completeTopics,
+	"profile":   completeProfiles,
+}
+
+// argCompletion completes the positional argument at index, or every
+// argument when index is -1
+type argCompletion struct {
+	index    int
+	complete cobra.CompletionFunc
+}
+
+// argCompletions complete the arguments that name repositories, groups or
+// providers, by command path. Like flagCompletions, they only read the
+// config file and the metadata cache.
+var argCompletions = map[string]argCompletion{
+	"gitstuff apply":            {0, completeGroups},
+	"gitstuff archive":          {-1, completeRepositories},
+	"gitstuff archive-export":   {1, completeGroups},
+	"gitstuff audit access":     {0, completeGroups},
+	"gitstuff audit files":      {0, completeGroups},
+	"gitstuff browse":           {0, completeGroups},
+	"gitstuff checkout-default": {0, completeGroups},
+	"gitstuff clone":            {0, completeRepositoriesAndGroups},
+	"gitstuff config edit":      {0, completeProviders},
+	"gitstuff config remove":    {0, completeProviders},
+	"gitstuff config test":      {0, completeProviders},
+	"gitstuff config token":     {0, completeProviders},
+	"gitstuff create":           {0, completeProviders},
+	"gitstuff daemon":           {0, completeGroups},
+	"gitstuff grep":             {1, completeGroups},
+	"gitstuff issues":           {0, completeGroups},
+	"gitstuff migrate-layout":   {0, completeGroups},
+	"gitstuff note":             {0, completeRepositories},
+	"gitstuff open":             {0, completeRepositories},
+	"gitstuff resolve":          {0, completeRepositories},
+	"gitstuff restructure":      {0, completeGroups},
+	"gitstuff sync":             {0, completeGroups},
+	"gitstuff tag create":       {1, completeGroups},
+	"gitstuff unarchive":        {-1, completeRepositories},
+	"gitstuff webhook add":      {1, completeGroups},
+	"gitstuff webhook list":     {0, completeGroups},
+	"gitstuff webhook remove":   {1, completeGroups},
+}
+
+// registerArgCompletions sets the argCompletions as the ValidArgsFunction
+// of every command of the tree under cmd that does not have one
+func registerArgCompletions(cmd *cobra.Command) {
+	if completion, ok := argCompletions[cmd.CommandPath()]; ok && cmd.ValidArgsFunction == nil {
+		cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if completion.index >= 0 && len(args) != completion.index {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completion.complete(cmd, args, toComplete)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		registerArgCompletions(sub)
+	}
+}
+
+// registerFlagCompletions adds the flagCompletions to every command of the
+// tree under cmd, except to flags that already complete their own values
+func registerFlagCompletions(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		complete, ok := flagCompletions[flag.Name]
+		if !ok {
+			return
+		}
+		if _, exists := cmd.GetFlagCompletionFunc(flag.Name); exists {
+			return
+		}
+		if err := cmd.RegisterFlagCompletionFunc(flag.Name, complete); err != nil {
+			verbosity.Debug("Not completing --%s of %s: %v", flag.Name, cmd.Name(), err)
+		}
+	})
+	for _, sub := range cmd.Commands() {
+		registerFlagCompletions(sub)
+	}
+}
+
+func completeProviders(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.ReadStored()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []cobra.Completion
+	for _, provider := range cfg.Providers {
+		if provider.Name != "" && strings.HasPrefix(provider.Name, toComplete) {
+			completions = append(completions, cobra.CompletionWithDesc(provider.Name, strings.TrimSpace(provider.Type+" "+provider.URL)))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeWorkspaces(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.ReadStored()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(cfg.Workspaces))
+	for name := range cfg.Workspaces {
+		names = append(names, name)
+	}
+	return matchingCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	names, err := config.Profiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return matchingCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroups offers every group that has a repository in the metadata
+// cache, including the parents of nested groups
+func completeGroups(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var groups []string
+	for _, repo := range cachedRepositories() {
+		for dir := path.Dir(repo.FullPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			groups = append(groups, dir)
+		}
+	}
+	return matchingCompletions(groups, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepositories offers the full path of every repository in the
+// metadata cache
+func completeRepositories(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var paths []string
+	for _, repo := range cachedRepositories() {
+		paths = append(paths, repo.FullPath)
+	}
+	return matchingCompletions(paths, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepositoriesAndGroups offers repositories and groups together, for
+// commands that take either
+func completeRepositoriesAndGroups(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	repos, _ := completeRepositories(cmd, args, toComplete)
+	groups, _ := completeGroups(cmd, args, toComplete)
+	completions := append(repos, groups...)
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeTopics(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var topics []string
+	for _, repo := range cachedRepositories() {
+		topics = append(topics, repo.Topics...)
+	}
+	return matchingCompletions(topics, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// cachedRepositories returns the repositories of every listing in the
+// metadata cache, however old it is
+func cachedRepositories() []*scm.Repository {
+	cfg, err := config.ReadStored()
+	if err != nil {
+		return nil
+	}
+	dir, err := cfg.CacheDir()
+	if err != nil {
+		return nil
+	}
+	var repos []*scm.Repository
+	for _, entry := range cache.NewStore(filepath.Join(dir, "metadata"), 0).LoadAll() {
+		repos = append(repos, entry.Repositories...)
+	}
+	return repos
+}
+
+// matchingCompletions returns the distinct values starting with toComplete,
+// sorted
+func matchingCompletions(values []string, toComplete string) []cobra.Completion {
+	seen := make(map[string]bool)
+	var completions []cobra.Completion
+	for _, value := range values {
+		if value == "" || seen[value] || !strings.HasPrefix(value, toComplete) {
+			continue
+		}
+		seen[value] = true
+		completions = append(completions, value)
+	}
+	sort.Strings(completions)
+	return completions
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/cache"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFlagCompletions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cacheDir := filepath.Join(home, "cache")
+	configYAML := `providers:
+  - name: work
+    type: gitlab
+    url: https://gitlab.example.com
+  - name: personal
+    type: github
+    url: https://github.com
+cache:
+  dir: ` + cacheDir + `
+workspaces:
+  payments:
+    repos: [backend/api]
+  platform:
+    groups: [infra]
+`
+	if err := os.WriteFile(filepath.Join(home, ".gitstuff.yaml"), []byte(configYAML), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store := cache.NewStore(filepath.Join(cacheDir, "metadata"), 0)
+	if err := store.Save("work", []*scm.Repository{
+		{FullPath: "backend/api", Topics: []string{"go", "payments"}},
+		{FullPath: "backend/services/billing", Topics: []string{"go"}},
+		{FullPath: "standalone"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("personal", []*scm.Repository{{FullPath: "infra/terraform", Topics: []string{"terraform"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		complete   cobra.CompletionFunc
+		toComplete string
+		want       []cobra.Completion
+	}{
+		{"groups", completeGroups, "", []cobra.Completion{"backend", "backend/services", "infra"}},
+		{"groups by prefix", completeGroups, "backend/", []cobra.Completion{"backend/services"}},
+		{"providers", completeProviders, "p", []cobra.Completion{"personal\tgithub https://github.com"}},
+		{"workspaces", completeWorkspaces, "", []cobra.Completion{"payments", "platform"}},
+		{"topics", completeTopics, "", []cobra.Completion{"go", "payments", "terraform"}},
+		{"repositories", completeRepositories, "backend/", []cobra.Completion{"backend/api", "backend/services/billing"}},
+		{"repositories and groups", completeRepositoriesAndGroups, "backend/", []cobra.Completion{"backend/api", "backend/services", "backend/services/billing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := tt.complete(nil, nil, tt.toComplete)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("Expected no file completion, got directive %d", directive)
+			}
+		})
+	}
+}
+
+func TestFlagCompletions_NoConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if got, _ := completeGroups(nil, nil, ""); len(got) != 0 {
+		t.Errorf("Expected no groups without a config file, got %q", got)
+	}
+	if got, _ := completeProviders(nil, nil, ""); len(got) != 0 {
+		t.Errorf("Expected no providers without a config file, got %q", got)
+	}
+}
+
+func TestRegisterFlagCompletions(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{Use: "sub", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(sub)
+	sub.Flags().String("group", "", "")
+	sub.Flags().String("provider", "", "")
+	sub.Flags().String("name", "", "")
+	fixed := cobra.FixedCompletions([]cobra.Completion{"gitlab"}, cobra.ShellCompDirectiveNoFileComp)
+	if err := sub.RegisterFlagCompletionFunc("provider", fixed); err != nil {
+		t.Fatal(err)
+	}
+
+	registerFlagCompletions(root)
+
+	if _, ok := sub.GetFlagCompletionFunc("group"); !ok {
+		t.Error("Expected --group to be completed")
+	}
+	if _, ok := sub.GetFlagCompletionFunc("name"); ok {
+		t.Error("Expected --name not to be completed")
+	}
+	complete, _ := sub.GetFlagCompletionFunc("provider")
+	if got, _ := complete(sub, nil, ""); !slices.Equal(got, []cobra.Completion{"gitlab"}) {
+		t.Errorf("Expected the existing --provider completion to be kept, got %q", got)
+	}
+}
+
+func TestArgCompletions(t *testing.T) {
+	for path := range argCompletions {
+		cmd, _, err := rootCmd.Find(strings.Fields(path)[1:])
+		if err != nil || cmd.CommandPath() != path {
+			t.Errorf("argCompletions has %q, which is not a command", path)
+		}
+	}
+
+	root := &cobra.Command{Use: "gitstuff"}
+	sync := &cobra.Command{Use: "sync", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(sync)
+	registerArgCompletions(root)
+	if sync.ValidArgsFunction == nil {
+		t.Fatal("Expected the group argument of sync to be completed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	if got, directive := sync.ValidArgsFunction(sync, []string{"backend"}, ""); got != nil || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("Expected nothing for a second argument, got %q", got)
+	}
+}
@@ -2,12 +2,20 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"gitstuff/internal/config"
+	"gitstuff/internal/httpclient"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
 	"gitstuff/internal/verbosity"
 
 	"github.com/spf13/cobra"
@@ -17,22 +25,332 @@ import (
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Configure SCM provider settings",
-	Long:  `Configure GitLab or GitHub connection settings interactively.`,
-	RunE:  runConfig,
+	Long: `Configure GitLab or GitHub connection settings interactively.
+
+When --url is given without --provider, the provider type is detected by
+probing the API of the instance, so this is enough to add a provider:
+
+  gitstuff config --url git.internal.example.com --token <token>`,
+	RunE: runConfig,
 }
 
 func init() {
 	rootCmd.AddCommand(configCmd)
-	configCmd.Flags().StringP("provider", "p", "", "Provider type (gitlab or github)")
+	configCmd.Flags().StringP("provider", "p", "", "Provider type (gitlab or github); detected from --url when omitted")
 	configCmd.Flags().StringP("name", "n", "", "Provider name (identifier)")
 	configCmd.Flags().StringP("url", "u", "", "Provider instance URL")
 	configCmd.Flags().StringP("token", "t", "", "Access token")
 	configCmd.Flags().StringP("base-dir", "d", "", "Base directory for cloned repositories")
 	configCmd.Flags().BoolP("insecure", "k", false, "Skip SSL certificate verification (for self-signed certificates)")
 	configCmd.Flags().StringP("group", "g", "", "Default group/organization to filter repositories (optional)")
+	configCmd.Flags().Bool("keyring", false, "Store the token in the OS keychain instead of the config file")
+	configCmd.Flags().String("token-env", "", "Read the token from this environment variable when loading the config")
+	configCmd.Flags().String("token-cmd", "", "Read the token from the output of this shell command when loading the config")
+	configCmd.Flags().String("protocol", "", "Clone this provider's repositories over ssh or https unless --ssh or --https is given")
+	configCmd.Flags().String("ssh-host", "", "Host to use in SSH clone URLs instead of the provider's, such as an alias from ~/.ssh/config")
+	configCmd.MarkFlagsMutuallyExclusive("token", "token-env", "token-cmd")
+	configCmd.MarkFlagsMutuallyExclusive("keyring", "token-env", "token-cmd")
+	_ = configCmd.RegisterFlagCompletionFunc("provider", cobra.FixedCompletions([]cobra.Completion{"gitlab", "github"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = configCmd.RegisterFlagCompletionFunc("protocol", cobra.FixedCompletions([]cobra.Completion{"ssh", "https"}, cobra.ShellCompDirectiveNoFileComp))
+
+	configCmd.AddCommand(configMigrateTokensCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configRemoveCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configTestCmd)
+	configCmd.AddCommand(configProfilesCmd)
+
+	configTestCmd.Flags().Bool("json", false, "Output the report as JSON")
+
+	configEditCmd.Flags().StringP("provider", "p", "", "Provider type (gitlab or github)")
+	configEditCmd.Flags().StringP("url", "u", "", "Provider instance URL")
+	configEditCmd.Flags().StringP("token", "t", "", "Access token")
+	configEditCmd.Flags().BoolP("insecure", "k", false, "Skip SSL certificate verification (for self-signed certificates)")
+	configEditCmd.Flags().StringP("group", "g", "", "Default group/organization to filter repositories (empty to clear)")
+	configEditCmd.Flags().Bool("keyring", false, "Store the token in the OS keychain instead of the config file")
+	configEditCmd.Flags().String("token-env", "", "Read the token from this environment variable when loading the config")
+	configEditCmd.Flags().String("token-cmd", "", "Read the token from the output of this shell command when loading the config")
+	configEditCmd.Flags().String("protocol", "", "Clone this provider's repositories over ssh or https (empty to clear)")
+	configEditCmd.Flags().String("ssh-host", "", "Host to use in SSH clone URLs instead of the provider's (empty to clear)")
+	configEditCmd.MarkFlagsMutuallyExclusive("token-env", "token-cmd", "keyring")
+	_ = configEditCmd.RegisterFlagCompletionFunc("provider", cobra.FixedCompletions([]cobra.Completion{"gitlab", "github"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = configEditCmd.RegisterFlagCompletionFunc("protocol", cobra.FixedCompletions([]cobra.Completion{"ssh", "https"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured providers with masked tokens",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.ReadStored()
+		if err != nil {
+			return err
+		}
+		configPath, err := config.FilePath()
+		if err != nil {
+			return err
+		}
+		displayProviders(stdout, cfg, configPath)
+		return nil
+	},
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the profiles that have a config file",
+	Long: `List the default configuration and every profile that has a config file,
+marking the one in use. Select a profile with --profile or GITSTUFF_PROFILE;
+'gitstuff --profile <name> config' creates a new one.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := config.Profiles()
+		if err != nil {
+			return err
+		}
+		return displayProfiles(stdout, names, config.Profile())
+	},
+}
+
+// displayProfiles lists the default configuration and the named profiles
+// with their config files, marking active
+func displayProfiles(w io.Writer, names []string, active string) error {
+	defaultPath, err := config.DefaultFilePath()
+	if err != nil {
+		return err
+	}
+	mark := func(name string) string {
+		if name == active {
+			return "*"
+		}
+		return " "
+	}
+	fmt.Fprintf(w, "%s %-12s %s\n", mark(""), "(default)", defaultPath)
+	for _, name := range names {
+		path, err := config.ProfilePath(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s %-12s %s\n", mark(name), name, path)
+	}
+	return nil
+}
+
+var configRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a provider by name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkConfigWritable(); err != nil {
+			return err
+		}
+		if err := config.RemoveProvider(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "🗑️  %s\n", i18n.T("config.removed", args[0]))
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Update fields of an existing provider",
+	Long: `Update fields of an existing provider non-interactively. Only the flags that
+are given are changed.
+
+Examples:
+  gitstuff config edit work --group backend-team
+  gitstuff config edit work --token glpat-new-token
+  gitstuff config edit personal --token-env GITHUB_TOKEN`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigEdit,
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	if flags.NFlag() == 0 {
+		return fmt.Errorf("nothing to change (see 'gitstuff config edit --help')")
+	}
+	if err := checkConfigWritable(); err != nil {
+		return err
+	}
+
+	err := config.UpdateProvider(args[0], func(provider *config.ProviderConfig) {
+		if flags.Changed("provider") {
+			provider.Type, _ = flags.GetString("provider")
+		}
+		if flags.Changed("url") {
+			provider.URL, _ = flags.GetString("url")
+		}
+		if flags.Changed("insecure") {
+			provider.Insecure, _ = flags.GetBool("insecure")
+		}
+		if flags.Changed("group") {
+			provider.Group, _ = flags.GetString("group")
+		}
+		if flags.Changed("protocol") {
+			provider.Protocol, _ = flags.GetString("protocol")
+		}
+		if flags.Changed("ssh-host") {
+			provider.SSHHost, _ = flags.GetString("ssh-host")
+		}
+
+		// Switching to another token source replaces the previous one
+		if flags.Changed("token-env") {
+			provider.TokenEnv, _ = flags.GetString("token-env")
+			provider.TokenCmd, provider.TokenSource, provider.Token = "", "", ""
+		}
+		if flags.Changed("token-cmd") {
+			provider.TokenCmd, _ = flags.GetString("token-cmd")
+			provider.TokenEnv, provider.TokenSource, provider.Token = "", "", ""
+		}
+		if useKeyring, _ := flags.GetBool("keyring"); useKeyring {
+			provider.TokenSource = config.TokenSourceKeyring
+			provider.TokenEnv, provider.TokenCmd = "", ""
+		}
+		if flags.Changed("token") {
+			provider.Token, _ = flags.GetString("token")
+			provider.TokenEnv, provider.TokenCmd = "", ""
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "✅ %s\n", i18n.T("config.updated", args[0]))
+	return nil
+}
+
+var configTestCmd = &cobra.Command{
+	Use:   "test [provider-name]",
+	Short: "Test provider connectivity and credentials",
+	Long: `Validate configured providers by calling the current user endpoint with each
+provider's token. Reports whether the provider is reachable, the authenticated
+user, the token's scopes (when the provider reports them) and the API rate
+limit, with a hint on how to fix common failures.
+
+Examples:
+  gitstuff config test          # Test every provider
+  gitstuff config test work     # Test a single provider`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigTest,
+}
+
+func runConfigTest(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	providers, err := selectProviders(cfg.Providers, args)
+	if err != nil {
+		return err
+	}
+	return runProviderChecks(cmd, cfg, providers)
+}
+
+// selectProviders returns the provider named in args, or all providers
+func selectProviders(providers []config.ProviderConfig, args []string) ([]config.ProviderConfig, error) {
+	if len(args) == 0 {
+		return providers, nil
+	}
+	for _, provider := range providers {
+		if provider.Name == args[0] {
+			return []config.ProviderConfig{provider}, nil
+		}
+	}
+	return nil, fmt.Errorf("provider %s not found (see 'gitstuff config list')", args[0])
+}
+
+// displayProviders prints the configured providers without revealing tokens
+func displayProviders(w io.Writer, cfg *config.Config, configPath string) {
+	if len(cfg.Providers) == 0 {
+		fmt.Fprintln(w, i18n.T("config.no_providers", configPath))
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n\n", i18n.T("config.providers_header", configPath))
+	for _, provider := range cfg.Providers {
+		fmt.Fprintf(w, "📡 %s [%s]\n", provider.Name, provider.Type)
+		fmt.Fprintf(w, "   %s\n", i18n.T("config.field_url", provider.URL))
+		fmt.Fprintf(w, "   %s\n", i18n.T("config.field_token", describeToken(provider)))
+		if provider.Group != "" {
+			fmt.Fprintf(w, "   %s\n", i18n.T("config.field_group", provider.Group))
+		}
+		if provider.Protocol != "" {
+			fmt.Fprintf(w, "   %s\n", i18n.T("config.field_protocol", provider.Protocol))
+		}
+		if provider.SSHHost != "" {
+			fmt.Fprintf(w, "   %s\n", i18n.T("config.field_ssh_host", provider.SSHHost))
+		}
+		if provider.Insecure {
+			fmt.Fprintf(w, "   %s\n", i18n.T("config.field_insecure"))
+		}
+		fmt.Fprintln(w)
+	}
+	if cfg.Local.BaseDir != "" {
+		fmt.Fprintln(w, i18n.T("config.field_base_dir", cfg.Local.BaseDir))
+	}
+	if len(cfg.Workspaces) > 0 {
+		names := make([]string, 0, len(cfg.Workspaces))
+		for name := range cfg.Workspaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintln(w, i18n.T("config.field_workspaces", strings.Join(names, ", ")))
+	}
+}
+
+// describeToken says where a provider's token comes from, showing at most the
+// first four characters of a stored token
+func describeToken(provider config.ProviderConfig) string {
+	switch {
+	case provider.TokenSource == config.TokenSourceKeyring:
+		return i18n.T("config.token_keychain")
+	case provider.TokenEnv != "":
+		return "$" + provider.TokenEnv
+	case provider.TokenCmd != "":
+		return i18n.T("config.token_command", provider.TokenCmd)
+	case provider.Token == "":
+		return i18n.T("config.token_not_set")
+	case len(provider.Token) <= 8:
+		return "********"
+	default:
+		return provider.Token[:4] + "********"
+	}
+}
+
+var configMigrateTokensCmd = &cobra.Command{
+	Use:   "migrate-tokens",
+	Short: "Move plaintext tokens from the config file into the OS keychain",
+	Long: `Store every provider token that is currently kept in plaintext in
+the config file in the operating system keychain (macOS Keychain, Windows
+Credential Manager or Secret Service), and switch those providers to
+token_source: keyring.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkConfigWritable(); err != nil {
+			return err
+		}
+		migrated, err := config.MigrateTokensToKeyring()
+		if err != nil {
+			return err
+		}
+		if len(migrated) == 0 {
+			fmt.Fprintln(stdout, i18n.T("config.no_plaintext_tokens"))
+			return nil
+		}
+		for _, name := range migrated {
+			fmt.Fprintf(stdout, "🔐 %s\n", i18n.T("config.token_migrated", name))
+		}
+		return nil
+	},
 }
 
 func runConfig(cmd *cobra.Command, args []string) error {
+	if err := checkConfigWritable(); err != nil {
+		return err
+	}
+
 	// Check if flags were provided for non-interactive setup
 	providerType, _ := cmd.Flags().GetString("provider")
 	name, _ := cmd.Flags().GetString("name")
@@ -41,6 +359,11 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	baseDir, _ := cmd.Flags().GetString("base-dir")
 	insecure, _ := cmd.Flags().GetBool("insecure")
 	group, _ := cmd.Flags().GetString("group")
+	useKeyring, _ := cmd.Flags().GetBool("keyring")
+	tokenEnv, _ := cmd.Flags().GetString("token-env")
+	tokenCmd, _ := cmd.Flags().GetString("token-cmd")
+	protocol, _ := cmd.Flags().GetString("protocol")
+	sshHost, _ := cmd.Flags().GetString("ssh-host")
 
 	if providerType != "" {
 		verbosity.Debug("Running config in non-interactive mode for provider: %s", providerType)
@@ -50,12 +373,25 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 	reader := bufio.NewReader(os.Stdin)
 
+	if providerType == "" && url != "" {
+		detected, err := detectProviderType(commandContext(cmd), url, insecure)
+		switch {
+		case err != nil:
+			fmt.Fprintf(stdout, "⚠️  %s\n", i18n.T("config.detect_failed", redact.Error(err)))
+		case detected == "gitea":
+			return fmt.Errorf("%s is a Gitea instance, which is not supported", url)
+		default:
+			fmt.Fprintf(stdout, "🔎 %s\n", i18n.T("config.detected", providerDisplayNames[detected], url))
+			providerType = detected
+		}
+	}
+
 	// Interactive mode if no provider type specified
 	if providerType == "" {
-		fmt.Println("Available SCM providers:")
-		fmt.Println("1. GitLab")
-		fmt.Println("2. GitHub")
-		fmt.Print("Select a provider (1-2): ")
+		fmt.Fprintln(stdout, i18n.T("config.available_providers"))
+		fmt.Fprintln(stdout, "1. GitLab")
+		fmt.Fprintln(stdout, "2. GitHub")
+		fmt.Fprintf(stdout, "%s ", i18n.T("config.select_provider"))
 
 		choice, _ := reader.ReadString('\n')
 		choice = strings.TrimSpace(choice)
@@ -77,7 +413,7 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 	// Get provider name
 	if name == "" {
-		fmt.Printf("Provider name (identifier for this %s instance): ", providerType)
+		fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_name", providerType))
 		name, _ = reader.ReadString('\n')
 		name = strings.TrimSpace(name)
 		if name == "" {
@@ -88,9 +424,9 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	// Get URL
 	if url == "" {
 		if providerType == "gitlab" {
-			fmt.Print("GitLab URL (e.g., https://gitlab.com or gitlab.example.com): ")
+			fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_gitlab_url"))
 		} else {
-			fmt.Print("GitHub URL (leave blank for github.com or enter GitHub Enterprise URL): ")
+			fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_github_url"))
 		}
 		url, _ = reader.ReadString('\n')
 		url = strings.TrimSpace(url)
@@ -101,30 +437,38 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get token
-	if token == "" {
+	if token == "" && tokenEnv == "" && tokenCmd == "" {
 		if providerType == "gitlab" {
-			fmt.Print("GitLab Access Token: ")
+			fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_gitlab_token"))
 		} else {
-			fmt.Print("GitHub Personal Access Token: ")
+			fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_github_token"))
 		}
 		tokenBytes, err := term.ReadPassword(syscall.Stdin)
 		if err != nil {
 			return fmt.Errorf("failed to read token: %w", err)
 		}
 		token = string(tokenBytes)
-		fmt.Println()
+		fmt.Fprintln(stdout)
+
+		if token == "" {
+			newProvider := config.ProviderConfig{Name: name, Type: providerType, URL: url, Insecure: insecure}
+			token, err = createToken(commandContext(cmd), newProvider, "", false, reader, tokenClient(""))
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	// Get base directory
 	if baseDir == "" && !cmd.Flags().Changed("base-dir") {
-		fmt.Print("Base directory for repositories (default: ~/gitstuff-repos): ")
+		fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_base_dir"))
 		baseDir, _ = reader.ReadString('\n')
 		baseDir = strings.TrimSpace(baseDir)
 	}
 
 	// Get insecure setting (mainly for GitLab)
 	if !insecure && !cmd.Flags().Changed("insecure") && providerType == "gitlab" {
-		fmt.Print("Skip SSL certificate verification? (y/N): ")
+		fmt.Fprintf(stdout, "%s (y/N): ", i18n.T("config.prompt_insecure"))
 		response, _ := reader.ReadString('\n')
 		response = strings.ToLower(strings.TrimSpace(response))
 		insecure = response == "y" || response == "yes"
@@ -133,22 +477,37 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	// Get group/organization filter
 	if group == "" && !cmd.Flags().Changed("group") {
 		if providerType == "gitlab" {
-			fmt.Print("Default GitLab group to filter repositories (optional, leave blank for all): ")
+			fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_gitlab_group"))
 		} else {
-			fmt.Print("Default GitHub organization to filter repositories (optional, leave blank for all): ")
+			fmt.Fprintf(stdout, "%s ", i18n.T("config.prompt_github_org"))
 		}
 		group, _ = reader.ReadString('\n')
 		group = strings.TrimSpace(group)
 	}
 
 	// Add the provider
-	err := config.AddProvider(name, providerType, url, token, baseDir, insecure, group)
+	provider := config.ProviderConfig{
+		Name:     name,
+		Type:     providerType,
+		URL:      url,
+		Token:    token,
+		Insecure: insecure,
+		Group:    group,
+		TokenEnv: tokenEnv,
+		TokenCmd: tokenCmd,
+		Protocol: protocol,
+		SSHHost:  sshHost,
+	}
+	if useKeyring {
+		provider.TokenSource = config.TokenSourceKeyring
+	}
+	err := config.AddProviderConfig(provider, baseDir)
 	if err != nil {
 		return err
 	}
 
 	// Ask if user wants to add another provider
-	fmt.Print("Would you like to add another provider? (y/N): ")
+	fmt.Fprintf(stdout, "%s (y/N): ", i18n.T("config.prompt_another"))
 	response, _ := reader.ReadString('\n')
 	response = strings.ToLower(strings.TrimSpace(response))
 
@@ -158,6 +517,24 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		return runConfig(cmd, args)
 	}
 
-	fmt.Println("Configuration complete!")
+	fmt.Fprintln(stdout, i18n.T("config.complete"))
 	return nil
 }
+
+// providerDetectTimeout bounds how long detection may hold up the config
+// command; every probe is a single small request
+const providerDetectTimeout = 15 * time.Second
+
+var providerDisplayNames = map[string]string{
+	"github": "GitHub",
+	"gitlab": "GitLab",
+}
+
+// detectProviderType finds out which product runs at rawURL, for when the
+// user did not say
+func detectProviderType(ctx context.Context, rawURL string, insecure bool) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, providerDetectTimeout)
+	defer cancel()
+	verbosity.Debug("Detecting provider type of %s", rawURL)
+	return scm.DetectProviderType(ctx, httpclient.New(httpclient.Options{Insecure: insecure}), rawURL)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+)
+
+func TestDescribeToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider config.ProviderConfig
+		want     string
+	}{
+		{name: "plaintext", provider: config.ProviderConfig{Token: "glpat-abcdefghijkl"}, want: "glpa********"},
+		{name: "short", provider: config.ProviderConfig{Token: "abc"}, want: "********"},
+		{name: "missing", provider: config.ProviderConfig{}, want: "(not set)"},
+		{name: "keyring", provider: config.ProviderConfig{TokenSource: config.TokenSourceKeyring}, want: "(OS keychain)"},
+		{name: "env", provider: config.ProviderConfig{TokenEnv: "GITLAB_TOKEN"}, want: "$GITLAB_TOKEN"},
+		{name: "command", provider: config.ProviderConfig{TokenCmd: "pass show gitlab"}, want: "(output of: pass show gitlab)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeToken(tt.provider); got != tt.want {
+				t.Errorf("describeToken() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayProviders(t *testing.T) {
+	cfg := &config.Config{
+		Providers: []config.ProviderConfig{
+			{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com", Token: "glpat-secret-token", Group: "backend", Insecure: true},
+		},
+		Local:      config.LocalConfig{BaseDir: "/src"},
+		Workspaces: map[string]config.Workspace{"oncall": {}, "frontend": {}},
+	}
+
+	var buf bytes.Buffer
+	displayProviders(&buf, cfg, "/home/user/.gitstuff.yaml")
+	output := buf.String()
+
+	if strings.Contains(output, "secret-token") {
+		t.Errorf("Expected token to be masked, got:\n%s", output)
+	}
+	for _, want := range []string{"work [gitlab]", "https://gitlab.example.com", "glpa********", "Group: backend", "Insecure: true", "Base directory: /src", "Workspaces: frontend, oncall"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	displayProviders(&buf, &config.Config{}, "/home/user/.gitstuff.yaml")
+	if !strings.Contains(buf.String(), "No providers configured") {
+		t.Errorf("Expected empty message, got %q", buf.String())
+	}
+}
+
+func TestDisplayProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(config.EnvFilePath, "")
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+
+	var buf bytes.Buffer
+	if err := displayProfiles(&buf, []string{"oss", "work"}, "work"); err != nil {
+		t.Fatalf("displayProfiles() error = %v", err)
+	}
+	dir := filepath.Join(home, "xdg", "gitstuff")
+	want := "  (default)    " + filepath.Join(dir, "config.yaml") + "\n" +
+		"  oss          " + filepath.Join(dir, "profiles", "oss.yaml") + "\n" +
+		"* work         " + filepath.Join(dir, "profiles", "work.yaml") + "\n"
+	if buf.String() != want {
+		t.Errorf("displayProfiles() =\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestSelectProviders(t *testing.T) {
+	providers := []config.ProviderConfig{{Name: "work"}, {Name: "home"}}
+
+	all, err := selectProviders(providers, nil)
+	if err != nil || len(all) != 2 {
+		t.Errorf("Expected all providers, got %v (%v)", all, err)
+	}
+
+	one, err := selectProviders(providers, []string{"home"})
+	if err != nil || len(one) != 1 || one[0].Name != "home" {
+		t.Errorf("Expected only home, got %v (%v)", one, err)
+	}
+
+	if _, err := selectProviders(providers, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
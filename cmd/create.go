@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create <provider> <path>",
+	Short: "Create a repository on a provider",
+	Long: `Create an empty repository at path on the provider with this name or type,
+as a GitLab project or a GitHub repository. The path includes its group or
+organization; a path without one is created under your own account.
+
+With --push, the current directory (or --dir) is pushed to the new repository as its
+default branch and gets it as its origin remote. A directory that is not a
+git repository yet is initialized, and a repository without commits gets
+one with all of its files.
+
+Examples:
+  gitstuff create work platform/tools/deploy-bot --description "Deploys things"
+  gitstuff create github my-org/site --visibility public --push
+  gitstuff create work team/api --default-branch trunk --push --https`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCreate,
+}
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+	createCmd.Flags().String("visibility", "private", "Visibility of the repository: "+strings.Join(scm.Visibilities, ", "))
+	createCmd.Flags().StringP("description", "d", "", "Description of the repository")
+	createCmd.Flags().String("default-branch", "", "Default branch (default: the provider's, or the current branch with --push)")
+	createCmd.Flags().Bool("push", false, "Initialize the current directory if needed and push it to the new repository")
+	createCmd.Flags().String("dir", ".", "With --push, the directory to push")
+	createCmd.Flags().Bool("https", false, "With --push, use the HTTPS URL for origin instead of SSH")
+}
+
+// initialCommitMessage is the message of the commit made with --push in a
+// repository without commits
+const initialCommitMessage = "Initial commit"
+
+func runCreate(cmd *cobra.Command, args []string) error {
+	provider, fullPath := args[0], strings.Trim(args[1], "/")
+	visibility, _ := cmd.Flags().GetString("visibility")
+	if !slices.Contains(scm.Visibilities, visibility) {
+		return fmt.Errorf("unsupported --visibility %q (expected one of %s)", visibility, strings.Join(scm.Visibilities, ", "))
+	}
+	description, _ := cmd.Flags().GetString("description")
+	defaultBranch, _ := cmd.Flags().GetString("default-branch")
+	push, _ := cmd.Flags().GetBool("push")
+	dir, _ := cmd.Flags().GetString("dir")
+	useHTTPS, _ := cmd.Flags().GetBool("https")
+
+	if err := checkWritable("create repositories"); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+	if len(selected) > 1 {
+		return fmt.Errorf("%d providers match '%s', pass the name of one", len(selected), provider)
+	}
+	creator, ok := scm.Unwrap(selected[0]).(scm.RepositoryCreator)
+	if !ok {
+		return fmt.Errorf("provider type %s does not support creating repositories", selected[0].GetProviderType())
+	}
+
+	// Check the directory before creating anything, so a push that cannot
+	// work does not leave an empty repository behind
+	var local *pushTarget
+	if push {
+		if local, err = preparePush(dir, defaultBranch); err != nil {
+			return err
+		}
+		defaultBranch = local.branch
+	}
+
+	repo, err := creator.CreateRepository(commandContext(cmd), scm.NewRepository{
+		FullPath:      fullPath,
+		Description:   description,
+		Visibility:    visibility,
+		DefaultBranch: defaultBranch,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ %s\n", i18n.T("create.created", repo.FullPath, visibility))
+	if repo.WebURL != "" {
+		fmt.Fprintf(stdout, "   %s\n", repo.WebURL)
+	}
+	if local == nil {
+		return nil
+	}
+
+	remoteURL := cloneURLFor(cfg, repo, !useHTTPS)
+	if err := local.push(remoteURL); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("created %s but could not push to it: %w", repo.FullPath, redact.Error(err))
+	}
+	fmt.Fprintf(stdout, "📤 %s\n", i18n.T("create.pushed", local.branch, redact.String(remoteURL)))
+	return nil
+}
+
+// pushTarget is a local directory to push to a new repository
+type pushTarget struct {
+	path   string // Top level of the working tree, or the directory to initialize
+	branch string
+	isRepo bool
+}
+
+// preparePush checks that dir can be pushed to a new repository as branch,
+// which defaults to its current branch, or main for a directory that is not
+// a repository yet
+func preparePush(dir, branch string) (*pushTarget, error) {
+	topLevel, err := git.TopLevel(dir)
+	if err != nil {
+		if branch == "" {
+			branch = "main"
+		}
+		return &pushTarget{path: dir, branch: branch}, nil
+	}
+
+	if _, err := git.RemoteURL(topLevel, "origin"); err == nil {
+		return nil, fmt.Errorf("%s already has an origin remote", topLevel)
+	}
+	current, err := git.CurrentBranch(topLevel)
+	if err != nil {
+		return nil, err
+	}
+	if branch != "" && branch != current {
+		return nil, fmt.Errorf("the current branch is %s, not the --default-branch %s", current, branch)
+	}
+	return &pushTarget{path: topLevel, branch: current, isRepo: true}, nil
+}
+
+// push initializes and commits the directory as needed, adds remoteURL as
+// origin and pushes the branch to it
+func (p *pushTarget) push(remoteURL string) error {
+	if !p.isRepo {
+		if err := git.InitRepository(p.path, p.branch); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "🆕 %s\n", i18n.T("create.initialized", p.path))
+	}
+	if git.ResolveRef(p.path, "HEAD") == "" {
+		if err := git.CommitAll(p.path, initialCommitMessage); err != nil {
+			return err
+		}
+	}
+	if _, err := git.EnsureRemote(p.path, "origin", remoteURL); err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	if err := git.PushBranch(p.path, p.branch, &output, &output); err != nil {
+		return withOutput(err, output.String())
+	}
+	return nil
+}
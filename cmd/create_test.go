@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+// mockCreateClient creates repositories that live at remote
+type mockCreateClient struct {
+	mockSCMClient
+	remote  string
+	created []scm.NewRepository
+}
+
+func (m *mockCreateClient) CreateRepository(ctx context.Context, repo scm.NewRepository) (*scm.Repository, error) {
+	m.created = append(m.created, repo)
+	return &scm.Repository{
+		FullPath:    repo.FullPath,
+		CloneURL:    m.remote,
+		SSHCloneURL: m.remote,
+		WebURL:      "https://gitlab.example.com/" + repo.FullPath,
+		Provider:    m.providerType,
+	}, nil
+}
+
+func TestCommand_Create(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	for _, key := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(key+"_NAME", "Test User")
+		t.Setenv(key+"_EMAIL", "test@example.com")
+	}
+
+	tempDir := t.TempDir()
+	remote := filepath.Join(tempDir, "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	project := filepath.Join(tempDir, "project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+	client := &mockCreateClient{mockSCMClient: mockSCMClient{providerType: "gitlab"}, remote: remote}
+	clients := map[string]scm.Client{"work": client}
+
+	out, err := runCommand(t, cfg, clients, "create", "work", "team/tool", "--visibility", "internal", "-d", "Tooling", "--default-branch", "trunk", "--push", "--dir", project)
+	if err != nil {
+		t.Fatalf("create failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"Created team/tool (internal)", "https://gitlab.example.com/team/tool", "Initialized a git repository in " + project, "Pushed trunk to " + remote} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if len(client.created) != 1 || client.created[0] != (scm.NewRepository{FullPath: "team/tool", Description: "Tooling", Visibility: "internal", DefaultBranch: "trunk"}) {
+		t.Errorf("Unexpected repositories created: %+v", client.created)
+	}
+	if git.ResolveRef(remote, "refs/heads/trunk") == "" {
+		t.Error("Expected trunk to be pushed")
+	}
+	if url, err := git.RemoteURL(project, "origin"); err != nil || url != remote {
+		t.Errorf("Expected origin to be %s, got %q (%v)", remote, url, err)
+	}
+
+	// The project has an origin now, so pushing it again is refused before
+	// anything is created
+	if _, err := runCommand(t, cfg, clients, "create", "work", "team/other", "--push", "--dir", project, "--default-branch", ""); err == nil || !strings.Contains(err.Error(), "already has an origin remote") {
+		t.Errorf("Expected an existing origin error, got %v", err)
+	}
+	if len(client.created) != 1 {
+		t.Errorf("Expected no repository to be created, got %+v", client.created)
+	}
+
+	if _, err := runCommand(t, cfg, clients, "create", "work", "team/other", "--visibility", "secret"); err == nil || !strings.Contains(err.Error(), "unsupported --visibility") {
+		t.Errorf("Expected an unsupported visibility error, got %v", err)
+	}
+	plain := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab"}}
+	if _, err := runCommand(t, cfg, plain, "create", "work", "team/other", "--visibility", "private", "--push=false"); err == nil || !strings.Contains(err.Error(), "does not support creating repositories") {
+		t.Errorf("Expected an unsupported provider error, got %v", err)
+	}
+}
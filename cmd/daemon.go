@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/verbosity"
+	"gitstuff/internal/watch"
+
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon [group-path]",
+	Short: "Keep repositories in sync by running sync on a schedule",
+	Long: `Run as a long-lived process that syncs repositories every --interval, the
+same way as 'gitstuff sync'. The config file is read again before every run,
+and repository listings are always fetched fresh from the providers.
+
+Each run is logged with a summary of what was cloned, updated, skipped and
+failed; -v also logs every repository. With --listen, the status of the
+daemon and its last run is served as JSON at /status, and /healthz answers
+for liveness checks. With --watch as well, the clones are watched for
+changes and whether each has uncommitted changes is served at
+/repositories, without running git status on every request.
+
+Examples:
+  gitstuff daemon                                  # Sync every 30 minutes
+  gitstuff daemon --interval 1h backend            # Sync one group every hour
+  gitstuff daemon --listen 127.0.0.1:8321 -j 4     # Serve the status over HTTP`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().Duration("interval", 30*time.Minute, "Time between the start of one sync run and the next")
+	daemonCmd.Flags().String("listen", "", "Serve the daemon status over HTTP at this address, e.g. 127.0.0.1:8321")
+	daemonCmd.Flags().Bool("watch", false, "Watch clones for changes and serve their status at /repositories (needs --listen)")
+	daemonCmd.Flags().Bool("https", false, "Use HTTPS instead of SSH when cloning")
+	daemonCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to sync in parallel")
+	daemonCmd.Flags().Bool("move-renamed", false, "Move clones of renamed or transferred repositories")
+	daemonCmd.Flags().Bool("protocol-fallback", false, "Retry failed clones over the other protocol (default: git.protocol_fallback)")
+	daemonCmd.Flags().Bool("checkout-default", false, "Switch clean repositories to their default branch before pulling")
+	addPullStrategyFlags(daemonCmd)
+	addRetryFlags(daemonCmd)
+	addRepoFilterFlags(daemonCmd)
+	addLimitRateFlag(daemonCmd)
+}
+
+// minDaemonInterval keeps the daemon from hammering the provider APIs
+const minDaemonInterval = time.Minute
+
+// daemonRun is the outcome of one sync run, as served at /status
+type daemonRun struct {
+	Number       int             `json:"number"`
+	Started      time.Time       `json:"started"`
+	Finished     time.Time       `json:"finished"`
+	Repositories int             `json:"repositories"`
+	Cloned       int             `json:"cloned"`
+	Updated      int             `json:"updated"`
+	Skipped      int             `json:"skipped"`
+	Failed       int             `json:"failed"`
+	Interrupted  int             `json:"interrupted"`
+	Error        string          `json:"error,omitempty"`
+	Failures     []daemonFailure `json:"failures,omitempty"`
+
+	VisibilityChanges []daemonVisibilityChange `json:"visibility_changes,omitempty"`
+	Removed           []daemonRemoval          `json:"removed,omitempty"`
+}
+
+// daemonRemoval is a clone or backup removed by retention
+type daemonRemoval struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+type daemonVisibilityChange struct {
+	Repository string `json:"repository"`
+	Provider   string `json:"provider"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Exposed    bool   `json:"exposed"`
+}
+
+type daemonFailure struct {
+	Repository string `json:"repository"`
+	Provider   string `json:"provider"`
+	Error      string `json:"error"`
+}
+
+type daemonState struct {
+	Started  time.Time  `json:"started"`
+	Interval string     `json:"interval"`
+	Running  bool       `json:"running"`
+	Runs     int        `json:"runs"`
+	NextRun  *time.Time `json:"next_run,omitempty"`
+	LastRun  *daemonRun `json:"last_run,omitempty"`
+}
+
+// daemonStatus is the daemon's state, shared with the status endpoint
+type daemonStatus struct {
+	mu    sync.Mutex
+	state daemonState
+}
+
+func newDaemonStatus(interval time.Duration) *daemonStatus {
+	return &daemonStatus{state: daemonState{Started: time.Now(), Interval: interval.String()}}
+}
+
+// begin marks a run as started and returns its number
+func (s *daemonStatus) begin() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Runs++
+	s.state.Running = true
+	s.state.NextRun = nil
+	return s.state.Runs
+}
+
+func (s *daemonStatus) finish(run *daemonRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Running = false
+	s.state.LastRun = run
+}
+
+func (s *daemonStatus) schedule(next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.NextRun = &next
+}
+
+func (s *daemonStatus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// daemonRepository is the status of a watched clone, as served at
+// /repositories
+type daemonRepository struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch,omitempty"`
+	Dirty  bool   `json:"dirty"`
+}
+
+// daemonClones serves the status of the clones watcher keeps up to date
+type daemonClones struct {
+	watcher *watch.Watcher
+	baseDir string
+}
+
+func (c daemonClones) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	repos := make([]daemonRepository, 0)
+	for path, status := range c.watcher.Statuses() {
+		if rel, err := filepath.Rel(c.baseDir, path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		repos = append(repos, daemonRepository{Path: path, Branch: status.CurrentBranch, Dirty: status.HasChanges})
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+
+	data, err := json.MarshalIndent(repos, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// daemonHandler serves the daemon status, and the status of the clones
+// when clones is not nil
+func daemonHandler(status *daemonStatus, clones *daemonClones) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/status", status)
+	if clones != nil {
+		mux.Handle("/repositories", clones)
+	}
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	interval, _ := cmd.Flags().GetDuration("interval")
+	if interval < minDaemonInterval {
+		return fmt.Errorf("--interval must be at least %s", minDaemonInterval)
+	}
+	listen, _ := cmd.Flags().GetString("listen")
+	watchClones, _ := cmd.Flags().GetBool("watch")
+	if watchClones && listen == "" {
+		return fmt.Errorf("--watch needs --listen to serve the status of the clones")
+	}
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	if err := checkWritable("sync repositories"); err != nil {
+		return err
+	}
+	stopLimit, err := startTransferLimit(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopLimit()
+
+	// A cached listing would hide repositories created since the last run
+	refreshCache = true
+
+	ctx := commandContext(cmd)
+	status := newDaemonStatus(interval)
+	var clones *daemonClones
+	if watchClones {
+		watcher, err := watch.New(nil)
+		if err != nil {
+			return fmt.Errorf("failed to watch clones: %w", err)
+		}
+		defer watcher.Close()
+		go watcher.Run(ctx, nil)
+		clones = &daemonClones{watcher: watcher, baseDir: cfg.Local.BaseDir}
+	}
+	if listen != "" {
+		listener, err := net.Listen("tcp", listen)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", listen, err)
+		}
+		server := &http.Server{Handler: daemonHandler(status, clones), ReadHeaderTimeout: 10 * time.Second}
+		go func() { _ = server.Serve(listener) }()
+		defer server.Close()
+		daemonLog(i18n.T("daemon.listening", listener.Addr()))
+	}
+
+	daemonLog(i18n.T("daemon.started", interval))
+	for {
+		started := time.Now()
+		runDaemonSync(ctx, cmd, groupPath, status)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if clones != nil {
+			clones.watchNew(cfg)
+		}
+
+		next := started.Add(interval)
+		if now := time.Now(); next.Before(now) {
+			next = now
+		}
+		status.schedule(next)
+		daemonLog(i18n.T("daemon.next_run", next.Format("15:04:05")))
+		select {
+		case <-time.After(time.Until(next)):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// runDaemonSync runs one sync with the current config and records and logs
+// its outcome. Errors are part of the outcome, so the daemon keeps going.
+func runDaemonSync(ctx context.Context, cmd *cobra.Command, groupPath string, status *daemonStatus) {
+	run := &daemonRun{Number: status.begin(), Started: time.Now()}
+	daemonLog(i18n.T("daemon.run_started", run.Number))
+
+	cfg, summary, err := daemonSync(ctx, cmd, groupPath)
+	run.Finished = time.Now()
+	if cfg != nil {
+		recordLastRun(cfg, newLastRun("daemon", nil, run.Started, summary, err))
+	}
+	if err != nil {
+		run.Error = redact.Error(err).Error()
+	} else if summary != nil {
+		recordDaemonRun(run, summary)
+	}
+	status.finish(run)
+	displayDaemonRun(stdout, run)
+}
+
+// daemonSync runs one sync, returning the config it used unless it could
+// not be loaded
+func daemonSync(ctx context.Context, cmd *cobra.Command, groupPath string) (*config.Config, *processSummary, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := checkWritable("sync repositories"); err != nil {
+		// Read-only mode writes nothing, not even the last run
+		return nil, nil, err
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return cfg, nil, err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	var out io.Writer = io.Discard
+	if verbosity.IsEnabled(verbosity.InfoLevel) {
+		out = stdout
+	}
+	summary, err := syncRepositories(ctx, cmd, cfg, clients, groupPath, filter, out)
+	return cfg, summary, err
+}
+
+// watchNew starts watching the clones recorded in the state file that are
+// not watched yet, such as those cloned by the last run
+func (c *daemonClones) watchNew(cfg *config.Config) {
+	for _, path := range loadState(cfg, io.Discard).Paths() {
+		if err := c.watcher.Add(path); err != nil {
+			verbosity.Debug("Not watching %s: %v", path, err)
+		}
+	}
+}
+
+func recordDaemonRun(run *daemonRun, summary *processSummary) {
+	run.Cloned = summary.Cloned
+	run.Updated = summary.Updated
+	run.Skipped = summary.Skipped + len(summary.Dirty) + len(summary.Protected)
+	run.Failed = summary.Failed()
+	run.Interrupted = len(summary.Interrupted)
+	run.Repositories = summary.Successful() + run.Failed + run.Interrupted
+	for _, failure := range summary.Failures {
+		run.Failures = append(run.Failures, daemonFailure{
+			Repository: failure.Repo.FullPath,
+			Provider:   failure.Repo.Provider,
+			Error:      redact.Error(failure.Err).Error(),
+		})
+	}
+	for _, change := range summary.VisibilityChanges {
+		run.VisibilityChanges = append(run.VisibilityChanges, daemonVisibilityChange{
+			Repository: change.Repo.FullPath,
+			Provider:   change.Repo.Provider,
+			From:       change.From,
+			To:         change.To,
+			Exposed:    change.Exposed(),
+		})
+	}
+	for _, action := range summary.Retention {
+		switch {
+		case action.Err != nil:
+			failure := daemonFailure{Repository: action.Path, Error: redact.Error(action.Err).Error()}
+			if action.Repo != nil {
+				failure.Repository, failure.Provider = action.Repo.FullPath, action.Repo.Provider
+			}
+			run.Failures = append(run.Failures, failure)
+		case action.Removed:
+			run.Removed = append(run.Removed, daemonRemoval{Path: action.label(), Reason: action.Reason})
+		}
+	}
+}
+
+func displayDaemonRun(w io.Writer, run *daemonRun) {
+	duration := run.Finished.Sub(run.Started).Round(time.Second)
+	switch {
+	case run.Error != "":
+		daemonLogTo(w, "❌ "+i18n.T("daemon.run_failed", run.Number, run.Error))
+	case run.Repositories == 0:
+		daemonLogTo(w, i18n.T("daemon.run_empty", run.Number))
+	default:
+		daemonLogTo(w, i18n.T("daemon.run_finished", run.Number, duration, run.Cloned, run.Updated, run.Skipped, run.Failed))
+	}
+	for _, failure := range run.Failures {
+		fmt.Fprintf(w, "  - %s [%s]: %s\n", failure.Repository, failure.Provider, failure.Error)
+	}
+	for _, change := range run.VisibilityChanges {
+		line := i18n.T("sync.visibility_changed", change.Repository, change.Provider, change.From, change.To)
+		if change.Exposed {
+			daemonLogTo(w, "🚨 "+i18n.T("sync.visibility_exposed", line))
+		} else {
+			daemonLogTo(w, "👁️  "+line)
+		}
+	}
+	for _, removal := range run.Removed {
+		daemonLogTo(w, "🧹 "+i18n.T("daemon.removed", removal.Path, removal.Reason))
+	}
+}
+
+func daemonLog(message string) {
+	daemonLogTo(stdout, message)
+}
+
+// daemonLogTo writes a timestamped line, as the daemon's output is usually
+// read from a log file
+func daemonLogTo(w io.Writer, message string) {
+	fmt.Fprintf(w, "[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), message)
+}
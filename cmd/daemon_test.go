@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/watch"
+)
+
+func TestRunDaemonSync(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	out := useTestProviders(t, cfg, map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}})
+	t.Cleanup(func() { resetFlags(daemonCmd) })
+	_ = daemonCmd.Flags().Set("https", "true") // The fixture remotes are local paths
+
+	status := newDaemonStatus(time.Hour)
+	runDaemonSync(context.Background(), daemonCmd, "", status)
+
+	run := status.state.LastRun
+	if run == nil || run.Number != 1 || status.state.Running {
+		t.Fatalf("Expected the first run to be recorded as finished, got %+v", status.state)
+	}
+	if run.Repositories != 3 || run.Cloned != 1 || run.Updated != 1 || run.Skipped != 1 || run.Failed != 0 || run.Error != "" {
+		t.Errorf("Unexpected run %+v", run)
+	}
+	if !strings.Contains(out.String(), "Sync run 1 finished") || !strings.Contains(out.String(), "1 cloned, 1 updated, 1 skipped, 0 failed") {
+		t.Errorf("Unexpected log:\n%s", out)
+	}
+	if strings.Contains(out.String(), "Processing") {
+		t.Errorf("Expected per-repository output only with -v, got:\n%s", out)
+	}
+}
+
+func TestRunDaemonSync_ErrorKeepsGoing(t *testing.T) {
+	out := useTestProviders(t, nil, nil)
+
+	status := newDaemonStatus(time.Hour)
+	runDaemonSync(context.Background(), daemonCmd, "", status)
+	runDaemonSync(context.Background(), daemonCmd, "", status)
+
+	run := status.state.LastRun
+	if status.state.Runs != 2 || run.Number != 2 || !strings.Contains(run.Error, "failed to load config") {
+		t.Errorf("Expected both runs to be recorded with the error, got %+v", status.state)
+	}
+	if strings.Count(out.String(), "❌") != 2 {
+		t.Errorf("Expected each failure to be logged, got:\n%s", out)
+	}
+}
+
+func TestRecordDaemonRun(t *testing.T) {
+	summary := &processSummary{
+		Cloned:      2,
+		Updated:     3,
+		Skipped:     1,
+		Dirty:       []*scm.Repository{{FullPath: "team/dirty"}},
+		Failures:    []repoFailure{{Repo: &scm.Repository{FullPath: "team/broken", Provider: "gitlab"}, Err: errors.New("exit status 128")}},
+		Interrupted: []*scm.Repository{{FullPath: "team/late"}},
+		VisibilityChanges: []state.VisibilityChange{
+			{Repo: &scm.Repository{FullPath: "team/secret", Provider: "gitlab"}, From: "private", To: "public"},
+		},
+	}
+
+	run := &daemonRun{}
+	recordDaemonRun(run, summary)
+
+	if run.Repositories != 9 || run.Skipped != 2 || run.Failed != 1 || run.Interrupted != 1 {
+		t.Errorf("Unexpected counts %+v", run)
+	}
+	want := daemonFailure{Repository: "team/broken", Provider: "gitlab", Error: "exit status 128"}
+	if len(run.Failures) != 1 || run.Failures[0] != want {
+		t.Errorf("Unexpected failures %+v", run.Failures)
+	}
+	wantChange := daemonVisibilityChange{Repository: "team/secret", Provider: "gitlab", From: "private", To: "public", Exposed: true}
+	if len(run.VisibilityChanges) != 1 || run.VisibilityChanges[0] != wantChange {
+		t.Errorf("Unexpected visibility changes %+v", run.VisibilityChanges)
+	}
+
+	var out strings.Builder
+	displayDaemonRun(&out, run)
+	if !strings.Contains(out.String(), "🚨 team/secret [gitlab]: private → public") {
+		t.Errorf("Expected the exposed repository to be logged, got:\n%s", out.String())
+	}
+}
+
+func TestDaemonHandler(t *testing.T) {
+	status := newDaemonStatus(30 * time.Minute)
+	number := status.begin()
+	status.finish(&daemonRun{Number: number, Cloned: 4, Error: ""})
+	status.schedule(time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC))
+
+	server := httptest.NewServer(daemonHandler(status, nil))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	var got daemonState
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if got.Interval != "30m0s" || got.Runs != 1 || got.Running || got.LastRun == nil || got.LastRun.Cloned != 4 ||
+		got.NextRun == nil || !got.NextRun.Equal(time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected status %+v", got)
+	}
+
+	health, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("Expected /healthz to answer 200, got %d", health.StatusCode)
+	}
+}
+
+func TestDaemon_RejectsShortInterval(t *testing.T) {
+	_, err := runCommand(t, testConfig(t.TempDir(), "work"), nil, "daemon", "--interval", "10s")
+	if err == nil || !strings.Contains(err.Error(), "--interval must be at least 1m0s") {
+		t.Errorf("Expected the interval to be rejected, got %v", err)
+	}
+}
+
+func TestDaemonHandler_Repositories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	baseDir := t.TempDir()
+	clone := filepath.Join(baseDir, "gitlab", "team", "api")
+	if out, err := exec.Command("git", "init", clone).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("# api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := watch.New([]string{clone})
+	if err != nil {
+		t.Fatalf("watch.New() failed: %v", err)
+	}
+	defer watcher.Close()
+
+	server := httptest.NewServer(daemonHandler(newDaemonStatus(time.Hour), &daemonClones{watcher: watcher, baseDir: baseDir}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/repositories")
+	if err != nil {
+		t.Fatalf("GET /repositories failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var got []daemonRepository
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode repositories: %v", err)
+	}
+	if len(got) != 1 || got[0].Path != "gitlab/team/api" || !got[0].Dirty {
+		t.Errorf("Unexpected repositories %+v", got)
+	}
+}
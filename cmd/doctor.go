@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/httpclient"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check connectivity and credentials for configured providers",
+	Long: `Check every configured provider by making an authenticated API call.
+
+Reports reachability, latency, the authenticated user and remaining API rate
+limit for each provider. Exits with a non-zero status if any provider is
+unreachable, so it can be used from monitoring scripts.
+
+Examples:
+  gitstuff doctor          # Human-readable report
+  gitstuff doctor --json   # Machine-readable report`,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("json", false, "Output the report as JSON")
+}
+
+type providerReport struct {
+	Name               string     `json:"name"`
+	Type               string     `json:"type"`
+	URL                string     `json:"url"`
+	Reachable          bool       `json:"reachable"`
+	LatencyMS          int64      `json:"latency_ms"`
+	User               string     `json:"user,omitempty"`
+	Version            string     `json:"version,omitempty"`
+	RateLimitLimit     *int       `json:"rate_limit_limit,omitempty"`
+	RateLimitRemaining *int       `json:"rate_limit_remaining,omitempty"`
+	RateLimitReset     *time.Time `json:"rate_limit_reset,omitempty"`
+	Scopes             []string   `json:"scopes,omitempty"`
+	Warning            string     `json:"warning,omitempty"`
+	Error              string     `json:"error,omitempty"`
+	Hint               string     `json:"hint,omitempty"`
+}
+
+type doctorReport struct {
+	Healthy   bool             `json:"healthy"`
+	CheckedAt time.Time        `json:"checked_at"`
+	Providers []providerReport `json:"providers"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	return runProviderChecks(cmd, cfg, cfg.Providers)
+}
+
+// runProviderChecks checks providers and prints the report as text or, with
+// --json, as JSON. It fails when any provider is unreachable.
+func runProviderChecks(cmd *cobra.Command, cfg *config.Config, providers []config.ProviderConfig) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	// Health checks bypass the response cache so results are always live
+	agent := userAgent(cfg.HTTP.UserAgentSuffix)
+	report := checkProviders(commandContext(cmd), providers, func(providerConfig config.ProviderConfig) (scm.Client, error) {
+		return createClientWithOptions(providerConfig, httpclient.Options{
+			Insecure:  providerConfig.Insecure,
+			UserAgent: agent,
+		})
+	})
+
+	if asJSON {
+		if err := writeDoctorJSON(stdout, report); err != nil {
+			return err
+		}
+	} else {
+		displayDoctorReport(stdout, report)
+	}
+
+	if !report.Healthy {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("one or more providers are unreachable")
+	}
+	return nil
+}
+
+func checkProviders(ctx context.Context, providers []config.ProviderConfig, newClient func(config.ProviderConfig) (scm.Client, error)) *doctorReport {
+	report := &doctorReport{
+		Healthy:   true,
+		CheckedAt: time.Now().UTC(),
+		Providers: make([]providerReport, 0, len(providers)),
+	}
+
+	for _, providerConfig := range providers {
+		result := checkProvider(ctx, providerConfig, newClient)
+		if !result.Reachable {
+			report.Healthy = false
+		}
+		report.Providers = append(report.Providers, result)
+	}
+
+	return report
+}
+
+func checkProvider(ctx context.Context, providerConfig config.ProviderConfig, newClient func(config.ProviderConfig) (scm.Client, error)) providerReport {
+	result := providerReport{
+		Name: providerConfig.Name,
+		Type: providerConfig.Type,
+		URL:  providerConfig.URL,
+	}
+
+	verbosity.Debug("Checking provider: %s (%s)", providerConfig.Name, providerConfig.Type)
+	client, err := newClient(providerConfig)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create client: %v", err)
+		result.Hint = "Check the provider URL with 'gitstuff config list'"
+		return result
+	}
+
+	checker, ok := client.(scm.HealthChecker)
+	if !ok {
+		result.Error = fmt.Sprintf("provider type %s does not support health checks", providerConfig.Type)
+		return result
+	}
+
+	start := time.Now()
+	health, err := checker.CheckHealth(ctx)
+	result.LatencyMS = time.Since(start).Milliseconds()
+	verbosity.DebugTiming(start, "Health check for %s", providerConfig.Name)
+	if err != nil {
+		result.Error = err.Error()
+		result.Hint = diagnoseHealthError(providerConfig, err)
+		return result
+	}
+
+	result.Reachable = true
+	result.User = health.User
+	result.Scopes = health.Scopes
+	result.Warning = scopeWarning(providerConfig.Type, health.Scopes)
+	if reporter, ok := client.(scm.VersionReporter); ok {
+		if version, err := reporter.ServerVersion(ctx); err == nil {
+			result.Version = version
+			if warning := versionWarning(providerConfig.Type, version); warning != "" {
+				result.Warning = strings.TrimPrefix(result.Warning+"; "+warning, "; ")
+			}
+		} else {
+			verbosity.Debug("Could not get the version of %s: %v", providerConfig.Name, err)
+		}
+	}
+	if health.RateLimitLimit > 0 {
+		result.RateLimitLimit = &health.RateLimitLimit
+		result.RateLimitRemaining = &health.RateLimitRemaining
+	}
+	if !health.RateLimitReset.IsZero() {
+		reset := health.RateLimitReset.UTC()
+		result.RateLimitReset = &reset
+	}
+
+	return result
+}
+
+func writeDoctorJSON(w io.Writer, report *doctorReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+func displayDoctorReport(w io.Writer, report *doctorReport) {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("doctor.checking", len(report.Providers)))
+
+	for _, result := range report.Providers {
+		if result.Reachable {
+			fmt.Fprintf(w, "✅ %s (%s) %s\n", result.Name, result.Type, result.URL)
+			fmt.Fprintf(w, "   %s\n", i18n.T("doctor.user", result.User))
+			if result.Version != "" {
+				fmt.Fprintf(w, "   %s\n", i18n.T("doctor.version", result.Version))
+			}
+			fmt.Fprintf(w, "   %s\n", i18n.T("doctor.latency", result.LatencyMS))
+			if result.RateLimitLimit != nil {
+				fmt.Fprintf(w, "   %s\n", i18n.T("doctor.rate_limit", *result.RateLimitRemaining, *result.RateLimitLimit))
+			}
+			if result.Scopes != nil {
+				fmt.Fprintf(w, "   %s\n", i18n.T("doctor.scopes", formatScopes(result.Scopes)))
+			}
+			if result.Warning != "" {
+				fmt.Fprintf(w, "   ⚠️  %s\n", result.Warning)
+			}
+		} else {
+			fmt.Fprintf(w, "❌ %s (%s) %s\n", result.Name, result.Type, result.URL)
+			fmt.Fprintf(w, "   %s\n", i18n.T("doctor.error", result.Error))
+			if result.Hint != "" {
+				fmt.Fprintf(w, "   %s\n", i18n.T("doctor.hint", result.Hint))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if report.Healthy {
+		fmt.Fprintln(w, i18n.T("doctor.healthy"))
+	} else {
+		fmt.Fprintln(w, i18n.T("doctor.unhealthy"))
+	}
+}
+
+func formatScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return i18n.T("doctor.scopes_none")
+	}
+	return strings.Join(scopes, ", ")
+}
+
+// scopeWarning explains what will not work when a token's scopes are known
+// and too narrow for gitstuff
+func scopeWarning(providerType string, scopes []string) string {
+	if scopes == nil {
+		return ""
+	}
+	has := func(names ...string) bool {
+		for _, scope := range scopes {
+			if slices.Contains(names, scope) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch providerType {
+	case "gitlab":
+		if !has("api", "read_api") {
+			return i18n.T("doctor.lacks_read_api")
+		}
+		if !has("api", "read_repository", "write_repository") {
+			return i18n.T("doctor.lacks_read_repository")
+		}
+	case "github":
+		if !has("repo") {
+			if has("public_repo") {
+				return i18n.T("doctor.only_public_repo")
+			}
+			return i18n.T("doctor.lacks_repo")
+		}
+	}
+	return ""
+}
+
+// diagnoseHealthError suggests how to fix a failed health check
+func diagnoseHealthError(providerConfig config.ProviderConfig, err error) string {
+	switch scm.ClassifyError(err) {
+	case scm.ErrorUnauthorized:
+		return i18n.T("doctor.hint_unauthorized", providerConfig.Name)
+	case scm.ErrorForbidden:
+		return i18n.T("doctor.hint_forbidden")
+	case scm.ErrorNotFound:
+		return i18n.T("doctor.hint_not_found")
+	case scm.ErrorRateLimited:
+		return i18n.T("doctor.hint_rate_limited")
+	case scm.ErrorServer:
+		return i18n.T("doctor.hint_server")
+	}
+	if scm.StatusCode(err) != 0 {
+		return ""
+	}
+
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return i18n.T("doctor.hint_dns")
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr):
+		if providerConfig.Insecure {
+			return i18n.T("doctor.hint_tls_insecure")
+		}
+		return i18n.T("doctor.hint_tls", providerConfig.Name)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return i18n.T("doctor.hint_timeout")
+	case strings.Contains(err.Error(), "connection refused"):
+		return i18n.T("doctor.hint_refused")
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+type mockHealthClient struct {
+	mockSCMClient
+	health *scm.ProviderHealth
+	err    error
+}
+
+func (m *mockHealthClient) CheckHealth(ctx context.Context) (*scm.ProviderHealth, error) {
+	return m.health, m.err
+}
+
+func TestCheckProviders(t *testing.T) {
+	reset := time.Unix(1700000000, 0)
+	clients := map[string]scm.Client{
+		"work": &mockHealthClient{
+			mockSCMClient: mockSCMClient{providerType: "gitlab"},
+			health: &scm.ProviderHealth{
+				User:               "jdoe",
+				RateLimitLimit:     2000,
+				RateLimitRemaining: 1500,
+				RateLimitReset:     reset,
+			},
+		},
+		"mirror": &mockHealthClient{
+			mockSCMClient: mockSCMClient{providerType: "github"},
+			err:           errors.New("401 Bad credentials"),
+		},
+		"nolimit": &mockHealthClient{
+			mockSCMClient: mockSCMClient{providerType: "gitlab"},
+			health:        &scm.ProviderHealth{User: "jdoe"},
+		},
+		"legacy": &mockSCMClient{providerType: "gitlab"},
+	}
+	newClient := func(p config.ProviderConfig) (scm.Client, error) {
+		return clients[p.Name], nil
+	}
+
+	providers := []config.ProviderConfig{
+		{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"},
+		{Name: "mirror", Type: "github", URL: "https://github.com"},
+		{Name: "nolimit", Type: "gitlab", URL: "https://gitlab.internal"},
+		{Name: "legacy", Type: "gitlab", URL: "https://old.example.com"},
+	}
+
+	report := checkProviders(context.Background(), providers, newClient)
+
+	if report.Healthy {
+		t.Error("Expected report to be unhealthy when a provider fails")
+	}
+	if len(report.Providers) != 4 {
+		t.Fatalf("Expected 4 provider results, got %d", len(report.Providers))
+	}
+
+	work := report.Providers[0]
+	if !work.Reachable || work.User != "jdoe" {
+		t.Errorf("Expected work to be reachable as jdoe, got %+v", work)
+	}
+	if work.RateLimitRemaining == nil || *work.RateLimitRemaining != 1500 {
+		t.Errorf("Expected 1500 remaining, got %v", work.RateLimitRemaining)
+	}
+	if work.RateLimitReset == nil || !work.RateLimitReset.Equal(reset) {
+		t.Errorf("Expected reset %v, got %v", reset, work.RateLimitReset)
+	}
+
+	mirror := report.Providers[1]
+	if mirror.Reachable || !strings.Contains(mirror.Error, "Bad credentials") {
+		t.Errorf("Expected mirror to be unreachable with error, got %+v", mirror)
+	}
+
+	nolimit := report.Providers[2]
+	if !nolimit.Reachable || nolimit.RateLimitLimit != nil || nolimit.RateLimitReset != nil {
+		t.Errorf("Expected no rate limit information, got %+v", nolimit)
+	}
+
+	legacy := report.Providers[3]
+	if legacy.Reachable || !strings.Contains(legacy.Error, "does not support health checks") {
+		t.Errorf("Expected legacy client to report missing support, got %+v", legacy)
+	}
+}
+
+func TestCheckProviders_ClientCreationError(t *testing.T) {
+	newClient := func(p config.ProviderConfig) (scm.Client, error) {
+		return nil, errors.New("invalid URL")
+	}
+
+	report := checkProviders(context.Background(), []config.ProviderConfig{{Name: "broken", Type: "gitlab"}}, newClient)
+
+	if report.Healthy {
+		t.Error("Expected report to be unhealthy")
+	}
+	if !strings.Contains(report.Providers[0].Error, "failed to create client") {
+		t.Errorf("Expected client creation error, got %q", report.Providers[0].Error)
+	}
+}
+
+func TestCheckProviders_AllHealthy(t *testing.T) {
+	newClient := func(p config.ProviderConfig) (scm.Client, error) {
+		return &mockHealthClient{health: &scm.ProviderHealth{User: "me"}}, nil
+	}
+
+	report := checkProviders(context.Background(), []config.ProviderConfig{{Name: "a"}, {Name: "b"}}, newClient)
+
+	if !report.Healthy {
+		t.Error("Expected report to be healthy")
+	}
+}
+
+func TestWriteDoctorJSON(t *testing.T) {
+	limit, remaining := 5000, 42
+	report := &doctorReport{
+		Healthy: false,
+		Providers: []providerReport{
+			{Name: "gh", Type: "github", Reachable: true, User: "octocat", LatencyMS: 12, RateLimitLimit: &limit, RateLimitRemaining: &remaining},
+			{Name: "gl", Type: "gitlab", Error: "connection refused"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDoctorJSON(&buf, report); err != nil {
+		t.Fatalf("writeDoctorJSON failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+
+	if decoded["healthy"] != false {
+		t.Errorf("Expected healthy=false, got %v", decoded["healthy"])
+	}
+	providers := decoded["providers"].([]interface{})
+	gh := providers[0].(map[string]interface{})
+	if gh["rate_limit_remaining"] != float64(42) || gh["user"] != "octocat" {
+		t.Errorf("Unexpected github entry: %v", gh)
+	}
+	gl := providers[1].(map[string]interface{})
+	if _, ok := gl["rate_limit_remaining"]; ok {
+		t.Errorf("Expected rate limit to be omitted for unreachable provider: %v", gl)
+	}
+	if gl["error"] != "connection refused" {
+		t.Errorf("Expected error to be reported, got %v", gl["error"])
+	}
+}
+
+func TestDisplayDoctorReport(t *testing.T) {
+	limit, remaining := 2000, 1999
+	report := &doctorReport{
+		Healthy: true,
+		Providers: []providerReport{
+			{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com", Reachable: true, User: "jdoe", LatencyMS: 80, RateLimitLimit: &limit, RateLimitRemaining: &remaining},
+		},
+	}
+
+	var buf bytes.Buffer
+	displayDoctorReport(&buf, report)
+	output := buf.String()
+
+	for _, want := range []string{"✅ work (gitlab)", "User: jdoe", "Latency: 80ms", "Rate limit: 1999/2000 remaining", "All providers are reachable"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestScopeWarning(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerType string
+		scopes       []string
+		want         string
+	}{
+		{name: "unknown scopes", providerType: "github", scopes: nil, want: ""},
+		{name: "gitlab api", providerType: "gitlab", scopes: []string{"api"}, want: ""},
+		{name: "gitlab read only", providerType: "gitlab", scopes: []string{"read_api", "read_repository"}, want: ""},
+		{name: "gitlab no api", providerType: "gitlab", scopes: []string{"read_repository"}, want: "read_api"},
+		{name: "gitlab no repository", providerType: "gitlab", scopes: []string{"read_api"}, want: "read_repository"},
+		{name: "github repo", providerType: "github", scopes: []string{"repo", "read:org"}, want: ""},
+		{name: "github public only", providerType: "github", scopes: []string{"public_repo"}, want: "public_repo"},
+		{name: "github none", providerType: "github", scopes: []string{}, want: "lacks the repo scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scopeWarning(tt.providerType, tt.scopes)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("scopeWarning() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiagnoseHealthError(t *testing.T) {
+	provider := config.ProviderConfig{Name: "work"}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "unauthorized", err: fmt.Errorf("failed: %w", &scm.APIError{StatusCode: 401, Err: errors.New("401")}), want: "gitstuff config edit work --token"},
+		{name: "not found", err: &scm.APIError{StatusCode: 404, Err: errors.New("404")}, want: "instance root"},
+		{name: "server error", err: &scm.APIError{StatusCode: 502, Err: errors.New("502")}, want: "server error"},
+		{name: "dns", err: &url.Error{Op: "Get", URL: "https://nowhere", Err: &net.DNSError{Err: "no such host", Name: "nowhere"}}, want: "could not be resolved"},
+		{name: "certificate", err: &url.Error{Op: "Get", URL: "https://self", Err: x509.UnknownAuthorityError{}}, want: "--insecure"},
+		{name: "refused", err: errors.New("dial tcp 127.0.0.1:1: connect: connection refused"), want: "refused"},
+		{name: "unknown", err: errors.New("something else"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnoseHealthError(provider, tt.err)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("diagnoseHealthError() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayDoctorReport_ScopesAndHints(t *testing.T) {
+	report := &doctorReport{
+		Providers: []providerReport{
+			{Name: "work", Type: "gitlab", Reachable: true, User: "jdoe", Scopes: []string{"read_api"}, Warning: "Token lacks the read_repository scope"},
+			{Name: "home", Type: "github", Error: "401 Bad credentials", Hint: "The token was rejected"},
+		},
+	}
+
+	var buf bytes.Buffer
+	displayDoctorReport(&buf, report)
+	output := buf.String()
+
+	for _, want := range []string{"Token scopes: read_api", "⚠️  Token lacks the read_repository scope", "Hint: The token was rejected"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
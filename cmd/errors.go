@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// providerErrorKeys maps each error category to its message and hint keys
+var providerErrorKeys = map[scm.ErrorKind][2]string{
+	scm.ErrorUnauthorized: {"error.unauthorized", "hint.unauthorized"},
+	scm.ErrorForbidden:    {"error.forbidden", "hint.forbidden"},
+	scm.ErrorNotFound:     {"error.not_found", "hint.not_found"},
+	scm.ErrorRateLimited:  {"error.rate_limited", "hint.rate_limited"},
+	scm.ErrorServer:       {"error.server", "hint.server"},
+}
+
+// describeProviderError explains a failed provider request in terms of what
+// went wrong and how to fix it. Errors that cannot be classified are
+// returned as they are, with no hint.
+func describeProviderError(err error) (message, hint string) {
+	keys, ok := providerErrorKeys[scm.ClassifyError(err)]
+	if !ok {
+		return redact.Error(err).Error(), ""
+	}
+	verbosity.Debug("Provider error: %v", redact.Error(err))
+	return i18n.T(keys[0], scm.StatusCode(err)), i18n.T(keys[1])
+}
+
+// providerErrorText is describeProviderError as a single line, with the
+// hint on an indented line below it
+func providerErrorText(err error) string {
+	message, hint := describeProviderError(err)
+	if hint == "" {
+		return message
+	}
+	return message + "\n   💡 " + hint
+}
+
+// providerError is a provider failure returned from a command, described
+// for the user but still wrapping the original error
+type providerError struct {
+	provider string
+	err      error
+}
+
+func (e *providerError) Error() string {
+	return i18n.T("error.provider", e.provider, providerErrorText(e.err))
+}
+
+func (e *providerError) Unwrap() error {
+	return e.err
+}
+
+// Exit codes of runs over many repositories, so scripts and CI can tell a
+// partial failure from a total one
+const (
+	exitError          = 1 // The command failed or could not start
+	exitPartialFailure = 2 // Some repositories failed
+	exitTotalFailure   = 3 // Every repository failed
+)
+
+// exitCodeError is a failure that exits with its own code
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the exit status for err, 0 when it is nil
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+	return exitError
+}
+
+// runFailure returns the error for a run in which repositories failed, nil
+// when none did
+func runFailure(summary *processSummary) error {
+	if summary == nil || summary.Failed() == 0 {
+		return nil
+	}
+	// Repositories that a --fail-fast stop or an interrupt kept from
+	// running did not fail, so the run is only a total failure when every
+	// repository ran and failed
+	if summary.Successful() == 0 && !summary.Stopped && len(summary.Interrupted) == 0 {
+		return &exitCodeError{code: exitTotalFailure, err: errors.New(i18n.T("clone.all_failed", summary.Failed()))}
+	}
+	total := summary.Failed() + summary.Successful() + len(summary.Interrupted)
+	return &exitCodeError{code: exitPartialFailure, err: errors.New(i18n.T("clone.some_failed", summary.Failed(), total))}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestDescribeProviderError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantMessage string
+		wantHint    string
+	}{
+		{
+			name:        "unclassified",
+			err:         errors.New("dial tcp: connection refused"),
+			wantMessage: "dial tcp: connection refused",
+		},
+		{
+			name:        "unauthorized",
+			err:         fmt.Errorf("failed to list repositories: %w", &scm.APIError{StatusCode: 401, Err: errors.New("Bad credentials")}),
+			wantMessage: "authentication failed (HTTP 401)",
+			wantHint:    "--token",
+		},
+		{
+			name:        "forbidden",
+			err:         &scm.APIError{StatusCode: 403, Err: errors.New("insufficient_scope")},
+			wantMessage: "access denied (HTTP 403)",
+			wantHint:    "scope",
+		},
+		{
+			name:        "rate limited",
+			err:         &scm.APIError{StatusCode: 403, Err: errors.New("API rate limit exceeded")},
+			wantMessage: "rate limited by the provider (HTTP 403)",
+			wantHint:    "gitstuff doctor",
+		},
+		{
+			name:        "server error",
+			err:         &scm.APIError{StatusCode: 503, Err: errors.New("unavailable")},
+			wantMessage: "the provider reported a server error (HTTP 503)",
+			wantHint:    "try again later",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, hint := describeProviderError(tt.err)
+			if message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", message, tt.wantMessage)
+			}
+			if tt.wantHint == "" && hint != "" {
+				t.Errorf("hint = %q, want none", hint)
+			}
+			if !strings.Contains(hint, tt.wantHint) {
+				t.Errorf("hint = %q, want it to contain %q", hint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	cause := &scm.APIError{StatusCode: 404, Err: errors.New("404 Not Found")}
+	err := error(&providerError{provider: "gitlab", err: cause})
+
+	if !errors.Is(err, cause) {
+		t.Error("providerError should wrap the original error")
+	}
+	text := err.Error()
+	if !strings.HasPrefix(text, "error from gitlab provider: not found (HTTP 404)") {
+		t.Errorf("unexpected error text %q", text)
+	}
+	if !strings.Contains(text, "💡") {
+		t.Errorf("expected a hint in %q", text)
+	}
+}
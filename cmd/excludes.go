@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var excludesCmd = &cobra.Command{
+	Use:   "excludes [path]",
+	Short: "Install the team's ignore and attribute patterns into every clone",
+	Long: `Install the ignore patterns of git.excludes and git.excludes_file into the
+.git/info/exclude file of every git repository in the base directory (or the
+given path), and the attributes of git.attributes and git.attributes_file into
+.git/info/attributes. Nothing is written to the working trees, so OS and
+editor junk stops showing up in status without changing any repository.
+
+The patterns go in a block marked as managed by gitstuff, which is replaced
+on every run; entries outside the block are kept. clone and sync update the
+block of every repository they process.
+
+Examples:
+  gitstuff excludes                 # Every clone in the base directory
+  gitstuff excludes ~/src/work      # Clones below a directory
+  gitstuff excludes --remove        # Take the managed patterns out again`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExcludes,
+}
+
+func init() {
+	rootCmd.AddCommand(excludesCmd)
+	excludesCmd.Flags().Bool("remove", false, "Remove the managed patterns instead of installing them")
+}
+
+// managedInfo holds the patterns installed into each clone's info files
+type managedInfo struct {
+	excludes   []string
+	attributes []string
+}
+
+// loadManagedInfo collects the configured ignore and attribute patterns
+func loadManagedInfo(cfg *config.Config) (managedInfo, error) {
+	excludes, err := patternsWithFile(cfg.Git.Excludes, cfg.Git.ExcludesFile)
+	if err != nil {
+		return managedInfo{}, err
+	}
+	attributes, err := patternsWithFile(cfg.Git.Attributes, cfg.Git.AttributesFile)
+	if err != nil {
+		return managedInfo{}, err
+	}
+	return managedInfo{excludes: excludes, attributes: attributes}, nil
+}
+
+// patternsWithFile returns patterns followed by the lines of file
+func patternsWithFile(patterns []string, file string) ([]string, error) {
+	lines := append([]string(nil), patterns...)
+	if file == "" {
+		return lines, nil
+	}
+	content, err := os.ReadFile(expandHome(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	return lines, nil
+}
+
+func (m managedInfo) empty() bool {
+	return len(m.excludes) == 0 && len(m.attributes) == 0
+}
+
+// apply installs the patterns into the repository at repoPath and reports
+// whether anything changed
+func (m managedInfo) apply(repoPath string) (bool, error) {
+	excludesChanged, err := git.SetManagedBlock(repoPath, git.InfoExclude, m.excludes)
+	if err != nil {
+		return false, err
+	}
+	attributesChanged, err := git.SetManagedBlock(repoPath, git.InfoAttributes, m.attributes)
+	if err != nil {
+		return false, err
+	}
+	return excludesChanged || attributesChanged, nil
+}
+
+// applyManagedInfo keeps the managed patterns of the repositories clone and
+// sync processed up to date. Failures only warn.
+func applyManagedInfo(w io.Writer, info managedInfo, repoPaths []string) {
+	if info.empty() {
+		return
+	}
+	for _, repoPath := range repoPaths {
+		if _, err := info.apply(repoPath); err != nil {
+			fmt.Fprintf(w, "⚠️  %s\n", i18n.T("excludes.failed", repoPath, redact.Error(err)))
+		}
+	}
+}
+
+func runExcludes(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	remove, _ := cmd.Flags().GetBool("remove")
+	if err := checkWritable("change repository excludes"); err != nil {
+		return err
+	}
+
+	var info managedInfo
+	if !remove {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+		}
+		if info, err = loadManagedInfo(cfg); err != nil {
+			return err
+		}
+		if info.empty() {
+			return fmt.Errorf("no patterns configured (set git.excludes or git.attributes in the config file)")
+		}
+	}
+	root, repoPaths, err := localRepositories(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("status.found_local", len(repoPaths), root))
+
+	changed, failed := 0, 0
+	for _, repoPath := range repoPaths {
+		updated, err := info.apply(repoPath)
+		switch {
+		case err != nil:
+			fmt.Fprintf(stdout, "❌ %s: %v\n", relativeTo(root, repoPath), redact.Error(err))
+			failed++
+		case updated:
+			fmt.Fprintf(stdout, "📝 %s\n", relativeTo(root, repoPath))
+			changed++
+		}
+	}
+	verbosity.DebugTiming(start, "Updated excludes of %d repositories", len(repoPaths))
+
+	fmt.Fprintf(stdout, "\n%s\n", i18n.T("excludes.summary", changed, len(repoPaths)-changed-failed, failed))
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to update %d repositories", failed)
+	}
+	return nil
+}
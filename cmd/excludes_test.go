@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_Excludes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	cfg, _ := setupSyncFixture(t)
+	teamFile := filepath.Join(t.TempDir(), "gitignore")
+	if err := os.WriteFile(teamFile, []byte("*.swp\r\n.idea/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Git = config.GitConfig{Excludes: []string{".DS_Store"}, ExcludesFile: teamFile, Attributes: []string{"*.sh text eol=lf"}}
+	clean := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	if err := os.WriteFile(filepath.Join(clean, ".DS_Store"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCommand(t, cfg, nil, "excludes")
+	if err != nil {
+		t.Fatalf("excludes failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Updated 2 repositories, 0 already up to date, 0 failed") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+	if status, err := exec.Command("git", "-C", clean, "status", "--porcelain").Output(); err != nil || len(status) != 0 {
+		t.Errorf("Expected .DS_Store to be ignored, got %q (%v)", status, err)
+	}
+	exclude, err := os.ReadFile(filepath.Join(clean, ".git", "info", "exclude"))
+	if err != nil || !strings.Contains(string(exclude), ".DS_Store\n*.swp\n.idea/\n") {
+		t.Errorf("Unexpected info/exclude %q (%v)", exclude, err)
+	}
+
+	if out, err := runCommand(t, cfg, nil, "excludes"); err != nil || !strings.Contains(out, "Updated 0 repositories, 2 already up to date") {
+		t.Errorf("Expected nothing to change, got %v\n%s", err, out)
+	}
+	if out, err := runCommand(t, cfg, nil, "excludes", "--remove"); err != nil || !strings.Contains(out, "Updated 2 repositories") {
+		t.Errorf("Expected the patterns to be removed, got %v\n%s", err, out)
+	}
+	if status, _ := exec.Command("git", "-C", clean, "status", "--porcelain").Output(); !strings.Contains(string(status), ".DS_Store") {
+		t.Errorf("Expected .DS_Store to show up again, got %q", status)
+	}
+
+	cfg.Git = config.GitConfig{}
+	if _, err := runCommand(t, cfg, nil, "excludes", "--remove=false"); err == nil || !strings.Contains(err.Error(), "no patterns configured") {
+		t.Errorf("Expected a missing configuration error, got %v", err)
+	}
+}
+
+func TestCommand_SyncInstallsExcludes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	cfg.Git.Excludes = []string{"wip.txt"}
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}}
+
+	if out, err := runCommand(t, cfg, clients, "sync", "--https"); err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, out)
+	}
+	for _, name := range []string{"clean", "dirty", "missing"} {
+		exclude, err := os.ReadFile(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", name, ".git", "info", "exclude"))
+		if err != nil || !strings.Contains(string(exclude), "\nwip.txt\n") {
+			t.Errorf("Expected %s to exclude wip.txt, got %q (%v)", name, exclude, err)
+		}
+	}
+}
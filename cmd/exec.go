@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:     "exec [group] -- <command> [args...]",
+	Aliases: []string{"foreach"},
+	Short:   "Run a shell command in every cloned repository",
+	Long: `Run a shell command in every cloned repository, or every repository in a
+group, and report which repositories passed and which failed with their
+exit codes. Repositories come from the configured providers, so the usual
+filters apply, and those not cloned yet are skipped.
+
+The command runs through the shell with the repository as working directory
+and with GITSTUFF_REPO, GITSTUFF_REPO_NAME, GITSTUFF_PROVIDER and
+GITSTUFF_DEFAULT_BRANCH set. A command of several arguments keeps them as
+given; a single argument is run as a shell script. Separate the command from
+a group with --.
+
+The command exits with a non-zero status when the command failed anywhere.
+
+Examples:
+  gitstuff exec git status --short            # Run in every repository
+  gitstuff exec backend -- make test          # Run in one group
+  gitstuff foreach -j 8 --provider github -- 'git log -1 --format=%cr'`,
+	RunE: runExec,
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to run the command in at a time")
+	execCmd.Flags().String("provider", "", "Only repositories from the provider with this name or type")
+	execCmd.Flags().BoolP("quiet", "q", false, "Only show the output of repositories where the command failed")
+	addRepoFilterFlags(execCmd)
+	// Flags after the command belong to it
+	execCmd.Flags().SetInterspersed(false)
+}
+
+type execResult struct {
+	Repo     *scm.Repository
+	ExitCode int
+	Err      error
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	groupPath, command, err := splitExecArgs(args, cmd.ArgsLenAtDash())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	provider, _ := cmd.Flags().GetString("provider")
+	quiet, _ := cmd.Flags().GetBool("quiet")
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+
+	repos := collectRepositories(commandContext(cmd), selected, groupPath, filter)
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+	cloned := clonedRepositories(cfg, repos)
+	if skipped := len(repos) - len(cloned); skipped > 0 {
+		verbosity.Info("Skipping %d repositories that are not cloned", skipped)
+	}
+
+	results := execRepositories(cfg, cloned, command, jobs, quiet, stdout)
+	verbosity.DebugTiming(start, "Ran command in %d repositories", len(results))
+
+	if failed := displayExecSummary(stdout, results); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("command failed in %d of %d repositories", failed, len(results))
+	}
+	return nil
+}
+
+// splitExecArgs separates the optional group from the command. dash is the
+// position of --, if cobra saw it; once flag parsing has stopped at the
+// first argument, a -- right after the group is still among args.
+func splitExecArgs(args []string, dash int) (string, string, error) {
+	if dash < 0 {
+		for i := 0; i < len(args) && i < 2; i++ {
+			if args[i] == "--" {
+				dash = i
+				args = append(args[:i:i], args[i+1:]...)
+				break
+			}
+		}
+	}
+
+	groupPath := ""
+	if dash >= 0 {
+		if dash > 1 {
+			return "", "", fmt.Errorf("expected at most one group before --, got %d arguments", dash)
+		}
+		if dash == 1 {
+			groupPath = args[0]
+		}
+		args = args[dash:]
+	}
+	if len(args) == 0 {
+		return "", "", fmt.Errorf("no command given")
+	}
+	if len(args) == 1 {
+		// A single argument is a script for the shell, like 'make && make test'
+		return groupPath, args[0], nil
+	}
+	return groupPath, shellJoin(args), nil
+}
+
+// shellSafe matches words the shell passes on as they are
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellJoin joins args into one command line for shellCommand, quoting
+// those the shell would otherwise split or expand
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case shellSafe.MatchString(arg):
+			quoted[i] = arg
+		case runtime.GOOS == "windows":
+			quoted[i] = cmdQuote(arg)
+		default:
+			quoted[i] = git.ShellQuote(arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+var (
+	// cmdSpecial matches the characters cmd.exe interprets outside quotes
+	cmdSpecial = regexp.MustCompile(`[()%!^"<>&|]`)
+	// quoteBackslashes matches the backslashes before a quote or the end,
+	// which a program parsing its arguments reads as escapes
+	quoteBackslashes = regexp.MustCompile(`(\\*)("|$)`)
+)
+
+// cmdQuote quotes s as a single argument of a program started by cmd /C.
+// The program's argument parsing gets s back from double quotes, with
+// quotes inside doubled and the backslashes before them escaped. Every
+// character cmd reads itself, the quotes included, is escaped with ^, so
+// cmd never enters a quoted section and passes the argument on as it is.
+func cmdQuote(s string) string {
+	quoted := `"` + quoteBackslashes.ReplaceAllString(s, "$1$1$2$2") + `"`
+	return cmdSpecial.ReplaceAllString(quoted, "^$0")
+}
+
+// selectClients returns the clients of the providers whose name or type is
+// provider, or every client when provider is empty. clients must be in the
+// same order as cfg.Providers.
+func selectClients(cfg *config.Config, clients []scm.Client, provider string) ([]scm.Client, error) {
+	if provider == "" {
+		return clients, nil
+	}
+	var selected []scm.Client
+	for i, client := range clients {
+		if i < len(cfg.Providers) && (cfg.Providers[i].Name == provider || cfg.Providers[i].Type == provider) {
+			selected = append(selected, client)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no provider named '%s' is configured", provider)
+	}
+	return selected, nil
+}
+
+// shellCommand returns a command running script through the shell in dir,
+// with the repository described in its environment
+func shellCommand(dir string, repo *scm.Repository, script string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", script)
+		rawShellCommandLine(cmd, script)
+	} else {
+		cmd = exec.Command("sh", "-c", script)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GITSTUFF_REPO="+repo.FullPath,
+		"GITSTUFF_REPO_NAME="+repo.Name,
+		"GITSTUFF_PROVIDER="+repo.Provider,
+		"GITSTUFF_DEFAULT_BRANCH="+repo.DefaultBranch,
+	)
+	return cmd
+}
+
+func execRepositories(cfg *config.Config, repos []*scm.Repository, command string, jobs int, quiet bool, out io.Writer) []execResult {
+	results := make([]execResult, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			results[i] = execRepository(w, paths.ResolveRepositoryPath(cfg, repo), repo, command, quiet)
+			return results[i].Err
+		}
+	}
+	runner.New(jobs).Run(tasks, out)
+	return results
+}
+
+// execRepository runs command in one repository, writing a header and the
+// command's output to w
+func execRepository(w io.Writer, repoPath string, repo *scm.Repository, command string, quiet bool) execResult {
+	result := execResult{Repo: repo}
+
+	var output strings.Builder
+	cmd := shellCommand(repoPath, repo, command)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		result.Err = err
+		result.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	}
+
+	if quiet && result.Err == nil {
+		return result
+	}
+	if result.Err != nil {
+		fmt.Fprintf(w, "❌ %s\n", repo.FullPath)
+	} else {
+		fmt.Fprintf(w, "▶ %s\n", repo.FullPath)
+	}
+	if text := strings.TrimRight(output.String(), "\n"); text != "" {
+		fmt.Fprintln(w, text)
+	}
+	fmt.Fprintln(w)
+	return result
+}
+
+// displayExecSummary lists the failed repositories with their exit codes
+// and returns how many there are
+func displayExecSummary(w io.Writer, results []execResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Fprintln(w, i18n.T("exec.summary", len(results)-failed, failed))
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		if result.ExitCode >= 0 {
+			fmt.Fprintf(w, "   ❌ %s - %s\n", result.Repo.FullPath, i18n.T("exec.exit_code", result.ExitCode))
+		} else {
+			fmt.Fprintf(w, "   ❌ %s - %v\n", result.Repo.FullPath, result.Err)
+		}
+	}
+	return failed
+}
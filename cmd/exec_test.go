@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestSplitExecArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		dash      int
+		wantGroup string
+		wantCmd   string
+		wantErr   bool
+	}{
+		{name: "command only", args: []string{"git", "status", "--short"}, dash: -1, wantCmd: "git status --short"},
+		{name: "dash seen by cobra", args: []string{"make", "test"}, dash: 0, wantCmd: "make test"},
+		{name: "group before dash", args: []string{"backend", "--", "make", "test"}, dash: -1, wantGroup: "backend", wantCmd: "make test"},
+		{name: "group with dash seen by cobra", args: []string{"backend", "make"}, dash: 1, wantGroup: "backend", wantCmd: "make"},
+		{name: "dash later is part of the command", args: []string{"git", "log", "--", "README.md"}, dash: -1, wantCmd: "git log -- README.md"},
+		{name: "arguments are quoted", args: []string{"git", "commit", "-m", "fix typo"}, dash: -1, wantCmd: "git commit -m 'fix typo'"},
+		{name: "quotes inside arguments", args: []string{"echo", "it's $HOME;*"}, dash: -1, wantCmd: `echo 'it'\''s $HOME;*'`},
+		{name: "single argument is a script", args: []string{"git fetch && git status"}, dash: -1, wantCmd: "git fetch && git status"},
+		{name: "too many groups", args: []string{"a", "b", "make"}, dash: 2, wantErr: true},
+		{name: "no command", args: []string{"backend", "--"}, dash: -1, wantErr: true},
+		{name: "nothing", dash: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, command, err := splitExecArgs(tt.args, tt.dash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitExecArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if group != tt.wantGroup || command != tt.wantCmd {
+				t.Errorf("splitExecArgs() = %q, %q, want %q, %q", group, command, tt.wantGroup, tt.wantCmd)
+			}
+		})
+	}
+}
+
+func TestCmdQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"fix typo", `^"fix typo^"`},
+		{`say "hi"`, `^"say ^"^"hi^"^"^"`},
+		{`C:\dir\`, `^"C:\dir\\^"`},
+		{`a\"b`, `^"a\\^"^"b^"`},
+		{"50% & more | less", `^"50^% ^& more ^| less^"`},
+	}
+	for _, tt := range tests {
+		if got := cmdQuote(tt.arg); got != tt.want {
+			t.Errorf("cmdQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestSelectClients(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{
+		{Name: "work", Type: "gitlab"},
+		{Name: "personal", Type: "github"},
+		{Name: "oss", Type: "github"},
+	}}
+	clients := []scm.Client{&mockSCMClient{}, &mockSCMClient{}, &mockSCMClient{}}
+
+	if got, _ := selectClients(cfg, clients, ""); len(got) != 3 {
+		t.Errorf("Expected every client without a provider, got %d", len(got))
+	}
+	if got, _ := selectClients(cfg, clients, "work"); len(got) != 1 || got[0] != clients[0] {
+		t.Errorf("Expected the client named work, got %v", got)
+	}
+	if got, _ := selectClients(cfg, clients, "github"); len(got) != 2 || got[0] != clients[1] || got[1] != clients[2] {
+		t.Errorf("Expected both github clients, got %v", got)
+	}
+	if _, err := selectClients(cfg, clients, "missing"); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
+
+func TestExecRepositories(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cloned := clonedRepositories(cfg, repos)
+
+	var buf bytes.Buffer
+	results := execRepositories(cfg, cloned, `echo "in $GITSTUFF_REPO"; test ! -f wip.txt || exit 3`, 2, false, &buf)
+	if results[0].Err != nil || results[1].ExitCode != 3 {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	output := buf.String()
+	for _, want := range []string{"▶ group/clean\nin group/clean", "❌ group/dirty\nin group/dirty"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	buf.Reset()
+	_, command, _ := splitExecArgs([]string{"printf", "<%s>", "fix typo", "$HOME"}, 0)
+	execRepositories(cfg, cloned[:1], command, 1, false, &buf)
+	if !strings.Contains(buf.String(), "<fix typo><$HOME>") {
+		t.Errorf("Expected the arguments to reach the command unchanged, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	execRepositories(cfg, cloned, "true", 1, true, &buf)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output in quiet mode, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if failed := displayExecSummary(&buf, results); failed != 1 {
+		t.Errorf("Expected 1 failure, got %d", failed)
+	}
+	for _, want := range []string{"passed in 1 repositories, failed in 1", "group/dirty - exit code 3"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
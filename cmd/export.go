@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var archiveExportCmd = &cobra.Command{
+	Use:   "archive-export <output-dir> [group]",
+	Short: "Export source archives of cloned repositories without history",
+	Long: `Write an archive of the files of every cloned repository, or every
+repository in a group, at a ref to the output directory with git archive. The
+archives hold no .git directory or history, for handing code snapshots to
+auditors or other third parties.
+
+Each archive is written to <output-dir>/<provider>/<repository path> with the
+format's extension, and its files are under a directory named after the
+repository. A SHA256SUMS file listing the archives is written next to them.
+
+Without --ref the remote default branch is exported. Refs are resolved in the
+local clones, so run 'gitstuff sync --fetch-only' first to export what is on
+the remote. A branch that is not checked out locally is found on origin.
+
+Examples:
+  gitstuff archive-export ./audit                     # Default branches of every clone
+  gitstuff archive-export ./audit backend --ref v2.1  # A tag in one group
+  gitstuff archive-export ./audit --format zip --include 'payments/*'`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runArchiveExport,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveExportCmd)
+	archiveExportCmd.Flags().String("ref", "", "Branch, tag or commit to export (default: the remote default branch)")
+	archiveExportCmd.Flags().String("format", "tar.gz", "Archive format: "+strings.Join(git.ArchiveFormats, ", "))
+	archiveExportCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to export in parallel")
+	archiveExportCmd.Flags().String("provider", "", "Only repositories from the provider with this name or type")
+	addRepoFilterFlags(archiveExportCmd)
+}
+
+// checksumsFileName lists the exported archives with their SHA-256 sums in
+// the format of sha256sum
+const checksumsFileName = "SHA256SUMS"
+
+type exportResult struct {
+	Repo   *scm.Repository
+	File   string // Relative to the output directory, with forward slashes
+	Ref    string
+	Commit string
+	Err    error
+}
+
+func runArchiveExport(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	outputDir := args[0]
+	groupPath := ""
+	if len(args) == 2 {
+		groupPath = args[1]
+	}
+
+	ref, _ := cmd.Flags().GetString("ref")
+	format, _ := cmd.Flags().GetString("format")
+	if !slices.Contains(git.ArchiveFormats, format) {
+		return fmt.Errorf("unsupported --format %q (expected one of %s)", format, strings.Join(git.ArchiveFormats, ", "))
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	provider, _ := cmd.Flags().GetString("provider")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+
+	repos := collectRepositories(commandContext(cmd), selected, groupPath, filter)
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+	cloned := clonedRepositories(cfg, repos)
+	if skipped := len(repos) - len(cloned); skipped > 0 {
+		fmt.Fprintf(stdout, "%s\n", i18n.T("export.skipped_missing", skipped))
+	}
+	if len(cloned) == 0 {
+		return fmt.Errorf("no cloned repositories to export")
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("export.exporting", len(cloned), outputDir))
+	results := exportRepositories(cfg, cloned, outputDir, ref, format, jobs, stdout)
+	if err := writeChecksums(outputDir, results); err != nil {
+		return err
+	}
+	verbosity.DebugTiming(start, "Exported %d repositories", len(results))
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	fmt.Fprintf(stdout, "\n%s\n", i18n.T("export.summary", len(results)-failed, failed, filepath.Join(outputDir, checksumsFileName)))
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("export failed in %d of %d repositories", failed, len(results))
+	}
+	return nil
+}
+
+func exportRepositories(cfg *config.Config, repos []*scm.Repository, outputDir, ref, format string, jobs int, out io.Writer) []exportResult {
+	results := make([]exportResult, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			results[i] = exportRepository(paths.ResolveRepositoryPath(cfg, repo), repo, outputDir, ref, format)
+			if err := results[i].Err; err != nil {
+				fmt.Fprintf(w, "❌ [%s] %s: %v\n", repo.Provider, repo.FullPath, redact.Error(err))
+				return err
+			}
+			fmt.Fprintf(w, "📦 %s (%s @ %s)\n", results[i].File, results[i].Ref, results[i].Commit[:min(len(results[i].Commit), 12)])
+			return nil
+		}
+	}
+	runner.New(jobs).Run(tasks, out)
+	return results
+}
+
+// exportRepository archives ref of the clone at repoPath, or its default
+// branch when ref is empty
+func exportRepository(repoPath string, repo *scm.Repository, outputDir, ref, format string) exportResult {
+	result := exportResult{Repo: repo, File: repo.Provider + "/" + repo.FullPath + "." + format}
+
+	refs := []string{ref, "origin/" + ref}
+	if ref == "" {
+		refs = []string{"HEAD"}
+		if defaultRef := git.DefaultBranchRef(repoPath); defaultRef != "" {
+			refs = append([]string{defaultRef}, refs...)
+		}
+		if repo.DefaultBranch != "" {
+			refs = append([]string{"origin/" + repo.DefaultBranch}, refs...)
+		}
+	}
+	result.Ref = git.ResolveRef(repoPath, refs...)
+	switch {
+	case result.Ref != "":
+	case ref == "":
+		result.Err = errors.New("no default branch found")
+		return result
+	default:
+		result.Err = fmt.Errorf("ref %s not found", ref)
+		return result
+	}
+
+	target := filepath.Join(outputDir, filepath.FromSlash(result.File))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		result.Err = fmt.Errorf("failed to create directory: %w", err)
+		return result
+	}
+	result.Commit, result.Err = git.Archive(repoPath, result.Ref, format, repo.Name, target)
+	return result
+}
+
+// writeChecksums writes the SHA-256 sums of the exported archives to the
+// checksums file in outputDir
+func writeChecksums(outputDir string, results []exportResult) error {
+	var sums strings.Builder
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		sum, err := fileSHA256(filepath.Join(outputDir, filepath.FromSlash(result.File)))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sums, "%s  %s\n", sum, result.File)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, checksumsFileName), []byte(sums.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", checksumsFileName, err)
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read archive: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_ArchiveExport(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	clean := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	for _, args := range [][]string{
+		{"-C", clean, "tag", "v1.0"},
+		{"-C", clean, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "Unreleased"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}}
+
+	outputDir := filepath.Join(t.TempDir(), "audit")
+	out, err := runCommand(t, cfg, clients, "archive-export", outputDir, "--include", "group/clean", "--ref", "v1.0", "--format", "zip")
+	if err != nil {
+		t.Fatalf("archive-export failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"📦 gitlab/group/clean.zip (v1.0 @ ", "Exported 1 repositories, 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "gitlab", "group", "clean.zip")); err != nil {
+		t.Errorf("Expected the archive to exist: %v", err)
+	}
+	sums, err := os.ReadFile(filepath.Join(outputDir, checksumsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := exec.Command("sha256sum", "--check", "--strict", checksumsFileName)
+	check.Dir = outputDir
+	if _, err := exec.LookPath("sha256sum"); err == nil {
+		if out, err := check.CombinedOutput(); err != nil {
+			t.Errorf("Checksums do not verify: %v\n%s\n%s", err, out, sums)
+		}
+	}
+
+	out, err = runCommand(t, cfg, clients, "archive-export", outputDir, "--include", "group/*", "--ref", "v1.0", "--format", "zip")
+	if err == nil || !strings.Contains(err.Error(), "export failed in 1 of 2 repositories") {
+		t.Fatalf("Expected the repository without the tag to fail, got %v\n%s", err, out)
+	}
+	for _, want := range []string{"Skipping 1 repositories that are not cloned", "❌ [gitlab] group/dirty: ref v1.0 not found"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if _, err := runCommand(t, cfg, clients, "archive-export", outputDir, "--format", "rar"); err == nil || !strings.Contains(err.Error(), "unsupported --format") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
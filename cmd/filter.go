@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+// repoFilter decides which provider repositories a command works with
+type repoFilter struct {
+	includeArchived bool
+
+	// topics come from --topic; repositories must have all of them
+	topics []string
+
+	// labels come from --label; repositories must have all of them in
+	// annotations
+	labels      []string
+	annotations *state.State
+
+	// patterns come from flags and apply to every provider
+	patterns patternSet
+
+	// providerPatterns come from each provider's config
+	providerPatterns map[scm.Client]patternSet
+
+	// workspace comes from --workspace, which can also leave out whole
+	// providers
+	workspace         patternSet
+	excludedProviders map[scm.Client]bool
+}
+
+// patternSet matches repository paths against include and exclude patterns.
+// A path passes when it matches any include pattern (or there are none) and
+// no exclude pattern.
+type patternSet struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// noGroupFilter lifts the group each provider is restricted to in the config
+// file, for the commands with the repository filter flags
+var noGroupFilter bool
+
+func addRepoFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("include-archived", false, "Include archived repositories")
+	cmd.Flags().Bool("exclude-archived", true, "Exclude archived repositories (default)")
+	cmd.MarkFlagsMutuallyExclusive("include-archived", "exclude-archived")
+	cmd.Flags().StringSlice("include", nil, "Only repositories whose path matches a glob (or re:<regex>); repeatable")
+	cmd.Flags().StringSlice("exclude", nil, "Skip repositories whose path matches a glob (or re:<regex>); repeatable")
+	cmd.Flags().StringP("workspace", "w", "", "Only repositories in this workspace from the config file")
+	cmd.Flags().StringSlice("topic", nil, "Only repositories with this topic; repeatable, repositories must have all of them")
+	cmd.Flags().StringSlice("label", nil, "Only repositories with this local label (see 'gitstuff note'); repeatable, repositories must have all of them")
+	cmd.Flags().BoolVar(&noGroupFilter, "no-group-filter", false, "Do not restrict providers to the group set in their config")
+}
+
+// repoFilterFromFlags builds the filter for a command from its flags and the
+// include/exclude patterns of each provider. clients must be in the same
+// order as cfg.Providers, as returned by createClients.
+func repoFilterFromFlags(cmd *cobra.Command, cfg *config.Config, clients []scm.Client) (repoFilter, error) {
+	includeArchived, _ := cmd.Flags().GetBool("include-archived")
+	if excludeArchived, _ := cmd.Flags().GetBool("exclude-archived"); !excludeArchived {
+		includeArchived = true
+	}
+	filter := repoFilter{includeArchived: includeArchived}
+	filter.topics, _ = cmd.Flags().GetStringSlice("topic")
+	if filter.labels, _ = cmd.Flags().GetStringSlice("label"); len(filter.labels) > 0 {
+		annotations, err := state.Load(cfg.Local.BaseDir)
+		if err != nil {
+			return repoFilter{}, err
+		}
+		filter.annotations = annotations
+	}
+
+	include, _ := cmd.Flags().GetStringSlice("include")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	patterns, err := compilePatternSet(include, exclude)
+	if err != nil {
+		return repoFilter{}, err
+	}
+	filter.patterns = patterns
+
+	for i, client := range clients {
+		if i >= len(cfg.Providers) {
+			break
+		}
+		providerConfig := cfg.Providers[i]
+		if len(providerConfig.Include) == 0 && len(providerConfig.Exclude) == 0 {
+			continue
+		}
+		patterns, err := compilePatternSet(providerConfig.Include, providerConfig.Exclude)
+		if err != nil {
+			return repoFilter{}, fmt.Errorf("provider %s: %w", providerConfig.Name, err)
+		}
+		if filter.providerPatterns == nil {
+			filter.providerPatterns = make(map[scm.Client]patternSet)
+		}
+		filter.providerPatterns[client] = patterns
+	}
+
+	if name, _ := cmd.Flags().GetString("workspace"); name != "" {
+		if err := filter.useWorkspace(cfg, clients, name); err != nil {
+			return repoFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
+
+// useWorkspace limits the filter to the named workspace from cfg
+func (f *repoFilter) useWorkspace(cfg *config.Config, clients []scm.Client, name string) error {
+	workspace, ok := cfg.Workspaces[name]
+	if !ok {
+		names := make([]string, 0, len(cfg.Workspaces))
+		for configured := range cfg.Workspaces {
+			names = append(names, configured)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("unknown workspace %q: no workspaces are configured", name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown workspace %q (configured: %s)", name, strings.Join(names, ", "))
+	}
+
+	patterns, err := compilePatternSet(workspace.Include, workspace.Exclude)
+	if err != nil {
+		return fmt.Errorf("workspace %s: %w", name, err)
+	}
+	for _, repo := range workspace.Repos {
+		patterns.include = append(patterns.include, regexp.MustCompile("^"+regexp.QuoteMeta(strings.Trim(repo, "/"))+"$"))
+	}
+	for _, group := range workspace.Groups {
+		patterns.include = append(patterns.include, regexp.MustCompile("^"+regexp.QuoteMeta(strings.Trim(group, "/"))+"/"))
+	}
+	f.workspace = patterns
+
+	if len(workspace.Providers) == 0 {
+		return nil
+	}
+	for _, provider := range workspace.Providers {
+		if !slices.ContainsFunc(cfg.Providers, func(p config.ProviderConfig) bool { return p.Name == provider }) {
+			return fmt.Errorf("workspace %s: unknown provider %q", name, provider)
+		}
+	}
+	f.excludedProviders = make(map[scm.Client]bool)
+	for i, client := range clients {
+		if i < len(cfg.Providers) && !slices.Contains(workspace.Providers, cfg.Providers[i].Name) {
+			f.excludedProviders[client] = true
+		}
+	}
+	return nil
+}
+
+func (f repoFilter) matches(client scm.Client, repo *scm.Repository) bool {
+	if !f.includeArchived && repo.Archived {
+		return false
+	}
+	if !f.patterns.matches(repo.FullPath) || !f.workspace.matches(repo.FullPath) {
+		return false
+	}
+	if f.excludedProviders[client] {
+		return false
+	}
+	for _, topic := range f.topics {
+		if !slices.ContainsFunc(repo.Topics, func(t string) bool { return strings.EqualFold(t, topic) }) {
+			return false
+		}
+	}
+	if len(f.labels) > 0 {
+		annotation := f.annotations.Annotation(repo)
+		for _, label := range f.labels {
+			if !annotation.HasLabel(label) {
+				return false
+			}
+		}
+	}
+	if patterns, ok := f.providerPatterns[client]; ok && !patterns.matches(repo.FullPath) {
+		return false
+	}
+	return true
+}
+
+// apply returns the repositories that pass the filter, ignoring patterns
+// configured for a specific provider
+func (f repoFilter) apply(repos []*scm.Repository) []*scm.Repository {
+	return f.applyFor(nil, repos)
+}
+
+// applyFor returns the repositories from client that pass the filter
+func (f repoFilter) applyFor(client scm.Client, repos []*scm.Repository) []*scm.Repository {
+	filtered := make([]*scm.Repository, 0, len(repos))
+	for _, repo := range repos {
+		if f.matches(client, repo) {
+			filtered = append(filtered, repo)
+		}
+	}
+	if skipped := len(repos) - len(filtered); skipped > 0 {
+		verbosity.Debug("Filtered out %d repositories", skipped)
+	}
+	return filtered
+}
+
+// applyTree removes repositories that do not pass the filter from tree
+func (f repoFilter) applyTree(client scm.Client, tree *scm.RepositoryTree) {
+	if tree == nil {
+		return
+	}
+	tree.Repositories = f.applyFor(client, tree.Repositories)
+	for _, group := range tree.Groups {
+		f.applyGroup(client, group)
+	}
+}
+
+func (f repoFilter) applyGroup(client scm.Client, group *scm.GroupNode) {
+	group.Repositories = f.applyFor(client, group.Repositories)
+	for _, subGroup := range group.SubGroups {
+		f.applyGroup(client, subGroup)
+	}
+}
+
+func compilePatternSet(include, exclude []string) (patternSet, error) {
+	var set patternSet
+	for _, raw := range include {
+		re, err := compilePattern(raw)
+		if err != nil {
+			return patternSet{}, err
+		}
+		set.include = append(set.include, re)
+	}
+	for _, raw := range exclude {
+		re, err := compilePattern(raw)
+		if err != nil {
+			return patternSet{}, err
+		}
+		set.exclude = append(set.exclude, re)
+	}
+	return set, nil
+}
+
+func (s patternSet) matches(fullPath string) bool {
+	if len(s.include) > 0 && !matchesAny(s.include, fullPath) {
+		return false
+	}
+	return !matchesAny(s.exclude, fullPath)
+}
+
+func matchesAny(patterns []*regexp.Regexp, fullPath string) bool {
+	for _, re := range patterns {
+		if re.MatchString(fullPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePattern compiles a "re:" prefixed regular expression, or otherwise a
+// glob matched against the whole path where * and ? stay within one path
+// segment and ** crosses segments
+func compilePattern(raw string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(raw, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", raw, err)
+		}
+		return re, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; c {
+		case '*':
+			if i+1 < len(raw) && raw[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(raw[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid pattern %q: unterminated character class", raw)
+			}
+			class := raw[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", raw, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRepoFilter_Apply(t *testing.T) {
+	repos := []*scm.Repository{
+		{FullPath: "team/active"},
+		{FullPath: "team/old", Archived: true},
+		{FullPath: "team/current"},
+	}
+
+	excluded := repoFilter{}.apply(repos)
+	if len(excluded) != 2 || excluded[0].FullPath != "team/active" || excluded[1].FullPath != "team/current" {
+		t.Errorf("Expected archived repository to be excluded, got %v", excluded)
+	}
+
+	included := repoFilter{includeArchived: true}.apply(repos)
+	if len(included) != 3 {
+		t.Errorf("Expected all repositories with includeArchived, got %d", len(included))
+	}
+}
+
+func TestRepoFilter_Topics(t *testing.T) {
+	repos := []*scm.Repository{
+		{FullPath: "infra/network", Topics: []string{"terraform", "aws"}},
+		{FullPath: "infra/dns", Topics: []string{"Terraform"}},
+		{FullPath: "apps/api", Topics: []string{"go"}},
+	}
+
+	cmd := &cobra.Command{Use: "test"}
+	addRepoFilterFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--topic", "terraform"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	filter, err := repoFilterFromFlags(cmd, &config.Config{}, nil)
+	if err != nil {
+		t.Fatalf("repoFilterFromFlags failed: %v", err)
+	}
+	if got := filter.apply(repos); len(got) != 2 || got[1].FullPath != "infra/dns" {
+		t.Errorf("Expected both repositories tagged terraform, got %v", got)
+	}
+
+	filter.topics = []string{"terraform", "aws"}
+	if got := filter.apply(repos); len(got) != 1 || got[0].FullPath != "infra/network" {
+		t.Errorf("Expected only the repository with both topics, got %v", got)
+	}
+}
+
+func TestRepoFilter_ApplyTree(t *testing.T) {
+	tree := &scm.RepositoryTree{
+		Repositories: []*scm.Repository{{Name: "root", Archived: true}, {Name: "root-active"}},
+		Groups: map[string]*scm.GroupNode{
+			"team": {
+				Group:        &scm.Group{Name: "team"},
+				Repositories: []*scm.Repository{{Name: "legacy", Archived: true}},
+				SubGroups: map[string]*scm.GroupNode{
+					"sub": {
+						Group:        &scm.Group{Name: "sub"},
+						Repositories: []*scm.Repository{{Name: "service"}, {Name: "old-service", Archived: true}},
+					},
+				},
+			},
+		},
+	}
+
+	repoFilter{}.applyTree(nil, tree)
+
+	if len(tree.Repositories) != 1 || tree.Repositories[0].Name != "root-active" {
+		t.Errorf("Unexpected root repositories: %v", tree.Repositories)
+	}
+	if len(tree.Groups["team"].Repositories) != 0 {
+		t.Errorf("Expected archived group repository to be removed")
+	}
+	sub := tree.Groups["team"].SubGroups["sub"].Repositories
+	if len(sub) != 1 || sub[0].Name != "service" {
+		t.Errorf("Unexpected subgroup repositories: %v", sub)
+	}
+}
+
+func TestRepoFilterFromFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "default excludes", args: nil, want: false},
+		{name: "include archived", args: []string{"--include-archived"}, want: true},
+		{name: "exclude disabled", args: []string{"--exclude-archived=false"}, want: true},
+		{name: "explicit exclude", args: []string{"--exclude-archived"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			addRepoFilterFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags failed: %v", err)
+			}
+			filter, err := repoFilterFromFlags(cmd, &config.Config{}, nil)
+			if err != nil {
+				t.Fatalf("repoFilterFromFlags failed: %v", err)
+			}
+			if got := filter.includeArchived; got != tt.want {
+				t.Errorf("includeArchived = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*/sandbox-*", path: "team/sandbox-alice", want: true},
+		{pattern: "*/sandbox-*", path: "team/sub/sandbox-alice", want: false},
+		{pattern: "**/sandbox-*", path: "team/sub/sandbox-alice", want: true},
+		{pattern: "team/*", path: "team/api", want: true},
+		{pattern: "team/*", path: "other/api", want: false},
+		{pattern: "team/ap?", path: "team/api", want: true},
+		{pattern: "team/[ab]pi", path: "team/api", want: true},
+		{pattern: "team/[!ab]pi", path: "team/api", want: false},
+		{pattern: "team/a.i", path: "team/api", want: false},
+		{pattern: "re:^team/(api|web)$", path: "team/web", want: true},
+		{pattern: "re:legacy", path: "team/legacy-tool", want: true},
+		{pattern: "re:^legacy", path: "team/legacy-tool", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			re, err := compilePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("compilePattern(%q) failed: %v", tt.pattern, err)
+			}
+			if got := re.MatchString(tt.path); got != tt.want {
+				t.Errorf("match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilePattern_Invalid(t *testing.T) {
+	for _, pattern := range []string{"team/[abc", "re:(unclosed"} {
+		if _, err := compilePattern(pattern); err == nil {
+			t.Errorf("Expected error for pattern %q", pattern)
+		}
+	}
+}
+
+func TestRepoFilterFromFlags_Patterns(t *testing.T) {
+	repos := []*scm.Repository{
+		{FullPath: "team/api"},
+		{FullPath: "team/sandbox-alice"},
+		{FullPath: "other/web"},
+	}
+	gitlab := &mockSCMClient{providerType: "gitlab"}
+	github := &mockSCMClient{providerType: "github"}
+	cfg := &config.Config{Providers: []config.ProviderConfig{
+		{Name: "gitlab", Exclude: []string{"*/sandbox-*"}},
+		{Name: "github"},
+	}}
+
+	cmd := &cobra.Command{Use: "test"}
+	addRepoFilterFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--exclude", "other/*"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, []scm.Client{gitlab, github})
+	if err != nil {
+		t.Fatalf("repoFilterFromFlags failed: %v", err)
+	}
+
+	if got := filter.applyFor(gitlab, repos); len(got) != 1 || got[0].FullPath != "team/api" {
+		t.Errorf("Expected only team/api from gitlab, got %v", got)
+	}
+	if got := filter.applyFor(github, repos); len(got) != 2 {
+		t.Errorf("Expected config excludes not to apply to github, got %v", got)
+	}
+
+	cmd = &cobra.Command{Use: "test"}
+	addRepoFilterFlags(cmd)
+	if err := cmd.ParseFlags([]string{"--include", "re:^team/", "--include", "other/web"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	filter, err = repoFilterFromFlags(cmd, cfg, []scm.Client{gitlab, github})
+	if err != nil {
+		t.Fatalf("repoFilterFromFlags failed: %v", err)
+	}
+	if got := filter.applyFor(github, repos); len(got) != 3 {
+		t.Errorf("Expected all repositories to match an include, got %v", got)
+	}
+	if got := filter.applyFor(gitlab, repos); len(got) != 2 {
+		t.Errorf("Expected config exclude to still apply with includes, got %v", got)
+	}
+}
+
+func TestRepoFilterFromFlags_InvalidConfigPattern(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{{Name: "work", Include: []string{"re:("}}}}
+
+	cmd := &cobra.Command{Use: "test"}
+	addRepoFilterFlags(cmd)
+	_, err := repoFilterFromFlags(cmd, cfg, []scm.Client{&mockSCMClient{}})
+	if err == nil || !strings.Contains(err.Error(), "provider work") {
+		t.Errorf("Expected provider error for invalid pattern, got %v", err)
+	}
+}
+
+func TestRepoFilterFromFlags_Workspace(t *testing.T) {
+	repos := []*scm.Repository{
+		{FullPath: "web/storefront"},
+		{FullPath: "web/admin/dashboard"},
+		{FullPath: "web-legacy/site"},
+		{FullPath: "infra/terraform"},
+		{FullPath: "infra/ansible"},
+		{FullPath: "tools/oncall-bot"},
+	}
+	gitlab := &mockSCMClient{providerType: "gitlab"}
+	github := &mockSCMClient{providerType: "github"}
+	cfg := &config.Config{
+		Providers: []config.ProviderConfig{{Name: "gitlab"}, {Name: "github"}},
+		Workspaces: map[string]config.Workspace{
+			"frontend": {Groups: []string{"web"}, Exclude: []string{"**/admin/**"}},
+			"oncall":   {Repos: []string{"infra/terraform"}, Include: []string{"**/oncall-*"}, Providers: []string{"gitlab"}},
+			"broken":   {Include: []string{"re:("}},
+			"stranger": {Providers: []string{"bitbucket"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		client  scm.Client
+		want    []string
+		wantErr string
+	}{
+		{name: "groups and excludes", args: []string{"--workspace", "frontend"}, client: gitlab, want: []string{"web/storefront"}},
+		{name: "repos and includes", args: []string{"-w", "oncall"}, client: gitlab, want: []string{"infra/terraform", "tools/oncall-bot"}},
+		{name: "provider left out", args: []string{"-w", "oncall"}, client: github, want: []string{}},
+		{name: "combined with flags", args: []string{"-w", "frontend", "--exclude", "*/storefront"}, client: gitlab, want: []string{}},
+		{name: "unknown", args: []string{"-w", "backend"}, wantErr: "configured: broken, frontend, oncall, stranger"},
+		{name: "invalid pattern", args: []string{"-w", "broken"}, wantErr: "workspace broken"},
+		{name: "unknown provider", args: []string{"-w", "stranger"}, wantErr: `unknown provider "bitbucket"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			addRepoFilterFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags failed: %v", err)
+			}
+			filter, err := repoFilterFromFlags(cmd, cfg, []scm.Client{gitlab, github})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("repoFilterFromFlags failed: %v", err)
+			}
+
+			got := []string{}
+			for _, repo := range filter.applyFor(tt.client, repos) {
+				got = append(got, repo.FullPath)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+var forkCmd = &cobra.Command{
+	Use:   "fork <owner/repo>",
+	Short: "Fork a repository, clone the fork and add the original as upstream",
+	Long: `Fork a repository through the provider API, clone the fork to its usual
+place in the base directory and add the original repository as the upstream
+remote, so 'git fetch upstream' works right away.
+
+The fork goes to your own account, or to the group or organization given with
+--namespace. A fork that already exists there is cloned as is. Without
+--provider, the repository is looked up on each provider that can fork.
+
+Examples:
+  gitstuff fork kubernetes/kubectl
+  gitstuff fork platform/deploy-tools --provider work --namespace my-team
+  gitstuff fork golang/go --https`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFork,
+}
+
+func init() {
+	rootCmd.AddCommand(forkCmd)
+	forkCmd.Flags().String("provider", "", "Provider with this name or type to fork on")
+	forkCmd.Flags().String("namespace", "", "Group or organization to fork into (default: your own account)")
+	forkCmd.Flags().Bool("https", false, "Clone and add upstream with HTTPS URLs instead of SSH")
+}
+
+// upstreamRemote is the remote forks get for the repository they were
+// forked from
+const upstreamRemote = "upstream"
+
+func runFork(cmd *cobra.Command, args []string) error {
+	repoPath := args[0]
+	provider, _ := cmd.Flags().GetString("provider")
+	namespace, _ := cmd.Flags().GetString("namespace")
+	useHTTPS, _ := cmd.Flags().GetBool("https")
+
+	if err := checkWritable("fork repositories"); err != nil {
+		return err
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+	remotes, err := compileRemoteRules(cfg.Remotes)
+	if err != nil {
+		return err
+	}
+	setup, err := compileSetupRules(cfg.Setup)
+	if err != nil {
+		return err
+	}
+	managed, err := loadManagedInfo(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := commandContext(cmd)
+	forker, err := findForker(ctx, selected, repoPath)
+	if err != nil {
+		return err
+	}
+	fork, err := forker.ForkRepository(ctx, repoPath, namespace)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "🍴 %s\n\n", i18n.T("fork.forked", fork.ParentFullPath, fork.FullPath))
+
+	opts := cloneOptions{useSSH: !useHTTPS, protocolSet: useHTTPS, jobs: 1, remotes: remotes, setup: setup,
+		state: loadState(cfg, stdout), maintenance: cfg.Git.Maintenance, managed: managed, referenceForks: cfg.Git.ReferenceForks}
+	if summary := processRepositories(ctx, []*scm.Repository{fork}, cfg, opts, stdout); summary.Failed() > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("forked %s but could not clone it", fork.FullPath)
+	}
+
+	upstreamURL := cloneURLFor(cfg, &scm.Repository{Provider: fork.Provider, WebURL: fork.WebURL, CloneURL: fork.ParentCloneURL, SSHCloneURL: fork.ParentSSHCloneURL}, cloneUsesSSH(cfg, fork, opts))
+	if upstreamURL == "" {
+		fmt.Fprintf(stdout, "⚠️  %s\n", i18n.T("fork.no_upstream", fork.FullPath))
+		return nil
+	}
+	clonePath := paths.ResolveRepositoryPath(cfg, fork)
+	change, err := git.EnsureRemote(clonePath, upstreamRemote, upstreamURL)
+	if err != nil {
+		return fmt.Errorf("failed to add the %s remote: %w", upstreamRemote, redact.Error(err))
+	}
+	switch change {
+	case git.RemoteAdded:
+		fmt.Fprintf(stdout, "🔗 %s\n", i18n.T("remote.added", upstreamRemote, redact.String(upstreamURL)))
+	case git.RemoteUpdated:
+		fmt.Fprintf(stdout, "🔗 %s\n", i18n.T("remote.updated", upstreamRemote, redact.String(upstreamURL)))
+	}
+	fmt.Fprintln(stdout, i18n.T("fork.done", clonePath))
+	return nil
+}
+
+// findForker returns the client to fork repoPath with: the only client that
+// can fork, or else the first one the repository can be found on
+func findForker(ctx context.Context, clients []scm.Client, repoPath string) (scm.RepositoryForker, error) {
+	var forkers []scm.RepositoryForker
+	for _, client := range clients {
+		if forker, ok := scm.Unwrap(client).(scm.RepositoryForker); ok {
+			forkers = append(forkers, forker)
+		}
+	}
+	switch len(forkers) {
+	case 0:
+		return nil, fmt.Errorf("no configured provider supports forking")
+	case 1:
+		return forkers[0], nil
+	}
+
+	for _, forker := range forkers {
+		getter, ok := forker.(scm.RepositoryGetter)
+		if !ok {
+			continue
+		}
+		if _, err := getter.GetRepository(ctx, repoPath); err == nil {
+			return forker, nil
+		}
+	}
+	return nil, fmt.Errorf("repository '%s' not found on any provider that can fork (pick one with --provider)", repoPath)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+// mockForkClient forks every repository into fork
+type mockForkClient struct {
+	mockSCMClient
+	fork       *scm.Repository
+	namespaces []string
+}
+
+func (m *mockForkClient) ForkRepository(ctx context.Context, fullPath, namespace string) (*scm.Repository, error) {
+	m.namespaces = append(m.namespaces, namespace)
+	return m.fork, nil
+}
+
+func TestCommand_Fork(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	upstream := createRemoteRepo(t, filepath.Join(tempDir, "remotes", "upstream.git"))
+	forked := createRemoteRepo(t, filepath.Join(tempDir, "remotes", "fork.git"))
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+	client := &mockForkClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab"},
+		fork: &scm.Repository{
+			Name: "tool", FullPath: "me/tool", Provider: "gitlab",
+			CloneURL: forked, SSHCloneURL: forked,
+			Fork: true, ParentFullPath: "upstream/tool", ParentCloneURL: upstream, ParentSSHCloneURL: upstream,
+		},
+	}
+	clients := map[string]scm.Client{"work": client}
+
+	out, err := runCommand(t, cfg, clients, "fork", "upstream/tool", "--namespace", "me")
+	if err != nil {
+		t.Fatalf("fork failed: %v\n%s", err, out)
+	}
+	clonePath := filepath.Join(cfg.Local.BaseDir, "gitlab", "me", "tool")
+	for _, want := range []string{"Forked upstream/tool to me/tool", "Fork ready in " + clonePath} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if len(client.namespaces) != 1 || client.namespaces[0] != "me" {
+		t.Errorf("Expected a fork into me, got %v", client.namespaces)
+	}
+	if url, err := git.RemoteURL(clonePath, "origin"); err != nil || url != forked {
+		t.Errorf("Expected origin to be the fork, got %q (%v)", url, err)
+	}
+	if url, err := git.RemoteURL(clonePath, upstreamRemote); err != nil || url != upstream {
+		t.Errorf("Expected upstream to be the original, got %q (%v)", url, err)
+	}
+
+	// Forking again reuses the clone and leaves upstream alone
+	out, err = runCommand(t, cfg, clients, "fork", "upstream/tool")
+	if err != nil {
+		t.Fatalf("second fork failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "Added remote") {
+		t.Errorf("Expected upstream to be unchanged, got:\n%s", out)
+	}
+
+	plain := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab"}}
+	if _, err := runCommand(t, cfg, plain, "fork", "upstream/tool"); err == nil || !strings.Contains(err.Error(), "no configured provider supports forking") {
+		t.Errorf("Expected an unsupported provider error, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern> [group]",
+	Short: "Search the contents of all cloned repositories",
+	Long: `Search the tracked files of every cloned repository, or every repository in
+a group, for an extended regular expression with git grep, and print each
+matching line with its provider, repository and path within the repository.
+
+Repositories come from the configured providers, so the usual filters apply,
+and those not cloned yet are skipped. Only the checked out working trees are
+searched; binary files are skipped.
+
+Examples:
+  gitstuff grep 'db\.internal'                 # Search every clone
+  gitstuff grep -i --word-regexp todo backend  # Whole words in one group, ignoring case
+  gitstuff grep -F 'log.Printf(' --provider github
+  gitstuff grep -l 'golang.org/x/crypto' -j 8  # Only list the matching files`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runGrep,
+}
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Ignore case when matching")
+	grepCmd.Flags().BoolP("fixed-strings", "F", false, "Match the pattern literally instead of as a regular expression")
+	grepCmd.Flags().Bool("word-regexp", false, "Only match whole words")
+	grepCmd.Flags().BoolP("files-with-matches", "l", false, "Only list the matching files")
+	grepCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to search in parallel")
+	grepCmd.Flags().String("provider", "", "Only repositories from the provider with this name or type")
+	addRepoFilterFlags(grepCmd)
+}
+
+// maxGrepLineLength is how many characters of a matching line are shown
+const maxGrepLineLength = 200
+
+type grepResult struct {
+	Repo    *scm.Repository
+	Matches []git.GrepMatch
+	Err     error
+}
+
+func runGrep(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	pattern := args[0]
+	groupPath := ""
+	if len(args) == 2 {
+		groupPath = args[1]
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	var opts git.GrepOptions
+	opts.IgnoreCase, _ = cmd.Flags().GetBool("ignore-case")
+	opts.FixedStrings, _ = cmd.Flags().GetBool("fixed-strings")
+	opts.WordRegexp, _ = cmd.Flags().GetBool("word-regexp")
+	opts.FilesOnly, _ = cmd.Flags().GetBool("files-with-matches")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	provider, _ := cmd.Flags().GetString("provider")
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+
+	repos := collectRepositories(commandContext(cmd), selected, groupPath, filter)
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+	cloned := clonedRepositories(cfg, repos)
+	if skipped := len(repos) - len(cloned); skipped > 0 {
+		verbosity.Info("Skipping %d repositories that are not cloned", skipped)
+	}
+
+	results := grepRepositories(cfg, cloned, pattern, opts, jobs, stdout)
+	verbosity.DebugTiming(start, "Searched %d repositories", len(results))
+
+	if failed := displayGrepSummary(stdout, results, opts.FilesOnly); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("search failed in %d of %d repositories", failed, len(results))
+	}
+	return nil
+}
+
+// grepRepositories searches each repository with up to jobs at a time,
+// printing the matches of each repository together as it finishes
+func grepRepositories(cfg *config.Config, repos []*scm.Repository, pattern string, opts git.GrepOptions, jobs int, out io.Writer) []grepResult {
+	results := make([]grepResult, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			matches, err := git.Grep(paths.ResolveRepositoryPath(cfg, repo), pattern, opts)
+			results[i] = grepResult{Repo: repo, Matches: matches, Err: err}
+			if err != nil {
+				fmt.Fprintf(w, "❌ [%s] %s: %v\n", repo.Provider, repo.FullPath, redact.Error(err))
+				return err
+			}
+			for _, match := range matches {
+				fmt.Fprintln(w, formatGrepMatch(repo, match, opts.FilesOnly))
+			}
+			return nil
+		}
+	}
+	runner.New(jobs).Run(tasks, out)
+	return results
+}
+
+// formatGrepMatch formats a match as "[provider] repo: path:line: text", or
+// "[provider] repo: path" for files only
+func formatGrepMatch(repo *scm.Repository, match git.GrepMatch, filesOnly bool) string {
+	location := fmt.Sprintf("[%s] %s: %s", repo.Provider, repo.FullPath, match.Path)
+	if filesOnly {
+		return location
+	}
+	text := strings.TrimSpace(match.Text)
+	if utf8.RuneCountInString(text) > maxGrepLineLength {
+		text = string([]rune(text)[:maxGrepLineLength]) + "…"
+	}
+	return fmt.Sprintf("%s:%d: %s", location, match.Line, text)
+}
+
+// displayGrepSummary counts the matches and returns how many repositories
+// could not be searched
+func displayGrepSummary(w io.Writer, results []grepResult, filesOnly bool) int {
+	lines, failed, withMatches := 0, 0, 0
+	files := make(map[string]bool)
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		if len(result.Matches) > 0 {
+			withMatches++
+		}
+		for _, match := range result.Matches {
+			files[result.Repo.Provider+"\x00"+result.Repo.FullPath+"\x00"+match.Path] = true
+		}
+		lines += len(result.Matches)
+	}
+
+	if len(files) == 0 && failed == 0 {
+		fmt.Fprintln(w, i18n.T("grep.none", len(results)))
+		return 0
+	}
+	fmt.Fprintln(w)
+	if filesOnly {
+		fmt.Fprintln(w, i18n.T("grep.summary_files", len(files), withMatches, len(results)))
+	} else {
+		fmt.Fprintln(w, i18n.T("grep.summary", lines, len(files), withMatches, len(results)))
+	}
+	if failed > 0 {
+		fmt.Fprintf(w, "❌ %s\n", i18n.T("grep.failed", failed))
+	}
+	return failed
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+func TestFormatGrepMatch(t *testing.T) {
+	repo := &scm.Repository{FullPath: "team/api", Provider: "gitlab"}
+	match := git.GrepMatch{Path: "cmd/main.go", Line: 12, Text: "\t\tconnect(\"db.internal\")  "}
+
+	if got, want := formatGrepMatch(repo, match, false), `[gitlab] team/api: cmd/main.go:12: connect("db.internal")`; got != want {
+		t.Errorf("formatGrepMatch() = %q, want %q", got, want)
+	}
+	if got, want := formatGrepMatch(repo, match, true), "[gitlab] team/api: cmd/main.go"; got != want {
+		t.Errorf("formatGrepMatch() = %q, want %q", got, want)
+	}
+
+	match.Text = strings.Repeat("é", maxGrepLineLength+10)
+	if got := formatGrepMatch(repo, match, false); !strings.HasSuffix(got, strings.Repeat("é", maxGrepLineLength)+"…") {
+		t.Errorf("Expected long lines to be cut, got %q", got)
+	}
+}
+
+func TestCommand_Grep(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+
+	files := map[string]string{
+		"group/clean/main.go":        "package main\n\n// TODO: retry db.internal\n",
+		"group/clean/docs/README.md": "Talks to DB.internal\n",
+		"group/dirty/todo.txt":       "nothing to see\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(cfg.Local.BaseDir, "gitlab", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		repoPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", strings.Split(name, "/")[1])
+		if out, err := exec.Command("git", "-C", repoPath, "add", ".").CombinedOutput(); err != nil {
+			t.Fatalf("git add failed: %v\n%s", err, out)
+		}
+	}
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos, groupRepos: map[string][]*scm.Repository{"group": repos}}}
+
+	out, err := runCommand(t, cfg, clients, "grep", "-i", `db\.internal`)
+	if err != nil {
+		t.Fatalf("grep failed: %v\n%s", err, out)
+	}
+	want := "[gitlab] group/clean: docs/README.md:1: Talks to DB.internal\n" +
+		"[gitlab] group/clean: main.go:3: // TODO: retry db.internal\n" +
+		"\n2 matching lines in 2 files of 1 repositories (2 searched)\n"
+	if out != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", out, want)
+	}
+
+	if _, err := runCommand(t, cfg, clients, "grep", "(unclosed"); err == nil || !strings.Contains(err.Error(), "search failed in 2 of 2 repositories") {
+		t.Errorf("Expected an invalid pattern to fail, got %v", err)
+	}
+
+	out, err = runCommand(t, cfg, clients, "grep", "-l", "-F", "wip", "group")
+	if err != nil {
+		t.Fatalf("grep failed: %v\n%s", err, out)
+	}
+	if out != "No matches in 2 repositories\n" {
+		t.Errorf("Expected untracked files not to match, got:\n%s", out)
+	}
+}
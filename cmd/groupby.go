@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+)
+
+// listGroupings are the values list --group-by accepts
+var listGroupings = []string{"provider", "owner", "language", "topic"}
+
+// repoBucket is one section of list --group-by output
+type repoBucket struct {
+	name  string
+	repos []*scm.Repository
+}
+
+func validateGroupBy(groupBy string) error {
+	if groupBy == "" || slices.Contains(listGroupings, groupBy) {
+		return nil
+	}
+	return fmt.Errorf("invalid --group-by %q: use one of %s", groupBy, strings.Join(listGroupings, ", "))
+}
+
+// repoGroupKeys returns the buckets repo belongs in when grouping by
+// groupBy. A repository is in one bucket per topic, and in none when it has
+// no topics or its language is unknown.
+func repoGroupKeys(repo *scm.Repository, groupBy string) []string {
+	switch groupBy {
+	case "provider":
+		return []string{repo.Provider}
+	case "owner":
+		owner, _, _ := strings.Cut(repo.FullPath, "/")
+		return []string{owner}
+	case "language":
+		if repo.Language == "" {
+			return nil
+		}
+		return []string{repo.Language}
+	case "topic":
+		return repo.Topics
+	}
+	return nil
+}
+
+// bucketRepositories sorts repos into buckets by groupBy, ordered by name
+// with the repositories that have no value for it last. Repositories keep
+// their order within a bucket.
+func bucketRepositories(repos []*scm.Repository, groupBy string) []repoBucket {
+	index := make(map[string]int)
+	var buckets, missing []repoBucket
+	for _, repo := range repos {
+		keys := repoGroupKeys(repo, groupBy)
+		if len(keys) == 0 {
+			if missing == nil {
+				missing = []repoBucket{{name: missingBucketName(groupBy)}}
+			}
+			missing[0].repos = append(missing[0].repos, repo)
+			continue
+		}
+		for _, key := range keys {
+			i, ok := index[key]
+			if !ok {
+				i = len(buckets)
+				index[key] = i
+				buckets = append(buckets, repoBucket{name: key})
+			}
+			buckets[i].repos = append(buckets[i].repos, repo)
+		}
+	}
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return strings.ToLower(buckets[i].name) < strings.ToLower(buckets[j].name)
+	})
+	return append(buckets, missing...)
+}
+
+// missingBucketName names the bucket of repositories without a value for
+// groupBy
+func missingBucketName(groupBy string) string {
+	switch groupBy {
+	case "language":
+		return i18n.T("list.bucket_no_language")
+	case "topic":
+		return i18n.T("list.bucket_no_topic")
+	}
+	return i18n.T("list.bucket_none")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestBucketRepositories(t *testing.T) {
+	api := &scm.Repository{FullPath: "team/api", Provider: "gitlab", Language: "Go", Topics: []string{"backend", "api"}}
+	web := &scm.Repository{FullPath: "team/web", Provider: "github", Language: "TypeScript", Topics: []string{"frontend"}}
+	tool := &scm.Repository{FullPath: "octo/tool", Provider: "github", Language: "go"}
+	repos := []*scm.Repository{api, web, tool}
+
+	tests := []struct {
+		groupBy string
+		want    map[string][]*scm.Repository
+		order   []string
+	}{
+		{
+			groupBy: "provider",
+			order:   []string{"github", "gitlab"},
+			want:    map[string][]*scm.Repository{"github": {web, tool}, "gitlab": {api}},
+		},
+		{
+			groupBy: "owner",
+			order:   []string{"octo", "team"},
+			want:    map[string][]*scm.Repository{"octo": {tool}, "team": {api, web}},
+		},
+		{
+			groupBy: "language",
+			order:   []string{"Go", "go", "TypeScript"},
+			want:    map[string][]*scm.Repository{"Go": {api}, "go": {tool}, "TypeScript": {web}},
+		},
+		{
+			groupBy: "topic",
+			order:   []string{"api", "backend", "frontend", "(no topics)"},
+			want:    map[string][]*scm.Repository{"api": {api}, "backend": {api}, "frontend": {web}, "(no topics)": {tool}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			buckets := bucketRepositories(repos, tt.groupBy)
+			if len(buckets) != len(tt.order) {
+				t.Fatalf("Expected %d buckets, got %+v", len(tt.order), buckets)
+			}
+			for i, bucket := range buckets {
+				if bucket.name != tt.order[i] {
+					t.Errorf("Bucket %d = %q, want %q", i, bucket.name, tt.order[i])
+				}
+				want := tt.want[bucket.name]
+				if len(bucket.repos) != len(want) {
+					t.Errorf("Bucket %q has %d repositories, want %d", bucket.name, len(bucket.repos), len(want))
+					continue
+				}
+				for j := range want {
+					if bucket.repos[j] != want[j] {
+						t.Errorf("Bucket %q repository %d = %s, want %s", bucket.name, j, bucket.repos[j].FullPath, want[j].FullPath)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBucketRepositories_UnknownLanguageLast(t *testing.T) {
+	buckets := bucketRepositories([]*scm.Repository{
+		{FullPath: "team/docs"},
+		{FullPath: "team/api", Language: "Go"},
+	}, "language")
+	if len(buckets) != 2 || buckets[0].name != "Go" || buckets[1].name != "(unknown language)" {
+		t.Errorf("Expected repositories without a language last, got %+v", buckets)
+	}
+}
+
+func TestValidateGroupBy(t *testing.T) {
+	for _, value := range append([]string{""}, listGroupings...) {
+		if err := validateGroupBy(value); err != nil {
+			t.Errorf("validateGroupBy(%q) = %v", value, err)
+		}
+	}
+	if err := validateGroupBy("stars"); err == nil {
+		t.Error("Expected an error for an unknown grouping")
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/output"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runCommand runs the gitstuff command line args against cfg, with clients
+// standing in for the providers by name, and returns what it printed. $HOME
+// is a temporary directory, so no real config or cache is touched.
+func runCommand(t *testing.T, cfg *config.Config, clients map[string]scm.Client, args ...string) (string, error) {
+	t.Helper()
+	buf := useTestProviders(t, cfg, clients)
+	t.Cleanup(func() {
+		resetFlags(rootCmd)
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	})
+
+	// Usage and help go with the output instead of the test log
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stdout)
+	rootCmd.SetArgs(args)
+	err := rootCmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+// useTestProviders makes commands load cfg and use clients for the providers
+// by name until the test ends, and returns the buffer that collects their
+// output
+func useTestProviders(t *testing.T, cfg *config.Config, clients map[string]scm.Client) *bytes.Buffer {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME"} {
+		t.Setenv(env, "")
+	}
+
+	var buf bytes.Buffer
+	oldStdout, oldLoader, oldFactory := stdout, configLoader, newClientFactory
+	stdout = output.New(&buf)
+	configLoader = func() (*config.Config, error) {
+		if cfg == nil {
+			return nil, fmt.Errorf("config file not found")
+		}
+		return cfg, nil
+	}
+	newClientFactory = func(*config.Config) func(config.ProviderConfig) (scm.Client, error) {
+		return func(provider config.ProviderConfig) (scm.Client, error) {
+			client, ok := clients[provider.Name]
+			if !ok {
+				return nil, fmt.Errorf("no test client for provider %s", provider.Name)
+			}
+			return client, nil
+		}
+	}
+	t.Cleanup(func() {
+		stdout, configLoader, newClientFactory = oldStdout, oldLoader, oldFactory
+		readOnly = false
+		config.SetReadOnly(false)
+	})
+	return &buf
+}
+
+// resetFlags puts every flag of cmd and its subcommands back to its default,
+// since cobra keeps flag values between executions of the same command
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// testConfig returns a config for one provider per client name, with the
+// caches turned off and baseDir for clones
+func testConfig(baseDir string, names ...string) *config.Config {
+	cfg := &config.Config{
+		Local: config.LocalConfig{BaseDir: baseDir},
+		Cache: config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+	}
+	for _, name := range names {
+		cfg.Providers = append(cfg.Providers, config.ProviderConfig{Name: name, Type: "gitlab", URL: "https://" + name + ".example.com"})
+	}
+	return cfg
+}
+
+func TestCommand_List(t *testing.T) {
+	client := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+		{Name: "api", FullPath: "team/api", Provider: "gitlab"},
+		{Name: "web", FullPath: "team/web", Provider: "gitlab"},
+	}}
+
+	out, err := runCommand(t, testConfig(t.TempDir(), "work"), map[string]scm.Client{"work": client}, "list", "--status=false", "--exclude", "team/web")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "[gitlab] team/api") || strings.Contains(out, "team/web") {
+		t.Errorf("Unexpected list output:\n%s", out)
+	}
+}
+
+func TestCommand_ListGroupScope(t *testing.T) {
+	api := &scm.Repository{Name: "api", FullPath: "team/api", Provider: "gitlab"}
+	other := &scm.Repository{Name: "tool", FullPath: "other/tool", Provider: "gitlab"}
+	client := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{api, other}, groupRepos: map[string][]*scm.Repository{"team": {api}}}
+	cfg := testConfig(t.TempDir(), "work")
+	cfg.Providers[0].Group = "team"
+
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "list", "--status=false")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "team/api") || strings.Contains(out, "other/tool") {
+		t.Errorf("Expected only the configured group to be listed, got:\n%s", out)
+	}
+	resetFlags(rootCmd)
+
+	out, err = runCommand(t, cfg, map[string]scm.Client{"work": client}, "list", "--status=false", "--no-group-filter")
+	if err != nil {
+		t.Fatalf("list --no-group-filter failed: %v", err)
+	}
+	if !strings.Contains(out, "team/api") || !strings.Contains(out, "other/tool") {
+		t.Errorf("Expected every repository with --no-group-filter, got:\n%s", out)
+	}
+}
+
+func TestCommand_SyncDryRun(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--dry-run")
+	if err != nil {
+		t.Fatalf("sync --dry-run failed: %v", err)
+	}
+	for _, line := range []string{"🔄 pull   group/clean", "⚠️  skip   group/dirty", "📥 clone  group/missing", "Would clone 1, pull 1, skip 1, error 0"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing")) {
+		t.Error("Expected the dry run not to clone anything")
+	}
+}
+
+func TestCommand_ReadOnly(t *testing.T) {
+	client := &mockSCMClient{providerType: "gitlab"}
+	cfg := testConfig(t.TempDir(), "work")
+
+	_, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "--read-only", "sync")
+	if err == nil || !strings.Contains(err.Error(), "cannot sync repositories") {
+		t.Errorf("Expected sync to be refused, got %v", err)
+	}
+
+	// Flags from the previous run must not leak into the next one
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--dry-run")
+	if err != nil {
+		t.Fatalf("sync --dry-run failed: %v", err)
+	}
+	if !strings.Contains(out, "No repositories found") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+}
+
+func TestCommand_MissingConfig(t *testing.T) {
+	_, err := runCommand(t, nil, nil, "list")
+	if err == nil || !strings.Contains(err.Error(), "run 'gitstuff config' first") {
+		t.Errorf("Expected a missing config error, got %v", err)
+	}
+}
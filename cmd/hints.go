@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"gitstuff/internal/config"
+	"gitstuff/internal/hints"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/verbosity"
+)
+
+// longListing is how many repositories make a flat listing worth arranging
+// in a tree or sections
+const longListing = 50
+
+// hintRules suggest follow-up commands from the facts commands record
+var hintRules = []hints.Rule{
+	{
+		ID:      "list-not-cloned",
+		Command: "list",
+		When:    func(f hints.Facts) bool { return f["not_cloned"] > 0 },
+		Message: func(f hints.Facts) string { return i18n.T("hint.not_cloned", f["not_cloned"], f["total"]) },
+	},
+	{
+		ID:      "list-dirty",
+		Command: "list",
+		When:    func(f hints.Facts) bool { return f["dirty"] > 0 },
+		Message: func(f hints.Facts) string { return i18n.T("hint.dirty", f["dirty"]) },
+	},
+	{
+		ID:      "list-long",
+		Command: "list",
+		When:    func(f hints.Facts) bool { return f["total"] >= longListing && f["grouped"] == 0 },
+		Message: func(f hints.Facts) string { return i18n.T("hint.long_list", f["total"]) },
+	},
+	{
+		ID:      "sync-failed",
+		Command: "sync",
+		When:    func(f hints.Facts) bool { return f["failed"] > 0 },
+		Message: func(f hints.Facts) string { return i18n.T("hint.sync_failed") },
+	},
+	{
+		ID:      "sync-dirty",
+		Command: "sync",
+		When:    func(f hints.Facts) bool { return f["dirty"] > 0 },
+		Message: func(f hints.Facts) string { return i18n.T("hint.sync_dirty", f["dirty"]) },
+	},
+}
+
+// showHints writes the hints for what command found to stderr. Hints are
+// only shown on a terminal and not at all with disable_hints in the config.
+func showHints(cfg *config.Config, command string, facts hints.Facts) {
+	if cfg.DisableHints || !stdoutIsTerminal() {
+		return
+	}
+	seenPath := ""
+	if path, err := cfg.StatePath("hints.json"); err == nil {
+		seenPath = path
+	}
+	if _, err := hints.New(hintRules, seenPath).Show(stderr, command, facts); err != nil {
+		verbosity.Debug("Could not count shown hints: %v", err)
+	}
+}
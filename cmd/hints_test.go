@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/output"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_ListHints(t *testing.T) {
+	var hintsOut bytes.Buffer
+	oldStderr, oldTerminal := stderr, stdoutIsTerminal
+	stderr = output.New(&hintsOut)
+	stdoutIsTerminal = func() bool { return true }
+	defer func() { stderr, stdoutIsTerminal = oldStderr, oldTerminal }()
+
+	cfg := testConfig(t.TempDir(), "work")
+	cfg.Cache.Dir = t.TempDir()
+	client := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+		{Name: "api", FullPath: "team/api", Provider: "gitlab"},
+		{Name: "web", FullPath: "team/web", Provider: "gitlab"},
+	}}
+	clients := map[string]scm.Client{"work": client}
+
+	for i := 0; i < 4; i++ {
+		if _, err := runCommand(t, cfg, clients, "list"); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	}
+	if got := strings.Count(hintsOut.String(), "💡 2 of 2 repositories are not cloned"); got != 3 {
+		t.Errorf("Expected the hint the first 3 times, got %d:\n%s", got, hintsOut.String())
+	}
+
+	hintsOut.Reset()
+	cfg.Cache.Dir = t.TempDir()
+	cfg.DisableHints = true
+	if _, err := runCommand(t, cfg, clients, "list"); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if hintsOut.Len() != 0 {
+		t.Errorf("Expected no hints with disable_hints, got:\n%s", hintsOut.String())
+	}
+}
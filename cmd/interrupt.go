@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// interruptible is set while a loop that stops cleanly on interrupt is
+// running. At other times an interrupt exits straight away.
+var interruptible atomic.Bool
+
+// notifyInterrupt returns a context that is cancelled by the first interrupt
+// while interruptible is set, after which the repositories being worked on
+// are finished and no others started. A second interrupt kills the git
+// commands still running and a third exits straight away. stop releases the
+// signal handler and reports whether the run was interrupted.
+func notifyInterrupt(w io.Writer) (ctx context.Context, stop func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	abortCtx, abort := context.WithCancel(context.Background())
+	git.SetAbortContext(abortCtx)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		for count := 1; ; count++ {
+			select {
+			case <-signals:
+			case <-done:
+				return
+			}
+			switch {
+			case count == 1 && !interruptible.Load():
+				os.Exit(130)
+			case count == 1:
+				fmt.Fprintf(w, "\n⚠️  %s\n", i18n.T("interrupt.stopping"))
+				cancel()
+			case count == 2:
+				fmt.Fprintf(w, "\n⚠️  %s\n", i18n.T("interrupt.aborting"))
+				abort()
+			default:
+				os.Exit(130)
+			}
+		}
+	}()
+
+	return ctx, func() bool {
+		interrupted := ctx.Err() != nil
+		signal.Stop(signals)
+		close(done)
+		cancel()
+		abort()
+		return interrupted
+	}
+}
+
+// commandContext returns the context cmd runs with, which is only missing
+// when a command function is called directly
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var issuesCmd = &cobra.Command{
+	Use:   "issues [group]",
+	Short: "List the open issues assigned to you across providers",
+	Long: `List the open issues assigned to you on every configured provider, most
+recently updated first. Pull requests are not included.
+
+Examples:
+  gitstuff issues                          # Everything assigned to you
+  gitstuff issues backend                  # Only issues of repositories in a group
+  gitstuff issues --label bug --label p1   # Only issues with both labels
+  gitstuff issues --repo team/api          # Only issues of one repository`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runIssues,
+}
+
+func init() {
+	rootCmd.AddCommand(issuesCmd)
+	issuesCmd.Flags().StringSlice("label", nil, "Only issues with this label; repeatable, issues must have all of them")
+	issuesCmd.Flags().StringSlice("repo", nil, "Only issues of the repository with this full path; repeatable")
+	issuesCmd.Flags().String("provider", "", "Only issues from the provider with this name or type")
+}
+
+func runIssues(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	labels, _ := cmd.Flags().GetStringSlice("label")
+	repoPaths, _ := cmd.Flags().GetStringSlice("repo")
+	provider, _ := cmd.Flags().GetString("provider")
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = strings.Trim(args[0], "/")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return err
+	}
+
+	ctx := commandContext(cmd)
+	filter := scm.IssueFilter{Labels: labels}
+	var issues []scm.Issue
+	listed := 0
+	for _, client := range selected {
+		lister, ok := scm.Unwrap(client).(scm.IssueLister)
+		if !ok {
+			verbosity.Debug("Provider type %s cannot list issues", client.GetProviderType())
+			continue
+		}
+		found, err := lister.ListAssignedIssues(ctx, filter)
+		if err != nil {
+			fmt.Fprintf(stdout, "⚠️  %s\n", i18n.T("issues.provider_error", client.GetProviderType(), providerErrorText(err)))
+			continue
+		}
+		listed++
+		issues = append(issues, filterIssues(found, groupPath, repoPaths)...)
+	}
+	if listed == 0 {
+		return fmt.Errorf("no configured provider could list issues")
+	}
+	verbosity.DebugTiming(start, "Listed %d issues", len(issues))
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].UpdatedAt.After(issues[j].UpdatedAt) })
+	displayIssues(stdout, issues, time.Now())
+	return nil
+}
+
+// filterIssues keeps the issues of repositories in groupPath and, when any
+// are given, of one of repoPaths
+func filterIssues(issues []scm.Issue, groupPath string, repoPaths []string) []scm.Issue {
+	var kept []scm.Issue
+	for _, issue := range issues {
+		if groupPath != "" && !inGroup(issue.Repository, groupPath) {
+			continue
+		}
+		if len(repoPaths) > 0 && !slices.Contains(repoPaths, issue.Repository) {
+			continue
+		}
+		kept = append(kept, issue)
+	}
+	return kept
+}
+
+func displayIssues(w io.Writer, issues []scm.Issue, now time.Time) {
+	if len(issues) == 0 {
+		fmt.Fprintln(w, i18n.T("issues.none"))
+		return
+	}
+	fmt.Fprintf(w, "%s\n\n", i18n.T("issues.found", len(issues)))
+
+	for _, issue := range issues {
+		line := fmt.Sprintf("📌 [%s] %s#%d  %s", issue.Provider, issue.Repository, issue.Number, issue.Title)
+		if len(issue.Labels) > 0 {
+			line += fmt.Sprintf("  (%s)", strings.Join(issue.Labels, ", "))
+		}
+		if !issue.UpdatedAt.IsZero() {
+			line += "  " + i18n.T("issues.updated", formatAge(now.Sub(issue.UpdatedAt)))
+		}
+		fmt.Fprintln(w, line)
+		if issue.WebURL != "" {
+			fmt.Fprintf(w, "   %s\n", issue.WebURL)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+// mockIssueClient returns the issues that have every label of the filter
+type mockIssueClient struct {
+	mockSCMClient
+	issues []scm.Issue
+}
+
+func (m *mockIssueClient) ListAssignedIssues(ctx context.Context, filter scm.IssueFilter) ([]scm.Issue, error) {
+	var issues []scm.Issue
+	for _, issue := range m.issues {
+		matches := true
+		for _, label := range filter.Labels {
+			matches = matches && strings.Contains(strings.Join(issue.Labels, ","), label)
+		}
+		if matches {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+func TestCommand_Issues(t *testing.T) {
+	cfg := &config.Config{
+		Local: config.LocalConfig{BaseDir: filepath.Join(t.TempDir(), "repos")},
+		Cache: config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{
+			{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"},
+			{Name: "oss", Type: "github", URL: "https://github.com"},
+		},
+	}
+	now := time.Now()
+	clients := map[string]scm.Client{
+		"work": &mockIssueClient{mockSCMClient: mockSCMClient{providerType: "gitlab"}, issues: []scm.Issue{
+			{Number: 7, Title: "Crash on start", Repository: "backend/api", Labels: []string{"bug"}, Provider: "gitlab", UpdatedAt: now.Add(-3 * 24 * time.Hour), WebURL: "https://gitlab.example.com/backend/api/-/issues/7"},
+			{Number: 2, Title: "Update docs", Repository: "frontend/site", Provider: "gitlab", UpdatedAt: now.Add(-time.Hour)},
+		}},
+		"oss": &mockIssueClient{mockSCMClient: mockSCMClient{providerType: "github"}, issues: []scm.Issue{
+			{Number: 12, Title: "Login times out", Repository: "octo/tool", Labels: []string{"bug", "p1"}, Provider: "github", UpdatedAt: now.Add(-2 * time.Hour)},
+		}},
+	}
+
+	out, err := runCommand(t, cfg, clients, "issues")
+	if err != nil {
+		t.Fatalf("issues failed: %v\n%s", err, out)
+	}
+	first, second, third := strings.Index(out, "frontend/site#2"), strings.Index(out, "octo/tool#12"), strings.Index(out, "backend/api#7")
+	if !strings.Contains(out, "3 open issues assigned to you") || first < 0 || first > second || second > third {
+		t.Errorf("Expected all issues, most recently updated first, got:\n%s", out)
+	}
+	for _, want := range []string{"📌 [gitlab] backend/api#7  Crash on start  (bug)  updated 3d ago", "   https://gitlab.example.com/backend/api/-/issues/7"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out, err = runCommand(t, cfg, clients, "issues", "--label", "bug")
+	if err != nil || !strings.Contains(out, "2 open issues") || strings.Contains(out, "frontend/site") {
+		t.Errorf("Expected only issues labelled bug, got %v:\n%s", err, out)
+	}
+	out, err = runCommand(t, cfg, clients, "issues", "backend", "--label", "")
+	if err != nil || !strings.Contains(out, "1 open issues") || !strings.Contains(out, "backend/api#7") {
+		t.Errorf("Expected only issues in the backend group, got %v:\n%s", err, out)
+	}
+	out, err = runCommand(t, cfg, clients, "issues", "--repo", "octo/tool", "--provider", "oss")
+	if err != nil || !strings.Contains(out, "1 open issues") || !strings.Contains(out, "octo/tool#12") {
+		t.Errorf("Expected only the issues of octo/tool, got %v:\n%s", err, out)
+	}
+
+	plain := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab"}, "oss": &mockSCMClient{providerType: "github"}}
+	if _, err := runCommand(t, cfg, plain, "issues", "--repo", "", "--provider", ""); err == nil || !strings.Contains(err.Error(), "could list issues") {
+		t.Errorf("Expected an error without issue support, got %v", err)
+	}
+}
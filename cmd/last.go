@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var lastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Show the summary of the last clone, sync or daemon run",
+	Long: `Show what the last clone of several repositories, sync or daemon run did:
+its counts, the repositories that failed and those that took longest. Every
+such run writes its summary to .gitstuff-last-run.json in the base directory,
+so wrapper scripts and cron jobs can check the previous unattended run
+without digging through logs.
+
+Examples:
+  gitstuff last                                   # Summary of the last run
+  gitstuff last --json | jq '.failed'             # The file as written`,
+	Args: cobra.NoArgs,
+	RunE: runLast,
+}
+
+// lastRunSlowest is how many of the slowest repositories a run keeps
+const lastRunSlowest = 5
+
+func init() {
+	rootCmd.AddCommand(lastCmd)
+	lastCmd.Flags().Bool("json", false, "Print the summary as JSON")
+}
+
+func runLast(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	run, err := state.LoadRun(cfg.Local.BaseDir)
+	if errors.Is(err, os.ErrNotExist) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%s", i18n.T("last.none", cfg.Local.BaseDir))
+	}
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(run)
+	}
+	displayLastRun(stdout, run, time.Now())
+	return nil
+}
+
+// newLastRun summarizes a run of command that started at started and ended
+// with summary, or with err before any repository was processed
+func newLastRun(command string, args []string, started time.Time, summary *processSummary, err error) *state.Run {
+	run := &state.Run{Command: command, Args: args, Started: started, Finished: time.Now()}
+	run.Seconds = run.Duration().Round(time.Millisecond).Seconds()
+	if err != nil {
+		run.Error = redact.Error(err).Error()
+	}
+	if summary == nil {
+		return run
+	}
+
+	run.Cloned = summary.Cloned
+	run.Updated = summary.Updated
+	run.Fetched = summary.Fetched
+	run.Skipped = summary.Skipped
+	run.Dirty = len(summary.Dirty)
+	run.Protected = len(summary.Protected)
+	run.Failed = summary.Failed()
+	run.Interrupted = len(summary.Interrupted)
+	run.Removed = removedCount(summary.Retention)
+	run.Repositories = summary.Successful() + run.Failed + run.Interrupted
+	for _, failure := range summary.Failures {
+		run.Failures = append(run.Failures, state.RunFailure{
+			Repository: failure.Repo.FullPath,
+			Provider:   failure.Repo.Provider,
+			Error:      redact.Error(failure.Err).Error(),
+		})
+	}
+	for _, action := range summary.Retention {
+		if action.Err != nil {
+			run.Failures = append(run.Failures, state.RunFailure{Repository: action.label(), Error: redact.Error(action.Err).Error()})
+		}
+	}
+
+	for _, retry := range summary.Retried {
+		run.Retried = append(run.Retried, state.RunRetry{Repository: retry.Repo.FullPath, Provider: retry.Repo.Provider, Attempts: retry.Attempts})
+	}
+
+	durations := append([]repoDuration(nil), summary.Durations...)
+	sort.SliceStable(durations, func(i, j int) bool { return durations[i].Duration > durations[j].Duration })
+	for _, timing := range durations[:min(len(durations), lastRunSlowest)] {
+		run.Slowest = append(run.Slowest, state.RunTiming{
+			Repository: timing.Repo.FullPath,
+			Provider:   timing.Repo.Provider,
+			Seconds:    timing.Duration.Round(time.Millisecond).Seconds(),
+		})
+	}
+	return run
+}
+
+// recordLastRun writes run as the last run of cfg's base directory. Not
+// being able to is reported, but does not fail the run.
+func recordLastRun(cfg *config.Config, run *state.Run) {
+	if err := state.SaveRun(cfg.Local.BaseDir, run); err != nil {
+		fmt.Fprintf(stderr, "⚠️  %s\n", i18n.T("last.unwritable", redact.Error(err)))
+	}
+}
+
+func displayLastRun(w io.Writer, run *state.Run, now time.Time) {
+	command := strings.TrimSpace(run.Command + " " + strings.Join(run.Args, " "))
+	fmt.Fprintln(w, i18n.T("last.header", command, run.Started.Local().Format("2006-01-02 15:04:05"),
+		run.Duration().Round(time.Second), formatAge(now.Sub(run.Finished))))
+	if run.Error != "" {
+		fmt.Fprintf(w, "  ❌ %s\n", i18n.T("last.error", run.Error))
+		return
+	}
+
+	fmt.Fprintf(w, "  📥 %s\n", i18n.T("sync.summary_cloned", run.Cloned))
+	fmt.Fprintf(w, "  🔄 %s\n", i18n.T("sync.summary_updated", run.Updated))
+	if run.Fetched > 0 {
+		fmt.Fprintf(w, "  📡 %s\n", i18n.T("sync.summary_fetched", run.Fetched))
+	}
+	if run.Skipped > 0 {
+		fmt.Fprintf(w, "  ⏭️  %s\n", i18n.T("last.skipped", run.Skipped))
+	}
+	fmt.Fprintf(w, "  ⚠️  %s\n", i18n.T("sync.summary_skipped", run.Dirty))
+	if run.Protected > 0 {
+		fmt.Fprintf(w, "  ⚠️  %s\n", i18n.T("sync.summary_protected", run.Protected))
+	}
+	fmt.Fprintf(w, "  ❌ %s\n", i18n.T("sync.summary_failed", run.Failed))
+	if run.Interrupted > 0 {
+		fmt.Fprintf(w, "  ⏹️  %s\n", i18n.T("sync.summary_interrupted", run.Interrupted))
+	}
+	if run.Removed > 0 {
+		fmt.Fprintf(w, "  🧹 %s\n", i18n.T("sync.summary_removed", run.Removed))
+	}
+
+	if len(run.Failures) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("sync.failed_header"))
+		for _, failure := range run.Failures {
+			if failure.Provider != "" {
+				fmt.Fprintf(w, "  - %s [%s]: %s\n", failure.Repository, failure.Provider, failure.Error)
+			} else {
+				fmt.Fprintf(w, "  - %s: %s\n", failure.Repository, failure.Error)
+			}
+		}
+	}
+	if len(run.Retried) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("sync.retried_header"))
+		for _, retry := range run.Retried {
+			fmt.Fprintf(w, "  - %s [%s]: %s\n", retry.Repository, retry.Provider, i18n.T("sync.retried_attempts", retry.Attempts))
+		}
+	}
+	if len(run.Slowest) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("last.slowest"))
+		for _, timing := range run.Slowest {
+			duration := time.Duration(timing.Seconds * float64(time.Second)).Round(100 * time.Millisecond)
+			fmt.Fprintf(w, "  %s [%s]  %s\n", timing.Repository, timing.Provider, duration)
+		}
+	}
+}
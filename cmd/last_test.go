@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+)
+
+func TestCommand_SyncRecordsLastRun(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	repos = append(repos, &scm.Repository{Name: "gone", FullPath: "group/gone", Provider: "gitlab", CloneURL: filepath.Join(t.TempDir(), "gone.git")})
+
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+	if _, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--https"); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	run, err := state.LoadRun(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("Expected the run to be recorded: %v", err)
+	}
+	if run.Command != "sync" || run.Repositories != 4 || run.Cloned != 1 || run.Dirty != 1 || run.Failed != 1 {
+		t.Errorf("Unexpected run %+v", run)
+	}
+	if len(run.Failures) != 1 || run.Failures[0].Repository != "group/gone" {
+		t.Errorf("Expected group/gone to be recorded as failed, got %+v", run.Failures)
+	}
+	if len(run.Slowest) != 4 {
+		t.Errorf("Expected a timing for every repository, got %+v", run.Slowest)
+	}
+	resetFlags(rootCmd)
+
+	out, err := runCommand(t, cfg, nil, "last")
+	if err != nil {
+		t.Fatalf("last failed: %v", err)
+	}
+	for _, want := range []string{"Last run: sync", "Cloned:  1", "Failed:  1", "group/gone [gitlab]", "Slowest repositories:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	resetFlags(rootCmd)
+
+	out, err = runCommand(t, cfg, nil, "last", "--json")
+	if err != nil {
+		t.Fatalf("last --json failed: %v", err)
+	}
+	var decoded state.Run
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("Expected JSON output, got %v:\n%s", err, out)
+	}
+	if decoded.Failed != 1 {
+		t.Errorf("Expected 1 failure in the JSON, got %+v", decoded)
+	}
+}
+
+func TestCommand_LastWithoutRun(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: t.TempDir()}}
+	if _, err := runCommand(t, cfg, nil, "last"); err == nil || !strings.Contains(err.Error(), "No clone, sync or daemon run") {
+		t.Errorf("Expected an error saying nothing ran yet, got %v", err)
+	}
+}
+
+func TestNewLastRun_Error(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+	run := newLastRun("daemon", nil, started, nil, errors.New("no providers configured"))
+	if run.Error != "no providers configured" || run.Seconds < 59 {
+		t.Errorf("Unexpected run %+v", run)
+	}
+
+	var out strings.Builder
+	displayLastRun(&out, run, time.Now())
+	if !strings.Contains(out.String(), "Stopped before processing repositories: no providers configured") {
+		t.Errorf("Expected the error to be shown, got:\n%s", out.String())
+	}
+}
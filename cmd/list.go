@@ -1,16 +1,27 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"gitstuff/internal/cache"
 	"gitstuff/internal/config"
 	"gitstuff/internal/git"
 	"gitstuff/internal/github"
 	"gitstuff/internal/gitlab"
+	"gitstuff/internal/hints"
+	"gitstuff/internal/httpclient"
+	"gitstuff/internal/i18n"
 	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
 	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/theme"
 	"gitstuff/internal/verbosity"
 
 	"github.com/spf13/cobra"
@@ -18,16 +29,134 @@ import (
 
 // createClient creates an SCM client based on the provider config
 func createClient(providerConfig config.ProviderConfig) (scm.Client, error) {
+	return createClientWithOptions(providerConfig, httpclient.Options{
+		Insecure:  providerConfig.Insecure,
+		UserAgent: userAgent(""),
+	})
+}
+
+// userAgent identifies gitstuff on provider requests, with an optional
+// suffix from config for proxies that audit clients
+func userAgent(suffix string) string {
+	agent := "gitstuff/" + version
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		agent += " " + suffix
+	}
+	return agent
+}
+
+func createClientWithOptions(providerConfig config.ProviderConfig, opts httpclient.Options) (scm.Client, error) {
 	switch providerConfig.Type {
 	case "gitlab":
-		return gitlab.NewClient(providerConfig.URL, providerConfig.Token, providerConfig.Insecure)
+		return gitlab.NewClientWithOptions(providerConfig.URL, providerConfig.Token, opts)
 	case "github":
-		return github.NewClient(providerConfig.URL, providerConfig.Token, providerConfig.Insecure)
+		return github.NewClientWithOptions(providerConfig.URL, providerConfig.Token, opts)
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", providerConfig.Type)
 	}
 }
 
+// clientFactory returns a client constructor that applies the HTTP settings
+// from cfg, such as the response cache, to every provider
+func clientFactory(cfg *config.Config) func(config.ProviderConfig) (scm.Client, error) {
+	cacheDir := ""
+	if !noCache && !cfg.Cache.DisableHTTP {
+		if dir, err := cfg.CacheDir(); err == nil {
+			cacheDir = filepath.Join(dir, "http")
+		} else {
+			verbosity.Debug("HTTP cache disabled: %v", err)
+		}
+	}
+
+	agent := userAgent(cfg.HTTP.UserAgentSuffix)
+	return func(providerConfig config.ProviderConfig) (scm.Client, error) {
+		return createClientWithOptions(providerConfig, httpclient.Options{
+			Insecure:  providerConfig.Insecure,
+			CacheDir:  cacheDir,
+			UserAgent: agent,
+		})
+	}
+}
+
+// metadataStore returns the repository metadata cache for cfg, or nil when
+// it is disabled
+func metadataStore(cfg *config.Config) (*cache.Store, error) {
+	if noCache || cfg.Cache.DisableMetadata {
+		return nil, nil
+	}
+	ttl, err := cfg.MetadataCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := cfg.CacheDir()
+	if err != nil {
+		verbosity.Debug("Metadata cache disabled: %v", err)
+		return nil, nil
+	}
+	return cache.NewStore(filepath.Join(dir, "metadata"), ttl), nil
+}
+
+// createClients creates clients for all configured providers, restricted to
+// the group set for them unless --no-group-filter is given and wrapped with
+// the repository metadata cache when it is enabled
+func createClients(cfg *config.Config) ([]scm.Client, error) {
+	return newClients(cfg, !noGroupFilter)
+}
+
+// createUnscopedClients is createClients ignoring the group each provider is
+// restricted to, for commands that must see every repository
+func createUnscopedClients(cfg *config.Config) ([]scm.Client, error) {
+	return newClients(cfg, false)
+}
+
+// reportStaleListing warns on w when the last listing of client came from
+// the metadata cache because its provider was unavailable, and reports
+// whether it did
+func reportStaleListing(w io.Writer, client scm.Client) bool {
+	cached, ok := client.(*cache.Client)
+	if !ok {
+		return false
+	}
+	since, err := cached.Stale()
+	if since.IsZero() {
+		return false
+	}
+	fmt.Fprintf(w, "⚠️  %s\n", i18n.T("list.stale", client.GetProviderType(), providerErrorText(err), formatAge(time.Since(since))))
+	return true
+}
+
+func newClients(cfg *config.Config, scoped bool) ([]scm.Client, error) {
+	store, err := metadataStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	newClient := newClientFactory(cfg)
+	clients := make([]scm.Client, 0, len(cfg.Providers))
+	for _, providerConfig := range cfg.Providers {
+		verbosity.Debug("Creating client for provider: %s (%s)", providerConfig.Name, providerConfig.Type)
+		client, err := newClient(providerConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for provider %s: %w", providerConfig.Name, err)
+		}
+		group := ""
+		if scoped {
+			group = providerConfig.Group
+		}
+		client = scm.Scope(client, group)
+		if store != nil {
+			key := cache.Key(providerConfig.Name, providerConfig.URL, providerConfig.Token)
+			if group != "" {
+				key = cache.Key(key, "scope", group)
+			}
+			client = cache.Wrap(client, store, key, refreshCache)
+		}
+		clients = append(clients, client)
+	}
+	checkProviderVersions(context.Background(), cfg, cfg.Providers, clients)
+	return clients, nil
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all repositories from configured SCM providers",
@@ -40,122 +169,250 @@ func init() {
 	listCmd.Flags().BoolP("tree", "t", false, "Display repositories in tree structure with groups")
 	listCmd.Flags().BoolP("status", "s", true, "Show local repository status")
 	listCmd.Flags().StringP("group", "g", "", "Filter repositories to only those in the specified group")
+	listCmd.Flags().Bool("ci", false, "Show the CI status of each repository's default branch")
+	listCmd.Flags().Bool("json", false, "Output the repositories and their metadata as JSON")
+	listCmd.MarkFlagsMutuallyExclusive("json", "tree")
+	listCmd.MarkFlagsMutuallyExclusive("json", "ci")
+	listCmd.Flags().String("group-by", "", "Show repositories in sections with counts by provider, owner, language or topic")
+	listCmd.MarkFlagsMutuallyExclusive("group-by", "tree")
+	listCmd.MarkFlagsMutuallyExclusive("group-by", "json")
+	_ = listCmd.RegisterFlagCompletionFunc("group-by", cobra.FixedCompletions(listGroupings, cobra.ShellCompDirectiveNoFileComp))
+	addRepoFilterFlags(listCmd)
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	groupBy, _ := cmd.Flags().GetString("group-by")
+	if err := validateGroupBy(groupBy); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
 	}
 
-	// Create clients for all configured providers
-	var clients []scm.Client
-	for _, providerConfig := range cfg.Providers {
-		client, err := createClient(providerConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create client for provider %s: %w", providerConfig.Name, err)
-		}
-		clients = append(clients, client)
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
 	}
 
 	showTree, _ := cmd.Flags().GetBool("tree")
 	showStatus, _ := cmd.Flags().GetBool("status")
+	showCI, _ := cmd.Flags().GetBool("ci")
+	asJSON, _ := cmd.Flags().GetBool("json")
 	groupFilter, _ := cmd.Flags().GetString("group")
-
-	// Use group from flag first, then from any provider config, then empty string
-	targetGroup := groupFilter
-	if targetGroup == "" {
-		for _, providerConfig := range cfg.Providers {
-			if providerConfig.Group != "" {
-				targetGroup = providerConfig.Group
-				break
-			}
-		}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
 	}
 
+	// Providers with a group in their config only list that group
+	if asJSON {
+		return writeRepositoryListJSON(commandContext(cmd), clients, cfg, groupFilter, filter)
+	}
 	if showTree {
-		return displayRepositoryTree(clients, cfg, showStatus, targetGroup)
+		return displayRepositoryTree(commandContext(cmd), clients, cfg, showStatus, showCI, groupFilter, filter)
 	} else {
-		return displayRepositoryList(clients, cfg, showStatus, targetGroup)
+		return displayRepositoryList(commandContext(cmd), clients, cfg, showStatus, showCI, groupFilter, groupBy, filter)
 	}
 }
 
-func displayRepositoryList(clients []scm.Client, cfg *config.Config, showStatus bool, groupFilter string) error {
+func displayRepositoryList(ctx context.Context, clients []scm.Client, cfg *config.Config, showStatus, showCI bool, groupFilter, groupBy string, filter repoFilter) error {
 	start := time.Now()
 	verbosity.Debug("Starting repository list from %d providers", len(clients))
 
 	var allRepos []*scm.Repository
+	fetchers := make(readmeFetchers)
+	var pipelines pipelineStatuses
+	if showCI {
+		pipelines = make(pipelineStatuses)
+	}
 
-	for _, client := range clients {
-		var repos []*scm.Repository
-		var err error
-
-		clientStart := time.Now()
-		if groupFilter != "" {
-			verbosity.Debug("Fetching repositories from %s provider in group: %s", client.GetProviderType(), groupFilter)
-			repos, err = client.ListRepositoriesInGroup(groupFilter)
-		} else {
-			verbosity.Debug("Fetching all repositories from %s provider", client.GetProviderType())
-			repos, err = client.ListAllRepositories()
+	stale := make(map[*scm.Repository]bool)
+	fetched, errs := fetchProviderRepositories(ctx, clients, groupFilter)
+	for i, client := range clients {
+		if errs[i] != nil {
+			return &providerError{provider: client.GetProviderType(), err: errs[i]}
 		}
-		if err != nil {
-			return fmt.Errorf("error from %s provider: %w", client.GetProviderType(), err)
+		repos := filter.applyFor(client, fetched[i])
+		if reportStaleListing(stdout, client) {
+			for _, repo := range repos {
+				stale[repo] = true
+			}
+		}
+		fetchers.add(client, repos)
+		if verbosity.IsEnabled(verbosity.InfoLevel) || groupBy == "language" {
+			detectLanguages(ctx, client, repos)
+		}
+		if pipelines != nil {
+			pipelines.fetch(ctx, client, repos)
 		}
-		verbosity.DebugTiming(clientStart, "Fetched %d repositories from %s provider", len(repos), client.GetProviderType())
 		allRepos = append(allRepos, repos...)
 	}
 
 	verbosity.DebugTiming(start, "Repository discovery completed")
-	fmt.Printf("Found %d repositories:\n\n", len(allRepos))
 
-	for _, repo := range allRepos {
-		fmt.Printf("📁 [%s] %s\n", repo.Provider, repo.FullPath)
+	var readmes map[*scm.Repository][]string
+	if verbosity.IsEnabled(verbosity.InfoLevel) {
+		readmeStart := time.Now()
+		readmes = fetchers.previews(ctx, allRepos)
+		verbosity.DebugTiming(readmeStart, "Fetched %d README previews", len(readmes))
+	}
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("list.found", len(allRepos)))
 
-		if verbosity.IsEnabled(verbosity.InfoLevel) {
-			fmt.Printf("   Web URL: %s\n", repo.WebURL)
-			fmt.Printf("   SSH URL: %s\n", repo.SSHCloneURL)
+	entry := listEntryWriter{cfg: cfg, showStatus: showStatus, stale: stale, readmes: readmes, pipelines: pipelines, now: time.Now(),
+		annotations: loadState(cfg, stderr), facts: hints.Facts{"total": len(allRepos)}}
+	if groupBy == "" {
+		for _, repo := range allRepos {
+			entry.write(repo)
+		}
+	} else {
+		for _, bucket := range bucketRepositories(allRepos, groupBy) {
+			fmt.Fprintf(stdout, "%s\n\n", i18n.T("list.bucket", bucket.name, len(bucket.repos)))
+			for _, repo := range bucket.repos {
+				entry.write(repo)
+			}
 		}
+	}
 
-		if verbosity.IsEnabled(verbosity.DebugLevel) {
-			fmt.Printf("   Clone URL: %s\n", repo.CloneURL)
-			fmt.Printf("   Default Branch: %s\n", repo.DefaultBranch)
-			fmt.Printf("   Provider: %s\n", repo.Provider)
+	if pipelines != nil {
+		displayPipelineSummary(pipelines)
+	}
+	if groupBy != "" {
+		entry.facts["grouped"] = 1
+	}
+	showHints(cfg, "list", entry.facts)
+	return nil
+}
+
+// listEntryWriter writes the repositories of the flat list, with the details
+// gathered for them before
+type listEntryWriter struct {
+	cfg        *config.Config
+	showStatus bool
+	stale      map[*scm.Repository]bool
+	readmes    map[*scm.Repository][]string
+	pipelines  pipelineStatuses
+	now        time.Time
+
+	// annotations hold the local notes and labels shown under repositories
+	annotations *state.State
+
+	// facts count what the listing found, for hints
+	facts hints.Facts
+}
+
+func (e listEntryWriter) write(repo *scm.Repository) {
+	repoLine := fmt.Sprintf("📁 [%s] %s", repo.Provider, repo.FullPath)
+	if len(repo.Topics) > 0 {
+		repoLine += "  🏷️  " + strings.Join(repo.Topics, ", ")
+	}
+	if e.stale[repo] {
+		repoLine += "  " + i18n.T("list.stale_marker")
+	}
+	fmt.Fprintln(stdout, repoLine)
+	writeAnnotation(stdout, e.annotations.Annotation(repo))
+
+	if verbosity.IsEnabled(verbosity.InfoLevel) {
+		fmt.Fprintf(stdout, "   %s\n", i18n.T("field.web_url", repo.WebURL))
+		fmt.Fprintf(stdout, "   %s\n", i18n.T("field.ssh_url", repo.SSHCloneURL))
+		if metadata := formatRepoMetadata(repo, e.now); metadata != "" {
+			fmt.Fprintf(stdout, "   📊 %s\n", metadata)
 		}
+		if lines := e.readmes[repo]; len(lines) > 0 {
+			fmt.Fprintf(stdout, "   %s\n", i18n.T("field.readme"))
+			for _, line := range lines {
+				fmt.Fprintf(stdout, "     │ %s\n", line)
+			}
+		}
+	}
 
-		if showStatus {
-			localPath := paths.ResolveRepositoryPath(cfg, repo)
-			status, err := git.GetRepositoryStatus(localPath)
-			if err != nil {
-				fmt.Printf("   Status: ❌ Error checking status: %v\n", err)
-			} else {
-				displayStatus(status)
+	if verbosity.IsEnabled(verbosity.DebugLevel) {
+		fmt.Fprintf(stdout, "   %s\n", i18n.T("field.clone_url", repo.CloneURL))
+		fmt.Fprintf(stdout, "   %s\n", i18n.T("field.default_branch", repo.DefaultBranch))
+		fmt.Fprintf(stdout, "   %s\n", i18n.T("field.provider", repo.Provider))
+	}
+
+	if e.showStatus {
+		localPath := paths.ResolveRepositoryPath(e.cfg, repo)
+		status, err := git.GetRepositoryStatus(localPath)
+		if err != nil {
+			fmt.Fprintf(stdout, "   %s %s %s\n", i18n.T("status.label"), theme.Get().Error, i18n.T("status.error_checking", redact.Error(err)))
+		} else {
+			displayStatus(status)
+			if !status.Exists {
+				e.facts["not_cloned"]++
+			} else if status.HasChanges {
+				e.facts["dirty"]++
 			}
 		}
+	}
+
+	if e.pipelines != nil {
+		fmt.Fprintf(stdout, "   %s %s\n", i18n.T("ci.label"), e.pipelines.describe(repo))
+		if pipeline := e.pipelines[repo].Pipeline; pipeline != nil && pipeline.WebURL != "" && verbosity.IsEnabled(verbosity.InfoLevel) {
+			fmt.Fprintf(stdout, "   %s\n", i18n.T("ci.web_url", pipeline.WebURL))
+		}
+	}
 
-		fmt.Print("\n")
+	fmt.Fprint(stdout, "\n")
+}
+
+// writeRepositoryListJSON writes the repositories as a JSON array, in the
+// shape the api command's list method returns them
+func writeRepositoryListJSON(ctx context.Context, clients []scm.Client, cfg *config.Config, groupFilter string, filter repoFilter) error {
+	repos := make([]apiRepository, 0)
+	fetched, errs := fetchProviderRepositories(ctx, clients, groupFilter)
+	for i, client := range clients {
+		if errs[i] != nil {
+			return &providerError{provider: client.GetProviderType(), err: errs[i]}
+		}
+		matched := filter.applyFor(client, fetched[i])
+		stale := reportStaleListing(stderr, client)
+		detectLanguages(ctx, client, matched)
+		for _, repo := range matched {
+			result := newAPIRepository(cfg, repo)
+			result.Stale = stale
+			repos = append(repos, result)
+		}
 	}
 
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(repos); err != nil {
+		return fmt.Errorf("failed to encode repositories: %w", err)
+	}
 	return nil
 }
 
-func displayRepositoryTree(clients []scm.Client, cfg *config.Config, showStatus bool, groupFilter string) error {
-	fmt.Println("Repository tree structure:")
+func displayRepositoryTree(ctx context.Context, clients []scm.Client, cfg *config.Config, showStatus, showCI bool, groupFilter string, filter repoFilter) error {
+	fmt.Fprintln(stdout, i18n.T("list.tree_header"))
 
 	for _, client := range clients {
-		fmt.Printf("\n=== %s Provider ===\n", strings.ToUpper(client.GetProviderType()))
+		fmt.Fprintf(stdout, "\n%s\n", i18n.T("list.provider_header", strings.ToUpper(client.GetProviderType())))
 
-		tree, err := client.BuildRepositoryTree()
+		tree, err := client.BuildRepositoryTree(ctx)
 		if err != nil {
-			fmt.Printf("Error building tree for %s: %v\n", client.GetProviderType(), err)
+			fmt.Fprintln(stdout, i18n.T("list.tree_error", client.GetProviderType(), providerErrorText(err)))
 			continue
 		}
+		filter.applyTree(client, tree)
+
+		var pipelines pipelineStatuses
+		if showCI {
+			pipelines = make(pipelineStatuses)
+			pipelines.fetch(ctx, client, treeRepositories(tree, groupFilter))
+		}
+		if verbosity.IsEnabled(verbosity.InfoLevel) {
+			detectLanguages(ctx, client, treeRepositories(tree, groupFilter))
+		}
 
 		if groupFilter != "" {
-			fmt.Printf("(filtered by group: %s)\n", groupFilter)
-			displayFilteredTree(tree, groupFilter, cfg, showStatus, client.GetProviderType())
+			fmt.Fprintln(stdout, i18n.T("list.filtered_by", groupFilter))
+			displayFilteredTree(tree, groupFilter, cfg, showStatus, pipelines, client.GetProviderType())
 		} else {
 			if len(tree.Repositories) > 0 {
-				fmt.Println("Root repositories:")
+				fmt.Fprintln(stdout, i18n.T("list.root_repos"))
 				for _, repo := range tree.Repositories {
 					repoLine := fmt.Sprintf("📁 %s", repo.Name)
 
@@ -163,23 +420,29 @@ func displayRepositoryTree(clients []scm.Client, cfg *config.Config, showStatus
 						localPath := paths.ResolveRepositoryPath(cfg, repo)
 						status, err := git.GetRepositoryStatus(localPath)
 						if err != nil {
-							repoLine += fmt.Sprintf(" - ❌ Error: %v", err)
+							repoLine += " - " + theme.Get().Error + " " + i18n.T("status.error", redact.Error(err))
 						} else {
 							repoLine += " - " + getCompactStatus(status, repo.DefaultBranch)
 						}
 					}
+					if pipelines != nil {
+						repoLine += " - CI " + pipelines.icon(repo)
+					}
 
-					fmt.Println(repoLine)
+					fmt.Fprintln(stdout, repoLine)
 
 					if verbosity.IsEnabled(verbosity.InfoLevel) {
-						fmt.Printf("   Web URL: %s\n", repo.WebURL)
-						fmt.Printf("   SSH URL: %s\n", repo.SSHCloneURL)
+						fmt.Fprintf(stdout, "   %s\n", i18n.T("field.web_url", repo.WebURL))
+						fmt.Fprintf(stdout, "   %s\n", i18n.T("field.ssh_url", repo.SSHCloneURL))
+						if metadata := formatRepoMetadata(repo, time.Now()); metadata != "" {
+							fmt.Fprintf(stdout, "   📊 %s\n", metadata)
+						}
 					}
 				}
 			}
 
 			for groupName, groupNode := range tree.Groups {
-				displayGroup(groupNode, 0, cfg, showStatus)
+				displayGroup(groupNode, 0, cfg, showStatus, pipelines)
 				_ = groupName
 			}
 		}
@@ -188,12 +451,12 @@ func displayRepositoryTree(clients []scm.Client, cfg *config.Config, showStatus
 	return nil
 }
 
-func displayFilteredTree(tree *scm.RepositoryTree, groupFilter string, cfg *config.Config, showStatus bool, providerType string) {
+func displayFilteredTree(tree *scm.RepositoryTree, groupFilter string, cfg *config.Config, showStatus bool, pipelines pipelineStatuses, providerType string) {
 	targetGroup := findGroupInTree(tree, groupFilter)
 	if targetGroup != nil {
-		displayGroup(targetGroup, 0, cfg, showStatus)
+		displayGroup(targetGroup, 0, cfg, showStatus, pipelines)
 	} else {
-		fmt.Printf("Group '%s' not found in %s\n", groupFilter, providerType)
+		fmt.Fprintln(stdout, i18n.T("list.group_not_found", groupFilter, providerType))
 	}
 }
 
@@ -215,9 +478,9 @@ func findGroupInTree(tree *scm.RepositoryTree, groupPath string) *scm.GroupNode
 	return currentNode
 }
 
-func displayGroup(group *scm.GroupNode, indent int, cfg *config.Config, showStatus bool) {
+func displayGroup(group *scm.GroupNode, indent int, cfg *config.Config, showStatus bool, pipelines pipelineStatuses) {
 	prefix := strings.Repeat("  ", indent)
-	fmt.Printf("%s📂 %s/\n", prefix, group.Group.Name)
+	fmt.Fprintf(stdout, "%s📂 %s/\n", prefix, group.Group.Name)
 
 	for _, repo := range group.Repositories {
 		repoLine := fmt.Sprintf("%s  📁 %s", prefix, repo.Name)
@@ -226,37 +489,44 @@ func displayGroup(group *scm.GroupNode, indent int, cfg *config.Config, showStat
 			localPath := paths.ResolveRepositoryPath(cfg, repo)
 			status, err := git.GetRepositoryStatus(localPath)
 			if err != nil {
-				repoLine += fmt.Sprintf(" - ❌ Error: %v", err)
+				repoLine += " - " + theme.Get().Error + " " + i18n.T("status.error", redact.Error(err))
 			} else {
 				repoLine += " - " + getCompactStatus(status, repo.DefaultBranch)
 			}
 		}
+		if pipelines != nil {
+			repoLine += " - CI " + pipelines.icon(repo)
+		}
 
-		fmt.Println(repoLine)
+		fmt.Fprintln(stdout, repoLine)
 
 		if verbosity.IsEnabled(verbosity.InfoLevel) {
-			fmt.Printf("%s     Web URL: %s\n", prefix, repo.WebURL)
-			fmt.Printf("%s     SSH URL: %s\n", prefix, repo.SSHCloneURL)
+			fmt.Fprintf(stdout, "%s     %s\n", prefix, i18n.T("field.web_url", repo.WebURL))
+			fmt.Fprintf(stdout, "%s     %s\n", prefix, i18n.T("field.ssh_url", repo.SSHCloneURL))
+			if metadata := formatRepoMetadata(repo, time.Now()); metadata != "" {
+				fmt.Fprintf(stdout, "%s     📊 %s\n", prefix, metadata)
+			}
 		}
 	}
 
 	for _, subGroup := range group.SubGroups {
-		displayGroup(subGroup, indent+1, cfg, showStatus)
+		displayGroup(subGroup, indent+1, cfg, showStatus, pipelines)
 	}
 }
 
 func getCompactStatus(status *git.Status, defaultBranch string) string {
+	symbols := theme.Get()
 	if !status.Exists {
-		return "❌ Not cloned"
+		return symbols.Missing + " " + i18n.T("status.not_cloned")
 	}
 
 	if !status.IsGitRepo {
-		return "⚠️ Not a git repo"
+		return symbols.Warning + " " + i18n.T("status.not_git_repo_short")
 	}
 
-	result := "✅"
+	result := symbols.Cloned
 	if status.HasChanges {
-		result += " 🔄"
+		result += " " + symbols.Dirty
 	}
 	if status.CurrentBranch != "" {
 		// Only show branch name if it's not the default branch and not main/master
@@ -277,22 +547,23 @@ func isDefaultBranch(currentBranch, defaultBranch string) bool {
 }
 
 func displayStatus(status *git.Status) {
+	symbols := theme.Get()
 	if !status.Exists {
-		fmt.Print("Status: ❌ Not cloned\n")
+		fmt.Fprintf(stdout, "%s %s %s\n", i18n.T("status.label"), symbols.Missing, i18n.T("status.not_cloned"))
 		return
 	}
 
 	if !status.IsGitRepo {
-		fmt.Print("Status: ⚠️  Directory exists but not a git repository\n")
+		fmt.Fprintf(stdout, "%s %s  %s\n", i18n.T("status.label"), symbols.Warning, i18n.T("status.not_git_repo"))
 		return
 	}
 
-	fmt.Printf("Status: ✅ Cloned")
+	fmt.Fprintf(stdout, "%s %s %s", i18n.T("status.label"), symbols.Cloned, i18n.T("status.cloned"))
 	if status.CurrentBranch != "" {
-		fmt.Printf(" (branch: %s)", status.CurrentBranch)
+		fmt.Fprintf(stdout, " %s", i18n.T("status.branch", status.CurrentBranch))
 	}
 	if status.HasChanges {
-		fmt.Print(" 🔄 Has uncommitted changes")
+		fmt.Fprintf(stdout, " %s %s", symbols.Dirty, i18n.T("status.has_changes"))
 	}
-	fmt.Print("\n")
+	fmt.Fprint(stdout, "\n")
 }
@@ -2,29 +2,30 @@ package cmd
 
 import (
 	"bytes"
-	"io"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"gitstuff/internal/cache"
 	"gitstuff/internal/config"
 	"gitstuff/internal/git"
+	"gitstuff/internal/output"
 	"gitstuff/internal/scm"
 	"gitstuff/internal/verbosity"
 )
 
 func captureOutput(f func()) string {
-	old := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
+	var buf bytes.Buffer
+	old := stdout
+	stdout = output.New(&buf)
+	defer func() { stdout = old }()
 
 	f()
-
-	w.Close()
-	os.Stdout = old
-
-	var buf bytes.Buffer
-	_, _ = io.Copy(&buf, r)
 	return buf.String()
 }
 
@@ -36,18 +37,18 @@ type mockSCMClient struct {
 	tree         *scm.RepositoryTree
 }
 
-func (m *mockSCMClient) ListAllRepositories() ([]*scm.Repository, error) {
+func (m *mockSCMClient) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
 	return m.repos, nil
 }
 
-func (m *mockSCMClient) ListRepositoriesInGroup(groupPath string) ([]*scm.Repository, error) {
+func (m *mockSCMClient) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*scm.Repository, error) {
 	if repos, exists := m.groupRepos[groupPath]; exists {
 		return repos, nil
 	}
 	return []*scm.Repository{}, nil
 }
 
-func (m *mockSCMClient) BuildRepositoryTree() (*scm.RepositoryTree, error) {
+func (m *mockSCMClient) BuildRepositoryTree(ctx context.Context) (*scm.RepositoryTree, error) {
 	return m.tree, nil
 }
 
@@ -89,7 +90,7 @@ func TestDisplayRepositoryList_WithoutVerbose(t *testing.T) {
 	clients := []scm.Client{mockClient}
 
 	output := captureOutput(func() {
-		_ = displayRepositoryList(clients, cfg, false, "")
+		_ = displayRepositoryList(context.Background(), clients, cfg, false, false, "", "", repoFilter{})
 	})
 
 	// Check output contains repository names
@@ -134,7 +135,7 @@ func TestDisplayRepositoryList_WithVerbose(t *testing.T) {
 	output := captureOutput(func() {
 		// Set verbosity to Info level to show URLs
 		verbosity.SetLevel(verbosity.InfoLevel)
-		_ = displayRepositoryList(clients, cfg, false, "")
+		_ = displayRepositoryList(context.Background(), clients, cfg, false, false, "", "", repoFilter{})
 		// Reset verbosity to Normal after test
 		verbosity.SetLevel(verbosity.Normal)
 	})
@@ -148,6 +149,46 @@ func TestDisplayRepositoryList_WithVerbose(t *testing.T) {
 	}
 }
 
+// mockReadmeClient is a mockSCMClient that serves READMEs
+type mockReadmeClient struct {
+	mockSCMClient
+	readmes map[string]string
+}
+
+func (m *mockReadmeClient) FetchReadme(ctx context.Context, repo *scm.Repository) (string, error) {
+	return m.readmes[repo.FullPath], nil
+}
+
+func TestDisplayRepositoryList_ReadmePreview(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: "/tmp/test"}}
+	client := &mockReadmeClient{
+		mockSCMClient: mockSCMClient{providerType: "github", repos: []*scm.Repository{
+			{Name: "tool", FullPath: "octo/tool", Provider: "github"},
+			{Name: "bare", FullPath: "octo/bare", Provider: "github"},
+		}},
+		readmes: map[string]string{"octo/tool": "[![CI](badge.svg)](ci)\n# Tool\n\nDoes things."},
+	}
+
+	normal := captureOutput(func() {
+		_ = displayRepositoryList(context.Background(), []scm.Client{client}, cfg, false, false, "", "", repoFilter{})
+	})
+	if strings.Contains(normal, "README") {
+		t.Errorf("Expected no README preview without verbose output, got: %s", normal)
+	}
+
+	verbose := captureOutput(func() {
+		verbosity.SetLevel(verbosity.InfoLevel)
+		defer verbosity.SetLevel(verbosity.Normal)
+		_ = displayRepositoryList(context.Background(), []scm.Client{client}, cfg, false, false, "", "", repoFilter{})
+	})
+	if !strings.Contains(verbose, "README:\n     │ Tool\n     │ \n     │ Does things.") {
+		t.Errorf("Expected the README preview of octo/tool, got: %s", verbose)
+	}
+	if strings.Count(verbose, "README:") != 1 {
+		t.Errorf("Expected no preview for a repository without a README, got: %s", verbose)
+	}
+}
+
 func TestDisplayRepositoryTree_MultipleProviders(t *testing.T) {
 	// Mock config
 	cfg := &config.Config{
@@ -221,7 +262,7 @@ func TestDisplayRepositoryTree_MultipleProviders(t *testing.T) {
 	clients := []scm.Client{gitlabClient, githubClient}
 
 	output := captureOutput(func() {
-		_ = displayRepositoryTree(clients, cfg, false, "")
+		_ = displayRepositoryTree(context.Background(), clients, cfg, false, false, "", repoFilter{})
 	})
 
 	// Check output contains both providers
@@ -285,7 +326,7 @@ func TestDisplayRepositoryTree_WithVerbose(t *testing.T) {
 	output := captureOutput(func() {
 		// Set verbosity to Info level to show URLs
 		verbosity.SetLevel(verbosity.InfoLevel)
-		_ = displayRepositoryTree(clients, cfg, false, "")
+		_ = displayRepositoryTree(context.Background(), clients, cfg, false, false, "", repoFilter{})
 		// Reset verbosity to Normal after test
 		verbosity.SetLevel(verbosity.Normal)
 	})
@@ -472,3 +513,205 @@ func TestCreateClient_UnsupportedProvider(t *testing.T) {
 		t.Errorf("Expected error to contain '%s', got: %s", expectedErr, err.Error())
 	}
 }
+
+func TestClientFactory_HTTPCache(t *testing.T) {
+	var requests, conditional int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"repos-v1"`)
+		if r.Header.Get("If-None-Match") == `"repos-v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "repo", "full_name": "org/repo", "permissions": {"pull": true}}]`))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	cfg := &config.Config{Cache: config.CacheConfig{Dir: cacheDir}}
+	providerConfig := config.ProviderConfig{Name: "gh", Type: "github", URL: server.URL + "/api/v3", Token: "test-token"}
+
+	client, err := clientFactory(cfg)(providerConfig)
+	if err != nil {
+		t.Fatalf("clientFactory failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		repos, err := client.ListAllRepositories(context.Background())
+		if err != nil {
+			t.Fatalf("ListAllRepositories failed: %v", err)
+		}
+		if len(repos) != 1 || repos[0].FullPath != "org/repo" {
+			t.Fatalf("Unexpected repositories on call %d: %+v", i+1, repos)
+		}
+	}
+
+	if requests != 2 || conditional != 1 {
+		t.Errorf("Expected second request to be a conditional revalidation, got %d requests and %d conditional", requests, conditional)
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "http", "*", "*.json"))
+	if len(entries) == 0 {
+		t.Error("Expected cache entries to be written under the cache directory")
+	}
+}
+
+func TestClientFactory_CacheDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"repos-v1"`)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	providerConfig := config.ProviderConfig{Name: "gh", Type: "github", URL: server.URL + "/api/v3", Token: "test-token"}
+
+	tests := []struct {
+		name    string
+		cache   config.CacheConfig
+		noCache bool
+	}{
+		{"disabled in config", config.CacheConfig{DisableHTTP: true}, false},
+		{"disabled by flag", config.CacheConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			tt.cache.Dir = cacheDir
+			noCache = tt.noCache
+			t.Cleanup(func() { noCache = false })
+
+			client, err := clientFactory(&config.Config{Cache: tt.cache})(providerConfig)
+			if err != nil {
+				t.Fatalf("clientFactory failed: %v", err)
+			}
+			if _, err := client.ListAllRepositories(context.Background()); err != nil {
+				t.Fatalf("ListAllRepositories failed: %v", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(cacheDir, "http")); !os.IsNotExist(err) {
+				t.Error("Expected no cache directory to be created")
+			}
+		})
+	}
+}
+
+func TestClientFactory_UserAgent(t *testing.T) {
+	agents := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents <- r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Cache: config.CacheConfig{DisableHTTP: true},
+		HTTP:  config.HTTPConfig{UserAgentSuffix: "acme-audit/7"},
+	}
+
+	for _, providerType := range []string{"github", "gitlab"} {
+		t.Run(providerType, func(t *testing.T) {
+			url := server.URL
+			if providerType == "github" {
+				url += "/api/v3"
+			}
+			providerConfig := config.ProviderConfig{Name: providerType, Type: providerType, URL: url, Token: "test-token"}
+
+			client, err := clientFactory(cfg)(providerConfig)
+			if err != nil {
+				t.Fatalf("clientFactory failed: %v", err)
+			}
+			if _, err := client.ListAllRepositories(context.Background()); err != nil {
+				t.Fatalf("ListAllRepositories failed: %v", err)
+			}
+
+			want := "gitstuff/" + version + " acme-audit/7"
+			if got := <-agents; got != want {
+				t.Errorf("Expected User-Agent %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	if got := userAgent(""); got != "gitstuff/"+version {
+		t.Errorf("userAgent(\"\") = %q", got)
+	}
+	if got := userAgent("  corp-proxy  "); got != "gitstuff/"+version+" corp-proxy" {
+		t.Errorf("userAgent with suffix = %q", got)
+	}
+}
+
+func TestDisplayRepositoryList_Topics(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: t.TempDir()}}
+	client := &mockSCMClient{providerType: "github", repos: []*scm.Repository{
+		{FullPath: "octo/infra", Provider: "github", Topics: []string{"terraform", "aws"}},
+		{FullPath: "octo/tool", Provider: "github"},
+	}}
+
+	output := captureOutput(func() {
+		_ = displayRepositoryList(context.Background(), []scm.Client{client}, cfg, false, false, "", "", repoFilter{})
+	})
+	if !strings.Contains(output, "📁 [github] octo/infra  🏷️  terraform, aws\n") || !strings.Contains(output, "📁 [github] octo/tool\n") {
+		t.Errorf("Expected topics after the repositories that have them, got:\n%s", output)
+	}
+}
+
+// unavailableClient is a provider that cannot be reached
+type unavailableClient struct {
+	mockSCMClient
+}
+
+func (c *unavailableClient) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
+	return nil, errors.New("dial tcp: connection refused")
+}
+
+func TestCommand_ListStaleProvider(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "work", "public")
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, Dir: t.TempDir()}
+	store := cache.NewStore(filepath.Join(cfg.Cache.Dir, "metadata"), 0)
+	public := cfg.Providers[1]
+	if err := store.Save(cache.Key(public.Name, public.URL, public.Token), []*scm.Repository{{FullPath: "octo/tool", Provider: "github"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	clients := map[string]scm.Client{
+		"work":   &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{{FullPath: "team/api", Provider: "gitlab"}}},
+		"public": &unavailableClient{mockSCMClient{providerType: "github"}},
+	}
+	out, err := runCommand(t, cfg, clients, "list", "--status=false", "--refresh")
+	if err != nil {
+		t.Fatalf("Expected the cached listing to be used, got %v", err)
+	}
+	for _, want := range []string{"github provider unavailable (dial tcp: connection refused)", "📁 [github] octo/tool  (cached)", "📁 [gitlab] team/api\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestCommand_ListGroupBy(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "work")
+	client := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+		{FullPath: "team/api", Provider: "gitlab", Topics: []string{"backend"}},
+		{FullPath: "team/worker", Provider: "gitlab", Topics: []string{"backend"}},
+		{FullPath: "team/web", Provider: "gitlab"},
+	}}
+
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "list", "--status=false", "--group-by", "topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "📂 backend (2)\n\n📁 [gitlab] team/api  🏷️  backend\n\n📁 [gitlab] team/worker  🏷️  backend\n\n📂 (no topics) (1)\n\n📁 [gitlab] team/web\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("Expected sections with counts, got:\n%s", out)
+	}
+
+	if _, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "list", "--group-by", "size"); err == nil || !strings.Contains(err.Error(), "invalid --group-by") {
+		t.Errorf("Expected an invalid --group-by error, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Manage git background maintenance across local repositories",
+	Long: `Turn git's background maintenance on or off for every local clone. Registered
+repositories get their commit-graph, prefetched remote refs and packs kept up
+to date by the system scheduler (cron, systemd timers, launchd or the Windows
+task scheduler), which makes status and log much faster on large repositories.
+
+Set git.maintenance in the config file to enable it for every new clone.`,
+}
+
+var maintenanceEnableCmd = &cobra.Command{
+	Use:   "enable [path]",
+	Short: "Enable background maintenance for all local repositories",
+	Long: `Register every git repository in the base directory (or the given path) for
+git's background maintenance with the incremental strategy, and schedule it.
+
+Examples:
+  gitstuff maintenance enable                # Every clone in the base directory
+  gitstuff maintenance enable ~/src/work     # Clones below a directory`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMaintenance(cmd, args, true)
+	},
+}
+
+var maintenanceDisableCmd = &cobra.Command{
+	Use:   "disable [path]",
+	Short: "Disable background maintenance for all local repositories",
+	Long: `Unregister every git repository in the base directory (or the given path)
+from git's background maintenance. Once no repository is registered any more,
+the schedule is removed as well.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMaintenance(cmd, args, false)
+	},
+}
+
+var maintenanceStatusCmd = &cobra.Command{
+	Use:   "status [path]",
+	Short: "Show which local repositories have background maintenance",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runMaintenanceStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	maintenanceCmd.AddCommand(maintenanceEnableCmd)
+	maintenanceCmd.AddCommand(maintenanceDisableCmd)
+	maintenanceCmd.AddCommand(maintenanceStatusCmd)
+}
+
+// startMaintenance and stopMaintenance change the system scheduler, so tests
+// replace them
+var (
+	startMaintenance = git.StartMaintenance
+	stopMaintenance  = git.StopMaintenance
+)
+
+// localRepositories returns the git repositories below the given path, or
+// below the configured base directory
+func localRepositories(args []string) (string, []string, error) {
+	var root string
+	if len(args) == 1 {
+		root = args[0]
+	} else {
+		cfg, err := loadConfig()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load config: %w (run 'gitstuff config' first or pass a path)", err)
+		}
+		root = cfg.Local.BaseDir
+	}
+	repoPaths, err := git.FindRepositories(root)
+	if err != nil {
+		return "", nil, err
+	}
+	return root, repoPaths, nil
+}
+
+func runMaintenance(cmd *cobra.Command, args []string, enable bool) error {
+	start := time.Now()
+	if err := checkWritable("change git maintenance"); err != nil {
+		return err
+	}
+	root, repoPaths, err := localRepositories(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("status.found_local", len(repoPaths), root))
+
+	var failed int
+	if enable {
+		failed = enableMaintenance(stdout, root, repoPaths)
+	} else {
+		failed = disableMaintenance(stdout, root, repoPaths)
+	}
+	verbosity.DebugTiming(start, "Maintenance updated for %d repositories", len(repoPaths))
+
+	fmt.Fprintf(stdout, "\n%s\n", i18n.T("maintenance.summary", len(repoPaths)-failed, failed))
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to change maintenance of %d repositories", failed)
+	}
+	return nil
+}
+
+// enableMaintenance registers each repository for background maintenance
+// and schedules it once any is registered. Registration edits the global git
+// config, so it runs one repository at a time. It returns how many
+// repositories failed.
+func enableMaintenance(w io.Writer, root string, repoPaths []string) int {
+	failed := 0
+	var registered []string
+	for _, repoPath := range repoPaths {
+		if err := git.RegisterMaintenance(repoPath); err != nil {
+			fmt.Fprintf(w, "❌ %s: %v\n", relativeTo(root, repoPath), redact.Error(err))
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "🧰 %s\n", relativeTo(root, repoPath))
+		registered = append(registered, repoPath)
+	}
+	if len(registered) > 0 {
+		if err := startMaintenance(registered[0]); err != nil {
+			fmt.Fprintf(w, "⚠️  %s\n", i18n.T("maintenance.schedule_failed", redact.Error(err)))
+		}
+	}
+	return failed
+}
+
+// disableMaintenance unregisters each repository and removes the schedule
+// once no repository is left registered. It returns how many repositories
+// failed.
+func disableMaintenance(w io.Writer, root string, repoPaths []string) int {
+	failed := 0
+	for _, repoPath := range repoPaths {
+		if err := git.UnregisterMaintenance(repoPath); err != nil {
+			fmt.Fprintf(w, "❌ %s: %v\n", relativeTo(root, repoPath), redact.Error(err))
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "⏹️  %s\n", relativeTo(root, repoPath))
+	}
+	if remaining, err := git.MaintenanceRepositories(); err == nil && len(remaining) == 0 && len(repoPaths) > 0 {
+		if err := stopMaintenance(repoPaths[0]); err != nil {
+			fmt.Fprintf(w, "⚠️  %s\n", i18n.T("maintenance.unschedule_failed", redact.Error(err)))
+		}
+	}
+	return failed
+}
+
+// maintainNewClones enables background maintenance for the clones clone and
+// sync just made when git.maintenance is set. Failures only warn, since the
+// clones themselves succeeded.
+func maintainNewClones(w io.Writer, repoPaths []string) {
+	if len(repoPaths) == 0 {
+		return
+	}
+	if failed := enableMaintenance(io.Discard, "", repoPaths); failed > 0 {
+		fmt.Fprintf(w, "⚠️  %s\n", i18n.T("maintenance.new_clones_failed", failed))
+		return
+	}
+	verbosity.Info("Enabled background maintenance for %d new clones", len(repoPaths))
+}
+
+func runMaintenanceStatus(cmd *cobra.Command, args []string) error {
+	root, repoPaths, err := localRepositories(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("status.found_local", len(repoPaths), root))
+
+	enabled := 0
+	for _, repoPath := range repoPaths {
+		registered, err := git.MaintenanceRegistered(repoPath)
+		if err != nil {
+			return err
+		}
+		state := i18n.T("maintenance.off")
+		if registered {
+			state = i18n.T("maintenance.on")
+			enabled++
+		}
+		fmt.Fprintf(stdout, "📁 %s - %s\n", relativeTo(root, repoPath), state)
+	}
+	fmt.Fprintf(stdout, "\n%s\n", i18n.T("maintenance.status_summary", enabled, len(repoPaths)))
+	return nil
+}
+
+// relativeTo returns path relative to root when it is below it
+func relativeTo(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+// stubMaintenanceSchedule keeps tests away from the real system scheduler
+// and global git config, and counts the schedule changes
+func stubMaintenanceSchedule(t *testing.T) (started, stopped *int) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	started, stopped = new(int), new(int)
+	oldStart, oldStop := startMaintenance, stopMaintenance
+	startMaintenance = func(string) error { *started++; return nil }
+	stopMaintenance = func(string) error { *stopped++; return nil }
+	t.Cleanup(func() { startMaintenance, stopMaintenance = oldStart, oldStop })
+	return started, stopped
+}
+
+func TestCommand_Maintenance(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	cfg, _ := setupSyncFixture(t)
+	started, stopped := stubMaintenanceSchedule(t)
+	clean := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+
+	out, err := runCommand(t, cfg, nil, "maintenance", "enable")
+	if err != nil {
+		t.Fatalf("maintenance enable failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Maintenance changed for 2 repositories, 0 failed") {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+	if *started != 1 {
+		t.Errorf("Expected maintenance to be scheduled once, got %d", *started)
+	}
+	if registered, err := git.MaintenanceRegistered(clean); err != nil || !registered {
+		t.Errorf("Expected %s to be registered, got %v (%v)", clean, registered, err)
+	}
+
+	out, err = runCommand(t, cfg, nil, "maintenance", "status")
+	if err != nil {
+		t.Fatalf("maintenance status failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{filepath.Join("gitlab", "group", "clean") + " - enabled", "2 of 2 repositories have background maintenance"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if out, err := runCommand(t, cfg, nil, "maintenance", "disable"); err != nil {
+		t.Fatalf("maintenance disable failed: %v\n%s", err, out)
+	}
+	if repos, err := git.MaintenanceRepositories(); err != nil || len(repos) != 0 {
+		t.Errorf("Expected no registered repositories, got %v (%v)", repos, err)
+	}
+	if *stopped != 1 {
+		t.Errorf("Expected the schedule to be removed once, got %d", *stopped)
+	}
+}
+
+func TestCommand_SyncEnablesMaintenance(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	cfg.Git.Maintenance = true
+	started, _ := stubMaintenanceSchedule(t)
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}}
+
+	if out, err := runCommand(t, cfg, clients, "sync", "--https"); err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, out)
+	}
+	registered, err := git.MaintenanceRepositories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registered) != 1 || !strings.HasSuffix(filepath.ToSlash(registered[0]), "group/missing") {
+		t.Errorf("Expected only the new clone to be registered, got %v", registered)
+	}
+	if *started != 1 {
+		t.Errorf("Expected maintenance to be scheduled once, got %d", *started)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/manifest"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/scm"
+)
+
+// defaultManifestRepo is the repository of a group that holds its manifest
+const defaultManifestRepo = "gitstuff-manifest"
+
+// applyManifest brings the manifest repository of group up to date and
+// returns the repositories of group its manifest requires, along with the
+// setup to run in them once cloned
+func applyManifest(ctx context.Context, out io.Writer, cfg *config.Config, repos []*scm.Repository, group, repoName string, opts cloneOptions) ([]*scm.Repository, []setupRule, error) {
+	group = strings.Trim(group, "/")
+	manifestPath := group + "/" + repoName
+
+	var manifestRepo *scm.Repository
+	for _, repo := range repos {
+		if repo.FullPath == manifestPath {
+			manifestRepo = repo
+			break
+		}
+	}
+	if manifestRepo == nil {
+		return nil, nil, fmt.Errorf("manifest repository %s not found (set another one with --manifest-repo)", manifestPath)
+	}
+
+	fmt.Fprintln(out, i18n.T("manifest.updating", manifestPath))
+	if _, err := processRepository(ctx, out, "📋", manifestRepo, cfg, opts); err != nil {
+		return nil, nil, fmt.Errorf("failed to update manifest repository %s: %w", manifestPath, err)
+	}
+	m, err := manifest.Load(filepath.Join(paths.ResolveRepositoryPath(cfg, manifestRepo), manifest.FileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matched := make(map[*scm.Repository]bool)
+	var setup []setupRule
+	for _, entry := range m.Repos {
+		include, err := compilePatternSet([]string{group + "/" + entry.Path}, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("manifest of %s: %w", group, err)
+		}
+		found := false
+		for _, repo := range repos {
+			if repo != manifestRepo && include.matches(repo.FullPath) {
+				matched[repo] = true
+				found = true
+			}
+		}
+		if !found {
+			fmt.Fprintf(out, "⚠️  %s\n", i18n.T("manifest.unmatched", entry.Path, group))
+		}
+		if len(entry.Setup) > 0 {
+			setup = append(setup, setupRule{include: include, commands: entry.Setup})
+		}
+	}
+
+	// Keep the order of the provider listing
+	var selected []*scm.Repository
+	for _, repo := range repos {
+		if matched[repo] {
+			selected = append(selected, repo)
+		}
+	}
+	fmt.Fprintf(out, "%s\n\n", i18n.T("manifest.selected", group, len(selected)))
+	return selected, setup, nil
+}
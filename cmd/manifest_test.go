@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/manifest"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_SyncManifest(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+
+	var repos []*scm.Repository
+	for _, fullPath := range []string{"team/gitstuff-manifest", "team/api", "team/libs/one", "team/web"} {
+		name := filepath.Base(fullPath)
+		repos = append(repos, &scm.Repository{
+			Name:     name,
+			FullPath: fullPath,
+			CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", strings.ReplaceAll(fullPath, "/", "-")+".git")),
+			Provider: "gitlab",
+		})
+	}
+
+	manifestRemote := repos[0].CloneURL
+	work := manifestRemote + ".work"
+	content := "repos:\n  - path: api\n    setup:\n      - touch bootstrapped\n  - path: libs/*\n  - path: missing\n"
+	if err := os.WriteFile(filepath.Join(work, manifest.FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"-C", work, "add", manifest.FileName},
+		{"-C", work, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "-m", "Add manifest"},
+		{"-C", work, "push", "-q", manifestRemote, "HEAD"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	client := &mockSCMClient{providerType: "gitlab", groupRepos: map[string][]*scm.Repository{"team": repos}}
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--manifest", "team", "--https")
+	if err != nil {
+		t.Fatalf("sync --manifest failed: %v\n%s", err, out)
+	}
+
+	for _, want := range []string{
+		"The manifest lists missing, which matches no repository in team",
+		"The manifest of team selects 2 repositories",
+		"Running setup: touch bootstrapped",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	base := filepath.Join(cfg.Local.BaseDir, "gitlab")
+	for _, path := range []string{"team/gitstuff-manifest", "team/api/bootstrapped", "team/libs/one"} {
+		if _, err := os.Stat(filepath.Join(base, path)); err != nil {
+			t.Errorf("Expected %s to exist: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "team/web")); !os.IsNotExist(err) {
+		t.Errorf("Expected team/web not to be cloned, got %v", err)
+	}
+
+	if _, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--manifest", "team", "--manifest-repo", "nope"); err == nil || !strings.Contains(err.Error(), "team/nope not found") {
+		t.Errorf("Expected a missing manifest repository error, got %v", err)
+	}
+}
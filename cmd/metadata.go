@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// languageJobs is how many primary languages are looked up at a time
+const languageJobs = 8
+
+// detectLanguages looks up the primary language of the repos that have none
+// when their provider lists them without it. Failures are only logged, so
+// those repositories are shown without a language.
+func detectLanguages(ctx context.Context, client scm.Client, repos []*scm.Repository) {
+	detector, ok := scm.Unwrap(client).(scm.LanguageDetector)
+	if !ok {
+		return
+	}
+	var tasks []runner.Task
+	for _, repo := range repos {
+		if repo.Language != "" {
+			continue
+		}
+		tasks = append(tasks, func(w io.Writer) error {
+			language, err := detector.PrimaryLanguage(ctx, repo)
+			if err != nil {
+				verbosity.WithRepo(repo.FullPath, repo.Provider).Debug("Could not detect language: %v", redact.Error(err))
+				return err
+			}
+			repo.Language = language
+			return nil
+		})
+	}
+	runner.New(languageJobs).RunContext(ctx, tasks, io.Discard)
+}
+
+// formatRepoMetadata joins the language, size, stars and last activity of
+// repo that its provider reported, or returns an empty string
+func formatRepoMetadata(repo *scm.Repository, now time.Time) string {
+	var parts []string
+	if repo.Language != "" {
+		parts = append(parts, repo.Language)
+	}
+	if repo.Size > 0 {
+		parts = append(parts, formatSize(repo.Size))
+	}
+	if repo.Stars > 0 {
+		parts = append(parts, fmt.Sprintf("⭐ %d", repo.Stars))
+	}
+	if !repo.LastActivity.IsZero() {
+		parts = append(parts, i18n.T("list.active", formatAge(now.Sub(repo.LastActivity))))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatSize formats a size in bytes for display
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(bytes)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// mockLanguageClient is a mockSCMClient whose listings leave out languages
+type mockLanguageClient struct {
+	mockSCMClient
+	languages map[string]string
+}
+
+func (m *mockLanguageClient) PrimaryLanguage(ctx context.Context, repo *scm.Repository) (string, error) {
+	return m.languages[repo.FullPath], nil
+}
+
+func TestFormatRepoMetadata(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	repo := &scm.Repository{Language: "Go", Size: 3 << 20, Stars: 42, LastActivity: now.Add(-72 * time.Hour)}
+	if got, want := formatRepoMetadata(repo, now), "Go · 3.0 MiB · ⭐ 42 · active 3d ago"; got != want {
+		t.Errorf("formatRepoMetadata() = %q, want %q", got, want)
+	}
+	if got := formatRepoMetadata(&scm.Repository{}, now); got != "" {
+		t.Errorf("Expected nothing without metadata, got %q", got)
+	}
+	for size, want := range map[int64]string{512: "512 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"} {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestDisplayRepositoryList_Metadata(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: t.TempDir()}}
+	client := &mockLanguageClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+			{FullPath: "team/api", Provider: "gitlab", Size: 2048, Stars: 1},
+		}},
+		languages: map[string]string{"team/api": "Rust"},
+	}
+
+	output := captureOutput(func() {
+		verbosity.SetLevel(verbosity.InfoLevel)
+		defer verbosity.SetLevel(verbosity.Normal)
+		_ = displayRepositoryList(context.Background(), []scm.Client{client}, cfg, false, false, "", "", repoFilter{})
+	})
+	if !strings.Contains(output, "   📊 Rust · 2.0 KiB · ⭐ 1\n") {
+		t.Errorf("Expected the metadata with the detected language, got:\n%s", output)
+	}
+}
+
+func TestCommand_ListJSON(t *testing.T) {
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(t.TempDir(), "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+	active := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clients := map[string]scm.Client{"work": &mockLanguageClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+			{FullPath: "team/api", Name: "api", Provider: "gitlab", Size: 5000, Stars: 3, LastActivity: active},
+			{FullPath: "team/docs", Name: "docs", Provider: "gitlab"},
+		}},
+		languages: map[string]string{"team/api": "Go"},
+	}}
+
+	out, err := runCommand(t, cfg, clients, "list", "--json")
+	if err != nil {
+		t.Fatalf("list --json failed: %v\n%s", err, out)
+	}
+	var repos []apiRepository
+	if err := json.Unmarshal([]byte(out), &repos); err != nil {
+		t.Fatalf("Expected a JSON array, got %v:\n%s", err, out)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(repos))
+	}
+	api := repos[0]
+	if api.Language != "Go" || api.Size != 5000 || api.Stars != 3 || api.LastActivity == nil || !api.LastActivity.Equal(active) {
+		t.Errorf("Unexpected metadata in %+v", api)
+	}
+	if repos[1].LastActivity != nil || strings.Count(out, "last_activity") != 1 {
+		t.Errorf("Expected last_activity to be left out when unknown, got:\n%s", out)
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateLayoutCmd = &cobra.Command{
+	Use:   "migrate-layout [group]",
+	Short: "Move repositories from the legacy layout to the provider-based layout",
+	Long: `Find repositories cloned by older versions of gitstuff into
+{base_dir}/{full_path} and move them to {base_dir}/{provider}/{full_path},
+where new clones are made.
+
+A repository is left where it is when its new location already exists, or
+when the legacy path cannot be attributed to a single repository (for
+example the same full path on two providers). Directories emptied by the
+move are removed.
+
+Examples:
+  gitstuff migrate-layout --dry-run   # Show what would be moved
+  gitstuff migrate-layout             # Move every legacy repository
+  gitstuff migrate-layout backend     # Only move repositories in a group`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMigrateLayout,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateLayoutCmd)
+	migrateLayoutCmd.Flags().BoolP("dry-run", "n", false, "Show planned moves without changing anything")
+}
+
+type migrateAction int
+
+const (
+	migrateMove migrateAction = iota
+	migrateCollision
+	migrateAmbiguous
+)
+
+type migrateEntry struct {
+	Repo   *scm.Repository
+	From   string
+	To     string
+	Action migrateAction
+	Err    error
+}
+
+func runMigrateLayout(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if !dryRun {
+		if err := checkWritable("move clones"); err != nil {
+			return err
+		}
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+	// Every repository is needed to detect ambiguous legacy paths, including
+	// archived ones
+	repos := collectRepositories(commandContext(cmd), clients, "", repoFilter{includeArchived: true})
+	plan := planMigration(cfg, repos, groupPath)
+	verbosity.DebugTiming(start, "Planned migration of %d repositories", len(plan))
+
+	if dryRun {
+		displayMigrationPlan(stdout, plan)
+		return nil
+	}
+
+	applyMigration(cfg, plan)
+	if failed := displayMigrationResult(stdout, plan); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to move %d repositories", failed)
+	}
+	return nil
+}
+
+// planMigration finds the repositories in groupPath (or all of them) that
+// are cloned at their legacy path
+func planMigration(cfg *config.Config, repos []*scm.Repository, groupPath string) []migrateEntry {
+	// A legacy path is ambiguous when several repositories map to it, or when
+	// it is also the provider-based path of a repository
+	legacyCount := make(map[string]int)
+	providerPaths := make(map[string]bool)
+	for _, repo := range repos {
+		legacyCount[paths.GetLegacyPath(cfg, repo)]++
+		providerPaths[paths.GetClonePath(cfg, repo)] = true
+	}
+
+	var plan []migrateEntry
+	for _, repo := range repos {
+		if groupPath != "" && !strings.HasPrefix(repo.FullPath, groupPath+"/") {
+			continue
+		}
+
+		from := paths.GetLegacyPath(cfg, repo)
+		if providerPaths[from] {
+			continue // Already in the provider-based layout
+		}
+		status, err := git.GetRepositoryStatus(from)
+		if err != nil || !status.Exists || !status.IsGitRepo {
+			continue
+		}
+
+		entry := migrateEntry{Repo: repo, From: from, To: paths.GetClonePath(cfg, repo)}
+		switch {
+		case legacyCount[from] > 1:
+			entry.Action = migrateAmbiguous
+		case pathExists(entry.To):
+			entry.Action = migrateCollision
+		}
+		plan = append(plan, entry)
+	}
+	return plan
+}
+
+func pathExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// applyMigration moves every repository planned to move, recording failures
+// in the plan
+func applyMigration(cfg *config.Config, plan []migrateEntry) {
+	for i := range plan {
+		entry := &plan[i]
+		if entry.Action != migrateMove {
+			continue
+		}
+		verbosity.Debug("Moving %s to %s", entry.From, entry.To)
+		if err := os.MkdirAll(filepath.Dir(entry.To), 0755); err != nil {
+			entry.Err = fmt.Errorf("failed to create directory: %w", err)
+			continue
+		}
+		if err := os.Rename(entry.From, entry.To); err != nil {
+			entry.Err = fmt.Errorf("failed to move repository: %w", err)
+			continue
+		}
+		removeEmptyParents(filepath.Dir(entry.From), cfg.Local.BaseDir)
+	}
+}
+
+// removeEmptyParents removes dir and its parents, up to but excluding root,
+// for as long as they are empty
+func removeEmptyParents(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return // Not empty
+		}
+	}
+}
+
+func displayMigrationPlan(w io.Writer, plan []migrateEntry) {
+	if len(plan) == 0 {
+		fmt.Fprintln(w, i18n.T("migrate.none"))
+		return
+	}
+	fmt.Fprintf(w, "%s\n\n", i18n.T("migrate.plan_header", len(plan)))
+
+	moves := 0
+	for _, entry := range plan {
+		if entry.Action == migrateMove {
+			moves++
+			fmt.Fprintf(w, "📦 %s\n   %s\n", i18n.T("migrate.would_move", entry.Repo.FullPath, entry.Repo.Provider), i18n.T("migrate.paths", entry.From, entry.To))
+			continue
+		}
+		displayMigrationSkip(w, entry)
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("migrate.plan_summary", moves, len(plan)-moves))
+}
+
+// displayMigrationResult prints what was moved and returns how many moves
+// failed
+func displayMigrationResult(w io.Writer, plan []migrateEntry) int {
+	if len(plan) == 0 {
+		fmt.Fprintln(w, i18n.T("migrate.none"))
+		return 0
+	}
+
+	moved, skipped, failed := 0, 0, 0
+	for _, entry := range plan {
+		switch {
+		case entry.Action != migrateMove:
+			skipped++
+			displayMigrationSkip(w, entry)
+		case entry.Err != nil:
+			failed++
+			fmt.Fprintf(w, "❌ %s [%s] - %s\n", entry.Repo.FullPath, entry.Repo.Provider, i18n.T("migrate.failed", redact.Error(entry.Err)))
+		default:
+			moved++
+			fmt.Fprintf(w, "✅ %s\n", i18n.T("migrate.moved", entry.Repo.FullPath, entry.Repo.Provider, entry.To))
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("migrate.summary", moved, skipped, failed))
+	return failed
+}
+
+func displayMigrationSkip(w io.Writer, entry migrateEntry) {
+	switch entry.Action {
+	case migrateCollision:
+		fmt.Fprintf(w, "⚠️  %s [%s] - %s\n", entry.Repo.FullPath, entry.Repo.Provider, i18n.T("migrate.collision", entry.To))
+	case migrateAmbiguous:
+		fmt.Fprintf(w, "⚠️  %s [%s] - %s\n", entry.Repo.FullPath, entry.Repo.Provider, i18n.T("migrate.ambiguous", entry.From))
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+func TestPlanAndApplyMigration(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+
+	repos := []*scm.Repository{
+		{FullPath: "team/api", Provider: "gitlab"},
+		{FullPath: "team/web", Provider: "gitlab"},
+		{FullPath: "shared/lib", Provider: "gitlab"},
+		{FullPath: "shared/lib", Provider: "github"},
+		{FullPath: "other/new", Provider: "github"},
+	}
+
+	for _, fullPath := range []string{"team/api", "team/web", "shared/lib"} {
+		legacy := filepath.Join(cfg.Local.BaseDir, filepath.FromSlash(fullPath))
+		createRemoteRepo(t, legacy+".src")
+		if err := git.CloneRepositoryWithOutput(legacy+".src", legacy, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("Failed to clone %s: %v", fullPath, err)
+		}
+	}
+	// team/web was already cloned into the new layout as well
+	if err := os.MkdirAll(filepath.Join(cfg.Local.BaseDir, "gitlab", "team", "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := planMigration(cfg, repos, "")
+	want := map[string]migrateAction{
+		"gitlab:team/api":   migrateMove,
+		"gitlab:team/web":   migrateCollision,
+		"gitlab:shared/lib": migrateAmbiguous,
+		"github:shared/lib": migrateAmbiguous,
+	}
+	if len(plan) != len(want) {
+		t.Fatalf("Expected %d plan entries, got %+v", len(want), plan)
+	}
+	for _, entry := range plan {
+		key := entry.Repo.Provider + ":" + entry.Repo.FullPath
+		if action, ok := want[key]; !ok || action != entry.Action {
+			t.Errorf("Unexpected action %d for %s", entry.Action, key)
+		}
+	}
+
+	if grouped := planMigration(cfg, repos, "team"); len(grouped) != 2 {
+		t.Errorf("Expected 2 entries in group team, got %d", len(grouped))
+	}
+
+	var buf bytes.Buffer
+	displayMigrationPlan(&buf, plan)
+	if !strings.Contains(buf.String(), "Would move 1, skip 3") {
+		t.Errorf("Unexpected plan output:\n%s", buf.String())
+	}
+
+	applyMigration(cfg, plan)
+	buf.Reset()
+	if failed := displayMigrationResult(&buf, plan); failed != 0 {
+		t.Fatalf("Expected no failures, got %d:\n%s", failed, buf.String())
+	}
+	if !strings.Contains(buf.String(), "1 moved, 3 skipped, 0 failed") {
+		t.Errorf("Unexpected result output:\n%s", buf.String())
+	}
+
+	status, err := git.GetRepositoryStatus(filepath.Join(cfg.Local.BaseDir, "gitlab", "team", "api"))
+	if err != nil || !status.IsGitRepo {
+		t.Errorf("Expected repository at the provider-based path, got %+v, %v", status, err)
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "team", "api")) {
+		t.Error("Expected the legacy path to be gone")
+	}
+
+	if plan := planMigration(cfg, repos[:1], ""); len(plan) != 0 {
+		t.Errorf("Expected nothing left to migrate, got %+v", plan)
+	}
+}
+
+func TestRemoveEmptyParents(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a", "b", "c"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "keep.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	removeEmptyParents(filepath.Join(root, "a", "b", "c"), root)
+
+	if pathExists(filepath.Join(root, "a", "b")) {
+		t.Error("Expected empty directories to be removed")
+	}
+	if !pathExists(filepath.Join(root, "a")) {
+		t.Error("Expected non-empty directory to be kept")
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note <repository> [note]",
+	Short: "Attach a local note and labels to a repository",
+	Long: `Attach a free-form note and labels to a repository, for your own workflow
+context. They are kept in the state file in the base directory, never sent
+to the provider, and follow the repository when it is renamed or moved.
+
+Notes and labels are shown by 'gitstuff list' and 'gitstuff browse', and
+--label limits list, sync and the other commands to the repositories with
+those labels. Without a note or flags, the current note and labels are
+printed.
+
+Examples:
+  gitstuff note backend/api "do not upgrade until Q3"
+  gitstuff note api --label oncall --label payments
+  gitstuff note api --remove-label payments
+  gitstuff note api ""                   # Remove the note, keep the labels
+  gitstuff note api --clear              # Remove the note and all labels
+  gitstuff sync --label oncall`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runNote,
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	noteCmd.Flags().StringSlice("label", nil, "Add a label; repeatable")
+	noteCmd.Flags().StringSlice("remove-label", nil, "Remove a label; repeatable")
+	noteCmd.Flags().Bool("clear", false, "Remove the note and all labels")
+	noteCmd.MarkFlagsMutuallyExclusive("clear", "label")
+	noteCmd.MarkFlagsMutuallyExclusive("clear", "remove-label")
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	add, _ := cmd.Flags().GetStringSlice("label")
+	remove, _ := cmd.Flags().GetStringSlice("remove-label")
+	clearAll, _ := cmd.Flags().GetBool("clear")
+	changing := len(args) == 2 || len(add) > 0 || len(remove) > 0 || clearAll
+	if changing {
+		if err := checkWritable("change notes"); err != nil {
+			return err
+		}
+		if clearAll && len(args) == 2 {
+			return fmt.Errorf("--clear cannot be combined with a note")
+		}
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	repos := collectRepositories(commandContext(cmd), clients, "", repoFilter{includeArchived: true})
+	repo, err := resolveRepositoryArg(repos, args[0])
+	if err != nil {
+		return err
+	}
+
+	st, err := state.Load(cfg.Local.BaseDir)
+	if err != nil {
+		return err
+	}
+	annotation := st.Annotation(repo)
+	if !changing {
+		if annotation.Empty() {
+			fmt.Fprintln(stdout, i18n.T("note.none", repo.FullPath))
+			return nil
+		}
+		fmt.Fprintf(stdout, "📁 %s\n", repo.FullPath)
+		writeAnnotation(stdout, annotation)
+		return nil
+	}
+
+	if clearAll {
+		annotation = state.Annotation{}
+	}
+	if len(args) == 2 {
+		annotation.Note = strings.TrimSpace(args[1])
+	}
+	annotation.Labels = updateLabels(annotation.Labels, add, remove)
+	if err := st.Annotate(repo, annotation); err != nil {
+		return err
+	}
+	if err := st.Save(); err != nil {
+		return err
+	}
+
+	if annotation.Empty() {
+		fmt.Fprintf(stdout, "✅ %s\n", i18n.T("note.cleared", repo.FullPath))
+		return nil
+	}
+	fmt.Fprintf(stdout, "✅ %s\n", i18n.T("note.saved", repo.FullPath))
+	writeAnnotation(stdout, annotation)
+	return nil
+}
+
+// updateLabels adds and then removes labels, ignoring case and keeping the
+// labels sorted and unique
+func updateLabels(labels, add, remove []string) []string {
+	var updated []string
+	for _, label := range append(append([]string(nil), labels...), add...) {
+		label = strings.TrimSpace(label)
+		if label == "" || containsFold(updated, label) || containsFold(remove, label) {
+			continue
+		}
+		updated = append(updated, label)
+	}
+	slices.SortFunc(updated, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return updated
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
+
+// writeAnnotation writes the note and labels of a repository under its
+// line in a listing
+func writeAnnotation(w io.Writer, annotation state.Annotation) {
+	if annotation.Note != "" {
+		fmt.Fprintf(w, "   📝 %s\n", i18n.T("field.note", annotation.Note))
+	}
+	if len(annotation.Labels) > 0 {
+		fmt.Fprintf(w, "   🔖 %s\n", i18n.T("field.labels", strings.Join(annotation.Labels, ", ")))
+	}
+}
+
+// annotationSummary is the note and labels of a repository on one line, for
+// the browser
+func annotationSummary(annotation state.Annotation) string {
+	var parts []string
+	if len(annotation.Labels) > 0 {
+		parts = append(parts, "🔖 "+strings.Join(annotation.Labels, ", "))
+	}
+	if annotation.Note != "" {
+		parts = append(parts, "📝 "+annotation.Note)
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_Note(t *testing.T) {
+	cfg := testConfig(t.TempDir(), "work")
+	client := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+		{ID: "1", Name: "api", FullPath: "team/api", Provider: "gitlab"},
+		{ID: "2", Name: "web", FullPath: "team/web", Provider: "gitlab"},
+	}}
+	clients := map[string]scm.Client{"work": client}
+	run := func(args ...string) (string, error) {
+		resetFlags(rootCmd)
+		return runCommand(t, cfg, clients, args...)
+	}
+
+	if _, err := run("note", "api", "do not upgrade", "--label", "oncall", "--label", "payments"); err != nil {
+		t.Fatalf("note failed: %v", err)
+	}
+	if _, err := run("note", "api", "--remove-label", "Payments"); err != nil {
+		t.Fatalf("note failed: %v", err)
+	}
+
+	out, err := run("note", "api")
+	if err != nil {
+		t.Fatalf("note failed: %v", err)
+	}
+	if want := "📁 team/api\n   📝 Note: do not upgrade\n   🔖 Labels: oncall\n"; out != want {
+		t.Errorf("Expected the annotation, got:\n%s", out)
+	}
+
+	out, err = run("list", "--status=false", "--label", "oncall")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "📁 [gitlab] team/api\n   📝 Note: do not upgrade\n") || strings.Contains(out, "team/web") {
+		t.Errorf("Expected only the labelled repository with its note, got:\n%s", out)
+	}
+
+	if _, err := run("note", "api", "--clear"); err != nil {
+		t.Fatalf("note failed: %v", err)
+	}
+	if out, _ := run("note", "api"); !strings.Contains(out, "team/api has no note or labels") {
+		t.Errorf("Expected the annotation to be cleared, got:\n%s", out)
+	}
+}
+
+func TestUpdateLabels(t *testing.T) {
+	got := updateLabels([]string{"oncall", "Payments"}, []string{"backend", "ONCALL", " "}, []string{"payments"})
+	if want := []string{"backend", "oncall"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("updateLabels() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open [repository]",
+	Short: "Open a repository's web page in the browser",
+	Long: `Open the web page of a repository in the default browser. The repository is
+given by its full path (or just its name when that is unique), by a local
+directory, or taken from the current working directory.
+
+Examples:
+  gitstuff open                      # Repository of the current directory
+  gitstuff open backend/api --mrs    # Merge requests of backend/api
+  gitstuff open api --pipelines      # CI pipelines (GitHub Actions on GitHub)
+  gitstuff open --issues --print     # Print the issues URL instead of opening it`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOpen,
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().Bool("mrs", false, "Open the merge requests page")
+	openCmd.Flags().Bool("prs", false, "Open the pull requests page (same as --mrs)")
+	openCmd.Flags().Bool("issues", false, "Open the issues page")
+	openCmd.Flags().Bool("pipelines", false, "Open the CI pipelines page")
+	openCmd.Flags().Bool("print", false, "Print the URL instead of opening it")
+	openCmd.MarkFlagsMutuallyExclusive("mrs", "prs", "issues", "pipelines")
+}
+
+type repoPage int
+
+const (
+	pageHome repoPage = iota
+	pageChangeRequests
+	pageIssues
+	pagePipelines
+)
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	page := pageHome
+	for flag, p := range map[string]repoPage{"mrs": pageChangeRequests, "prs": pageChangeRequests, "issues": pageIssues, "pipelines": pagePipelines} {
+		if set, _ := cmd.Flags().GetBool(flag); set {
+			page = p
+		}
+	}
+	printOnly, _ := cmd.Flags().GetBool("print")
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	repos := collectRepositories(commandContext(cmd), clients, "", repoFilter{includeArchived: true})
+
+	var repo *scm.Repository
+	if len(args) == 1 && !isDirectory(args[0]) {
+		repo, err = resolveRepositoryArg(repos, args[0])
+	} else {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		repo, err = findRepositoryByDirectory(cfg, repos, dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	url := repoPageURL(repo, page)
+	if printOnly {
+		fmt.Fprintln(stdout, url)
+		return nil
+	}
+	fmt.Fprintln(stdout, i18n.T("browse.opening", url))
+	if err := openURL(url); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	return nil
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// resolveRepositoryArg finds a repository by full path, or by name or path
+// suffix when only one repository matches
+func resolveRepositoryArg(repos []*scm.Repository, path string) (*scm.Repository, error) {
+	path = strings.Trim(path, "/")
+
+	var exact, suffix []*scm.Repository
+	for _, repo := range repos {
+		switch {
+		case strings.EqualFold(repo.FullPath, path):
+			exact = append(exact, repo)
+		case strings.HasSuffix(strings.ToLower(repo.FullPath), "/"+strings.ToLower(path)):
+			suffix = append(suffix, repo)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = suffix
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no repository matches '%s'", path)
+	case 1:
+		return matches[0], nil
+	}
+
+	names := make([]string, len(matches))
+	for i, repo := range matches {
+		names[i] = fmt.Sprintf("%s [%s]", repo.FullPath, repo.Provider)
+	}
+	return nil, fmt.Errorf("'%s' matches several repositories: %s", path, strings.Join(names, ", "))
+}
+
+// findRepositoryByDirectory finds the repository cloned at, or containing,
+// dir
+func findRepositoryByDirectory(cfg *config.Config, repos []*scm.Repository, dir string) (*scm.Repository, error) {
+	top, err := git.TopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+	top = canonicalPath(top)
+
+	for _, repo := range repos {
+		if canonicalPath(paths.GetClonePath(cfg, repo)) == top || canonicalPath(paths.GetLegacyPath(cfg, repo)) == top {
+			return repo, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not a repository cloned by gitstuff", top)
+}
+
+// canonicalPath makes paths comparable by resolving them to absolute paths
+// without symlinks
+func canonicalPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+// repoPageURL returns the URL of a page of the repository's web UI
+func repoPageURL(repo *scm.Repository, page repoPage) string {
+	base := strings.TrimSuffix(repo.WebURL, "/")
+	if repo.Provider == "github" {
+		switch page {
+		case pageChangeRequests:
+			return base + "/pulls"
+		case pageIssues:
+			return base + "/issues"
+		case pagePipelines:
+			return base + "/actions"
+		}
+		return base
+	}
+
+	switch page {
+	case pageChangeRequests:
+		return base + "/-/merge_requests"
+	case pageIssues:
+		return base + "/-/issues"
+	case pagePipelines:
+		return base + "/-/pipelines"
+	}
+	return base
+}
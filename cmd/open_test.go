@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestRepoPageURL(t *testing.T) {
+	gitlabRepo := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api"}
+	githubRepo := &scm.Repository{Provider: "github", WebURL: "https://github.com/octo/tool/"}
+
+	tests := []struct {
+		repo *scm.Repository
+		page repoPage
+		want string
+	}{
+		{gitlabRepo, pageHome, "https://gitlab.example.com/team/api"},
+		{gitlabRepo, pageChangeRequests, "https://gitlab.example.com/team/api/-/merge_requests"},
+		{gitlabRepo, pageIssues, "https://gitlab.example.com/team/api/-/issues"},
+		{gitlabRepo, pagePipelines, "https://gitlab.example.com/team/api/-/pipelines"},
+		{githubRepo, pageHome, "https://github.com/octo/tool"},
+		{githubRepo, pageChangeRequests, "https://github.com/octo/tool/pulls"},
+		{githubRepo, pageIssues, "https://github.com/octo/tool/issues"},
+		{githubRepo, pagePipelines, "https://github.com/octo/tool/actions"},
+	}
+
+	for _, tt := range tests {
+		if got := repoPageURL(tt.repo, tt.page); got != tt.want {
+			t.Errorf("repoPageURL(%s, %d) = %q, want %q", tt.repo.Provider, tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestResolveRepositoryArg(t *testing.T) {
+	repos := []*scm.Repository{
+		{FullPath: "team/api", Provider: "gitlab"},
+		{FullPath: "team/web", Provider: "gitlab"},
+		{FullPath: "other/web", Provider: "github"},
+		{FullPath: "team/api", Provider: "github"},
+		{FullPath: "solo/cli", Provider: "github"},
+	}
+
+	tests := []struct {
+		path    string
+		want    *scm.Repository
+		wantErr bool
+	}{
+		{path: "solo/cli", want: repos[4]},
+		{path: "cli", want: repos[4]},
+		{path: "/SOLO/CLI/", want: repos[4]},
+		{path: "other/web", want: repos[2]},
+		{path: "web", wantErr: true},
+		{path: "team/api", wantErr: true},
+		{path: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := resolveRepositoryArg(repos, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveRepositoryArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRepositoryArg() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRepositoryByDirectory(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	subdir := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean", "docs")
+	if err := os.MkdirAll(subdir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := findRepositoryByDirectory(cfg, repos, subdir)
+	if err != nil {
+		t.Fatalf("findRepositoryByDirectory failed: %v", err)
+	}
+	if repo != repos[0] {
+		t.Errorf("Expected %s, got %s", repos[0].FullPath, repo.FullPath)
+	}
+
+	if _, err := findRepositoryByDirectory(cfg, repos[2:], subdir); err == nil {
+		t.Error("Expected an error for a repository gitstuff does not know")
+	}
+	if _, err := findRepositoryByDirectory(cfg, repos, t.TempDir()); err == nil {
+		t.Error("Expected an error outside a repository")
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/codeowners"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners [team-or-user]",
+	Short: "Report code ownership across cloned repositories",
+	Long: `Read the CODEOWNERS file of every repository under the base directory and
+report who owns what.
+
+Without arguments, every owner is listed with the repositories they appear in,
+followed by the repositories that have no owners. With a team or user, only
+the repositories (and the patterns within them) that it owns are listed. A
+bare team name such as "backend" matches "@org/backend".
+
+CODEOWNERS is looked up in .github/, the repository root, docs/ and .gitlab/,
+in that order.
+
+Examples:
+  gitstuff owners                  # All owners and unowned repositories
+  gitstuff owners @org/backend     # Repositories owned by a team
+  gitstuff owners backend          # Same, matching the team name only
+  gitstuff owners --unowned        # Repositories without any owners`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOwners,
+}
+
+func init() {
+	rootCmd.AddCommand(ownersCmd)
+	ownersCmd.Flags().Bool("unowned", false, "Only list repositories without owners")
+}
+
+type repoOwnership struct {
+	Path string
+	File *codeowners.File
+	Err  error
+}
+
+// owned reports whether the repository's CODEOWNERS names anyone
+func (o repoOwnership) owned() bool {
+	return o.File != nil && len(o.File.Owners()) > 0
+}
+
+func runOwners(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	unowned, _ := cmd.Flags().GetBool("unowned")
+	if unowned && len(args) == 1 {
+		return fmt.Errorf("--unowned cannot be combined with a team or user")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	root := cfg.Local.BaseDir
+	verbosity.Debug("Scanning %s for git repositories", root)
+	repoPaths, err := git.FindRepositories(root)
+	if err != nil {
+		return err
+	}
+	results := collectOwnership(root, repoPaths)
+	verbosity.DebugTiming(start, "Read CODEOWNERS from %d repositories", len(results))
+
+	switch {
+	case unowned:
+		displayUnowned(stdout, results)
+	case len(args) == 1:
+		displayOwnedBy(stdout, args[0], results)
+	default:
+		displayOwnersIndex(stdout, results)
+	}
+	return nil
+}
+
+func collectOwnership(root string, repoPaths []string) []repoOwnership {
+	results := make([]repoOwnership, 0, len(repoPaths))
+	for _, repoPath := range repoPaths {
+		name := repoPath
+		if rel, err := filepath.Rel(root, repoPath); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		file, err := codeowners.Load(repoPath)
+		results = append(results, repoOwnership{Path: name, File: file, Err: err})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results
+}
+
+// displayOwnersIndex lists every owner with the repositories naming them,
+// then the repositories without owners
+func displayOwnersIndex(w io.Writer, results []repoOwnership) {
+	reposByOwner := make(map[string][]string)
+	for _, result := range results {
+		if result.File == nil {
+			continue
+		}
+		for _, owner := range result.File.Owners() {
+			reposByOwner[owner] = append(reposByOwner[owner], result.Path)
+		}
+	}
+
+	owners := make([]string, 0, len(reposByOwner))
+	for owner := range reposByOwner {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	fmt.Fprintf(w, "%s\n\n", i18n.T("owners.header", len(results)))
+	for _, owner := range owners {
+		repos := reposByOwner[owner]
+		fmt.Fprintf(w, "👥 %s (%s)\n", owner, i18n.T("owners.repo_count", len(repos)))
+		for _, repo := range repos {
+			fmt.Fprintf(w, "   %s\n", repo)
+		}
+	}
+	if len(owners) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	displayUnowned(w, results)
+}
+
+// displayOwnedBy lists the repositories in which query owns at least one
+// pattern
+func displayOwnedBy(w io.Writer, query string, results []repoOwnership) {
+	count := 0
+	for _, result := range results {
+		if result.File == nil {
+			continue
+		}
+		patterns := result.File.PatternsOwnedBy(query)
+		if len(patterns) == 0 {
+			continue
+		}
+		if count == 0 {
+			fmt.Fprintf(w, "%s\n\n", i18n.T("owners.owned_header", query))
+		}
+		count++
+		fmt.Fprintf(w, "📁 %s (%s)\n", result.Path, strings.Join(patterns, ", "))
+	}
+
+	if count == 0 {
+		fmt.Fprintln(w, i18n.T("owners.owned_none", query))
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("owners.owned_summary", query, count, len(results)))
+}
+
+// displayUnowned lists repositories without a CODEOWNERS file, or whose file
+// names no owners, and repositories whose file could not be read
+func displayUnowned(w io.Writer, results []repoOwnership) {
+	var unowned []repoOwnership
+	for _, result := range results {
+		if !result.owned() {
+			unowned = append(unowned, result)
+		}
+	}
+
+	if len(unowned) == 0 {
+		fmt.Fprintln(w, i18n.T("owners.all_owned", len(results)))
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n", i18n.T("owners.unowned_header", len(unowned), len(results)))
+	for _, result := range unowned {
+		var reason string
+		switch {
+		case result.Err != nil:
+			reason = i18n.T("owners.read_failed", redact.Error(result.Err))
+		case result.File == nil:
+			reason = i18n.T("owners.no_file")
+		default:
+			reason = i18n.T("owners.no_owners", result.File.Path)
+		}
+		fmt.Fprintf(w, "⚠️  %s - %s\n", result.Path, reason)
+	}
+}
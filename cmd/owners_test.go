@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupOwnersFixture(t *testing.T) (string, []string) {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"team/api/.github/CODEOWNERS": "* @org/backend\n/docs/ @org/docs\n",
+		"team/web/CODEOWNERS":         "* @org/frontend\n/api-client/ @org/backend\n",
+		"team/empty/CODEOWNERS":       "# owners to be decided\n/src/\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "team", "legacy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var repoPaths []string
+	for _, name := range []string{"web", "api", "empty", "legacy"} {
+		repoPaths = append(repoPaths, filepath.Join(root, "team", name))
+	}
+	return root, repoPaths
+}
+
+func TestDisplayOwnedBy(t *testing.T) {
+	root, repoPaths := setupOwnersFixture(t)
+	results := collectOwnership(root, repoPaths)
+
+	var buf bytes.Buffer
+	displayOwnedBy(&buf, "backend", results)
+	output := buf.String()
+
+	for _, want := range []string{"team/api (*)", "team/web (/api-client/)", "owns code in 2 of 4 repositories"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "team/api") > strings.Index(output, "team/web") {
+		t.Errorf("Expected repositories sorted by path, got:\n%s", output)
+	}
+
+	buf.Reset()
+	displayOwnedBy(&buf, "@org/nobody", results)
+	if !strings.Contains(buf.String(), "No repositories are owned by @org/nobody") {
+		t.Errorf("Unexpected output for unknown owner:\n%s", buf.String())
+	}
+}
+
+func TestDisplayOwnersIndex(t *testing.T) {
+	root, repoPaths := setupOwnersFixture(t)
+
+	var buf bytes.Buffer
+	displayOwnersIndex(&buf, collectOwnership(root, repoPaths))
+	output := buf.String()
+
+	for _, want := range []string{
+		"@org/backend (2 repositories)",
+		"@org/docs (1 repositories)",
+		"Repositories without owners (2 of 4)",
+		"team/empty - CODEOWNERS names no owners",
+		"team/legacy - no CODEOWNERS file",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"gitstuff/internal/progress"
+	"gitstuff/internal/verbosity"
+
+	"golang.org/x/term"
+)
+
+var noProgress bool
+
+// stdoutIsTerminal is replaced by tests
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// newProgressBar returns a progress bar for total repositories when out is
+// a terminal, or nil when output should scroll as usual: when it is piped,
+// with --no-progress, or with -v, whose log lines would break up the bar
+func newProgressBar(out io.Writer, total int) *progress.Bar {
+	if noProgress || out != io.Writer(stdout) || verbosity.IsEnabled(verbosity.InfoLevel) || !stdoutIsTerminal() {
+		return nil
+	}
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width = 0
+	}
+	return progress.New(out, total, width)
+}
+
+// processWithProgress processes repo with its output held back, showing it
+// above the bar only when the repository needs the user's attention
+func processWithProgress(bar *progress.Bar, process func(w io.Writer) (repoOutcome, error), name string) (repoOutcome, error) {
+	bar.Start(name)
+	var buf bytes.Buffer
+	outcome, err := process(&buf)
+	shown := ""
+	if outcome == outcomeFailed || outcome == outcomeDirty || outcome == outcomeProtected {
+		shown = buf.String()
+	}
+	bar.Finish(name, err != nil, shown)
+	return outcome, err
+}
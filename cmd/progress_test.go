@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/output"
+	"gitstuff/internal/scm"
+)
+
+func TestProcessRepositories_ProgressBar(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	var repos []*scm.Repository
+	for i := 0; i < 2; i++ {
+		repos = append(repos, &scm.Repository{
+			Name:     fmt.Sprintf("repo%d", i),
+			FullPath: fmt.Sprintf("group/repo%d", i),
+			CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", fmt.Sprintf("repo%d.git", i))),
+			Provider: "gitlab",
+		})
+	}
+	repos = append(repos, &scm.Repository{
+		Name:     "broken",
+		FullPath: "group/broken",
+		CloneURL: filepath.Join(tempDir, "remotes", "missing.git"),
+		Provider: "gitlab",
+	})
+
+	var buf bytes.Buffer
+	oldStdout, oldTerminal := stdout, stdoutIsTerminal
+	stdout = output.New(&buf)
+	stdoutIsTerminal = func() bool { return true }
+	defer func() { stdout, stdoutIsTerminal = oldStdout, oldTerminal }()
+
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 2}, stdout)
+	if summary.Cloned != 2 || summary.Failed() != 1 {
+		t.Fatalf("Expected 2 cloned and 1 failed, got %d and %d", summary.Cloned, summary.Failed())
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "3/3 (1 failed)") {
+		t.Errorf("Expected the final count on the bar, got %q", got)
+	}
+	if !strings.Contains(got, "Processing group/broken [gitlab]") || !strings.Contains(got, "Failed to clone") {
+		t.Errorf("Expected the failed repository's output to be shown, got %q", got)
+	}
+	if strings.Contains(got, "Cloned successfully") {
+		t.Errorf("Expected successful clones to be shown only on the bar, got %q", got)
+	}
+	if !strings.HasSuffix(got, "\x1b[J") {
+		t.Errorf("Expected the bar to be erased at the end, got %q", got)
+	}
+}
+
+func TestNewProgressBar_Disabled(t *testing.T) {
+	oldStdout, oldTerminal := stdout, stdoutIsTerminal
+	stdout = output.New(&bytes.Buffer{})
+	defer func() { stdout, stdoutIsTerminal = oldStdout, oldTerminal }()
+
+	stdoutIsTerminal = func() bool { return false }
+	if newProgressBar(stdout, 3) != nil {
+		t.Error("Expected no progress bar when stdout is not a terminal")
+	}
+
+	stdoutIsTerminal = func() bool { return true }
+	if newProgressBar(&bytes.Buffer{}, 3) != nil {
+		t.Error("Expected no progress bar for output other than stdout")
+	}
+
+	noProgress = true
+	defer func() { noProgress = false }()
+	if newProgressBar(stdout, 3) != nil {
+		t.Error("Expected no progress bar with --no-progress")
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/urlrewrite"
+
+	"github.com/spf13/cobra"
+)
+
+// cloneUsesSSH reports whether repo should first be cloned over SSH: the
+// protocol that last worked for its provider when fallback is enabled,
+// otherwise the one asked for by flags, then the one set for its provider
+func cloneUsesSSH(cfg *config.Config, repo *scm.Repository, opts cloneOptions) bool {
+	if opts.protocolFallback {
+		switch opts.state.Protocol(repo) {
+		case "ssh":
+			return true
+		case "https":
+			return false
+		}
+	}
+	if !opts.protocolSet {
+		if provider := providerFor(cfg, repo); provider != nil && provider.Protocol != "" {
+			return provider.Protocol == "ssh"
+		}
+	}
+	return opts.useSSH
+}
+
+// cloneURLFor returns the URL to clone repo from over SSH or HTTPS, after
+// the rewrites configured for its provider and then the url_rewrites
+func cloneURLFor(cfg *config.Config, repo *scm.Repository, useSSH bool) string {
+	if !useSSH {
+		if repo.CloneURL == "" {
+			return ""
+		}
+		return urlrewrite.InsteadOf(repo.CloneURL, cfg.URLRewrites)
+	}
+	if repo.SSHCloneURL == "" {
+		return ""
+	}
+	sshURL := repo.SSHCloneURL
+	if provider := providerFor(cfg, repo); provider != nil {
+		sshURL = urlrewrite.SSHHost(sshURL, provider.SSHHost)
+	}
+	return urlrewrite.InsteadOf(sshURL, cfg.URLRewrites)
+}
+
+func protocolName(useSSH bool) string {
+	if useSSH {
+		return "ssh"
+	}
+	return "https"
+}
+
+// cloneWithFallback clones repo to clonePath, sending the provider token
+// with HTTPS clones. With protocol fallback enabled, a clone that fails to
+// authenticate or connect is retried over the other protocol, and the
+// protocol that worked is recorded for the provider.
+func cloneWithFallback(cfg *config.Config, repo *scm.Repository, clonePath string, useSSH bool, opts cloneOptions, stdout, stderr io.Writer) error {
+	reference := cloneReference(cfg, repo, opts)
+	if reference != "" {
+		fmt.Fprintf(stdout, "🔗 %s\n", i18n.T("clone.reference", reference))
+	}
+	if !opts.protocolFallback {
+		return cloneOverProtocol(cfg, repo, clonePath, useSSH, reference, stdout, stderr)
+	}
+
+	var output bytes.Buffer
+	err := cloneOverProtocol(cfg, repo, clonePath, useSSH, reference, stdout, io.MultiWriter(stderr, &output))
+	if err == nil {
+		opts.state.RecordProtocol(repo, protocolName(useSSH))
+		return nil
+	}
+	other := cloneURLFor(cfg, repo, !useSSH)
+	if other == "" || !git.IsAccessError(output.String()) {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "🔁 %s\n", i18n.T("clone.protocol_retry", strings.ToUpper(protocolName(!useSSH)), redact.String(other)))
+	if retryErr := cloneOverProtocol(cfg, repo, clonePath, !useSSH, reference, stdout, stderr); retryErr != nil {
+		return fmt.Errorf("%w (over %s: %v)", err, strings.ToUpper(protocolName(!useSSH)), retryErr)
+	}
+	opts.state.RecordProtocol(repo, protocolName(!useSSH))
+	return nil
+}
+
+func cloneOverProtocol(cfg *config.Config, repo *scm.Repository, clonePath string, useSSH bool, reference string, stdout, stderr io.Writer) error {
+	cloneURL := cloneURLFor(cfg, repo, useSSH)
+	cloneOpts := git.CloneOptions{Reference: reference}
+	if !useSSH {
+		cloneOpts.Auth = httpAuthFor(cfg, repo, cloneURL)
+	}
+	return git.CloneRepositoryWithOptions(cloneURL, clonePath, cloneOpts, stdout, stderr)
+}
+
+// httpAuthFor returns the token of the provider hosting repo for HTTPS
+// requests to remoteURL, limited to its host. It is nil when there is no
+// token, git's credential helper is to be used instead, or remoteURL is not
+// on the provider's host, for example after a url_rewrites entry points it
+// at a mirror.
+func httpAuthFor(cfg *config.Config, repo *scm.Repository, remoteURL string) *git.HTTPAuth {
+	if cfg.Git.CredentialHelper {
+		return nil
+	}
+	provider := providerFor(cfg, repo)
+	if provider == nil || provider.Token == "" {
+		return nil
+	}
+	parsed, err := url.Parse(remoteURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return nil
+	}
+	host := strings.ToLower(parsed.Host)
+	if providerHost := urlHost(provider.URL); host == "" || (providerHost != host && providerHost != "api."+host) {
+		return nil
+	}
+	username := "oauth2"
+	if provider.Type == "github" {
+		username = "x-access-token"
+	}
+	return &git.HTTPAuth{Username: username, Token: provider.Token, URL: parsed.Scheme + "://" + parsed.Host + "/"}
+}
+
+// originAuthFor returns the token for pulls and fetches of the clone of repo
+// at repoPath, limited to the host of its origin remote
+func originAuthFor(cfg *config.Config, repo *scm.Repository, repoPath string) *git.HTTPAuth {
+	originURL, err := git.RemoteURL(repoPath, "origin")
+	if err != nil {
+		return nil
+	}
+	return httpAuthFor(cfg, repo, originURL)
+}
+
+// providerFor returns the configured provider hosting repo: the one of its
+// type whose URL has the repository's host, or the only one of its type
+func providerFor(cfg *config.Config, repo *scm.Repository) *config.ProviderConfig {
+	host := urlHost(repo.WebURL)
+	var candidates []*config.ProviderConfig
+	for i := range cfg.Providers {
+		provider := &cfg.Providers[i]
+		if provider.Type != repo.Provider {
+			continue
+		}
+		if providerHost := urlHost(provider.URL); host != "" && (providerHost == host || providerHost == "api."+host) {
+			return provider
+		}
+		candidates = append(candidates, provider)
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
+// protocolFallbackFromFlags returns whether protocol fallback is enabled by
+// --protocol-fallback, or else by the configuration
+func protocolFallbackFromFlags(cmd *cobra.Command, cfg *config.Config) bool {
+	if cmd.Flags().Changed("protocol-fallback") {
+		enabled, _ := cmd.Flags().GetBool("protocol-fallback")
+		return enabled
+	}
+	return cfg.Git.ProtocolFallback
+}
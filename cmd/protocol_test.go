@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+)
+
+func TestProviderFor(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{
+		{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com", Token: "work-token"},
+		{Name: "public", Type: "gitlab", URL: "https://gitlab.com", Token: "public-token"},
+		{Name: "hub", Type: "github", URL: "https://api.github.com", Token: "hub-token"},
+	}}
+
+	tests := []struct {
+		repo *scm.Repository
+		want string
+	}{
+		{&scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api"}, "work"},
+		{&scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.com/team/api"}, "public"},
+		{&scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.other.com/team/api"}, ""},
+		{&scm.Repository{Provider: "github", WebURL: "https://github.com/owner/tool"}, "hub"},
+		{&scm.Repository{Provider: "github", WebURL: "https://github.example.com/owner/tool"}, "hub"},
+	}
+	for _, tt := range tests {
+		got := providerFor(cfg, tt.repo)
+		if (got == nil && tt.want != "") || (got != nil && got.Name != tt.want) {
+			t.Errorf("providerFor(%s) = %+v, want %q", tt.repo.WebURL, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPAuthFor(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{
+		{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com", Token: "work-token"},
+		{Name: "hub", Type: "github", URL: "https://api.github.com", Token: "hub-token"},
+	}}
+	work := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api"}
+	hub := &scm.Repository{Provider: "github", WebURL: "https://github.com/owner/tool"}
+	// Only one GitLab provider is configured, so providerFor falls back to it
+	other := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.other.com/team/api"}
+
+	tests := []struct {
+		name      string
+		repo      *scm.Repository
+		remoteURL string
+		want      *git.HTTPAuth
+	}{
+		{"provider host", work, "https://gitlab.example.com/team/api.git", &git.HTTPAuth{Username: "oauth2", Token: "work-token", URL: "https://gitlab.example.com/"}},
+		{"github api host", hub, "https://github.com/owner/tool.git", &git.HTTPAuth{Username: "x-access-token", Token: "hub-token", URL: "https://github.com/"}},
+		{"rewritten to a mirror", work, "https://mirror.example.com/team/api.git", nil},
+		{"fallback provider on another host", other, "https://gitlab.other.com/team/api.git", nil},
+		{"ssh remote", work, "git@gitlab.example.com:team/api.git", nil},
+		{"local remote", work, "/srv/git/team/api.git", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := httpAuthFor(cfg, tt.repo, tt.remoteURL)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("httpAuthFor(%s) = %+v, want %+v", tt.remoteURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneUsesSSH_ProviderProtocol(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{
+		{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com", Protocol: "ssh"},
+		{Name: "public", Type: "gitlab", URL: "https://gitlab.com"},
+		{Name: "hub", Type: "github", URL: "https://api.github.com", Protocol: "https"},
+	}}
+	work := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api"}
+	hub := &scm.Repository{Provider: "github", WebURL: "https://github.com/owner/tool"}
+	other := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.com/team/api"}
+
+	tests := []struct {
+		name string
+		repo *scm.Repository
+		opts cloneOptions
+		want bool
+	}{
+		{"provider prefers ssh", work, cloneOptions{useSSH: false}, true},
+		{"provider prefers https", hub, cloneOptions{useSSH: true}, false},
+		{"no provider protocol", other, cloneOptions{useSSH: false}, false},
+		{"flag overrides provider", hub, cloneOptions{useSSH: true, protocolSet: true}, true},
+		{"flag overrides provider https", work, cloneOptions{useSSH: false, protocolSet: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cloneUsesSSH(cfg, tt.repo, tt.opts); got != tt.want {
+				t.Errorf("cloneUsesSSH() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloneWithFallback(t *testing.T) {
+	// Every SSH connection is refused as if the key were not accepted
+	t.Setenv("GIT_SSH_COMMAND", `sh -c 'echo "git@example.com: Permission denied (publickey)." >&2; exit 255'`)
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	repo := &scm.Repository{
+		FullPath:    "team/api",
+		Provider:    "gitlab",
+		WebURL:      "https://gitlab.example.com/team/api",
+		CloneURL:    createRemoteRepo(t, filepath.Join(tempDir, "remotes", "api.git")),
+		SSHCloneURL: "ssh://git@example.com/team/api.git",
+	}
+
+	var out bytes.Buffer
+	if err := cloneWithFallback(cfg, repo, filepath.Join(tempDir, "without"), true, cloneOptions{}, &out, &out); err == nil {
+		t.Fatal("Expected the SSH clone to fail without fallback")
+	}
+
+	st, err := state.Load(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	opts := cloneOptions{useSSH: true, protocolFallback: true, state: st}
+	clonePath := filepath.Join(tempDir, "with")
+	out.Reset()
+	if err := cloneWithFallback(cfg, repo, clonePath, cloneUsesSSH(cfg, repo, opts), opts, &out, &out); err != nil {
+		t.Fatalf("Expected the clone to fall back to HTTPS, got %v:\n%s", err, out.String())
+	}
+	if status, err := git.GetRepositoryStatus(clonePath); err != nil || !status.IsGitRepo {
+		t.Errorf("Expected a clone at %s", clonePath)
+	}
+	if got := st.Protocol(repo); got != "https" {
+		t.Errorf("Expected HTTPS to be recorded, got %q", got)
+	}
+	if cloneUsesSSH(cfg, repo, opts) {
+		t.Error("Expected later clones to start with HTTPS")
+	}
+}
+
+func TestCloneWithFallback_SendsTokenOverHTTPS(t *testing.T) {
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	headers := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: server.URL, Token: "work-token"}}}
+	repo := &scm.Repository{Provider: "gitlab", WebURL: server.URL + "/team/api", CloneURL: server.URL + "/team/api.git"}
+
+	var out bytes.Buffer
+	if err := cloneWithFallback(cfg, repo, filepath.Join(t.TempDir(), "api"), false, cloneOptions{}, &out, &out); err == nil {
+		t.Fatal("Expected the clone to fail")
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:work-token"))
+	if got := <-headers; got != want {
+		t.Errorf("Expected Authorization %q without protocol fallback, got %q", want, got)
+	}
+
+	cfg.Git.CredentialHelper = true
+	if err := cloneWithFallback(cfg, repo, filepath.Join(t.TempDir(), "api"), false, cloneOptions{}, &out, &out); err == nil {
+		t.Fatal("Expected the clone to fail")
+	}
+	if got := <-headers; got != "" {
+		t.Errorf("Expected no token with credential_helper, got %q", got)
+	}
+}
+
+func TestCloneWithFallback_NoTokenForRewrittenHost(t *testing.T) {
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	headers := make(chan string, 10)
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer mirror.Close()
+
+	cfg := &config.Config{
+		Providers:   []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com", Token: "work-token"}},
+		URLRewrites: map[string]string{"https://gitlab.example.com/": mirror.URL + "/"},
+	}
+	repo := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api", CloneURL: "https://gitlab.example.com/team/api.git"}
+
+	var out bytes.Buffer
+	if err := cloneWithFallback(cfg, repo, filepath.Join(t.TempDir(), "api"), false, cloneOptions{}, &out, &out); err == nil {
+		t.Fatal("Expected the clone to fail")
+	}
+	if got := <-headers; got != "" {
+		t.Errorf("Expected no token to be sent to the rewritten host, got %q", got)
+	}
+}
+
+func TestProcessRepository_SendsTokenOnUpdate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+
+	listen := func(headers chan string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers <- r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusForbidden)
+		}))
+	}
+	originHeaders, otherHeaders := make(chan string, 10), make(chan string, 10)
+	origin, other := listen(originHeaders), listen(otherHeaders)
+	defer origin.Close()
+	defer other.Close()
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: origin.URL, Token: "work-token"}},
+	}
+	repo := &scm.Repository{Name: "api", FullPath: "team/api", Provider: "gitlab", WebURL: origin.URL + "/team/api",
+		CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "api.git"))}
+
+	var out bytes.Buffer
+	if _, err := processRepository(context.Background(), &out, "api", repo, cfg, cloneOptions{}); err != nil {
+		t.Fatalf("Failed to clone: %v\n%s", err, out.String())
+	}
+	clonePath := paths.ResolveRepositoryPath(cfg, repo)
+	for _, args := range [][]string{
+		{"remote", "set-url", "origin", origin.URL + "/team/api.git"},
+		{"remote", "add", "fork", other.URL + "/someone/api.git"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", clonePath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:work-token"))
+	if _, err := processRepository(context.Background(), &out, "api", repo, cfg, cloneOptions{update: true, fetchOnly: true}); err == nil {
+		t.Fatal("Expected the fetch to fail")
+	}
+	if got := <-originHeaders; got != want {
+		t.Errorf("Expected fetch to send Authorization %q, got %q", want, got)
+	}
+	if got := <-otherHeaders; got != "" {
+		t.Errorf("Expected no token for the other remote's host, got %q", got)
+	}
+
+	if _, err := processRepository(context.Background(), &out, "api", repo, cfg, cloneOptions{update: true}); err == nil {
+		t.Fatal("Expected the pull to fail")
+	}
+	if got := <-originHeaders; got != want {
+		t.Errorf("Expected pull to send Authorization %q, got %q", want, got)
+	}
+}
+
+func TestCloneURLFor_SSHHost(t *testing.T) {
+	cfg := &config.Config{Providers: []config.ProviderConfig{
+		{Name: "work", Type: "gitlab", URL: "https://gitlab.com", SSHHost: "gitlab-work"},
+		{Name: "hub", Type: "github", URL: "https://api.github.com"},
+	}}
+	work := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.com/team/api", CloneURL: "https://gitlab.com/team/api.git", SSHCloneURL: "git@gitlab.com:team/api.git"}
+	hub := &scm.Repository{Provider: "github", WebURL: "https://github.com/octo/tool", SSHCloneURL: "git@github.com:octo/tool.git"}
+
+	if got := cloneURLFor(cfg, work, true); got != "git@gitlab-work:team/api.git" {
+		t.Errorf("Expected the SSH host alias, got %q", got)
+	}
+	if got := cloneURLFor(cfg, work, false); got != work.CloneURL {
+		t.Errorf("Expected HTTPS URLs to be left alone, got %q", got)
+	}
+	if got := cloneURLFor(cfg, hub, true); got != hub.SSHCloneURL {
+		t.Errorf("Expected providers without ssh_host to be left alone, got %q", got)
+	}
+}
+
+func TestCloneURLFor_URLRewrites(t *testing.T) {
+	cfg := &config.Config{
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.com", SSHHost: "gitlab-work"}},
+		URLRewrites: map[string]string{
+			"https://gitlab.com/": "https://mirror.internal/gitlab/",
+			"git@gitlab-work:":    "ssh://git@proxy.internal/gitlab/",
+		},
+	}
+	repo := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.com/team/api", CloneURL: "https://gitlab.com/team/api.git", SSHCloneURL: "git@gitlab.com:team/api.git"}
+
+	if got := cloneURLFor(cfg, repo, false); got != "https://mirror.internal/gitlab/team/api.git" {
+		t.Errorf("Expected the HTTPS URL to be rewritten, got %q", got)
+	}
+	if got := cloneURLFor(cfg, repo, true); got != "ssh://git@proxy.internal/gitlab/team/api.git" {
+		t.Errorf("Expected the SSH URL to be rewritten after the host alias, got %q", got)
+	}
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/cache"
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Find local clones whose remote repository was deleted or moved",
+	Long: `Compare the repositories under the base directory with the repositories on
+every configured provider and report clones that no longer belong to any of
+them: the remote was deleted, or moved to another group (reported with its
+new path when the repository name is unique).
+
+Nothing is changed unless --archive or --remove is given, and then only
+after confirmation. --remove skips clones with uncommitted changes, commits
+on no remote branch or stashes unless --force is also given. The provider listings are always fetched fresh, and
+the command refuses to act when any provider cannot be listed.
+
+Examples:
+  gitstuff prune                          # Report orphaned clones
+  gitstuff prune --archive ~/old-repos    # Move them out of the base directory
+  gitstuff prune --remove --yes           # Delete them without asking`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().String("archive", "", "Move orphaned clones into this directory")
+	pruneCmd.Flags().Bool("remove", false, "Delete orphaned clones")
+	pruneCmd.Flags().Bool("force", false, "With --remove, also delete clones with uncommitted changes, unpushed commits or stashes")
+	pruneCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+	pruneCmd.MarkFlagsMutuallyExclusive("archive", "remove")
+}
+
+type pruneEntry struct {
+	Path    string
+	Rel     string
+	MovedTo *scm.Repository
+	Dirty   bool
+	Skipped bool
+	Err     error
+
+	// Unpushed commits are on no remote-tracking branch, so like Stashes
+	// they exist only in this clone
+	Unpushed int
+	Stashes  int
+}
+
+// hasLocalWork reports whether removing the clone would lose work that
+// exists nowhere else
+func (e pruneEntry) hasLocalWork() bool {
+	return e.Dirty || e.Unpushed > 0 || e.Stashes > 0
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	archiveDir, _ := cmd.Flags().GetString("archive")
+	remove, _ := cmd.Flags().GetBool("remove")
+	force, _ := cmd.Flags().GetBool("force")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if archiveDir != "" || remove {
+		if err := checkWritable("archive or remove clones"); err != nil {
+			return err
+		}
+	}
+
+	// Clones outside the group of a provider are not orphans
+	clients, err := createUnscopedClients(cfg)
+	if err != nil {
+		return err
+	}
+	repos, err := listAllRepositoriesFresh(commandContext(cmd), clients)
+	if err != nil {
+		return err
+	}
+
+	localPaths, err := git.FindRepositories(cfg.Local.BaseDir)
+	if err != nil {
+		return err
+	}
+	orphans := findOrphans(cfg, repos, localPaths)
+	verbosity.DebugTiming(start, "Found %d orphaned clones among %d", len(orphans), len(localPaths))
+
+	displayOrphans(stdout, orphans)
+	if len(orphans) == 0 || (archiveDir == "" && !remove) {
+		return nil
+	}
+
+	prompt := i18n.T("prune.confirm_remove", len(orphans))
+	if archiveDir != "" {
+		archiveDir = expandHome(archiveDir)
+		prompt = i18n.T("prune.confirm_archive", len(orphans), archiveDir)
+	}
+	if !yes && !confirm(os.Stdin, stdout, prompt) {
+		fmt.Fprintln(stdout, i18n.T("prune.cancelled"))
+		return nil
+	}
+
+	if archiveDir != "" {
+		archiveOrphans(orphans, archiveDir)
+	} else {
+		removeOrphans(cfg, orphans, force)
+	}
+	if failed := displayPruneResult(stdout, orphans, archiveDir != ""); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to prune %d clones", failed)
+	}
+	return nil
+}
+
+// listAllRepositoriesFresh lists every provider's repositories, bypassing
+// the metadata cache. Unlike collectRepositories it fails when any provider
+// cannot be listed, as a partial listing would make clones look orphaned.
+func listAllRepositoriesFresh(ctx context.Context, clients []scm.Client) ([]*scm.Repository, error) {
+	var all []*scm.Repository
+	for _, client := range clients {
+		var repos []*scm.Repository
+		var err error
+		if cached, ok := client.(*cache.Client); ok {
+			repos, err = cached.Refresh(ctx)
+		} else {
+			repos, err = client.ListAllRepositories(ctx)
+		}
+		if err != nil {
+			return nil, &providerError{provider: client.GetProviderType(), err: err}
+		}
+		all = append(all, repos...)
+	}
+	return all, nil
+}
+
+// findOrphans returns the local repositories that are not where any provider
+// repository would be cloned
+func findOrphans(cfg *config.Config, repos []*scm.Repository, localPaths []string) []pruneEntry {
+	known := make(map[string]bool)
+	providerTypes := make(map[string]bool)
+	for _, provider := range cfg.Providers {
+		providerTypes[provider.Type] = true
+	}
+	for _, repo := range repos {
+		known[canonicalPath(paths.GetClonePath(cfg, repo))] = true
+		known[canonicalPath(paths.GetLegacyPath(cfg, repo))] = true
+		providerTypes[repo.Provider] = true
+	}
+
+	var orphans []pruneEntry
+	for _, localPath := range localPaths {
+		if known[canonicalPath(localPath)] {
+			continue
+		}
+		entry := pruneEntry{Path: localPath, Rel: localPath}
+		if rel, err := filepath.Rel(cfg.Local.BaseDir, localPath); err == nil {
+			entry.Rel = filepath.ToSlash(rel)
+		}
+
+		provider := ""
+		if first, _, ok := strings.Cut(entry.Rel, "/"); ok && providerTypes[first] {
+			provider = first
+		}
+		entry.MovedTo = findMovedRepository(repos, provider, filepath.Base(localPath))
+
+		if status, err := git.GetDetailedStatus(localPath); err == nil {
+			entry.Dirty = status.HasChanges
+			entry.Stashes = status.StashCount
+		}
+		if unpushed, err := git.UnpushedCommits(localPath); err == nil {
+			entry.Unpushed = unpushed
+		}
+		orphans = append(orphans, entry)
+	}
+	return orphans
+}
+
+// findMovedRepository returns the only repository called name (on provider,
+// when known), or nil if there is none or more than one
+func findMovedRepository(repos []*scm.Repository, provider, name string) *scm.Repository {
+	var match *scm.Repository
+	for _, repo := range repos {
+		if repo.Name != name || (provider != "" && repo.Provider != provider) {
+			continue
+		}
+		if match != nil {
+			return nil
+		}
+		match = repo
+	}
+	return match
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(in io.Reader, out io.Writer, prompt string) bool {
+	fmt.Fprintf(out, "%s (y/N): ", prompt)
+	response, _ := bufio.NewReader(in).ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
+func archiveOrphans(orphans []pruneEntry, archiveDir string) {
+	for i := range orphans {
+		entry := &orphans[i]
+		target := filepath.Join(archiveDir, filepath.FromSlash(entry.Rel))
+		if pathExists(target) {
+			entry.Err = fmt.Errorf("%s already exists", target)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			entry.Err = fmt.Errorf("failed to create directory: %w", err)
+			continue
+		}
+		if err := os.Rename(entry.Path, target); err != nil {
+			entry.Err = fmt.Errorf("failed to move clone: %w", err)
+		}
+	}
+}
+
+func removeOrphans(cfg *config.Config, orphans []pruneEntry, force bool) {
+	for i := range orphans {
+		entry := &orphans[i]
+		if entry.hasLocalWork() && !force {
+			entry.Skipped = true
+			continue
+		}
+		if err := os.RemoveAll(entry.Path); err != nil {
+			entry.Err = fmt.Errorf("failed to remove clone: %w", err)
+			continue
+		}
+		removeEmptyParents(filepath.Dir(entry.Path), cfg.Local.BaseDir)
+	}
+}
+
+func displayOrphans(w io.Writer, orphans []pruneEntry) {
+	if len(orphans) == 0 {
+		fmt.Fprintln(w, i18n.T("prune.none"))
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n\n", i18n.T("prune.header", len(orphans)))
+	moved := 0
+	for _, entry := range orphans {
+		note := ""
+		if entry.Dirty {
+			note += " " + i18n.T("sync.uncommitted")
+		}
+		if entry.Unpushed > 0 {
+			note += " " + i18n.T("prune.unpushed", entry.Unpushed)
+		}
+		if entry.Stashes > 0 {
+			note += " " + i18n.T("prune.stashed", entry.Stashes)
+		}
+		if entry.MovedTo != nil {
+			moved++
+			fmt.Fprintf(w, "➡️  %s - %s%s\n", entry.Rel, i18n.T("prune.moved", entry.MovedTo.FullPath, entry.MovedTo.Provider), note)
+		} else {
+			fmt.Fprintf(w, "🗑️  %s - %s%s\n", entry.Rel, i18n.T("prune.deleted"), note)
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("prune.summary", len(orphans)-moved, moved))
+}
+
+// displayPruneResult reports what was archived or removed and returns how
+// many clones could not be
+func displayPruneResult(w io.Writer, orphans []pruneEntry, archived bool) int {
+	done, skipped, failed := 0, 0, 0
+	for _, entry := range orphans {
+		switch {
+		case entry.Err != nil:
+			failed++
+			fmt.Fprintf(w, "❌ %s - %s\n", entry.Rel, i18n.T("prune.failed", redact.Error(entry.Err)))
+		case entry.Skipped:
+			skipped++
+			fmt.Fprintf(w, "⚠️  %s - %s\n", entry.Rel, i18n.T("prune.skipped_dirty"))
+		default:
+			done++
+		}
+	}
+
+	key := "prune.removed"
+	if archived {
+		key = "prune.archived"
+	}
+	fmt.Fprintln(w, i18n.T(key, done, skipped, failed))
+	return failed
+}
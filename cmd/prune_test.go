@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+func TestFindOrphans(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+
+	// clean was transferred to another group and dirty was deleted
+	remote := []*scm.Repository{
+		{Name: "clean", FullPath: "other/clean", Provider: "gitlab"},
+		repos[2],
+	}
+	localPaths, err := git.FindRepositories(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("Failed to find repositories: %v", err)
+	}
+
+	orphans := findOrphans(cfg, remote, localPaths)
+	if len(orphans) != 2 {
+		t.Fatalf("Expected 2 orphans, got %+v", orphans)
+	}
+	byRel := make(map[string]pruneEntry)
+	for _, entry := range orphans {
+		byRel[entry.Rel] = entry
+	}
+
+	clean := byRel["gitlab/group/clean"]
+	if clean.MovedTo == nil || clean.MovedTo.FullPath != "other/clean" || clean.Dirty {
+		t.Errorf("Expected clean to have moved to other/clean, got %+v", clean)
+	}
+	dirty := byRel["gitlab/group/dirty"]
+	if dirty.MovedTo != nil || !dirty.Dirty {
+		t.Errorf("Expected dirty to be deleted with changes, got %+v", dirty)
+	}
+
+	if none := findOrphans(cfg, repos, localPaths); len(none) != 0 {
+		t.Errorf("Expected no orphans when every clone is listed, got %+v", none)
+	}
+
+	var buf bytes.Buffer
+	displayOrphans(&buf, orphans)
+	if !strings.Contains(buf.String(), "other/clean") {
+		t.Errorf("Expected the new location in output, got:\n%s", buf.String())
+	}
+}
+
+func TestFindMovedRepository(t *testing.T) {
+	repos := []*scm.Repository{
+		{Name: "api", FullPath: "a/api", Provider: "gitlab"},
+		{Name: "api", FullPath: "b/api", Provider: "github"},
+		{Name: "web", FullPath: "a/web", Provider: "gitlab"},
+		{Name: "web", FullPath: "b/web", Provider: "gitlab"},
+	}
+
+	tests := []struct {
+		provider string
+		name     string
+		want     string
+	}{
+		{"gitlab", "api", "a/api"},
+		{"", "api", ""},
+		{"gitlab", "web", ""},
+		{"gitlab", "gone", ""},
+	}
+	for _, tt := range tests {
+		got := findMovedRepository(repos, tt.provider, tt.name)
+		if (got == nil && tt.want != "") || (got != nil && got.FullPath != tt.want) {
+			t.Errorf("findMovedRepository(%q, %q) = %+v, want %q", tt.provider, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveAndArchiveOrphans(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	localPaths, err := git.FindRepositories(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("Failed to find repositories: %v", err)
+	}
+
+	orphans := findOrphans(cfg, repos[2:], localPaths)
+	removeOrphans(cfg, orphans, false)
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")) {
+		t.Error("Expected clean to be removed")
+	}
+	if !pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")) {
+		t.Error("Expected dirty to be kept without --force")
+	}
+
+	var buf bytes.Buffer
+	if failed := displayPruneResult(&buf, orphans, false); failed != 0 {
+		t.Errorf("Expected no failures, got %d:\n%s", failed, buf.String())
+	}
+
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+	orphans = findOrphans(cfg, repos[2:], []string{filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")})
+	archiveOrphans(orphans, archiveDir)
+	if orphans[0].Err != nil {
+		t.Fatalf("Failed to archive: %v", orphans[0].Err)
+	}
+	if !pathExists(filepath.Join(archiveDir, "gitlab", "group", "dirty", "wip.txt")) {
+		t.Error("Expected dirty to be moved into the archive with its changes")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := confirm(strings.NewReader(tt.input), &bytes.Buffer{}, "Continue?"); got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveOrphans_KeepsLocalWork(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	clean := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	dirty := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")
+	for _, args := range [][]string{
+		// clean gets a commit on a branch that was never pushed
+		{"-C", clean, "checkout", "-q", "-b", "local-only"},
+		{"-C", clean, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Local work"},
+		// dirty keeps its changes in a stash only
+		{"-C", dirty, "add", "wip.txt"},
+		{"-C", dirty, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "stash", "-q"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	orphans := findOrphans(cfg, repos[2:], []string{clean, dirty})
+	if orphans[0].Unpushed != 1 || orphans[1].Dirty || orphans[1].Stashes != 1 {
+		t.Fatalf("Expected one unpushed commit and one stash, got %+v", orphans)
+	}
+
+	var buf bytes.Buffer
+	displayOrphans(&buf, orphans)
+	for _, want := range []string{"gitlab/group/clean - no longer exists on any provider (1 unpushed commits)", "gitlab/group/dirty - no longer exists on any provider (1 stashes)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected the listing to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	removeOrphans(cfg, orphans, false)
+	if !pathExists(clean) || !pathExists(dirty) {
+		t.Fatal("Expected clones with local work to be kept without --force")
+	}
+	removeOrphans(cfg, orphans, true)
+	if pathExists(clean) || pathExists(dirty) {
+		t.Error("Expected --force to remove them")
+	}
+}
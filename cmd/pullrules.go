@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+// pullAction is what to do when pulling would merge into a default branch
+// that has local commits
+type pullAction string
+
+const (
+	pullRefuse pullAction = "refuse"
+	pullRebase pullAction = "rebase"
+	pullMerge  pullAction = "merge"
+)
+
+// pullRule is a config.PullRule ready to be matched against repositories
+type pullRule struct {
+	include patternSet
+	action  pullAction
+}
+
+func compilePullRules(rules []config.PullRule) ([]pullRule, error) {
+	compiled := make([]pullRule, 0, len(rules))
+	for _, rule := range rules {
+		action := pullAction(rule.OnProtectedBranch)
+		switch action {
+		case "":
+			action = pullRefuse
+		case pullRefuse, pullRebase, pullMerge:
+		default:
+			return nil, fmt.Errorf("invalid on_protected_branch %q (expected %q, %q or %q)", rule.OnProtectedBranch, pullRefuse, pullRebase, pullMerge)
+		}
+
+		include, err := compilePatternSet(rule.Include, nil)
+		if err != nil {
+			return nil, fmt.Errorf("pull rule: %w", err)
+		}
+		compiled = append(compiled, pullRule{include: include, action: action})
+	}
+	return compiled, nil
+}
+
+// protectedBranchAction returns the action of the first rule matching repo,
+// refusing by default
+func protectedBranchAction(rules []pullRule, repo *scm.Repository) pullAction {
+	for _, rule := range rules {
+		if rule.include.matches(repo.FullPath) {
+			return rule.action
+		}
+	}
+	return pullRefuse
+}
+
+// planPull decides how to pull the repository at repoPath. Pulling into the
+// default branch while it has local commits would create a merge commit the
+// provider will not accept, so that case follows the configured action.
+// ahead is the number of local commits when that case applies.
+func planPull(repoPath string, status *git.Status, repo *scm.Repository, rules []pullRule) (action pullAction, ahead int, err error) {
+	if repo.DefaultBranch == "" || status.CurrentBranch != repo.DefaultBranch {
+		return pullMerge, 0, nil
+	}
+
+	detailed, err := git.GetDetailedStatus(repoPath)
+	if err != nil {
+		return "", 0, err
+	}
+	if detailed.Ahead == 0 {
+		return pullMerge, 0, nil
+	}
+	return protectedBranchAction(rules, repo), detailed.Ahead, nil
+}
+
+// pullWithAction pulls the repository at repoPath the way strategy asks,
+// unless the protected-branch action requires a rebase
+func pullWithAction(repoPath string, action pullAction, strategy git.PullOptions, stdout, stderr io.Writer) error {
+	if action == pullRebase {
+		strategy.Rebase = true
+	}
+	return git.PullRepositoryWithOptions(repoPath, strategy, stdout, stderr)
+}
+
+// Pull strategies for --rebase, --ff-only and git.pull_strategy
+const (
+	pullStrategyMerge  = "merge"
+	pullStrategyRebase = "rebase"
+	pullStrategyFFOnly = "ff-only"
+)
+
+func addPullStrategyFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("rebase", false, "Pull with --rebase instead of merging (default: git.pull_strategy)")
+	cmd.Flags().Bool("ff-only", false, "Only fast-forward when pulling; diverged repositories fail instead of merging")
+	cmd.Flags().Bool("autostash", false, "Stash uncommitted changes around pulls instead of skipping dirty repositories")
+	cmd.MarkFlagsMutuallyExclusive("rebase", "ff-only")
+}
+
+// pullStrategyFromFlags returns how to pull from --rebase, --ff-only and
+// --autostash, or else from the configuration
+func pullStrategyFromFlags(cmd *cobra.Command, cfg *config.Config) (git.PullOptions, error) {
+	strategy := cfg.Git.PullStrategy
+	rebase, _ := cmd.Flags().GetBool("rebase")
+	ffOnly, _ := cmd.Flags().GetBool("ff-only")
+	switch {
+	case rebase:
+		strategy = pullStrategyRebase
+	case ffOnly:
+		strategy = pullStrategyFFOnly
+	}
+
+	opts := git.PullOptions{Autostash: cfg.Git.Autostash}
+	if cmd.Flags().Changed("autostash") {
+		opts.Autostash, _ = cmd.Flags().GetBool("autostash")
+	}
+	switch strategy {
+	case "":
+	case pullStrategyMerge:
+		opts.Merge = true
+	case pullStrategyRebase:
+		opts.Rebase = true
+	case pullStrategyFFOnly:
+		opts.FFOnly = true
+	default:
+		return git.PullOptions{}, fmt.Errorf("invalid git.pull_strategy %q (expected %q, %q or %q)", strategy, pullStrategyMerge, pullStrategyRebase, pullStrategyFFOnly)
+	}
+	return opts, nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompilePullRules(t *testing.T) {
+	rules, err := compilePullRules([]config.PullRule{
+		{Include: []string{"sandbox/**"}, OnProtectedBranch: "merge"},
+		{Include: []string{"team/**"}, OnProtectedBranch: "rebase"},
+		{Include: []string{"team/legacy"}, OnProtectedBranch: "merge"},
+	})
+	if err != nil {
+		t.Fatalf("compilePullRules failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want pullAction
+	}{
+		{path: "sandbox/alice/tool", want: pullMerge},
+		{path: "team/api", want: pullRebase},
+		{path: "team/legacy", want: pullRebase},
+		{path: "other/app", want: pullRefuse},
+	}
+	for _, tt := range tests {
+		if got := protectedBranchAction(rules, &scm.Repository{FullPath: tt.path}); got != tt.want {
+			t.Errorf("protectedBranchAction(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := compilePullRules([]config.PullRule{{OnProtectedBranch: "force"}}); err == nil {
+		t.Error("Expected error for unknown on_protected_branch")
+	}
+}
+
+// commitLocally adds a commit to the clone at repoPath and returns the name
+// of its current branch
+func commitLocally(t *testing.T, repoPath string) string {
+	t.Helper()
+	commit := exec.Command("git", "-C", repoPath, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "Local work")
+	if out, err := commit.CombinedOutput(); err != nil {
+		t.Fatalf("commit failed: %v\n%s", err, out)
+	}
+	branch, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse failed: %v", err)
+	}
+	return strings.TrimSpace(string(branch))
+}
+
+func TestProcessRepositories_ProtectedBranch(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         []config.PullRule
+		wantProtected bool
+		wantOutput    string
+	}{
+		{name: "refuse by default", wantProtected: true, wantOutput: "has 1 local commit(s) that would need a merge commit"},
+		{name: "rebase", rules: []config.PullRule{{OnProtectedBranch: "rebase"}}, wantOutput: "Rebasing 1 local commit(s)"},
+		{name: "merge", rules: []config.PullRule{{Include: []string{"group/*"}, OnProtectedBranch: "merge"}}, wantOutput: "Pulling latest changes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, repos := setupSyncFixture(t)
+			clean := repos[0]
+			clean.DefaultBranch = commitLocally(t, filepath.Join(cfg.Local.BaseDir, "gitlab", clean.FullPath))
+
+			rules, err := compilePullRules(tt.rules)
+			if err != nil {
+				t.Fatalf("compilePullRules failed: %v", err)
+			}
+
+			var out bytes.Buffer
+			summary := processRepositories(context.Background(), []*scm.Repository{clean}, cfg, cloneOptions{update: true, skipDirty: true, jobs: 1, pullRules: rules}, &out)
+
+			if got := len(summary.Protected) == 1; got != tt.wantProtected {
+				t.Errorf("protected = %v, want %v (summary %+v)\n%s", got, tt.wantProtected, summary, out.String())
+			}
+			if !tt.wantProtected && summary.Updated != 1 {
+				t.Errorf("Expected repository to be updated, got %+v\n%s", summary, out.String())
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.wantOutput, out.String())
+			}
+		})
+	}
+}
+
+func TestPlanSync_ProtectedBranch(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	clean := repos[0]
+	clean.DefaultBranch = commitLocally(t, filepath.Join(cfg.Local.BaseDir, "gitlab", clean.FullPath))
+
+	plan := planSync([]*scm.Repository{clean}, cfg, nil, false)
+	if plan[0].Action != syncSkipProtected {
+		t.Fatalf("Expected protected skip, got %d", plan[0].Action)
+	}
+
+	var buf bytes.Buffer
+	displaySyncPlan(&buf, plan)
+	if !strings.Contains(buf.String(), "(local commits on default branch)") || !strings.Contains(buf.String(), "skip 1") {
+		t.Errorf("Unexpected plan output:\n%s", buf.String())
+	}
+}
+
+func TestPullStrategyFromFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  config.GitConfig
+		args    []string
+		want    git.PullOptions
+		wantErr bool
+	}{
+		{name: "git decides", want: git.PullOptions{}},
+		{name: "config merge", config: config.GitConfig{PullStrategy: "merge"}, want: git.PullOptions{Merge: true}},
+		{name: "config rebase", config: config.GitConfig{PullStrategy: "rebase", Autostash: true}, want: git.PullOptions{Rebase: true, Autostash: true}},
+		{name: "flag overrides config", config: config.GitConfig{PullStrategy: "rebase"}, args: []string{"--ff-only"}, want: git.PullOptions{FFOnly: true}},
+		{name: "autostash flag off", config: config.GitConfig{Autostash: true}, args: []string{"--autostash=false"}, want: git.PullOptions{}},
+		{name: "rebase flag", args: []string{"--rebase", "--autostash"}, want: git.PullOptions{Rebase: true, Autostash: true}},
+		{name: "invalid config", config: config.GitConfig{PullStrategy: "squash"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{Use: "test"}
+			addPullStrategyFlags(cmd)
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+
+			got, err := pullStrategyFromFlags(cmd, &config.Config{Git: tt.config})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pullStrategyFromFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pullStrategyFromFlags() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
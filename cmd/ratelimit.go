@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/ratelimit"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+// proxyConnectCmd is the ssh ProxyCommand used while --limit-rate is active;
+// it tunnels an SSH connection through the rate limiting proxy. Hosts that
+// have their own ProxyCommand or ProxyJump in the ssh configuration keep
+// using it instead and are not throttled.
+var proxyConnectCmd = &cobra.Command{
+	Use:    "proxy-connect <proxy-addr> <host> <port> [<alias>]",
+	Hidden: true,
+	Args:   cobra.RangeArgs(3, 4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 4 {
+			if proxy := configuredSSHProxy(args[3], args[1], args[2]); proxy != nil {
+				proxy.Stdin, proxy.Stdout, proxy.Stderr = os.Stdin, os.Stdout, os.Stderr
+				return proxy.Run()
+			}
+		}
+		return ratelimit.Connect(args[0], args[1], args[2], os.Stdin, os.Stdout)
+	},
+}
+
+// configuredSSHProxy returns the proxy command that the ssh configuration
+// sets for alias, which resolves to host and port, or nil when it sets none
+func configuredSSHProxy(alias, host, port string) *exec.Cmd {
+	out, err := exec.Command("ssh", "-G", "-p", port, alias).Output()
+	if err != nil {
+		return nil
+	}
+	return sshProxyCommand(string(out), alias, host, port)
+}
+
+// sshProxyCommand returns the command for the ProxyCommand or ProxyJump in
+// sshConfig, the output of ssh -G, or nil when there is neither
+func sshProxyCommand(sshConfig, alias, host, port string) *exec.Cmd {
+	options := make(map[string]string)
+	for _, line := range strings.Split(sshConfig, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+			options[strings.ToLower(key)] = value
+		}
+	}
+
+	if command := options["proxycommand"]; command != "" && command != "none" {
+		command = strings.NewReplacer("%%", "%", "%h", host, "%p", port, "%n", alias, "%r", options["user"]).Replace(command)
+		if runtime.GOOS == "windows" {
+			return exec.Command("cmd", "/C", command)
+		}
+		return exec.Command("sh", "-c", command)
+	}
+	if jump := options["proxyjump"]; jump != "" && jump != "none" {
+		args := []string{"-W", net.JoinHostPort(host, port)}
+		if i := strings.LastIndex(jump, ","); i >= 0 {
+			args = append(args, "-J", jump[:i])
+			jump = jump[i+1:]
+		}
+		return exec.Command("ssh", append(args, jump)...)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(proxyConnectCmd)
+}
+
+func addLimitRateFlag(cmd *cobra.Command) {
+	cmd.Flags().String("limit-rate", "", "Limit the combined transfer rate of git, e.g. 500k or 2M (bytes per second)")
+}
+
+// startTransferLimit throttles git clones and pulls to the --limit-rate flag,
+// or git.limit_rate from config. The returned function stops throttling.
+func startTransferLimit(cmd *cobra.Command, cfg *config.Config) (func(), error) {
+	rate := cfg.Git.LimitRate
+	if cmd.Flags().Changed("limit-rate") {
+		rate, _ = cmd.Flags().GetString("limit-rate")
+	}
+
+	bytesPerSecond, err := ratelimit.ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	if bytesPerSecond == 0 {
+		return func() {}, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate gitstuff executable: %w", err)
+	}
+	upstream, err := upstreamProxy()
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := ratelimit.NewProxy(ratelimit.NewLimiter(bytesPerSecond), upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	git.SetTransferProxy(proxy.Addr(), fmt.Sprintf("%q proxy-connect %s %%h %%p %%n", exe, proxy.Addr()))
+	verbosity.Info("Limiting git transfers to %s", ratelimit.FormatRate(bytesPerSecond))
+
+	return func() {
+		git.SetTransferProxy("", "")
+		_ = proxy.Close()
+	}, nil
+}
+
+// upstreamProxy returns the proxy that throttled HTTP and HTTPS transfers go
+// on to, the one git would use without the limit: its http.proxy setting,
+// or else the proxy from the environment
+func upstreamProxy() (func(*http.Request) (*url.URL, error), error) {
+	configured := git.ConfigValue("", "http.proxy")
+	if configured == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	if !strings.Contains(configured, "://") {
+		configured = "http://" + configured
+	}
+	proxyURL, err := url.Parse(configured)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http.proxy in git configuration")
+	}
+	return http.ProxyURL(proxyURL), nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSSHProxyCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"no proxy", "user git\nhostname gitlab.example.com\nport 22\n", ""},
+		{"proxy command none", "user git\nproxycommand none\n", ""},
+		{"proxy command", "user git\nproxycommand nc -X connect -x bastion:8080 %h %p # %r@%n %%\n", "sh -c nc -X connect -x bastion:8080 gitlab.example.com 22 # git@work %"},
+		{"proxy jump", "user git\nproxyjump bastion\n", "ssh -W gitlab.example.com:22 bastion"},
+		{"proxy jump chain", "user git\nproxyjump outer,inner\n", "ssh -W gitlab.example.com:22 -J outer inner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := sshProxyCommand(tt.config, "work", "gitlab.example.com", "22")
+			got := ""
+			if cmd != nil {
+				got = strings.Join(cmd.Args, " ")
+			}
+			if got != tt.want {
+				t.Errorf("sshProxyCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpstreamProxy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "http.proxy")
+	t.Setenv("GIT_CONFIG_VALUE_0", "proxy.example.com:3128")
+
+	upstream, err := upstreamProxy()
+	if err != nil {
+		t.Fatalf("upstreamProxy failed: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://gitlab.example.com/team/api.git/info/refs", nil)
+	proxyURL, err := upstream(req)
+	if err != nil || proxyURL == nil || proxyURL.String() != "http://proxy.example.com:3128" {
+		t.Errorf("Expected git's http.proxy to be used, got %v (%v)", proxyURL, err)
+	}
+}
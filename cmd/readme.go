@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"io"
+
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/tui"
+	"gitstuff/internal/verbosity"
+)
+
+// readmeJobs is how many READMEs are fetched at a time for list output
+const readmeJobs = 8
+
+// readmeFetchers maps repositories to the clients that can fetch their
+// READMEs, leaving out those whose provider cannot
+type readmeFetchers map[*scm.Repository]scm.ReadmeFetcher
+
+func (f readmeFetchers) add(client scm.Client, repos []*scm.Repository) {
+	fetcher, ok := scm.Unwrap(client).(scm.ReadmeFetcher)
+	if !ok {
+		return
+	}
+	for _, repo := range repos {
+		f[repo] = fetcher
+	}
+}
+
+// preview returns the first lines of repo's README, or nothing when it has
+// none or its provider cannot fetch it
+func (f readmeFetchers) preview(ctx context.Context, repo *scm.Repository) ([]string, error) {
+	fetcher, ok := f[repo]
+	if !ok {
+		return nil, nil
+	}
+	content, err := fetcher.FetchReadme(ctx, repo)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+	return scm.ReadmePreview(content, tui.PreviewLines), nil
+}
+
+// previews fetches the README previews of repos in parallel. Failures are
+// only logged, so those repositories are shown without a preview.
+func (f readmeFetchers) previews(ctx context.Context, repos []*scm.Repository) map[*scm.Repository][]string {
+	lines := make([][]string, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			preview, err := f.preview(ctx, repo)
+			if err != nil {
+				verbosity.WithRepo(repo.FullPath, repo.Provider).Debug("Could not fetch README: %v", err)
+			}
+			lines[i] = preview
+			return err
+		}
+	}
+	runner.New(readmeJobs).RunContext(ctx, tasks, io.Discard)
+
+	previews := make(map[*scm.Repository][]string, len(repos))
+	for i, repo := range repos {
+		if len(lines[i]) > 0 {
+			previews[repo] = lines[i]
+		}
+	}
+	return previews
+}
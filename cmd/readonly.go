@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"gitstuff/internal/config"
+)
+
+// readOnly is set by --read-only or read_only in the config file. It forbids
+// every command that clones, pulls, changes clones or provider settings, or
+// writes the config file.
+var readOnly bool
+
+// checkWritable returns an error naming action when read-only mode is on.
+// Commands call it after loadConfig, which picks up the config setting.
+func checkWritable(action string) error {
+	if !readOnly {
+		return nil
+	}
+	return fmt.Errorf("cannot %s: %w", action, config.ErrReadOnly)
+}
+
+// checkConfigWritable is checkWritable for the config commands, which run
+// without loading the whole config
+func checkConfigWritable() error {
+	if stored, err := config.ReadStored(); err == nil && stored.ReadOnly {
+		readOnly = true
+	}
+	return checkWritable("change the config file")
+}
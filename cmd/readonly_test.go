@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitstuff/internal/config"
+)
+
+func TestCheckWritable(t *testing.T) {
+	t.Cleanup(func() { readOnly = false })
+
+	readOnly = false
+	if err := checkWritable("clone repositories"); err != nil {
+		t.Errorf("Expected no error outside read-only mode, got %v", err)
+	}
+
+	readOnly = true
+	err := checkWritable("clone repositories")
+	if !errors.Is(err, config.ErrReadOnly) || err.Error() != "cannot clone repositories: read-only mode is enabled" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestReadOnlyFromConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		readOnly = false
+		config.SetReadOnly(false)
+	})
+	content := "read_only: true\nproviders:\n  - name: gitlab\n    type: gitlab\n    url: https://gitlab.com\n    token: gl-token\nlocal:\n  base_dir: " + filepath.Join(tempDir, "repos") + "\n"
+	if err := os.WriteFile(filepath.Join(tempDir, ".gitstuff.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	readOnly = false
+	if err := checkConfigWritable(); !errors.Is(err, config.ErrReadOnly) {
+		t.Errorf("Expected config changes to be refused, got %v", err)
+	}
+
+	readOnly = false
+	if _, err := loadConfig(); err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if err := runClone(cloneCmd, nil); !errors.Is(err, config.ErrReadOnly) {
+		t.Errorf("Expected clone to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"path"
+	"path/filepath"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// cloneReference returns the local repository a new clone of repo borrows
+// objects from: --reference, or with referenceForks the clone of the
+// repository it was forked from when there is one. Empty means a plain
+// clone.
+func cloneReference(cfg *config.Config, repo *scm.Repository, opts cloneOptions) string {
+	if opts.reference != "" {
+		return opts.reference
+	}
+	if !opts.referenceForks || !repo.Fork || repo.ParentFullPath == "" {
+		return ""
+	}
+	parentPath := paths.ResolveRepositoryPath(cfg, parentRepository(repo))
+	if !pathExists(filepath.Join(parentPath, ".git")) {
+		verbosity.Debug("Parent %s of fork %s is not cloned, cloning without a reference", repo.ParentFullPath, repo.FullPath)
+		return ""
+	}
+	return parentPath
+}
+
+// parentRepository returns the repository repo was forked from, which is on
+// the same provider
+func parentRepository(repo *scm.Repository) *scm.Repository {
+	return &scm.Repository{
+		Name:        path.Base(repo.ParentFullPath),
+		FullPath:    repo.ParentFullPath,
+		CloneURL:    repo.ParentCloneURL,
+		SSHCloneURL: repo.ParentSSHCloneURL,
+		Provider:    repo.Provider,
+	}
+}
+
+// forksLast moves forks after the other repositories, so parents cloned in
+// the same run can be borrowed from
+func forksLast(repos []*scm.Repository) []*scm.Repository {
+	ordered := make([]*scm.Repository, 0, len(repos))
+	var forks []*scm.Repository
+	for _, repo := range repos {
+		if repo.Fork {
+			forks = append(forks, repo)
+			continue
+		}
+		ordered = append(ordered, repo)
+	}
+	return append(ordered, forks...)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestProcessRepositories_ReferenceForks(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	fork := repos[2]
+	fork.Fork = true
+	fork.ParentFullPath = "group/clean"
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), []*scm.Repository{fork, repos[0]}, cfg, cloneOptions{jobs: 1, referenceForks: true}, &out)
+	if summary.Cloned != 1 || summary.Failed() != 0 {
+		t.Fatalf("Expected the fork to be cloned, got %+v:\n%s", summary, out.String())
+	}
+
+	parentPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	if !strings.Contains(out.String(), "🔗 Borrowing objects from "+parentPath) {
+		t.Errorf("Expected the reference to be reported, got:\n%s", out.String())
+	}
+	alternates, err := os.ReadFile(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing", ".git", "objects", "info", "alternates"))
+	if err != nil {
+		t.Fatalf("Expected the fork to borrow objects from its parent: %v", err)
+	}
+	if !strings.Contains(string(alternates), filepath.Join(parentPath, ".git", "objects")) {
+		t.Errorf("Expected alternates to point into the parent clone, got %q", alternates)
+	}
+}
+
+func TestCloneReference(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	fork := &scm.Repository{FullPath: "me/clean", Provider: "gitlab", Fork: true, ParentFullPath: "group/clean"}
+	orphan := &scm.Repository{FullPath: "me/missing", Provider: "gitlab", Fork: true, ParentFullPath: "group/missing"}
+	parentPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+
+	tests := []struct {
+		name string
+		repo *scm.Repository
+		opts cloneOptions
+		want string
+	}{
+		{"fork of a cloned parent", fork, cloneOptions{referenceForks: true}, parentPath},
+		{"disabled", fork, cloneOptions{}, ""},
+		{"parent not cloned", orphan, cloneOptions{referenceForks: true}, ""},
+		{"not a fork", repos[0], cloneOptions{referenceForks: true}, ""},
+		{"explicit reference", repos[2], cloneOptions{reference: "/src/upstream", referenceForks: true}, "/src/upstream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cloneReference(cfg, tt.repo, tt.opts); got != tt.want {
+				t.Errorf("Expected reference %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestForksLast(t *testing.T) {
+	repos := []*scm.Repository{{FullPath: "me/a", Fork: true}, {FullPath: "up/a"}, {FullPath: "me/b", Fork: true}, {FullPath: "up/b"}}
+	var got []string
+	for _, repo := range forksLast(repos) {
+		got = append(got, repo.FullPath)
+	}
+	if strings.Join(got, " ") != "up/a up/b me/a me/b" {
+		t.Errorf("Expected forks after the other repositories, got %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/verbosity"
+
+	"golang.org/x/term"
+)
+
+// stdinIsTerminal reports whether the user can be asked questions
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// repoMove is a clone left at the path of a repository's previous name
+type repoMove struct {
+	Repo    *scm.Repository
+	OldPath string // Full path of the repository when it was cloned
+	From    string
+	To      string
+}
+
+// loadState reads the repository state for cfg's base directory. A state
+// file that cannot be read is reported and replaced.
+func loadState(cfg *config.Config, w io.Writer) *state.State {
+	st, err := state.Load(cfg.Local.BaseDir)
+	if err != nil {
+		fmt.Fprintf(w, "⚠️  %s\n", i18n.T("relocate.state_unreadable", redact.Error(err)))
+	}
+	return st
+}
+
+// detectMoves finds the repositories whose clone was recorded under another
+// path and is not at the repository's current path yet
+func detectMoves(cfg *config.Config, repos []*scm.Repository, st *state.State) []repoMove {
+	var moves []repoMove
+	for _, repo := range repos {
+		entry, from, ok := st.Lookup(repo)
+		if !ok {
+			continue
+		}
+		if pathExists(paths.ResolveRepositoryPath(cfg, repo)) {
+			continue
+		}
+		status, err := git.GetRepositoryStatus(from)
+		if err != nil || !status.IsGitRepo {
+			continue
+		}
+		moves = append(moves, repoMove{Repo: repo, OldPath: entry.FullPath, From: from, To: paths.GetClonePath(cfg, repo)})
+	}
+	return moves
+}
+
+// relocateMovedRepositories offers to move the clones of renamed or
+// transferred repositories to their new paths instead of cloning them again.
+// It returns the repositories left to process: when the clones could not be
+// moved, or nobody could be asked, the moved repositories are left out so
+// they are not cloned a second time.
+func relocateMovedRepositories(cfg *config.Config, repos []*scm.Repository, opts cloneOptions, w io.Writer) []*scm.Repository {
+	moves := detectMoves(cfg, repos, opts.state)
+	if len(moves) == 0 {
+		return repos
+	}
+
+	fmt.Fprintf(w, "%s\n", i18n.T("relocate.header", len(moves)))
+	for _, move := range moves {
+		fmt.Fprintf(w, "🚚 %s → %s [%s]\n", move.OldPath, move.Repo.FullPath, move.Repo.Provider)
+	}
+
+	skip := make(map[*scm.Repository]bool)
+	if !opts.moveRenamed {
+		if !stdinIsTerminal() {
+			fmt.Fprintf(w, "⚠️  %s\n\n", i18n.T("relocate.not_asked"))
+			for _, move := range moves {
+				skip[move.Repo] = true
+			}
+			return withoutRepositories(repos, skip)
+		}
+		if !confirm(os.Stdin, w, i18n.T("relocate.confirm", len(moves))) {
+			fmt.Fprintln(w)
+			return repos
+		}
+	}
+
+	for _, move := range moves {
+		if err := moveClone(cfg, move); err != nil {
+			fmt.Fprintf(w, "❌ %s - %s\n", move.Repo.FullPath, i18n.T("relocate.failed", redact.Error(err)))
+			skip[move.Repo] = true
+			continue
+		}
+		opts.state.Record(move.Repo, move.To)
+		fmt.Fprintf(w, "✅ %s - %s\n", move.Repo.FullPath, i18n.T("relocate.moved", move.To))
+	}
+	fmt.Fprintln(w)
+	return withoutRepositories(repos, skip)
+}
+
+// moveClone moves a clone to its repository's new path and points origin at
+// the new URL, keeping the protocol it was cloned with
+func moveClone(cfg *config.Config, move repoMove) error {
+	if err := os.MkdirAll(filepath.Dir(move.To), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(move.From, move.To); err != nil {
+		return fmt.Errorf("failed to move clone: %w", err)
+	}
+	removeEmptyParents(filepath.Dir(move.From), cfg.Local.BaseDir)
+	verbosity.Debug("Moved %s to %s", move.From, move.To)
+
+	current, err := git.RemoteURL(move.To, "origin")
+	if err != nil {
+		return err
+	}
+	url := cloneURLFor(cfg, move.Repo, true)
+	if strings.HasPrefix(current, "http://") || strings.HasPrefix(current, "https://") || url == "" {
+		url = cloneURLFor(cfg, move.Repo, false)
+	}
+	if url == "" {
+		return nil
+	}
+	if _, err := git.EnsureRemote(move.To, "origin", url); err != nil {
+		return err
+	}
+	return nil
+}
+
+func withoutRepositories(repos []*scm.Repository, skip map[*scm.Repository]bool) []*scm.Repository {
+	if len(skip) == 0 {
+		return repos
+	}
+	kept := make([]*scm.Repository, 0, len(repos)-len(skip))
+	for _, repo := range repos {
+		if !skip[repo] {
+			kept = append(kept, repo)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+)
+
+// setupMovedRepository records the sync fixture's clones in a state and
+// returns the repositories as listed after clean was transferred to another
+// group and renamed
+func setupMovedRepository(t *testing.T) (*config.Config, *state.State, []*scm.Repository) {
+	t.Helper()
+	cfg, repos := setupSyncFixture(t)
+
+	st, err := state.Load(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	for i, repo := range repos[:2] {
+		repo.ID = strconv.Itoa(i + 1)
+		st.Record(repo, filepath.Join(cfg.Local.BaseDir, "gitlab", repo.FullPath))
+	}
+
+	moved := &scm.Repository{ID: "1", Name: "tidy", FullPath: "other/tidy", CloneURL: repos[0].CloneURL, Provider: "gitlab"}
+	return cfg, st, []*scm.Repository{moved, repos[1], repos[2]}
+}
+
+func TestDetectMoves(t *testing.T) {
+	cfg, st, repos := setupMovedRepository(t)
+
+	moves := detectMoves(cfg, repos, st)
+	if len(moves) != 1 {
+		t.Fatalf("Expected 1 move, got %+v", moves)
+	}
+	move := moves[0]
+	if move.OldPath != "group/clean" || move.From != filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean") ||
+		move.To != filepath.Join(cfg.Local.BaseDir, "gitlab", "other", "tidy") {
+		t.Errorf("Unexpected move %+v", move)
+	}
+
+	plan := planSync(repos, cfg, nil, false)
+	markMoves(plan, moves)
+	if plan[0].Action != syncMove || plan[0].LocalPath != move.From {
+		t.Errorf("Expected the clone to be planned as a move, got %+v", plan[0])
+	}
+}
+
+func TestRelocateMovedRepositories(t *testing.T) {
+	cfg, st, repos := setupMovedRepository(t)
+
+	var out bytes.Buffer
+	remaining := relocateMovedRepositories(cfg, repos, cloneOptions{state: st, moveRenamed: true}, &out)
+	if len(remaining) != 3 {
+		t.Fatalf("Expected every repository to remain, got %d:\n%s", len(remaining), out.String())
+	}
+
+	to := filepath.Join(cfg.Local.BaseDir, "gitlab", "other", "tidy")
+	if status, err := git.GetRepositoryStatus(to); err != nil || !status.IsGitRepo {
+		t.Fatalf("Expected the clone at %s, got %+v (%v):\n%s", to, status, err, out.String())
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")) {
+		t.Error("Expected the old path to be gone")
+	}
+	if _, path, _ := st.Lookup(repos[0]); path != to {
+		t.Errorf("Expected the state to record %s, got %s", to, path)
+	}
+	if len(detectMoves(cfg, repos, st)) != 0 {
+		t.Error("Expected no moves once the clone was moved")
+	}
+}
+
+func TestRelocateMovedRepositories_NotAsked(t *testing.T) {
+	cfg, st, repos := setupMovedRepository(t)
+
+	original := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	t.Cleanup(func() { stdinIsTerminal = original })
+
+	var out bytes.Buffer
+	remaining := relocateMovedRepositories(cfg, repos, cloneOptions{state: st}, &out)
+	if len(remaining) != 2 || remaining[0] == repos[0] {
+		t.Errorf("Expected the moved repository to be left out, got %d repositories", len(remaining))
+	}
+	if !pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")) {
+		t.Error("Expected the clone to stay where it was")
+	}
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+)
+
+// remoteRule is a config.RemoteRule ready to be applied to repositories
+type remoteRule struct {
+	name      string
+	url       *template.Template
+	include   patternSet
+	forksOnly bool
+}
+
+func compileRemoteRules(rules []config.RemoteRule) ([]remoteRule, error) {
+	compiled := make([]remoteRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Name == "" || rule.URL == "" {
+			return nil, fmt.Errorf("remote rules need a name and a url")
+		}
+		if rule.Name == "origin" {
+			return nil, fmt.Errorf("remote rule cannot replace origin")
+		}
+
+		tmpl, err := template.New(rule.Name).Option("missingkey=error").Parse(rule.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url for remote %s: %w", rule.Name, err)
+		}
+		include, err := compilePatternSet(rule.Include, nil)
+		if err != nil {
+			return nil, fmt.Errorf("remote %s: %w", rule.Name, err)
+		}
+
+		compiled = append(compiled, remoteRule{name: rule.Name, url: tmpl, include: include, forksOnly: rule.ForksOnly})
+	}
+	return compiled, nil
+}
+
+// remoteURL renders the rule's URL for repo. It returns an empty URL when the
+// rule does not apply to repo.
+func (r remoteRule) remoteURL(repo *scm.Repository) (string, error) {
+	if r.forksOnly && (!repo.Fork || repo.ParentFullPath == "") {
+		return "", nil
+	}
+	if !r.include.matches(repo.FullPath) {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := r.url.Execute(&buf, repo); err != nil {
+		return "", fmt.Errorf("failed to render url: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// applyRemoteRules adds or updates the extra remotes configured for repo.
+// Problems are reported as warnings so they never fail a clone or pull.
+func applyRemoteRules(w io.Writer, repoPath string, repo *scm.Repository, rules []remoteRule) {
+	for _, rule := range rules {
+		url, err := rule.remoteURL(repo)
+		if err == nil && url == "" {
+			continue
+		}
+
+		var change git.RemoteChange
+		if err == nil {
+			change, err = git.EnsureRemote(repoPath, rule.name, url)
+		}
+		if err != nil {
+			fmt.Fprintf(w, "⚠️  %s\n", i18n.T("remote.failed", rule.name, redact.Error(err)))
+			continue
+		}
+
+		switch change {
+		case git.RemoteAdded:
+			fmt.Fprintf(w, "🔗 %s\n", i18n.T("remote.added", rule.name, redact.String(url)))
+		case git.RemoteUpdated:
+			fmt.Fprintf(w, "🔗 %s\n", i18n.T("remote.updated", rule.name, redact.String(url)))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestCompileRemoteRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    config.RemoteRule
+		wantErr string
+	}{
+		{name: "missing url", rule: config.RemoteRule{Name: "upstream"}, wantErr: "need a name and a url"},
+		{name: "origin", rule: config.RemoteRule{Name: "origin", URL: "x"}, wantErr: "cannot replace origin"},
+		{name: "bad template", rule: config.RemoteRule{Name: "mirror", URL: "{{.FullPath"}, wantErr: "invalid url for remote mirror"},
+		{name: "bad pattern", rule: config.RemoteRule{Name: "mirror", URL: "x", Include: []string{"re:("}}, wantErr: "remote mirror"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileRemoteRules([]config.RemoteRule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRemoteRule_RemoteURL(t *testing.T) {
+	rules, err := compileRemoteRules([]config.RemoteRule{
+		{Name: "upstream", URL: "{{.ParentSSHCloneURL}}", ForksOnly: true},
+		{Name: "mirror", URL: "git@backup.example.com:{{.Provider}}/{{.FullPath}}.git", Include: []string{"team/**"}},
+	})
+	if err != nil {
+		t.Fatalf("compileRemoteRules failed: %v", err)
+	}
+	upstream, mirror := rules[0], rules[1]
+
+	fork := &scm.Repository{FullPath: "team/tool", Provider: "github", Fork: true,
+		ParentFullPath: "upstream/tool", ParentSSHCloneURL: "git@github.com:upstream/tool.git"}
+	plain := &scm.Repository{FullPath: "other/app", Provider: "gitlab"}
+
+	tests := []struct {
+		name string
+		rule remoteRule
+		repo *scm.Repository
+		want string
+	}{
+		{name: "fork upstream", rule: upstream, repo: fork, want: "git@github.com:upstream/tool.git"},
+		{name: "not a fork", rule: upstream, repo: plain, want: ""},
+		{name: "unknown parent", rule: upstream, repo: &scm.Repository{FullPath: "team/x", Fork: true}, want: ""},
+		{name: "mirror match", rule: mirror, repo: fork, want: "git@backup.example.com:github/team/tool.git"},
+		{name: "mirror no match", rule: mirror, repo: plain, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule.remoteURL(tt.repo)
+			if err != nil {
+				t.Fatalf("remoteURL failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("remoteURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	broken, _ := compileRemoteRules([]config.RemoteRule{{Name: "bad", URL: "{{.NoSuchField}}"}})
+	if _, err := broken[0].remoteURL(plain); err == nil {
+		t.Error("Expected error for unknown template field")
+	}
+}
+
+func TestProcessRepositories_AppliesRemoteRules(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	rules, err := compileRemoteRules([]config.RemoteRule{
+		{Name: "mirror", URL: "https://mirror.example.com/{{.FullPath}}.git"},
+	})
+	if err != nil {
+		t.Fatalf("compileRemoteRules failed: %v", err)
+	}
+
+	opts := cloneOptions{update: true, skipDirty: true, jobs: 1, remotes: rules}
+	var out bytes.Buffer
+	processRepositories(context.Background(), repos, cfg, opts, &out)
+
+	for _, name := range []string{"clean", "dirty", "missing"} {
+		repoPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", name)
+		url, err := exec.Command("git", "-C", repoPath, "remote", "get-url", "mirror").Output()
+		if err != nil {
+			t.Errorf("Expected mirror remote in %s: %v\n%s", name, err, out.String())
+			continue
+		}
+		if want := "https://mirror.example.com/group/" + name + ".git"; strings.TrimSpace(string(url)) != want {
+			t.Errorf("mirror remote for %s = %q, want %q", name, strings.TrimSpace(string(url)), want)
+		}
+	}
+	if !strings.Contains(out.String(), "🔗 Added remote mirror") {
+		t.Errorf("Expected remote to be reported, got:\n%s", out.String())
+	}
+
+	// A second run must not change anything
+	out.Reset()
+	processRepositories(context.Background(), repos, cfg, opts, &out)
+	if strings.Contains(out.String(), "🔗") {
+		t.Errorf("Expected remotes to be left alone on the second run, got:\n%s", out.String())
+	}
+}
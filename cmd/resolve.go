@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve <repository>",
+	Short: "Print the local path of a repository",
+	Long: `Print the path of a repository's clone and nothing else, for editor plugins
+and scripts that jump to a repository. The repository is given by its full
+path, or just its name when that is unique.
+
+Clones made by clone and sync are found in the base directory's state file
+without asking the providers. Other repositories are looked up on the
+providers; when one is not cloned, --ensure clones it first. Clone output
+goes to stderr, so stdout only ever has the path.
+
+Examples:
+  cd "$(gitstuff resolve api)"
+  code "$(gitstuff resolve backend/api --ensure)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runResolve,
+}
+
+func init() {
+	rootCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().Bool("ensure", false, "Clone the repository when it is not cloned yet")
+	resolveCmd.Flags().Bool("https", false, "Clone over HTTPS instead of SSH")
+}
+
+func runResolve(cmd *cobra.Command, args []string) error {
+	name := strings.Trim(args[0], "/")
+	ensure, _ := cmd.Flags().GetBool("ensure")
+	useHTTPS, _ := cmd.Flags().GetBool("https")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	if path, ok := resolveRecordedClone(cfg, name); ok {
+		fmt.Fprintln(stdout, path)
+		return nil
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(cmd)
+	repo, err := resolveRepositoryArg(collectRepositories(ctx, clients, "", repoFilter{includeArchived: true}), name)
+	if err != nil {
+		return err
+	}
+
+	localPath := paths.ResolveRepositoryPath(cfg, repo)
+	if !pathExists(localPath) {
+		if !ensure {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s is not cloned (use --ensure to clone it)", repo.FullPath)
+		}
+		if err := checkWritable("clone repositories"); err != nil {
+			return err
+		}
+		opts, err := configCloneOptions(cfg, git.PullOptions{}, stderr)
+		if err != nil {
+			return err
+		}
+		opts.useSSH, opts.protocolSet = !useHTTPS, useHTTPS
+		summary := processRepositories(ctx, []*scm.Repository{repo}, cfg, opts, stderr)
+		if len(summary.Failures) > 0 {
+			cmd.SilenceUsage = true
+			return summary.Failures[0].Err
+		}
+		if len(summary.Interrupted) > 0 {
+			return fmt.Errorf("clone of %s interrupted", repo.FullPath)
+		}
+		localPath = paths.ResolveRepositoryPath(cfg, repo)
+	}
+	fmt.Fprintln(stdout, localPath)
+	return nil
+}
+
+// resolveRecordedClone returns the path of the only clone recorded for name
+// in the state file, when it is still there
+func resolveRecordedClone(cfg *config.Config, name string) (string, bool) {
+	st, err := state.Load(cfg.Local.BaseDir)
+	if err != nil {
+		verbosity.Debug("Not using the state file: %v", err)
+		return "", false
+	}
+	matches := st.Find(name)
+	if len(matches) != 1 || !pathExists(matches[0]) {
+		return "", false
+	}
+	return matches[0], true
+}
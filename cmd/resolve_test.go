@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestCommand_Resolve(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	for i, repo := range repos {
+		repo.ID = string(rune('1' + i))
+		repo.WebURL = "https://gitlab.example.com/" + repo.FullPath
+	}
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}}
+
+	out, err := runCommand(t, cfg, clients, "resolve", "clean")
+	if want := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean") + "\n"; err != nil || out != want {
+		t.Errorf("Expected only the path of the clone, got %v: %q", err, out)
+	}
+
+	if _, err := runCommand(t, cfg, clients, "resolve", "group/missing"); err == nil || !strings.Contains(err.Error(), "--ensure") {
+		t.Errorf("Expected an error for a repository that is not cloned, got %v", err)
+	}
+
+	missing := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing")
+	out, err = runCommand(t, cfg, clients, "resolve", "group/missing", "--ensure", "--https")
+	if err != nil || out != missing+"\n" || !pathExists(filepath.Join(missing, ".git")) {
+		t.Errorf("Expected the repository to be cloned and only its path printed, got %v: %q", err, out)
+	}
+
+	// The clone is recorded, so it is found without the providers
+	out, err = runCommand(t, cfg, map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab"}}, "resolve", "missing", "--ensure=false")
+	if err != nil || out != missing+"\n" {
+		t.Errorf("Expected the recorded clone, got %v: %q", err, out)
+	}
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var restructureCmd = &cobra.Command{
+	Use:   "restructure [group]",
+	Short: "Move local clones to follow groups restructured on the provider",
+	Long: `Find clones whose repository now lives under another path because it, or a
+group above it, was moved or renamed on the provider, and plan moving them
+to the matching local path.
+
+Repositories are matched by provider ID, using the IDs recorded by clone and
+sync. Clones gitstuff has no record of are looked up on GitLab by their old
+path, which GitLab redirects to the project's current path.
+
+Nothing is changed without --apply. With --apply each clone is moved, its
+origin remote pointed at the new URL and emptied directories removed.
+
+Examples:
+  gitstuff restructure              # Show the planned moves
+  gitstuff restructure platform     # Only moves into or out of a group
+  gitstuff restructure --apply      # Move the clones`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestructure,
+}
+
+func init() {
+	rootCmd.AddCommand(restructureCmd)
+	restructureCmd.Flags().Bool("apply", false, "Move the clones instead of only showing the plan")
+}
+
+// restructureEntry is a planned clone move, with its outcome once applied
+type restructureEntry struct {
+	repoMove
+	Err error
+}
+
+// groupMove is a group whose repositories all moved under another path
+type groupMove struct {
+	From  string
+	To    string
+	Count int
+}
+
+func runRestructure(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	apply, _ := cmd.Flags().GetBool("apply")
+	if apply {
+		if err := checkWritable("move clones"); err != nil {
+			return err
+		}
+	}
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = strings.Trim(args[0], "/")
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(cmd)
+	repos, err := listAllRepositoriesFresh(ctx, clients)
+	if err != nil {
+		return err
+	}
+	localPaths, err := git.FindRepositories(cfg.Local.BaseDir)
+	if err != nil {
+		return err
+	}
+
+	st := loadState(cfg, stdout)
+	moves := detectMoves(cfg, repos, st)
+	moves = append(moves, lookupMoves(ctx, cfg, clients, repos, localPaths, moves)...)
+	plan := planRestructure(moves, groupPath)
+	verbosity.DebugTiming(start, "Planned %d clone moves", len(plan))
+
+	displayRestructurePlan(stdout, plan, apply)
+	if !apply || len(plan) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(stdout)
+	applyRestructure(cfg, st, plan)
+	if err := st.Save(); err != nil {
+		fmt.Fprintf(stdout, "⚠️  %s\n", i18n.T("relocate.state_unwritable", redact.Error(err)))
+	}
+	if failed := displayRestructureResult(stdout, plan); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to move %d clones", failed)
+	}
+	return nil
+}
+
+// lookupMoves asks the providers that follow redirects where the repositories
+// of orphaned clones went, for clones with no recorded ID. Clones already
+// planned to move are skipped.
+func lookupMoves(ctx context.Context, cfg *config.Config, clients []scm.Client, repos []*scm.Repository, localPaths []string, planned []repoMove) []repoMove {
+	skip := make(map[string]bool, len(planned))
+	for _, move := range planned {
+		skip[canonicalPath(move.From)] = true
+	}
+	byKey := make(map[string]*scm.Repository, len(repos))
+	for _, repo := range repos {
+		byKey[state.Key(repo)] = repo
+	}
+
+	var moves []repoMove
+	for _, orphan := range findOrphans(cfg, repos, localPaths) {
+		if skip[canonicalPath(orphan.Path)] {
+			continue
+		}
+		provider, oldPath, ok := strings.Cut(orphan.Rel, "/")
+		if !ok {
+			continue // Legacy layout, the provider is unknown
+		}
+		for _, client := range clients {
+			getter, ok := scm.Unwrap(client).(scm.RepositoryGetter)
+			if !ok || client.GetProviderType() != provider {
+				continue
+			}
+			found, err := getter.GetRepository(ctx, oldPath)
+			if err != nil {
+				verbosity.Debug("Could not look up %s on %s: %v", oldPath, provider, err)
+				continue
+			}
+			repo := byKey[state.Key(found)]
+			if repo == nil || repo.FullPath == oldPath {
+				continue
+			}
+			to := paths.GetClonePath(cfg, repo)
+			if !pathExists(to) {
+				moves = append(moves, repoMove{Repo: repo, OldPath: oldPath, From: orphan.Path, To: to})
+			}
+			break
+		}
+	}
+	return moves
+}
+
+// planRestructure keeps the moves into or out of groupPath, or all of them,
+// ordered by old path
+func planRestructure(moves []repoMove, groupPath string) []restructureEntry {
+	var plan []restructureEntry
+	for _, move := range moves {
+		if groupPath != "" && !inGroup(move.OldPath, groupPath) && !inGroup(move.Repo.FullPath, groupPath) {
+			continue
+		}
+		plan = append(plan, restructureEntry{repoMove: move})
+	}
+	sort.Slice(plan, func(i, j int) bool { return plan[i].OldPath < plan[j].OldPath })
+	return plan
+}
+
+func inGroup(fullPath, groupPath string) bool {
+	return strings.HasPrefix(fullPath, groupPath+"/")
+}
+
+// groupMoves summarizes the plan by group: the parent paths the moved
+// repositories left and the ones they went to
+func groupMoves(plan []restructureEntry) []groupMove {
+	var groups []groupMove
+	index := make(map[[2]string]int)
+	for _, entry := range plan {
+		from, to := path.Dir(entry.OldPath), path.Dir(entry.Repo.FullPath)
+		if from == to {
+			continue // Renamed within its group
+		}
+		key := [2]string{from, to}
+		if i, ok := index[key]; ok {
+			groups[i].Count++
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, groupMove{From: from, To: to, Count: 1})
+	}
+	return groups
+}
+
+func displayRestructurePlan(w io.Writer, plan []restructureEntry, applying bool) {
+	if len(plan) == 0 {
+		fmt.Fprintln(w, i18n.T("restructure.none"))
+		return
+	}
+
+	fmt.Fprintf(w, "%s\n\n", i18n.T("restructure.header", len(plan)))
+	if groups := groupMoves(plan); len(groups) > 0 {
+		for _, group := range groups {
+			fmt.Fprintf(w, "📂 %s/ → %s/ %s\n", group.From, group.To, i18n.T("restructure.group_count", group.Count))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, entry := range plan {
+		fmt.Fprintf(w, "🚚 %s → %s [%s]\n", entry.OldPath, entry.Repo.FullPath, entry.Repo.Provider)
+	}
+	if !applying {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("restructure.apply_hint"))
+	}
+}
+
+// applyRestructure moves every planned clone, recording the new paths in st
+// and failures in the plan
+func applyRestructure(cfg *config.Config, st *state.State, plan []restructureEntry) {
+	for i := range plan {
+		entry := &plan[i]
+		if pathExists(entry.To) {
+			entry.Err = fmt.Errorf("%s already exists", entry.To)
+			continue
+		}
+		if err := moveClone(cfg, entry.repoMove); err != nil {
+			entry.Err = err
+			continue
+		}
+		st.Record(entry.Repo, entry.To)
+	}
+}
+
+// displayRestructureResult reports each move and returns how many failed
+func displayRestructureResult(w io.Writer, plan []restructureEntry) int {
+	failed := 0
+	for _, entry := range plan {
+		if entry.Err != nil {
+			failed++
+			fmt.Fprintf(w, "❌ %s - %s\n", entry.OldPath, i18n.T("relocate.failed", redact.Error(entry.Err)))
+			continue
+		}
+		fmt.Fprintf(w, "✅ %s - %s\n", entry.OldPath, i18n.T("relocate.moved", entry.To))
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("restructure.summary", len(plan)-failed, failed))
+	return failed
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+)
+
+// mockGetterClient is a mockSCMClient that resolves old repository paths
+// like a provider keeping redirects
+type mockGetterClient struct {
+	mockSCMClient
+	redirects map[string]*scm.Repository
+}
+
+func (m *mockGetterClient) GetRepository(ctx context.Context, fullPath string) (*scm.Repository, error) {
+	if repo, ok := m.redirects[fullPath]; ok {
+		return repo, nil
+	}
+	return nil, fmt.Errorf("404 Not Found")
+}
+
+func TestRestructure_GroupMovedUnderParent(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	st, err := state.Load(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	var listed []*scm.Repository
+	for i, repo := range repos {
+		repo.ID = strconv.Itoa(i + 1)
+		if i < 2 {
+			st.Record(repo, filepath.Join(cfg.Local.BaseDir, "gitlab", repo.FullPath))
+		}
+		moved := *repo
+		moved.FullPath = "platform/" + repo.FullPath
+		listed = append(listed, &moved)
+	}
+
+	plan := planRestructure(detectMoves(cfg, listed, st), "platform")
+	if len(plan) != 2 || plan[0].OldPath != "group/clean" || plan[1].OldPath != "group/dirty" {
+		t.Fatalf("Expected the two clones to move, got %+v", plan)
+	}
+	if len(planRestructure(detectMoves(cfg, listed, st), "elsewhere")) != 0 {
+		t.Error("Expected no moves outside the requested group")
+	}
+	groups := groupMoves(plan)
+	if len(groups) != 1 || groups[0] != (groupMove{From: "group", To: "platform/group", Count: 2}) {
+		t.Errorf("Expected the group move to be summarized, got %+v", groups)
+	}
+
+	var out bytes.Buffer
+	displayRestructurePlan(&out, plan, false)
+	for _, want := range []string{"📂 group/ → platform/group/ (2 repositories)", "🚚 group/dirty → platform/group/dirty [gitlab]", "--apply"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the plan to contain %q, got:\n%s", want, out.String())
+		}
+	}
+
+	applyRestructure(cfg, st, plan)
+	out.Reset()
+	if failed := displayRestructureResult(&out, plan); failed != 0 {
+		t.Fatalf("Expected every move to succeed, got:\n%s", out.String())
+	}
+	for _, name := range []string{"clean", "dirty"} {
+		to := filepath.Join(cfg.Local.BaseDir, "gitlab", "platform", "group", name)
+		if status, err := git.GetRepositoryStatus(to); err != nil || !status.IsGitRepo {
+			t.Errorf("Expected the clone at %s, got %+v (%v)", to, status, err)
+		}
+	}
+	if !pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "platform", "group", "dirty", "wip.txt")) {
+		t.Error("Expected uncommitted changes to move with the clone")
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group")) {
+		t.Error("Expected the emptied group directory to be removed")
+	}
+	if len(detectMoves(cfg, listed, st)) != 0 {
+		t.Error("Expected no moves once the clones were moved")
+	}
+}
+
+func TestLookupMoves(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	moved := &scm.Repository{ID: "7", Name: "clean", FullPath: "platform/group/clean", CloneURL: repos[0].CloneURL, Provider: "gitlab"}
+	listed := []*scm.Repository{moved, {ID: "8", Name: "dirty", FullPath: "group/dirty", Provider: "gitlab"}}
+	client := &mockGetterClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: listed},
+		redirects:     map[string]*scm.Repository{"group/clean": {ID: "7", FullPath: "platform/group/clean", Provider: "gitlab"}},
+	}
+
+	localPaths, err := git.FindRepositories(cfg.Local.BaseDir)
+	if err != nil {
+		t.Fatalf("FindRepositories failed: %v", err)
+	}
+	moves := lookupMoves(context.Background(), cfg, []scm.Client{client}, listed, localPaths, nil)
+	if len(moves) != 1 {
+		t.Fatalf("Expected 1 move, got %+v", moves)
+	}
+	if moves[0].Repo != moved || moves[0].OldPath != "group/clean" ||
+		moves[0].To != filepath.Join(cfg.Local.BaseDir, "gitlab", "platform", "group", "clean") {
+		t.Errorf("Unexpected move %+v", moves[0])
+	}
+
+	if again := lookupMoves(context.Background(), cfg, []scm.Client{client}, listed, localPaths, moves); len(again) != 0 {
+		t.Errorf("Expected clones already planned to be skipped, got %+v", again)
+	}
+}
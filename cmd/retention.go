@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// retentionRules are the checked retention settings of the config file
+type retentionRules struct {
+	archivedAfter time.Duration
+	keep          []config.KeepRule
+}
+
+func compileRetention(cfg config.RetentionConfig) (retentionRules, error) {
+	archivedAfter, err := parseAge(cfg.ArchivedAfter)
+	if err != nil {
+		return retentionRules{}, fmt.Errorf("invalid retention.archived_after: %w", err)
+	}
+	for _, rule := range cfg.Keep {
+		if rule.Path == "" {
+			return retentionRules{}, fmt.Errorf("retention.keep rule without a path")
+		}
+		if _, err := filepath.Match(rule.Path, ""); err != nil {
+			return retentionRules{}, fmt.Errorf("invalid retention.keep path %q: %w", rule.Path, err)
+		}
+		if rule.Last < 1 {
+			return retentionRules{}, fmt.Errorf("retention.keep rule for %q must keep at least 1", rule.Path)
+		}
+	}
+	return retentionRules{archivedAfter: archivedAfter, keep: cfg.Keep}, nil
+}
+
+func (r retentionRules) empty() bool {
+	return r.archivedAfter == 0 && len(r.keep) == 0
+}
+
+// retentionAction is a clone or backup due for removal
+type retentionAction struct {
+	Path   string
+	Repo   *scm.Repository // Whose clone it is; nil for backups
+	Reason string          // Why it is due
+
+	// Kept says why it was left alone anyway, e.g. local work in a clone
+	Kept    string
+	Removed bool
+	Err     error
+}
+
+func (a retentionAction) label() string {
+	if a.Repo != nil {
+		return fmt.Sprintf("%s [%s]", a.Repo.FullPath, a.Repo.Provider)
+	}
+	return a.Path
+}
+
+// plan returns the clones of archived repositories among repos and the
+// backups that are due for removal at now
+func (r retentionRules) plan(cfg *config.Config, repos []*scm.Repository, now time.Time) []retentionAction {
+	var actions []retentionAction
+	if r.archivedAfter > 0 {
+		for _, repo := range repos {
+			// Without a last activity there is no telling how long ago it
+			// was archived
+			if !repo.Archived || repo.LastActivity.IsZero() || now.Sub(repo.LastActivity) < r.archivedAfter {
+				continue
+			}
+			clonePath := paths.ResolveRepositoryPath(cfg, repo)
+			if !pathExists(clonePath) {
+				continue
+			}
+			action := retentionAction{Path: clonePath, Repo: repo, Reason: i18n.T("retention.archived", formatAge(now.Sub(repo.LastActivity)))}
+			status, err := git.GetDetailedStatus(clonePath)
+			if err == nil && !status.IsGitRepo {
+				verbosity.Debug("Skipping %s for retention: not a git repository", clonePath)
+				continue
+			}
+			// Commits on other branches, or on branches without an
+			// upstream, are lost with the clone too
+			unpushed := 0
+			if err == nil {
+				unpushed, err = git.UnpushedCommits(clonePath)
+			}
+			switch {
+			case err != nil:
+				action.Kept = i18n.T("retention.kept_unreadable", redact.Error(err))
+			case status.HasChanges || status.Ahead > 0 || unpushed > 0 || status.StashCount > 0:
+				action.Kept = i18n.T("retention.kept_local_work")
+			}
+			actions = append(actions, action)
+		}
+	}
+
+	for _, rule := range r.keep {
+		for _, old := range oldBackups(expandHome(rule.Path), rule.Last) {
+			actions = append(actions, retentionAction{Path: old, Reason: i18n.T("retention.old_backup", rule.Last)})
+		}
+	}
+	return actions
+}
+
+// oldBackups returns the files or directories matching pattern except the
+// newest keep of them
+func oldBackups(pattern string, keep int) []string {
+	matches, _ := filepath.Glob(pattern)
+	modified := make(map[string]time.Time, len(matches))
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			verbosity.Debug("Skipping %s for retention: %v", match, err)
+			continue
+		}
+		modified[match] = info.ModTime()
+	}
+	backups := make([]string, 0, len(modified))
+	for backup := range modified {
+		backups = append(backups, backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !modified[backups[i]].Equal(modified[backups[j]]) {
+			return modified[backups[i]].After(modified[backups[j]])
+		}
+		return backups[i] > backups[j]
+	})
+	if len(backups) <= keep {
+		return nil
+	}
+	return backups[keep:]
+}
+
+// applyRetention removes what actions are due for, except what is kept
+func applyRetention(cfg *config.Config, actions []retentionAction) {
+	for i := range actions {
+		action := &actions[i]
+		if action.Kept != "" {
+			continue
+		}
+		if err := os.RemoveAll(action.Path); err != nil {
+			action.Err = fmt.Errorf("failed to remove %s: %w", action.Path, err)
+			continue
+		}
+		action.Removed = true
+		if action.Repo != nil {
+			removeEmptyParents(filepath.Dir(action.Path), cfg.Local.BaseDir)
+		}
+	}
+}
+
+func displayRetention(w io.Writer, actions []retentionAction, dryRun bool) {
+	if len(actions) == 0 {
+		return
+	}
+	header := "retention.header"
+	if dryRun {
+		header = "retention.dry_run_header"
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T(header))
+	for _, action := range actions {
+		switch {
+		case action.Err != nil:
+			fmt.Fprintf(w, "  ❌ %s - %s\n", action.label(), i18n.T("retention.failed", redact.Error(action.Err)))
+		case action.Kept != "":
+			fmt.Fprintf(w, "  ⚠️  %s - %s, %s\n", action.label(), action.Reason, action.Kept)
+		default:
+			fmt.Fprintf(w, "  🧹 %s - %s\n", action.label(), action.Reason)
+		}
+	}
+}
+
+// removedCount returns how many of actions were removed
+func removedCount(actions []retentionAction) int {
+	removed := 0
+	for _, action := range actions {
+		if action.Removed {
+			removed++
+		}
+	}
+	return removed
+}
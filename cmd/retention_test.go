@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestCompileRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.RetentionConfig
+		wantErr string
+	}{
+		{"empty", config.RetentionConfig{}, ""},
+		{"valid", config.RetentionConfig{ArchivedAfter: "90d", Keep: []config.KeepRule{{Path: "~/backups/*.bundle", Last: 3}}}, ""},
+		{"bad age", config.RetentionConfig{ArchivedAfter: "soon"}, "archived_after"},
+		{"no path", config.RetentionConfig{Keep: []config.KeepRule{{Last: 3}}}, "without a path"},
+		{"bad pattern", config.RetentionConfig{Keep: []config.KeepRule{{Path: "[", Last: 3}}}, "invalid retention.keep path"},
+		{"keeps nothing", config.RetentionConfig{Keep: []config.KeepRule{{Path: "*.bundle"}}}, "at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileRetention(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRetention_ArchivedClones(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	now := time.Now()
+	for _, repo := range repos {
+		repo.Archived = true
+		repo.LastActivity = now.Add(-200 * 24 * time.Hour)
+	}
+	recent := &scm.Repository{Name: "recent", FullPath: "group/recent", Provider: "gitlab", Archived: true, LastActivity: now.Add(-24 * time.Hour)}
+	repos = append(repos, recent)
+
+	rules, err := compileRetention(config.RetentionConfig{ArchivedAfter: "90d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := rules.plan(cfg, repos, now)
+	if len(actions) != 2 {
+		t.Fatalf("Expected the clean and dirty clones to be due, got %+v", actions)
+	}
+	if actions[0].Repo.FullPath != "group/clean" || actions[0].Kept != "" {
+		t.Errorf("Expected group/clean to be removed, got %+v", actions[0])
+	}
+	if actions[1].Repo.FullPath != "group/dirty" || actions[1].Kept == "" {
+		t.Errorf("Expected group/dirty to be kept for its local changes, got %+v", actions[1])
+	}
+
+	applyRetention(cfg, actions)
+	if removedCount(actions) != 1 {
+		t.Errorf("Expected 1 removal, got %d", removedCount(actions))
+	}
+	if pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")) {
+		t.Error("Expected the clean clone to be removed")
+	}
+	if !pathExists(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")) {
+		t.Error("Expected the dirty clone to be kept")
+	}
+}
+
+func TestRetention_KeepsUnpushedBranchesAndPlainDirectories(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	now := time.Now()
+	clean := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+	for _, args := range [][]string{
+		// The only unpushed commit is on a branch that is not checked out
+		{"-C", clean, "checkout", "-q", "-b", "local-only"},
+		{"-C", clean, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "Local work"},
+		{"-C", clean, "checkout", "-q", "-"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	plain := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing")
+	if err := os.MkdirAll(plain, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, repo := range repos {
+		repo.Archived = true
+		repo.LastActivity = now.Add(-200 * 24 * time.Hour)
+	}
+
+	rules, err := compileRetention(config.RetentionConfig{ArchivedAfter: "90d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := rules.plan(cfg, []*scm.Repository{repos[0], repos[2]}, now)
+	if len(actions) != 1 || actions[0].Repo.FullPath != "group/clean" || actions[0].Kept == "" {
+		t.Fatalf("Expected only group/clean to be due and kept for its unpushed branch, got %+v", actions)
+	}
+
+	applyRetention(cfg, actions)
+	if !pathExists(clean) || !pathExists(plain) {
+		t.Error("Expected the clone with an unpushed branch and the plain directory to be kept")
+	}
+}
+
+func TestRetention_KeepLastBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var names []string
+	for i, name := range []string{"a.bundle", "b.bundle", "c.bundle", "d.bundle"} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modified := now.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, file)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := compileRetention(config.RetentionConfig{Keep: []config.KeepRule{{Path: filepath.Join(dir, "*.bundle"), Last: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := rules.plan(&config.Config{}, nil, now)
+	var due []string
+	for _, action := range actions {
+		due = append(due, action.Path)
+	}
+	if want := []string{names[1], names[0]}; !slices.Equal(due, want) {
+		t.Fatalf("Expected %q to be due, got %q", want, due)
+	}
+
+	applyRetention(&config.Config{}, actions)
+	remaining, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(remaining) != 3 {
+		t.Errorf("Expected the 2 newest bundles and the notes to remain, got %q", remaining)
+	}
+}
+
+func TestCommand_SyncRetention(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	cfg.Retention = config.RetentionConfig{ArchivedAfter: "30d"}
+	repos[0].Archived = true
+	repos[0].LastActivity = time.Now().Add(-60 * 24 * time.Hour)
+	clonePath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "clean")
+
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--dry-run")
+	if err != nil {
+		t.Fatalf("sync --dry-run failed: %v", err)
+	}
+	if !strings.Contains(out, "🧹 group/clean [gitlab]") {
+		t.Errorf("Expected the dry run to list the archived clone, got:\n%s", out)
+	}
+	if !pathExists(clonePath) {
+		t.Fatal("Expected the dry run to leave the clone alone")
+	}
+	resetFlags(rootCmd)
+
+	out, err = runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--https")
+	if err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if !strings.Contains(out, "Removed by retention: 1") {
+		t.Errorf("Expected the summary to count the removal, got:\n%s", out)
+	}
+	if pathExists(clonePath) {
+		t.Error("Expected the archived clone to be removed")
+	}
+}
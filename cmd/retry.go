@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+// retryPolicy is how often clones, fetches and pulls that fail for a
+// transient reason or time out are tried again, and how long to wait before
+// the first retry. Each further retry waits twice as long as the one before.
+type retryPolicy struct {
+	retries int
+	delay   time.Duration
+}
+
+func addRetryFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("retries", 0, "Retry clones and pulls that fail on a network error this many times (default: git.retries)")
+	cmd.Flags().Duration("retry-delay", config.DefaultRetryDelay, "Wait this long before the first retry, doubling it for each next one (default: git.retry_delay)")
+}
+
+// retryPolicyFromFlags returns the retry policy from --retries and
+// --retry-delay, or else from the configuration
+func retryPolicyFromFlags(cmd *cobra.Command, cfg *config.Config) (retryPolicy, error) {
+	policy, err := configRetryPolicy(cfg)
+	if err != nil {
+		return retryPolicy{}, err
+	}
+	if cmd.Flags().Changed("retries") {
+		policy.retries, _ = cmd.Flags().GetInt("retries")
+	}
+	if cmd.Flags().Changed("retry-delay") {
+		policy.delay, _ = cmd.Flags().GetDuration("retry-delay")
+	}
+	if policy.retries < 0 {
+		return retryPolicy{}, fmt.Errorf("--retries must not be negative")
+	}
+	if policy.delay < 0 {
+		return retryPolicy{}, fmt.Errorf("--retry-delay must not be negative")
+	}
+	return policy, nil
+}
+
+// configRetryPolicy returns the retry policy set by git.retries and
+// git.retry_delay
+func configRetryPolicy(cfg *config.Config) (retryPolicy, error) {
+	delay, err := cfg.GitRetryDelay()
+	if err != nil {
+		return retryPolicy{}, err
+	}
+	if cfg.Git.Retries < 0 {
+		return retryPolicy{}, fmt.Errorf("invalid git.retries %d: must not be negative", cfg.Git.Retries)
+	}
+	return retryPolicy{retries: cfg.Git.Retries, delay: delay}, nil
+}
+
+// run calls op until it succeeds, fails for a reason retrying cannot fix, or
+// the retries are used up, and returns its last error. op writes git's error
+// output to the writer it is given, which tells transient failures apart.
+// Retries are announced on w and counted in retried, when it is not nil.
+// Waiting for the next attempt stops once ctx is done.
+func (p retryPolicy) run(ctx context.Context, w, stderr io.Writer, retried *int, op func(stderr io.Writer) error) error {
+	delay := p.delay
+	for attempt := 1; ; attempt++ {
+		var output bytes.Buffer
+		err := op(io.MultiWriter(stderr, &output))
+		if err == nil || attempt > p.retries || !retryable(err, output.String()) {
+			return err
+		}
+
+		fmt.Fprintf(w, "🔁 %s\n", i18n.T("clone.retrying", attempt, p.retries+1, delay))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		if retried != nil {
+			*retried++
+		}
+		delay *= 2
+	}
+}
+
+// retryable reports whether a failed git operation is worth trying again:
+// it timed out or its output shows a network problem
+func retryable(err error, output string) bool {
+	return errors.Is(err, git.ErrTimeout) || git.IsTransientError(output+err.Error())
+}
+
+// repoRetries is a repository that needed more than one attempt
+type repoRetries struct {
+	Repo     *scm.Repository
+	Attempts int
+}
+
+// displayRetried lists the repositories that were retried with their number
+// of attempts
+func displayRetried(w io.Writer, retried []repoRetries) {
+	if len(retried) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("sync.retried_header"))
+	for _, retry := range retried {
+		fmt.Fprintf(w, "  - %s [%s]: %s\n", retry.Repo.FullPath, retry.Repo.Provider, i18n.T("sync.retried_attempts", retry.Attempts))
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+func TestRetryPolicy_Run(t *testing.T) {
+	failing := func(output string, failures int) (func(stderr io.Writer) error, *int) {
+		calls := 0
+		return func(stderr io.Writer) error {
+			calls++
+			if calls > failures {
+				return nil
+			}
+			fmt.Fprintln(stderr, output)
+			return errors.New("exit status 128")
+		}, &calls
+	}
+
+	tests := []struct {
+		name        string
+		output      string
+		failures    int
+		wantCalls   int
+		wantRetried int
+		wantErr     bool
+	}{
+		{"succeeds after a dropped connection", "fatal: the remote end hung up unexpectedly", 2, 3, 2, false},
+		{"gives up after the retries", "ssh: connect to host example.com port 22: Connection timed out", 5, 3, 2, true},
+		{"does not retry other failures", "remote: Repository not found.", 1, 1, 0, true},
+	}
+	if !retryable(fmt.Errorf("failed to clone repository: %w after 10m0s", git.ErrTimeout), "") {
+		t.Error("Expected a timed out operation to be retried")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, calls := failing(tt.output, tt.failures)
+			var out bytes.Buffer
+			retried := 0
+			err := retryPolicy{retries: 2}.run(context.Background(), &out, io.Discard, &retried, op)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if *calls != tt.wantCalls || retried != tt.wantRetried {
+				t.Errorf("Expected %d calls and %d retries, got %d and %d", tt.wantCalls, tt.wantRetried, *calls, retried)
+			}
+			if tt.wantRetried > 0 && !strings.Contains(out.String(), "Attempt 1 of 3 failed, retrying in 0s") {
+				t.Errorf("Expected the retry to be announced, got:\n%s", out.String())
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	op, calls := failing("fatal: early EOF", 5)
+	if err := (retryPolicy{retries: 3, delay: time.Hour}).run(ctx, io.Discard, io.Discard, nil, op); err == nil || *calls != 1 {
+		t.Errorf("Expected a done context to stop waiting for a retry, got %v after %d calls", err, *calls)
+	}
+}
+
+func TestProcessRepositories_Retries(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+	repos := []*scm.Repository{
+		// Nothing listens on port 1, so the connection is refused
+		{Name: "offline", FullPath: "group/offline", CloneURL: "http://127.0.0.1:1/group/offline.git", Provider: "gitlab"},
+		{Name: "missing", FullPath: "group/missing", CloneURL: filepath.Join(tempDir, "missing.git"), Provider: "gitlab"},
+	}
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{jobs: 1, retry: retryPolicy{retries: 2}}, &out)
+	if summary.Failed() != 2 {
+		t.Fatalf("Expected both clones to fail, got %+v", summary)
+	}
+	if len(summary.Retried) != 1 || summary.Retried[0].Repo != repos[0] || summary.Retried[0].Attempts != 3 {
+		t.Errorf("Expected only the unreachable repository to be tried three times, got %+v", summary.Retried)
+	}
+
+	out.Reset()
+	displayCloneSummary(&out, summary)
+	if !strings.Contains(out.String(), "Retried after transient failures:\n  - group/offline [gitlab]: 3 attempts\n") {
+		t.Errorf("Expected the summary to list the attempts, got:\n%s", out.String())
+	}
+}
@@ -1,58 +1,178 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"time"
 
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/output"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/theme"
+	"gitstuff/internal/timing"
 	"gitstuff/internal/verbosity"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+)
+
+// stdout and stderr are where commands write. Both serialize writes, so
+// parallel workers can share them, and tests replace them to capture output.
+var (
+	stdout = output.Stdout
+	stderr = output.Stderr
+)
+
+// configLoader and newClientFactory are how commands get their config and
+// provider clients. Tests replace them to run whole commands without a
+// config file under $HOME or real providers.
+var (
+	configLoader     = config.Load
+	newClientFactory = clientFactory
 )
 
 var cfgFile string
+var profileFlag string
 var verboseCount int
+var noCache bool
+var refreshCache bool
+var language string
+var gitBackend string
+var gitTimeout time.Duration
+var logFormat string
+var logTimestamps bool
 
 var rootCmd = &cobra.Command{
-	Use:   "gitstuff",
-	Short: "A CLI tool for managing GitLab repositories",
+	Use:           "gitstuff",
+	SilenceErrors: true,
+	Short:         "A CLI tool for managing GitLab repositories",
 	Long: `GitStuff is a command-line tool for managing your GitLab repositories.
 It allows you to list repositories, clone them individually or all at once,
 and check their status including current branch information.`,
 }
 
 func Execute() {
-	err := rootCmd.Execute()
+	timing.Start()
+	registerFlagCompletions(rootCmd)
+	registerArgCompletions(rootCmd)
+	ctx, stop := notifyInterrupt(stderr)
+	err := rootCmd.ExecuteContext(ctx)
+	interrupted := stop()
+	if verbosity.IsEnabled(verbosity.InfoLevel) {
+		verbosity.Info("Timing: %s", timing.Snapshot())
+	}
 	if err != nil {
-		os.Exit(1)
+		fmt.Fprintln(stderr, "Error:", redact.String(err.Error()))
+		os.Exit(exitCode(err))
+	}
+	if interrupted {
+		os.Exit(130)
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gitstuff.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $GITSTUFF_CONFIG or $XDG_CONFIG_HOME/gitstuff/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "use the named profile, with its own providers and base directory, from $XDG_CONFIG_HOME/gitstuff/profiles/<name>.yaml (default is $GITSTUFF_PROFILE)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "bypass the on-disk HTTP response and repository metadata caches")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "re-fetch repository metadata from providers and update the cache")
+	rootCmd.PersistentFlags().StringVar(&language, "lang", "", "output language (en, es); defaults to GITSTUFF_LANG or the system locale")
+	rootCmd.PersistentFlags().StringVar(&gitBackend, "git-backend", "", "how local repository status is read: go-git (default) or exec")
+	rootCmd.PersistentFlags().DurationVar(&gitTimeout, "timeout", 0, "kill a git clone, pull, fetch or push running longer than this, e.g. 10m (default: git.timeout, no limit)")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "refuse to clone, pull, remove or otherwise change anything (default: read_only in the config)")
 	rootCmd.PersistentFlags().CountVarP(&verboseCount, "verbose", "v", "verbose output (use -v, -vv, -vvv for increasing levels)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "format of log messages: text or json (one object per line on stderr)")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "print every repository's output instead of a progress bar when cloning or syncing on a terminal")
+	rootCmd.PersistentFlags().BoolVar(&logTimestamps, "log-timestamps", false, "prefix text log messages with the time")
 
 	cobra.OnInitialize(func() {
 		verbosity.SetFromCount(verboseCount)
+		cobra.CheckErr(verbosity.SetFormat(logFormat))
+		verbosity.SetTimestamps(logTimestamps)
+		i18n.SetLocale(i18n.Detect(language))
+		config.SetReadOnly(readOnly)
 	})
 }
 
+// initConfig points the config package at the file given with --config or
+// the profile given with --profile, if any, for every command that loads or
+// writes the configuration
 func initConfig() {
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
+	config.SetFilePath(cfgFile)
+	config.SetProfile(profileFlag)
+}
 
-		viper.AddConfigPath(home)
-		viper.AddConfigPath(".")
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".gitstuff")
+// loadConfig loads the configuration file and applies the settings that are
+// not tied to a single command
+func loadConfig() (*config.Config, error) {
+	cfg, err := configLoader()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ReadOnly {
+		readOnly = true
+		config.SetReadOnly(true)
+	}
+	if err := applyGitBackend(cfg.Git.StatusBackend); err != nil {
+		return nil, err
 	}
+	if err := applyGitTimeout(cfg); err != nil {
+		return nil, err
+	}
+	if err := applyTheme(cfg.Theme); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	viper.AutomaticEnv()
+// applyTheme selects the status symbols configured in the theme section
+func applyTheme(cfg config.ThemeConfig) error {
+	err := theme.Set(cfg.Name, theme.Symbols{
+		Cloned:  cfg.Cloned,
+		Dirty:   cfg.Dirty,
+		Missing: cfg.Missing,
+		Warning: cfg.Warning,
+		Error:   cfg.Error,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid theme.name: %w", err)
+	}
+	return nil
+}
+
+// applyGitTimeout sets the timeout of git network operations, preferring
+// the --timeout flag over the configured value
+func applyGitTimeout(cfg *config.Config) error {
+	timeout, err := cfg.GitTimeout()
+	if err != nil {
+		return err
+	}
+	if gitTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	if gitTimeout > 0 {
+		timeout = gitTimeout
+	}
+	git.SetOperationTimeout(timeout)
+	if timeout > 0 {
+		verbosity.Debug("Git operations time out after %s", timeout)
+	}
+	return nil
+}
 
-	if err := viper.ReadInConfig(); err == nil {
-		verbosity.Debug("Using config file: %s", viper.ConfigFileUsed())
+// applyGitBackend selects the status backend, preferring the --git-backend
+// flag over the configured value
+func applyGitBackend(configured string) error {
+	backend := configured
+	if gitBackend != "" {
+		backend = gitBackend
+	}
+	if backend != "" {
+		if err := git.SetStatusBackend(backend); err != nil {
+			return err
+		}
 	}
+	verbosity.Debug("Using %s status backend", git.GetStatusBackend())
+	return nil
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/theme"
+)
+
+func TestApplyGitBackend(t *testing.T) {
+	t.Cleanup(func() {
+		gitBackend = ""
+		_ = git.SetStatusBackend(string(git.BackendGoGit))
+	})
+
+	tests := []struct {
+		name       string
+		flag       string
+		configured string
+		want       git.StatusBackend
+		wantErr    bool
+	}{
+		{name: "default", want: git.BackendGoGit},
+		{name: "from config", configured: "exec", want: git.BackendExec},
+		{name: "flag overrides config", flag: "go-git", configured: "exec", want: git.BackendGoGit},
+		{name: "invalid", configured: "svn", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = git.SetStatusBackend(string(git.BackendGoGit))
+			gitBackend = tt.flag
+
+			err := applyGitBackend(tt.configured)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyGitBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && git.GetStatusBackend() != tt.want {
+				t.Errorf("Expected backend %q, got %q", tt.want, git.GetStatusBackend())
+			}
+		})
+	}
+}
+
+func TestApplyGitTimeout(t *testing.T) {
+	t.Cleanup(func() {
+		gitTimeout = 0
+		git.SetOperationTimeout(0)
+	})
+
+	tests := []struct {
+		name       string
+		flag       time.Duration
+		configured string
+		want       time.Duration
+		wantErr    bool
+	}{
+		{name: "default", want: 0},
+		{name: "from config", configured: "10m", want: 10 * time.Minute},
+		{name: "flag overrides config", flag: time.Minute, configured: "10m", want: time.Minute},
+		{name: "invalid", configured: "soon", wantErr: true},
+		{name: "negative flag", flag: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git.SetOperationTimeout(0)
+			gitTimeout = tt.flag
+
+			err := applyGitTimeout(&config.Config{Git: config.GitConfig{Timeout: tt.configured}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyGitTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && git.OperationTimeout() != tt.want {
+				t.Errorf("Expected timeout %s, got %s", tt.want, git.OperationTimeout())
+			}
+		})
+	}
+}
+
+func TestCommand_ListTheme(t *testing.T) {
+	t.Cleanup(func() { _ = theme.Set(theme.DefaultName, theme.Symbols{}) })
+	cfg := testConfig(t.TempDir(), "work")
+	cfg.Theme = config.ThemeConfig{Name: "ascii", Missing: "MISSING"}
+	client := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{{Name: "api", FullPath: "team/api", Provider: "gitlab"}}}
+
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "list")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if !strings.Contains(out, "MISSING Not cloned") || strings.Contains(out, "❌") {
+		t.Errorf("Expected the configured missing symbol, got:\n%s", out)
+	}
+
+	cfg.Theme = config.ThemeConfig{Name: "neon"}
+	if _, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "list"); err == nil || !strings.Contains(err.Error(), `unknown theme "neon"`) {
+		t.Errorf("Expected an unknown theme error, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/secrets"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan cloned repositories",
+}
+
+var scanSecretsCmd = &cobra.Command{
+	Use:   "secrets [path]",
+	Short: "Sweep cloned repositories for committed secrets",
+	Long: `Scan every repository under the base directory (or the given path) for
+secrets such as API tokens and private keys, and report the findings of all
+repositories together.
+
+The built-in engine matches well-known token formats against the tracked
+files of each working tree. When gitleaks is installed it is used instead
+(--engine auto, the default), which also scans the history of each
+repository. Secrets are never printed in full.
+
+The command exits with a non-zero status when anything is found, so it can
+be run on a schedule.
+
+Examples:
+  gitstuff scan secrets                    # Scan the configured base directory
+  gitstuff scan secrets --engine builtin   # Do not use gitleaks
+  gitstuff scan secrets --json > report.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runScanSecrets,
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.AddCommand(scanSecretsCmd)
+	scanSecretsCmd.Flags().String("engine", "auto", "Detection engine: auto, builtin or gitleaks")
+	scanSecretsCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to scan in parallel")
+	scanSecretsCmd.Flags().Bool("json", false, "Output the findings as JSON")
+}
+
+type secretScanResult struct {
+	Repository string            `json:"repository"`
+	Findings   []secrets.Finding `json:"findings"`
+	Error      string            `json:"error,omitempty"`
+}
+
+func runScanSecrets(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	engine, _ := cmd.Flags().GetString("engine")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	scanner, err := secretScanner(engine)
+	if err != nil {
+		return err
+	}
+
+	var root string
+	if len(args) == 1 {
+		root = args[0]
+	} else {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first or pass a path)", err)
+		}
+		root = cfg.Local.BaseDir
+	}
+
+	verbosity.Debug("Scanning %s for git repositories", root)
+	repoPaths, err := git.FindRepositories(root)
+	if err != nil {
+		return err
+	}
+	verbosity.Info("Scanning %d repositories with %s", len(repoPaths), scanner.Name())
+
+	results := scanRepositories(root, repoPaths, scanner, jobs)
+	verbosity.DebugTiming(start, "Secret scan completed")
+
+	if asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return fmt.Errorf("failed to encode findings: %w", err)
+		}
+	} else {
+		displaySecretFindings(stdout, scanner.Name(), results)
+	}
+
+	findings, failed := 0, 0
+	for _, result := range results {
+		findings += len(result.Findings)
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if findings > 0 || failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d possible secrets found, %d repositories could not be scanned", findings, failed)
+	}
+	return nil
+}
+
+// secretScanner returns the scanner for engine; "auto" prefers gitleaks
+func secretScanner(engine string) (secrets.Scanner, error) {
+	switch engine {
+	case "builtin":
+		return secrets.NewBuiltinScanner(), nil
+	case "gitleaks":
+		scanner, ok := secrets.FindGitleaks()
+		if !ok {
+			return nil, fmt.Errorf("gitleaks not found in PATH (install it or use --engine builtin)")
+		}
+		return scanner, nil
+	case "auto":
+		if scanner, ok := secrets.FindGitleaks(); ok {
+			return scanner, nil
+		}
+		return secrets.NewBuiltinScanner(), nil
+	}
+	return nil, fmt.Errorf("unknown engine %q (expected auto, builtin or gitleaks)", engine)
+}
+
+func scanRepositories(root string, repoPaths []string, scanner secrets.Scanner, jobs int) []secretScanResult {
+	results := make([]secretScanResult, len(repoPaths))
+	tasks := make([]runner.Task, len(repoPaths))
+	for i, repoPath := range repoPaths {
+		name := repoPath
+		if rel, err := filepath.Rel(root, repoPath); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		tasks[i] = func(w io.Writer) error {
+			findings, err := scanner.Scan(repoPath)
+			results[i] = secretScanResult{Repository: name, Findings: findings}
+			if err != nil {
+				results[i].Error = redact.Error(err).Error()
+			}
+			return err
+		}
+	}
+
+	runner.New(jobs).Run(tasks, io.Discard)
+	sort.Slice(results, func(i, j int) bool { return results[i].Repository < results[j].Repository })
+	return results
+}
+
+// displaySecretFindings lists the findings of each repository followed by a
+// count per rule
+func displaySecretFindings(w io.Writer, engine string, results []secretScanResult) {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("scan.header", len(results), engine))
+
+	ruleCounts := make(map[string]int)
+	affected, total := 0, 0
+	for _, result := range results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "❌ %s - %s\n", result.Repository, i18n.T("scan.failed", result.Error))
+			continue
+		}
+		if len(result.Findings) == 0 {
+			continue
+		}
+
+		affected++
+		total += len(result.Findings)
+		fmt.Fprintf(w, "🔑 %s - %s\n", result.Repository, i18n.T("scan.found", len(result.Findings)))
+		for _, finding := range result.Findings {
+			ruleCounts[finding.Rule]++
+			location := fmt.Sprintf("%s:%d", finding.File, finding.Line)
+			if finding.Commit != "" {
+				location += fmt.Sprintf(" (%.8s)", finding.Commit)
+			}
+			fmt.Fprintf(w, "   %s  %s  %s\n", finding.Rule, location, finding.Match)
+		}
+	}
+
+	if total == 0 {
+		fmt.Fprintln(w, i18n.T("scan.clean"))
+		return
+	}
+
+	rules := make([]string, 0, len(ruleCounts))
+	for rule := range ruleCounts {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	fmt.Fprintf(w, "\n%s\n", i18n.T("scan.summary", total, affected, len(results)))
+	for _, rule := range rules {
+		fmt.Fprintf(w, "   %-28s %d\n", rule, ruleCounts[rule])
+	}
+}
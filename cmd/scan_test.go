@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/secrets"
+)
+
+type stubScanner struct {
+	findings map[string][]secrets.Finding
+	errs     map[string]error
+}
+
+func (s *stubScanner) Name() string { return "stub" }
+
+func (s *stubScanner) Scan(repoPath string) ([]secrets.Finding, error) {
+	for suffix, err := range s.errs {
+		if strings.HasSuffix(repoPath, suffix) {
+			return nil, err
+		}
+	}
+	for suffix, findings := range s.findings {
+		if strings.HasSuffix(repoPath, suffix) {
+			return findings, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestScanRepositories(t *testing.T) {
+	scanner := &stubScanner{
+		findings: map[string][]secrets.Finding{
+			"team/api": {
+				{Rule: "gitlab-token", File: "config.yml", Line: 3, Match: "glpa****"},
+				{Rule: "private-key", File: "deploy/id_rsa", Line: 1, Match: "----****", Commit: "0123456789abcdef"},
+			},
+			"team/web": {{Rule: "gitlab-token", File: ".env", Line: 1, Match: "glpa****"}},
+		},
+		errs: map[string]error{"team/broken": errors.New("not a git repository")},
+	}
+	repoPaths := []string{"/src/team/web", "/src/team/clean", "/src/team/api", "/src/team/broken"}
+
+	results := scanRepositories("/src", repoPaths, scanner, 2)
+	if results[0].Repository != "team/api" || results[3].Repository != "team/web" {
+		t.Errorf("Expected results sorted by repository, got %+v", results)
+	}
+	if results[1].Error != "not a git repository" {
+		t.Errorf("Expected scan error for team/broken, got %+v", results[1])
+	}
+
+	var buf bytes.Buffer
+	displaySecretFindings(&buf, "stub", results)
+	output := buf.String()
+
+	for _, want := range []string{
+		"Scanning 4 repositories for secrets with stub",
+		"🔑 team/api - 2 possible secrets",
+		"private-key  deploy/id_rsa:1 (01234567)  ----****",
+		"❌ team/broken - scan failed (not a git repository)",
+		"3 possible secrets in 2 of 4 repositories",
+		"gitlab-token                 2",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "team/clean") {
+		t.Errorf("Expected clean repositories to be omitted, got:\n%s", output)
+	}
+}
+
+func TestSecretScanner(t *testing.T) {
+	scanner, err := secretScanner("builtin")
+	if err != nil || scanner.Name() != "builtin" {
+		t.Errorf("Expected builtin scanner, got %v (%v)", scanner, err)
+	}
+	if _, err := secretScanner("trufflehog"); err == nil {
+		t.Error("Expected error for unknown engine")
+	}
+	if scanner, err := secretScanner("auto"); err != nil || scanner == nil {
+		t.Errorf("Expected auto to pick a scanner, got %v (%v)", scanner, err)
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gitstuff/internal/cache"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search repositories by name and description across providers",
+	Long: `Search every provider for repositories whose name, path or description
+contains the query, and show which of them are already cloned.
+
+Providers are asked through their search APIs, which also find repositories
+you are not a member of, such as public ones. Repositories in the metadata
+cache are searched as well, so your own matches are listed first and still
+show up when a provider cannot be reached. With --cached only the cache is
+searched and no requests are made.
+
+Examples:
+  gitstuff search billing                # Search all providers
+  gitstuff search "payment gateway" -n 5 # At most 5 matches per provider
+  gitstuff search billing --cached       # Search the cached listings offline
+  gitstuff search billing --clone        # Clone the matches that are not cloned yet`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntP("limit", "n", 20, "Maximum number of matches per provider")
+	searchCmd.Flags().Bool("cached", false, "Only search the metadata cache, without contacting the providers")
+	searchCmd.Flags().Bool("clone", false, "Clone the matches that are not cloned yet")
+	searchCmd.Flags().Bool("https", false, "Use HTTPS instead of SSH when cloning")
+	searchCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to clone in parallel")
+	addRepoFilterFlags(searchCmd)
+	addLimitRateFlag(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	query := strings.TrimSpace(args[0])
+	if query == "" {
+		return fmt.Errorf("the search query is empty")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	cloneMatches, _ := cmd.Flags().GetBool("clone")
+	if cloneMatches {
+		if err := checkWritable("clone repositories"); err != nil {
+			return err
+		}
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 1 {
+		return fmt.Errorf("--limit must be at least 1")
+	}
+	cachedOnly, _ := cmd.Flags().GetBool("cached")
+
+	ctx := commandContext(cmd)
+	repos := searchRepositories(ctx, clients, query, limit, cachedOnly, filter, stdout)
+	verbosity.DebugTiming(start, "Search completed")
+
+	cloned := make(map[*scm.Repository]bool)
+	for _, repo := range clonedRepositories(cfg, repos) {
+		cloned[repo] = true
+	}
+	displaySearchResults(stdout, query, repos, cloned)
+	if !cloneMatches || len(repos) == 0 {
+		return nil
+	}
+
+	var missing []*scm.Repository
+	for _, repo := range repos {
+		if !cloned[repo] {
+			missing = append(missing, repo)
+		}
+	}
+	if len(missing) == 0 {
+		fmt.Fprintln(stdout, i18n.T("search.all_cloned"))
+		return nil
+	}
+
+	stopLimit, err := startTransferLimit(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopLimit()
+
+	useHTTPS, _ := cmd.Flags().GetBool("https")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	remotes, err := compileRemoteRules(cfg.Remotes)
+	if err != nil {
+		return err
+	}
+	setup, err := compileSetupRules(cfg.Setup)
+	if err != nil {
+		return err
+	}
+	managed, err := loadManagedInfo(cfg)
+	if err != nil {
+		return err
+	}
+	opts := cloneOptions{
+		useSSH:           !useHTTPS,
+		protocolSet:      useHTTPS,
+		jobs:             jobs,
+		filter:           filter,
+		remotes:          remotes,
+		state:            loadState(cfg, stdout),
+		protocolFallback: cfg.Git.ProtocolFallback,
+		setup:            setup,
+		maintenance:      cfg.Git.Maintenance,
+		managed:          managed,
+		referenceForks:   cfg.Git.ReferenceForks,
+	}
+
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("search.cloning", len(missing)))
+	summary := processRepositories(ctx, missing, cfg, opts, stdout)
+	displayCloneSummary(stdout, summary)
+	return nil
+}
+
+// searchRepositories returns up to limit repositories of each client
+// matching query. Matches from the client's cached listing, which are the
+// repositories the user has access to, come before those from its search
+// API. Clients that cannot search are listed and matched locally.
+func searchRepositories(ctx context.Context, clients []scm.Client, query string, limit int, cachedOnly bool, filter repoFilter, w io.Writer) []*scm.Repository {
+	var found []*scm.Repository
+	for _, client := range clients {
+		var matches []*scm.Repository
+		if cached, ok := client.(*cache.Client); ok {
+			matches = matchRepositories(cached.Cached(), query)
+		}
+
+		if !cachedOnly {
+			remote, err := searchProvider(ctx, client, query, limit)
+			if err != nil {
+				fmt.Fprintf(w, "⚠️  %s\n", i18n.T("search.provider_error", client.GetProviderType(), providerErrorText(err)))
+			}
+			seen := make(map[string]bool, len(matches))
+			for _, repo := range matches {
+				seen[repo.FullPath] = true
+			}
+			for _, repo := range remote {
+				if !seen[repo.FullPath] {
+					matches = append(matches, repo)
+				}
+			}
+		}
+
+		matches = filter.applyFor(client, matches)
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+		found = append(found, matches...)
+	}
+	return found
+}
+
+// searchProvider asks the provider's search API for query, or lists all
+// repositories and matches them when the client cannot search
+func searchProvider(ctx context.Context, client scm.Client, query string, limit int) ([]*scm.Repository, error) {
+	if searcher, ok := scm.Unwrap(client).(scm.RepositorySearcher); ok {
+		return searcher.SearchRepositories(ctx, query, limit)
+	}
+	repos, err := client.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return matchRepositories(repos, query), nil
+}
+
+// matchRepositories returns the repositories whose name, full path or
+// description contains query, ignoring case
+func matchRepositories(repos []*scm.Repository, query string) []*scm.Repository {
+	query = strings.ToLower(query)
+	var matches []*scm.Repository
+	for _, repo := range repos {
+		for _, field := range []string{repo.Name, repo.FullPath, repo.Description} {
+			if strings.Contains(strings.ToLower(field), query) {
+				matches = append(matches, repo)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func displaySearchResults(w io.Writer, query string, repos []*scm.Repository, cloned map[*scm.Repository]bool) {
+	if len(repos) == 0 {
+		fmt.Fprintln(w, i18n.T("search.none", query))
+		return
+	}
+	fmt.Fprintf(w, "%s\n\n", i18n.T("search.found", len(repos), query))
+
+	for _, repo := range repos {
+		state := "📥 " + i18n.T("search.not_cloned")
+		if cloned[repo] {
+			state = "✅ " + i18n.T("search.cloned")
+		}
+		fmt.Fprintf(w, "📁 [%s] %s  %s\n", repo.Provider, repo.FullPath, state)
+		if description, _, _ := strings.Cut(strings.TrimSpace(repo.Description), "\n"); description != "" {
+			fmt.Fprintf(w, "   %s\n", description)
+		}
+		if verbosity.IsEnabled(verbosity.InfoLevel) && repo.WebURL != "" {
+			fmt.Fprintf(w, "   %s\n", i18n.T("field.web_url", repo.WebURL))
+		}
+	}
+	fmt.Fprintln(w)
+}
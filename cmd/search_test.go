@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/cache"
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+// mockSearchClient is a client with a search API that returns results, or
+// fails with err
+type mockSearchClient struct {
+	mockSCMClient
+	results []*scm.Repository
+	err     error
+	queries []string
+}
+
+func (m *mockSearchClient) SearchRepositories(ctx context.Context, query string, limit int) ([]*scm.Repository, error) {
+	m.queries = append(m.queries, query)
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(m.results) > limit {
+		return m.results[:limit], nil
+	}
+	return m.results, nil
+}
+
+func TestMatchRepositories(t *testing.T) {
+	repos := []*scm.Repository{
+		{Name: "billing", FullPath: "team/billing"},
+		{Name: "invoices", FullPath: "finance/invoices", Description: "Billing documents"},
+		{Name: "web", FullPath: "billing-team/web"},
+		{Name: "api", FullPath: "team/api", Description: "Public API"},
+	}
+
+	var got []string
+	for _, repo := range matchRepositories(repos, "BILLING") {
+		got = append(got, repo.FullPath)
+	}
+	if want := "team/billing,finance/invoices,billing-team/web"; strings.Join(got, ",") != want {
+		t.Errorf("matchRepositories() = %v, want %s", got, want)
+	}
+}
+
+func TestSearchRepositories(t *testing.T) {
+	member := &scm.Repository{Name: "billing", FullPath: "team/billing", Provider: "github"}
+	searcher := &mockSearchClient{
+		mockSCMClient: mockSCMClient{providerType: "github", repos: []*scm.Repository{member}},
+		results: []*scm.Repository{
+			{Name: "billing-sdk", FullPath: "acme/billing-sdk", Provider: "github"},
+			{Name: "billing", FullPath: "team/billing", Provider: "github", Description: "From the search API"},
+		},
+	}
+	store := cache.NewStore(t.TempDir(), time.Hour)
+	if err := store.Save("github", []*scm.Repository{member}); err != nil {
+		t.Fatal(err)
+	}
+	lister := &mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+		{Name: "billing-ui", FullPath: "web/billing-ui", Provider: "gitlab"},
+		{Name: "api", FullPath: "web/api", Provider: "gitlab"},
+	}}
+	clients := []scm.Client{cache.Wrap(searcher, store, "github", false), lister}
+
+	tests := []struct {
+		name       string
+		limit      int
+		cachedOnly bool
+		want       string
+	}{
+		{name: "cached matches first", limit: 10, want: "team/billing,acme/billing-sdk,web/billing-ui"},
+		{name: "limit per provider", limit: 1, want: "team/billing,web/billing-ui"},
+		{name: "cached only", limit: 10, cachedOnly: true, want: "team/billing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var got []string
+			for _, repo := range searchRepositories(context.Background(), clients, "billing", tt.limit, tt.cachedOnly, repoFilter{}, &out) {
+				got = append(got, repo.FullPath)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("searchRepositories() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("provider unreachable", func(t *testing.T) {
+		failing := &mockSearchClient{mockSCMClient: mockSCMClient{providerType: "github"}, err: errors.New("connection refused")}
+		var out bytes.Buffer
+		repos := searchRepositories(context.Background(), []scm.Client{cache.Wrap(failing, store, "github", false)}, "billing", 10, false, repoFilter{}, &out)
+		if len(repos) != 1 || repos[0].FullPath != "team/billing" {
+			t.Errorf("Expected the cached match despite the error, got %v", repos)
+		}
+		if !strings.Contains(out.String(), "Could not search github provider") {
+			t.Errorf("Expected a warning, got %q", out.String())
+		}
+	})
+}
+
+func TestCommand_Search(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "hub", Type: "github", URL: "https://github.com"}},
+	}
+	cloned := &scm.Repository{Name: "billing", FullPath: "team/billing", Provider: "github", Description: "Invoices and payments\nMore details"}
+	missing := &scm.Repository{
+		Name:     "billing-sdk",
+		FullPath: "acme/billing-sdk",
+		Provider: "github",
+		CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", "billing-sdk.git")),
+	}
+	client := &mockSearchClient{mockSCMClient: mockSCMClient{providerType: "github"}, results: []*scm.Repository{cloned, missing}}
+
+	clonedPath := filepath.Join(cfg.Local.BaseDir, "github", "team", "billing")
+	if out, err := exec.Command("git", "init", "-q", clonedPath).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	out, err := runCommand(t, cfg, map[string]scm.Client{"hub": client}, "search", "billing", "--clone", "--https")
+	if err != nil {
+		t.Fatalf("search failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		`Found 2 repositories matching "billing":`,
+		"📁 [github] team/billing  ✅ cloned\n   Invoices and payments\n",
+		"📁 [github] acme/billing-sdk  📥 not cloned\n",
+		"Cloning 1 matching repositories",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Local.BaseDir, "github", "acme", "billing-sdk", ".git")); err != nil {
+		t.Errorf("Expected the match to be cloned: %v", err)
+	}
+
+	out, err = runCommand(t, cfg, map[string]scm.Client{"hub": client}, "search", "nothing-like-this", "--cached")
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if !strings.Contains(out, `No repositories match "nothing-like-this"`) || len(client.queries) != 1 {
+		t.Errorf("Expected no matches without a search request, got %d requests and:\n%s", len(client.queries), out)
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/fakeprovider"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that this build works by running against fake providers",
+	Long: `Start fake GitLab and GitHub servers on this machine and run list, clone
+and sync against them in a temporary directory.
+
+Nothing is read from your configuration and no network access is needed,
+only git. Exits with a non-zero status if any step fails, so packagers can
+run it to validate a build.
+
+Examples:
+  gitstuff selftest          # Run the checks and clean up
+  gitstuff selftest --keep   # Keep the temporary directory to inspect`,
+	Args: cobra.NoArgs,
+	RunE: runSelftest,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().Bool("keep", false, "Keep the temporary directory instead of removing it")
+}
+
+// selftestRepos are the repositories each fake provider serves
+var selftestRepos = map[string][]string{
+	"gitlab": {"team/api", "team/web"},
+	"github": {"octo/tool"},
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	keep, _ := cmd.Flags().GetBool("keep")
+
+	dir, err := os.MkdirTemp("", "gitstuff-selftest-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	if keep {
+		fmt.Fprintf(stdout, "📁 %s\n\n", i18n.T("selftest.kept", dir))
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	if !runSelftestSteps(commandContext(cmd), stdout, dir) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("selftest failed")
+	}
+	fmt.Fprintf(stdout, "\n%s\n", i18n.T("selftest.passed"))
+	return nil
+}
+
+// selftestRun holds what the selftest steps share
+type selftestRun struct {
+	dir     string
+	cfg     *config.Config
+	servers []*fakeprovider.Server
+	clients []scm.Client
+	repos   []*scm.Repository
+	opts    cloneOptions
+}
+
+// runSelftestSteps runs every step in dir, reporting each to w, and returns
+// whether all passed. A step is skipped once an earlier one failed.
+func runSelftestSteps(ctx context.Context, w io.Writer, dir string) bool {
+	run := &selftestRun{dir: dir}
+	defer func() {
+		for _, server := range run.servers {
+			server.Close()
+		}
+	}()
+
+	steps := []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{"providers", run.startProviders},
+		{"list", run.list},
+		{"clone", run.clone},
+		{"sync", run.sync},
+	}
+	for _, step := range steps {
+		if err := step.run(ctx); err != nil {
+			fmt.Fprintf(w, "❌ %s\n   %v\n", i18n.T("selftest."+step.name), err)
+			return false
+		}
+		fmt.Fprintf(w, "✅ %s\n", i18n.T("selftest."+step.name))
+	}
+	return true
+}
+
+func (r *selftestRun) startProviders(ctx context.Context) error {
+	r.cfg = &config.Config{
+		Local: config.LocalConfig{BaseDir: filepath.Join(r.dir, "repos")},
+		Cache: config.CacheConfig{Dir: filepath.Join(r.dir, "cache"), DisableHTTP: true, DisableMetadata: true},
+	}
+	for _, providerType := range []string{"gitlab", "github"} {
+		server, err := fakeprovider.New(providerType, filepath.Join(r.dir, providerType))
+		if err != nil {
+			return err
+		}
+		r.servers = append(r.servers, server)
+		for _, fullPath := range selftestRepos[providerType] {
+			if err := server.AddRepository(fullPath); err != nil {
+				return err
+			}
+		}
+		r.cfg.Providers = append(r.cfg.Providers, config.ProviderConfig{
+			Name: "fake-" + providerType, Type: providerType, URL: server.URL, Token: fakeprovider.Token,
+		})
+	}
+
+	// The real clients, never ones swapped in elsewhere, are what is tested
+	newClient := clientFactory(r.cfg)
+	for _, provider := range r.cfg.Providers {
+		client, err := newClient(provider)
+		if err != nil {
+			return err
+		}
+		if checker, ok := client.(scm.HealthChecker); ok {
+			if _, err := checker.CheckHealth(ctx); err != nil {
+				return fmt.Errorf("%s: %w", provider.Name, err)
+			}
+		}
+		r.clients = append(r.clients, client)
+	}
+	return nil
+}
+
+func (r *selftestRun) list(ctx context.Context) error {
+	want := 0
+	for i, client := range r.clients {
+		repos, err := client.ListAllRepositories(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.cfg.Providers[i].Name, err)
+		}
+		expected := selftestRepos[client.GetProviderType()]
+		if len(repos) != len(expected) {
+			return fmt.Errorf("%s: listed %d repositories, expected %d", r.cfg.Providers[i].Name, len(repos), len(expected))
+		}
+		r.repos = append(r.repos, repos...)
+		want += len(expected)
+	}
+	if len(r.repos) != want {
+		return fmt.Errorf("listed %d repositories, expected %d", len(r.repos), want)
+	}
+	return nil
+}
+
+func (r *selftestRun) clone(ctx context.Context) error {
+	opts, err := configCloneOptions(r.cfg, git.PullOptions{}, io.Discard)
+	if err != nil {
+		return err
+	}
+	opts.useSSH = false
+	r.opts = opts
+
+	if err := r.process(ctx); err != nil {
+		return err
+	}
+	for _, repo := range r.repos {
+		if status, err := git.GetRepositoryStatus(paths.ResolveRepositoryPath(r.cfg, repo)); err != nil || !status.IsGitRepo {
+			return fmt.Errorf("%s was not cloned", repo.FullPath)
+		}
+	}
+	return nil
+}
+
+func (r *selftestRun) sync(ctx context.Context) error {
+	repo := r.repos[0]
+	for _, server := range r.servers {
+		if server.Type == repo.Provider {
+			if err := server.Commit(repo.FullPath, "SELFTEST", "synced\n"); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.opts.update = true
+	if err := r.process(ctx); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(paths.ResolveRepositoryPath(r.cfg, repo), "SELFTEST")); err != nil {
+		return fmt.Errorf("%s did not receive the new commit", repo.FullPath)
+	}
+	return nil
+}
+
+// process clones or updates every repository, failing with the output of
+// the first repository that failed
+func (r *selftestRun) process(ctx context.Context) error {
+	var out bytes.Buffer
+	summary := processRepositories(ctx, r.repos, r.cfg, r.opts, &out)
+	if len(summary.Failures) > 0 {
+		failure := summary.Failures[0]
+		return fmt.Errorf("%s: %v\n%s", failure.Repo.FullPath, failure.Err, out.String())
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommand_Selftest(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	out, err := runCommand(t, nil, nil, "selftest")
+	if err != nil {
+		t.Fatalf("selftest failed: %v\n%s", err, out)
+	}
+	for _, step := range []string{"Listed repositories", "Cloned repositories", "Synced a new commit"} {
+		if !strings.Contains(out, "✅ "+step) {
+			t.Errorf("Expected step %q to pass:\n%s", step, out)
+		}
+	}
+	if !strings.Contains(out, "All selftest steps passed") {
+		t.Errorf("Expected the selftest to pass:\n%s", out)
+	}
+}
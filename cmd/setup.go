@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+// setupLogName is the file in a clone's .git directory that keeps the output
+// of its last setup
+const setupLogName = "gitstuff-setup.log"
+
+// setupTailLines is how much of the output of a failed setup is shown
+const setupTailLines = 20
+
+// setupRule runs commands in repositories matching include after they are
+// first cloned
+type setupRule struct {
+	include  patternSet
+	commands []string
+}
+
+func compileSetupRules(rules []config.SetupRule) ([]setupRule, error) {
+	compiled := make([]setupRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule.Commands) == 0 {
+			return nil, fmt.Errorf("setup rule for %v has no commands", rule.Include)
+		}
+		include, err := compilePatternSet(rule.Include, nil)
+		if err != nil {
+			return nil, fmt.Errorf("setup rule: %w", err)
+		}
+		compiled = append(compiled, setupRule{include: include, commands: rule.Commands})
+	}
+	return compiled, nil
+}
+
+// setupCommands returns the commands of every rule matching repo, in order
+func setupCommands(rules []setupRule, repo *scm.Repository) []string {
+	var commands []string
+	for _, rule := range rules {
+		if rule.include.matches(repo.FullPath) {
+			commands = append(commands, rule.commands...)
+		}
+	}
+	return commands
+}
+
+// runSetup runs the setup commands for repo in its new clone at repoPath,
+// stopping at the first command that fails. Their output is kept in the
+// clone's setup log rather than shown, except for the end of it when a
+// command fails.
+func runSetup(w io.Writer, repoPath string, repo *scm.Repository, rules []setupRule) error {
+	commands := setupCommands(rules, repo)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	var output bytes.Buffer
+	var failed error
+	for _, command := range commands {
+		fmt.Fprintf(w, "🛠️  %s\n", i18n.T("setup.running", command))
+		fmt.Fprintf(&output, "$ %s\n", command)
+		cmd := shellCommand(repoPath, repo, command)
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		if err := cmd.Run(); err != nil {
+			failed = fmt.Errorf("setup command %q failed: %w", command, err)
+			break
+		}
+	}
+
+	logPath := filepath.Join(repoPath, ".git", setupLogName)
+	if err := os.WriteFile(logPath, output.Bytes(), 0644); err != nil {
+		verbosity.Debug("Failed to write setup log for %s: %v", repo.FullPath, err)
+		logPath = ""
+	}
+
+	if failed == nil {
+		fmt.Fprintf(w, "✅ %s\n", i18n.T("setup.done"))
+		return nil
+	}
+	for _, line := range lastLines(output.String(), setupTailLines) {
+		fmt.Fprintf(w, "   │ %s\n", line)
+	}
+	if logPath != "" {
+		return fmt.Errorf("%w (output in %s)", failed, logPath)
+	}
+	return failed
+}
+
+// lastLines returns the last n lines of text, or all of them when fewer
+func lastLines(text string, n int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestSetupCommands(t *testing.T) {
+	rules, err := compileSetupRules([]config.SetupRule{
+		{Commands: []string{"git config core.hooksPath .githooks"}},
+		{Include: []string{"web/*"}, Commands: []string{"npm ci"}},
+		{Include: []string{"web/legacy", "tools/*"}, Commands: []string{"make bootstrap"}},
+	})
+	if err != nil {
+		t.Fatalf("compileSetupRules() error = %v", err)
+	}
+
+	tests := []struct {
+		fullPath string
+		want     []string
+	}{
+		{fullPath: "api/server", want: []string{"git config core.hooksPath .githooks"}},
+		{fullPath: "web/shop", want: []string{"git config core.hooksPath .githooks", "npm ci"}},
+		{fullPath: "web/legacy", want: []string{"git config core.hooksPath .githooks", "npm ci", "make bootstrap"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fullPath, func(t *testing.T) {
+			got := setupCommands(rules, &scm.Repository{FullPath: tt.fullPath})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setupCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := compileSetupRules([]config.SetupRule{{Include: []string{"web/*"}}}); err == nil {
+		t.Error("Expected an error for a rule without commands")
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "one\n", want: []string{"one"}},
+		{text: "one\ntwo\nthree\n", want: []string{"two", "three"}},
+	}
+	for _, tt := range tests {
+		if got := lastLines(tt.text, 2); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lastLines(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCommand_SyncSetup(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+		Setup: []config.SetupRule{
+			{Commands: []string{"echo bootstrapping $GITSTUFF_REPO_NAME"}},
+			{Include: []string{"team/broken"}, Commands: []string{"echo missing dependency >&2; exit 3", "touch unreachable"}},
+		},
+	}
+
+	var repos []*scm.Repository
+	for _, fullPath := range []string{"team/api", "team/broken"} {
+		repos = append(repos, &scm.Repository{
+			Name:     filepath.Base(fullPath),
+			FullPath: fullPath,
+			CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", filepath.Base(fullPath)+".git")),
+			Provider: "gitlab",
+		})
+	}
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--https", "-j", "1")
+	if err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, out)
+	}
+
+	base := filepath.Join(cfg.Local.BaseDir, "gitlab", "team")
+	log, err := os.ReadFile(filepath.Join(base, "api", ".git", setupLogName))
+	if err != nil {
+		t.Fatalf("Expected a setup log: %v", err)
+	}
+	if string(log) != "$ echo bootstrapping $GITSTUFF_REPO_NAME\nbootstrapping api\n" {
+		t.Errorf("Unexpected setup log:\n%s", log)
+	}
+	if strings.Contains(out, "bootstrapping api") {
+		t.Errorf("Expected the output of a successful setup to stay in the log, got:\n%s", out)
+	}
+
+	brokenLog := filepath.Join(base, "broken", ".git", setupLogName)
+	for _, want := range []string{
+		"Setup finished",
+		"│ missing dependency",
+		`team/broken [gitlab]: setup command "echo missing dependency >&2; exit 3" failed: exit status 3 (output in ` + brokenLog + ")",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "broken", "unreachable")); !os.IsNotExist(err) {
+		t.Errorf("Expected setup to stop at the failing command, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cmd
+
+import "os/exec"
+
+// rawShellCommandLine only matters on Windows, where cmd parses the command
+// line itself
+func rawShellCommandLine(cmd *exec.Cmd, script string) {}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// rawShellCommandLine hands script to cmd exactly as written. Go would
+// otherwise quote it once more with backslash escapes, which cmd does not
+// understand; /S makes cmd drop only the outer quotes added here.
+func rawShellCommandLine(cmd *exec.Cmd, script string) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + script + `"`}
+}
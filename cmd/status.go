@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/theme"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [path]",
+	Short: "Show the status of all local repositories",
+	Long: `Scan the base directory (or the given path) for git repositories and report
+their branch, uncommitted changes, commits ahead/behind upstream and stash count.
+
+This command only looks at the local filesystem and never contacts any provider,
+so it works offline and is much faster than 'gitstuff list --status'.
+
+Examples:
+  gitstuff status              # Scan the configured base directory
+  gitstuff status ~/src/work   # Scan a specific directory
+  gitstuff status --dirty      # Only show repositories that need attention`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().Bool("dirty", false, "Only show repositories with changes, unpushed/unpulled commits or stashes")
+	statusCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to inspect in parallel")
+}
+
+type localRepoStatus struct {
+	Path   string
+	Status *git.Status
+	Err    error
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	var root string
+	if len(args) == 1 {
+		root = args[0]
+		if err := applyGitBackend(""); err != nil {
+			return err
+		}
+	} else {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first or pass a path)", err)
+		}
+		root = cfg.Local.BaseDir
+	}
+
+	dirtyOnly, _ := cmd.Flags().GetBool("dirty")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	verbosity.Debug("Scanning %s for git repositories", root)
+	repoPaths, err := git.FindRepositories(root)
+	if err != nil {
+		return err
+	}
+	verbosity.DebugTiming(start, "Found %d repositories", len(repoPaths))
+
+	statuses := collectLocalStatuses(repoPaths, jobs)
+	displayLocalStatuses(stdout, root, statuses, dirtyOnly)
+
+	verbosity.DebugTiming(start, "Status scan completed")
+	return nil
+}
+
+func collectLocalStatuses(repoPaths []string, jobs int) []localRepoStatus {
+	statuses := make([]localRepoStatus, len(repoPaths))
+	tasks := make([]runner.Task, len(repoPaths))
+	for i, repoPath := range repoPaths {
+		tasks[i] = func(w io.Writer) error {
+			status, err := git.GetDetailedStatus(repoPath)
+			statuses[i] = localRepoStatus{Path: repoPath, Status: status, Err: err}
+			return err
+		}
+	}
+
+	runner.New(jobs).Run(tasks, io.Discard)
+	return statuses
+}
+
+func needsAttention(status *git.Status) bool {
+	return status.HasChanges || status.Ahead > 0 || status.Behind > 0 || status.StashCount > 0
+}
+
+func displayLocalStatuses(w io.Writer, root string, statuses []localRepoStatus, dirtyOnly bool) {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("status.found_local", len(statuses), root))
+
+	shown := 0
+	attention := 0
+	for _, entry := range statuses {
+		name := entry.Path
+		if rel, err := filepath.Rel(root, entry.Path); err == nil {
+			name = rel
+		}
+
+		if entry.Err != nil {
+			attention++
+			fmt.Fprintf(w, "📁 %s - %s %s\n", name, theme.Get().Error, i18n.T("status.error", redact.Error(entry.Err)))
+			shown++
+			continue
+		}
+
+		if needsAttention(entry.Status) {
+			attention++
+		} else if dirtyOnly {
+			continue
+		}
+
+		fmt.Fprintf(w, "📁 %s - %s\n", name, formatLocalStatus(entry.Status))
+		shown++
+	}
+
+	if shown > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, i18n.T("status.summary", len(statuses), attention))
+}
+
+func formatLocalStatus(status *git.Status) string {
+	var parts []string
+
+	if status.CurrentBranch != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", status.CurrentBranch))
+	}
+
+	if status.HasChanges {
+		parts = append(parts, theme.Get().Dirty+" "+i18n.T("status.uncommitted"))
+	} else {
+		parts = append(parts, theme.Get().Cloned+" "+i18n.T("status.clean"))
+	}
+
+	if status.Upstream == "" {
+		parts = append(parts, i18n.T("status.no_upstream"))
+	} else {
+		if status.Ahead > 0 {
+			parts = append(parts, fmt.Sprintf("↑%d", status.Ahead))
+		}
+		if status.Behind > 0 {
+			parts = append(parts, fmt.Sprintf("↓%d", status.Behind))
+		}
+	}
+
+	if status.StashCount > 0 {
+		parts = append(parts, "📦 "+i18n.T("status.stashed", status.StashCount))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/git"
+)
+
+func TestFormatLocalStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   *git.Status
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "clean and in sync",
+			status:   &git.Status{CurrentBranch: "main", Upstream: "origin/main"},
+			contains: []string{"(main)", "✅ clean"},
+			excludes: []string{"↑", "↓", "stashed", "no upstream"},
+		},
+		{
+			name:     "dirty ahead behind with stash",
+			status:   &git.Status{CurrentBranch: "feature", HasChanges: true, Upstream: "origin/feature", Ahead: 2, Behind: 3, StashCount: 1},
+			contains: []string{"(feature)", "🔄 uncommitted changes", "↑2", "↓3", "📦 1 stashed"},
+			excludes: []string{"✅ clean"},
+		},
+		{
+			name:     "no upstream",
+			status:   &git.Status{CurrentBranch: "local-only"},
+			contains: []string{"no upstream"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatLocalStatus(tt.status)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Expected %q to contain %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("Expected %q not to contain %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestDisplayLocalStatuses(t *testing.T) {
+	root := "/repos"
+	statuses := []localRepoStatus{
+		{Path: filepath.Join(root, "gitlab", "group", "clean"), Status: &git.Status{Exists: true, IsGitRepo: true, CurrentBranch: "main", Upstream: "origin/main"}},
+		{Path: filepath.Join(root, "gitlab", "group", "dirty"), Status: &git.Status{Exists: true, IsGitRepo: true, CurrentBranch: "main", HasChanges: true}},
+		{Path: filepath.Join(root, "github", "org", "stashed"), Status: &git.Status{Exists: true, IsGitRepo: true, CurrentBranch: "main", StashCount: 2}},
+		{Path: filepath.Join(root, "github", "org", "broken"), Err: errors.New("corrupt")},
+	}
+
+	t.Run("all repositories", func(t *testing.T) {
+		var buf bytes.Buffer
+		displayLocalStatuses(&buf, root, statuses, false)
+		output := buf.String()
+
+		for _, want := range []string{
+			"Found 4 local repositories in /repos",
+			"gitlab/group/clean - (main) ✅ clean",
+			"gitlab/group/dirty",
+			"github/org/stashed",
+			"github/org/broken - ❌ Error: corrupt",
+			"Summary: 4 repositories, 3 need attention",
+		} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+			}
+		}
+	})
+
+	t.Run("dirty only", func(t *testing.T) {
+		var buf bytes.Buffer
+		displayLocalStatuses(&buf, root, statuses, true)
+		output := buf.String()
+
+		if strings.Contains(output, "group/clean") {
+			t.Errorf("Expected clean repository to be hidden, got:\n%s", output)
+		}
+		if !strings.Contains(output, "group/dirty") || !strings.Contains(output, "org/stashed") {
+			t.Errorf("Expected repositories needing attention to be shown, got:\n%s", output)
+		}
+	})
+}
+
+func TestCollectLocalStatuses(t *testing.T) {
+	root := t.TempDir()
+	remote := createRemoteRepo(t, filepath.Join(root, "remotes", "repo.git"))
+	local := filepath.Join(root, "repos", "gitlab", "group", "repo")
+	if err := git.CloneRepositoryWithOutput(remote, local, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("Failed to clone: %v", err)
+	}
+
+	repoPaths, err := git.FindRepositories(filepath.Join(root, "repos"))
+	if err != nil {
+		t.Fatalf("FindRepositories failed: %v", err)
+	}
+
+	statuses := collectLocalStatuses(repoPaths, 2)
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 status, got %d", len(statuses))
+	}
+	if statuses[0].Err != nil {
+		t.Fatalf("Unexpected error: %v", statuses[0].Err)
+	}
+	if statuses[0].Path != local || statuses[0].Status.Upstream == "" {
+		t.Errorf("Expected status for %s with upstream, got %+v", local, statuses[0])
+	}
+}
@@ -0,0 +1,539 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/hints"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/manifest"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [group-path]",
+	Short: "Clone missing repositories and pull existing ones in one pass",
+	Long: `Reconcile local repositories with all configured providers.
+
+Repositories that are not cloned yet are cloned, existing clean repositories
+are pulled, and repositories with uncommitted changes are skipped with a
+warning (or pulled with their changes stashed, with --autostash). Pulls merge
+unless --rebase, --ff-only or git.pull_strategy says otherwise. A
+reconciliation summary is printed at the end.
+
+Examples:
+  gitstuff sync                   # Sync all repositories
+  gitstuff sync group/subgroup    # Sync only repositories in a group
+  gitstuff sync --dry-run         # Show what would happen without changing anything
+  gitstuff sync --dry-run --tree  # Show the plan on the group hierarchy
+  gitstuff sync -j 8              # Sync 8 repositories at a time
+  gitstuff sync --checkout-default # Also return clean clones to their default branch
+  gitstuff sync --fetch-only      # Fetch instead of pull, leaving worktrees alone
+  gitstuff sync --rebase --autostash # Rebase local commits, stashing local changes
+  gitstuff sync --manifest team-a # Sync what team-a's manifest requires`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSync,
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.Flags().Bool("https", false, "Use HTTPS instead of SSH when cloning")
+	syncCmd.Flags().IntP("jobs", "j", 1, "Number of repositories to sync in parallel")
+	syncCmd.Flags().BoolP("dry-run", "n", false, "Show planned actions without cloning or pulling")
+	syncCmd.Flags().BoolP("tree", "t", false, "With --dry-run, show the planned actions in the group tree")
+	syncCmd.Flags().Bool("move-renamed", false, "Move clones of renamed or transferred repositories without asking")
+	syncCmd.Flags().Bool("protocol-fallback", false, "Retry failed clones over the other protocol (default: git.protocol_fallback)")
+	syncCmd.Flags().Bool("checkout-default", false, "Switch clean repositories to their default branch before pulling")
+	syncCmd.Flags().Bool("fetch-only", false, "Fetch existing repositories instead of pulling them, leaving their branches alone")
+	addPullStrategyFlags(syncCmd)
+	addRetryFlags(syncCmd)
+	syncCmd.MarkFlagsMutuallyExclusive("fetch-only", "checkout-default")
+	syncCmd.MarkFlagsMutuallyExclusive("fetch-only", "dry-run")
+	syncCmd.MarkFlagsMutuallyExclusive("fetch-only", "rebase", "ff-only")
+	syncCmd.Flags().String("manifest", "", "Sync the repositories required by the manifest of this group")
+	syncCmd.Flags().String("manifest-repo", defaultManifestRepo, "Repository of the group that holds "+manifest.FileName)
+	syncCmd.MarkFlagsMutuallyExclusive("manifest", "fetch-only")
+	syncCmd.MarkFlagsMutuallyExclusive("manifest", "dry-run")
+	addRepoFilterFlags(syncCmd)
+	addLimitRateFlag(syncCmd)
+}
+
+type syncAction int
+
+const (
+	syncClone syncAction = iota
+	syncPull
+	syncSkipDirty
+	syncSkipProtected
+	syncConflict
+	syncMove
+)
+
+// syncPlanEntry is the action sync would take for a single repository
+type syncPlanEntry struct {
+	Repo      *scm.Repository
+	LocalPath string
+	OldPath   string // Full path the clone of a moved repository was made for
+	Action    syncAction
+	Err       error
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+
+	stopLimit, err := startTransferLimit(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopLimit()
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	showTree, _ := cmd.Flags().GetBool("tree")
+
+	if showTree && !dryRun {
+		return fmt.Errorf("--tree can only be used with --dry-run")
+	}
+	if !dryRun {
+		if err := checkWritable("sync repositories (--dry-run shows the plan)"); err != nil {
+			return err
+		}
+	}
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+	if manifestGroup, _ := cmd.Flags().GetString("manifest"); manifestGroup != "" {
+		if groupPath != "" {
+			return fmt.Errorf("--manifest selects the group; do not pass one as well")
+		}
+		groupPath = manifestGroup
+	}
+
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+
+	ctx := commandContext(cmd)
+	if dryRun {
+		pullStrategy, err := pullStrategyFromFlags(cmd, cfg)
+		if err != nil {
+			return err
+		}
+		return displaySyncDryRun(ctx, cfg, clients, groupPath, filter, showTree, pullStrategy.Autostash)
+	}
+
+	summary, err := syncRepositories(ctx, cmd, cfg, clients, groupPath, filter, stdout)
+	recordLastRun(cfg, newLastRun("sync", args, start, summary, err))
+	if err != nil {
+		return err
+	}
+	if summary == nil {
+		fmt.Fprintln(stdout, i18n.T("sync.none_found"))
+		return nil
+	}
+	displaySyncSummary(stdout, summary)
+	showHints(cfg, "sync", hints.Facts{"failed": summary.Failed(), "dirty": len(summary.Dirty)})
+
+	verbosity.DebugTiming(start, "Sync completed")
+	return nil
+}
+
+// syncRepositories clones and pulls the repositories in groupPath, or all of
+// them, with the options from cmd's flags, writing progress to out. The
+// summary is nil when there was nothing to sync.
+func syncRepositories(ctx context.Context, cmd *cobra.Command, cfg *config.Config, clients []scm.Client, groupPath string, filter repoFilter, out io.Writer) (*processSummary, error) {
+	useHTTPS, _ := cmd.Flags().GetBool("https")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	moveRenamed, _ := cmd.Flags().GetBool("move-renamed")
+	checkoutDefault, _ := cmd.Flags().GetBool("checkout-default")
+	fetchOnly, _ := cmd.Flags().GetBool("fetch-only")
+
+	remotes, err := compileRemoteRules(cfg.Remotes)
+	if err != nil {
+		return nil, err
+	}
+	pullRules, err := compilePullRules(cfg.PullRules)
+	if err != nil {
+		return nil, err
+	}
+	pullStrategy, err := pullStrategyFromFlags(cmd, cfg)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := retryPolicyFromFlags(cmd, cfg)
+	if err != nil {
+		return nil, err
+	}
+	setup, err := compileSetupRules(cfg.Setup)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := loadManagedInfo(cfg)
+	if err != nil {
+		return nil, err
+	}
+	retention, err := compileRetention(cfg.Retention)
+	if err != nil {
+		return nil, err
+	}
+
+	listed, repos := collectSyncRepositories(ctx, clients, groupPath, filter, retention)
+	if len(repos) == 0 {
+		if groupPath != "" {
+			return nil, fmt.Errorf("no repositories found in group '%s'", groupPath)
+		}
+		return nil, nil
+	}
+
+	opts := cloneOptions{useSSH: !useHTTPS, protocolSet: useHTTPS, update: true, skipDirty: true, jobs: jobs, filter: filter, remotes: remotes, pullRules: pullRules,
+		state: loadState(cfg, out), moveRenamed: moveRenamed, protocolFallback: protocolFallbackFromFlags(cmd, cfg), checkoutDefault: checkoutDefault, fetchOnly: fetchOnly, pull: pullStrategy, setup: setup, maintenance: cfg.Git.Maintenance, managed: managed,
+		referenceForks: cfg.Git.ReferenceForks, retry: retry}
+	if manifestGroup, _ := cmd.Flags().GetString("manifest"); manifestGroup != "" {
+		manifestRepo, _ := cmd.Flags().GetString("manifest-repo")
+		var manifestSetup []setupRule
+		repos, manifestSetup, err = applyManifest(ctx, out, cfg, repos, manifestGroup, manifestRepo, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.setup = append(opts.setup, manifestSetup...)
+	}
+
+	fmt.Fprintf(out, "%s\n\n", i18n.T("sync.syncing", len(repos)))
+	repos = relocateMovedRepositories(cfg, repos, opts, out)
+	summary := processRepositories(ctx, repos, cfg, opts, out)
+	if !retention.empty() && ctx.Err() == nil {
+		summary.Retention = retention.plan(cfg, listed, time.Now())
+		applyRetention(cfg, summary.Retention)
+	}
+	return summary, nil
+}
+
+// collectSyncRepositories returns the repositories listed for sync and those
+// of them to sync. The listing includes archived repositories when
+// retention removes their clones.
+func collectSyncRepositories(ctx context.Context, clients []scm.Client, groupPath string, filter repoFilter, retention retentionRules) (listed, repos []*scm.Repository) {
+	listFilter := filter
+	if retention.archivedAfter > 0 {
+		listFilter.includeArchived = true
+	}
+	listed = collectRepositories(ctx, clients, groupPath, listFilter)
+	if listFilter.includeArchived == filter.includeArchived {
+		return listed, listed
+	}
+	repos = slices.DeleteFunc(slices.Clone(listed), func(repo *scm.Repository) bool { return repo.Archived })
+	return listed, repos
+}
+
+// displayVisibilityChanges lists the repositories whose visibility changed,
+// marking those that became public, as that may expose code by accident
+func displayVisibilityChanges(w io.Writer, changes []state.VisibilityChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("sync.visibility_header"))
+	for _, change := range changes {
+		line := i18n.T("sync.visibility_changed", change.Repo.FullPath, change.Repo.Provider, change.From, change.To)
+		if change.Exposed() {
+			fmt.Fprintf(w, "  🚨 %s\n", i18n.T("sync.visibility_exposed", line))
+		} else {
+			fmt.Fprintf(w, "  - %s\n", line)
+		}
+	}
+}
+
+func displaySyncDryRun(ctx context.Context, cfg *config.Config, clients []scm.Client, groupPath string, filter repoFilter, showTree, autostash bool) error {
+	pullRules, err := compilePullRules(cfg.PullRules)
+	if err != nil {
+		return err
+	}
+	retention, err := compileRetention(cfg.Retention)
+	if err != nil {
+		return err
+	}
+
+	listed, repos := collectSyncRepositories(ctx, clients, groupPath, filter, retention)
+	if len(repos) == 0 {
+		if groupPath != "" {
+			return fmt.Errorf("no repositories found in group '%s'", groupPath)
+		}
+		fmt.Fprintln(stdout, i18n.T("sync.none_found"))
+		return nil
+	}
+
+	plan := planSync(repos, cfg, pullRules, autostash)
+	markMoves(plan, detectMoves(cfg, repos, loadState(cfg, stdout)))
+	if showTree {
+		displaySyncPlanTree(stdout, plan)
+	} else {
+		displaySyncPlan(stdout, plan)
+	}
+	displayRetention(stdout, retention.plan(cfg, listed, time.Now()), true)
+	return nil
+}
+
+// planSync determines what sync would do for each repository without
+// changing anything on disk. With autostash, dirty clones are pulled too.
+func planSync(repos []*scm.Repository, cfg *config.Config, pullRules []pullRule, autostash bool) []syncPlanEntry {
+	plan := make([]syncPlanEntry, 0, len(repos))
+	for _, repo := range repos {
+		entry := syncPlanEntry{Repo: repo, LocalPath: paths.ResolveRepositoryPath(cfg, repo)}
+
+		status, err := git.GetRepositoryStatus(entry.LocalPath)
+		switch {
+		case err != nil:
+			entry.Action = syncConflict
+			entry.Err = err
+		case !status.Exists:
+			entry.Action = syncClone
+		case !status.IsGitRepo:
+			entry.Action = syncConflict
+			entry.Err = fmt.Errorf("directory exists but is not a git repository")
+		case status.HasChanges && !autostash:
+			entry.Action = syncSkipDirty
+		default:
+			entry.Action = syncPull
+			if action, _, err := planPull(entry.LocalPath, status, repo, pullRules); err != nil {
+				entry.Action = syncConflict
+				entry.Err = err
+			} else if action == pullRefuse {
+				entry.Action = syncSkipProtected
+			}
+		}
+
+		plan = append(plan, entry)
+	}
+	return plan
+}
+
+// markMoves turns the planned clones of moved repositories into moves of
+// their existing clones
+func markMoves(plan []syncPlanEntry, moves []repoMove) {
+	byRepo := make(map[*scm.Repository]repoMove, len(moves))
+	for _, move := range moves {
+		byRepo[move.Repo] = move
+	}
+	for i := range plan {
+		if move, ok := byRepo[plan[i].Repo]; ok && plan[i].Action == syncClone {
+			plan[i].Action = syncMove
+			plan[i].LocalPath = move.From
+			plan[i].OldPath = move.OldPath
+		}
+	}
+}
+
+func displaySyncPlan(w io.Writer, plan []syncPlanEntry) {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("sync.plan_header", len(plan)))
+
+	for _, entry := range plan {
+		switch entry.Action {
+		case syncClone:
+			fmt.Fprintf(w, "📥 %-6s %s [%s]\n", i18n.T("sync.action_clone"), entry.Repo.FullPath, entry.Repo.Provider)
+		case syncPull:
+			fmt.Fprintf(w, "🔄 %-6s %s [%s]\n", i18n.T("sync.action_pull"), entry.Repo.FullPath, entry.Repo.Provider)
+		case syncSkipDirty:
+			fmt.Fprintf(w, "⚠️  %-6s %s [%s] %s\n", i18n.T("sync.action_skip"), entry.Repo.FullPath, entry.Repo.Provider, i18n.T("sync.uncommitted"))
+		case syncSkipProtected:
+			fmt.Fprintf(w, "⚠️  %-6s %s [%s] %s\n", i18n.T("sync.action_skip"), entry.Repo.FullPath, entry.Repo.Provider, i18n.T("sync.local_commits"))
+		case syncConflict:
+			fmt.Fprintf(w, "❌ %-6s %s [%s] (%v)\n", i18n.T("sync.action_error"), entry.Repo.FullPath, entry.Repo.Provider, redact.Error(entry.Err))
+		case syncMove:
+			fmt.Fprintf(w, "🚚 %-6s %s [%s] %s\n", i18n.T("sync.action_move"), entry.Repo.FullPath, entry.Repo.Provider, i18n.T("sync.moved_from", entry.LocalPath))
+		}
+	}
+
+	displaySyncPlanSummary(w, plan)
+}
+
+func displaySyncPlanSummary(w io.Writer, plan []syncPlanEntry) {
+	counts := make(map[syncAction]int)
+	for _, entry := range plan {
+		counts[entry.Action]++
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("sync.plan_summary",
+		counts[syncClone], counts[syncPull], counts[syncSkipDirty]+counts[syncSkipProtected], counts[syncConflict]))
+	if counts[syncMove] > 0 {
+		fmt.Fprintln(w, i18n.T("sync.plan_moves", counts[syncMove]))
+	}
+}
+
+// planNode is a group in the tree rendering of a sync plan
+type planNode struct {
+	groups map[string]*planNode
+	leaves []planLeaf
+}
+
+// planLeaf is a repository in the tree, or the old location of a clone that
+// would be moved away
+type planLeaf struct {
+	name    string
+	entry   syncPlanEntry
+	removed bool
+}
+
+func newPlanNode() *planNode {
+	return &planNode{groups: make(map[string]*planNode)}
+}
+
+func (n *planNode) add(fullPath string, leaf planLeaf) {
+	parts := strings.Split(fullPath, "/")
+	node := n
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := node.groups[part]
+		if !ok {
+			child = newPlanNode()
+			node.groups[part] = child
+		}
+		node = child
+	}
+	leaf.name = parts[len(parts)-1]
+	node.leaves = append(node.leaves, leaf)
+}
+
+// displaySyncPlanTree shows the plan on each provider's group hierarchy.
+// Moved clones appear twice: removed from their old group and added to the
+// new one.
+func displaySyncPlanTree(w io.Writer, plan []syncPlanEntry) {
+	fmt.Fprintln(w, i18n.T("sync.plan_header", len(plan)))
+
+	var providers []string
+	roots := make(map[string]*planNode)
+	for _, entry := range plan {
+		root, ok := roots[entry.Repo.Provider]
+		if !ok {
+			root = newPlanNode()
+			roots[entry.Repo.Provider] = root
+			providers = append(providers, entry.Repo.Provider)
+		}
+		root.add(entry.Repo.FullPath, planLeaf{entry: entry})
+		if entry.Action == syncMove && entry.OldPath != "" {
+			root.add(entry.OldPath, planLeaf{entry: entry, removed: true})
+		}
+	}
+
+	for _, provider := range providers {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("list.provider_header", strings.ToUpper(provider)))
+		displayPlanNode(w, roots[provider], 0)
+	}
+	displaySyncPlanSummary(w, plan)
+}
+
+func displayPlanNode(w io.Writer, node *planNode, indent int) {
+	prefix := strings.Repeat("  ", indent)
+
+	sort.SliceStable(node.leaves, func(i, j int) bool { return node.leaves[i].name < node.leaves[j].name })
+	for _, leaf := range node.leaves {
+		fmt.Fprintf(w, "%s%s\n", prefix, planLeafLine(leaf))
+	}
+
+	names := make([]string, 0, len(node.groups))
+	for name := range node.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s📂 %s/\n", prefix, name)
+		displayPlanNode(w, node.groups[name], indent+1)
+	}
+}
+
+func planLeafLine(leaf planLeaf) string {
+	entry := leaf.entry
+	if leaf.removed {
+		return fmt.Sprintf("➖ %s %s", leaf.name, i18n.T("sync.moved_to", entry.Repo.FullPath))
+	}
+	switch entry.Action {
+	case syncClone:
+		return "➕ " + leaf.name
+	case syncPull:
+		return "🔄 " + leaf.name
+	case syncSkipDirty:
+		return fmt.Sprintf("⚠️  %s %s", leaf.name, i18n.T("sync.uncommitted"))
+	case syncSkipProtected:
+		return fmt.Sprintf("⚠️  %s %s", leaf.name, i18n.T("sync.local_commits"))
+	case syncMove:
+		return fmt.Sprintf("🚚 %s %s", leaf.name, i18n.T("sync.moved_from", entry.LocalPath))
+	default:
+		return fmt.Sprintf("❌ %s (%v)", leaf.name, redact.Error(entry.Err))
+	}
+}
+
+func displaySyncSummary(w io.Writer, summary *processSummary) {
+	fmt.Fprintln(w, i18n.T("sync.summary_header"))
+	fmt.Fprintf(w, "  📥 %s\n", i18n.T("sync.summary_cloned", summary.Cloned))
+	fmt.Fprintf(w, "  🔄 %s\n", i18n.T("sync.summary_updated", summary.Updated))
+	if summary.Switched > 0 {
+		fmt.Fprintf(w, "  🔀 %s\n", i18n.T("sync.summary_switched", summary.Switched))
+	}
+	if summary.Fetched > 0 {
+		fmt.Fprintf(w, "  📡 %s\n", i18n.T("sync.summary_fetched", summary.Fetched))
+	}
+	fmt.Fprintf(w, "  ⚠️  %s\n", i18n.T("sync.summary_skipped", len(summary.Dirty)))
+	if len(summary.Protected) > 0 {
+		fmt.Fprintf(w, "  ⚠️  %s\n", i18n.T("sync.summary_protected", len(summary.Protected)))
+	}
+	fmt.Fprintf(w, "  ❌ %s\n", i18n.T("sync.summary_failed", summary.Failed()))
+	if len(summary.Interrupted) > 0 {
+		fmt.Fprintf(w, "  ⏹️  %s\n", i18n.T("sync.summary_interrupted", len(summary.Interrupted)))
+	}
+	if len(summary.VisibilityChanges) > 0 {
+		fmt.Fprintf(w, "  👁️  %s\n", i18n.T("sync.summary_visibility", len(summary.VisibilityChanges)))
+	}
+	if removed := removedCount(summary.Retention); removed > 0 {
+		fmt.Fprintf(w, "  🧹 %s\n", i18n.T("sync.summary_removed", removed))
+	}
+	displaySummaryDetails(w, summary)
+	displayRetention(w, summary.Retention, false)
+}
+
+// displaySummaryDetails lists the repositories whose visibility changed,
+// those that were retried and those that were skipped or failed
+func displaySummaryDetails(w io.Writer, summary *processSummary) {
+	displayVisibilityChanges(w, summary.VisibilityChanges)
+	displayRetried(w, summary.Retried)
+
+	if len(summary.Dirty) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("sync.dirty_header"))
+		for _, repo := range summary.Dirty {
+			fmt.Fprintf(w, "  - %s [%s]\n", repo.FullPath, repo.Provider)
+		}
+	}
+
+	if len(summary.Protected) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("sync.protected_header"))
+		for _, repo := range summary.Protected {
+			fmt.Fprintf(w, "  - %s [%s]\n", repo.FullPath, repo.Provider)
+		}
+	}
+
+	if len(summary.Failures) > 0 {
+		fmt.Fprintf(w, "\n%s\n", i18n.T("sync.failed_header"))
+		for _, failure := range summary.Failures {
+			fmt.Fprintf(w, "  - %s [%s]: %v\n", failure.Repo.FullPath, failure.Repo.Provider, redact.Error(failure.Err))
+		}
+	}
+}
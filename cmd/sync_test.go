@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+)
+
+// setupSyncFixture creates three remotes: one already cloned and clean, one
+// cloned with local changes and one not cloned yet
+func setupSyncFixture(t *testing.T) (*config.Config, []*scm.Repository) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: filepath.Join(tempDir, "repos")}}
+
+	var repos []*scm.Repository
+	for _, name := range []string{"clean", "dirty", "missing"} {
+		repos = append(repos, &scm.Repository{
+			Name:     name,
+			FullPath: "group/" + name,
+			CloneURL: createRemoteRepo(t, filepath.Join(tempDir, "remotes", name+".git")),
+			Provider: "gitlab",
+		})
+	}
+
+	for _, repo := range repos[:2] {
+		target := filepath.Join(cfg.Local.BaseDir, "gitlab", repo.FullPath)
+		if err := git.CloneRepositoryWithOutput(repo.CloneURL, target, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("Failed to clone %s: %v", repo.FullPath, err)
+		}
+	}
+
+	dirtyFile := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty", "wip.txt")
+	if err := os.WriteFile(dirtyFile, []byte("work in progress"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	return cfg, repos
+}
+
+func TestPlanSync(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+
+	conflictPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "plain")
+	if err := os.MkdirAll(conflictPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	repos = append(repos, &scm.Repository{Name: "plain", FullPath: "group/plain", Provider: "gitlab"})
+
+	plan := planSync(repos, cfg, nil, false)
+
+	want := []syncAction{syncPull, syncSkipDirty, syncClone, syncConflict}
+	if len(plan) != len(want) {
+		t.Fatalf("Expected %d plan entries, got %d", len(want), len(plan))
+	}
+	for i, action := range want {
+		if plan[i].Action != action {
+			t.Errorf("Expected action %d for %s, got %d", action, plan[i].Repo.FullPath, plan[i].Action)
+		}
+	}
+
+	var buf bytes.Buffer
+	displaySyncPlan(&buf, plan)
+	output := buf.String()
+	for _, line := range []string{"🔄 pull   group/clean", "⚠️  skip   group/dirty", "📥 clone  group/missing", "❌ error  group/plain", "Would clone 1, pull 1, skip 1, error 1"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected plan output to contain %q, got:\n%s", line, output)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing")); !os.IsNotExist(err) {
+		t.Error("Expected planning not to clone anything")
+	}
+}
+
+func TestProcessRepositories_SyncSkipsDirty(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+
+	var out bytes.Buffer
+	summary := processRepositories(context.Background(), repos, cfg, cloneOptions{update: true, skipDirty: true, jobs: 2}, &out)
+
+	if summary.Updated != 1 || summary.Cloned != 1 || len(summary.Dirty) != 1 || summary.Failed() != 0 {
+		t.Fatalf("Unexpected summary: %+v\n%s", summary, out.String())
+	}
+	if summary.Dirty[0].FullPath != "group/dirty" {
+		t.Errorf("Expected group/dirty to be skipped, got %s", summary.Dirty[0].FullPath)
+	}
+	if !strings.Contains(out.String(), "Skipped: repository has uncommitted changes") {
+		t.Errorf("Expected warning for dirty repository, got:\n%s", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "missing", ".git")); err != nil {
+		t.Errorf("Expected missing repository to be cloned: %v", err)
+	}
+}
+
+func TestDisplaySyncSummary(t *testing.T) {
+	summary := &processSummary{
+		Cloned:  2,
+		Updated: 5,
+		Dirty:   []*scm.Repository{{FullPath: "group/wip", Provider: "gitlab"}},
+		Failures: []repoFailure{
+			{Repo: &scm.Repository{FullPath: "org/broken", Provider: "github"}, Err: errors.New("exit status 128")},
+		},
+	}
+
+	var buf bytes.Buffer
+	displaySyncSummary(&buf, summary)
+	output := buf.String()
+
+	for _, want := range []string{
+		"Cloned:  2",
+		"Updated: 5",
+		"Skipped (uncommitted changes): 1",
+		"Failed:  1",
+		"- group/wip [gitlab]",
+		fmt.Sprintf("- org/broken [github]: %s", "exit status 128"),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestDisplaySyncSummary_Localized(t *testing.T) {
+	i18n.SetLocale("es")
+	t.Cleanup(func() { i18n.SetLocale(i18n.DefaultLocale) })
+
+	var buf bytes.Buffer
+	displaySyncSummary(&buf, &processSummary{Cloned: 1, Dirty: []*scm.Repository{{FullPath: "group/wip", Provider: "gitlab"}}})
+	output := buf.String()
+
+	for _, want := range []string{"Resumen de sincronización:", "Clonados:      1", "Omitidos (cambios sin confirmar): 1"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected localized summary to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestDisplaySyncPlanTree(t *testing.T) {
+	repo := func(provider, fullPath string) *scm.Repository {
+		return &scm.Repository{FullPath: fullPath, Provider: provider}
+	}
+	plan := []syncPlanEntry{
+		{Repo: repo("gitlab", "team/backend/api"), Action: syncPull},
+		{Repo: repo("gitlab", "team/backend/worker"), Action: syncClone},
+		{Repo: repo("gitlab", "team/docs"), Action: syncSkipDirty},
+		{Repo: repo("gitlab", "platform/tools"), Action: syncMove, LocalPath: "/repos/gitlab/team/tools", OldPath: "team/tools"},
+		{Repo: repo("github", "octo/site"), Action: syncConflict, Err: errors.New("not a git repository")},
+	}
+
+	var buf bytes.Buffer
+	displaySyncPlanTree(&buf, plan)
+
+	want := `Sync plan for 5 repositories (dry run):
+
+=== GITLAB Provider ===
+📂 platform/
+  🚚 tools (from /repos/gitlab/team/tools)
+📂 team/
+  ⚠️  docs (uncommitted changes)
+  ➖ tools (moves to platform/tools)
+  📂 backend/
+    🔄 api
+    ➕ worker
+
+=== GITHUB Provider ===
+📂 octo/
+  ❌ site (not a git repository)
+
+Would clone 1, pull 1, skip 1, error 1
+Would move 1 clones of renamed repositories
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCommand_SyncFetchOnly(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+
+	// A new commit upstream of the dirty clone
+	dirtyPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")
+	work := repos[1].CloneURL + ".work"
+	for _, args := range [][]string{
+		{"-C", work, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "Remote commit"},
+		{"-C", work, "push", "-q", repos[1].CloneURL, "HEAD"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--fetch-only", "--https")
+	if err != nil {
+		t.Fatalf("sync --fetch-only failed: %v", err)
+	}
+	for _, line := range []string{"📥 Cloned:  1", "📡 Fetched: 2", "Skipped (uncommitted changes): 0"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, out)
+		}
+	}
+
+	status, err := git.GetDetailedStatus(dirtyPath)
+	if err != nil {
+		t.Fatalf("GetDetailedStatus failed: %v", err)
+	}
+	if status.Behind != 1 || !status.HasChanges {
+		t.Errorf("Expected the dirty clone to be fetched but not pulled, got %+v", status)
+	}
+}
+
+func TestCommand_SyncAutostash(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+
+	dirtyPath := filepath.Join(cfg.Local.BaseDir, "gitlab", "group", "dirty")
+	work := repos[1].CloneURL + ".work"
+	for _, args := range [][]string{
+		{"-C", work, "-c", "user.name=Test User", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "Remote commit"},
+		{"-C", work, "push", "-q", repos[1].CloneURL, "HEAD"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	client := &mockSCMClient{providerType: "gitlab", repos: repos}
+	out, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--autostash", "--ff-only", "--https")
+	if err != nil {
+		t.Fatalf("sync --autostash failed: %v", err)
+	}
+	if !strings.Contains(out, "Skipped (uncommitted changes): 0") {
+		t.Errorf("Expected the dirty clone to be pulled, got:\n%s", out)
+	}
+
+	status, err := git.GetDetailedStatus(dirtyPath)
+	if err != nil {
+		t.Fatalf("GetDetailedStatus failed: %v", err)
+	}
+	if status.Behind != 0 || !status.HasChanges {
+		t.Errorf("Expected the dirty clone to be up to date with its changes kept, got %+v", status)
+	}
+
+	if _, err := runCommand(t, cfg, map[string]scm.Client{"work": client}, "sync", "--fetch-only", "--rebase"); err == nil {
+		t.Error("Expected --fetch-only and --rebase to be rejected together")
+	}
+}
+
+func TestCommand_SyncVisibilityChanges(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cfg.Cache = config.CacheConfig{DisableHTTP: true, DisableMetadata: true}
+	cfg.Providers = []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}}
+	for i, repo := range repos {
+		repo.ID = fmt.Sprint(i + 1)
+		repo.WebURL = "https://gitlab.example.com/" + repo.FullPath
+		repo.Visibility = "private"
+	}
+	clients := map[string]scm.Client{"work": &mockSCMClient{providerType: "gitlab", repos: repos}}
+
+	out, err := runCommand(t, cfg, clients, "sync", "--https")
+	if err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "Visibility:") {
+		t.Errorf("Expected no visibility changes on the first sync, got:\n%s", out)
+	}
+
+	repos[0].Visibility = "public"
+	repos[2].Visibility = "internal"
+	out, err = runCommand(t, cfg, clients, "sync", "--https")
+	if err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Visibility:    2 changed",
+		"🚨 group/clean [gitlab]: private → public (now public",
+		"  - group/missing [gitlab]: private → internal",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// The new visibilities are recorded, so they are only reported once
+	out, err = runCommand(t, cfg, clients, "sync", "--https")
+	if err != nil {
+		t.Fatalf("sync failed: %v\n%s", err, out)
+	}
+	if strings.Contains(out, "Visibility:") {
+		t.Errorf("Expected the changes to be reported once, got:\n%s", out)
+	}
+}
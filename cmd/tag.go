@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/paths"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags across repositories",
+}
+
+var tagCreateCmd = &cobra.Command{
+	Use:   "create <tag> [group]",
+	Short: "Create and push the same tag in many repositories",
+	Long: `Create a tag in every cloned repository (or every repository in a group) and
+push it to origin, for coordinated releases across repositories.
+
+By default each repository is tagged at the tip of its default branch on
+origin, after fetching. Every repository is checked before anything is
+tagged: if any of them cannot be tagged, for example because the tag already
+exists at a different commit, nothing is created. Repositories that already
+have the tag at the same commit are reported and left alone, so a partly
+failed run can simply be repeated.
+
+Examples:
+  gitstuff tag create v1.4.0 -m "Release 1.4.0"       # Annotated tag everywhere
+  gitstuff tag create v1.4.0 backend-team --sign      # Signed tag in one group
+  gitstuff tag create v1.4.0 --ref release --dry-run  # Show what would be tagged`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runTagCreate,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagCreateCmd)
+	tagCreateCmd.Flags().StringP("message", "m", "", "Create an annotated tag with this message")
+	tagCreateCmd.Flags().BoolP("sign", "s", false, "Create a GPG-signed tag")
+	tagCreateCmd.Flags().String("ref", "", "Branch or commit to tag (default: each repository's default branch)")
+	tagCreateCmd.Flags().BoolP("dry-run", "n", false, "Check every repository and show what would be tagged")
+	tagCreateCmd.Flags().Bool("no-push", false, "Create the tags locally without pushing them")
+	tagCreateCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to check and push in parallel")
+	addRepoFilterFlags(tagCreateCmd)
+}
+
+type tagState int
+
+const (
+	tagCreate   tagState = iota // Tag to be created and pushed
+	tagPushOnly                 // Tag exists locally at the right commit
+	tagAlready                  // Tag exists on origin at the right commit
+	tagConflict                 // Tag exists at a different commit
+	tagFailed
+)
+
+type tagEntry struct {
+	Repo   *scm.Repository
+	Path   string
+	Commit string
+	Other  string // Commit the existing tag points to, for conflicts
+	State  tagState
+	Err    error
+	Pushed bool
+}
+
+type tagOptions struct {
+	tag     string
+	ref     string
+	message string
+	sign    bool
+	push    bool
+}
+
+func runTagCreate(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+
+	opts := tagOptions{tag: args[0]}
+	opts.message, _ = cmd.Flags().GetString("message")
+	opts.sign, _ = cmd.Flags().GetBool("sign")
+	opts.ref, _ = cmd.Flags().GetString("ref")
+	noPush, _ := cmd.Flags().GetBool("no-push")
+	opts.push = !noPush
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	if err := git.CheckTagName(opts.tag); err != nil {
+		return err
+	}
+	// Even a dry run fetches every clone to find the commit to tag
+	if err := checkWritable("tag repositories"); err != nil {
+		return err
+	}
+
+	clients, err := createClients(cfg)
+	if err != nil {
+		return err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return err
+	}
+
+	groupPath := ""
+	if len(args) == 2 {
+		groupPath = args[1]
+	}
+	repos := collectRepositories(commandContext(cmd), clients, groupPath, filter)
+	if len(repos) == 0 && groupPath != "" {
+		return fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+
+	cloned := clonedRepositories(cfg, repos)
+	if skipped := len(repos) - len(cloned); skipped > 0 {
+		fmt.Fprintf(stdout, "%s\n", i18n.T("tag.not_cloned", skipped))
+	}
+	fmt.Fprintf(stdout, "%s\n\n", i18n.T("tag.checking", len(cloned), opts.tag))
+
+	entries := planTags(cfg, cloned, opts, jobs)
+	verbosity.DebugTiming(start, "Checked %d repositories", len(entries))
+	displayTagPlan(stdout, entries, opts.tag)
+
+	blocked := 0
+	for _, entry := range entries {
+		if entry.State == tagConflict || entry.State == tagFailed {
+			blocked++
+		}
+	}
+	if blocked > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d repositories cannot be tagged, nothing was created", blocked)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := createTags(entries, opts); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if opts.push {
+		pushTags(entries, opts.tag, jobs)
+	}
+
+	if failed := displayTagSummary(stdout, entries, opts); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to push %s to %d repositories (run the command again to retry)", opts.tag, failed)
+	}
+	return nil
+}
+
+// planTags fetches each repository and works out what creating the tag
+// would do there, without changing anything
+func planTags(cfg *config.Config, repos []*scm.Repository, opts tagOptions, jobs int) []tagEntry {
+	entries := make([]tagEntry, len(repos))
+	tasks := make([]runner.Task, len(repos))
+	for i, repo := range repos {
+		tasks[i] = func(w io.Writer) error {
+			entries[i] = planTag(paths.ResolveRepositoryPath(cfg, repo), repo, opts)
+			return entries[i].Err
+		}
+	}
+	runner.New(jobs).Run(tasks, io.Discard)
+	return entries
+}
+
+func planTag(repoPath string, repo *scm.Repository, opts tagOptions) tagEntry {
+	entry := tagEntry{Repo: repo, Path: repoPath}
+	fail := func(err error) tagEntry {
+		entry.State = tagFailed
+		entry.Err = err
+		return entry
+	}
+
+	var output bytes.Buffer
+	if err := git.Fetch(repoPath, &output, &output); err != nil {
+		return fail(withOutput(err, output.String()))
+	}
+
+	refs := []string{"origin/" + opts.ref, opts.ref}
+	if opts.ref == "" {
+		refs = []string{"origin/" + repo.DefaultBranch, repo.DefaultBranch, "HEAD"}
+		if repo.DefaultBranch == "" {
+			refs = refs[2:]
+		}
+	}
+	ref := git.ResolveRef(repoPath, refs...)
+	if ref == "" {
+		return fail(fmt.Errorf("unknown revision %s", refs[len(refs)-1]))
+	}
+	commit, err := git.RevParse(repoPath, ref)
+	if err != nil {
+		return fail(err)
+	}
+	entry.Commit = commit
+
+	if !opts.push {
+		// Only the local tag matters, and an existing one is already done
+		return classifyTag(entry, git.LocalTagCommit(repoPath, opts.tag), "")
+	}
+	remote, err := git.RemoteTagCommit(repoPath, opts.tag)
+	if err != nil {
+		return fail(err)
+	}
+	return classifyTag(entry, remote, git.LocalTagCommit(repoPath, opts.tag))
+}
+
+// classifyTag sets the state of entry from the commits an existing remote
+// or local tag points to (empty when there is none)
+func classifyTag(entry tagEntry, remote, local string) tagEntry {
+	switch {
+	case remote != "" && remote != entry.Commit:
+		entry.State, entry.Other = tagConflict, remote
+	case local != "" && local != entry.Commit:
+		entry.State, entry.Other = tagConflict, local
+	case remote != "":
+		entry.State = tagAlready
+	case local != "":
+		entry.State = tagPushOnly
+	default:
+		entry.State = tagCreate
+	}
+	return entry
+}
+
+// createTags creates the local tags one repository at a time. If any fails,
+// the tags created so far are deleted again.
+func createTags(entries []tagEntry, opts tagOptions) error {
+	var created []string
+	for i := range entries {
+		entry := &entries[i]
+		if entry.State != tagCreate {
+			continue
+		}
+		if err := git.CreateTag(entry.Path, opts.tag, entry.Commit, opts.message, opts.sign); err != nil {
+			for _, path := range created {
+				if err := git.DeleteTag(path, opts.tag); err != nil {
+					verbosity.Debug("Rollback failed for %s: %v", path, err)
+				}
+			}
+			return fmt.Errorf("%s: %w (no tags were created)", entry.Repo.FullPath, err)
+		}
+		created = append(created, entry.Path)
+	}
+	return nil
+}
+
+func pushTags(entries []tagEntry, tag string, jobs int) {
+	var tasks []runner.Task
+	for i := range entries {
+		entry := &entries[i]
+		if entry.State != tagCreate && entry.State != tagPushOnly {
+			continue
+		}
+		tasks = append(tasks, func(w io.Writer) error {
+			var output bytes.Buffer
+			if err := git.PushTag(entry.Path, tag, &output, &output); err != nil {
+				entry.Err = withOutput(err, output.String())
+				return entry.Err
+			}
+			entry.Pushed = true
+			return nil
+		})
+	}
+	runner.New(jobs).Run(tasks, io.Discard)
+}
+
+func displayTagPlan(w io.Writer, entries []tagEntry, tag string) {
+	for _, entry := range entries {
+		switch entry.State {
+		case tagCreate:
+			fmt.Fprintf(w, "🏷️  %s - %s\n", entry.Repo.FullPath, i18n.T("tag.will_create", shortCommit(entry.Commit)))
+		case tagPushOnly:
+			fmt.Fprintf(w, "🏷️  %s - %s\n", entry.Repo.FullPath, i18n.T("tag.will_push", shortCommit(entry.Commit)))
+		case tagAlready:
+			fmt.Fprintf(w, "✅ %s - %s\n", entry.Repo.FullPath, i18n.T("tag.already", tag))
+		case tagConflict:
+			fmt.Fprintf(w, "⚠️  %s - %s\n", entry.Repo.FullPath, i18n.T("tag.conflict", tag, shortCommit(entry.Other), shortCommit(entry.Commit)))
+		case tagFailed:
+			fmt.Fprintf(w, "❌ %s - %s\n", entry.Repo.FullPath, i18n.T("tag.failed", redact.Error(entry.Err)))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// displayTagSummary prints the outcome and returns how many pushes failed
+func displayTagSummary(w io.Writer, entries []tagEntry, opts tagOptions) int {
+	tagged, already, failed := 0, 0, 0
+	for _, entry := range entries {
+		switch {
+		case entry.State == tagAlready:
+			already++
+		case entry.Err != nil:
+			failed++
+			fmt.Fprintf(w, "❌ %s - %s\n", entry.Repo.FullPath, i18n.T("tag.push_failed", redact.Error(entry.Err)))
+		case entry.Pushed || !opts.push:
+			tagged++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, i18n.T("tag.summary", opts.tag, tagged, already, failed))
+	return failed
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/scm"
+)
+
+func TestClassifyTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote string
+		local  string
+		want   tagState
+	}{
+		{"no tag", "", "", tagCreate},
+		{"pushed at the same commit", "abc", "abc", tagAlready},
+		{"only on the remote", "abc", "", tagAlready},
+		{"only local", "", "abc", tagPushOnly},
+		{"remote at another commit", "def", "", tagConflict},
+		{"local at another commit", "", "def", tagConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := classifyTag(tagEntry{Commit: "abc"}, tt.remote, tt.local)
+			if entry.State != tt.want {
+				t.Errorf("classifyTag() = %d, want %d", entry.State, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAndPushTags(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	cfg, repos := setupSyncFixture(t)
+	cloned := clonedRepositories(cfg, repos)
+	if len(cloned) != 2 {
+		t.Fatalf("Expected 2 cloned repositories, got %d", len(cloned))
+	}
+	opts := tagOptions{tag: "v1.0.0", message: "Release 1.0.0", push: true}
+
+	entries := planTags(cfg, cloned, opts, 2)
+	for _, entry := range entries {
+		if entry.State != tagCreate || entry.Err != nil {
+			t.Fatalf("Unexpected plan for %s: %+v", entry.Repo.FullPath, entry)
+		}
+	}
+
+	if err := createTags(entries, opts); err != nil {
+		t.Fatalf("createTags failed: %v", err)
+	}
+	pushTags(entries, opts.tag, 2)
+
+	var buf bytes.Buffer
+	if failed := displayTagSummary(&buf, entries, opts); failed != 0 {
+		t.Fatalf("Expected no failures:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "2 tagged, 0 already tagged, 0 failed") {
+		t.Errorf("Unexpected summary:\n%s", buf.String())
+	}
+	for _, entry := range entries {
+		if commit, err := git.RemoteTagCommit(entry.Path, opts.tag); err != nil || commit != entry.Commit {
+			t.Errorf("Expected %s to be pushed for %s, got %q, %v", opts.tag, entry.Repo.FullPath, commit, err)
+		}
+	}
+
+	// Running again reports the tags as already there
+	for _, entry := range planTags(cfg, cloned, opts, 1) {
+		if entry.State != tagAlready {
+			t.Errorf("Expected %s to be tagged already, got %+v", entry.Repo.FullPath, entry)
+		}
+	}
+}
+
+func TestCreateTags_RollsBack(t *testing.T) {
+	cfg, repos := setupSyncFixture(t)
+	cloned := clonedRepositories(cfg, repos)
+	entries := planTags(cfg, cloned, tagOptions{tag: "v2", push: false}, 1)
+	entries = append(entries, tagEntry{Repo: &scm.Repository{FullPath: "group/broken"}, Path: t.TempDir(), Commit: entries[0].Commit})
+
+	if err := createTags(entries, tagOptions{tag: "v2"}); err == nil {
+		t.Fatal("Expected createTags to fail")
+	}
+	for _, entry := range entries[:2] {
+		if commit := git.LocalTagCommit(entry.Path, "v2"); commit != "" {
+			t.Errorf("Expected the tag in %s to be rolled back", entry.Repo.FullPath)
+		}
+	}
+}
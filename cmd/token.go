@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/httpclient"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var configTokenCmd = &cobra.Command{
+	Use:   "token <provider-name>",
+	Short: "Create an access token in the browser and store it",
+	Long: `Open the page of the provider's instance where access tokens are created,
+with the token name and the scopes gitstuff needs already filled in, then read
+the token you paste and check it against the API before storing it.
+
+A token the provider rejects is asked for again. A token that lacks a needed
+scope is only stored after confirming. The new token replaces the provider's
+current one.
+
+Examples:
+  gitstuff config token work             # Open the browser and store the new token
+  gitstuff config token work --print     # Print the URL instead of opening it
+  gitstuff config token work --keyring   # Store the token in the OS keychain`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigToken,
+}
+
+func init() {
+	configCmd.AddCommand(configTokenCmd)
+	configTokenCmd.Flags().Bool("print", false, "Print the token creation URL instead of opening it in the browser")
+	configTokenCmd.Flags().Bool("keyring", false, "Store the token in the OS keychain instead of the config file")
+}
+
+// maxTokenAttempts is how often a rejected token may be pasted again
+const maxTokenAttempts = 3
+
+// tokenScopes are the scopes requested for new tokens: enough to list,
+// clone and pull repositories
+var tokenScopes = map[string][]string{
+	"gitlab": {"read_api", "read_repository"},
+	"github": {"repo", "read:org"},
+}
+
+// gitlabUserSettingsVersion is the GitLab version that moved the access
+// token page from the profile to the user settings
+const gitlabUserSettingsVersion = "16.7"
+
+func runConfigToken(cmd *cobra.Command, args []string) error {
+	if err := checkConfigWritable(); err != nil {
+		return err
+	}
+	stored, err := config.ReadStored()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	providers, err := selectProviders(stored.Providers, args)
+	if err != nil {
+		return err
+	}
+	providerConfig := providers[0]
+	printOnly, _ := cmd.Flags().GetBool("print")
+	useKeyring, _ := cmd.Flags().GetBool("keyring")
+
+	token, err := createToken(commandContext(cmd), providerConfig, recordedVersion(stored, providerConfig), printOnly,
+		bufio.NewReader(os.Stdin), tokenClient(stored.HTTP.UserAgentSuffix))
+	if err != nil {
+		return err
+	}
+
+	err = config.UpdateProvider(providerConfig.Name, func(provider *config.ProviderConfig) {
+		provider.Token = token
+		provider.TokenEnv, provider.TokenCmd = "", ""
+		if useKeyring {
+			provider.TokenSource = config.TokenSourceKeyring
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ %s\n", i18n.T("token.stored", providerConfig.Name))
+	return nil
+}
+
+// tokenClient creates the clients that check pasted tokens, bypassing the
+// response cache like the other health checks
+func tokenClient(userAgentSuffix string) func(config.ProviderConfig) (scm.Client, error) {
+	agent := userAgent(userAgentSuffix)
+	return func(providerConfig config.ProviderConfig) (scm.Client, error) {
+		return createClientWithOptions(providerConfig, httpclient.Options{
+			Insecure:  providerConfig.Insecure,
+			UserAgent: agent,
+		})
+	}
+}
+
+// recordedVersion returns the instance version recorded for the provider,
+// or an empty string when it was never checked
+func recordedVersion(cfg *config.Config, providerConfig config.ProviderConfig) string {
+	path, err := versionRecordsPath(cfg)
+	if err != nil {
+		return ""
+	}
+	return loadVersionRecords(path)[providerConfig.URL].Version
+}
+
+// tokenCreationURL returns the page of the instance where a personal access
+// token with the scopes gitstuff needs can be created, with the form filled in
+func tokenCreationURL(providerType, rawURL, version string) (string, error) {
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		rawURL = "https://" + rawURL
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if base.Host == "" {
+		return "", fmt.Errorf("URL must have a valid host")
+	}
+	base.RawQuery, base.Fragment = "", ""
+	scopes := strings.Join(tokenScopes[providerType], ",")
+
+	switch providerType {
+	case "gitlab":
+		page := "/-/user_settings/personal_access_tokens"
+		if version != "" && !scm.VersionAtLeast(version, gitlabUserSettingsVersion) {
+			page = "/-/profile/personal_access_tokens"
+		}
+		base.Path = strings.TrimSuffix(strings.TrimSuffix(base.Path, "/"), "/api/v4") + page
+		base.RawQuery = url.Values{"name": {"gitstuff"}, "scopes": {scopes}}.Encode()
+	case "github":
+		// github.com and Enterprise Server share the page; API URLs such as
+		// https://ghe.example.com/api/v3 point at the same host
+		base.Path = "/settings/tokens/new"
+		base.RawQuery = url.Values{"description": {"gitstuff"}, "scopes": {scopes}}.Encode()
+	default:
+		return "", fmt.Errorf("unsupported provider type: %s", providerType)
+	}
+	return base.String(), nil
+}
+
+// createToken walks the user through creating a token for the provider in
+// the browser and returns the pasted token once the provider accepts it
+func createToken(ctx context.Context, providerConfig config.ProviderConfig, version string, printOnly bool, reader *bufio.Reader, newClient func(config.ProviderConfig) (scm.Client, error)) (string, error) {
+	tokenURL, err := tokenCreationURL(providerConfig.Type, providerConfig.URL, version)
+	if err != nil {
+		return "", err
+	}
+	scopes := strings.Join(tokenScopes[providerConfig.Type], ", ")
+	if printOnly || openURL(tokenURL) != nil {
+		fmt.Fprintf(stdout, "%s\n  %s\n", i18n.T("token.create_at", scopes), tokenURL)
+	} else {
+		fmt.Fprintf(stdout, "🌐 %s\n", i18n.T("token.opened", tokenURL))
+		fmt.Fprintf(stdout, "   %s\n", i18n.T("token.create_there", scopes))
+	}
+
+	for attempt := 1; ; attempt++ {
+		fmt.Fprintf(stdout, "%s ", i18n.T("token.paste"))
+		token, err := readToken(reader)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		if token == "" {
+			return "", errors.New("no token was entered")
+		}
+
+		providerConfig.Token = token
+		user, warning, err := validateToken(ctx, providerConfig, newClient)
+		switch kind := scm.ClassifyError(err); {
+		case err == nil:
+		case kind == scm.ErrorUnauthorized || kind == scm.ErrorForbidden:
+			fmt.Fprintf(stdout, "❌ %s\n", providerErrorText(err))
+			if attempt == maxTokenAttempts {
+				return "", fmt.Errorf("the token was rejected %d times", maxTokenAttempts)
+			}
+			continue
+		default:
+			return "", fmt.Errorf("failed to check the token: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "✅ %s\n", i18n.T("token.works", user))
+		if warning == "" {
+			return token, nil
+		}
+		fmt.Fprintf(stdout, "⚠️  %s\n", warning)
+		fmt.Fprintf(stdout, "%s (y/N): ", i18n.T("token.use_anyway"))
+		response, _ := reader.ReadString('\n')
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response == "y" || response == "yes" {
+			return token, nil
+		}
+		if attempt == maxTokenAttempts {
+			return "", fmt.Errorf("no token with the %s scopes was entered", scopes)
+		}
+	}
+}
+
+// validateToken checks a token against the provider and returns the user it
+// belongs to and a warning when it lacks a scope gitstuff needs
+func validateToken(ctx context.Context, providerConfig config.ProviderConfig, newClient func(config.ProviderConfig) (scm.Client, error)) (user, warning string, err error) {
+	client, err := newClient(providerConfig)
+	if err != nil {
+		return "", "", err
+	}
+	checker, ok := client.(scm.HealthChecker)
+	if !ok {
+		return "", "", fmt.Errorf("provider type %s cannot check tokens", providerConfig.Type)
+	}
+	health, err := checker.CheckHealth(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return health.User, scopeWarning(providerConfig.Type, health.Scopes), nil
+}
+
+// readToken reads a token without echoing it when stdin is a terminal, and
+// otherwise reads a line so tokens can be piped in
+func readToken(reader *bufio.Reader) (string, error) {
+	if term.IsTerminal(int(syscall.Stdin)) {
+		tokenBytes, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Fprintln(stdout)
+		return strings.TrimSpace(string(tokenBytes)), err
+	}
+	line, err := reader.ReadString('\n')
+	fmt.Fprintln(stdout)
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+func TestTokenCreationURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		providerType string
+		url          string
+		version      string
+		want         string
+	}{
+		{
+			name:         "gitlab.com",
+			providerType: "gitlab",
+			url:          "https://gitlab.com",
+			want:         "https://gitlab.com/-/user_settings/personal_access_tokens?name=gitstuff&scopes=read_api%2Cread_repository",
+		},
+		{
+			name:         "self-hosted without scheme",
+			providerType: "gitlab",
+			url:          "git.example.com/",
+			version:      "17.2.1-ee",
+			want:         "https://git.example.com/-/user_settings/personal_access_tokens?name=gitstuff&scopes=read_api%2Cread_repository",
+		},
+		{
+			name:         "older gitlab",
+			providerType: "gitlab",
+			url:          "https://git.example.com/gitlab/api/v4",
+			version:      "15.11.3",
+			want:         "https://git.example.com/gitlab/-/profile/personal_access_tokens?name=gitstuff&scopes=read_api%2Cread_repository",
+		},
+		{
+			name:         "github.com",
+			providerType: "github",
+			url:          "https://github.com",
+			want:         "https://github.com/settings/tokens/new?description=gitstuff&scopes=repo%2Cread%3Aorg",
+		},
+		{
+			name:         "github enterprise api url",
+			providerType: "github",
+			url:          "https://ghe.example.com/api/v3",
+			want:         "https://ghe.example.com/settings/tokens/new?description=gitstuff&scopes=repo%2Cread%3Aorg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenCreationURL(tt.providerType, tt.url, tt.version)
+			if err != nil {
+				t.Fatalf("tokenCreationURL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("tokenCreationURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tokenCreationURL("gitea", "https://gitea.example.com", ""); err == nil {
+		t.Error("Expected an error for an unsupported provider type")
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	tokens := map[string]*mockHealthClient{
+		"good": {
+			mockSCMClient: mockSCMClient{providerType: "gitlab"},
+			health:        &scm.ProviderHealth{User: "jdoe", Scopes: []string{"read_api", "read_repository"}},
+		},
+		"narrow": {
+			mockSCMClient: mockSCMClient{providerType: "gitlab"},
+			health:        &scm.ProviderHealth{User: "jdoe", Scopes: []string{"read_repository"}},
+		},
+		"revoked": {
+			mockSCMClient: mockSCMClient{providerType: "gitlab"},
+			err:           &scm.APIError{StatusCode: http.StatusUnauthorized, Err: errors.New("401 Unauthorized")},
+		},
+		"offline": {
+			mockSCMClient: mockSCMClient{providerType: "gitlab"},
+			err:           errors.New("connection refused"),
+		},
+	}
+	newClient := func(p config.ProviderConfig) (scm.Client, error) {
+		return tokens[p.Token], nil
+	}
+
+	var opened []string
+	original := openURL
+	openURL = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	defer func() { openURL = original }()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "accepted", input: "good\n", want: "good"},
+		{name: "rejected then accepted", input: "revoked\ngood\n", want: "good"},
+		{name: "missing scope declined", input: "narrow\nn\ngood\n", want: "good"},
+		{name: "missing scope confirmed", input: "narrow\ny\n", want: "narrow"},
+		{name: "rejected every time", input: "revoked\nrevoked\nrevoked\n", wantErr: "rejected 3 times"},
+		{name: "unreachable", input: "offline\n", wantErr: "connection refused"},
+		{name: "nothing pasted", input: "\n", wantErr: "no token"},
+	}
+
+	provider := config.ProviderConfig{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestProviders(t, nil, nil)
+			opened = nil
+
+			got, err := createToken(context.Background(), provider, "", false, bufio.NewReader(strings.NewReader(tt.input)), newClient)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("createToken() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("createToken() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("createToken() = %q, want %q", got, tt.want)
+			}
+			if len(opened) != 1 || !strings.Contains(opened[0], "gitlab.example.com/-/user_settings/personal_access_tokens") {
+				t.Errorf("Expected the token page to be opened once, got %v", opened)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gitstuff/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the config file for mistakes",
+	Long: `Check a config file, by default the one in use, for unknown keys such as
+misspelled settings, values of the wrong type, duplicate keys and provider
+names, and base directories that overlap the archive directory or the base
+directory of another profile. Every problem is reported with its line.
+
+Other commands refuse to load a config file with these problems, instead of
+silently ignoring a typo.
+
+Examples:
+  gitstuff config validate
+  gitstuff --profile work config validate
+  gitstuff config validate ./ci-gitstuff.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigValidate,
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	configPath, err := config.FilePath()
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		configPath = args[0]
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	problems := config.Validate(data)
+	if len(problems) == 0 {
+		problems = baseDirConflicts(configPath, data)
+	}
+	return reportProblems(stdout, configPath, problems)
+}
+
+// baseDirConflicts reports the default configuration and profiles, other
+// than the one at configPath, whose base directory overlaps its own
+func baseDirConflicts(configPath string, data []byte) []config.Problem {
+	baseDir, err := configBaseDir(configPath)
+	if err != nil {
+		return nil
+	}
+
+	type otherConfig struct{ label, path string }
+	var others []otherConfig
+	if path, err := config.DefaultFilePath(); err == nil {
+		others = append(others, otherConfig{"the default configuration", path})
+	}
+	names, _ := config.Profiles()
+	for _, name := range names {
+		if path, err := config.ProfilePath(name); err == nil {
+			others = append(others, otherConfig{"profile " + name, path})
+		}
+	}
+
+	var problems []config.Problem
+	for _, other := range others {
+		if sameFile(other.path, configPath) {
+			continue
+		}
+		otherDir, err := configBaseDir(other.path)
+		if err != nil || !config.DirsOverlap(baseDir, otherDir) {
+			continue
+		}
+		problems = append(problems, config.Problem{
+			Line:    config.KeyLine(data, "local", "base_dir"),
+			Message: fmt.Sprintf("base_dir %s conflicts with %s of %s (%s): profiles must not share clones", baseDir, otherDir, other.label, other.path),
+		})
+	}
+	return problems
+}
+
+// configBaseDir returns the base directory the config file at path uses
+func configBaseDir(path string) (string, error) {
+	cfg, err := config.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Local.BaseDir != "" {
+		return expandHome(cfg.Local.BaseDir), nil
+	}
+	return config.DefaultBaseDir()
+}
+
+// sameFile reports whether a and b name the same existing file
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// reportProblems writes each problem as path:line: message, in the form
+// editors jump to, and fails when there are any
+func reportProblems(w io.Writer, configPath string, problems []config.Problem) error {
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "✅ %s is valid\n", configPath)
+		return nil
+	}
+	for _, problem := range problems {
+		if problem.Line > 0 {
+			fmt.Fprintf(w, "❌ %s:%d: %s\n", configPath, problem.Line, problem.Message)
+		} else {
+			fmt.Fprintf(w, "❌ %s: %s\n", configPath, problem.Message)
+		}
+	}
+	return fmt.Errorf("found %d problems in %s", len(problems), configPath)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommand_ConfigValidate(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		resetFlags(rootCmd)
+		return runCommand(t, nil, nil, args...)
+	}
+	home := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(home, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	valid := write("valid.yaml", "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n")
+	out, err := run("config", "validate", valid)
+	if err != nil || !strings.Contains(out, "is valid") {
+		t.Errorf("Expected %s to be valid, got %v:\n%s", valid, err, out)
+	}
+
+	typo := write("typo.yaml", "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n    protocl: ssh\n")
+	out, err = run("config", "validate", typo)
+	if err == nil {
+		t.Error("Expected an error for an unknown key")
+	}
+	if want := typo + `:5: unknown key "protocl" (did you mean "protocol"?)`; !strings.Contains(out, want) {
+		t.Errorf("Expected %q, got:\n%s", want, out)
+	}
+}
+
+func TestBaseDirConflicts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GITSTUFF_CONFIG", "")
+	dir := filepath.Join(home, ".config", "gitstuff")
+	if err := os.MkdirAll(filepath.Join(dir, "profiles"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("local:\n  base_dir: ~/src\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	workPath := filepath.Join(dir, "profiles", "work.yaml")
+	work := []byte("providers: []\nlocal:\n  base_dir: ~/src/work\n")
+	if err := os.WriteFile(workPath, work, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := baseDirConflicts(workPath, work)
+	if len(problems) != 1 || problems[0].Line != 3 || !strings.Contains(problems[0].Message, "the default configuration") {
+		t.Errorf("Expected a conflict with the default configuration on line 3, got %v", problems)
+	}
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [path]",
+	Short: "Check local repositories and mirrors for corruption",
+	Long: `Scan the base directory (or the given path) for git repositories, including
+bare mirrors and backups, and check their integrity.
+
+With --fsck every repository is checked with 'git fsck --no-dangling'. The
+command exits with a non-zero status when any repository is corrupt, so it can
+be run from cron or a CI schedule.
+
+Examples:
+  gitstuff verify --fsck                    # Check the configured base directory
+  gitstuff verify --fsck /backups/mirrors   # Check a directory of bare mirrors
+  gitstuff verify --fsck -j 8               # Check 8 repositories at a time`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().Bool("fsck", false, "Check object integrity with git fsck --no-dangling")
+	verifyCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to check in parallel")
+}
+
+type fsckResult struct {
+	Path   string
+	Output string
+	Err    error
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
+	runFsck, _ := cmd.Flags().GetBool("fsck")
+	if !runFsck {
+		return fmt.Errorf("no checks selected (use --fsck)")
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	var root string
+	if len(args) == 1 {
+		root = args[0]
+	} else {
+		cfg, err := loadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first or pass a path)", err)
+		}
+		root = cfg.Local.BaseDir
+	}
+
+	verbosity.Debug("Scanning %s for git repositories", root)
+	repoPaths, err := git.FindRepositoriesWithBare(root)
+	if err != nil {
+		return err
+	}
+	verbosity.DebugTiming(start, "Found %d repositories", len(repoPaths))
+
+	results := fsckRepositories(repoPaths, jobs)
+	corrupt := displayFsckResults(stdout, root, results)
+
+	verbosity.DebugTiming(start, "Verification completed")
+	if corrupt > 0 {
+		return fmt.Errorf("%d of %d repositories failed verification", corrupt, len(results))
+	}
+	return nil
+}
+
+func fsckRepositories(repoPaths []string, jobs int) []fsckResult {
+	results := make([]fsckResult, len(repoPaths))
+	tasks := make([]runner.Task, len(repoPaths))
+	for i, repoPath := range repoPaths {
+		tasks[i] = func(w io.Writer) error {
+			output, err := git.Fsck(repoPath)
+			results[i] = fsckResult{Path: repoPath, Output: output, Err: err}
+			return err
+		}
+	}
+
+	runner.New(jobs).Run(tasks, io.Discard)
+	return results
+}
+
+// displayFsckResults prints one line per repository, with git's report
+// indented below repositories that are corrupt or have warnings, and returns
+// the number of corrupt repositories
+func displayFsckResults(w io.Writer, root string, results []fsckResult) int {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("verify.header", len(results), root))
+
+	corrupt := 0
+	warnings := 0
+	for _, result := range results {
+		name := result.Path
+		if rel, err := filepath.Rel(root, result.Path); err == nil {
+			name = rel
+		}
+
+		switch {
+		case result.Err != nil:
+			corrupt++
+			fmt.Fprintf(w, "❌ %s - %s\n", name, i18n.T("verify.corrupt", redact.Error(result.Err)))
+		case result.Output != "":
+			warnings++
+			fmt.Fprintf(w, "⚠️  %s - %s\n", name, i18n.T("verify.warnings"))
+		default:
+			fmt.Fprintf(w, "✅ %s - %s\n", name, i18n.T("verify.ok"))
+			continue
+		}
+
+		for _, line := range strings.Split(result.Output, "\n") {
+			if line != "" {
+				fmt.Fprintf(w, "   %s\n", line)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%s\n", i18n.T("verify.summary", len(results), corrupt, warnings))
+	return corrupt
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFsckRepositories(t *testing.T) {
+	root := t.TempDir()
+	healthy := createRemoteRepo(t, filepath.Join(root, "mirrors", "healthy.git"))
+	broken := createRemoteRepo(t, filepath.Join(root, "mirrors", "broken.git"))
+
+	objects, _ := filepath.Glob(filepath.Join(broken, "objects", "??", "*"))
+	for _, object := range objects {
+		if err := os.Remove(object); err != nil {
+			t.Fatalf("Failed to remove object: %v", err)
+		}
+	}
+
+	results := fsckRepositories([]string{healthy, broken}, 2)
+	if results[0].Err != nil {
+		t.Errorf("Expected healthy mirror to pass, got %v: %s", results[0].Err, results[0].Output)
+	}
+	if results[1].Err == nil {
+		t.Error("Expected mirror with missing objects to fail")
+	}
+}
+
+func TestDisplayFsckResults(t *testing.T) {
+	root := "/backups"
+	results := []fsckResult{
+		{Path: "/backups/group/ok.git"},
+		{Path: "/backups/group/odd.git", Output: "warning in tree 1234: zeroPaddedFilemode"},
+		{Path: "/backups/group/bad.git", Output: "missing blob 5678\nmissing tree 9abc", Err: errors.New("git fsck failed: exit status 2")},
+	}
+
+	var buf bytes.Buffer
+	corrupt := displayFsckResults(&buf, root, results)
+	output := buf.String()
+
+	if corrupt != 1 {
+		t.Errorf("Expected 1 corrupt repository, got %d", corrupt)
+	}
+	for _, want := range []string{
+		"Verifying 3 repositories in /backups with git fsck:",
+		"✅ group/ok.git - ok",
+		"⚠️  group/odd.git - ok with warnings",
+		"   warning in tree 1234: zeroPaddedFilemode",
+		"❌ group/bad.git - corrupt (git fsck failed: exit status 2)",
+		"   missing blob 5678",
+		"   missing tree 9abc",
+		"Summary: 3 repositories checked, 1 corrupt, 1 with warnings",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
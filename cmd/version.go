@@ -13,7 +13,7 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of gitstuff",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("gitstuff version %s\n", version)
+		fmt.Fprintf(stdout, "gitstuff version %s\n", version)
 	},
 }
 
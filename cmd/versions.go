@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+)
+
+const (
+	// versionCheckInterval is how long a recorded instance version is
+	// trusted, so upgrades are noticed without asking on every run
+	versionCheckInterval = 7 * 24 * time.Hour
+	versionCheckTimeout  = 5 * time.Second
+)
+
+// providerVersion is the version an instance reported when last checked.
+// Version is empty when it could not be found out.
+type providerVersion struct {
+	Type      string    `json:"type"`
+	Version   string    `json:"version"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// versionRecords are the recorded instance versions by provider URL
+type versionRecords map[string]providerVersion
+
+func versionRecordsPath(cfg *config.Config) (string, error) {
+	return cfg.StatePath("versions.json")
+}
+
+func loadVersionRecords(path string) versionRecords {
+	records := make(versionRecords)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return records
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		verbosity.Debug("Ignoring unreadable version records %s: %v", path, err)
+		return make(versionRecords)
+	}
+	return records
+}
+
+func (r versionRecords) save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// checkProviderVersions asks instances that were not seen before, or not
+// for a while, for their version, records it and warns when it is older
+// than the clients support. Otherwise newer API endpoints would fail later
+// with nothing more than a 404.
+func checkProviderVersions(ctx context.Context, cfg *config.Config, providers []config.ProviderConfig, clients []scm.Client) {
+	path, err := versionRecordsPath(cfg)
+	if err != nil {
+		verbosity.Debug("Not checking provider versions: %v", err)
+		return
+	}
+	records := loadVersionRecords(path)
+
+	changed := false
+	for i, providerConfig := range providers {
+		reporter, ok := clients[i].(scm.VersionReporter)
+		if !ok {
+			continue
+		}
+		record, seen := records[providerConfig.URL]
+		if seen && record.Type == providerConfig.Type && time.Since(record.CheckedAt) < versionCheckInterval {
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+		version, err := reporter.ServerVersion(checkCtx)
+		cancel()
+		if err != nil {
+			// Recorded anyway, so an instance that will not tell is not
+			// asked again on every run
+			verbosity.Debug("Could not get the version of %s: %v", providerConfig.Name, err)
+		} else if version != "" {
+			verbosity.Debug("%s runs version %s", providerConfig.Name, version)
+		}
+		records[providerConfig.URL] = providerVersion{Type: providerConfig.Type, Version: version, CheckedAt: time.Now().UTC()}
+		changed = true
+
+		if warning := versionWarning(providerConfig.Type, version); warning != "" {
+			fmt.Fprintf(stderr, "⚠️  %s: %s\n", providerConfig.Name, warning)
+		}
+	}
+
+	if changed {
+		if err := records.save(path); err != nil {
+			verbosity.Debug("Could not record provider versions: %v", err)
+		}
+	}
+}
+
+// versionWarning explains what to expect from an instance that is older
+// than the clients support, or returns an empty string
+func versionWarning(providerType, version string) string {
+	minimum, ok := scm.VersionSupported(providerType, version)
+	if ok {
+		return ""
+	}
+	return i18n.T("provider.version_unsupported", version, minimum)
+}
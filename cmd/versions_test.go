@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/output"
+	"gitstuff/internal/scm"
+)
+
+type versionedClient struct {
+	mockSCMClient
+	version string
+	calls   int
+}
+
+func (c *versionedClient) ServerVersion(ctx context.Context) (string, error) {
+	c.calls++
+	return c.version, nil
+}
+
+func TestCheckProviderVersions(t *testing.T) {
+	cfg := &config.Config{Cache: config.CacheConfig{Dir: t.TempDir()}}
+	providers := []config.ProviderConfig{
+		{Name: "old", Type: "gitlab", URL: "https://old.example.com"},
+		{Name: "new", Type: "gitlab", URL: "https://new.example.com"},
+		{Name: "plain", Type: "gitlab", URL: "https://plain.example.com"},
+	}
+	oldClient := &versionedClient{mockSCMClient: mockSCMClient{providerType: "gitlab"}, version: "12.10.14-ee"}
+	newClient := &versionedClient{mockSCMClient: mockSCMClient{providerType: "gitlab"}, version: "17.5.0-ee"}
+	clients := []scm.Client{oldClient, newClient, &mockSCMClient{providerType: "gitlab"}}
+
+	var buf bytes.Buffer
+	oldStderr := stderr
+	stderr = output.New(&buf)
+	defer func() { stderr = oldStderr }()
+
+	checkProviderVersions(context.Background(), cfg, providers, clients)
+	if got := buf.String(); !strings.Contains(got, "old: version 12.10.14-ee is older than 13.0") || strings.Contains(got, "new:") {
+		t.Errorf("Expected a warning for the old instance only, got %q", got)
+	}
+
+	// Recorded versions are not asked for again until they are stale
+	buf.Reset()
+	checkProviderVersions(context.Background(), cfg, providers, clients)
+	if oldClient.calls != 1 || newClient.calls != 1 || buf.Len() != 0 {
+		t.Errorf("Expected recorded versions to be reused, got %d and %d calls and %q", oldClient.calls, newClient.calls, buf.String())
+	}
+
+	path, _ := versionRecordsPath(cfg)
+	records := loadVersionRecords(path)
+	if records["https://new.example.com"].Version != "17.5.0-ee" {
+		t.Errorf("Unexpected records %+v", records)
+	}
+	stale := records["https://old.example.com"]
+	stale.CheckedAt = time.Now().Add(-versionCheckInterval - time.Hour)
+	records["https://old.example.com"] = stale
+	if err := records.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	checkProviderVersions(context.Background(), cfg, providers, clients)
+	if oldClient.calls != 2 || newClient.calls != 1 {
+		t.Errorf("Expected only the stale record to be checked again, got %d and %d calls", oldClient.calls, newClient.calls)
+	}
+}
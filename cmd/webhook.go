@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var webhookCmd = &cobra.Command{
+	Use:     "webhook",
+	Aliases: []string{"hooks"},
+	Short:   "List, register and remove webhooks across repositories",
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list [group]",
+	Short: "List the webhooks of every repository",
+	Long: `List the webhooks of every repository (or every repository in a group)
+through the provider APIs, to see where pushes are sent before adding or
+removing webhooks in bulk. Repositories without webhooks are left out.
+
+Examples:
+  gitstuff webhook list
+  gitstuff hooks list backend --url https://ci.example.com/hook`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWebhookList,
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add <url> [group]",
+	Short: "Register a push webhook on every repository",
+	Long: `Register a webhook pointing at url on every repository (or every repository
+in a group) through the provider APIs. The webhook fires on branch and tag
+pushes, which is what push-triggered mirroring needs. Repositories that
+already have a webhook for url are left alone, so the command can be re-run
+as repositories are added.
+
+The secret is sent as the X-Gitlab-Token header on GitLab and used to sign
+payloads on GitHub. It is read from --secret or the GITSTUFF_WEBHOOK_SECRET
+environment variable.
+
+Examples:
+  gitstuff webhook add https://mirror.example.com/hook
+  gitstuff webhook add https://mirror.example.com/hook backend --dry-run`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runWebhookAdd,
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove <url> [group]",
+	Short: "Remove the webhooks pointing at a URL from every repository",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runWebhookRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookListCmd, webhookAddCmd, webhookRemoveCmd)
+	webhookListCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to query in parallel")
+	webhookListCmd.Flags().String("provider", "", "Only repositories from the provider with this name or type")
+	webhookListCmd.Flags().String("url", "", "Only webhooks pointing at this URL")
+	addRepoFilterFlags(webhookListCmd)
+	webhookAddCmd.Flags().String("secret", "", "Secret token for the webhook (default: $GITSTUFF_WEBHOOK_SECRET)")
+	for _, cmd := range []*cobra.Command{webhookAddCmd, webhookRemoveCmd} {
+		cmd.Flags().BoolP("dry-run", "n", false, "Show what would change without calling the provider")
+		cmd.Flags().IntP("jobs", "j", 4, "Number of repositories to update in parallel")
+		cmd.Flags().String("provider", "", "Only repositories from the provider with this name or type")
+		addRepoFilterFlags(cmd)
+	}
+}
+
+type webhookState int
+
+const (
+	webhookAdded webhookState = iota
+	webhookExists
+	webhookRemoved
+	webhookAbsent
+	webhookUnsupported
+	webhookFailed
+)
+
+type webhookResult struct {
+	Repo  *scm.Repository
+	State webhookState
+	Count int // Webhooks removed
+	Err   error
+}
+
+// webhookTarget is a repository along with the client that manages it
+type webhookTarget struct {
+	repo    *scm.Repository
+	manager scm.WebhookManager // nil when the provider cannot manage webhooks
+}
+
+func runWebhookAdd(cmd *cobra.Command, args []string) error {
+	secret, _ := cmd.Flags().GetString("secret")
+	if secret == "" {
+		secret = os.Getenv("GITSTUFF_WEBHOOK_SECRET")
+	}
+	return runWebhook(cmd, args, func(ctx context.Context, target webhookTarget, hookURL string, dryRun bool) webhookResult {
+		return addWebhook(ctx, target, hookURL, secret, dryRun)
+	})
+}
+
+func runWebhookRemove(cmd *cobra.Command, args []string) error {
+	return runWebhook(cmd, args, removeWebhook)
+}
+
+func runWebhook(cmd *cobra.Command, args []string, apply func(context.Context, webhookTarget, string, bool) webhookResult) error {
+	start := time.Now()
+
+	hookURL := args[0]
+	if parsed, err := url.Parse(hookURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q (expected http:// or https://)", hookURL)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	provider, _ := cmd.Flags().GetString("provider")
+	if !dryRun {
+		if err := checkWritable("change webhooks"); err != nil {
+			return err
+		}
+	}
+
+	groupPath := ""
+	if len(args) == 2 {
+		groupPath = args[1]
+	}
+	ctx := commandContext(cmd)
+	targets, err := collectWebhookTargets(ctx, cmd, cfg, provider, groupPath)
+	if err != nil {
+		return err
+	}
+
+	results := make([]webhookResult, len(targets))
+	tasks := make([]runner.Task, len(targets))
+	for i, target := range targets {
+		tasks[i] = func(w io.Writer) error {
+			results[i] = apply(ctx, target, hookURL, dryRun)
+			return results[i].Err
+		}
+	}
+	runner.New(jobs).Run(tasks, io.Discard)
+	verbosity.DebugTiming(start, "Updated webhooks on %d repositories", len(results))
+
+	if failed := displayWebhookResults(stdout, results, hookURL, dryRun); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to update webhooks on %d repositories", failed)
+	}
+	return nil
+}
+
+// collectWebhookTargets returns the repositories selected by the provider,
+// group and filter flags of cmd
+func collectWebhookTargets(ctx context.Context, cmd *cobra.Command, cfg *config.Config, provider, groupPath string) ([]webhookTarget, error) {
+	clients, err := createClients(cfg)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := repoFilterFromFlags(cmd, cfg, clients)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := selectClients(cfg, clients, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []webhookTarget
+	for _, collected := range collectProviderRepositories(ctx, selected, groupPath, filter) {
+		manager, _ := scm.Unwrap(collected.client).(scm.WebhookManager)
+		for _, repo := range collected.repos {
+			targets = append(targets, webhookTarget{repo: repo, manager: manager})
+		}
+	}
+	if len(targets) == 0 && groupPath != "" {
+		return nil, fmt.Errorf("no repositories found in group '%s'", groupPath)
+	}
+	return targets, nil
+}
+
+func addWebhook(ctx context.Context, target webhookTarget, hookURL, secret string, dryRun bool) webhookResult {
+	result := webhookResult{Repo: target.repo}
+	if target.manager == nil {
+		result.State = webhookUnsupported
+		return result
+	}
+
+	hooks, err := target.manager.ListWebhooks(ctx, target.repo)
+	if err != nil {
+		result.State, result.Err = webhookFailed, err
+		return result
+	}
+	for _, hook := range hooks {
+		if hook.URL == hookURL {
+			result.State = webhookExists
+			return result
+		}
+	}
+
+	result.State = webhookAdded
+	if !dryRun {
+		if _, err := target.manager.CreateWebhook(ctx, target.repo, hookURL, secret); err != nil {
+			result.State, result.Err = webhookFailed, err
+		}
+	}
+	return result
+}
+
+func removeWebhook(ctx context.Context, target webhookTarget, hookURL string, dryRun bool) webhookResult {
+	result := webhookResult{Repo: target.repo, State: webhookAbsent}
+	if target.manager == nil {
+		result.State = webhookUnsupported
+		return result
+	}
+
+	hooks, err := target.manager.ListWebhooks(ctx, target.repo)
+	if err != nil {
+		result.State, result.Err = webhookFailed, err
+		return result
+	}
+	for _, hook := range hooks {
+		if hook.URL != hookURL {
+			continue
+		}
+		if !dryRun {
+			if err := target.manager.DeleteWebhook(ctx, target.repo, hook.ID); err != nil {
+				result.State, result.Err = webhookFailed, err
+				return result
+			}
+		}
+		result.State = webhookRemoved
+		result.Count++
+	}
+	return result
+}
+
+// displayWebhookResults lists the repositories that changed or failed and
+// returns how many failed
+func displayWebhookResults(w io.Writer, results []webhookResult, hookURL string, dryRun bool) int {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("webhook.header", hookURL, len(results)))
+
+	counts := make(map[webhookState]int)
+	for _, result := range results {
+		counts[result.State]++
+		switch result.State {
+		case webhookAdded:
+			key := "webhook.added"
+			if dryRun {
+				key = "webhook.would_add"
+			}
+			fmt.Fprintf(w, "➕ %s - %s\n", result.Repo.FullPath, i18n.T(key))
+		case webhookRemoved:
+			key := "webhook.removed"
+			if dryRun {
+				key = "webhook.would_remove"
+			}
+			fmt.Fprintf(w, "➖ %s - %s\n", result.Repo.FullPath, i18n.T(key, result.Count))
+		case webhookUnsupported:
+			fmt.Fprintf(w, "⚠️  %s - %s\n", result.Repo.FullPath, i18n.T("webhook.unsupported", result.Repo.Provider))
+		case webhookFailed:
+			fmt.Fprintf(w, "❌ %s - %s\n", result.Repo.FullPath, i18n.T("webhook.failed", redact.Error(result.Err)))
+		}
+	}
+
+	changed := counts[webhookAdded] + counts[webhookRemoved]
+	unchanged := counts[webhookExists] + counts[webhookAbsent]
+	key := "webhook.summary"
+	if dryRun {
+		key = "webhook.summary_dry_run"
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T(key, changed, unchanged, counts[webhookUnsupported], counts[webhookFailed]))
+	return counts[webhookFailed]
+}
+
+// webhookListing is the webhooks of one repository
+type webhookListing struct {
+	Repo        *scm.Repository
+	Hooks       []scm.Webhook
+	Unsupported bool
+	Err         error
+}
+
+func runWebhookList(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	cfg, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'gitstuff config' first)", err)
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+	provider, _ := cmd.Flags().GetString("provider")
+	hookURL, _ := cmd.Flags().GetString("url")
+
+	groupPath := ""
+	if len(args) == 1 {
+		groupPath = args[0]
+	}
+	ctx := commandContext(cmd)
+	targets, err := collectWebhookTargets(ctx, cmd, cfg, provider, groupPath)
+	if err != nil {
+		return err
+	}
+
+	listings := make([]webhookListing, len(targets))
+	tasks := make([]runner.Task, len(targets))
+	for i, target := range targets {
+		tasks[i] = func(w io.Writer) error {
+			listings[i] = webhookListing{Repo: target.repo, Unsupported: target.manager == nil}
+			if target.manager == nil {
+				return nil
+			}
+			hooks, err := target.manager.ListWebhooks(ctx, target.repo)
+			for _, hook := range hooks {
+				if hookURL == "" || hook.URL == hookURL {
+					listings[i].Hooks = append(listings[i].Hooks, hook)
+				}
+			}
+			listings[i].Err = err
+			return err
+		}
+	}
+	runner.New(jobs).Run(tasks, io.Discard)
+	verbosity.DebugTiming(start, "Listed webhooks of %d repositories", len(listings))
+
+	if failed := displayWebhookListings(stdout, listings); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to list webhooks of %d repositories", failed)
+	}
+	return nil
+}
+
+// displayWebhookListings lists the repositories with webhooks and returns
+// how many could not be listed
+func displayWebhookListings(w io.Writer, listings []webhookListing) int {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("webhook.list_header", len(listings)))
+
+	hooks, withHooks, unsupported, failed := 0, 0, 0, 0
+	for _, listing := range listings {
+		switch {
+		case listing.Unsupported:
+			unsupported++
+			continue
+		case listing.Err != nil:
+			failed++
+			fmt.Fprintf(w, "❌ %s - %s\n", listing.Repo.FullPath, i18n.T("webhook.failed", redact.Error(listing.Err)))
+			continue
+		case len(listing.Hooks) == 0:
+			continue
+		}
+		withHooks++
+		hooks += len(listing.Hooks)
+		fmt.Fprintf(w, "🪝 %s [%s]\n", listing.Repo.FullPath, listing.Repo.Provider)
+		for _, hook := range listing.Hooks {
+			fmt.Fprintf(w, "   %s  (id %s)\n", redact.String(hook.URL), hook.ID)
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("webhook.list_summary", hooks, withHooks, len(listings), unsupported, failed))
+	return failed
+}
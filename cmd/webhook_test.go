@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gitstuff/internal/config"
+	"gitstuff/internal/scm"
+)
+
+type fakeWebhookManager struct {
+	hooks   map[string][]scm.Webhook
+	listErr error
+	nextID  int
+}
+
+func (f *fakeWebhookManager) ListWebhooks(ctx context.Context, repo *scm.Repository) ([]scm.Webhook, error) {
+	return f.hooks[repo.FullPath], f.listErr
+}
+
+func (f *fakeWebhookManager) CreateWebhook(ctx context.Context, repo *scm.Repository, url, secret string) (scm.Webhook, error) {
+	f.nextID++
+	hook := scm.Webhook{ID: strconv.Itoa(f.nextID), URL: url}
+	f.hooks[repo.FullPath] = append(f.hooks[repo.FullPath], hook)
+	return hook, nil
+}
+
+func (f *fakeWebhookManager) DeleteWebhook(ctx context.Context, repo *scm.Repository, id string) error {
+	var kept []scm.Webhook
+	for _, hook := range f.hooks[repo.FullPath] {
+		if hook.ID != id {
+			kept = append(kept, hook)
+		}
+	}
+	f.hooks[repo.FullPath] = kept
+	return nil
+}
+
+func TestAddAndRemoveWebhook(t *testing.T) {
+	const hookURL = "https://mirror.example.com/hook"
+	manager := &fakeWebhookManager{hooks: map[string][]scm.Webhook{
+		"team/old": {{ID: "7", URL: hookURL}, {ID: "8", URL: "https://ci.example.com"}, {ID: "9", URL: hookURL}},
+	}}
+	fresh := webhookTarget{repo: &scm.Repository{FullPath: "team/new"}, manager: manager}
+	existing := webhookTarget{repo: &scm.Repository{FullPath: "team/old"}, manager: manager}
+
+	if result := addWebhook(context.Background(), fresh, hookURL, "s3cret", true); result.State != webhookAdded || len(manager.hooks["team/new"]) != 0 {
+		t.Errorf("Expected a dry run to add nothing, got %+v", result)
+	}
+	if result := addWebhook(context.Background(), fresh, hookURL, "s3cret", false); result.State != webhookAdded || len(manager.hooks["team/new"]) != 1 {
+		t.Errorf("Expected the webhook to be added, got %+v", result)
+	}
+	if result := addWebhook(context.Background(), existing, hookURL, "", false); result.State != webhookExists {
+		t.Errorf("Expected the existing webhook to be kept, got %+v", result)
+	}
+
+	if result := removeWebhook(context.Background(), existing, hookURL, false); result.State != webhookRemoved || result.Count != 2 {
+		t.Errorf("Expected both matching webhooks to be removed, got %+v", result)
+	}
+	if hooks := manager.hooks["team/old"]; len(hooks) != 1 || hooks[0].ID != "8" {
+		t.Errorf("Expected the other webhook to be kept, got %+v", hooks)
+	}
+	if result := removeWebhook(context.Background(), existing, hookURL, false); result.State != webhookAbsent {
+		t.Errorf("Expected nothing left to remove, got %+v", result)
+	}
+
+	unsupported := webhookTarget{repo: &scm.Repository{FullPath: "x/y"}}
+	if result := addWebhook(context.Background(), unsupported, hookURL, "", false); result.State != webhookUnsupported {
+		t.Errorf("Expected unsupported provider, got %+v", result)
+	}
+
+	manager.listErr = errors.New("403 Forbidden")
+	if result := addWebhook(context.Background(), fresh, hookURL, "", false); result.State != webhookFailed || result.Err == nil {
+		t.Errorf("Expected failure, got %+v", result)
+	}
+}
+
+func TestDisplayWebhookResults(t *testing.T) {
+	results := []webhookResult{
+		{Repo: &scm.Repository{FullPath: "a"}, State: webhookAdded},
+		{Repo: &scm.Repository{FullPath: "b"}, State: webhookExists},
+		{Repo: &scm.Repository{FullPath: "c", Provider: "other"}, State: webhookUnsupported},
+		{Repo: &scm.Repository{FullPath: "d"}, State: webhookFailed, Err: errors.New("403 Forbidden")},
+	}
+
+	var buf bytes.Buffer
+	if failed := displayWebhookResults(&buf, results, "https://mirror.example.com/hook", false); failed != 1 {
+		t.Errorf("Expected 1 failure, got %d", failed)
+	}
+	output := buf.String()
+	for _, want := range []string{"a - webhook added", "c - other provider cannot manage webhooks", "d - failed (403 Forbidden)", "1 changed, 1 already up to date, 1 unsupported, 1 failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// mockWebhookClient is a provider whose repositories have webhooks
+type mockWebhookClient struct {
+	mockSCMClient
+	fakeWebhookManager
+}
+
+func TestCommand_WebhookList(t *testing.T) {
+	cfg := &config.Config{
+		Local:     config.LocalConfig{BaseDir: t.TempDir()},
+		Cache:     config.CacheConfig{DisableHTTP: true, DisableMetadata: true},
+		Providers: []config.ProviderConfig{{Name: "work", Type: "gitlab", URL: "https://gitlab.example.com"}},
+	}
+	client := &mockWebhookClient{
+		mockSCMClient: mockSCMClient{providerType: "gitlab", repos: []*scm.Repository{
+			{FullPath: "team/api", Provider: "gitlab"},
+			{FullPath: "team/web", Provider: "gitlab"},
+			{FullPath: "team/docs", Provider: "gitlab"},
+		}},
+		fakeWebhookManager: fakeWebhookManager{hooks: map[string][]scm.Webhook{
+			"team/api": {{ID: "1", URL: "https://ci.example.com/hook"}, {ID: "2", URL: "https://chat.example.com"}},
+			"team/web": {{ID: "3", URL: "https://ci.example.com/hook"}},
+		}},
+	}
+	clients := map[string]scm.Client{"work": client}
+
+	out, err := runCommand(t, cfg, clients, "hooks", "list")
+	if err != nil {
+		t.Fatalf("hooks list failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"🪝 team/api [gitlab]\n   https://ci.example.com/hook  (id 1)\n   https://chat.example.com  (id 2)", "3 webhooks on 2 of 3 repositories"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "team/docs") {
+		t.Errorf("Expected repositories without webhooks to be left out, got:\n%s", out)
+	}
+
+	out, err = runCommand(t, cfg, clients, "webhook", "list", "--url", "https://chat.example.com")
+	if err != nil || !strings.Contains(out, "1 webhooks on 1 of 3 repositories") || strings.Contains(out, "team/web") {
+		t.Errorf("Expected only the webhooks pointing at the URL, got %v:\n%s", err, out)
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/redact"
+	"gitstuff/internal/runner"
+	"gitstuff/internal/theme"
+	"gitstuff/internal/verbosity"
+
+	"github.com/spf13/cobra"
+)
+
+var wipCmd = &cobra.Command{
+	Use:   "wip [path]",
+	Short: "Report the uncommitted work in every local repository",
+	Long: `Scan the base directory (or the given path) for git repositories with
+uncommitted changes and show, for each one, how many files changed, a short
+diffstat and how long ago its oldest changed file was modified. Old,
+forgotten changes come first, to help decide what to commit, stash or
+discard.
+
+Like status, this only looks at the local filesystem.
+
+Examples:
+  gitstuff wip                  # Oldest work first
+  gitstuff wip --sort size      # Largest changes first
+  gitstuff wip ~/src/work       # Scan a specific directory`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWip,
+}
+
+func init() {
+	rootCmd.AddCommand(wipCmd)
+	wipCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to inspect in parallel")
+	wipCmd.Flags().String("sort", "age", "Sort by age (oldest first), size (most changed lines first) or path")
+}
+
+type repoWip struct {
+	Path   string
+	Branch string
+	Wip    *git.WorkInProgress
+	Err    error
+}
+
+func runWip(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+	sortBy, _ := cmd.Flags().GetString("sort")
+	if sortBy != "age" && sortBy != "size" && sortBy != "path" {
+		return fmt.Errorf("unsupported --sort %q (expected age, size or path)", sortBy)
+	}
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	root, repoPaths, err := localRepositories(args)
+	if err != nil {
+		return err
+	}
+
+	results := make([]repoWip, len(repoPaths))
+	tasks := make([]runner.Task, len(repoPaths))
+	for i, repoPath := range repoPaths {
+		tasks[i] = func(w io.Writer) error {
+			results[i] = repoWip{Path: repoPath}
+			results[i].Wip, results[i].Err = git.GetWorkInProgress(repoPath)
+			if results[i].Err == nil && results[i].Wip.Files > 0 {
+				results[i].Branch, _ = git.CurrentBranch(repoPath)
+			}
+			return results[i].Err
+		}
+	}
+	runner.New(jobs).Run(tasks, io.Discard)
+	verbosity.DebugTiming(start, "Inspected %d repositories", len(repoPaths))
+
+	sortWip(results, sortBy)
+	displayWip(stdout, root, results, time.Now())
+	return nil
+}
+
+// sortWip orders the results by the age of their oldest change, by the
+// number of changed lines or by path
+func sortWip(results []repoWip, sortBy string) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].Wip, results[j].Wip
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		switch sortBy {
+		case "age":
+			if a.OldestModified.IsZero() != b.OldestModified.IsZero() {
+				return b.OldestModified.IsZero()
+			}
+			if !a.OldestModified.Equal(b.OldestModified) {
+				return a.OldestModified.Before(b.OldestModified)
+			}
+		case "size":
+			if a.Insertions+a.Deletions != b.Insertions+b.Deletions {
+				return a.Insertions+a.Deletions > b.Insertions+b.Deletions
+			}
+		}
+		return results[i].Path < results[j].Path
+	})
+}
+
+func displayWip(w io.Writer, root string, results []repoWip, now time.Time) {
+	fmt.Fprintf(w, "%s\n\n", i18n.T("status.found_local", len(results), root))
+
+	dirty := 0
+	for _, result := range results {
+		name := relativeTo(root, result.Path)
+		if result.Err != nil {
+			fmt.Fprintf(w, "📁 %s - %s %s\n", name, theme.Get().Error, i18n.T("status.error", redact.Error(result.Err)))
+			continue
+		}
+		if result.Wip.Files == 0 {
+			continue
+		}
+		dirty++
+		fmt.Fprintf(w, "📝 %s - %s\n", name, formatWip(result, now))
+	}
+
+	if dirty == 0 {
+		fmt.Fprintln(w, i18n.T("wip.none"))
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", i18n.T("wip.summary", dirty, len(results)))
+}
+
+func formatWip(result repoWip, now time.Time) string {
+	wip := result.Wip
+	var parts []string
+	if result.Branch != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", result.Branch))
+	}
+	parts = append(parts, i18n.T("wip.files", wip.Files, wip.Insertions, wip.Deletions))
+	if wip.Untracked > 0 {
+		parts = append(parts, i18n.T("wip.untracked", wip.Untracked))
+	}
+	if wip.OldestFile != "" {
+		parts = append(parts, "🕰️  "+i18n.T("wip.oldest", formatAge(now.Sub(wip.OldestModified)), wip.OldestFile))
+	}
+	return strings.Join(parts, " ")
+}
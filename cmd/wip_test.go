@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitstuff/internal/git"
+)
+
+func TestCommand_Wip(t *testing.T) {
+	cfg, _ := setupSyncFixture(t)
+
+	out, err := runCommand(t, cfg, nil, "wip")
+	if err != nil {
+		t.Fatalf("wip failed: %v\n%s", err, out)
+	}
+	dirty := filepath.Join("gitlab", "group", "dirty")
+	for _, want := range []string{"📝 " + dirty + " - (", "1 files, +0 -0 (1 untracked)", "oldest change 0m ago: wip.txt", "1 of 2 repositories have uncommitted changes"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, filepath.Join("group", "clean")) {
+		t.Errorf("Expected clean repositories to be left out, got:\n%s", out)
+	}
+
+	if _, err := runCommand(t, cfg, nil, "wip", "--sort", "colour"); err == nil || !strings.Contains(err.Error(), "unsupported --sort") {
+		t.Errorf("Expected an unsupported sort error, got %v", err)
+	}
+}
+
+func TestSortWip(t *testing.T) {
+	now := time.Now()
+	results := []repoWip{
+		{Path: "a", Wip: &git.WorkInProgress{Files: 1, Insertions: 5, OldestFile: "x", OldestModified: now}},
+		{Path: "b", Wip: &git.WorkInProgress{Files: 1, Deletions: 1}},
+		{Path: "c", Wip: &git.WorkInProgress{Files: 1, Insertions: 50, OldestFile: "y", OldestModified: now.Add(-time.Hour)}},
+		{Path: "d"},
+	}
+	order := func() string {
+		var paths []string
+		for _, result := range results {
+			paths = append(paths, result.Path)
+		}
+		return strings.Join(paths, "")
+	}
+
+	for _, tt := range []struct{ sortBy, want string }{
+		{"age", "dcab"},
+		{"size", "dcab"},
+		{"path", "dabc"},
+	} {
+		sortWip(results, tt.sortBy)
+		if got := order(); got != tt.want {
+			t.Errorf("sortWip(%s) = %s, want %s", tt.sortBy, got, tt.want)
+		}
+	}
+}
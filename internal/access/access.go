@@ -0,0 +1,143 @@
+// Package access keeps snapshots of who has access to each repository and
+// compares them, so new admins and outside collaborators stand out.
+package access
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gitstuff/internal/scm"
+	"gitstuff/internal/state"
+)
+
+// FileName is the name of the snapshot file in the base directory
+const FileName = ".gitstuff-access.json"
+
+// Repository is the access to one repository when the snapshot was taken
+type Repository struct {
+	FullPath string       `json:"full_path"`
+	Provider string       `json:"provider"`
+	Members  []scm.Member `json:"members"`
+}
+
+// Snapshot holds the members of each repository, keyed like the state file
+// so renamed repositories are still compared with their earlier members
+type Snapshot struct {
+	TakenAt      time.Time             `json:"taken_at"`
+	Repositories map[string]Repository `json:"repositories"`
+}
+
+// Load reads the snapshot at path. A missing file gives an empty snapshot
+// with a zero TakenAt.
+func Load(path string) (*Snapshot, error) {
+	s := &Snapshot{Repositories: make(map[string]Repository)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access snapshot: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse access snapshot %s: %w", path, err)
+	}
+	if s.Repositories == nil {
+		s.Repositories = make(map[string]Repository)
+	}
+	return s, nil
+}
+
+// Members returns the members recorded for repo, and whether it was in the
+// snapshot at all
+func (s *Snapshot) Members(repo *scm.Repository) ([]scm.Member, bool) {
+	entry, ok := s.Repositories[state.Key(repo)]
+	return entry.Members, ok
+}
+
+// Record replaces the members recorded for repo
+func (s *Snapshot) Record(repo *scm.Repository, members []scm.Member) {
+	s.Repositories[state.Key(repo)] = Repository{FullPath: repo.FullPath, Provider: repo.Provider, Members: members}
+}
+
+// Save writes the snapshot to path, replacing the previous one at once so
+// an interrupted write does not lose it
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode access snapshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gitstuff-access-*")
+	if err != nil {
+		return fmt.Errorf("failed to write access snapshot: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write access snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write access snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write access snapshot: %w", err)
+	}
+	return nil
+}
+
+// Change is a member whose access differs between two snapshots. From is
+// empty for added members and To for removed ones.
+type Change struct {
+	Username string
+	From     string
+	To       string
+	External bool
+}
+
+// Added reports whether the member had no access before
+func (c Change) Added() bool {
+	return c.From == ""
+}
+
+// Removed reports whether the member lost all access
+func (c Change) Removed() bool {
+	return c.To == ""
+}
+
+// Alarming reports whether the change needs a closer look: a member who
+// became admin, or an external member who was given access
+func (c Change) Alarming() bool {
+	return (c.To == "admin" && c.From != "admin") || (c.Added() && c.External)
+}
+
+// Diff returns the changes from previous to current, sorted by username
+func Diff(previous, current []scm.Member) []Change {
+	before := make(map[string]scm.Member, len(previous))
+	for _, member := range previous {
+		before[member.Username] = member
+	}
+
+	var changes []Change
+	for _, member := range current {
+		old, ok := before[member.Username]
+		delete(before, member.Username)
+		if ok && old.Permission == member.Permission {
+			continue
+		}
+		changes = append(changes, Change{Username: member.Username, From: old.Permission, To: member.Permission, External: member.External})
+	}
+	for _, member := range before {
+		changes = append(changes, Change{Username: member.Username, From: member.Permission, External: member.External})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Username < changes[j].Username })
+	return changes
+}
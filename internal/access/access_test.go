@@ -0,0 +1,92 @@
+package access
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestDiff(t *testing.T) {
+	previous := []scm.Member{
+		{Username: "lead", Permission: "admin"},
+		{Username: "dev", Permission: "write"},
+		{Username: "intern", Permission: "read"},
+		{Username: "leaver", Permission: "write"},
+	}
+	current := []scm.Member{
+		{Username: "lead", Permission: "admin"},
+		{Username: "dev", Permission: "admin"},
+		{Username: "intern", Permission: "write"},
+		{Username: "contractor", Permission: "read", External: true},
+		{Username: "newhire", Permission: "write"},
+	}
+
+	changes := Diff(previous, current)
+	want := []Change{
+		{Username: "contractor", To: "read", External: true},
+		{Username: "dev", From: "write", To: "admin"},
+		{Username: "intern", From: "read", To: "write"},
+		{Username: "leaver", From: "write"},
+		{Username: "newhire", To: "write"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("Diff() = %+v, want %+v", changes, want)
+	}
+
+	alarming := map[string]bool{"contractor": true, "dev": true}
+	for _, change := range changes {
+		if change.Alarming() != alarming[change.Username] {
+			t.Errorf("Alarming() = %v for %+v", change.Alarming(), change)
+		}
+	}
+	if !changes[0].Added() || changes[0].Removed() || !changes[3].Removed() {
+		t.Errorf("Unexpected added/removed for %+v", changes)
+	}
+
+	if changes := Diff(current, current); len(changes) != 0 {
+		t.Errorf("Expected no changes between equal lists, got %+v", changes)
+	}
+}
+
+func TestSnapshot_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repos", FileName)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !s.TakenAt.IsZero() || len(s.Repositories) != 0 {
+		t.Errorf("Expected an empty snapshot, got %+v", s)
+	}
+
+	repo := &scm.Repository{ID: "7", FullPath: "team/api", Provider: "gitlab", WebURL: "https://gitlab.com/team/api"}
+	members := []scm.Member{{Username: "lead", Permission: "admin"}}
+	s.Record(repo, members)
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// Renamed repositories keep their ID
+	renamed := &scm.Repository{ID: "7", FullPath: "platform/api", Provider: "gitlab", WebURL: "https://gitlab.com/platform/api"}
+	got, ok := loaded.Members(renamed)
+	if !ok || !reflect.DeepEqual(got, members) {
+		t.Errorf("Members() = %+v, %v", got, ok)
+	}
+	if _, ok := loaded.Members(&scm.Repository{ID: "8", Provider: "gitlab", WebURL: "https://gitlab.com/team/web"}); ok {
+		t.Error("Expected unknown repositories not to be found")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for a corrupt snapshot")
+	}
+}
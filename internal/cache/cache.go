@@ -0,0 +1,122 @@
+// Package cache stores repository metadata fetched from providers so
+// commands do not have to list every repository from the API each time.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/scm"
+	"gitstuff/internal/timing"
+	"gitstuff/internal/verbosity"
+)
+
+// DefaultTTL is how long cached repository metadata is used before it is
+// fetched again
+const DefaultTTL = time.Hour
+
+// Entry is a cached repository listing
+type Entry struct {
+	FetchedAt    time.Time         `json:"fetched_at"`
+	Repositories []*scm.Repository `json:"repositories"`
+}
+
+// Store persists repository listings as JSON files in a directory
+type Store struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+func NewStore(dir string, ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Load returns the entry stored under key, or nil when there is none or it
+// cannot be read
+func (s *Store) Load(key string) *Entry {
+	defer timing.Track(timing.Filesystem, time.Now())
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		verbosity.Debug("Ignoring unreadable metadata cache entry %s: %v", key, err)
+		return nil
+	}
+	return &entry
+}
+
+// IsFresh reports whether entry is younger than the store's TTL
+func (s *Store) IsFresh(entry *Entry) bool {
+	return entry != nil && s.now().Sub(entry.FetchedAt) < s.ttl
+}
+
+// Save writes repos under key, replacing any previous entry atomically
+func (s *Store) Save(key string, repos []*scm.Repository) error {
+	defer timing.Track(timing.Filesystem, time.Now())
+	data, err := json.Marshal(&Entry{FetchedAt: s.now(), Repositories: repos})
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata cache: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create metadata cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// Key derives a cache key for a provider. The token is part of the key
+// because different credentials can see different repositories.
+func Key(name, url, token string) string {
+	sum := sha256.Sum256([]byte(name + "\x00" + url + "\x00" + token))
+	return hex.EncodeToString(sum[:])[:32]
+}
+
+// LoadAll returns every readable entry in the store, fresh or not
+func (s *Store) LoadAll() []*Entry {
+	defer timing.Track(timing.Filesystem, time.Now())
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+	var entries []*Entry
+	for _, file := range files {
+		if entry := s.Load(strings.TrimSuffix(filepath.Base(file), ".json")); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitstuff/internal/scm"
+)
+
+type countingClient struct {
+	repos      []*scm.Repository
+	err        error
+	calls      int
+	groupCalls int
+}
+
+func (c *countingClient) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.repos, nil
+}
+
+func (c *countingClient) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*scm.Repository, error) {
+	c.groupCalls++
+	return c.repos[:1], nil
+}
+
+func (c *countingClient) BuildRepositoryTree(ctx context.Context) (*scm.RepositoryTree, error) {
+	return &scm.RepositoryTree{}, nil
+}
+
+func (c *countingClient) GetProviderType() string {
+	return "gitlab"
+}
+
+func testRepos() []*scm.Repository {
+	return []*scm.Repository{
+		{ID: "1", Name: "api", FullPath: "team/api", CloneURL: "https://gitlab.com/team/api.git", Provider: "gitlab"},
+		{ID: "2", Name: "web", FullPath: "team/web", CloneURL: "https://gitlab.com/team/web.git", Provider: "gitlab"},
+	}
+}
+
+func TestStore_SaveLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "metadata")
+	store := NewStore(dir, time.Minute)
+
+	if store.Load("missing") != nil {
+		t.Error("Expected no entry for missing key")
+	}
+
+	if err := store.Save("key", testRepos()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	entry := store.Load("key")
+	if entry == nil {
+		t.Fatal("Expected entry after save")
+	}
+	if len(entry.Repositories) != 2 || entry.Repositories[1].FullPath != "team/web" {
+		t.Errorf("Unexpected repositories: %+v", entry.Repositories)
+	}
+	if !store.IsFresh(entry) {
+		t.Error("Expected new entry to be fresh")
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Failed to stat cache directory: %v", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("Expected cache directory mode 0700, got %o", info.Mode().Perm())
+	}
+}
+
+func TestStore_Expiry(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if err := store.Save("key", testRepos()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	entry := store.Load("key")
+
+	store.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if store.IsFresh(entry) {
+		t.Error("Expected entry older than TTL to be stale")
+	}
+	if store.IsFresh(nil) {
+		t.Error("Expected nil entry to be stale")
+	}
+}
+
+func TestStore_CorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir, 0)
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if store.Load("bad") != nil {
+		t.Error("Expected corrupt entry to be ignored")
+	}
+	if store.ttl != DefaultTTL {
+		t.Errorf("Expected default TTL, got %v", store.ttl)
+	}
+}
+
+func TestStore_LoadAll(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir, time.Minute)
+	for _, key := range []string{"one", "two"} {
+		if err := store.Save(key, testRepos()); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if entries := store.LoadAll(); len(entries) != 2 {
+		t.Errorf("Expected the 2 readable entries, got %d", len(entries))
+	}
+	if entries := NewStore(filepath.Join(dir, "missing"), 0).LoadAll(); len(entries) != 0 {
+		t.Errorf("Expected no entries in a missing directory, got %d", len(entries))
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := Key("gitlab", "https://gitlab.com", "token-a")
+	if base != Key("gitlab", "https://gitlab.com", "token-a") {
+		t.Error("Expected key to be stable")
+	}
+	if base == Key("gitlab", "https://gitlab.com", "token-b") {
+		t.Error("Expected different tokens to produce different keys")
+	}
+	if base == Key("gitlab", "https://gitlab.example.com", "token-a") {
+		t.Error("Expected different URLs to produce different keys")
+	}
+}
+
+func TestClient_ServesFromCache(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Hour)
+	inner := &countingClient{repos: testRepos()}
+
+	first := Wrap(inner, store, "key", false)
+	if _, err := first.ListAllRepositories(context.Background()); err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+	if first.ServedFromCache() {
+		t.Error("Expected first listing to come from the provider")
+	}
+
+	second := Wrap(inner, store, "key", false)
+	repos, err := second.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected 1 provider call, got %d", inner.calls)
+	}
+	if !second.ServedFromCache() || len(repos) != 2 {
+		t.Errorf("Expected cached listing of 2 repositories, got %d", len(repos))
+	}
+
+	if second.Unwrap() != inner {
+		t.Error("Expected Unwrap to return the provider client")
+	}
+}
+
+func TestClient_RefreshFlag(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Hour)
+	inner := &countingClient{repos: testRepos()}
+
+	for i := 0; i < 2; i++ {
+		client := Wrap(inner, store, "key", true)
+		if _, err := client.ListAllRepositories(context.Background()); err != nil {
+			t.Fatalf("ListAllRepositories failed: %v", err)
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("Expected refresh to always call the provider, got %d calls", inner.calls)
+	}
+	if store.Load("key") == nil {
+		t.Error("Expected refresh to update the cache")
+	}
+}
+
+func TestClient_GroupListingsCachedSeparately(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Hour)
+	inner := &countingClient{repos: testRepos()}
+	client := Wrap(inner, store, "key", false)
+
+	for i := 0; i < 2; i++ {
+		repos, err := client.ListRepositoriesInGroup(context.Background(), "team")
+		if err != nil {
+			t.Fatalf("ListRepositoriesInGroup failed: %v", err)
+		}
+		if len(repos) != 1 {
+			t.Errorf("Expected group listing of 1 repository, got %d", len(repos))
+		}
+	}
+	if inner.groupCalls != 1 {
+		t.Errorf("Expected 1 group call, got %d", inner.groupCalls)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+	if len(repos) != 2 || inner.calls != 1 {
+		t.Errorf("Expected full listing to be fetched separately, got %d repos and %d calls", len(repos), inner.calls)
+	}
+}
+
+func TestClient_Refresh(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Hour)
+	inner := &countingClient{repos: testRepos()[:1]}
+	client := Wrap(inner, store, "key", false)
+
+	if _, err := client.ListAllRepositories(context.Background()); err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+
+	inner.repos = testRepos()
+	repos, err := client.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(repos) != 2 || client.ServedFromCache() {
+		t.Errorf("Expected live listing of 2 repositories, got %d", len(repos))
+	}
+	if entry := store.Load("key"); entry == nil || len(entry.Repositories) != 2 {
+		t.Error("Expected refresh to update the stored listing")
+	}
+}
+
+func TestClient_Cached(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Minute)
+	inner := &countingClient{repos: testRepos()}
+	client := Wrap(inner, store, "key", false)
+
+	if repos := client.Cached(); repos != nil {
+		t.Errorf("Expected no cached listing, got %d repositories", len(repos))
+	}
+	if _, err := client.ListAllRepositories(context.Background()); err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+
+	store.now = func() time.Time { return time.Now().Add(time.Hour) }
+	if repos := client.Cached(); len(repos) != 2 {
+		t.Errorf("Expected the stale listing of 2 repositories, got %d", len(repos))
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected Cached not to call the provider, got %d calls", inner.calls)
+	}
+}
+
+func TestClient_StaleWhenUnavailable(t *testing.T) {
+	store := NewStore(t.TempDir(), time.Minute)
+	inner := &countingClient{repos: testRepos()}
+	if _, err := Wrap(inner, store, "key", false).ListAllRepositories(context.Background()); err != nil {
+		t.Fatalf("ListAllRepositories failed: %v", err)
+	}
+	store.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	// The refresh flag does not stop the fallback either
+	inner.err = &scm.APIError{StatusCode: http.StatusBadGateway, Err: errors.New("bad gateway")}
+	client := Wrap(inner, store, "key", true)
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the stale listing instead of %v", err)
+	}
+	since, reason := client.Stale()
+	if len(repos) != 2 || since.IsZero() || reason != inner.err || !client.ServedFromCache() {
+		t.Errorf("Expected a stale listing of 2 repositories, got %d since %v (%v)", len(repos), since, reason)
+	}
+
+	// Refused requests are not outages
+	inner.err = &scm.APIError{StatusCode: http.StatusUnauthorized, Err: errors.New("unauthorized")}
+	client = Wrap(inner, store, "key", false)
+	if _, err := client.ListAllRepositories(context.Background()); err == nil {
+		t.Error("Expected an authentication failure not to fall back to the cache")
+	}
+	if since, _ := client.Stale(); !since.IsZero() {
+		t.Error("Expected no stale listing")
+	}
+
+	inner.err = errors.New("dial tcp: connection refused")
+	if _, err := Wrap(inner, NewStore(t.TempDir(), time.Minute), "key", false).ListAllRepositories(context.Background()); err == nil {
+		t.Error("Expected the error without a cached listing")
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"gitstuff/internal/scm"
+	"gitstuff/internal/timing"
+	"gitstuff/internal/verbosity"
+)
+
+// Client wraps an scm.Client and serves repository listings from a Store
+// while they are fresh
+type Client struct {
+	scm.Client
+
+	store   *Store
+	key     string
+	refresh bool
+
+	servedFromCache bool
+
+	// staleSince is when the listing served last was fetched, when the
+	// provider was unavailable and it was served instead; staleErr is why
+	staleSince time.Time
+	staleErr   error
+}
+
+// Wrap returns a caching client for client. With refresh set, listings are
+// always fetched from the provider and the cache is updated.
+func Wrap(client scm.Client, store *Store, key string, refresh bool) *Client {
+	return &Client{Client: client, store: store, key: key, refresh: refresh}
+}
+
+// Unwrap returns the provider client, for detecting optional capabilities
+func (c *Client) Unwrap() scm.Client {
+	return c.Client
+}
+
+// ServedFromCache reports whether the last listing came from the cache
+// rather than the provider
+func (c *Client) ServedFromCache() bool {
+	return c.servedFromCache
+}
+
+// Stale returns when the last listing was fetched and why the provider
+// could not be asked again, when it was served from the cache because the
+// provider was unavailable. The time is zero otherwise.
+func (c *Client) Stale() (time.Time, error) {
+	return c.staleSince, c.staleErr
+}
+
+func (c *Client) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
+	return c.list(ctx, c.key, c.Client.ListAllRepositories)
+}
+
+func (c *Client) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*scm.Repository, error) {
+	return c.list(ctx, Key(c.key, "group", groupPath), func(ctx context.Context) ([]*scm.Repository, error) {
+		return c.Client.ListRepositoriesInGroup(ctx, groupPath)
+	})
+}
+
+// Refresh fetches all repositories from the provider and updates the cache
+func (c *Client) Refresh(ctx context.Context) ([]*scm.Repository, error) {
+	return c.fetch(ctx, c.key, c.Client.ListAllRepositories)
+}
+
+// Cached returns the last full listing stored for the provider, however old
+// it is, or nil when there is none. It never calls the provider.
+func (c *Client) Cached() []*scm.Repository {
+	if entry := c.store.Load(c.key); entry != nil {
+		return entry.Repositories
+	}
+	return nil
+}
+
+// list serves the listing under key from the cache while it is fresh. When
+// the provider is unavailable, an older listing is served instead.
+func (c *Client) list(ctx context.Context, key string, fetch func(context.Context) ([]*scm.Repository, error)) ([]*scm.Repository, error) {
+	var entry *Entry
+	if !c.refresh {
+		if entry = c.store.Load(key); c.store.IsFresh(entry) {
+			verbosity.Debug("Using cached repository metadata for %s provider (fetched %s ago)",
+				c.GetProviderType(), time.Since(entry.FetchedAt).Round(time.Second))
+			c.servedFromCache = true
+			timing.CacheHit()
+			return entry.Repositories, nil
+		}
+	}
+	repos, err := c.fetch(ctx, key, fetch)
+	if err == nil || ctx.Err() != nil || !scm.IsUnavailable(err) {
+		return repos, err
+	}
+	if entry == nil {
+		entry = c.store.Load(key)
+	}
+	if entry == nil {
+		return nil, err
+	}
+	verbosity.Debug("%s provider unavailable, using cached repository metadata fetched %s ago: %v",
+		c.GetProviderType(), time.Since(entry.FetchedAt).Round(time.Second), err)
+	c.servedFromCache = true
+	c.staleSince, c.staleErr = entry.FetchedAt, err
+	return entry.Repositories, nil
+}
+
+func (c *Client) fetch(ctx context.Context, key string, fetch func(context.Context) ([]*scm.Repository, error)) ([]*scm.Repository, error) {
+	c.servedFromCache = false
+	c.staleSince, c.staleErr = time.Time{}, nil
+	repos, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.store.Save(key, repos); err != nil {
+		verbosity.Debug("Could not update metadata cache: %v", err)
+	}
+	return repos, nil
+}
@@ -0,0 +1,204 @@
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Locations lists where GitHub and GitLab look for a CODEOWNERS file, in the
+// order they are searched
+var Locations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// Rule assigns owners to the files matching a pattern
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Section string
+	Line    int
+}
+
+// File is a parsed CODEOWNERS file
+type File struct {
+	Path  string
+	Rules []Rule
+}
+
+// Find returns the path of the CODEOWNERS file in a repository, relative to
+// the repository root, or an empty string if there is none
+func Find(repoPath string) string {
+	for _, location := range Locations {
+		info, err := os.Stat(filepath.Join(repoPath, filepath.FromSlash(location)))
+		if err == nil && !info.IsDir() {
+			return location
+		}
+	}
+	return ""
+}
+
+// Load finds and parses the CODEOWNERS file of a repository. It returns nil
+// without an error when the repository has none.
+func Load(repoPath string) (*File, error) {
+	location := Find(repoPath)
+	if location == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(filepath.Join(repoPath, filepath.FromSlash(location)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", location, err)
+	}
+	defer f.Close()
+
+	rules, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", location, err)
+	}
+	return &File{Path: location, Rules: rules}, nil
+}
+
+// Parse reads CODEOWNERS rules. GitLab sections ("[Section]", "^[Section]")
+// are supported, including their default owners.
+func Parse(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	var section string
+	var sectionOwners []string
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := splitFields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		if name, owners, ok := parseSection(fields); ok {
+			section, sectionOwners = name, owners
+			continue
+		}
+
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = sectionOwners
+		}
+		rules = append(rules, Rule{
+			Pattern: fields[0],
+			Owners:  owners,
+			Section: section,
+			Line:    lineNumber,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Owners returns the distinct owners named anywhere in the file, sorted
+func (f *File) Owners() []string {
+	seen := make(map[string]bool)
+	var owners []string
+	for _, rule := range f.Rules {
+		for _, owner := range rule.Owners {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// PatternsOwnedBy returns the patterns of the rules that name an owner
+// matching query (see MatchOwner)
+func (f *File) PatternsOwnedBy(query string) []string {
+	var patterns []string
+	for _, rule := range f.Rules {
+		for _, owner := range rule.Owners {
+			if MatchOwner(owner, query) {
+				patterns = append(patterns, rule.Pattern)
+				break
+			}
+		}
+	}
+	return patterns
+}
+
+// MatchOwner reports whether owner is the user, team or email given by
+// query. The comparison ignores case and the leading "@", and a bare team
+// name such as "backend" matches "@org/backend".
+func MatchOwner(owner, query string) bool {
+	owner = strings.ToLower(strings.TrimPrefix(owner, "@"))
+	query = strings.ToLower(strings.TrimPrefix(query, "@"))
+	if owner == query {
+		return true
+	}
+	return !strings.Contains(query, "/") && strings.HasSuffix(owner, "/"+query)
+}
+
+func stripComment(line string) string {
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitFields splits a line on whitespace, keeping escaped spaces in patterns
+func splitFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ' ' || c == '\t':
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(c)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// parseSection recognises a GitLab section header such as
+// "^[Docs][2] @docs-team" and returns its name and default owners
+func parseSection(fields []string) (string, []string, bool) {
+	header := strings.TrimPrefix(fields[0], "^")
+	if !strings.HasPrefix(header, "[") {
+		return "", nil, false
+	}
+	end := strings.Index(header, "]")
+	if end < 0 {
+		return "", nil, false
+	}
+	return header[1:end], fields[1:], true
+}
@@ -0,0 +1,101 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `# Default owners
+*                @org/platform
+
+/docs/           @org/docs alice@example.com # inline comment
+/my\ file.txt    @bob
+/unowned/
+
+^[Backend] @org/backend
+/api/
+/api/internal/   @carol
+`
+	rules, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	want := []Rule{
+		{Pattern: "*", Owners: []string{"@org/platform"}, Line: 2},
+		{Pattern: "/docs/", Owners: []string{"@org/docs", "alice@example.com"}, Line: 4},
+		{Pattern: "/my file.txt", Owners: []string{"@bob"}, Line: 5},
+		{Pattern: "/unowned/", Owners: nil, Line: 6},
+		{Pattern: "/api/", Owners: []string{"@org/backend"}, Section: "Backend", Line: 9},
+		{Pattern: "/api/internal/", Owners: []string{"@carol"}, Section: "Backend", Line: 10},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", rules, want)
+	}
+}
+
+func TestMatchOwner(t *testing.T) {
+	tests := []struct {
+		owner string
+		query string
+		want  bool
+	}{
+		{owner: "@org/backend", query: "@org/backend", want: true},
+		{owner: "@org/backend", query: "org/backend", want: true},
+		{owner: "@org/backend", query: "backend", want: true},
+		{owner: "@Org/Backend", query: "@org/backend", want: true},
+		{owner: "@org/backend", query: "other/backend", want: false},
+		{owner: "@org/backend-ops", query: "backend", want: false},
+		{owner: "@alice", query: "alice", want: true},
+		{owner: "alice@example.com", query: "alice@example.com", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.owner+" "+tt.query, func(t *testing.T) {
+			if got := MatchOwner(tt.owner, tt.query); got != tt.want {
+				t.Errorf("MatchOwner(%q, %q) = %v, want %v", tt.owner, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	repo := t.TempDir()
+
+	file, err := Load(repo)
+	if err != nil || file != nil {
+		t.Fatalf("Expected no file without CODEOWNERS, got %v (%v)", file, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "CODEOWNERS"), []byte("* @root-owner\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "* @org/web @alice\n/docs/ @org/docs @alice\n"
+	if err := os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err = Load(repo)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if file.Path != ".github/CODEOWNERS" {
+		t.Errorf("Expected .github/CODEOWNERS to take precedence, got %s", file.Path)
+	}
+	if got := file.Owners(); !reflect.DeepEqual(got, []string{"@alice", "@org/docs", "@org/web"}) {
+		t.Errorf("Owners() = %v", got)
+	}
+	if got := file.PatternsOwnedBy("alice"); !reflect.DeepEqual(got, []string{"*", "/docs/"}) {
+		t.Errorf("PatternsOwnedBy(alice) = %v", got)
+	}
+	if got := file.PatternsOwnedBy("docs"); !reflect.DeepEqual(got, []string{"/docs/"}) {
+		t.Errorf("PatternsOwnedBy(docs) = %v", got)
+	}
+}
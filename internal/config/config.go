@@ -1,9 +1,16 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gitstuff/internal/redact"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,19 +18,242 @@ import (
 type Config struct {
 	Providers []ProviderConfig `yaml:"providers"`
 	Local     LocalConfig      `yaml:"local"`
+	Cache     CacheConfig      `yaml:"cache,omitempty"`
+	HTTP      HTTPConfig       `yaml:"http,omitempty"`
+	Git       GitConfig        `yaml:"git,omitempty"`
+	Remotes   []RemoteRule     `yaml:"remotes,omitempty"`
+	PullRules []PullRule       `yaml:"pull_rules,omitempty"`
+	Setup     []SetupRule      `yaml:"setup,omitempty"`
+	Audit     AuditConfig      `yaml:"audit,omitempty"`
+	Retention RetentionConfig  `yaml:"retention,omitempty"`
+	Theme     ThemeConfig      `yaml:"theme,omitempty"`
+
+	// Workspaces are named subsets of repositories, selected with
+	// --workspace
+	Workspaces map[string]Workspace `yaml:"workspaces,omitempty"`
+
+	// ReadOnly forbids cloning, pulling, removing clones and writing this
+	// file, for inventory and reporting on shared machines
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// URLRewrites replace the start of clone URLs, like git's insteadOf:
+	// each key is a prefix and its value what to use instead, such as
+	// an internal mirror. The longest matching prefix wins.
+	URLRewrites map[string]string `yaml:"url_rewrites,omitempty"`
+
+	// DisableHints turns off the follow-up command suggestions shown after
+	// some commands
+	DisableHints bool `yaml:"disable_hints,omitempty"`
 }
 
 type ProviderConfig struct {
-	Name     string `yaml:"name"`
-	Type     string `yaml:"type"` // "gitlab" or "github"
-	URL      string `yaml:"url"`
-	Token    string `yaml:"token"`
-	Insecure bool   `yaml:"insecure"`
-	Group    string `yaml:"group"`
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"` // "gitlab" or "github"
+	URL      string   `yaml:"url"`
+	Token    string   `yaml:"token"`
+	Insecure bool     `yaml:"insecure"`
+	Group    string   `yaml:"group"`
+	Include  []string `yaml:"include,omitempty"` // glob or re:<regex> against FullPath
+	Exclude  []string `yaml:"exclude,omitempty"`
+
+	// TokenSource is "config" (default) to read Token from this file or
+	// "keyring" to read it from the operating system keychain
+	TokenSource string `yaml:"token_source,omitempty"`
+
+	// TokenEnv names an environment variable holding the token
+	TokenEnv string `yaml:"token_env,omitempty"`
+
+	// TokenCmd is a shell command whose output is the token, such as
+	// "pass show work/gitlab"
+	TokenCmd string `yaml:"token_cmd,omitempty"`
+
+	// Protocol is "ssh" or "https" to clone this provider's repositories
+	// over when no protocol flag is given; SSH when unset
+	Protocol string `yaml:"protocol,omitempty"`
+
+	// SSHHost replaces the host of this provider's SSH clone URLs, such as
+	// a Host alias in ~/.ssh/config that selects another identity
+	SSHHost string `yaml:"ssh_host,omitempty"`
 }
 
 type LocalConfig struct {
 	BaseDir string `yaml:"base_dir"`
+
+	// ArchiveDir is where 'gitstuff archive --local' moves clones, by
+	// default the base directory with an "-archived" suffix
+	ArchiveDir string `yaml:"archive_dir,omitempty"`
+}
+
+type CacheConfig struct {
+	Dir         string `yaml:"dir,omitempty"`
+	DisableHTTP bool   `yaml:"disable_http,omitempty"`
+
+	// MetadataTTL is how long repository listings are reused, as a Go
+	// duration such as "30m"
+	MetadataTTL     string `yaml:"metadata_ttl,omitempty"`
+	DisableMetadata bool   `yaml:"disable_metadata,omitempty"`
+}
+
+type HTTPConfig struct {
+	// UserAgentSuffix is appended to the gitstuff/<version> User-Agent
+	UserAgentSuffix string `yaml:"user_agent_suffix,omitempty"`
+}
+
+type GitConfig struct {
+	// StatusBackend is "go-git" (default) or "exec" to run the git binary
+	StatusBackend string `yaml:"status_backend,omitempty"`
+
+	// LimitRate caps the combined transfer rate of clones and pulls, e.g. "2M"
+	LimitRate string `yaml:"limit_rate,omitempty"`
+
+	// ProtocolFallback retries clones that fail to authenticate or connect
+	// over the other protocol, remembering which one worked per provider
+	ProtocolFallback bool `yaml:"protocol_fallback,omitempty"`
+
+	// CredentialHelper leaves HTTPS clones to git's credential helper
+	// instead of sending them the provider token
+	CredentialHelper bool `yaml:"credential_helper,omitempty"`
+
+	// PullStrategy is how pulls integrate upstream changes: "merge",
+	// "rebase" or "ff-only". Empty leaves it to git's own pull settings.
+	PullStrategy string `yaml:"pull_strategy,omitempty"`
+
+	// Autostash stashes uncommitted changes around pulls, so dirty clones
+	// are updated instead of skipped
+	Autostash bool `yaml:"autostash,omitempty"`
+
+	// Retries is how many times a clone, fetch or pull that failed for a
+	// transient reason, like a dropped connection, is tried again
+	Retries int `yaml:"retries,omitempty"`
+
+	// RetryDelay is how long to wait before the first retry, as a Go
+	// duration; each further retry waits twice as long. Default 2s.
+	RetryDelay string `yaml:"retry_delay,omitempty"`
+
+	// Timeout is how long a single clone, pull, fetch or push may run
+	// before it is killed and its repository fails, as a Go duration.
+	// Empty means no limit.
+	Timeout string `yaml:"timeout,omitempty"`
+
+	// Maintenance registers new clones for git's background maintenance,
+	// see 'gitstuff maintenance'
+	Maintenance bool `yaml:"maintenance,omitempty"`
+
+	// ReferenceForks clones forks with the local clone of the repository
+	// they were forked from as a reference, so both share its objects
+	ReferenceForks bool `yaml:"reference_forks,omitempty"`
+
+	// Excludes and Attributes are installed into every clone's
+	// .git/info/exclude and .git/info/attributes, outside the working tree.
+	// The file variants read more patterns from a file. See 'gitstuff excludes'.
+	Excludes       []string `yaml:"excludes,omitempty"`
+	ExcludesFile   string   `yaml:"excludes_file,omitempty"`
+	Attributes     []string `yaml:"attributes,omitempty"`
+	AttributesFile string   `yaml:"attributes_file,omitempty"`
+}
+
+// RemoteRule adds an extra remote to matching repositories after they are
+// cloned or updated
+type RemoteRule struct {
+	Name string `yaml:"name"`
+
+	// URL is a Go template rendered with the repository, e.g.
+	// "{{.ParentSSHCloneURL}}" or "git@backup.example.com:{{.FullPath}}.git"
+	URL string `yaml:"url"`
+
+	// Include limits the rule to repositories whose path matches a glob
+	// or re:<regex>
+	Include []string `yaml:"include,omitempty"`
+
+	// ForksOnly limits the rule to forks whose parent is known
+	ForksOnly bool `yaml:"forks_only,omitempty"`
+}
+
+// PullRule decides how repositories matching Include are pulled when the
+// default branch is checked out and has local commits. The first matching
+// rule wins.
+type PullRule struct {
+	Include []string `yaml:"include,omitempty"`
+
+	// OnProtectedBranch is "refuse" (default), "rebase" or "merge"
+	OnProtectedBranch string `yaml:"on_protected_branch"`
+}
+
+// SetupRule runs Commands in repositories matching Include, or in all of
+// them when Include is empty, right after they are first cloned. Commands of
+// every matching rule run in order.
+type SetupRule struct {
+	Include  []string `yaml:"include,omitempty"`
+	Commands []string `yaml:"commands"`
+}
+
+// Workspace selects the repositories that are in any of Repos or Groups or
+// match any Include pattern, all of them when none are given, and drops those
+// matching an Exclude pattern
+type Workspace struct {
+	// Repos are full repository paths, e.g. "web/storefront"
+	Repos []string `yaml:"repos,omitempty"`
+
+	// Groups are group paths whose repositories, including those in
+	// subgroups, are in the workspace
+	Groups []string `yaml:"groups,omitempty"`
+
+	// Include and Exclude are globs or re:<regex> as for --include and
+	// --exclude
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+
+	// Providers limits the workspace to the providers with these names
+	Providers []string `yaml:"providers,omitempty"`
+}
+
+// RetentionConfig removes clones and backups that are no longer needed at
+// the end of sync and daemon runs
+type RetentionConfig struct {
+	// ArchivedAfter removes the clones of repositories archived on their
+	// provider once they have had no activity for this long, e.g. "90d"
+	ArchivedAfter string `yaml:"archived_after,omitempty"`
+
+	// Keep rules remove all but the newest files matching a pattern
+	Keep []KeepRule `yaml:"keep,omitempty"`
+}
+
+// ThemeConfig chooses the symbols that mark the state of repositories in
+// output. Name is "emoji" (default) or "ascii"; each symbol set here
+// replaces the one of the named theme.
+type ThemeConfig struct {
+	Name    string `yaml:"name,omitempty"`
+	Cloned  string `yaml:"cloned,omitempty"`
+	Dirty   string `yaml:"dirty,omitempty"`
+	Missing string `yaml:"missing,omitempty"`
+	Warning string `yaml:"warning,omitempty"`
+	Error   string `yaml:"error,omitempty"`
+}
+
+// KeepRule keeps the newest Last files or directories matching Path, a glob
+// such as "~/backups/*.bundle"
+type KeepRule struct {
+	Path string `yaml:"path"`
+	Last int    `yaml:"last"`
+}
+
+type AuditConfig struct {
+	Files []AuditFile `yaml:"files,omitempty"`
+}
+
+// AuditFile is a file every matching repository must contain
+type AuditFile struct {
+	// Path is relative to the repository root, e.g. "LICENSE"
+	Path string `yaml:"path"`
+
+	// Template is a golden copy the file must match. Relative paths are
+	// resolved against the config file's directory. Without a template only
+	// the file's presence is checked.
+	Template string `yaml:"template,omitempty"`
+
+	// Include limits the check to repositories whose path matches a glob
+	// or re:<regex>
+	Include []string `yaml:"include,omitempty"`
 }
 
 // Legacy LocalConfig with different field name
@@ -44,13 +274,235 @@ type LegacyConfig struct {
 	Local  LegacyLocalConfig `yaml:"local"`
 }
 
-func Load() (*Config, error) {
+// ErrReadOnly is returned for changes that read-only mode forbids
+var ErrReadOnly = errors.New("read-only mode is enabled")
+
+var readOnly bool
+
+// SetReadOnly forbids writing the config file
+func SetReadOnly(enabled bool) {
+	readOnly = enabled
+}
+
+// EnvFilePath names the environment variable that moves the config file
+const EnvFilePath = "GITSTUFF_CONFIG"
+
+var filePath string
+
+// SetFilePath makes the config file path, such as one given with --config,
+// take precedence over GITSTUFF_CONFIG and the default; "" removes it
+func SetFilePath(path string) {
+	filePath = path
+}
+
+// EnvProfile names the environment variable that selects a profile
+const EnvProfile = "GITSTUFF_PROFILE"
+
+var profile string
+
+// SetProfile selects a profile, such as one given with --profile, over
+// GITSTUFF_PROFILE; "" removes it
+func SetProfile(name string) {
+	profile = name
+}
+
+// Profile returns the name of the selected profile, or "" for the default
+// configuration. A config file set with SetFilePath overrides profiles, and
+// --profile overrides $GITSTUFF_CONFIG, which overrides $GITSTUFF_PROFILE.
+func Profile() string {
+	if filePath != "" {
+		return ""
+	}
+	if profile != "" {
+		return profile
+	}
+	if os.Getenv(EnvFilePath) != "" {
+		return ""
+	}
+	return os.Getenv(EnvProfile)
+}
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ProfilePath returns the path of the config file of the named profile,
+// profiles/<name>.yaml in the config directory, or .gitstuff.<name>.yaml in
+// the home directory until that is migrated
+func ProfilePath(name string) (string, error) {
+	if !profileName.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	path, legacy, err := profilePaths(name)
+	if err != nil {
+		return "", err
+	}
+	return locate(path, legacy), nil
+}
+
+// Profiles returns the names of the profiles that have a config file, sorted
+func Profiles() ([]string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user home directory: %w", err)
 	}
+	current, _ := filepath.Glob(filepath.Join(dir, "profiles", "*.yaml"))
+	legacy, _ := filepath.Glob(filepath.Join(home, ".gitstuff.*.yaml"))
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range append(current, legacy...) {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), ".gitstuff."), ".yaml")
+		if profileName.MatchString(name) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FilePath returns the path of the config file: that of the selected
+// profile, if any, else DefaultFilePath
+func FilePath() (string, error) {
+	if name := Profile(); name != "" {
+		return ProfilePath(name)
+	}
+	return DefaultFilePath()
+}
+
+// DefaultFilePath returns the path of the config file used without a
+// profile: the one set with SetFilePath, else $GITSTUFF_CONFIG, else
+// config.yaml in the config directory, or .gitstuff.yaml in the home
+// directory until that is migrated
+func DefaultFilePath() (string, error) {
+	if filePath != "" {
+		return filePath, nil
+	}
+	if path := os.Getenv(EnvFilePath); path != "" {
+		return path, nil
+	}
+	path, legacy, err := profilePaths("")
+	if err != nil {
+		return "", err
+	}
+	return locate(path, legacy), nil
+}
+
+// ReadStored returns the configuration as stored in the config file, without
+// resolving tokens, migrating legacy settings or applying defaults
+func ReadStored() (*Config, error) {
+	configPath, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	return ReadFile(configPath)
+}
+
+// ReadFile returns the configuration stored in the config file at
+// configPath, like ReadStored
+func ReadFile(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("config file not found at %s - run 'gitstuff config' to set up", configPath)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return &config, nil
+}
+
+// RemoveProvider deletes the named provider from the config file, along with
+// its keychain entry if it has one
+func RemoveProvider(name string) error {
+	config, err := ReadStored()
+	if err != nil {
+		return err
+	}
+
+	for i, provider := range config.Providers {
+		if provider.Name != name {
+			continue
+		}
+		if provider.usesKeyring() {
+			if err := credentials.Delete(credentialKey(name)); err != nil && !errors.Is(err, ErrCredentialNotFound) {
+				return fmt.Errorf("failed to delete token from keyring: %w", err)
+			}
+		}
+		config.Providers = append(config.Providers[:i], config.Providers[i+1:]...)
+
+		configPath, err := FilePath()
+		if err != nil {
+			return err
+		}
+		return saveConfig(config, configPath)
+	}
+
+	return fmt.Errorf("provider %s not found", name)
+}
+
+// UpdateProvider applies update to the named provider and saves the config
+// file. A token set on a keyring provider is stored in the keychain.
+func UpdateProvider(name string, update func(*ProviderConfig)) error {
+	config, err := ReadStored()
+	if err != nil {
+		return err
+	}
 
-	configPath := filepath.Join(home, ".gitstuff.yaml")
+	for i := range config.Providers {
+		provider := &config.Providers[i]
+		if provider.Name != name {
+			continue
+		}
+
+		update(provider)
+		provider.Name = name
+		if provider.Type != "gitlab" && provider.Type != "github" {
+			return fmt.Errorf("unsupported provider type: %s (supported: gitlab, github)", provider.Type)
+		}
+		if provider.URL == "" {
+			return fmt.Errorf("provider URL is required")
+		}
+		if err := provider.checkProtocol(); err != nil {
+			return err
+		}
+		if provider.usesKeyring() && provider.Token != "" {
+			if err := credentials.Set(credentialKey(name), provider.Token); err != nil {
+				return fmt.Errorf("failed to store token in keyring: %w", err)
+			}
+		}
+		if provider.tokenInFile() && provider.Token == "" {
+			return fmt.Errorf("provider token is required")
+		}
+
+		configPath, err := FilePath()
+		if err != nil {
+			return err
+		}
+		return saveConfig(config, configPath)
+	}
+
+	return fmt.Errorf("provider %s not found", name)
+}
+
+// checkProtocol rejects a clone protocol other than ssh or https
+func (p *ProviderConfig) checkProtocol() error {
+	switch p.Protocol {
+	case "", "ssh", "https":
+		return nil
+	}
+	return fmt.Errorf("provider %s has unsupported protocol %s (supported: ssh, https)", p.Name, p.Protocol)
+}
+
+func Load() (*Config, error) {
+	configPath, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
 
 	// Read config file directly
 	data, err := os.ReadFile(configPath)
@@ -58,6 +510,10 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("config file not found at %s - run 'gitstuff config' to set up", configPath)
 	}
 
+	if problems := Validate(data); len(problems) > 0 {
+		return nil, &ValidationError{Path: configPath, Problems: problems}
+	}
+
 	var config Config
 	var legacyConfig LegacyConfig
 
@@ -66,6 +522,13 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Move a config file from the home directory to the config directory
+	if !readOnly && !config.ReadOnly {
+		if configPath, err = migrateLegacyFile(configPath, &config); err != nil {
+			return nil, err
+		}
+	}
+
 	// If no providers but legacy GitLab config exists, migrate it
 	if len(config.Providers) == 0 {
 		if err := yaml.Unmarshal(data, &legacyConfig); err == nil && legacyConfig.GitLab.URL != "" {
@@ -81,13 +544,22 @@ func Load() (*Config, error) {
 			}
 			config.Local = LocalConfig{BaseDir: legacyConfig.Local.BaseDir}
 
-			// Save migrated config
-			if saveErr := saveConfig(&config, configPath); saveErr != nil {
-				return nil, fmt.Errorf("failed to save migrated config: %w", saveErr)
+			// Save migrated config, unless the file may not be written
+			if !readOnly && !config.ReadOnly {
+				if saveErr := saveConfig(&config, configPath); saveErr != nil {
+					return nil, fmt.Errorf("failed to save migrated config: %w", saveErr)
+				}
 			}
 		}
 	}
 
+	for i := range config.Providers {
+		if err := resolveToken(&config.Providers[i]); err != nil {
+			return nil, err
+		}
+		redact.AddSecret(config.Providers[i].Token)
+	}
+
 	if len(config.Providers) == 0 {
 		return nil, fmt.Errorf("no SCM providers configured - run 'gitstuff config' to set up")
 	}
@@ -100,43 +572,177 @@ func Load() (*Config, error) {
 		if provider.Type != "gitlab" && provider.Type != "github" {
 			return nil, fmt.Errorf("provider %s has unsupported type %s", provider.Name, provider.Type)
 		}
+		if err := provider.checkProtocol(); err != nil {
+			return nil, err
+		}
+	}
+	for pattern := range config.URLRewrites {
+		if pattern == "" {
+			return nil, fmt.Errorf("url_rewrites has an empty pattern")
+		}
 	}
 
 	if config.Local.BaseDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		if config.Local.BaseDir, err = DefaultBaseDir(); err != nil {
+			return nil, err
 		}
-		config.Local.BaseDir = filepath.Join(home, "gitstuff-repos")
 	}
 
 	return &config, nil
 }
 
+// CacheDir returns the directory for cached data. It defaults to gitstuff
+// under $XDG_CACHE_HOME or the user cache directory (e.g. ~/.cache/gitstuff).
+func (c *Config) CacheDir() (string, error) {
+	if c.Cache.Dir != "" {
+		return c.Cache.Dir, nil
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "gitstuff"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "gitstuff"), nil
+}
+
+// StateDir returns the directory for data that is not worth backing up but
+// should survive clearing the cache, such as which hints were shown. It is
+// the cache directory when cache.dir is set, and otherwise gitstuff under
+// $XDG_STATE_HOME (by default ~/.local/state/gitstuff).
+func (c *Config) StateDir() (string, error) {
+	if c.Cache.Dir != "" {
+		return c.Cache.Dir, nil
+	}
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// StatePath returns the path of the named file in the state directory.
+// A file older versions kept in the cache directory is moved there first.
+func (c *Config) StatePath(name string) (string, error) {
+	dir, err := c.StateDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	cacheDir, err := c.CacheDir()
+	if err != nil || cacheDir == dir {
+		return path, nil
+	}
+	if old := filepath.Join(cacheDir, name); !fileExists(path) && fileExists(old) && !readOnly {
+		if err := os.MkdirAll(dir, 0755); err == nil {
+			_ = os.Rename(old, path)
+		}
+	}
+	return path, nil
+}
+
+// MetadataCacheTTL returns the configured repository metadata TTL, or zero
+// when the default should be used
+func (c *Config) MetadataCacheTTL() (time.Duration, error) {
+	if c.Cache.MetadataTTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(c.Cache.MetadataTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache.metadata_ttl %q: %w", c.Cache.MetadataTTL, err)
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid cache.metadata_ttl %q: must be positive", c.Cache.MetadataTTL)
+	}
+	return ttl, nil
+}
+
+// DefaultRetryDelay is how long to wait before the first retry when
+// git.retry_delay is not set
+const DefaultRetryDelay = 2 * time.Second
+
+// GitRetryDelay returns the configured delay before the first retry
+func (c *Config) GitRetryDelay() (time.Duration, error) {
+	if c.Git.RetryDelay == "" {
+		return DefaultRetryDelay, nil
+	}
+	delay, err := time.ParseDuration(c.Git.RetryDelay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid git.retry_delay %q: %w", c.Git.RetryDelay, err)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("invalid git.retry_delay %q: must not be negative", c.Git.RetryDelay)
+	}
+	return delay, nil
+}
+
+// GitTimeout returns the configured git operation timeout, or zero when
+// there is none
+func (c *Config) GitTimeout() (time.Duration, error) {
+	if c.Git.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.Git.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid git.timeout %q: %w", c.Git.Timeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid git.timeout %q: must be positive", c.Git.Timeout)
+	}
+	return timeout, nil
+}
+
 func AddProvider(name, providerType, url, token, baseDir string, insecure bool, group string) error {
+	return AddProviderConfig(ProviderConfig{
+		Name:     name,
+		Type:     providerType,
+		URL:      url,
+		Token:    token,
+		Insecure: insecure,
+		Group:    group,
+	}, baseDir)
+}
+
+// DefaultBaseDir returns the base directory used when local.base_dir is not
+// set, gitstuff-repos in the home directory
+func DefaultBaseDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, "gitstuff-repos"), nil
+}
+
+// AddProviderConfig adds provider to the config file, replacing any provider
+// with the same name. With token_source: keyring the token is stored in the
+// keychain instead of the file.
+func AddProviderConfig(provider ProviderConfig, baseDir string) error {
 	// Validate input parameters
-	if name == "" {
+	if provider.Name == "" {
 		return fmt.Errorf("provider name is required")
 	}
-	if providerType == "" {
+	if provider.Type == "" {
 		return fmt.Errorf("provider type is required")
 	}
-	if providerType != "gitlab" && providerType != "github" {
-		return fmt.Errorf("unsupported provider type: %s (supported: gitlab, github)", providerType)
+	if provider.Type != "gitlab" && provider.Type != "github" {
+		return fmt.Errorf("unsupported provider type: %s (supported: gitlab, github)", provider.Type)
 	}
-	if url == "" {
+	if provider.URL == "" {
 		return fmt.Errorf("provider URL is required")
 	}
-	if token == "" {
+	if provider.Token == "" && provider.TokenEnv == "" && provider.TokenCmd == "" {
 		return fmt.Errorf("provider token is required")
 	}
+	if err := provider.checkProtocol(); err != nil {
+		return err
+	}
 
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get user home directory: %w", err)
 	}
 
-	configPath := filepath.Join(home, ".gitstuff.yaml")
+	configPath, err := FilePath()
+	if err != nil {
+		return err
+	}
 
 	// Load existing config or create new one
 	var config Config
@@ -154,40 +760,49 @@ func AddProvider(name, providerType, url, token, baseDir string, insecure bool,
 		config.Local.BaseDir = baseDir
 	}
 
+	if provider.usesKeyring() {
+		if err := credentials.Set(credentialKey(provider.Name), provider.Token); err != nil {
+			return fmt.Errorf("failed to store token in keyring: %w", err)
+		}
+	}
+
 	// Check if provider already exists
-	for i, provider := range config.Providers {
-		if provider.Name == name {
-			config.Providers[i] = ProviderConfig{
-				Name:     name,
-				Type:     providerType,
-				URL:      url,
-				Token:    token,
-				Insecure: insecure,
-				Group:    group,
-			}
+	for i, existing := range config.Providers {
+		if existing.Name == provider.Name {
+			config.Providers[i] = provider
 			return saveConfig(&config, configPath)
 		}
 	}
 
 	// Add new provider
-	config.Providers = append(config.Providers, ProviderConfig{
-		Name:     name,
-		Type:     providerType,
-		URL:      url,
-		Token:    token,
-		Insecure: insecure,
-		Group:    group,
-	})
+	config.Providers = append(config.Providers, provider)
 
 	return saveConfig(&config, configPath)
 }
 
 func saveConfig(config *Config, configPath string) error {
-	data, err := yaml.Marshal(config)
+	if readOnly || config.ReadOnly {
+		return fmt.Errorf("refusing to write %s: %w", configPath, ErrReadOnly)
+	}
+
+	// Never write tokens that were resolved from somewhere else
+	toSave := *config
+	toSave.Providers = make([]ProviderConfig, len(config.Providers))
+	for i, provider := range config.Providers {
+		if !provider.tokenInFile() {
+			provider.Token = ""
+		}
+		toSave.Providers[i] = provider
+	}
+
+	data, err := yaml.Marshal(&toSave)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
 	err = os.WriteFile(configPath, data, 0600)
 	if err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
@@ -1,14 +1,42 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"gitstuff/internal/redact"
+
+	"github.com/zalando/go-keyring"
 	"gopkg.in/yaml.v3"
 )
 
+// TestMain keeps the tests, which point $HOME at temporary directories, from
+// finding the real config and cache through the XDG variables
+func TestMain(m *testing.M) {
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", EnvFilePath, EnvProfile} {
+		os.Unsetenv(env)
+	}
+	os.Exit(m.Run())
+}
+
+// configFile returns the path of the default config file under home,
+// creating its directory
+func configFile(t *testing.T, home string) string {
+	t.Helper()
+	dir := filepath.Join(home, ".config", "gitstuff")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
 func TestAddProvider_GitLab(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -319,7 +347,7 @@ local:
 
 func TestLoad_MultiProvider(t *testing.T) {
 	tempDir := t.TempDir()
-	configPath := filepath.Join(tempDir, ".gitstuff.yaml")
+	configPath := configFile(t, tempDir)
 
 	originalHome := os.Getenv("HOME")
 	t.Cleanup(func() {
@@ -423,3 +451,698 @@ func TestLoad_MultiProvider(t *testing.T) {
 		t.Errorf("Expected base dir '/multi/provider/dir', got '%s'", config.Local.BaseDir)
 	}
 }
+
+func TestCacheDir(t *testing.T) {
+	t.Run("configured directory", func(t *testing.T) {
+		cfg := &Config{Cache: CacheConfig{Dir: "/var/cache/gitstuff"}}
+		dir, err := cfg.CacheDir()
+		if err != nil {
+			t.Fatalf("CacheDir failed: %v", err)
+		}
+		if dir != "/var/cache/gitstuff" {
+			t.Errorf("Expected configured directory, got %s", dir)
+		}
+	})
+
+	t.Run("default directory", func(t *testing.T) {
+		t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+		t.Setenv("HOME", "/tmp/home")
+
+		dir, err := (&Config{}).CacheDir()
+		if err != nil {
+			t.Fatalf("CacheDir failed: %v", err)
+		}
+		if dir != filepath.Join("/tmp/xdg-cache", "gitstuff") {
+			t.Errorf("Expected the directory under $XDG_CACHE_HOME, got %s", dir)
+		}
+	})
+}
+
+func TestStatePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+
+	old := filepath.Join(home, "cache", "gitstuff", "hints.json")
+	if err := os.MkdirAll(filepath.Dir(old), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(old, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := (&Config{}).StatePath("hints.json")
+	if err != nil {
+		t.Fatalf("StatePath() error = %v", err)
+	}
+	if want := filepath.Join(home, "state", "gitstuff", "hints.json"); path != want {
+		t.Errorf("StatePath() = %s, want %s", path, want)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the file in the cache directory to be moved: %v", err)
+	}
+
+	// With cache.dir set, state stays next to the cache
+	cfg := &Config{Cache: CacheConfig{Dir: filepath.Join(home, "custom")}}
+	if path, _ := cfg.StatePath("hints.json"); path != filepath.Join(home, "custom", "hints.json") {
+		t.Errorf("Expected the state file in cache.dir, got %s", path)
+	}
+}
+
+func TestCacheConfig_RoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	configData := `providers:
+  - name: gitlab
+    type: gitlab
+    url: https://gitlab.com
+    token: token
+cache:
+  dir: /srv/cache
+  disable_http: true
+`
+	if err := os.WriteFile(configFile(t, tempDir), []byte(configData), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Cache.Dir != "/srv/cache" || !cfg.Cache.DisableHTTP {
+		t.Errorf("Expected cache settings to be loaded, got %+v", cfg.Cache)
+	}
+
+	// Adding a provider must keep the cache settings intact
+	if err := AddProvider("github", "github", "https://github.com", "gh", "", false, ""); err != nil {
+		t.Fatalf("AddProvider failed: %v", err)
+	}
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Cache.Dir != "/srv/cache" || !cfg.Cache.DisableHTTP {
+		t.Errorf("Expected cache settings to survive AddProvider, got %+v", cfg.Cache)
+	}
+}
+
+func TestLoad_RegistersTokensForRedaction(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Cleanup(redact.ResetSecrets)
+
+	testConfig := Config{
+		Providers: []ProviderConfig{
+			{Name: "gitlab", Type: "gitlab", URL: "https://gitlab.com", Token: "plain-config-token"},
+		},
+	}
+	data, err := yaml.Marshal(&testConfig)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	if err := os.WriteFile(configFile(t, tempDir), data, 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if got := redact.String("token=plain-config-token"); strings.Contains(got, "plain-config-token") {
+		t.Errorf("Expected configured token to be redacted, got %q", got)
+	}
+}
+
+func TestMetadataCacheTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset", value: "", want: 0},
+		{name: "minutes", value: "30m", want: 30 * time.Minute},
+		{name: "invalid", value: "soon", wantErr: true},
+		{name: "negative", value: "-1h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Cache: CacheConfig{MetadataTTL: tt.value}}
+			got, err := cfg.MetadataCacheTTL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MetadataCacheTTL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("MetadataCacheTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitRetryDelay(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: DefaultRetryDelay},
+		{value: "0s", want: 0},
+		{value: "500ms", want: 500 * time.Millisecond},
+		{value: "later", wantErr: true},
+		{value: "-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{Git: GitConfig{RetryDelay: tt.value}}
+		got, err := cfg.GitRetryDelay()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("GitRetryDelay(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("GitRetryDelay(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGitTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "10m", want: 10 * time.Minute},
+		{value: "forever", wantErr: true},
+		{value: "0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{Git: GitConfig{Timeout: tt.value}}
+		got, err := cfg.GitTimeout()
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("GitTimeout(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("GitTimeout(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAddProviderConfig_Keyring(t *testing.T) {
+	keyring.MockInit()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	err := AddProviderConfig(ProviderConfig{
+		Name:        "work",
+		Type:        "gitlab",
+		URL:         "https://gitlab.example.com",
+		Token:       "glpat-keyring-secret",
+		TokenSource: TokenSourceKeyring,
+	}, "")
+	if err != nil {
+		t.Fatalf("AddProviderConfig failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configFile(t, tempDir))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "glpat-keyring-secret") {
+		t.Errorf("Expected token to be kept out of the config file, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "token_source: keyring") {
+		t.Errorf("Expected token_source in config file, got:\n%s", data)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Providers[0].Token != "glpat-keyring-secret" {
+		t.Errorf("Expected token from keyring, got %q", cfg.Providers[0].Token)
+	}
+}
+
+func TestLoad_TokenSourceErrors(t *testing.T) {
+	keyring.MockInit()
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{name: "missing keyring entry", source: "keyring", wantErr: "failed to read token from keyring"},
+		{name: "unknown source", source: "vault", wantErr: `unsupported token_source "vault"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Setenv("HOME", tempDir)
+			content := "providers:\n  - name: absent\n    type: github\n    url: https://github.com\n    token_source: " + tt.source + "\n"
+			if err := os.WriteFile(configFile(t, tempDir), []byte(content), 0600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_Protocol(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	configPath := configFile(t, tempDir)
+	content := "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n    token: secret\n    protocol: https\n"
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Providers[0].Protocol != "https" {
+		t.Errorf("Expected protocol https, got %q", cfg.Providers[0].Protocol)
+	}
+
+	content = strings.Replace(content, "protocol: https", "protocol: git", 1)
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "unsupported protocol git") {
+		t.Errorf("Expected an unsupported protocol error, got %v", err)
+	}
+}
+
+func TestFilePath_Override(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Cleanup(func() { SetFilePath("") })
+	content := "providers:\n  - name: %s\n    type: gitlab\n    url: https://gitlab.example.com\n    token: secret\n"
+	for _, name := range []string{"home", "env", "flag"} {
+		path := filepath.Join(tempDir, name+".yaml")
+		if name == "home" {
+			path = filepath.Join(tempDir, ".gitstuff.yaml")
+		}
+		if err := os.WriteFile(path, []byte(fmt.Sprintf(content, name)), 0600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	loaded := func() string {
+		t.Helper()
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return cfg.Providers[0].Name
+	}
+	if got := loaded(); got != "home" {
+		t.Errorf("Expected the config in the home directory, got %q", got)
+	}
+	t.Setenv(EnvFilePath, filepath.Join(tempDir, "env.yaml"))
+	if got := loaded(); got != "env" {
+		t.Errorf("Expected the config from %s, got %q", EnvFilePath, got)
+	}
+	SetFilePath(filepath.Join(tempDir, "flag.yaml"))
+	if got := loaded(); got != "flag" {
+		t.Errorf("Expected the config set with SetFilePath, got %q", got)
+	}
+
+	// Changes are written to the same file
+	if err := UpdateProvider("flag", func(p *ProviderConfig) { p.Group = "team" }); err != nil {
+		t.Fatalf("UpdateProvider() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "flag.yaml")); !strings.Contains(string(data), "group: team") {
+		t.Errorf("Expected the change in flag.yaml, got:\n%s", data)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	keyring.MockInit()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Cleanup(func() { SetProfile("") })
+	add := func(name string) {
+		t.Helper()
+		err := AddProviderConfig(ProviderConfig{
+			Name:        "github",
+			Type:        "github",
+			URL:         "https://api.github.com",
+			Token:       name + "-token",
+			TokenSource: TokenSourceKeyring,
+		}, filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatalf("AddProviderConfig() error = %v", err)
+		}
+	}
+
+	add("personal")
+	t.Setenv(EnvProfile, "work")
+	add("work")
+	SetProfile("oss")
+	add("oss")
+
+	loaded := func(name, want string) {
+		t.Helper()
+		SetProfile(name)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() with profile %q error = %v", name, err)
+		}
+		if cfg.Local.BaseDir != filepath.Join(tempDir, want) || cfg.Providers[0].Token != want+"-token" {
+			t.Errorf("Profile %q loaded base dir %s and token %s, want those of %s", name, cfg.Local.BaseDir, cfg.Providers[0].Token, want)
+		}
+	}
+	loaded("oss", "oss")
+	loaded("", "work")
+	t.Setenv(EnvProfile, "")
+	loaded("", "personal")
+
+	got, err := Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() error = %v", err)
+	}
+	if want := []string{"oss", "work"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Profiles() = %v, want %v", got, want)
+	}
+
+	// A config file given explicitly wins over any profile
+	SetFilePath(filepath.Join(tempDir, "explicit.yaml"))
+	defer SetFilePath("")
+	if Profile() != "" {
+		t.Errorf("Expected no profile with an explicit config file, got %q", Profile())
+	}
+
+	if _, err := ProfilePath("../etc"); err == nil {
+		t.Error("Expected an error for a profile name with a path")
+	}
+}
+
+func TestLoad_MigratesLegacyFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { SetReadOnly(false) })
+	legacy := filepath.Join(home, ".gitstuff.yaml")
+	content := "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n    token: secret\n"
+	if err := os.WriteFile(legacy, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Read-only mode reads the legacy file where it is
+	SetReadOnly(true)
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if path, _ := FilePath(); path != legacy {
+		t.Errorf("Expected the legacy file to be used in read-only mode, got %s", path)
+	}
+
+	SetReadOnly(false)
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := filepath.Join(home, ".config", "gitstuff", "config.yaml")
+	if path, _ := FilePath(); path != want {
+		t.Errorf("FilePath() = %s, want %s", path, want)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be moved, got %v", legacy, err)
+	}
+
+	// $XDG_CONFIG_HOME moves the config directory
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	if path, _ := FilePath(); path != filepath.Join(home, "xdg", "gitstuff", "config.yaml") {
+		t.Errorf("Expected the config file under $XDG_CONFIG_HOME, got %s", path)
+	}
+}
+
+func TestLoad_URLRewrites(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	configPath := configFile(t, tempDir)
+	content := "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n    token: secret\nurl_rewrites:\n  https://gitlab.example.com/: https://mirror.internal/\n"
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.URLRewrites["https://gitlab.example.com/"]; got != "https://mirror.internal/" {
+		t.Errorf("Expected the rewrite to be loaded, got %v", cfg.URLRewrites)
+	}
+
+	content = strings.Replace(content, "https://gitlab.example.com/: ", `"": `, 1)
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "empty pattern") {
+		t.Errorf("Expected an empty pattern error, got %v", err)
+	}
+}
+
+func TestMigrateTokensToKeyring(t *testing.T) {
+	keyring.MockInit()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	if err := AddProvider("gitlab", "gitlab", "https://gitlab.com", "gl-plain", "", false, ""); err != nil {
+		t.Fatalf("AddProvider failed: %v", err)
+	}
+	if err := AddProvider("github", "github", "https://github.com", "gh-plain", "", false, ""); err != nil {
+		t.Fatalf("AddProvider failed: %v", err)
+	}
+
+	migrated, err := MigrateTokensToKeyring()
+	if err != nil {
+		t.Fatalf("MigrateTokensToKeyring failed: %v", err)
+	}
+	if strings.Join(migrated, ",") != "gitlab,github" {
+		t.Errorf("Expected both providers to be migrated, got %v", migrated)
+	}
+
+	data, _ := os.ReadFile(configFile(t, tempDir))
+	if strings.Contains(string(data), "gl-plain") || strings.Contains(string(data), "gh-plain") {
+		t.Errorf("Expected plaintext tokens to be removed, got:\n%s", data)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Providers[0].Token != "gl-plain" || cfg.Providers[1].Token != "gh-plain" {
+		t.Errorf("Expected tokens from keyring, got %q and %q", cfg.Providers[0].Token, cfg.Providers[1].Token)
+	}
+
+	again, err := MigrateTokensToKeyring()
+	if err != nil || len(again) != 0 {
+		t.Errorf("Expected nothing left to migrate, got %v (%v)", again, err)
+	}
+}
+
+func TestLoad_TokenEnvAndCmd(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available in PATH")
+	}
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	t.Setenv("GITSTUFF_TEST_GITLAB_TOKEN", "glpat-from-env")
+
+	content := `providers:
+  - name: ci
+    type: gitlab
+    url: https://gitlab.com
+    token_env: GITSTUFF_TEST_GITLAB_TOKEN
+  - name: personal
+    type: github
+    url: https://github.com
+    token_cmd: "printf 'ghp-from-cmd\n'"
+`
+	if err := os.WriteFile(configFile(t, tempDir), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Providers[0].Token != "glpat-from-env" {
+		t.Errorf("Expected token from environment, got %q", cfg.Providers[0].Token)
+	}
+	if cfg.Providers[1].Token != "ghp-from-cmd" {
+		t.Errorf("Expected token from command, got %q", cfg.Providers[1].Token)
+	}
+	if got := redact.String("token ghp-from-cmd"); strings.Contains(got, "ghp-from-cmd") {
+		t.Errorf("Expected command token to be registered for redaction, got %q", got)
+	}
+}
+
+func TestResolveToken_Errors(t *testing.T) {
+	t.Setenv("GITSTUFF_TEST_EMPTY", "")
+
+	tests := []struct {
+		name     string
+		provider ProviderConfig
+		wantErr  string
+	}{
+		{name: "unset env", provider: ProviderConfig{Name: "a", TokenEnv: "GITSTUFF_TEST_EMPTY"}, wantErr: "environment variable GITSTUFF_TEST_EMPTY is not set"},
+		{name: "failing cmd", provider: ProviderConfig{Name: "b", TokenCmd: "exit 3"}, wantErr: "token_cmd failed"},
+		{name: "empty cmd output", provider: ProviderConfig{Name: "c", TokenCmd: "true"}, wantErr: "token_cmd printed no token"},
+		{name: "several sources", provider: ProviderConfig{Name: "d", TokenEnv: "X", TokenCmd: "echo x"}, wantErr: "only one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := tt.provider
+			err := resolveToken(&provider)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAddProviderConfig_TokenEnvNotSaved(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	err := AddProviderConfig(ProviderConfig{Name: "ci", Type: "github", URL: "https://github.com", Token: "ghp-resolved", TokenEnv: "GITHUB_TOKEN"}, "")
+	if err != nil {
+		t.Fatalf("AddProviderConfig failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(configFile(t, tempDir))
+	if !strings.Contains(string(data), "token_env: GITHUB_TOKEN") || strings.Contains(string(data), "ghp-resolved") {
+		t.Errorf("Expected token_env without a token, got:\n%s", data)
+	}
+}
+
+func TestRemoveProvider(t *testing.T) {
+	keyring.MockInit()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	if err := AddProvider("gitlab", "gitlab", "https://gitlab.com", "gl-token", "", false, ""); err != nil {
+		t.Fatalf("AddProvider failed: %v", err)
+	}
+	keyringProvider := ProviderConfig{Name: "github", Type: "github", URL: "https://github.com", Token: "gh-token", TokenSource: TokenSourceKeyring}
+	if err := AddProviderConfig(keyringProvider, ""); err != nil {
+		t.Fatalf("AddProviderConfig failed: %v", err)
+	}
+
+	if err := RemoveProvider("github"); err != nil {
+		t.Fatalf("RemoveProvider failed: %v", err)
+	}
+	if _, err := credentials.Get("github"); !errors.Is(err, ErrCredentialNotFound) {
+		t.Errorf("Expected keyring token to be deleted, got %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Providers) != 1 || cfg.Providers[0].Name != "gitlab" {
+		t.Errorf("Expected only gitlab to remain, got %+v", cfg.Providers)
+	}
+
+	if err := RemoveProvider("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestUpdateProvider(t *testing.T) {
+	keyring.MockInit()
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	if err := AddProvider("work", "gitlab", "https://gitlab.com", "old-token", "", false, "team"); err != nil {
+		t.Fatalf("AddProvider failed: %v", err)
+	}
+
+	err := UpdateProvider("work", func(p *ProviderConfig) {
+		p.Name = "renamed"
+		p.URL = "https://gitlab.example.com"
+		p.Group = ""
+		p.TokenSource = TokenSourceKeyring
+	})
+	if err != nil {
+		t.Fatalf("UpdateProvider failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(configFile(t, tempDir))
+	if strings.Contains(string(data), "old-token") {
+		t.Errorf("Expected token to move to the keyring, got:\n%s", data)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	provider := cfg.Providers[0]
+	if provider.Name != "work" || provider.URL != "https://gitlab.example.com" || provider.Group != "" || provider.Token != "old-token" {
+		t.Errorf("Unexpected provider after update: %+v", provider)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		update func(*ProviderConfig)
+		want   string
+	}{
+		{name: "missing provider", target: "other", update: func(*ProviderConfig) {}, want: "not found"},
+		{name: "bad type", target: "work", update: func(p *ProviderConfig) { p.Type = "bitbucket" }, want: "unsupported provider type"},
+		{name: "empty url", target: "work", update: func(p *ProviderConfig) { p.URL = "" }, want: "URL is required"},
+		{name: "no token", target: "work", update: func(p *ProviderConfig) { p.TokenSource, p.Token = "", "" }, want: "token is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UpdateProvider(tt.target, tt.update)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	configPath := configFile(t, tempDir)
+
+	legacy := "gitlab:\n  url: https://gitlab.example.com\n  token: legacy-token\nlocal:\n  basedir: /legacy/dir\n"
+	if err := os.WriteFile(configPath, []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	SetReadOnly(true)
+	cfg, err := Load()
+	SetReadOnly(false)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Providers) != 1 {
+		t.Errorf("Expected the legacy config to be migrated in memory, got %+v", cfg.Providers)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != legacy {
+		t.Errorf("Expected the legacy config file to be left alone, got:\n%s", data)
+	}
+
+	stored := "read_only: true\nproviders:\n  - name: gitlab\n    type: gitlab\n    url: https://gitlab.com\n    token: gl-token\n"
+	if err := os.WriteFile(configPath, []byte(stored), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	if err := RemoveProvider("gitlab"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly from RemoveProvider, got %v", err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != stored {
+		t.Errorf("Expected the config file to be left alone, got:\n%s", data)
+	}
+}
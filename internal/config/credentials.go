@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Token sources for ProviderConfig.TokenSource
+const (
+	// TokenSourceConfig keeps the token in the config file (the default)
+	TokenSourceConfig = "config"
+	// TokenSourceKeyring keeps the token in the operating system keychain
+	TokenSourceKeyring = "keyring"
+)
+
+// keyringService is the service name tokens are stored under in the keychain
+const keyringService = "gitstuff"
+
+// ErrCredentialNotFound is returned when a store has no token for a provider
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialStore keeps provider tokens outside the config file, keyed by
+// provider name
+type CredentialStore interface {
+	Get(provider string) (string, error)
+	Set(provider, token string) error
+	Delete(provider string) error
+}
+
+// KeyringStore stores tokens in the macOS Keychain, Windows Credential
+// Manager or a Secret Service implementation such as GNOME Keyring
+type KeyringStore struct{}
+
+func (KeyringStore) Get(provider string) (string, error) {
+	token, err := keyring.Get(keyringService, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrCredentialNotFound
+	}
+	return token, err
+}
+
+func (KeyringStore) Set(provider, token string) error {
+	return keyring.Set(keyringService, provider, token)
+}
+
+func (KeyringStore) Delete(provider string) error {
+	err := keyring.Delete(keyringService, provider)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrCredentialNotFound
+	}
+	return err
+}
+
+var credentials CredentialStore = KeyringStore{}
+
+// credentialKey is the name a provider's token is stored under, prefixed
+// with the selected profile so profiles can use the same provider names
+func credentialKey(provider string) string {
+	if name := Profile(); name != "" {
+		return name + "/" + provider
+	}
+	return provider
+}
+
+// usesKeyring reports whether the provider's token lives in the keychain
+func (p ProviderConfig) usesKeyring() bool {
+	return p.TokenSource == TokenSourceKeyring
+}
+
+// tokenInFile reports whether the provider's token is stored in the config
+// file rather than resolved when the config is loaded
+func (p ProviderConfig) tokenInFile() bool {
+	return !p.usesKeyring() && p.TokenEnv == "" && p.TokenCmd == ""
+}
+
+// resolveToken fills in the token of a provider whose token is not stored in
+// the config file
+func resolveToken(provider *ProviderConfig) error {
+	switch provider.TokenSource {
+	case "", TokenSourceConfig, TokenSourceKeyring:
+	default:
+		return fmt.Errorf("provider %s has unsupported token_source %q (expected %q or %q)",
+			provider.Name, provider.TokenSource, TokenSourceConfig, TokenSourceKeyring)
+	}
+
+	sources := 0
+	for _, set := range []bool{provider.usesKeyring(), provider.TokenEnv != "", provider.TokenCmd != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("provider %s: only one of token_source: keyring, token_env and token_cmd may be set", provider.Name)
+	}
+
+	switch {
+	case provider.usesKeyring():
+		token, err := credentials.Get(credentialKey(provider.Name))
+		if err != nil {
+			return fmt.Errorf("provider %s: failed to read token from keyring: %w", provider.Name, err)
+		}
+		provider.Token = token
+	case provider.TokenEnv != "":
+		token := strings.TrimSpace(os.Getenv(provider.TokenEnv))
+		if token == "" {
+			return fmt.Errorf("provider %s: environment variable %s is not set", provider.Name, provider.TokenEnv)
+		}
+		provider.Token = token
+	case provider.TokenCmd != "":
+		token, err := runTokenCommand(provider.TokenCmd)
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", provider.Name, err)
+		}
+		provider.Token = token
+	}
+	return nil
+}
+
+// runTokenCommand runs command through the shell and returns its output as
+// the token. Stdin and stderr stay attached so password managers can prompt.
+func runTokenCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token_cmd failed: %w", err)
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("token_cmd printed no token")
+	}
+	return token, nil
+}
+
+// MigrateTokensToKeyring moves plaintext tokens from the config file into the
+// keychain and switches those providers to token_source: keyring. It returns
+// the names of the migrated providers.
+func MigrateTokensToKeyring() ([]string, error) {
+	config, err := ReadStored()
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []string
+	for i, provider := range config.Providers {
+		if !provider.tokenInFile() || provider.Token == "" {
+			continue
+		}
+		if err := credentials.Set(credentialKey(provider.Name), provider.Token); err != nil {
+			return nil, fmt.Errorf("provider %s: failed to store token in keyring: %w", provider.Name, err)
+		}
+		config.Providers[i].TokenSource = TokenSourceKeyring
+		migrated = append(migrated, provider.Name)
+	}
+
+	if len(migrated) == 0 {
+		return nil, nil
+	}
+	configPath, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	return migrated, saveConfig(config, configPath)
+}
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is something wrong in a config file, at Line when it is known
+type Problem struct {
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	}
+	return p.Message
+}
+
+// ValidationError is returned by Load for a config file with problems
+type ValidationError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config file %s (run 'gitstuff config validate' for details):", e.Path)
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&b, "\n  %s", problem)
+	}
+	return b.String()
+}
+
+// Validate checks the contents of a config file strictly: unknown keys,
+// values of the wrong type, duplicate keys and provider names, and settings
+// that cannot work together. Keys of the legacy single-GitLab format are
+// accepted. Problems are sorted by line.
+func Validate(data []byte) []Problem {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return []Problem{yamlProblem(strings.TrimPrefix(err.Error(), "yaml: "))}
+		}
+	}
+
+	var problems []Problem
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return []Problem{yamlProblem(strings.TrimPrefix(err.Error(), "yaml: "))}
+		}
+		for _, msg := range typeErr.Errors {
+			if problem, ok := decodeProblem(msg); ok {
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	if len(root.Content) > 0 {
+		problems = append(problems, checkProviders(mappingValue(root.Content[0], "providers"))...)
+		problems = append(problems, checkLocal(mappingValue(root.Content[0], "local"))...)
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+var (
+	lineMessage  = regexp.MustCompile(`^line (\d+): (.*)$`)
+	unknownField = regexp.MustCompile(`^field (\S+) not found in type config\.(\w+)$`)
+	wrongType    = regexp.MustCompile("^cannot unmarshal !!\\w+ `(.*)` into (.+)$")
+)
+
+// yamlProblem turns a "line N: message" error of the YAML parser into a
+// Problem
+func yamlProblem(msg string) Problem {
+	if m := lineMessage.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return Problem{Line: line, Message: m[2]}
+	}
+	return Problem{Message: msg}
+}
+
+// decodeProblem rewords an error of the strict decoder, and reports false
+// for the keys of the legacy format
+func decodeProblem(msg string) (Problem, bool) {
+	problem := yamlProblem(msg)
+	if m := unknownField.FindStringSubmatch(problem.Message); m != nil {
+		key, typeName := m[1], m[2]
+		if (typeName == "Config" && key == "gitlab") || (typeName == "LocalConfig" && key == "basedir") {
+			return problem, false
+		}
+		problem.Message = fmt.Sprintf("unknown key %q", key)
+		if suggestion := closestKey(key, knownKeys()[typeName]); suggestion != "" {
+			problem.Message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+	} else if m := wrongType.FindStringSubmatch(problem.Message); m != nil {
+		problem.Message = fmt.Sprintf("invalid value %q: expected %s", m[1], typeDescription(m[2]))
+	}
+	return problem, true
+}
+
+func typeDescription(goType string) string {
+	switch {
+	case goType == "bool":
+		return "true or false"
+	case goType == "int":
+		return "a whole number"
+	case goType == "string":
+		return "a string"
+	case strings.HasPrefix(goType, "[]"):
+		return "a list"
+	default:
+		return "a mapping of keys to values"
+	}
+}
+
+// knownKeys returns the YAML keys of every struct in Config by type name
+func knownKeys() map[string][]string {
+	keys := make(map[string][]string)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || keys[t.Name()] != nil {
+			return
+		}
+		keys[t.Name()] = []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			keys[t.Name()] = append(keys[t.Name()], name)
+			walk(field.Type)
+		}
+	}
+	walk(reflect.TypeOf(Config{}))
+	return keys
+}
+
+// closestKey returns the key within two edits of key, if there is one
+func closestKey(key string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToLower(key), candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// checkProviders checks what the decoder cannot: duplicate names and the
+// settings every provider needs
+func checkProviders(providers *yaml.Node) []Problem {
+	if providers == nil || providers.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var problems []Problem
+	names := make(map[string]int)
+	for _, provider := range providers.Content {
+		if provider.Kind != yaml.MappingNode {
+			continue
+		}
+		name, label := mappingValue(provider, "name"), "provider"
+		if name != nil && name.Value != "" {
+			label = "provider " + name.Value
+		}
+		switch {
+		case name == nil || name.Value == "":
+			problems = append(problems, Problem{Line: provider.Line, Message: "provider has no name"})
+		case names[name.Value] > 0:
+			problems = append(problems, Problem{Line: name.Line, Message: fmt.Sprintf("duplicate provider name %q, first used on line %d", name.Value, names[name.Value])})
+		default:
+			names[name.Value] = name.Line
+		}
+
+		if providerType := mappingValue(provider, "type"); providerType == nil {
+			problems = append(problems, Problem{Line: provider.Line, Message: label + " has no type (gitlab or github)"})
+		} else if providerType.Value != "gitlab" && providerType.Value != "github" {
+			problems = append(problems, Problem{Line: providerType.Line, Message: fmt.Sprintf("%s has unsupported type %s (supported: gitlab, github)", label, providerType.Value)})
+		}
+		if url := mappingValue(provider, "url"); url == nil || url.Value == "" {
+			problems = append(problems, Problem{Line: provider.Line, Message: label + " has no url"})
+		}
+		if protocol := mappingValue(provider, "protocol"); protocol != nil && protocol.Value != "" && protocol.Value != "ssh" && protocol.Value != "https" {
+			problems = append(problems, Problem{Line: protocol.Line, Message: fmt.Sprintf("%s has unsupported protocol %s (supported: ssh, https)", label, protocol.Value)})
+		}
+	}
+	return problems
+}
+
+// checkLocal checks that the archive directory is apart from the base
+// directory
+func checkLocal(local *yaml.Node) []Problem {
+	baseDir, archiveDir := mappingValue(local, "base_dir"), mappingValue(local, "archive_dir")
+	if baseDir == nil || archiveDir == nil || baseDir.Value == "" || archiveDir.Value == "" {
+		return nil
+	}
+	if DirsOverlap(baseDir.Value, archiveDir.Value) {
+		return []Problem{{Line: archiveDir.Line, Message: fmt.Sprintf("archive_dir %s conflicts with base_dir %s: neither may contain the other", archiveDir.Value, baseDir.Value)}}
+	}
+	return nil
+}
+
+// DirsOverlap reports whether a and b are the same directory or one
+// contains the other
+func DirsOverlap(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	within := func(dir, parent string) bool {
+		rel, err := filepath.Rel(parent, dir)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+	return within(a, b) || within(b, a)
+}
+
+// KeyLine returns the line of the key at path in config file contents, such
+// as "local", "base_dir", or 0 when it is not there
+func KeyLine(data []byte, path ...string) int {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return 0
+	}
+	node, line := root.Content[0], 0
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return 0
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line, value = node.Content[i].Line, node.Content[i+1]
+			}
+		}
+		if value == nil {
+			return 0
+		}
+		node = value
+	}
+	return line
+}
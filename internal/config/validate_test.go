@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Problem
+	}{
+		{
+			name: "valid",
+			data: "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\nlocal:\n  base_dir: /src\n",
+		},
+		{
+			name: "legacy format",
+			data: "gitlab:\n  url: https://gitlab.example.com\n  token: secret\nlocal:\n  basedir: /src\n",
+		},
+		{
+			name: "unknown keys and wrong types",
+			data: "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n    insecure: maybe\nlocal:\n  bse_dir: /src\nread_only: 1\n",
+			want: []Problem{
+				{Line: 5, Message: `invalid value "maybe": expected true or false`},
+				{Line: 7, Message: `unknown key "bse_dir" (did you mean "base_dir"?)`},
+				{Line: 8, Message: `invalid value "1": expected true or false`},
+			},
+		},
+		{
+			name: "duplicate providers",
+			data: "providers:\n  - name: work\n    type: gitlab\n    url: https://a.example.com\n  - name: work\n    type: svn\n    url: https://b.example.com\n",
+			want: []Problem{
+				{Line: 5, Message: `duplicate provider name "work", first used on line 2`},
+				{Line: 6, Message: "provider work has unsupported type svn (supported: gitlab, github)"},
+			},
+		},
+		{
+			name: "duplicate key",
+			data: "local:\n  base_dir: /src\n  base_dir: /other\n",
+			want: []Problem{{Line: 3, Message: `mapping key "base_dir" already defined at line 2`}},
+		},
+		{
+			name: "archive inside base directory",
+			data: "local:\n  base_dir: /src\n  archive_dir: /src/archived\n",
+			want: []Problem{{Line: 3, Message: "archive_dir /src/archived conflicts with base_dir /src: neither may contain the other"}},
+		},
+		{
+			name: "syntax error",
+			data: "providers: [\n",
+			want: []Problem{{Line: 1, Message: "did not find expected node content"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validate([]byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"/src", "/src/", true},
+		{"/src", "/src/work", true},
+		{"/src/work", "/src", true},
+		{"/src/work", "/src/personal", false},
+		{"/src", "/srcs", false},
+	}
+	for _, tt := range tests {
+		if got := DirsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("DirsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLoad_RejectsUnknownKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	content := "providers:\n  - name: work\n    type: gitlab\n    url: https://gitlab.example.com\n    token: secret\n    grup: backend\n"
+	if err := os.WriteFile(configFile(t, tempDir), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Load()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	if want := []Problem{{Line: 6, Message: `unknown key "grup" (did you mean "group"?)`}}; !reflect.DeepEqual(validationErr.Problems, want) {
+		t.Errorf("Problems = %v, want %v", validationErr.Problems, want)
+	}
+}
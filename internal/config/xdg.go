@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// xdgDir returns $<env>/gitstuff, or fallback/gitstuff under the home
+// directory when the variable is unset. Relative values are ignored, as the
+// XDG Base Directory specification requires.
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "gitstuff"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, "gitstuff"), nil
+}
+
+// ConfigDir returns the directory of the config files,
+// $XDG_CONFIG_HOME/gitstuff or ~/.config/gitstuff
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// profilePaths returns where the config file of the named profile, or the
+// default one for "", belongs and where older versions kept it in the home
+// directory
+func profilePaths(name string) (path, legacy string, err error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	if name == "" {
+		return filepath.Join(dir, "config.yaml"), filepath.Join(home, ".gitstuff.yaml"), nil
+	}
+	return filepath.Join(dir, "profiles", name+".yaml"), filepath.Join(home, ".gitstuff."+name+".yaml"), nil
+}
+
+// locate returns path, or legacy while only that exists
+func locate(path, legacy string) string {
+	if fileExists(path) || !fileExists(legacy) {
+		return path
+	}
+	return legacy
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// migrateLegacyFile moves the config file at configPath to the config
+// directory if it is the legacy file of the selected profile, and returns
+// where the file is now. Files with audit templates relative to them stay
+// where they are, as moving would break those.
+func migrateLegacyFile(configPath string, config *Config) (string, error) {
+	if filePath != "" || (Profile() == "" && os.Getenv(EnvFilePath) != "") {
+		return configPath, nil
+	}
+	for _, file := range config.Audit.Files {
+		if file.Template != "" && !filepath.IsAbs(file.Template) && !strings.HasPrefix(file.Template, "~") {
+			return configPath, nil
+		}
+	}
+	path, legacy, err := profilePaths(Profile())
+	if err != nil || configPath != legacy || fileExists(path) {
+		return configPath, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return "", fmt.Errorf("failed to move config file to %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Moved configuration from %s to %s\n", legacy, path)
+	return path, nil
+}
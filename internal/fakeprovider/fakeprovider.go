@@ -0,0 +1,206 @@
+// Package fakeprovider serves the subset of the GitLab and GitHub APIs that
+// gitstuff uses, backed by bare repositories on the local disk, so whole
+// commands can be run without a real provider.
+package fakeprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Token is the access token the servers accept
+const Token = "fake-token"
+
+// Server is a fake provider of one type. Its repositories are cloned from
+// local paths, so git needs no network access.
+type Server struct {
+	// Type is the provider type, "gitlab" or "github"
+	Type string
+
+	// URL is the base URL to configure the provider with
+	URL string
+
+	dir    string
+	server *httptest.Server
+
+	mu    sync.Mutex
+	repos []string
+}
+
+// New starts a fake provider of providerType keeping its repositories
+// under dir. Close it when done.
+func New(providerType, dir string) (*Server, error) {
+	if providerType != "gitlab" && providerType != "github" {
+		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found: %w", err)
+	}
+
+	s := &Server{Type: providerType, dir: dir}
+	mux := http.NewServeMux()
+	if providerType == "gitlab" {
+		mux.HandleFunc("GET /api/v4/projects", s.gitlabProjects)
+		mux.HandleFunc("GET /api/v4/user", s.user)
+		mux.HandleFunc("GET /api/v4/version", s.gitlabVersion)
+	} else {
+		mux.HandleFunc("GET /api/v3/user/repos", s.githubRepos)
+		mux.HandleFunc("GET /api/v3/user", s.user)
+		mux.HandleFunc("GET /api/v3/meta", s.githubMeta)
+	}
+	s.server = httptest.NewServer(s.authenticated(mux))
+	s.URL = s.server.URL
+	return s, nil
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddRepository creates the repository fullPath, such as "team/api", with
+// one commit on its main branch
+func (s *Server) AddRepository(fullPath string) error {
+	work := s.workTree(fullPath)
+	if err := git("init", "--initial-branch=main", work); err != nil {
+		return err
+	}
+	if err := s.Commit(fullPath, "README.md", "# "+path.Base(fullPath)+"\n"); err != nil {
+		return err
+	}
+	if err := git("clone", "--bare", work, s.bareRepo(fullPath)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos = append(s.repos, fullPath)
+	sort.Strings(s.repos)
+	return nil
+}
+
+// Commit writes content to file in repository fullPath and commits it. For
+// repositories already added, the commit is pushed so clones can pull it.
+func (s *Server) Commit(fullPath, file, content string) error {
+	work := s.workTree(fullPath)
+	if err := os.WriteFile(filepath.Join(work, file), []byte(content), 0644); err != nil {
+		return err
+	}
+	if err := git("-C", work, "add", file); err != nil {
+		return err
+	}
+	if err := git("-C", work, "-c", "user.name=gitstuff", "-c", "user.email=gitstuff@example.com", "commit", "-m", "Update "+file); err != nil {
+		return err
+	}
+	if _, err := os.Stat(s.bareRepo(fullPath)); err != nil {
+		return nil // Not added yet
+	}
+	return git("-C", work, "push", s.bareRepo(fullPath), "main")
+}
+
+// CloneURL returns the URL repository fullPath is cloned from
+func (s *Server) CloneURL(fullPath string) string {
+	return s.bareRepo(fullPath)
+}
+
+func (s *Server) workTree(fullPath string) string {
+	return filepath.Join(s.dir, "work", filepath.FromSlash(fullPath))
+}
+
+func (s *Server) bareRepo(fullPath string) string {
+	return filepath.Join(s.dir, "remotes", filepath.FromSlash(fullPath)+".git")
+}
+
+func (s *Server) repositories() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.repos...)
+}
+
+// authenticated rejects requests that do not carry Token the way the
+// provider's client sends it
+func (s *Server) authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("PRIVATE-TOKEN")
+		if s.Type == "github" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if token != Token {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "401 Unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) user(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"id": 1, "username": "gitstuff", "login": "gitstuff"})
+}
+
+func (s *Server) gitlabVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"version": "17.0.0", "revision": "fake"})
+}
+
+func (s *Server) gitlabProjects(w http.ResponseWriter, r *http.Request) {
+	projects := []map[string]any{}
+	for i, fullPath := range s.repositories() {
+		projects = append(projects, map[string]any{
+			"id":                  i + 1,
+			"name":                path.Base(fullPath),
+			"path":                path.Base(fullPath),
+			"path_with_namespace": fullPath,
+			"http_url_to_repo":    s.CloneURL(fullPath),
+			"ssh_url_to_repo":     s.CloneURL(fullPath),
+			"web_url":             s.URL + "/" + fullPath,
+			"default_branch":      "main",
+			"visibility":          "private",
+		})
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (s *Server) githubMeta(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-GitHub-Enterprise-Version", "3.14.0")
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+func (s *Server) githubRepos(w http.ResponseWriter, r *http.Request) {
+	repos := []map[string]any{}
+	for i, fullPath := range s.repositories() {
+		repos = append(repos, map[string]any{
+			"id":             i + 1,
+			"name":           path.Base(fullPath),
+			"full_name":      fullPath,
+			"clone_url":      s.CloneURL(fullPath),
+			"ssh_url":        s.CloneURL(fullPath),
+			"html_url":       s.URL + "/" + fullPath,
+			"default_branch": "main",
+			"private":        true,
+			"visibility":     "private",
+			"permissions":    map[string]bool{"pull": true},
+		})
+	}
+	writeJSON(w, http.StatusOK, repos)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func git(args ...string) error {
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %w\n%s", strings.Join(args, " "), err, out)
+	}
+	return nil
+}
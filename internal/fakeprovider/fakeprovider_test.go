@@ -0,0 +1,68 @@
+package fakeprovider
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"gitstuff/internal/github"
+	"gitstuff/internal/gitlab"
+	"gitstuff/internal/scm"
+)
+
+func TestServer_ListsRepositories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	for _, providerType := range []string{"gitlab", "github"} {
+		t.Run(providerType, func(t *testing.T) {
+			server, err := New(providerType, t.TempDir())
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			defer server.Close()
+			if err := server.AddRepository("team/api"); err != nil {
+				t.Fatalf("AddRepository() failed: %v", err)
+			}
+
+			var client scm.Client
+			if providerType == "gitlab" {
+				client, err = gitlab.NewClient(server.URL, Token, false)
+			} else {
+				client, err = github.NewClient(server.URL, Token, false)
+			}
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			repos, err := client.ListAllRepositories(context.Background())
+			if err != nil {
+				t.Fatalf("ListAllRepositories() failed: %v", err)
+			}
+			if len(repos) != 1 || repos[0].FullPath != "team/api" || repos[0].CloneURL != server.CloneURL("team/api") {
+				t.Errorf("Unexpected repositories %+v", repos)
+			}
+		})
+	}
+}
+
+func TestServer_RejectsWrongToken(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	server, err := New("gitlab", t.TempDir())
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer server.Close()
+
+	client, err := gitlab.NewClient(server.URL, "wrong", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.ListAllRepositories(context.Background()); err == nil {
+		t.Error("Expected a wrong token to be rejected")
+	}
+}
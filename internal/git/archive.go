@@ -0,0 +1,38 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// ArchiveFormats are the formats Archive can write, as named by git archive
+var ArchiveFormats = []string{"tar.gz", "tgz", "tar", "zip"}
+
+// Archive writes the files of commit-ish ref in the repository at repoPath,
+// without any history, to outputPath in format, with every path under
+// prefix. It returns the commit that was archived.
+func Archive(repoPath, ref, format, prefix, outputPath string) (string, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("ref %s not found", ref)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", repoPath, "archive", "--format="+format, "--prefix="+strings.TrimSuffix(prefix, "/")+"/", "-o", outputPath, commit)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git archive failed: %s", message)
+		}
+		return "", fmt.Errorf("git archive failed: %w", err)
+	}
+	return commit, nil
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArchive(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	repo := t.TempDir()
+	runGit(t, "init", "-q", "-b", "main", repo)
+	if err := os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, "-C", repo, "add", "main.go")
+	runGit(t, "-C", repo, "commit", "-q", "-m", "Initial commit")
+	runGit(t, "-C", repo, "tag", "v1.0")
+	if err := os.WriteFile(filepath.Join(repo, "later.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, "-C", repo, "add", "later.go")
+	runGit(t, "-C", repo, "commit", "-q", "-m", "Later work")
+
+	output := filepath.Join(t.TempDir(), "api.tar.gz")
+	commit, err := Archive(repo, "v1.0", "tar.gz", "api/", output)
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	want, _ := exec.Command("git", "-C", repo, "rev-parse", "v1.0^{commit}").Output()
+	if commit != strings.TrimSpace(string(want)) {
+		t.Errorf("Archive() commit = %s, want %s", commit, want)
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Expected a gzip archive: %v", err)
+	}
+	var names []string
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag != tar.TypeXGlobalHeader {
+			names = append(names, header.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"api/", "api/main.go"}) {
+		t.Errorf("Unexpected archive contents: %v", names)
+	}
+
+	if _, err := Archive(repo, "v9.9", "tar.gz", "api", output); err == nil || !strings.Contains(err.Error(), "v9.9 not found") {
+		t.Errorf("Expected a missing ref error, got %v", err)
+	}
+}
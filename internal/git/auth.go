@@ -0,0 +1,140 @@
+package git
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// HTTPAuth is a token sent with HTTPS requests to the remote, for example a
+// provider token when cloning over HTTPS without a credential helper
+type HTTPAuth struct {
+	Username string
+	Token    string
+
+	// URL limits the token to requests under it, for example
+	// https://gitlab.example.com/, so remotes and redirects elsewhere never
+	// see it. Empty sends it with every HTTPS request.
+	URL string
+}
+
+// apply passes auth to cmd as an Authorization header
+func (auth *HTTPAuth) apply(cmd *exec.Cmd) {
+	if auth == nil || auth.Token == "" {
+		return
+	}
+	key := "http.extraHeader"
+	if auth.URL != "" {
+		key = "http." + auth.URL + ".extraHeader"
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Token))
+	withConfigEnv(cmd, key, "Authorization: Basic "+credentials)
+}
+
+// CloneRepositoryWithAuth clones like CloneRepositoryWithOutput, sending auth
+// with HTTPS requests. The token is passed to git through its environment,
+// so it neither shows up in the process list nor is stored in the clone.
+func CloneRepositoryWithAuth(cloneURL, targetPath string, auth *HTTPAuth, stdout, stderr io.Writer) error {
+	return CloneRepositoryWithOptions(cloneURL, targetPath, CloneOptions{Auth: auth}, stdout, stderr)
+}
+
+// withConfigEnv sets a git configuration value for cmd only, after any
+// already passed through GIT_CONFIG_COUNT
+func withConfigEnv(cmd *exec.Cmd, key, value string) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	cmd.Env = append(cmd.Env,
+		"GIT_CONFIG_COUNT="+strconv.Itoa(count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count, key),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, value),
+	)
+}
+
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")
+}
+
+// accessErrors are fragments of git and ssh output that mean the remote
+// could not be reached or refused the credentials, as opposed to a problem
+// with the repository itself
+var accessErrors = []string{
+	"permission denied (publickey",
+	"host key verification failed",
+	"could not resolve hostname",
+	"could not resolve host",
+	"connection refused",
+	"connection timed out",
+	"operation timed out",
+	"connection reset",
+	"connection closed by",
+	"no route to host",
+	"network is unreachable",
+	"authentication failed",
+	"http basic: access denied",
+	"could not read username",
+	"could not read password",
+	"terminal prompts disabled",
+	"the requested url returned error: 401",
+	"the requested url returned error: 403",
+}
+
+// IsAccessError reports whether output from a failed clone or fetch shows an
+// authentication or connection problem that another protocol might avoid
+func IsAccessError(output string) bool {
+	output = strings.ToLower(output)
+	for _, fragment := range accessErrors {
+		if strings.Contains(output, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// transientErrors are fragments of git and ssh output that mean the
+// connection dropped or the remote was briefly unavailable, so the same
+// operation may well work when tried again
+var transientErrors = []string{
+	"could not resolve host",
+	"temporary failure in name resolution",
+	"connection timed out",
+	"operation timed out",
+	"connection reset",
+	"connection closed by",
+	"connection refused",
+	"failed to connect to",
+	"couldn't connect to server",
+	"no route to host",
+	"network is unreachable",
+	"kex_exchange_identification",
+	"ssh_exchange_identification",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"unexpected disconnect",
+	"tls connection was non-properly terminated",
+	"gnutls_handshake() failed",
+	"the requested url returned error: 429",
+	"the requested url returned error: 500",
+	"the requested url returned error: 502",
+	"the requested url returned error: 503",
+	"the requested url returned error: 504",
+}
+
+// IsTransientError reports whether output from a failed clone, fetch or pull
+// shows a network problem that retrying might get past, as opposed to one
+// with credentials or the repository itself
+func IsTransientError(output string) bool {
+	output = strings.ToLower(output)
+	for _, fragment := range transientErrors {
+		if strings.Contains(output, fragment) {
+			return true
+		}
+	}
+	return false
+}
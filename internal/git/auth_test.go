@@ -0,0 +1,79 @@
+package git
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneRepositoryWithAuth_SendsToken(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+
+	headers := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	auth := &HTTPAuth{Username: "oauth2", Token: "secret-token"}
+	err := CloneRepositoryWithAuth(server.URL+"/team/api.git", filepath.Join(t.TempDir(), "api"), auth, &out, &out)
+	if err == nil {
+		t.Fatal("Expected the clone to fail")
+	}
+
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:secret-token"))
+	if got := <-headers; got != want {
+		t.Errorf("Expected Authorization %q, got %q", want, got)
+	}
+	if !IsAccessError(out.String()) {
+		t.Errorf("Expected a 403 to be an access error, got:\n%s", out.String())
+	}
+}
+
+func TestIsAccessError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"git@gitlab.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", true},
+		{"ssh: Could not resolve hostname gitlab.internal: Name or service not known", true},
+		{"fatal: could not read Username for 'https://github.com': terminal prompts disabled", true},
+		{"remote: HTTP Basic: Access denied\nfatal: Authentication failed for 'https://gitlab.com/a/b.git/'", true},
+		{"fatal: destination path 'api' already exists and is not an empty directory.", false},
+		{"remote: Repository not found.", false},
+	}
+	for _, tt := range tests {
+		if got := IsAccessError(tt.output); got != tt.want {
+			t.Errorf("IsAccessError(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"ssh: connect to host gitlab.com port 22: Connection timed out\nfatal: Could not read from remote repository.", true},
+		{"error: RPC failed; curl 56 GnuTLS recv error (-9)\nfatal: early EOF", true},
+		{"fatal: unable to access 'https://github.com/a/b.git/': The requested URL returned error: 503", true},
+		{"kex_exchange_identification: read: Connection reset by peer", true},
+		{"git@gitlab.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", false},
+		{"fatal: Authentication failed for 'https://gitlab.com/a/b.git/'", false},
+		{"remote: Repository not found.", false},
+	}
+	for _, tt := range tests {
+		if got := IsTransientError(tt.output); got != tt.want {
+			t.Errorf("IsTransientError(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
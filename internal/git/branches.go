@@ -0,0 +1,104 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// Branch is a local branch of a repository
+type Branch struct {
+	Name    string
+	Current bool
+
+	// Upstream is the branch's upstream, e.g. "origin/main", or empty when
+	// it has none. UpstreamGone means it was deleted on the remote.
+	Upstream     string
+	UpstreamGone bool
+	Ahead        int
+	Behind       int
+
+	LastCommit time.Time
+
+	// Merged reports whether the branch is contained in the default branch
+	Merged bool
+}
+
+// DefaultBranchRef returns the ref of the repository's default branch:
+// the remote branch origin/HEAD points at, or else a local main or master.
+// It is empty when none of these exist.
+func DefaultBranchRef(repoPath string) string {
+	out, err := exec.Command("git", "-C", repoPath, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD").Output()
+	if err == nil {
+		if ref := strings.TrimSpace(string(out)); ref != "" {
+			return ref
+		}
+	}
+	return ResolveRef(repoPath, "main", "master")
+}
+
+// Branches returns the local branches of the repository at repoPath.
+// Merged is set against defaultRef; with an empty defaultRef no branch
+// counts as merged.
+func Branches(repoPath, defaultRef string) ([]Branch, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	out, err := exec.Command("git", "-C", repoPath, "for-each-ref",
+		"--format=%(refname:short)%00%(HEAD)%00%(upstream:short)%00%(upstream:track)%00%(committerdate:unix)",
+		"refs/heads").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	merged := make(map[string]bool)
+	if defaultRef != "" {
+		mergedOut, err := exec.Command("git", "-C", repoPath, "for-each-ref", "--format=%(refname:short)", "--merged", defaultRef, "refs/heads").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list merged branches: %w", err)
+		}
+		for _, name := range strings.Fields(string(mergedOut)) {
+			merged[name] = true
+		}
+	}
+
+	var branches []Branch
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+		branch := Branch{
+			Name:     fields[0],
+			Current:  fields[1] == "*",
+			Upstream: fields[2],
+			Merged:   merged[fields[0]],
+		}
+		branch.UpstreamGone, branch.Ahead, branch.Behind = parseTrack(fields[3])
+		if seconds, err := strconv.ParseInt(fields[4], 10, 64); err == nil {
+			branch.LastCommit = time.Unix(seconds, 0)
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// parseTrack reads %(upstream:track), e.g. "[ahead 2, behind 1]" or "[gone]"
+func parseTrack(track string) (gone bool, ahead, behind int) {
+	track = strings.Trim(track, "[]")
+	if track == "gone" {
+		return true, 0, 0
+	}
+	for _, part := range strings.Split(track, ",") {
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimSpace(part), "ahead %d", &n); err == nil {
+			ahead = n
+		} else if _, err := fmt.Sscanf(strings.TrimSpace(part), "behind %d", &n); err == nil {
+			behind = n
+		}
+	}
+	return false, ahead, behind
+}
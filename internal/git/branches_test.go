@@ -0,0 +1,91 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	tempDir := t.TempDir()
+	bareRepo := filepath.Join(tempDir, "bare.git")
+	workingRepo := filepath.Join(tempDir, "working")
+
+	runGit(t, "init", "--bare", "-b", "main", bareRepo)
+	runGit(t, "clone", bareRepo, workingRepo)
+	runGit(t, "-C", workingRepo, "checkout", "-b", "main")
+	runGit(t, "-C", workingRepo, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, "-C", workingRepo, "push", "-u", "origin", "main")
+	runGit(t, "-C", workingRepo, "remote", "set-head", "origin", "main")
+
+	runGit(t, "-C", workingRepo, "branch", "merged-fix")
+	runGit(t, "-C", workingRepo, "checkout", "-b", "feature")
+	runGit(t, "-C", workingRepo, "commit", "--allow-empty", "-m", "Feature work")
+	runGit(t, "-C", workingRepo, "push", "-u", "origin", "feature")
+	runGit(t, "-C", workingRepo, "commit", "--allow-empty", "-m", "More feature work")
+	runGit(t, "-C", workingRepo, "checkout", "-b", "deleted-upstream")
+	runGit(t, "-C", workingRepo, "push", "-u", "origin", "deleted-upstream")
+	runGit(t, "-C", workingRepo, "push", "origin", "--delete", "deleted-upstream")
+	runGit(t, "-C", workingRepo, "fetch", "--prune")
+	runGit(t, "-C", workingRepo, "checkout", "feature")
+
+	defaultRef := DefaultBranchRef(workingRepo)
+	if defaultRef != "origin/main" {
+		t.Fatalf("DefaultBranchRef() = %q, want origin/main", defaultRef)
+	}
+
+	branches, err := Branches(workingRepo, defaultRef)
+	if err != nil {
+		t.Fatalf("Branches failed: %v", err)
+	}
+	byName := make(map[string]Branch)
+	for _, branch := range branches {
+		byName[branch.Name] = branch
+	}
+	if len(byName) != 4 {
+		t.Fatalf("Expected 4 branches, got %+v", branches)
+	}
+
+	if main := byName["main"]; !main.Merged || main.Current || main.Upstream != "origin/main" {
+		t.Errorf("Unexpected main branch: %+v", main)
+	}
+	if fix := byName["merged-fix"]; !fix.Merged || fix.Upstream != "" {
+		t.Errorf("Unexpected merged-fix branch: %+v", fix)
+	}
+	if feature := byName["feature"]; feature.Merged || !feature.Current || feature.Ahead != 1 || feature.Behind != 0 {
+		t.Errorf("Unexpected feature branch: %+v", feature)
+	}
+	if gone := byName["deleted-upstream"]; !gone.UpstreamGone || gone.Merged {
+		t.Errorf("Unexpected deleted-upstream branch: %+v", gone)
+	}
+	if age := time.Since(byName["feature"].LastCommit); age < 0 || age > time.Hour {
+		t.Errorf("Expected a recent last commit, got %v", byName["feature"].LastCommit)
+	}
+}
+
+func TestParseTrack(t *testing.T) {
+	tests := []struct {
+		track  string
+		gone   bool
+		ahead  int
+		behind int
+	}{
+		{track: ""},
+		{track: "[gone]", gone: true},
+		{track: "[ahead 2]", ahead: 2},
+		{track: "[behind 3]", behind: 3},
+		{track: "[ahead 2, behind 3]", ahead: 2, behind: 3},
+	}
+
+	for _, tt := range tests {
+		gone, ahead, behind := parseTrack(tt.track)
+		if gone != tt.gone || ahead != tt.ahead || behind != tt.behind {
+			t.Errorf("parseTrack(%q) = %v, %d, %d, want %v, %d, %d", tt.track, gone, ahead, behind, tt.gone, tt.ahead, tt.behind)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// StatusBackend selects how GetRepositoryStatus inspects a working tree
+type StatusBackend string
+
+const (
+	// BackendGoGit reads repositories in-process without the git binary
+	BackendGoGit StatusBackend = "go-git"
+	// BackendExec runs the git binary for every status check
+	BackendExec StatusBackend = "exec"
+)
+
+var statusBackend = BackendGoGit
+
+// errGoGitUnsupported marks repositories go-git cannot open, such as ones
+// using extensions it does not implement; the exec backend handles those
+var errGoGitUnsupported = errors.New("repository not supported by go-git")
+
+// SetStatusBackend selects the status backend by name
+func SetStatusBackend(name string) error {
+	switch backend := StatusBackend(name); backend {
+	case BackendGoGit, BackendExec:
+		statusBackend = backend
+		return nil
+	default:
+		return fmt.Errorf("unknown git status backend %q (expected %q or %q)", name, BackendGoGit, BackendExec)
+	}
+}
+
+// GetStatusBackend returns the selected status backend
+func GetStatusBackend() StatusBackend {
+	return statusBackend
+}
+
+func goGitWorkingTreeStatus(repoPath string, status *Status) error {
+	repo, err := gogit.PlainOpenWithOptions(repoPath, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGoGitUnsupported, err)
+	}
+
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		status.CurrentBranch = head.Target().Short()
+	} else {
+		// Matches "git rev-parse --abbrev-ref HEAD" on a detached HEAD
+		status.CurrentBranch = "HEAD"
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGoGitUnsupported, err)
+	}
+	// git also honours core.excludesFile and the system excludes, which
+	// go-git does not load on its own
+	rootFS := osfs.New("/")
+	if patterns, err := gitignore.LoadSystemPatterns(rootFS); err == nil {
+		worktree.Excludes = append(worktree.Excludes, patterns...)
+	}
+	if patterns, err := gitignore.LoadGlobalPatterns(rootFS); err == nil {
+		worktree.Excludes = append(worktree.Excludes, patterns...)
+	}
+
+	worktreeStatus, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to check git status: %w", err)
+	}
+	status.HasChanges = !worktreeStatus.IsClean()
+
+	return nil
+}
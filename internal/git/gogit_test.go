@@ -0,0 +1,172 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetStatusBackend(t *testing.T) {
+	t.Cleanup(func() { statusBackend = BackendGoGit })
+
+	if GetStatusBackend() != BackendGoGit {
+		t.Errorf("Expected default backend %q, got %q", BackendGoGit, GetStatusBackend())
+	}
+	if err := SetStatusBackend("exec"); err != nil {
+		t.Fatalf("SetStatusBackend failed: %v", err)
+	}
+	if GetStatusBackend() != BackendExec {
+		t.Errorf("Expected exec backend, got %q", GetStatusBackend())
+	}
+	if err := SetStatusBackend("libgit2"); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+	if GetStatusBackend() != BackendExec {
+		t.Error("Expected unknown backend to leave the selection unchanged")
+	}
+}
+
+// The go-git backend must report the same branch and change state as git
+func TestStatusBackends_Agree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	t.Cleanup(func() { statusBackend = BackendGoGit })
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	globalIgnore := filepath.Join(home, "global-ignore")
+	if err := os.WriteFile(globalIgnore, []byte("*.swp\n"), 0644); err != nil {
+		t.Fatalf("Failed to write global ignore: %v", err)
+	}
+	runGit(t, "config", "--global", "core.excludesFile", globalIgnore)
+
+	write := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, repo string)
+		wantBranch string
+		wantDirty  bool
+	}{
+		{
+			name:       "clean",
+			setup:      func(t *testing.T, repo string) {},
+			wantBranch: "main",
+		},
+		{
+			name: "untracked file",
+			setup: func(t *testing.T, repo string) {
+				write(t, filepath.Join(repo, "new.txt"), "new")
+			},
+			wantBranch: "main",
+			wantDirty:  true,
+		},
+		{
+			name: "modified tracked file",
+			setup: func(t *testing.T, repo string) {
+				write(t, filepath.Join(repo, "README.md"), "changed")
+			},
+			wantBranch: "main",
+			wantDirty:  true,
+		},
+		{
+			name: "staged file",
+			setup: func(t *testing.T, repo string) {
+				write(t, filepath.Join(repo, "staged.txt"), "staged")
+				runGit(t, "-C", repo, "add", "staged.txt")
+			},
+			wantBranch: "main",
+			wantDirty:  true,
+		},
+		{
+			name: "ignored files",
+			setup: func(t *testing.T, repo string) {
+				write(t, filepath.Join(repo, ".gitignore"), "build/\n")
+				runGit(t, "-C", repo, "add", ".gitignore")
+				runGit(t, "-C", repo, "commit", "-m", "ignore build")
+				if err := os.MkdirAll(filepath.Join(repo, "build"), 0755); err != nil {
+					t.Fatalf("Failed to create build dir: %v", err)
+				}
+				write(t, filepath.Join(repo, "build", "out.bin"), "binary")
+				write(t, filepath.Join(repo, ".README.md.swp"), "swap")
+			},
+			wantBranch: "main",
+		},
+		{
+			name: "feature branch",
+			setup: func(t *testing.T, repo string) {
+				runGit(t, "-C", repo, "checkout", "-q", "-b", "feature/login")
+			},
+			wantBranch: "feature/login",
+		},
+		{
+			name: "detached head",
+			setup: func(t *testing.T, repo string) {
+				runGit(t, "-C", repo, "checkout", "-q", "--detach")
+			},
+			wantBranch: "HEAD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			runGit(t, "init", "-q", "-b", "main", repo)
+			write(t, filepath.Join(repo, "README.md"), "readme")
+			runGit(t, "-C", repo, "add", "README.md")
+			runGit(t, "-C", repo, "commit", "-q", "-m", "initial")
+			tt.setup(t, repo)
+
+			for _, backend := range []StatusBackend{BackendExec, BackendGoGit} {
+				statusBackend = backend
+				status, err := GetRepositoryStatus(repo)
+				if err != nil {
+					t.Fatalf("%s: GetRepositoryStatus failed: %v", backend, err)
+				}
+				if status.CurrentBranch != tt.wantBranch {
+					t.Errorf("%s: expected branch %q, got %q", backend, tt.wantBranch, status.CurrentBranch)
+				}
+				if status.HasChanges != tt.wantDirty {
+					t.Errorf("%s: expected HasChanges=%v, got %v", backend, tt.wantDirty, status.HasChanges)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetRepositoryStatus(b *testing.B) {
+	if _, err := exec.LookPath("git"); err != nil {
+		b.Skip("git not available in PATH")
+	}
+
+	repo := b.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", repo},
+		{"-C", repo, "-c", "user.name=Bench", "-c", "user.email=bench@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			b.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	b.Cleanup(func() { statusBackend = BackendGoGit })
+
+	for _, backend := range []StatusBackend{BackendExec, BackendGoGit} {
+		b.Run(string(backend), func(b *testing.B) {
+			statusBackend = backend
+			for i := 0; i < b.N; i++ {
+				if _, err := GetRepositoryStatus(repo); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// GrepOptions change how Grep matches. Patterns are extended regular
+// expressions unless FixedStrings is set.
+type GrepOptions struct {
+	IgnoreCase   bool
+	FixedStrings bool
+	WordRegexp   bool
+
+	// FilesOnly reports each matching file once, without line or text
+	FilesOnly bool
+}
+
+// GrepMatch is a line of a tracked file that matches, or with FilesOnly a
+// matching file
+type GrepMatch struct {
+	Path string
+	Line int
+	Text string
+}
+
+// Grep searches the tracked files in the working tree of the repository at
+// repoPath for pattern. Binary files are skipped. No matches is not an error.
+func Grep(repoPath, pattern string, opts GrepOptions) ([]GrepMatch, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	args := []string{"-C", repoPath, "grep", "--null", "--no-color", "-I"}
+	if opts.FixedStrings {
+		args = append(args, "-F")
+	} else {
+		args = append(args, "-E")
+	}
+	if opts.IgnoreCase {
+		args = append(args, "-i")
+	}
+	if opts.WordRegexp {
+		args = append(args, "-w")
+	}
+	if opts.FilesOnly {
+		args = append(args, "-l")
+	} else {
+		args = append(args, "-n")
+	}
+	args = append(args, "-e", pattern)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return nil, nil
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git grep failed: %s", message)
+		}
+		return nil, fmt.Errorf("git grep failed: %w", err)
+	}
+
+	if opts.FilesOnly {
+		var matches []GrepMatch
+		for _, path := range strings.Split(string(out), "\x00") {
+			if path != "" {
+				matches = append(matches, GrepMatch{Path: path})
+			}
+		}
+		return matches, nil
+	}
+	return parseGrepLines(string(out)), nil
+}
+
+// parseGrepLines reads "path\0line\0text" lines as printed by git grep
+// --null -n
+func parseGrepLines(out string) []GrepMatch {
+	var matches []GrepMatch
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		number, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		matches = append(matches, GrepMatch{Path: fields[0], Line: number, Text: fields[2]})
+	}
+	return matches
+}
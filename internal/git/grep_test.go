@@ -0,0 +1,83 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGrep(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	repo := t.TempDir()
+	runGit(t, "init", "-q", repo)
+	files := map[string]string{
+		"main.go":         "package main\n\nfunc main() {\n\tconnect(\"db.internal\")\n}\n",
+		"docs/setup.md":   "Connect to DB.INTERNAL first\n",
+		"untracked.txt":   "db.internal\n",
+		"image.bin":       "db.internal\x00\x01",
+		"notes/reconnect": "reconnect later\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, "-C", repo, "add", "main.go", "docs/setup.md", "image.bin", "notes/reconnect")
+	runGit(t, "-C", repo, "commit", "-q", "-m", "Initial commit")
+
+	tests := []struct {
+		name    string
+		pattern string
+		opts    GrepOptions
+		want    []GrepMatch
+	}{
+		{
+			name:    "regular expression",
+			pattern: "db\\.[a-z]+",
+			want:    []GrepMatch{{Path: "main.go", Line: 4, Text: "\tconnect(\"db.internal\")"}},
+		},
+		{
+			name:    "ignore case",
+			pattern: "db.internal",
+			opts:    GrepOptions{IgnoreCase: true, FixedStrings: true},
+			want: []GrepMatch{
+				{Path: "docs/setup.md", Line: 1, Text: "Connect to DB.INTERNAL first"},
+				{Path: "main.go", Line: 4, Text: "\tconnect(\"db.internal\")"},
+			},
+		},
+		{
+			name:    "whole words",
+			pattern: "connect",
+			opts:    GrepOptions{IgnoreCase: true, WordRegexp: true, FilesOnly: true},
+			want:    []GrepMatch{{Path: "docs/setup.md"}, {Path: "main.go"}},
+		},
+		{
+			name:    "no matches",
+			pattern: "nowhere",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Grep(repo, tt.pattern, tt.opts)
+			if err != nil {
+				t.Fatalf("Grep() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Grep() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Grep(repo, "(unclosed", GrepOptions{}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Markers around the lines gitstuff manages in a repository's info files,
+// so entries added by hand are kept
+const (
+	managedBlockBegin = "# BEGIN gitstuff managed - changes here are overwritten"
+	managedBlockEnd   = "# END gitstuff managed"
+)
+
+// Info files that SetManagedBlock can write, relative to the git directory
+const (
+	InfoExclude    = "info/exclude"
+	InfoAttributes = "info/attributes"
+)
+
+// SetManagedBlock replaces the lines gitstuff manages in the info file of
+// the repository at repoPath, such as InfoExclude. No lines removes the
+// block. It reports whether the file changed.
+func SetManagedBlock(repoPath, infoFile string, lines []string) (bool, error) {
+	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--git-path", infoFile).Output()
+	if err != nil {
+		return false, fmt.Errorf("%s is not a git repository", repoPath)
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoPath, path)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("failed to read %s: %w", infoFile, err)
+	}
+	updated := replaceManagedBlock(string(current), lines)
+	if updated == string(current) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(infoFile), err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", infoFile, err)
+	}
+	return true, nil
+}
+
+// replaceManagedBlock returns content with its managed block replaced by
+// lines, appending a block if it has none
+func replaceManagedBlock(content string, lines []string) string {
+	var kept []string
+	inBlock := false
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		switch {
+		case line == managedBlockBegin:
+			inBlock = true
+		case line == managedBlockEnd && inBlock:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+
+	if len(lines) > 0 {
+		if len(kept) > 0 {
+			kept = append(kept, "")
+		}
+		kept = append(kept, managedBlockBegin)
+		kept = append(kept, lines...)
+		kept = append(kept, managedBlockEnd)
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
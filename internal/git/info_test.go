@@ -0,0 +1,64 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReplaceManagedBlock(t *testing.T) {
+	block := managedBlockBegin + "\n.DS_Store\n" + managedBlockEnd + "\n"
+	tests := []struct {
+		name    string
+		content string
+		lines   []string
+		want    string
+	}{
+		{"empty file", "", []string{".DS_Store"}, block},
+		{"keeps own entries", "# mine\n*.log\n", []string{".DS_Store"}, "# mine\n*.log\n\n" + block},
+		{"replaces block", "*.log\n\n" + managedBlockBegin + "\nThumbs.db\n" + managedBlockEnd + "\n/tmp\n", []string{".DS_Store"}, "*.log\n\n/tmp\n\n" + block},
+		{"unchanged", "*.log\n\n" + block, []string{".DS_Store"}, "*.log\n\n" + block},
+		{"removes block", "*.log\n\n" + block, nil, "*.log\n"},
+		{"nothing to remove", "", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceManagedBlock(tt.content, tt.lines); got != tt.want {
+				t.Errorf("replaceManagedBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetManagedBlock(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	repoPath := t.TempDir()
+	runGit(t, "init", "--quiet", repoPath)
+	if err := os.WriteFile(filepath.Join(repoPath, ".DS_Store"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := SetManagedBlock(repoPath, InfoExclude, []string{".DS_Store"})
+	if err != nil || !changed {
+		t.Fatalf("SetManagedBlock() = %t, %v", changed, err)
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "status", "--porcelain").Output(); err != nil || strings.TrimSpace(string(out)) != "" {
+		t.Errorf("Expected a clean status, got %q (%v)", out, err)
+	}
+	if changed, err := SetManagedBlock(repoPath, InfoExclude, []string{".DS_Store"}); err != nil || changed {
+		t.Errorf("Expected no change the second time, got %t, %v", changed, err)
+	}
+
+	if _, err := SetManagedBlock(repoPath, InfoAttributes, []string{"*.sh text eol=lf"}); err != nil {
+		t.Fatalf("SetManagedBlock(attributes) failed: %v", err)
+	}
+	out, err := exec.Command("git", "-C", repoPath, "check-attr", "eol", "--", "run.sh").Output()
+	if err != nil || !strings.Contains(string(out), "eol: lf") {
+		t.Errorf("Expected run.sh to get eol=lf, got %q (%v)", out, err)
+	}
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// Background maintenance keeps the commit-graph, prefetched remote refs and
+// packs of registered repositories up to date, which speeds up status and
+// log on large repositories. Registration lives in the global git config,
+// so these functions must not run concurrently.
+
+// RegisterMaintenance adds the repository at repoPath to the repositories
+// background maintenance runs on, with git's incremental strategy
+func RegisterMaintenance(repoPath string) error {
+	return runMaintenance(repoPath, "register")
+}
+
+// UnregisterMaintenance removes the repository at repoPath from background
+// maintenance. Repositories that are not registered are left alone.
+func UnregisterMaintenance(repoPath string) error {
+	registered, err := MaintenanceRegistered(repoPath)
+	if err != nil || !registered {
+		return err
+	}
+	return runMaintenance(repoPath, "unregister")
+}
+
+// StartMaintenance registers the repository at repoPath and schedules the
+// background maintenance of all registered repositories with the system
+// scheduler, such as cron, systemd timers or launchd
+func StartMaintenance(repoPath string) error {
+	return runMaintenance(repoPath, "start")
+}
+
+// StopMaintenance removes the background maintenance schedule. Run from
+// repoPath, but it affects every registered repository.
+func StopMaintenance(repoPath string) error {
+	return runMaintenance(repoPath, "stop")
+}
+
+// MaintenanceRepositories returns the repositories registered for
+// background maintenance
+func MaintenanceRepositories() ([]string, error) {
+	out, err := exec.Command("git", "config", "--global", "--get-all", "maintenance.repo").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil // Not set
+		}
+		return nil, fmt.Errorf("failed to read maintenance.repo: %w", err)
+	}
+	var repos []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			repos = append(repos, line)
+		}
+	}
+	return repos, nil
+}
+
+// MaintenanceRegistered reports whether the repository at repoPath is
+// registered for background maintenance
+func MaintenanceRegistered(repoPath string) (bool, error) {
+	repos, err := MaintenanceRepositories()
+	if err != nil {
+		return false, err
+	}
+	want := comparablePath(repoPath)
+	for _, repo := range repos {
+		if comparablePath(repo) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// comparablePath resolves symbolic links so the paths git records compare
+// equal to the ones gitstuff computes
+func comparablePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Clean(path)
+}
+
+func runMaintenance(repoPath, subcommand string) error {
+	defer timing.Track(timing.Git, time.Now())
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", repoPath, "maintenance", subcommand)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("git maintenance %s failed: %s", subcommand, message)
+		}
+		return fmt.Errorf("git maintenance %s failed: %w", subcommand, err)
+	}
+	return nil
+}
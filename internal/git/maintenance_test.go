@@ -0,0 +1,51 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaintenanceRegistration(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+
+	tempDir := t.TempDir()
+	first := filepath.Join(tempDir, "with space", "first")
+	second := filepath.Join(tempDir, "second")
+	for _, repo := range []string{first, second} {
+		if err := os.MkdirAll(repo, 0755); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, "init", "-q", repo)
+	}
+
+	if repos, err := MaintenanceRepositories(); err != nil || len(repos) != 0 {
+		t.Fatalf("MaintenanceRepositories() = %v, %v; want none", repos, err)
+	}
+	for _, repo := range []string{first, second} {
+		if err := RegisterMaintenance(repo); err != nil {
+			t.Fatalf("RegisterMaintenance(%s) error = %v", repo, err)
+		}
+	}
+	if registered, err := MaintenanceRegistered(first); err != nil || !registered {
+		t.Errorf("MaintenanceRegistered() = %t, %v; want true", registered, err)
+	}
+	if out, _ := exec.Command("git", "-C", first, "config", "maintenance.strategy").Output(); string(out) != "incremental\n" {
+		t.Errorf("Expected the incremental strategy, got %q", out)
+	}
+
+	if err := UnregisterMaintenance(first); err != nil {
+		t.Fatalf("UnregisterMaintenance() error = %v", err)
+	}
+	if err := UnregisterMaintenance(first); err != nil {
+		t.Errorf("Expected unregistering twice to succeed, got %v", err)
+	}
+	repos, err := MaintenanceRepositories()
+	if err != nil || len(repos) != 1 || comparablePath(repos[0]) != comparablePath(second) {
+		t.Errorf("MaintenanceRepositories() = %v, %v; want only %s", repos, err, second)
+	}
+}
@@ -1,11 +1,22 @@
 package git
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"gitstuff/internal/redact"
+	"gitstuff/internal/timing"
+	"gitstuff/internal/verbosity"
 )
 
 type Status struct {
@@ -13,6 +24,12 @@ type Status struct {
 	CurrentBranch string
 	IsGitRepo     bool
 	HasChanges    bool
+
+	// Populated by GetDetailedStatus only
+	Upstream   string
+	Ahead      int
+	Behind     int
+	StashCount int
 }
 
 func GetRepositoryStatus(repoPath string) (*Status, error) {
@@ -32,11 +49,31 @@ func GetRepositoryStatus(repoPath string) (*Status, error) {
 	}
 
 	status.IsGitRepo = true
+	defer timing.Track(timing.Git, time.Now())
 
+	if statusBackend == BackendGoGit {
+		err := goGitWorkingTreeStatus(repoPath, status)
+		if err == nil {
+			return status, nil
+		}
+		if !errors.Is(err, errGoGitUnsupported) {
+			return nil, err
+		}
+		verbosity.Debug("go-git cannot read %s, falling back to git: %v", repoPath, err)
+	}
+
+	if err := execWorkingTreeStatus(repoPath, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// execWorkingTreeStatus fills in the branch and change state by running git
+func execWorkingTreeStatus(repoPath string, status *Status) error {
 	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current branch: %w", err)
+		return fmt.Errorf("failed to get current branch: %w", err)
 	}
 
 	status.CurrentBranch = strings.TrimSpace(string(output))
@@ -44,44 +81,453 @@ func GetRepositoryStatus(repoPath string) (*Status, error) {
 	cmd = exec.Command("git", "-C", repoPath, "status", "--porcelain")
 	output, err = cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("failed to check git status: %w", err)
+		return fmt.Errorf("failed to check git status: %w", err)
 	}
 
 	status.HasChanges = len(strings.TrimSpace(string(output))) > 0
+	return nil
+}
+
+// GetDetailedStatus returns the repository status along with ahead/behind
+// counts relative to the upstream branch and the number of stash entries.
+func GetDetailedStatus(repoPath string) (*Status, error) {
+	status, err := GetRepositoryStatus(repoPath)
+	if err != nil || !status.Exists || !status.IsGitRepo {
+		return status, err
+	}
+	defer timing.Track(timing.Git, time.Now())
+
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if output, err := cmd.Output(); err == nil {
+		status.Upstream = strings.TrimSpace(string(output))
+	}
+
+	if status.Upstream != "" {
+		cmd = exec.Command("git", "-C", repoPath, "rev-list", "--left-right", "--count", "@{upstream}...HEAD")
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count commits against upstream: %w", err)
+		}
+		fields := strings.Fields(string(output))
+		if len(fields) == 2 {
+			status.Behind, _ = strconv.Atoi(fields[0])
+			status.Ahead, _ = strconv.Atoi(fields[1])
+		}
+	}
+
+	cmd = exec.Command("git", "-C", repoPath, "stash", "list")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+		status.StashCount = len(strings.Split(trimmed, "\n"))
+	}
 
 	return status, nil
 }
 
+// UnpushedCommits counts the commits of the local branches, and of a
+// detached HEAD, that are on no remote-tracking branch, so they exist
+// nowhere but in the repository at repoPath
+func UnpushedCommits(repoPath string) (int, error) {
+	defer timing.Track(timing.Git, time.Now())
+	args := []string{"-C", repoPath, "rev-list", "--count", "--branches"}
+	if exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil {
+		args = append(args, "HEAD")
+	}
+	output, err := exec.Command("git", append(args, "--not", "--remotes")...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unpushed commits: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// FindRepositories walks root and returns the paths of all git repositories
+// below it. Repositories nested inside another repository are not reported.
+func FindRepositories(root string) ([]string, error) {
+	return findRepositories(root, false)
+}
+
+// FindRepositoriesWithBare is like FindRepositories but also reports bare
+// repositories, such as mirrors and backups
+func FindRepositoriesWithBare(root string) ([]string, error) {
+	return findRepositories(root, true)
+}
+
+func findRepositories(root string, includeBare bool) ([]string, error) {
+	defer timing.Track(timing.Filesystem, time.Now())
+	var repos []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		if includeBare && isBareRepository(path) {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	return repos, nil
+}
+
+// isBareRepository reports whether path looks like a bare repository
+func isBareRepository(path string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Fsck checks the integrity of the repository at repoPath with
+// "git fsck --no-dangling" and returns what git reported. A non-nil error
+// means git found problems or could not check the repository.
+func Fsck(repoPath string) (string, error) {
+	var output bytes.Buffer
+	cmd := exec.Command("git", "-C", repoPath, "fsck", "--no-dangling", "--no-progress")
+	if err := runRedacted(cmd, &output, &output); err != nil {
+		return strings.TrimSpace(output.String()), fmt.Errorf("git fsck failed: %w", err)
+	}
+	return strings.TrimSpace(output.String()), nil
+}
+
+// TrackedFiles returns the paths of the files tracked in the repository at
+// repoPath, relative to its root
+func TrackedFiles(repoPath string) ([]string, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	output, err := exec.Command("git", "-C", repoPath, "ls-files", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// TopLevel returns the root of the working tree containing path
+func TopLevel(path string) (string, error) {
+	output, err := exec.Command("git", "-C", path, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a git repository", path)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// InitRepository makes path a git repository whose first branch is branch
+func InitRepository(path, branch string) error {
+	defer timing.Track(timing.Git, time.Now())
+
+	if out, err := exec.Command("git", "init", "--quiet", "--initial-branch="+branch, path).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to initialize repository: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CurrentBranch returns the branch HEAD points at, which may not have any
+// commits yet
+func CurrentBranch(repoPath string) (string, error) {
+	output, err := exec.Command("git", "-C", repoPath, "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("HEAD of %s is not on a branch", repoPath)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitAll stages every change in the working tree at repoPath and commits it
+func CommitAll(repoPath, message string) error {
+	defer timing.Track(timing.Git, time.Now())
+
+	if out, err := exec.Command("git", "-C", repoPath, "add", "--all").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage changes: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "commit", "--quiet", "-m", message).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RemoteURL returns the URL of the remote called name
+func RemoteURL(repoPath, name string) (string, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	output, err := exec.Command("git", "-C", repoPath, "remote", "get-url", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read remote %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RemoteChange describes what EnsureRemote did
+type RemoteChange int
+
+const (
+	RemoteUnchanged RemoteChange = iota
+	RemoteAdded
+	RemoteUpdated
+)
+
+// EnsureRemote makes sure repoPath has a remote called name pointing at url,
+// adding it or changing its URL as needed
+func EnsureRemote(repoPath, name, url string) (RemoteChange, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	current, err := exec.Command("git", "-C", repoPath, "remote", "get-url", name).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return RemoteUnchanged, fmt.Errorf("failed to read remote %s: %w", name, err)
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "remote", "add", name, url).CombinedOutput(); err != nil {
+			return RemoteUnchanged, fmt.Errorf("failed to add remote %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+		}
+		return RemoteAdded, nil
+	}
+
+	if strings.TrimSpace(string(current)) == url {
+		return RemoteUnchanged, nil
+	}
+	if out, err := exec.Command("git", "-C", repoPath, "remote", "set-url", name, url).CombinedOutput(); err != nil {
+		return RemoteUnchanged, fmt.Errorf("failed to update remote %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return RemoteUpdated, nil
+}
+
 func CloneRepository(cloneURL, targetPath string, useSSH bool) error {
+	return CloneRepositoryWithOutput(cloneURL, targetPath, os.Stdout, os.Stderr)
+}
+
+// CloneRepositoryWithOutput clones like CloneRepository but sends git's
+// output to the given writers instead of the process stdout and stderr.
+func CloneRepositoryWithOutput(cloneURL, targetPath string, stdout, stderr io.Writer) error {
+	return CloneRepositoryWithAuth(cloneURL, targetPath, nil, stdout, stderr)
+}
+
+// CloneOptions choose how a repository is cloned. The zero value makes a
+// plain clone.
+type CloneOptions struct {
+	// Auth is sent with HTTPS requests to the remote
+	Auth *HTTPAuth
+
+	// Reference is a local repository whose objects the clone borrows
+	// through .git/objects/info/alternates instead of downloading them
+	// again. It is ignored when it is not a repository.
+	Reference string
+}
+
+// CloneRepositoryWithOptions clones cloneURL to targetPath the way opts asks,
+// sending git's output to the given writers
+func CloneRepositoryWithOptions(cloneURL, targetPath string, opts CloneOptions, stdout, stderr io.Writer) error {
 	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
 		return fmt.Errorf("failed to create target directory: %w", err)
 	}
 
-	var cmd *exec.Cmd
-	if useSSH {
-		cmd = exec.Command("git", "clone", cloneURL, targetPath)
-	} else {
-		cmd = exec.Command("git", "clone", cloneURL, targetPath)
+	args := []string{"clone"}
+	if opts.Reference != "" {
+		args = append(args, "--reference-if-able", opts.Reference)
 	}
-
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	err := runNetwork(stdout, stderr, func(ctx context.Context) *exec.Cmd {
+		cmd := networkCommand(ctx, append(args, cloneURL, targetPath)...)
+		if isHTTPURL(cloneURL) {
+			opts.Auth.apply(cmd)
+		}
+		return cmd
+	})
+	if err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
-
 	return nil
 }
 
 func PullRepository(repoPath string) error {
-	cmd := exec.Command("git", "-C", repoPath, "pull")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return PullRepositoryWithOutput(repoPath, os.Stdout, os.Stderr)
+}
+
+// PullRepositoryWithOutput pulls like PullRepository but sends git's output
+// to the given writers instead of the process stdout and stderr.
+func PullRepositoryWithOutput(repoPath string, stdout, stderr io.Writer) error {
+	return PullRepositoryWithOptions(repoPath, PullOptions{}, stdout, stderr)
+}
 
-	if err := cmd.Run(); err != nil {
+// PullRepositoryRebaseWithOutput pulls with --rebase so local commits are
+// replayed on top of the upstream branch instead of merged
+func PullRepositoryRebaseWithOutput(repoPath string, stdout, stderr io.Writer) error {
+	return PullRepositoryWithOptions(repoPath, PullOptions{Rebase: true}, stdout, stderr)
+}
+
+// PullOptions choose how a pull integrates the upstream branch. The zero
+// value leaves it to the repository's own pull configuration.
+type PullOptions struct {
+	// Rebase replays local commits on top of the upstream branch
+	Rebase bool
+
+	// Merge merges even when git is configured to rebase on pull
+	Merge bool
+
+	// FFOnly refuses to pull when the branches have diverged instead of
+	// creating a merge commit; Rebase takes precedence
+	FFOnly bool
+
+	// Autostash stashes uncommitted changes before pulling and restores
+	// them afterwards
+	Autostash bool
+
+	// Auth is sent with HTTPS requests to the remote
+	Auth *HTTPAuth
+}
+
+// PullRepositoryWithOptions pulls the repository at repoPath the way opts
+// asks, sending git's output to the given writers
+func PullRepositoryWithOptions(repoPath string, opts PullOptions, stdout, stderr io.Writer) error {
+	args := []string{"-C", repoPath, "pull"}
+	switch {
+	case opts.Rebase:
+		args = append(args, "--rebase")
+	case opts.FFOnly:
+		args = append(args, "--ff-only")
+	case opts.Merge:
+		args = append(args, "--no-rebase")
+	}
+	if opts.Autostash {
+		args = append(args, "--autostash")
+	}
+
+	err := runNetwork(stdout, stderr, func(ctx context.Context) *exec.Cmd {
+		cmd := networkCommand(ctx, args...)
+		opts.Auth.apply(cmd)
+		return cmd
+	})
+	if err != nil {
+		if opts.Rebase {
+			return fmt.Errorf("failed to pull repository with rebase: %w", err)
+		}
 		return fmt.Errorf("failed to pull repository: %w", err)
 	}
+	return nil
+}
+
+// runRedacted runs cmd with credentials masked from its output, since git
+// echoes remote URLs that may embed tokens
+func runRedacted(cmd *exec.Cmd, stdout, stderr io.Writer) error {
+	defer timing.Track(timing.Git, time.Now())
+	redactedOut, redactedErr := redact.NewWriters(stdout, stderr)
+	cmd.Stdout = redactedOut
+	cmd.Stderr = redactedErr
+
+	err := cmd.Run()
+	_ = redactedOut.Flush()
+	if redactedErr != redactedOut {
+		_ = redactedErr.Flush()
+	}
+	return err
+}
+
+// ResolveRef returns the first of refs that exists in the repository at
+// repoPath, or an empty string if none does
+func ResolveRef(repoPath string, refs ...string) string {
+	for _, ref := range refs {
+		if err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Run(); err == nil {
+			return ref
+		}
+	}
+	return ""
+}
+
+// CommitFilesOnBranch creates branch at base and commits files (paths
+// relative to the repository root) to it. The commit is made in a temporary
+// worktree so the checkout at repoPath, including uncommitted changes, is
+// left untouched.
+func CommitFilesOnBranch(repoPath, branch, base string, files map[string][]byte, message string) error {
+	worktree, err := AddWorktree(repoPath, branch, base)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = worktree.Remove(false) }()
+
+	for path, content := range files {
+		target := filepath.Join(worktree.Path, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return worktree.CommitAll(message)
+}
 
+// Fetch updates the remote-tracking branches of origin
+func Fetch(repoPath string, stdout, stderr io.Writer) error {
+	if err := runNetworkCommand(stdout, stderr, "-C", repoPath, "fetch", "--quiet", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	return nil
+}
+
+// CheckoutBranch switches the repository at repoPath to branch. A branch
+// that only exists on origin is fetched if needed and checked out tracking it.
+func CheckoutBranch(repoPath, branch string, stdout, stderr io.Writer) error {
+	if ResolveRef(repoPath, "refs/heads/"+branch, "refs/remotes/origin/"+branch) == "" {
+		if err := Fetch(repoPath, stdout, stderr); err != nil {
+			return err
+		}
+	}
+	cmd := exec.Command("git", "-C", repoPath, "checkout", "--quiet", branch)
+	if err := runRedacted(cmd, stdout, stderr); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", branch, err)
+	}
+	return nil
+}
+
+// FetchRepository updates the remote-tracking branches of every remote,
+// pruning those deleted upstream, without touching the working tree
+func FetchRepository(repoPath string, stdout, stderr io.Writer) error {
+	return FetchRepositoryWithAuth(repoPath, nil, stdout, stderr)
+}
+
+// FetchRepositoryWithAuth fetches like FetchRepository, sending auth with
+// HTTPS requests
+func FetchRepositoryWithAuth(repoPath string, auth *HTTPAuth, stdout, stderr io.Writer) error {
+	err := runNetwork(stdout, stderr, func(ctx context.Context) *exec.Cmd {
+		cmd := networkCommand(ctx, "-C", repoPath, "fetch", "--all", "--prune")
+		auth.apply(cmd)
+		return cmd
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch repository: %w", err)
+	}
+	return nil
+}
+
+// PushBranch pushes branch to the origin remote and sets it as upstream
+func PushBranch(repoPath, branch string, stdout, stderr io.Writer) error {
+	if err := runNetworkCommand(stdout, stderr, "-C", repoPath, "push", "--set-upstream", "origin", branch); err != nil {
+		return fmt.Errorf("failed to push branch %s: %w", branch, err)
+	}
 	return nil
 }
@@ -0,0 +1,104 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// CheckTagName returns an error if tag is not a valid tag name
+func CheckTagName(tag string) error {
+	if err := exec.Command("git", "check-ref-format", "refs/tags/"+tag).Run(); err != nil {
+		return fmt.Errorf("invalid tag name %q", tag)
+	}
+	return nil
+}
+
+// RevParse returns the commit ref points to
+func RevParse(repoPath, ref string) (string, error) {
+	out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %s", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// LocalTagCommit returns the commit tag points to in the repository at
+// repoPath, or an empty string if there is no such tag
+func LocalTagCommit(repoPath, tag string) string {
+	commit, err := RevParse(repoPath, "refs/tags/"+tag)
+	if err != nil {
+		return ""
+	}
+	return commit
+}
+
+// RemoteTagCommit returns the commit tag points to on the origin remote, or
+// an empty string if the remote has no such tag
+func RemoteTagCommit(repoPath, tag string) (string, error) {
+	ref := "refs/tags/" + tag
+	var stdout, stderr bytes.Buffer
+	if err := runNetworkCommand(&stdout, &stderr, "-C", repoPath, "ls-remote", "--tags", "origin", ref, ref+"^{}"); err != nil {
+		return "", fmt.Errorf("failed to list remote tags: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Annotated tags are listed twice; the peeled ^{} line is the commit
+	commit := ""
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		sha, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		switch name {
+		case ref + "^{}":
+			return sha, nil
+		case ref:
+			commit = sha
+		}
+	}
+	return commit, nil
+}
+
+// CreateTag tags ref. A message makes an annotated tag; sign makes a GPG
+// signed one, using the tag name as message when there is none.
+func CreateTag(repoPath, tag, ref, message string, sign bool) error {
+	defer timing.Track(timing.Git, time.Now())
+
+	args := []string{"-C", repoPath, "tag"}
+	switch {
+	case sign:
+		if message == "" {
+			message = tag
+		}
+		args = append(args, "-s", "-m", message)
+	case message != "":
+		args = append(args, "-a", "-m", message)
+	}
+	args = append(args, tag, ref)
+
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w: %s", tag, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// DeleteTag deletes a local tag
+func DeleteTag(repoPath, tag string) error {
+	if out, err := exec.Command("git", "-C", repoPath, "tag", "-d", tag).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete tag %s: %w: %s", tag, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// PushTag pushes tag to the origin remote
+func PushTag(repoPath, tag string, stdout, stderr io.Writer) error {
+	if err := runNetworkCommand(stdout, stderr, "-C", repoPath, "push", "origin", "refs/tags/"+tag); err != nil {
+		return fmt.Errorf("failed to push tag %s: %w", tag, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	repo := filepath.Join(dir, "repo")
+	runGit(t, "init", "--bare", remote)
+	runGit(t, "init", "-b", "main", repo)
+	runGit(t, "-C", repo, "commit", "--allow-empty", "-m", "Initial commit")
+	runGit(t, "-C", repo, "remote", "add", "origin", remote)
+
+	head, err := RevParse(repo, "main")
+	if err != nil || len(head) != 40 {
+		t.Fatalf("RevParse() = %q, %v", head, err)
+	}
+	if _, err := RevParse(repo, "missing"); err == nil {
+		t.Error("Expected an error for an unknown revision")
+	}
+
+	if err := CheckTagName("v1.0.0"); err != nil {
+		t.Errorf("CheckTagName(v1.0.0) = %v", err)
+	}
+	if err := CheckTagName("bad..tag"); err == nil {
+		t.Error("Expected an error for an invalid tag name")
+	}
+
+	if commit := LocalTagCommit(repo, "v1.0.0"); commit != "" {
+		t.Errorf("LocalTagCommit() = %q before tagging", commit)
+	}
+	if err := CreateTag(repo, "v1.0.0", "main", "Release 1.0.0", false); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if commit := LocalTagCommit(repo, "v1.0.0"); commit != head {
+		t.Errorf("LocalTagCommit() = %q, want %q", commit, head)
+	}
+	if out, err := exec.Command("git", "-C", repo, "cat-file", "-t", "v1.0.0").Output(); err != nil || string(out) != "tag\n" {
+		t.Errorf("Expected an annotated tag, got %q, %v", out, err)
+	}
+
+	if commit, err := RemoteTagCommit(repo, "v1.0.0"); err != nil || commit != "" {
+		t.Errorf("RemoteTagCommit() = %q, %v before pushing", commit, err)
+	}
+	var output bytes.Buffer
+	if err := PushTag(repo, "v1.0.0", &output, &output); err != nil {
+		t.Fatalf("PushTag failed: %v\n%s", err, output.String())
+	}
+	if commit, err := RemoteTagCommit(repo, "v1.0.0"); err != nil || commit != head {
+		t.Errorf("RemoteTagCommit() = %q, %v, want %q", commit, err, head)
+	}
+
+	if err := DeleteTag(repo, "v1.0.0"); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+	if commit := LocalTagCommit(repo, "v1.0.0"); commit != "" {
+		t.Errorf("Expected the tag to be deleted, got %q", commit)
+	}
+}
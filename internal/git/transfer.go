@@ -0,0 +1,145 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var transferProxy struct {
+	addr       string
+	sshCommand string
+}
+
+// SetTransferProxy routes clone and pull traffic through the HTTP proxy at
+// proxyAddr. sshProxyCommand is added as ssh's ProxyCommand to the ssh
+// command git would otherwise run, so SSH remotes go through the proxy too.
+// Empty values restore direct connections.
+func SetTransferProxy(proxyAddr, sshProxyCommand string) {
+	transferProxy.addr = proxyAddr
+	transferProxy.sshCommand = sshProxyCommand
+}
+
+// abortContext kills the network commands still running when it is done
+var abortContext = context.Background()
+
+// SetAbortContext makes clone, pull and fetch commands get killed once ctx is
+// done, for when the user asks to stop without waiting for them
+func SetAbortContext(ctx context.Context) {
+	abortContext = ctx
+}
+
+// operationTimeout bounds how long a single clone, pull, fetch or push may
+// run; zero leaves them unbounded
+var operationTimeout time.Duration
+
+// ErrTimeout is wrapped by the errors of network commands that were killed
+// for running longer than the operation timeout
+var ErrTimeout = errors.New("timed out")
+
+// SetOperationTimeout makes clone, pull, fetch and push commands get killed
+// once they run longer than timeout, so a hung connection fails its
+// repository instead of stalling the run. Zero turns the timeout off.
+func SetOperationTimeout(timeout time.Duration) {
+	operationTimeout = timeout
+}
+
+// OperationTimeout returns the timeout set with SetOperationTimeout
+func OperationTimeout() time.Duration {
+	return operationTimeout
+}
+
+// waitDelay is how long a killed command gets to close its output, which
+// helpers it started, like ssh, may still hold open
+var waitDelay = 5 * time.Second
+
+// networkCommand builds a git command that talks to a remote, applying the
+// transfer proxy when one is set. The command is killed once ctx is done.
+func networkCommand(ctx context.Context, args ...string) *exec.Cmd {
+	if transferProxy.addr == "" {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.WaitDelay = waitDelay
+		return cmd
+	}
+
+	proxyArgs := []string{"-c", "http.proxy=http://" + transferProxy.addr}
+	cmd := exec.CommandContext(ctx, "git", append(proxyArgs, args...)...)
+	cmd.WaitDelay = waitDelay
+	cmd.Env = os.Environ()
+	if transferProxy.sshCommand != "" {
+		repoPath := ""
+		if len(args) >= 2 && args[0] == "-C" {
+			repoPath = args[1]
+		}
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND="+sshCommand(repoPath)+" -o "+ShellQuote("ProxyCommand="+transferProxy.sshCommand))
+	}
+	return cmd
+}
+
+// sshCommand returns the ssh command git runs for the repository at
+// repoPath, or outside of any when it is empty: GIT_SSH_COMMAND,
+// core.sshCommand, GIT_SSH or plain ssh, in the order git looks for them
+func sshCommand(repoPath string) string {
+	if command := os.Getenv("GIT_SSH_COMMAND"); command != "" {
+		return command
+	}
+	if command := ConfigValue(repoPath, "core.sshCommand"); command != "" {
+		return command
+	}
+	if program := os.Getenv("GIT_SSH"); program != "" {
+		return ShellQuote(program)
+	}
+	return "ssh"
+}
+
+// ConfigValue returns the value git has for key in the repository at
+// repoPath, or in the working directory when repoPath is empty. It is empty
+// when key is not set.
+func ConfigValue(repoPath, key string) string {
+	args := []string{"config", "--get", key}
+	if repoPath != "" {
+		args = append([]string{"-C", repoPath}, args...)
+	}
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// runNetwork runs the command that build returns for a context that ends
+// when the run is aborted or the operation timeout runs out, whichever
+// comes first
+func runNetwork(stdout, stderr io.Writer, build func(ctx context.Context) *exec.Cmd) error {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if operationTimeout > 0 {
+		ctx, cancel = context.WithTimeout(abortContext, operationTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(abortContext)
+	}
+	defer cancel()
+
+	err := runRedacted(build(ctx), stdout, stderr)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrTimeout, operationTimeout)
+	}
+	return err
+}
+
+// runNetworkCommand runs git with args through runNetwork
+func runNetworkCommand(stdout, stderr io.Writer, args ...string) error {
+	return runNetwork(stdout, stderr, func(ctx context.Context) *exec.Cmd {
+		return networkCommand(ctx, args...)
+	})
+}
+
+// ShellQuote quotes s as a single POSIX shell word
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
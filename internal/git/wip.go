@@ -0,0 +1,87 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// emptyTree is the hash of git's empty tree, which the changes of a
+// repository without commits are compared against
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// WorkInProgress summarizes the uncommitted changes of a working tree
+type WorkInProgress struct {
+	Files      int // Changed, deleted and untracked files
+	Untracked  int
+	Insertions int // Lines changed in tracked text files, staged or not
+	Deletions  int
+
+	// OldestFile is the changed file with the oldest modification time,
+	// which tells how long the work has been sitting there. Deleted files
+	// have no time, so it is empty when nothing else changed.
+	OldestFile     string
+	OldestModified time.Time
+}
+
+// GetWorkInProgress returns the uncommitted changes of the repository at
+// repoPath, staged and unstaged, including untracked files
+func GetWorkInProgress(repoPath string) (*WorkInProgress, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	status, err := exec.Command("git", "-C", repoPath, "status", "--porcelain=v1", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	wip := &WorkInProgress{}
+	entries := bytes.Split(status, []byte{0})
+	for i := 0; i < len(entries); i++ {
+		entry := string(entries[i])
+		if len(entry) < 4 {
+			continue
+		}
+		code, path := entry[:2], entry[3:]
+		if code[0] == 'R' || code[0] == 'C' {
+			i++ // The original path of a rename or copy follows
+		}
+		wip.Files++
+		if code == "??" {
+			wip.Untracked++
+		}
+		info, err := os.Lstat(filepath.Join(repoPath, filepath.FromSlash(path)))
+		if err != nil {
+			continue
+		}
+		if wip.OldestFile == "" || info.ModTime().Before(wip.OldestModified) {
+			wip.OldestFile, wip.OldestModified = path, info.ModTime()
+		}
+	}
+
+	base := "HEAD"
+	if ResolveRef(repoPath, "HEAD") == "" {
+		base = emptyTree
+	}
+	numstat, err := exec.Command("git", "-C", repoPath, "diff", "--numstat", base).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff the working tree: %w", err)
+	}
+	for _, line := range strings.Split(string(numstat), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		// Binary files have "-" for both counts
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		wip.Insertions += added
+		wip.Deletions += deleted
+	}
+	return wip, nil
+}
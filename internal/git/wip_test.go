@@ -0,0 +1,76 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetWorkInProgress(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	repo := filepath.Join(t.TempDir(), "repo")
+	runGit(t, "init", "-b", "main", repo)
+	write := func(name, content string, modified time.Time) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without commits, staged files are compared with the empty tree
+	now := time.Now()
+	write("main.go", "package main\n", now)
+	runGit(t, "-C", repo, "add", "main.go")
+	wip, err := GetWorkInProgress(repo)
+	if err != nil {
+		t.Fatalf("GetWorkInProgress() error = %v", err)
+	}
+	if wip.Files != 1 || wip.Insertions != 1 || wip.OldestFile != "main.go" {
+		t.Errorf("Unexpected work in progress without commits: %+v", wip)
+	}
+
+	write("old.txt", "one\ntwo\nthree\n", now)
+	write("gone.txt", "bye\n", now)
+	runGit(t, "-C", repo, "add", ".")
+	runGit(t, "-C", repo, "commit", "-m", "Initial commit")
+
+	clean, err := GetWorkInProgress(repo)
+	if err != nil || clean.Files != 0 || clean.OldestFile != "" {
+		t.Errorf("Expected no work in progress in a clean repository, got %+v, %v", clean, err)
+	}
+
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	write("old.txt", "one\n2\n", weekAgo)
+	write("docs/notes.md", "draft\n", now.Add(-time.Hour))
+	if err := os.Remove(filepath.Join(repo, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, "-C", repo, "mv", "main.go", "app.go")
+
+	wip, err = GetWorkInProgress(repo)
+	if err != nil {
+		t.Fatalf("GetWorkInProgress() error = %v", err)
+	}
+	if wip.Files != 4 || wip.Untracked != 1 {
+		t.Errorf("Expected 4 files with 1 untracked, got %+v", wip)
+	}
+	// Untracked files are not part of the diff
+	if wip.Insertions != 1 || wip.Deletions != 3 {
+		t.Errorf("Expected +1 -3, got +%d -%d", wip.Insertions, wip.Deletions)
+	}
+	if wip.OldestFile != "old.txt" || wip.OldestModified.Sub(weekAgo).Abs() > time.Second {
+		t.Errorf("Expected old.txt to be the oldest change, got %s at %v", wip.OldestFile, wip.OldestModified)
+	}
+}
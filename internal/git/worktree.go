@@ -0,0 +1,91 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// Worktree is a temporary checkout of a new branch, used to prepare commits
+// without touching the main checkout of a repository
+type Worktree struct {
+	RepoPath string
+	Path     string
+	Branch   string
+}
+
+// AddWorktree creates branch at base and checks it out in a temporary
+// directory. It fails if the branch already exists.
+func AddWorktree(repoPath, branch, base string) (*Worktree, error) {
+	defer timing.Track(timing.Git, time.Now())
+
+	path, err := os.MkdirTemp("", "gitstuff-worktree-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	if out, err := exec.Command("git", "-C", repoPath, "worktree", "add", "--quiet", "-b", branch, path, base).CombinedOutput(); err != nil {
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to create branch %s: %w: %s", branch, err, strings.TrimSpace(string(out)))
+	}
+	return &Worktree{RepoPath: repoPath, Path: path, Branch: branch}, nil
+}
+
+// HasChanges reports whether the worktree has any uncommitted changes,
+// including untracked files
+func (w *Worktree) HasChanges() (bool, error) {
+	out, err := exec.Command("git", "-C", w.Path, "status", "--porcelain").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check worktree status: %w", err)
+	}
+	return len(strings.TrimSpace(string(out))) > 0, nil
+}
+
+// DiffStat summarises the uncommitted changes in the worktree
+func (w *Worktree) DiffStat() (string, error) {
+	if out, err := exec.Command("git", "-C", w.Path, "add", "--all").CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stage changes: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	out, err := exec.Command("git", "-C", w.Path, "diff", "--cached", "--stat").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff worktree: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// ApplyPatch applies a patch file to the worktree
+func (w *Worktree) ApplyPatch(patchPath string, stdout, stderr io.Writer) error {
+	cmd := exec.Command("git", "-C", w.Path, "apply", "--whitespace=nowarn", patchPath)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+	return nil
+}
+
+// CommitAll stages every change in the worktree and commits it
+func (w *Worktree) CommitAll(message string) error {
+	return CommitAll(w.Path, message)
+}
+
+// Remove deletes the temporary checkout. With deleteBranch the branch is
+// deleted too, discarding anything committed to it.
+func (w *Worktree) Remove(deleteBranch bool) error {
+	defer os.RemoveAll(w.Path)
+
+	if out, err := exec.Command("git", "-C", w.RepoPath, "worktree", "remove", "--force", w.Path).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove worktree: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	if deleteBranch {
+		if out, err := exec.Command("git", "-C", w.RepoPath, "branch", "-D", w.Branch).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete branch %s: %w: %s", w.Branch, err, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := filepath.Join(t.TempDir(), "repo")
+	runGit(t, "init", "-b", "main", repo)
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, "-C", repo, "add", "README.md")
+	runGit(t, "-C", repo, "commit", "-m", "Initial commit")
+
+	patch := filepath.Join(t.TempDir(), "change.patch")
+	if err := os.WriteFile(patch, []byte("--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-hello\n+hello world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, err := AddWorktree(repo, "change", "main")
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if _, err := AddWorktree(repo, "change", "main"); err == nil {
+		t.Error("Expected an error when the branch already exists")
+	}
+
+	if changed, err := worktree.HasChanges(); err != nil || changed {
+		t.Fatalf("HasChanges() = %v, %v for a fresh worktree", changed, err)
+	}
+	var output bytes.Buffer
+	if err := worktree.ApplyPatch(patch, &output, &output); err != nil {
+		t.Fatalf("ApplyPatch failed: %v\n%s", err, output.String())
+	}
+	if changed, err := worktree.HasChanges(); err != nil || !changed {
+		t.Fatalf("HasChanges() = %v, %v after applying a patch", changed, err)
+	}
+	stat, err := worktree.DiffStat()
+	if err != nil || !strings.Contains(stat, "README.md") || !strings.Contains(stat, "1 file changed") {
+		t.Errorf("DiffStat() = %q, %v", stat, err)
+	}
+
+	if err := worktree.CommitAll("Update README"); err != nil {
+		t.Fatalf("CommitAll failed: %v", err)
+	}
+	if err := worktree.Remove(false); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(worktree.Path); !os.IsNotExist(err) {
+		t.Errorf("Expected worktree directory to be removed, got %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", repo, "show", "change:README.md").Output()
+	if err != nil || string(out) != "hello world\n" {
+		t.Errorf("Expected the commit to be kept on the branch, got %q, %v", out, err)
+	}
+	content, err := os.ReadFile(filepath.Join(repo, "README.md"))
+	if err != nil || string(content) != "hello\n" {
+		t.Errorf("Expected the main checkout to be untouched, got %q, %v", content, err)
+	}
+
+	discarded, err := AddWorktree(repo, "discard", "main")
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if err := discarded.Remove(true); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if ref := ResolveRef(repo, "discard"); ref != "" {
+		t.Error("Expected the branch to be deleted")
+	}
+}
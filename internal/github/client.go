@@ -2,28 +2,36 @@ package github
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v67/github"
 	"golang.org/x/oauth2"
 
+	"gitstuff/internal/httpclient"
 	"gitstuff/internal/scm"
+	"gitstuff/internal/verbosity"
 )
 
+// forkLookupConcurrency limits parallel requests when looking up fork parents
+const forkLookupConcurrency = 8
+
 type Client struct {
 	client *github.Client
-	ctx    context.Context
 }
 
 func NewClient(baseURL, token string, insecure bool) (*Client, error) {
-	ctx := context.Background()
+	return NewClientWithOptions(baseURL, token, httpclient.Options{Insecure: insecure})
+}
 
+func NewClientWithOptions(baseURL, token string, opts httpclient.Options) (*Client, error) {
 	// Validate required parameters
 	if token == "" {
 		return nil, fmt.Errorf("GitHub access token is required")
@@ -32,29 +40,12 @@ func NewClient(baseURL, token string, insecure bool) (*Client, error) {
 		return nil, fmt.Errorf("GitHub base URL is required")
 	}
 
-	// Create HTTP client
-	var httpClient *http.Client
-	if insecure {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		httpClient = &http.Client{Transport: tr}
-	}
-
-	// Set up OAuth2 token source
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-
-	var tc *http.Client
-	if httpClient != nil {
-		// Combine OAuth2 with custom transport
-		tc = &http.Client{
-			Transport: &oauth2.Transport{
-				Source: ts,
-				Base:   httpClient.Transport,
-			},
-		}
-	} else {
-		tc = oauth2.NewClient(ctx, ts)
+	// Combine OAuth2 with the shared transport chain
+	tc := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+			Base:   httpclient.NewTransport(opts),
+		},
 	}
 
 	client := github.NewClient(tc)
@@ -79,7 +70,7 @@ func NewClient(baseURL, token string, insecure bool) (*Client, error) {
 		client.BaseURL = baseURLParsed
 	}
 
-	return &Client{client: client, ctx: ctx}, nil
+	return &Client{client: client}, nil
 }
 
 func normalizeURL(baseURL string) (string, error) {
@@ -112,7 +103,55 @@ func (c *Client) GetProviderType() string {
 	return "github"
 }
 
-func (c *Client) ListAllRepositories() ([]*scm.Repository, error) {
+// requestContext prepares ctx for API calls. Once a response reports the
+// rate limit used up, go-github fails later requests without sending them
+// unless told to wait for the reset.
+func requestContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, github.SleepUntilPrimaryRateLimitResetWhenRateLimited, true)
+}
+
+func (c *Client) CheckHealth(ctx context.Context) (*scm.ProviderHealth, error) {
+	user, resp, err := c.client.Users.Get(requestContext(ctx), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated user: %w", apiError(resp, err))
+	}
+
+	health := &scm.ProviderHealth{
+		User:               user.GetLogin(),
+		RateLimitLimit:     resp.Rate.Limit,
+		RateLimitRemaining: resp.Rate.Remaining,
+		RateLimitReset:     resp.Rate.Reset.Time,
+	}
+
+	// Classic tokens list their scopes in X-OAuth-Scopes; fine-grained
+	// tokens and GitHub App tokens send no such header
+	if values, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		health.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(values, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				health.Scopes = append(health.Scopes, scope)
+			}
+		}
+	}
+
+	return health, nil
+}
+
+// ServerVersion returns the GitHub Enterprise Server version, which every
+// API response carries in a header. github.com has none.
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	if c.client.BaseURL.Host == "api.github.com" {
+		return "", nil
+	}
+	_, resp, err := c.client.Meta.Get(requestContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get server metadata: %w", apiError(resp, err))
+	}
+	return resp.Header.Get("X-GitHub-Enterprise-Version"), nil
+}
+
+func (c *Client) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
+	ctx = requestContext(ctx)
 	var allRepos []*scm.Repository
 
 	opts := &github.RepositoryListOptions{
@@ -124,9 +163,9 @@ func (c *Client) ListAllRepositories() ([]*scm.Repository, error) {
 	}
 
 	for {
-		repos, resp, err := c.client.Repositories.List(c.ctx, "", opts)
+		repos, resp, err := c.client.Repositories.List(ctx, "", opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories: %w", err)
+			return nil, fmt.Errorf("failed to list repositories: %w", apiError(resp, err))
 		}
 
 		for _, repo := range repos {
@@ -134,17 +173,7 @@ func (c *Client) ListAllRepositories() ([]*scm.Repository, error) {
 				continue // Skip repos we don't have access to
 			}
 
-			scmRepo := &scm.Repository{
-				ID:            strconv.FormatInt(repo.GetID(), 10),
-				Name:          repo.GetName(),
-				FullPath:      repo.GetFullName(),
-				CloneURL:      repo.GetCloneURL(),
-				SSHCloneURL:   repo.GetSSHURL(),
-				DefaultBranch: repo.GetDefaultBranch(),
-				WebURL:        repo.GetHTMLURL(),
-				Provider:      "github",
-			}
-			allRepos = append(allRepos, scmRepo)
+			allRepos = append(allRepos, toRepository(repo))
 		}
 
 		if resp.NextPage == 0 {
@@ -156,11 +185,13 @@ func (c *Client) ListAllRepositories() ([]*scm.Repository, error) {
 	sort.Slice(allRepos, func(i, j int) bool {
 		return allRepos[i].FullPath < allRepos[j].FullPath
 	})
+	c.resolveForkParents(ctx, allRepos)
 
 	return allRepos, nil
 }
 
-func (c *Client) ListRepositoriesInGroup(orgName string) ([]*scm.Repository, error) {
+func (c *Client) ListRepositoriesInGroup(ctx context.Context, orgName string) ([]*scm.Repository, error) {
+	ctx = requestContext(ctx)
 	var allRepos []*scm.Repository
 
 	opts := &github.RepositoryListByOrgOptions{
@@ -172,23 +203,13 @@ func (c *Client) ListRepositoriesInGroup(orgName string) ([]*scm.Repository, err
 	}
 
 	for {
-		repos, resp, err := c.client.Repositories.ListByOrg(c.ctx, orgName, opts)
+		repos, resp, err := c.client.Repositories.ListByOrg(ctx, orgName, opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list repositories for organization %s: %w", orgName, err)
+			return nil, fmt.Errorf("failed to list repositories for organization %s: %w", orgName, apiError(resp, err))
 		}
 
 		for _, repo := range repos {
-			scmRepo := &scm.Repository{
-				ID:            strconv.FormatInt(repo.GetID(), 10),
-				Name:          repo.GetName(),
-				FullPath:      repo.GetFullName(),
-				CloneURL:      repo.GetCloneURL(),
-				SSHCloneURL:   repo.GetSSHURL(),
-				DefaultBranch: repo.GetDefaultBranch(),
-				WebURL:        repo.GetHTMLURL(),
-				Provider:      "github",
-			}
-			allRepos = append(allRepos, scmRepo)
+			allRepos = append(allRepos, toRepository(repo))
 		}
 
 		if resp.NextPage == 0 {
@@ -200,12 +221,113 @@ func (c *Client) ListRepositoriesInGroup(orgName string) ([]*scm.Repository, err
 	sort.Slice(allRepos, func(i, j int) bool {
 		return allRepos[i].FullPath < allRepos[j].FullPath
 	})
+	c.resolveForkParents(ctx, allRepos)
 
 	return allRepos, nil
 }
 
-func (c *Client) BuildRepositoryTree() (*scm.RepositoryTree, error) {
-	repos, err := c.ListAllRepositories()
+func toRepository(repo *github.Repository) *scm.Repository {
+	scmRepo := &scm.Repository{
+		ID:            strconv.FormatInt(repo.GetID(), 10),
+		Name:          repo.GetName(),
+		FullPath:      repo.GetFullName(),
+		CloneURL:      repo.GetCloneURL(),
+		SSHCloneURL:   repo.GetSSHURL(),
+		DefaultBranch: repo.GetDefaultBranch(),
+		WebURL:        repo.GetHTMLURL(),
+		Provider:      "github",
+		Archived:      repo.GetArchived(),
+		Description:   repo.GetDescription(),
+		Fork:          repo.GetFork(),
+		Visibility:    repo.GetVisibility(),
+		Topics:        repo.Topics,
+		Language:      repo.GetLanguage(),
+		Size:          int64(repo.GetSize()) * 1024, // Reported in kilobytes
+		Stars:         repo.GetStargazersCount(),
+		LastActivity:  repo.GetPushedAt().Time,
+	}
+	if scmRepo.LastActivity.IsZero() {
+		scmRepo.LastActivity = repo.GetUpdatedAt().Time
+	}
+	if scmRepo.Visibility == "" && repo.Private != nil {
+		// Older GitHub Enterprise versions only report whether it is private
+		scmRepo.Visibility = "public"
+		if repo.GetPrivate() {
+			scmRepo.Visibility = "private"
+		}
+	}
+	if parent := repo.GetParent(); parent != nil {
+		scmRepo.ParentFullPath = parent.GetFullName()
+		scmRepo.ParentCloneURL = parent.GetCloneURL()
+		scmRepo.ParentSSHCloneURL = parent.GetSSHURL()
+	}
+	return scmRepo
+}
+
+// SearchRepositories returns the repositories whose name or description
+// contains query, best matches first
+func (c *Client) SearchRepositories(ctx context.Context, query string, limit int) ([]*scm.Repository, error) {
+	ctx = requestContext(ctx)
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: min(limit, 100)}}
+
+	var repos []*scm.Repository
+	for len(repos) < limit {
+		result, resp, err := c.client.Search.Repositories(ctx, query+" in:name,description", opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search repositories: %w", apiError(resp, err))
+		}
+		for _, repo := range result.Repositories {
+			repos = append(repos, toRepository(repo))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(repos) > limit {
+		repos = repos[:limit]
+	}
+	return repos, nil
+}
+
+// resolveForkParents fills in the parent of each fork, which list responses
+// do not include. A fork whose lookup fails keeps an unknown parent.
+func (c *Client) resolveForkParents(ctx context.Context, repos []*scm.Repository) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, forkLookupConcurrency)
+	for _, repo := range repos {
+		if !repo.Fork || repo.ParentFullPath != "" {
+			continue
+		}
+		owner, name, ok := strings.Cut(repo.FullPath, "/")
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			full, _, err := c.client.Repositories.Get(ctx, owner, name)
+			if err != nil {
+				verbosity.Debug("Failed to look up parent of fork %s: %v", repo.FullPath, err)
+				return
+			}
+			if parent := full.GetParent(); parent != nil {
+				repo.ParentFullPath = parent.GetFullName()
+				repo.ParentCloneURL = parent.GetCloneURL()
+				repo.ParentSSHCloneURL = parent.GetSSHURL()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *Client) BuildRepositoryTree(ctx context.Context) (*scm.RepositoryTree, error) {
+	repos, err := c.ListAllRepositories(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -248,3 +370,394 @@ func buildTreeFromRepos(repos []*scm.Repository) *scm.RepositoryTree {
 
 	return tree
 }
+
+// splitFullPath splits a repository's full path into owner and name
+func splitFullPath(repo *scm.Repository) (string, string, error) {
+	owner, name, ok := strings.Cut(repo.FullPath, "/")
+	if !ok {
+		return "", "", fmt.Errorf("invalid repository path: %s", repo.FullPath)
+	}
+	return owner, name, nil
+}
+
+// CreateRepository creates an empty repository in the organization of the
+// full path, or under the authenticated user when the path has no owner or
+// names the user. GitHub takes the default branch from the first push.
+func (c *Client) CreateRepository(ctx context.Context, repo scm.NewRepository) (*scm.Repository, error) {
+	ctx = requestContext(ctx)
+	owner, name, found := strings.Cut(repo.FullPath, "/")
+	if !found {
+		owner, name = "", repo.FullPath
+	} else if strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository path %s: GitHub repositories are owner/name", repo.FullPath)
+	}
+	if owner != "" {
+		user, resp, err := c.client.Users.Get(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get authenticated user: %w", apiError(resp, err))
+		}
+		if strings.EqualFold(owner, user.GetLogin()) {
+			owner = ""
+		}
+	}
+
+	created, resp, err := c.client.Repositories.Create(ctx, owner, &github.Repository{
+		Name:        github.String(name),
+		Description: github.String(repo.Description),
+		Private:     github.Bool(repo.Visibility != "public"),
+		Visibility:  github.String(repo.Visibility),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	return toRepository(created), nil
+}
+
+// forkPollInterval is how often a new fork is checked for whether GitHub has
+// finished copying the repository, and forkReadyTimeout how long it waits
+var (
+	forkPollInterval = 2 * time.Second
+	forkReadyTimeout = 2 * time.Minute
+)
+
+// ForkRepository forks the repository at fullPath into the organization
+// namespace, or the user's account, and waits until the fork's default
+// branch can be read. GitHub returns the existing fork when there is one.
+func (c *Client) ForkRepository(ctx context.Context, fullPath, namespace string) (*scm.Repository, error) {
+	ctx = requestContext(ctx)
+	owner, name, err := splitFullPath(&scm.Repository{FullPath: fullPath})
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &github.RepositoryCreateForkOptions{Organization: namespace}
+	fork, resp, err := c.client.Repositories.CreateFork(ctx, owner, name, opts)
+	var accepted *github.AcceptedError
+	if err != nil && !errors.As(err, &accepted) {
+		return nil, fmt.Errorf("failed to fork %s: %w", fullPath, apiError(resp, err))
+	}
+
+	if branch := fork.GetDefaultBranch(); branch != "" {
+		deadline := time.Now().Add(forkReadyTimeout)
+		forkOwner := fork.GetOwner().GetLogin()
+		for {
+			_, resp, err := c.client.Repositories.GetBranch(ctx, forkOwner, fork.GetName(), branch, 0)
+			if err == nil {
+				break
+			}
+			if resp == nil || resp.StatusCode != http.StatusNotFound || time.Now().After(deadline) {
+				return nil, fmt.Errorf("failed to wait for fork of %s: %w", fullPath, apiError(resp, err))
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(forkPollInterval):
+			}
+		}
+	}
+
+	repo := toRepository(fork)
+	repo.Fork = true
+	if repo.ParentFullPath == "" {
+		// The parent is on the same host, so its URLs only differ by path
+		repo.ParentFullPath = fullPath
+		repo.ParentCloneURL = strings.Replace(repo.CloneURL, repo.FullPath, fullPath, 1)
+		repo.ParentSSHCloneURL = strings.Replace(repo.SSHCloneURL, repo.FullPath, fullPath, 1)
+	}
+	return repo, nil
+}
+
+func (c *Client) SetArchived(ctx context.Context, repo *scm.Repository, archived bool) error {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return err
+	}
+	_, resp, err := c.client.Repositories.Edit(requestContext(ctx), owner, name, &github.Repository{Archived: github.Bool(archived)})
+	if err != nil {
+		return fmt.Errorf("failed to change archived state of %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	return nil
+}
+
+func (c *Client) CreateChangeRequest(ctx context.Context, repo *scm.Repository, request scm.ChangeRequest) (string, error) {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return "", err
+	}
+
+	pr, resp, err := c.client.PullRequests.Create(requestContext(ctx), owner, name, &github.NewPullRequest{
+		Title: github.String(request.Title),
+		Body:  github.String(request.Description),
+		Head:  github.String(request.SourceBranch),
+		Base:  github.String(request.TargetBranch),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", apiError(resp, err))
+	}
+	return pr.GetHTMLURL(), nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context, repo *scm.Repository) ([]scm.Webhook, error) {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhooks []scm.Webhook
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		hooks, resp, err := c.client.Repositories.ListHooks(requestContext(ctx), owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks: %w", apiError(resp, err))
+		}
+		for _, hook := range hooks {
+			webhooks = append(webhooks, scm.Webhook{ID: strconv.FormatInt(hook.GetID(), 10), URL: hook.GetConfig().GetURL()})
+		}
+		if resp.NextPage == 0 {
+			return webhooks, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, repo *scm.Repository, url, secret string) (scm.Webhook, error) {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return scm.Webhook{}, err
+	}
+
+	config := &github.HookConfig{URL: github.String(url), ContentType: github.String("json")}
+	if secret != "" {
+		config.Secret = github.String(secret)
+	}
+	hook, resp, err := c.client.Repositories.CreateHook(requestContext(ctx), owner, name, &github.Hook{
+		Config: config,
+		Events: []string{"push"},
+		Active: github.Bool(true),
+	})
+	if err != nil {
+		return scm.Webhook{}, fmt.Errorf("failed to create webhook: %w", apiError(resp, err))
+	}
+	return scm.Webhook{ID: strconv.FormatInt(hook.GetID(), 10), URL: url}, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, repo *scm.Repository, id string) error {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return err
+	}
+	hookID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID %q", id)
+	}
+	if resp, err := c.client.Repositories.DeleteHook(requestContext(ctx), owner, name, hookID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", apiError(resp, err))
+	}
+	return nil
+}
+
+// ListMembers returns the collaborators of the repository, including
+// organization members with access through a team or base permission
+func (c *Client) ListMembers(ctx context.Context, repo *scm.Repository) ([]scm.Member, error) {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	collaborators, err := c.listCollaborators(ctx, owner, name, "all")
+	if err != nil {
+		return nil, err
+	}
+	// Only repositories owned by an organization have outside
+	// collaborators; GitHub refuses the filter for the others
+	outside, err := c.listCollaborators(ctx, owner, name, "outside")
+	var apiErr *scm.APIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnprocessableEntity) {
+		return nil, err
+	}
+	external := make(map[string]bool, len(outside))
+	for _, user := range outside {
+		external[user.GetLogin()] = true
+	}
+
+	members := make([]scm.Member, 0, len(collaborators))
+	for _, user := range collaborators {
+		members = append(members, scm.Member{
+			Username:   user.GetLogin(),
+			Permission: collaboratorPermission(user),
+			External:   external[user.GetLogin()],
+		})
+	}
+	return members, nil
+}
+
+func (c *Client) listCollaborators(ctx context.Context, owner, name, affiliation string) ([]*github.User, error) {
+	var users []*github.User
+	opts := &github.ListCollaboratorsOptions{Affiliation: affiliation, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := c.client.Repositories.ListCollaborators(requestContext(ctx), owner, name, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list collaborators: %w", apiError(resp, err))
+		}
+		users = append(users, page...)
+		if resp.NextPage == 0 {
+			return users, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// collaboratorPermission maps the role of a collaborator to scm.Permissions.
+// Custom roles are mapped to the highest permission they include.
+func collaboratorPermission(user *github.User) string {
+	switch role := user.GetRoleName(); role {
+	case "read", "triage", "write", "maintain", "admin":
+		return role
+	}
+	permissions := user.GetPermissions()
+	for _, level := range []struct{ key, permission string }{
+		{"admin", "admin"}, {"maintain", "maintain"}, {"push", "write"}, {"triage", "triage"},
+	} {
+		if permissions[level.key] {
+			return level.permission
+		}
+	}
+	return "read"
+}
+
+// ListAssignedIssues returns the open issues assigned to the user in every
+// repository they can see. Pull requests are left out.
+func (c *Client) ListAssignedIssues(ctx context.Context, filter scm.IssueFilter) ([]scm.Issue, error) {
+	opts := &github.IssueListOptions{
+		Filter:      "assigned",
+		State:       "open",
+		Labels:      filter.Labels,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var issues []scm.Issue
+	for {
+		page, resp, err := c.client.Issues.List(requestContext(ctx), true, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", apiError(resp, err))
+		}
+		for _, issue := range page {
+			if !issue.IsPullRequest() {
+				issues = append(issues, toIssue(issue))
+			}
+		}
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func toIssue(issue *github.Issue) scm.Issue {
+	result := scm.Issue{
+		Number:     issue.GetNumber(),
+		Title:      issue.GetTitle(),
+		Repository: issue.GetRepository().GetFullName(),
+		Author:     issue.GetUser().GetLogin(),
+		WebURL:     issue.GetHTMLURL(),
+		UpdatedAt:  issue.GetUpdatedAt().Time,
+		Provider:   "github",
+	}
+	for _, label := range issue.Labels {
+		result.Labels = append(result.Labels, label.GetName())
+	}
+	return result
+}
+
+// LatestPipeline returns the combined status of the workflow runs for the
+// latest commit of the repository's default branch that ran any
+func (c *Client) LatestPipeline(ctx context.Context, repo *scm.Repository) (*scm.Pipeline, error) {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, resp, err := c.client.Actions.ListRepositoryWorkflowRuns(requestContext(ctx), owner, name, &github.ListWorkflowRunsOptions{
+		Branch:              repo.DefaultBranch,
+		ExcludePullRequests: true,
+		ListOptions:         github.ListOptions{PerPage: 30},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow runs: %w", apiError(resp, err))
+	}
+	if len(runs.WorkflowRuns) == 0 {
+		return nil, nil
+	}
+
+	// Runs come newest first; each workflow of the commit has its own
+	latest := runs.WorkflowRuns[0]
+	pipeline := &scm.Pipeline{WebURL: latest.GetHTMLURL(), UpdatedAt: latest.GetUpdatedAt().Time}
+	seen := make(map[int64]bool)
+	for _, run := range runs.WorkflowRuns {
+		if run.GetHeadSHA() != latest.GetHeadSHA() || seen[run.GetWorkflowID()] {
+			continue
+		}
+		seen[run.GetWorkflowID()] = true
+		status := workflowRunStatus(run)
+		if pipelineRank[status] > pipelineRank[pipeline.Status] {
+			pipeline.Status, pipeline.WebURL = status, run.GetHTMLURL()
+		}
+	}
+	return pipeline, nil
+}
+
+// pipelineRank orders the scm.Pipeline states by how much they matter when
+// combining the workflow runs of a commit: one failure fails them all
+var pipelineRank = map[string]int{
+	scm.PipelineCanceled: 1,
+	scm.PipelineSuccess:  2,
+	scm.PipelineRunning:  3,
+	scm.PipelineFailed:   4,
+}
+
+// workflowRunStatus maps the status and conclusion of a workflow run to the
+// scm.Pipeline states
+func workflowRunStatus(run *github.WorkflowRun) string {
+	if run.GetStatus() != "completed" {
+		return scm.PipelineRunning
+	}
+	switch run.GetConclusion() {
+	case "success", "neutral":
+		return scm.PipelineSuccess
+	case "cancelled", "skipped", "stale":
+		return scm.PipelineCanceled
+	case "action_required":
+		return scm.PipelineRunning
+	default:
+		return scm.PipelineFailed
+	}
+}
+
+func (c *Client) FetchReadme(ctx context.Context, repo *scm.Repository) (string, error) {
+	owner, name, err := splitFullPath(repo)
+	if err != nil {
+		return "", err
+	}
+
+	readme, resp, err := c.client.Repositories.GetReadme(requestContext(ctx), owner, name, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get README: %w", apiError(resp, err))
+	}
+	content, err := readme.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode README: %w", err)
+	}
+	return content, nil
+}
+
+// apiError keeps the HTTP status of a failed request with its error, so
+// callers can tell what went wrong
+func apiError(resp *github.Response, err error) error {
+	if resp == nil || resp.Response == nil {
+		return err
+	}
+	return &scm.APIError{StatusCode: resp.StatusCode, Err: err}
+}
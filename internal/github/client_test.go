@@ -1,11 +1,18 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
 	"gitstuff/internal/scm"
+
+	"github.com/google/go-github/v67/github"
 )
 
 func TestNewClient(t *testing.T) {
@@ -142,7 +149,7 @@ func TestClient_ListAllRepositories_MockResponse(t *testing.T) {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	repos, err := client.ListAllRepositories()
+	repos, err := client.ListAllRepositories(context.Background())
 	if err != nil {
 		t.Fatalf("ListAllRepositories() error = %v", err)
 	}
@@ -195,7 +202,7 @@ func TestClient_ListRepositoriesInGroup_MockResponse(t *testing.T) {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	repos, err := client.ListRepositoriesInGroup("testorg")
+	repos, err := client.ListRepositoriesInGroup(context.Background(), "testorg")
 	if err != nil {
 		t.Fatalf("ListRepositoriesInGroup() error = %v", err)
 	}
@@ -260,7 +267,7 @@ func TestClient_BuildRepositoryTree(t *testing.T) {
 		t.Fatalf("Failed to create client: %v", err)
 	}
 
-	tree, err := client.BuildRepositoryTree()
+	tree, err := client.BuildRepositoryTree(context.Background())
 	if err != nil {
 		t.Fatalf("BuildRepositoryTree() error = %v", err)
 	}
@@ -352,3 +359,707 @@ func TestBuildTreeFromRepos(t *testing.T) {
 		t.Errorf("Expected 0 root repositories, got %d", len(tree.Repositories))
 	}
 }
+
+func TestClient_CheckHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/user" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "4321")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"login": "octocat", "id": 1}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	health, err := client.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+
+	if health.User != "octocat" {
+		t.Errorf("Expected user 'octocat', got '%s'", health.User)
+	}
+	if health.RateLimitLimit != 5000 {
+		t.Errorf("Expected rate limit 5000, got %d", health.RateLimitLimit)
+	}
+	if health.RateLimitRemaining != 4321 {
+		t.Errorf("Expected 4321 remaining, got %d", health.RateLimitRemaining)
+	}
+	if health.RateLimitReset.Unix() != 1700000000 {
+		t.Errorf("Expected reset at 1700000000, got %d", health.RateLimitReset.Unix())
+	}
+}
+
+func TestClient_CheckHealth_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "bad-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.CheckHealth(context.Background())
+	var apiErr *scm.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected APIError with status 401, got %v", err)
+	}
+}
+
+func TestClient_ListAllRepositories_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "insufficient scope"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ListAllRepositories(context.Background())
+	if kind := scm.ClassifyError(err); kind != scm.ErrorForbidden {
+		t.Errorf("Expected a forbidden error, got kind %v from %v", kind, err)
+	}
+}
+
+func TestClient_CheckHealth_Scopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "classic token", header: "repo, read:org", want: []string{"repo", "read:org"}},
+		{name: "no scopes", header: "", want: []string{}},
+		{name: "fine-grained token", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if tt.want != nil {
+					w.Header().Set("X-OAuth-Scopes", tt.header)
+				}
+				_, _ = w.Write([]byte(`{"login": "octocat", "id": 1}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+			health, err := client.CheckHealth(context.Background())
+			if err != nil {
+				t.Fatalf("CheckHealth() error = %v", err)
+			}
+			if !reflect.DeepEqual(health.Scopes, tt.want) {
+				t.Errorf("Scopes = %#v, want %#v", health.Scopes, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ListAllRepositories_Archived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "name": "active", "full_name": "org/active", "archived": false, "permissions": {"pull": true}},
+			{"id": 2, "name": "retired", "full_name": "org/retired", "archived": true, "permissions": {"pull": true}}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(repos))
+	}
+	if repos[0].Archived || !repos[1].Archived {
+		t.Errorf("Expected only org/retired to be archived, got %v and %v", repos[0].Archived, repos[1].Archived)
+	}
+}
+
+func TestClient_ListAllRepositories_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "full_name": "org/api", "language": "Go", "size": 2048, "stargazers_count": 42,
+			 "pushed_at": "2026-10-01T12:00:00Z", "updated_at": "2026-10-10T12:00:00Z", "permissions": {"pull": true}},
+			{"id": 2, "full_name": "org/empty", "updated_at": "2026-09-01T12:00:00Z", "permissions": {"pull": true}}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories() error = %v", err)
+	}
+	api := repos[0]
+	if api.Language != "Go" || api.Size != 2048*1024 || api.Stars != 42 {
+		t.Errorf("Unexpected metadata: language %q, size %d, stars %d", api.Language, api.Size, api.Stars)
+	}
+	if want := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC); !api.LastActivity.Equal(want) {
+		t.Errorf("Expected the last push as last activity, got %v", api.LastActivity)
+	}
+	// Without pushes, the last update is the last activity
+	if want := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC); !repos[1].LastActivity.Equal(want) {
+		t.Errorf("Expected the last update as last activity, got %v", repos[1].LastActivity)
+	}
+}
+
+func TestClient_ListAllRepositories_ForkParents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/user/repos":
+			_, _ = w.Write([]byte(`[
+				{"id": 1, "name": "tool", "full_name": "alice/tool", "fork": true, "permissions": {"pull": true}},
+				{"id": 2, "name": "own", "full_name": "alice/own", "permissions": {"pull": true}}
+			]`))
+		case "/api/v3/repos/alice/tool":
+			_, _ = w.Write([]byte(`{"id": 1, "full_name": "alice/tool", "fork": true,
+				"parent": {"full_name": "upstream/tool", "clone_url": "https://github.com/upstream/tool.git", "ssh_url": "git@github.com:upstream/tool.git"}}`))
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(repos))
+	}
+
+	own, fork := repos[0], repos[1]
+	if own.Fork || own.ParentFullPath != "" {
+		t.Errorf("Expected alice/own not to be a fork, got %+v", own)
+	}
+	if !fork.Fork || fork.ParentFullPath != "upstream/tool" || fork.ParentSSHCloneURL != "git@github.com:upstream/tool.git" {
+		t.Errorf("Expected parent upstream/tool for alice/tool, got %+v", fork)
+	}
+}
+
+func TestClient_CreateChangeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/repos/octo/tool/pulls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if body["head"] != "bump-deps" || body["base"] != "main" || body["body"] != "Details" {
+			t.Errorf("Unexpected pull request: %v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number": 3, "html_url": "https://github.com/octo/tool/pull/3"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	url, err := client.CreateChangeRequest(context.Background(), &scm.Repository{FullPath: "octo/tool"}, scm.ChangeRequest{
+		SourceBranch: "bump-deps",
+		TargetBranch: "main",
+		Title:        "Bump dependencies",
+		Description:  "Details",
+	})
+	if err != nil {
+		t.Fatalf("CreateChangeRequest failed: %v", err)
+	}
+	if url != "https://github.com/octo/tool/pull/3" {
+		t.Errorf("Unexpected URL: %s", url)
+	}
+}
+
+func TestClient_Webhooks(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/octo/tool/hooks":
+			_, _ = w.Write([]byte(`[{"id": 3, "url": "https://api.github.com/repos/octo/tool/hooks/3", "config": {"url": "https://mirror.example.com/hook"}}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/octo/tool/hooks":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 4}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v3/repos/octo/tool/hooks/3":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	repo := &scm.Repository{FullPath: "octo/tool"}
+
+	hooks, err := client.ListWebhooks(context.Background(), repo)
+	if err != nil || len(hooks) != 1 || hooks[0].ID != "3" || hooks[0].URL != "https://mirror.example.com/hook" {
+		t.Errorf("ListWebhooks() = %+v, %v", hooks, err)
+	}
+
+	hook, err := client.CreateWebhook(context.Background(), repo, "https://ci.example.com/hook", "s3cret")
+	if err != nil || hook.ID != "4" {
+		t.Errorf("CreateWebhook() = %+v, %v", hook, err)
+	}
+	config, _ := created["config"].(map[string]interface{})
+	if config["url"] != "https://ci.example.com/hook" || config["secret"] != "s3cret" {
+		t.Errorf("Unexpected webhook request: %v", created)
+	}
+
+	if err := client.DeleteWebhook(context.Background(), repo, "3"); err != nil {
+		t.Errorf("DeleteWebhook failed: %v", err)
+	}
+}
+
+func TestClient_ListAllRepositories_SecondaryRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "tool", "full_name": "org/tool", "permissions": {"pull": true}}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories() error = %v", err)
+	}
+	if len(repos) != 1 || requests != 2 {
+		t.Errorf("Expected 1 repository after one retry, got %d after %d requests", len(repos), requests)
+	}
+}
+
+func TestClient_FetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v3/repos/octo/tool/readme" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		// "# Tool\n\nDoes things." in base64
+		_, _ = w.Write([]byte(`{"type": "file", "encoding": "base64", "content": "IyBUb29sCgpEb2VzIHRoaW5ncy4="}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	readme, err := client.FetchReadme(context.Background(), &scm.Repository{FullPath: "octo/tool"})
+	if err != nil || readme != "# Tool\n\nDoes things." {
+		t.Errorf("FetchReadme() = %q, %v", readme, err)
+	}
+	readme, err = client.FetchReadme(context.Background(), &scm.Repository{FullPath: "octo/empty"})
+	if err != nil || readme != "" {
+		t.Errorf("Expected no README, got %q, %v", readme, err)
+	}
+}
+
+func TestClient_ServerVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/meta" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-GitHub-Enterprise-Version", "3.12.4")
+		_, _ = w.Write([]byte(`{"verifiable_password_authentication": false}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	version, err := client.ServerVersion(context.Background())
+	if err != nil || version != "3.12.4" {
+		t.Errorf("ServerVersion() = %q, %v; want 3.12.4", version, err)
+	}
+
+	dotcom, err := NewClient("https://github.com", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if version, err := dotcom.ServerVersion(context.Background()); version != "" || err != nil {
+		t.Errorf("Expected no version for github.com, got %q, %v", version, err)
+	}
+}
+
+func TestSearchRepositories(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/search/repositories" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.Query().Get("q")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"total_count": 1, "items": [
+			{"id": 9, "name": "billing", "full_name": "acme/billing", "description": "Invoices and payments"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.SearchRepositories(context.Background(), "billing", 20)
+	if err != nil {
+		t.Fatalf("SearchRepositories() error = %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullPath != "acme/billing" || repos[0].Description != "Invoices and payments" {
+		t.Errorf("Unexpected repositories: %+v", repos)
+	}
+	if query != "billing in:name,description" {
+		t.Errorf("Unexpected search query %q", query)
+	}
+}
+
+func TestClient_CreateRepository(t *testing.T) {
+	created := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/user":
+			_, _ = w.Write([]byte(`{"login": "Octocat"}`))
+		case r.Method == http.MethodPost && (r.URL.Path == "/api/v3/orgs/octo/repos" || r.URL.Path == "/api/v3/user/repos"):
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			created[r.URL.Path] = body
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 9, "name": "tool", "full_name": "octo/tool", "ssh_url": "git@github.com:octo/tool.git"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repo, err := client.CreateRepository(context.Background(), scm.NewRepository{FullPath: "octo/tool", Description: "Tooling", Visibility: "public"})
+	if err != nil || repo.ID != "9" || repo.SSHCloneURL != "git@github.com:octo/tool.git" {
+		t.Fatalf("CreateRepository() = %+v, %v", repo, err)
+	}
+	if body := created["/api/v3/orgs/octo/repos"]; body["name"] != "tool" || body["private"] != false || body["visibility"] != "public" || body["description"] != "Tooling" {
+		t.Errorf("Unexpected organization repository request: %v", body)
+	}
+
+	if _, err := client.CreateRepository(context.Background(), scm.NewRepository{FullPath: "octocat/notes", Visibility: "private"}); err != nil {
+		t.Fatalf("CreateRepository() for the user failed: %v", err)
+	}
+	if body := created["/api/v3/user/repos"]; body["name"] != "notes" || body["private"] != true {
+		t.Errorf("Unexpected user repository request: %v", body)
+	}
+
+	if _, err := client.CreateRepository(context.Background(), scm.NewRepository{FullPath: "octo/team/tool"}); err == nil {
+		t.Error("Expected an error for a nested path")
+	}
+}
+
+func TestClient_ForkRepository(t *testing.T) {
+	oldInterval := forkPollInterval
+	forkPollInterval = time.Millisecond
+	defer func() { forkPollInterval = oldInterval }()
+
+	var requested map[string]interface{}
+	branchChecks := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/repos/upstream/tool/forks":
+			if err := json.NewDecoder(r.Body).Decode(&requested); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"id": 9, "name": "tool", "full_name": "octo/tool", "default_branch": "main", "owner": {"login": "octo"},
+				"clone_url": "https://github.com/octo/tool.git", "ssh_url": "git@github.com:octo/tool.git"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/octo/tool/branches/main":
+			if branchChecks++; branchChecks == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"name": "main"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	fork, err := client.ForkRepository(context.Background(), "upstream/tool", "octo")
+	if err != nil {
+		t.Fatalf("ForkRepository failed: %v", err)
+	}
+	if requested["organization"] != "octo" {
+		t.Errorf("Expected the fork to go to octo, got %v", requested)
+	}
+	if fork.FullPath != "octo/tool" || fork.ParentFullPath != "upstream/tool" || fork.ParentSSHCloneURL != "git@github.com:upstream/tool.git" || branchChecks != 2 {
+		t.Errorf("Unexpected fork %+v after %d branch checks", fork, branchChecks)
+	}
+}
+
+func TestClient_SetArchived(t *testing.T) {
+	var edits []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPatch || r.URL.Path != "/api/v3/repos/octo/tool" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		edits = append(edits, body["archived"])
+		_, _ = w.Write([]byte(`{"id": 9}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	repo := &scm.Repository{FullPath: "octo/tool"}
+	if err := client.SetArchived(context.Background(), repo, true); err != nil {
+		t.Fatalf("SetArchived(true) failed: %v", err)
+	}
+	if err := client.SetArchived(context.Background(), repo, false); err != nil {
+		t.Fatalf("SetArchived(false) failed: %v", err)
+	}
+	if !reflect.DeepEqual(edits, []interface{}{true, false}) {
+		t.Errorf("Unexpected edits %v", edits)
+	}
+}
+
+func TestToRepository_Visibility(t *testing.T) {
+	tests := []struct {
+		name string
+		repo *github.Repository
+		want string
+	}{
+		{"reported", &github.Repository{Visibility: github.String("internal"), Private: github.Bool(true)}, "internal"},
+		{"private only", &github.Repository{Private: github.Bool(true)}, "private"},
+		{"public only", &github.Repository{Private: github.Bool(false)}, "public"},
+		{"unknown", &github.Repository{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toRepository(tt.repo).Visibility; got != tt.want {
+				t.Errorf("Visibility = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ListMembers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v3/repos/octo/tool/collaborators" && r.URL.Query().Get("affiliation") == "all":
+			_, _ = w.Write([]byte(`[
+				{"login": "owner", "role_name": "admin"},
+				{"login": "dev", "role_name": "write"},
+				{"login": "contractor", "role_name": "release-manager", "permissions": {"pull": true, "triage": true, "push": true}}
+			]`))
+		case r.URL.Path == "/api/v3/repos/octo/tool/collaborators" && r.URL.Query().Get("affiliation") == "outside":
+			_, _ = w.Write([]byte(`[{"login": "contractor"}]`))
+		case r.URL.Path == "/api/v3/repos/alice/notes/collaborators" && r.URL.Query().Get("affiliation") == "all":
+			_, _ = w.Write([]byte(`[{"login": "alice", "role_name": "admin"}]`))
+		case r.URL.Path == "/api/v3/repos/alice/notes/collaborators":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	members, err := client.ListMembers(context.Background(), &scm.Repository{FullPath: "octo/tool"})
+	if err != nil {
+		t.Fatalf("ListMembers() error = %v", err)
+	}
+	want := []scm.Member{
+		{Username: "owner", Permission: "admin"},
+		{Username: "dev", Permission: "write"},
+		{Username: "contractor", Permission: "write", External: true},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("ListMembers() = %+v, want %+v", members, want)
+	}
+
+	// Repositories of users cannot be filtered by outside collaborators
+	members, err = client.ListMembers(context.Background(), &scm.Repository{FullPath: "alice/notes"})
+	if err != nil || len(members) != 1 || members[0] != (scm.Member{Username: "alice", Permission: "admin"}) {
+		t.Errorf("ListMembers() = %+v, %v", members, err)
+	}
+}
+
+func TestClient_ListAssignedIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v3/issues" || query.Get("filter") != "assigned" || query.Get("state") != "open" || query.Get("labels") != "bug" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"number": 12, "title": "Login times out", "html_url": "https://github.com/octo/tool/issues/12",
+			 "updated_at": "2026-10-01T10:00:00Z", "user": {"login": "reporter"},
+			 "labels": [{"name": "bug"}, {"name": "p1"}], "repository": {"full_name": "octo/tool"}},
+			{"number": 13, "title": "Fix login", "pull_request": {"url": "https://api.github.com/repos/octo/tool/pulls/13"},
+			 "repository": {"full_name": "octo/tool"}}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	issues, err := client.ListAssignedIssues(context.Background(), scm.IssueFilter{Labels: []string{"bug"}})
+	if err != nil {
+		t.Fatalf("ListAssignedIssues() error = %v", err)
+	}
+	want := []scm.Issue{{
+		Number:     12,
+		Title:      "Login times out",
+		Repository: "octo/tool",
+		Labels:     []string{"bug", "p1"},
+		Author:     "reporter",
+		WebURL:     "https://github.com/octo/tool/issues/12",
+		UpdatedAt:  time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		Provider:   "github",
+	}}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("ListAssignedIssues() = %+v, want %+v", issues, want)
+	}
+}
+
+func TestClient_LatestPipeline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("branch") != "main" {
+			t.Errorf("Expected the runs of the default branch, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/octo/tool/actions/runs":
+			// Newest first: lint passed on the latest commit but tests failed,
+			// and an older run of the same workflow does not count
+			_, _ = w.Write([]byte(`{"total_count": 4, "workflow_runs": [
+				{"workflow_id": 1, "head_sha": "bbb", "status": "completed", "conclusion": "success",
+				 "html_url": "https://github.com/octo/tool/actions/runs/4", "updated_at": "2026-10-01T10:00:00Z"},
+				{"workflow_id": 2, "head_sha": "bbb", "status": "completed", "conclusion": "failure",
+				 "html_url": "https://github.com/octo/tool/actions/runs/3"},
+				{"workflow_id": 2, "head_sha": "bbb", "status": "completed", "conclusion": "success",
+				 "html_url": "https://github.com/octo/tool/actions/runs/2"},
+				{"workflow_id": 3, "head_sha": "aaa", "status": "in_progress",
+				 "html_url": "https://github.com/octo/tool/actions/runs/1"}
+			]}`))
+		case "/api/v3/repos/octo/docs/actions/runs":
+			_, _ = w.Write([]byte(`{"total_count": 0, "workflow_runs": []}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL+"/api/v3", "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	pipeline, err := client.LatestPipeline(context.Background(), &scm.Repository{FullPath: "octo/tool", DefaultBranch: "main"})
+	if err != nil {
+		t.Fatalf("LatestPipeline() error = %v", err)
+	}
+	want := &scm.Pipeline{
+		Status:    scm.PipelineFailed,
+		WebURL:    "https://github.com/octo/tool/actions/runs/3",
+		UpdatedAt: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("LatestPipeline() = %+v, want %+v", pipeline, want)
+	}
+
+	pipeline, err = client.LatestPipeline(context.Background(), &scm.Repository{FullPath: "octo/docs", DefaultBranch: "main"})
+	if err != nil || pipeline != nil {
+		t.Errorf("Expected no pipeline, got %+v, %v", pipeline, err)
+	}
+}
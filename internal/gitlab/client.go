@@ -1,44 +1,45 @@
 package gitlab
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/xanzy/go-gitlab"
 
+	"gitstuff/internal/httpclient"
 	"gitstuff/internal/scm"
 )
 
 type Client struct {
 	client *gitlab.Client
+
+	// groupMembers caches the usernames of the members of top-level
+	// groups, which decide who counts as external
+	groupMembers sync.Map
 }
 
 func NewClient(baseURL, token string, insecure bool) (*Client, error) {
+	return NewClientWithOptions(baseURL, token, httpclient.Options{Insecure: insecure})
+}
+
+func NewClientWithOptions(baseURL, token string, opts httpclient.Options) (*Client, error) {
 	normalizedURL, err := normalizeURL(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid GitLab URL: %w", err)
 	}
 
-	var options []gitlab.ClientOptionFunc
-	options = append(options, gitlab.WithBaseURL(normalizedURL))
-
-	if insecure {
-		httpClient := &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-		}
-		options = append(options, gitlab.WithHTTPClient(httpClient))
-	}
-
-	client, err := gitlab.NewClient(token, options...)
+	client, err := gitlab.NewClient(token,
+		gitlab.WithBaseURL(normalizedURL),
+		gitlab.WithHTTPClient(httpclient.New(opts)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gitlab client: %w", err)
 	}
@@ -71,15 +72,48 @@ func (c *Client) GetProviderType() string {
 	return "gitlab"
 }
 
-func (c *Client) ListAllRepositories() ([]*scm.Repository, error) {
-	return c.ListRepositoriesInGroup("")
+func (c *Client) CheckHealth(ctx context.Context) (*scm.ProviderHealth, error) {
+	user, resp, err := c.client.Users.CurrentUser(gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", apiError(resp, err))
+	}
+
+	health := &scm.ProviderHealth{User: user.Username}
+	if resp != nil {
+		health.RateLimitLimit, _ = strconv.Atoi(resp.Header.Get("RateLimit-Limit"))
+		health.RateLimitRemaining, _ = strconv.Atoi(resp.Header.Get("RateLimit-Remaining"))
+		if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+			health.RateLimitReset = time.Unix(reset, 0)
+		}
+	}
+
+	// Only personal, project and group access tokens can describe themselves
+	// (GitLab 15.5 and later); other tokens simply report no scopes
+	if token, _, err := c.client.PersonalAccessTokens.GetSinglePersonalAccessToken(gitlab.WithContext(ctx)); err == nil {
+		health.Scopes = append([]string{}, token.Scopes...)
+	}
+
+	return health, nil
+}
+
+// ServerVersion returns the version of the GitLab instance
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	version, resp, err := c.client.Version.GetVersion(gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get version: %w", apiError(resp, err))
+	}
+	return version.Version, nil
 }
 
-func (c *Client) ListRepositoriesInGroup(groupPath string) ([]*scm.Repository, error) {
+func (c *Client) ListAllRepositories(ctx context.Context) ([]*scm.Repository, error) {
+	return c.ListRepositoriesInGroup(ctx, "")
+}
+
+func (c *Client) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*scm.Repository, error) {
 	var allRepos []*scm.Repository
 
 	if groupPath != "" {
-		return c.listRepositoriesInSpecificGroup(groupPath)
+		return c.listRepositoriesInSpecificGroup(ctx, groupPath)
 	}
 
 	opts := &gitlab.ListProjectsOptions{
@@ -89,28 +123,19 @@ func (c *Client) ListRepositoriesInGroup(groupPath string) ([]*scm.Repository, e
 		},
 		Membership: gitlab.Bool(true),
 		Simple:     gitlab.Bool(false),
+		Statistics: gitlab.Bool(true),
 		OrderBy:    gitlab.String("path"),
 		Sort:       gitlab.String("asc"),
 	}
 
 	for {
-		projects, resp, err := c.client.Projects.ListProjects(opts)
+		projects, resp, err := c.client.Projects.ListProjects(opts, gitlab.WithContext(ctx))
 		if err != nil {
-			return nil, fmt.Errorf("failed to list projects: %w", err)
+			return nil, fmt.Errorf("failed to list projects: %w", apiError(resp, err))
 		}
 
 		for _, project := range projects {
-			repo := &scm.Repository{
-				ID:            strconv.Itoa(project.ID),
-				Name:          project.Name,
-				FullPath:      project.PathWithNamespace,
-				CloneURL:      project.HTTPURLToRepo,
-				SSHCloneURL:   project.SSHURLToRepo,
-				DefaultBranch: project.DefaultBranch,
-				WebURL:        project.WebURL,
-				Provider:      "gitlab",
-			}
-			allRepos = append(allRepos, repo)
+			allRepos = append(allRepos, toRepository(project))
 		}
 
 		if resp.NextPage == 0 {
@@ -126,13 +151,17 @@ func (c *Client) ListRepositoriesInGroup(groupPath string) ([]*scm.Repository, e
 	return allRepos, nil
 }
 
-func (c *Client) GetRepository(fullPath string) (*scm.Repository, error) {
-	project, _, err := c.client.Projects.GetProject(fullPath, nil)
+func (c *Client) GetRepository(ctx context.Context, fullPath string) (*scm.Repository, error) {
+	project, resp, err := c.client.Projects.GetProject(fullPath, nil, gitlab.WithContext(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get project %s: %w", fullPath, err)
+		return nil, fmt.Errorf("failed to get project %s: %w", fullPath, apiError(resp, err))
 	}
 
-	return &scm.Repository{
+	return toRepository(project), nil
+}
+
+func toRepository(project *gitlab.Project) *scm.Repository {
+	repo := &scm.Repository{
 		ID:            strconv.Itoa(project.ID),
 		Name:          project.Name,
 		FullPath:      project.PathWithNamespace,
@@ -141,10 +170,38 @@ func (c *Client) GetRepository(fullPath string) (*scm.Repository, error) {
 		DefaultBranch: project.DefaultBranch,
 		WebURL:        project.WebURL,
 		Provider:      "gitlab",
-	}, nil
+		Archived:      project.Archived,
+		Description:   project.Description,
+		Visibility:    string(project.Visibility),
+		Topics:        project.Topics,
+		Stars:         project.StarCount,
+	}
+	if project.LastActivityAt != nil {
+		repo.LastActivity = *project.LastActivityAt
+	}
+	if project.Statistics != nil {
+		repo.Size = project.Statistics.RepositorySize
+	}
+	if len(repo.Topics) == 0 {
+		// Instances older than GitLab 14.0 only have tags
+		repo.Topics = project.TagList
+	}
+
+	if parent := project.ForkedFromProject; parent != nil {
+		repo.Fork = true
+		repo.ParentFullPath = parent.PathWithNamespace
+		repo.ParentCloneURL = parent.HTTPURLToRepo
+		// Parents live on the same instance, so their SSH URL only differs
+		// from the fork's by path
+		if suffix := project.PathWithNamespace + ".git"; strings.HasSuffix(project.SSHURLToRepo, suffix) {
+			repo.ParentSSHCloneURL = strings.TrimSuffix(project.SSHURLToRepo, suffix) + parent.PathWithNamespace + ".git"
+		}
+	}
+
+	return repo
 }
 
-func (c *Client) ListGroups() ([]*scm.Group, error) {
+func (c *Client) ListGroups(ctx context.Context) ([]*scm.Group, error) {
 	var allGroups []*scm.Group
 
 	opts := &gitlab.ListGroupsOptions{
@@ -158,9 +215,9 @@ func (c *Client) ListGroups() ([]*scm.Group, error) {
 	}
 
 	for {
-		groups, resp, err := c.client.Groups.ListGroups(opts)
+		groups, resp, err := c.client.Groups.ListGroups(opts, gitlab.WithContext(ctx))
 		if err != nil {
-			return nil, fmt.Errorf("failed to list groups: %w", err)
+			return nil, fmt.Errorf("failed to list groups: %w", apiError(resp, err))
 		}
 
 		for _, group := range groups {
@@ -184,8 +241,8 @@ func (c *Client) ListGroups() ([]*scm.Group, error) {
 
 // Note: These types are now defined in scm package but kept here for BuildRepositoryTree compatibility
 
-func (c *Client) BuildRepositoryTree() (*scm.RepositoryTree, error) {
-	repos, err := c.ListAllRepositories()
+func (c *Client) BuildRepositoryTree(ctx context.Context) (*scm.RepositoryTree, error) {
+	repos, err := c.ListAllRepositories(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -247,12 +304,12 @@ func (c *Client) BuildRepositoryTree() (*scm.RepositoryTree, error) {
 	return tree, nil
 }
 
-func (c *Client) listRepositoriesInSpecificGroup(groupPath string) ([]*scm.Repository, error) {
+func (c *Client) listRepositoriesInSpecificGroup(ctx context.Context, groupPath string) ([]*scm.Repository, error) {
 	var allRepos []*scm.Repository
 
-	group, _, err := c.client.Groups.GetGroup(groupPath, nil)
+	group, resp, err := c.client.Groups.GetGroup(groupPath, nil, gitlab.WithContext(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get group %s: %w", groupPath, err)
+		return nil, fmt.Errorf("failed to get group %s: %w", groupPath, apiError(resp, err))
 	}
 
 	opts := &gitlab.ListGroupProjectsOptions{
@@ -266,24 +323,14 @@ func (c *Client) listRepositoriesInSpecificGroup(groupPath string) ([]*scm.Repos
 	}
 
 	for {
-		projects, resp, err := c.client.Groups.ListGroupProjects(group.ID, opts)
+		projects, resp, err := c.client.Groups.ListGroupProjects(group.ID, opts, gitlab.WithContext(ctx), withStatistics)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list projects in group %s: %w", groupPath, err)
+			return nil, fmt.Errorf("failed to list projects in group %s: %w", groupPath, apiError(resp, err))
 		}
 
 		for _, project := range projects {
 			if strings.HasPrefix(project.PathWithNamespace, groupPath+"/") || project.PathWithNamespace == groupPath {
-				repo := &scm.Repository{
-					ID:            strconv.Itoa(project.ID),
-					Name:          project.Name,
-					FullPath:      project.PathWithNamespace,
-					CloneURL:      project.HTTPURLToRepo,
-					SSHCloneURL:   project.SSHURLToRepo,
-					DefaultBranch: project.DefaultBranch,
-					WebURL:        project.WebURL,
-					Provider:      "gitlab",
-				}
-				allRepos = append(allRepos, repo)
+				allRepos = append(allRepos, toRepository(project))
 			}
 		}
 
@@ -299,3 +346,445 @@ func (c *Client) listRepositoriesInSpecificGroup(groupPath string) ([]*scm.Repos
 
 	return allRepos, nil
 }
+
+// SearchRepositories returns the projects whose name, path or description
+// contains query, including their namespace, most recently active first
+func (c *Client) SearchRepositories(ctx context.Context, query string, limit int) ([]*scm.Repository, error) {
+	opts := &gitlab.ListProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: min(limit, 100),
+			Page:    1,
+		},
+		Search:           gitlab.String(query),
+		SearchNamespaces: gitlab.Bool(true),
+		OrderBy:          gitlab.String("last_activity_at"),
+	}
+
+	var repos []*scm.Repository
+	for len(repos) < limit {
+		projects, resp, err := c.client.Projects.ListProjects(opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search projects: %w", apiError(resp, err))
+		}
+		for _, project := range projects {
+			repos = append(repos, toRepository(project))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(repos) > limit {
+		repos = repos[:limit]
+	}
+	return repos, nil
+}
+
+// CreateRepository creates an empty project in the namespace of the full
+// path, or in the user's namespace when it has none
+func (c *Client) CreateRepository(ctx context.Context, repo scm.NewRepository) (*scm.Repository, error) {
+	namespace, name := "", repo.FullPath
+	if i := strings.LastIndex(repo.FullPath, "/"); i >= 0 {
+		namespace, name = repo.FullPath[:i], repo.FullPath[i+1:]
+	}
+	opts := &gitlab.CreateProjectOptions{
+		Name:       gitlab.String(name),
+		Path:       gitlab.String(name),
+		Visibility: gitlab.Visibility(gitlab.VisibilityValue(repo.Visibility)),
+	}
+	if repo.Description != "" {
+		opts.Description = gitlab.String(repo.Description)
+	}
+	if repo.DefaultBranch != "" {
+		opts.DefaultBranch = gitlab.String(repo.DefaultBranch)
+	}
+	if namespace != "" {
+		ns, resp, err := c.client.Namespaces.GetNamespace(namespace, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, apiError(resp, err))
+		}
+		opts.NamespaceID = gitlab.Int(ns.ID)
+	}
+
+	project, resp, err := c.client.Projects.CreateProject(opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	return toRepository(project), nil
+}
+
+// forkPollInterval is how often a new fork is checked for whether GitLab
+// has finished copying the repository
+var forkPollInterval = 2 * time.Second
+
+// ForkRepository forks the project at fullPath and waits for GitLab to copy
+// its repository. Forking into a namespace that already has the fork returns
+// the existing fork.
+func (c *Client) ForkRepository(ctx context.Context, fullPath, namespace string) (*scm.Repository, error) {
+	source, resp, err := c.client.Projects.GetProject(fullPath, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project %s: %w", fullPath, apiError(resp, err))
+	}
+
+	opts := &gitlab.ForkProjectOptions{}
+	if namespace != "" {
+		opts.NamespacePath = gitlab.String(namespace)
+	}
+	project, resp, err := c.client.Projects.ForkProject(source.ID, opts, gitlab.WithContext(ctx))
+	if err != nil && resp != nil && resp.StatusCode == http.StatusConflict {
+		project, err = c.existingFork(ctx, source, namespace)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork %s: %w", fullPath, apiError(resp, err))
+	}
+
+	for project.ImportStatus == "scheduled" || project.ImportStatus == "started" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(forkPollInterval):
+		}
+		if project, resp, err = c.client.Projects.GetProject(project.ID, nil, gitlab.WithContext(ctx)); err != nil {
+			return nil, fmt.Errorf("failed to get fork of %s: %w", fullPath, apiError(resp, err))
+		}
+	}
+	if project.ImportStatus == "failed" {
+		return nil, fmt.Errorf("GitLab failed to copy the repository of %s: %s", fullPath, project.ImportError)
+	}
+
+	repo := toRepository(project)
+	repo.Fork = true
+	repo.ParentFullPath = source.PathWithNamespace
+	repo.ParentCloneURL = source.HTTPURLToRepo
+	repo.ParentSSHCloneURL = source.SSHURLToRepo
+	return repo, nil
+}
+
+// existingFork returns the fork of source in namespace, or in the user's
+// namespace when it is empty
+func (c *Client) existingFork(ctx context.Context, source *gitlab.Project, namespace string) (*gitlab.Project, error) {
+	if namespace == "" {
+		user, _, err := c.client.Users.CurrentUser(gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		namespace = user.Username
+	}
+	project, _, err := c.client.Projects.GetProject(namespace+"/"+source.Path, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if project.ForkedFromProject == nil || project.ForkedFromProject.ID != source.ID {
+		return nil, fmt.Errorf("%s already exists and is not a fork of %s", project.PathWithNamespace, source.PathWithNamespace)
+	}
+	return project, nil
+}
+
+func (c *Client) SetArchived(ctx context.Context, repo *scm.Repository, archived bool) error {
+	var resp *gitlab.Response
+	var err error
+	if archived {
+		_, resp, err = c.client.Projects.ArchiveProject(repo.ID, gitlab.WithContext(ctx))
+	} else {
+		_, resp, err = c.client.Projects.UnarchiveProject(repo.ID, gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change archived state of %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	return nil
+}
+
+func (c *Client) CreateChangeRequest(ctx context.Context, repo *scm.Repository, request scm.ChangeRequest) (string, error) {
+	mr, resp, err := c.client.MergeRequests.CreateMergeRequest(repo.ID, &gitlab.CreateMergeRequestOptions{
+		Title:              gitlab.String(request.Title),
+		Description:        gitlab.String(request.Description),
+		SourceBranch:       gitlab.String(request.SourceBranch),
+		TargetBranch:       gitlab.String(request.TargetBranch),
+		RemoveSourceBranch: gitlab.Bool(true),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to create merge request: %w", apiError(resp, err))
+	}
+	return mr.WebURL, nil
+}
+
+func (c *Client) ListWebhooks(ctx context.Context, repo *scm.Repository) ([]scm.Webhook, error) {
+	var webhooks []scm.Webhook
+	opts := &gitlab.ListProjectHooksOptions{PerPage: 100}
+	for {
+		hooks, resp, err := c.client.Projects.ListProjectHooks(repo.ID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks: %w", apiError(resp, err))
+		}
+		for _, hook := range hooks {
+			webhooks = append(webhooks, scm.Webhook{ID: strconv.Itoa(hook.ID), URL: hook.URL})
+		}
+		if resp.NextPage == 0 {
+			return webhooks, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (c *Client) CreateWebhook(ctx context.Context, repo *scm.Repository, url, secret string) (scm.Webhook, error) {
+	opts := &gitlab.AddProjectHookOptions{
+		URL:           gitlab.String(url),
+		PushEvents:    gitlab.Bool(true),
+		TagPushEvents: gitlab.Bool(true),
+	}
+	if secret != "" {
+		opts.Token = gitlab.String(secret)
+	}
+	hook, resp, err := c.client.Projects.AddProjectHook(repo.ID, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return scm.Webhook{}, fmt.Errorf("failed to create webhook: %w", apiError(resp, err))
+	}
+	return scm.Webhook{ID: strconv.Itoa(hook.ID), URL: hook.URL}, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, repo *scm.Repository, id string) error {
+	hookID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID %q", id)
+	}
+	if resp, err := c.client.Projects.DeleteProjectHook(repo.ID, hookID, gitlab.WithContext(ctx)); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", apiError(resp, err))
+	}
+	return nil
+}
+
+// accessPermissions maps GitLab access levels to scm.Permissions
+var accessPermissions = map[gitlab.AccessLevelValue]string{
+	gitlab.MinimalAccessPermissions: "read",
+	gitlab.GuestPermissions:         "read",
+	gitlab.ReporterPermissions:      "read",
+	gitlab.DeveloperPermissions:     "write",
+	gitlab.MaintainerPermissions:    "maintain",
+	gitlab.OwnerPermissions:         "admin",
+	gitlab.AdminPermissions:         "admin",
+}
+
+// ListMembers returns the members of the project, including those who
+// inherit access from its groups
+func (c *Client) ListMembers(ctx context.Context, repo *scm.Repository) ([]scm.Member, error) {
+	var members []scm.Member
+	opts := &gitlab.ListProjectMembersOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := c.client.ProjectMembers.ListAllProjectMembers(repo.ID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members: %w", apiError(resp, err))
+		}
+		for _, member := range page {
+			permission, ok := accessPermissions[member.AccessLevel]
+			if !ok {
+				permission = "read"
+			}
+			members = append(members, scm.Member{Username: member.Username, Permission: permission})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	root, _, _ := strings.Cut(repo.FullPath, "/")
+	insiders, err := c.topLevelGroupMembers(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if insiders != nil {
+		for i := range members {
+			members[i].External = !insiders[members[i].Username]
+		}
+	}
+	return members, nil
+}
+
+// topLevelGroupMembers returns the usernames of the members of the group at
+// path, or nil when path is a user's namespace
+func (c *Client) topLevelGroupMembers(ctx context.Context, path string) (map[string]bool, error) {
+	if cached, ok := c.groupMembers.Load(path); ok {
+		return cached.(map[string]bool), nil
+	}
+
+	var usernames map[string]bool
+	opts := &gitlab.ListGroupMembersOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := c.client.Groups.ListAllGroupMembers(path, opts, gitlab.WithContext(ctx))
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of group %s: %w", path, apiError(resp, err))
+		}
+		if usernames == nil {
+			usernames = make(map[string]bool)
+		}
+		for _, member := range page {
+			usernames[member.Username] = true
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	c.groupMembers.Store(path, usernames)
+	return usernames, nil
+}
+
+// ListAssignedIssues returns the open issues assigned to the user in every
+// project of the instance
+func (c *Client) ListAssignedIssues(ctx context.Context, filter scm.IssueFilter) ([]scm.Issue, error) {
+	opts := &gitlab.ListIssuesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		State:       gitlab.String("opened"),
+		Scope:       gitlab.String("assigned_to_me"),
+	}
+	if len(filter.Labels) > 0 {
+		labels := gitlab.LabelOptions(filter.Labels)
+		opts.Labels = &labels
+	}
+
+	var issues []scm.Issue
+	for {
+		page, resp, err := c.client.Issues.ListIssues(opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", apiError(resp, err))
+		}
+		for _, issue := range page {
+			issues = append(issues, toIssue(issue))
+		}
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func toIssue(issue *gitlab.Issue) scm.Issue {
+	result := scm.Issue{
+		Number:   issue.IID,
+		Title:    issue.Title,
+		Labels:   issue.Labels,
+		WebURL:   issue.WebURL,
+		Provider: "gitlab",
+	}
+	if issue.References != nil {
+		// The full reference is "group/project#12"
+		if i := strings.LastIndex(issue.References.Full, "#"); i > 0 {
+			result.Repository = issue.References.Full[:i]
+		}
+	}
+	if issue.Author != nil {
+		result.Author = issue.Author.Username
+	}
+	if issue.UpdatedAt != nil {
+		result.UpdatedAt = *issue.UpdatedAt
+	}
+	return result
+}
+
+// LatestPipeline returns the most recent pipeline of the project's default
+// branch
+func (c *Client) LatestPipeline(ctx context.Context, repo *scm.Repository) (*scm.Pipeline, error) {
+	pipelines, resp, err := c.client.Pipelines.ListProjectPipelines(repo.ID, &gitlab.ListProjectPipelinesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 1},
+		Ref:         gitlab.String(repo.DefaultBranch),
+		OrderBy:     gitlab.String("id"),
+		Sort:        gitlab.String("desc"),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pipelines of %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+
+	latest := pipelines[0]
+	pipeline := &scm.Pipeline{Status: pipelineStatus(latest.Status), WebURL: latest.WebURL}
+	if latest.UpdatedAt != nil {
+		pipeline.UpdatedAt = *latest.UpdatedAt
+	}
+	return pipeline, nil
+}
+
+// pipelineStatus maps a GitLab pipeline status to the scm.Pipeline states.
+// Pipelines that have not finished, including those waiting for a manual
+// job, are running.
+func pipelineStatus(status string) string {
+	switch status {
+	case "success":
+		return scm.PipelineSuccess
+	case "failed":
+		return scm.PipelineFailed
+	case "canceled", "skipped":
+		return scm.PipelineCanceled
+	default:
+		return scm.PipelineRunning
+	}
+}
+
+// FetchReadme returns the README GitLab shows on the project page, read from
+// the default branch
+func (c *Client) FetchReadme(ctx context.Context, repo *scm.Repository) (string, error) {
+	project, resp, err := c.client.Projects.GetProject(repo.ID, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get project %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	file := readmeFile(project)
+	if file == "" {
+		return "", nil
+	}
+
+	content, resp, err := c.client.RepositoryFiles.GetRawFile(project.ID, file, &gitlab.GetRawFileOptions{
+		Ref: gitlab.String(project.DefaultBranch),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, apiError(resp, err))
+	}
+	return string(content), nil
+}
+
+// PrimaryLanguage returns the language making up most of the project
+func (c *Client) PrimaryLanguage(ctx context.Context, repo *scm.Repository) (string, error) {
+	languages, resp, err := c.client.Projects.GetProjectLanguages(repo.ID, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to get languages of %s: %w", repo.FullPath, apiError(resp, err))
+	}
+	primary, share := "", float32(0)
+	for language, percentage := range *languages {
+		if percentage > share || (percentage == share && language < primary) {
+			primary, share = language, percentage
+		}
+	}
+	return primary, nil
+}
+
+// readmeFile returns the path of a project's README within its repository,
+// taken from the readme_url GitLab reports
+func readmeFile(project *gitlab.Project) string {
+	marker := "/-/blob/" + project.DefaultBranch + "/"
+	if _, file, ok := strings.Cut(project.ReadmeURL, marker); ok {
+		return file
+	}
+	return ""
+}
+
+// withStatistics asks for project statistics, which ListGroupProjectsOptions
+// has no field for
+func withStatistics(req *retryablehttp.Request) error {
+	query := req.URL.Query()
+	query.Set("statistics", "true")
+	req.URL.RawQuery = query.Encode()
+	return nil
+}
+
+// apiError keeps the HTTP status of a failed request with its error, so
+// callers can tell what went wrong
+func apiError(resp *gitlab.Response, err error) error {
+	if resp == nil || resp.Response == nil {
+		return err
+	}
+	return &scm.APIError{StatusCode: resp.StatusCode, Err: err}
+}
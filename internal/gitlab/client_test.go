@@ -1,10 +1,19 @@
 package gitlab
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"gitstuff/internal/scm"
+
+	"github.com/xanzy/go-gitlab"
 )
 
 func TestBuildRepositoryTree_EmptyRepos(t *testing.T) {
@@ -190,3 +199,703 @@ func buildTreeFromRepos(repos []*scm.Repository) *scm.RepositoryTree {
 
 	return tree
 }
+
+func TestClient_CheckHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/user" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("RateLimit-Limit", "2000")
+			w.Header().Set("RateLimit-Remaining", "1999")
+			w.Header().Set("RateLimit-Reset", "1700000000")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"id": 1, "username": "jdoe"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	health, err := client.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+
+	if health.User != "jdoe" {
+		t.Errorf("Expected user 'jdoe', got '%s'", health.User)
+	}
+	if health.RateLimitLimit != 2000 || health.RateLimitRemaining != 1999 {
+		t.Errorf("Expected rate limit 1999/2000, got %d/%d", health.RateLimitRemaining, health.RateLimitLimit)
+	}
+	if health.RateLimitReset.Unix() != 1700000000 {
+		t.Errorf("Expected reset at 1700000000, got %d", health.RateLimitReset.Unix())
+	}
+}
+
+func TestClient_CheckHealth_Scopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id": 1, "username": "jdoe"}`))
+		case "/api/v4/personal_access_tokens/self":
+			_, _ = w.Write([]byte(`{"id": 7, "name": "gitstuff", "scopes": ["read_api", "read_repository"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	health, err := client.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+	if strings.Join(health.Scopes, ",") != "read_api,read_repository" {
+		t.Errorf("Unexpected scopes: %v", health.Scopes)
+	}
+}
+
+func TestClient_CheckHealth_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message": "401 Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "bad-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.CheckHealth(context.Background())
+	var apiErr *scm.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected APIError with status 401, got %v", err)
+	}
+}
+
+func TestClient_ListAllRepositories_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "insufficient scope"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ListAllRepositories(context.Background())
+	if kind := scm.ClassifyError(err); kind != scm.ErrorForbidden {
+		t.Errorf("Expected a forbidden error, got kind %v from %v", kind, err)
+	}
+}
+
+func TestClient_CheckHealth_NoRateLimitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "username": "jdoe"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	health, err := client.CheckHealth(context.Background())
+	if err != nil {
+		t.Fatalf("CheckHealth() error = %v", err)
+	}
+
+	if health.RateLimitLimit != 0 {
+		t.Errorf("Expected unknown rate limit, got %d", health.RateLimitLimit)
+	}
+	if !health.RateLimitReset.IsZero() {
+		t.Errorf("Expected zero reset time, got %v", health.RateLimitReset)
+	}
+}
+
+func TestClient_ListAllRepositories_Archived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "name": "active", "path_with_namespace": "team/active", "archived": false, "visibility": "internal", "topics": ["terraform", "aws"]},
+			{"id": 2, "name": "retired", "path_with_namespace": "team/retired", "archived": true, "visibility": "public", "tag_list": ["legacy"]}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(repos))
+	}
+	if repos[0].Archived || !repos[1].Archived {
+		t.Errorf("Expected only team/retired to be archived, got %v and %v", repos[0].Archived, repos[1].Archived)
+	}
+	if repos[0].Visibility != "internal" || repos[1].Visibility != "public" {
+		t.Errorf("Unexpected visibilities %q and %q", repos[0].Visibility, repos[1].Visibility)
+	}
+	// Older instances only report tags
+	if !reflect.DeepEqual(repos[0].Topics, []string{"terraform", "aws"}) || !reflect.DeepEqual(repos[1].Topics, []string{"legacy"}) {
+		t.Errorf("Unexpected topics %v and %v", repos[0].Topics, repos[1].Topics)
+	}
+}
+
+func TestClient_RepositoryMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/groups/team":
+			_, _ = w.Write([]byte(`{"id": 7, "full_path": "team"}`))
+		case "/api/v4/groups/7/projects":
+			if r.URL.Query().Get("statistics") != "true" {
+				t.Errorf("Expected statistics to be requested, got %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"id": 1, "path_with_namespace": "team/api", "star_count": 3,
+				"last_activity_at": "2026-10-01T12:00:00Z", "statistics": {"repository_size": 5000}}]`))
+		case "/api/v4/projects/1/languages":
+			_, _ = w.Write([]byte(`{"Shell": 10.5, "Go": 80.1, "Makefile": 9.4}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListRepositoriesInGroup(context.Background(), "team")
+	if err != nil {
+		t.Fatalf("ListRepositoriesInGroup() error = %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("Expected 1 repository, got %d", len(repos))
+	}
+	repo := repos[0]
+	if repo.Size != 5000 || repo.Stars != 3 || !repo.LastActivity.Equal(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected metadata: size %d, stars %d, last activity %v", repo.Size, repo.Stars, repo.LastActivity)
+	}
+
+	language, err := client.PrimaryLanguage(context.Background(), repo)
+	if err != nil || language != "Go" {
+		t.Errorf("PrimaryLanguage() = %q, %v, want Go", language, err)
+	}
+}
+
+func TestToRepository_Fork(t *testing.T) {
+	project := &gitlab.Project{
+		ID:                1,
+		Name:              "api",
+		PathWithNamespace: "alice/api",
+		HTTPURLToRepo:     "https://gitlab.example.com/alice/api.git",
+		SSHURLToRepo:      "git@gitlab.example.com:alice/api.git",
+		ForkedFromProject: &gitlab.ForkParent{
+			PathWithNamespace: "team/backend/api",
+			HTTPURLToRepo:     "https://gitlab.example.com/team/backend/api.git",
+		},
+	}
+
+	repo := toRepository(project)
+	if !repo.Fork || repo.ParentFullPath != "team/backend/api" {
+		t.Fatalf("Expected fork of team/backend/api, got fork=%v parent=%q", repo.Fork, repo.ParentFullPath)
+	}
+	if repo.ParentCloneURL != "https://gitlab.example.com/team/backend/api.git" {
+		t.Errorf("Unexpected parent clone URL %q", repo.ParentCloneURL)
+	}
+	if repo.ParentSSHCloneURL != "git@gitlab.example.com:team/backend/api.git" {
+		t.Errorf("Unexpected parent SSH URL %q", repo.ParentSSHCloneURL)
+	}
+
+	project.ForkedFromProject = nil
+	if repo := toRepository(project); repo.Fork || repo.ParentFullPath != "" {
+		t.Errorf("Expected non-fork without parent, got fork=%v parent=%q", repo.Fork, repo.ParentFullPath)
+	}
+}
+
+func TestClient_CreateChangeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v4/projects/42/merge_requests" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		if body["source_branch"] != "bump-deps" || body["target_branch"] != "main" || body["title"] != "Bump dependencies" {
+			t.Errorf("Unexpected merge request: %v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 1, "iid": 7, "web_url": "https://gitlab.example.com/team/api/-/merge_requests/7"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	url, err := client.CreateChangeRequest(context.Background(), &scm.Repository{ID: "42", FullPath: "team/api"}, scm.ChangeRequest{
+		SourceBranch: "bump-deps",
+		TargetBranch: "main",
+		Title:        "Bump dependencies",
+	})
+	if err != nil {
+		t.Fatalf("CreateChangeRequest failed: %v", err)
+	}
+	if url != "https://gitlab.example.com/team/api/-/merge_requests/7" {
+		t.Errorf("Unexpected URL: %s", url)
+	}
+}
+
+func TestClient_Webhooks(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/42/hooks":
+			_, _ = w.Write([]byte(`[{"id": 3, "url": "https://mirror.example.com/hook"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/42/hooks":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 4, "url": "https://ci.example.com/hook"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v4/projects/42/hooks/3":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	repo := &scm.Repository{ID: "42", FullPath: "team/api"}
+
+	hooks, err := client.ListWebhooks(context.Background(), repo)
+	if err != nil || len(hooks) != 1 || hooks[0].ID != "3" || hooks[0].URL != "https://mirror.example.com/hook" {
+		t.Errorf("ListWebhooks() = %+v, %v", hooks, err)
+	}
+
+	hook, err := client.CreateWebhook(context.Background(), repo, "https://ci.example.com/hook", "s3cret")
+	if err != nil || hook.ID != "4" {
+		t.Errorf("CreateWebhook() = %+v, %v", hook, err)
+	}
+	if created["push_events"] != true || created["tag_push_events"] != true || created["token"] != "s3cret" {
+		t.Errorf("Unexpected webhook request: %v", created)
+	}
+
+	if err := client.DeleteWebhook(context.Background(), repo, "3"); err != nil {
+		t.Errorf("DeleteWebhook failed: %v", err)
+	}
+}
+
+func TestClient_ListAllRepositories_RateLimited(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "api", "path_with_namespace": "team/api"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.ListAllRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllRepositories() error = %v", err)
+	}
+	if len(repos) != 1 || requests != 2 {
+		t.Errorf("Expected 1 repository after one retry, got %d after %d requests", len(repos), requests)
+	}
+}
+
+func TestClient_FetchReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 42, "default_branch": "main", "readme_url": "https://gitlab.example.com/team/api/-/blob/main/docs/README.md"}`))
+		case "/api/v4/projects/43":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 43, "default_branch": "main", "readme_url": null}`))
+		case "/api/v4/projects/42/repository/files/docs%2FREADME%2Emd/raw":
+			if ref := r.URL.Query().Get("ref"); ref != "main" {
+				t.Errorf("Expected the default branch, got ref %q", ref)
+			}
+			_, _ = w.Write([]byte("# API\n\nServes things."))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	readme, err := client.FetchReadme(context.Background(), &scm.Repository{ID: "42", FullPath: "team/api"})
+	if err != nil || readme != "# API\n\nServes things." {
+		t.Errorf("FetchReadme() = %q, %v", readme, err)
+	}
+	readme, err = client.FetchReadme(context.Background(), &scm.Repository{ID: "43", FullPath: "team/empty"})
+	if err != nil || readme != "" {
+		t.Errorf("Expected no README, got %q, %v", readme, err)
+	}
+}
+
+func TestClient_ServerVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": "12.10.14-ee", "revision": "abc123"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	version, err := client.ServerVersion(context.Background())
+	if err != nil || version != "12.10.14-ee" {
+		t.Errorf("ServerVersion() = %q, %v; want 12.10.14-ee", version, err)
+	}
+}
+
+func TestSearchRepositories(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "name": "billing", "path_with_namespace": "team/billing", "description": "Invoices and payments"},
+			{"id": 2, "name": "billing-ui", "path_with_namespace": "team/billing-ui"},
+			{"id": 3, "name": "old-billing", "path_with_namespace": "archive/old-billing"}
+		]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repos, err := client.SearchRepositories(context.Background(), "billing", 2)
+	if err != nil {
+		t.Fatalf("SearchRepositories() error = %v", err)
+	}
+	if len(repos) != 2 || repos[0].FullPath != "team/billing" || repos[0].Description != "Invoices and payments" {
+		t.Errorf("Unexpected repositories: %+v", repos)
+	}
+	for _, want := range []string{"search=billing", "search_namespaces=true", "per_page=2"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("Expected query to contain %q, got %q", want, query)
+		}
+	}
+}
+
+func TestClient_CreateRepository(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/namespaces/team/platform":
+			_, _ = w.Write([]byte(`{"id": 7, "full_path": "team/platform"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 42, "name": "tool", "path_with_namespace": "team/platform/tool", "ssh_url_to_repo": "git@gitlab.example.com:team/platform/tool.git", "visibility": "internal"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	repo, err := client.CreateRepository(context.Background(), scm.NewRepository{
+		FullPath:      "team/platform/tool",
+		Description:   "Internal tooling",
+		Visibility:    "internal",
+		DefaultBranch: "trunk",
+	})
+	if err != nil || repo.ID != "42" || repo.FullPath != "team/platform/tool" {
+		t.Fatalf("CreateRepository() = %+v, %v", repo, err)
+	}
+	for key, want := range map[string]interface{}{"name": "tool", "path": "tool", "namespace_id": float64(7), "visibility": "internal", "description": "Internal tooling", "default_branch": "trunk"} {
+		if created[key] != want {
+			t.Errorf("Expected %s to be %v, got %v", key, want, created[key])
+		}
+	}
+
+	if _, err := client.CreateRepository(context.Background(), scm.NewRepository{FullPath: "missing/tool", Visibility: "private"}); err == nil || !strings.Contains(err.Error(), "failed to get namespace missing") {
+		t.Errorf("Expected an unknown namespace error, got %v", err)
+	}
+}
+
+func TestClient_ForkRepository(t *testing.T) {
+	oldInterval := forkPollInterval
+	forkPollInterval = time.Millisecond
+	defer func() { forkPollInterval = oldInterval }()
+
+	conflict, polls := false, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/upstream/tool":
+			_, _ = w.Write([]byte(`{"id": 5, "path": "tool", "path_with_namespace": "upstream/tool", "http_url_to_repo": "https://gitlab.example.com/upstream/tool.git", "ssh_url_to_repo": "git@gitlab.example.com:upstream/tool.git"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/5/fork":
+			if conflict {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"message": {"name": ["has already been taken"]}}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 9, "path": "tool", "path_with_namespace": "me/tool", "import_status": "scheduled"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/9":
+			polls++
+			status := "started"
+			if polls > 1 {
+				status = "finished"
+			}
+			_, _ = w.Write([]byte(`{"id": 9, "path": "tool", "path_with_namespace": "me/tool", "ssh_url_to_repo": "git@gitlab.example.com:me/tool.git", "import_status": "` + status + `"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id": 1, "username": "me"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/me/tool":
+			_, _ = w.Write([]byte(`{"id": 9, "path": "tool", "path_with_namespace": "me/tool", "forked_from_project": {"id": 5, "path_with_namespace": "upstream/tool"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	fork, err := client.ForkRepository(context.Background(), "upstream/tool", "")
+	if err != nil {
+		t.Fatalf("ForkRepository failed: %v", err)
+	}
+	if fork.FullPath != "me/tool" || !fork.Fork || fork.ParentSSHCloneURL != "git@gitlab.example.com:upstream/tool.git" || polls != 2 {
+		t.Errorf("Unexpected fork %+v after %d polls", fork, polls)
+	}
+
+	conflict = true
+	fork, err = client.ForkRepository(context.Background(), "upstream/tool", "")
+	if err != nil || fork.FullPath != "me/tool" || fork.ParentFullPath != "upstream/tool" {
+		t.Errorf("Expected the existing fork, got %+v, %v", fork, err)
+	}
+}
+
+func TestClient_SetArchived(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		calls = append(calls, r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 42}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	repo := &scm.Repository{ID: "42", FullPath: "team/api"}
+	if err := client.SetArchived(context.Background(), repo, true); err != nil {
+		t.Fatalf("SetArchived(true) failed: %v", err)
+	}
+	if err := client.SetArchived(context.Background(), repo, false); err != nil {
+		t.Fatalf("SetArchived(false) failed: %v", err)
+	}
+	if want := "/api/v4/projects/42/archive,/api/v4/projects/42/unarchive"; strings.Join(calls, ",") != want {
+		t.Errorf("Unexpected requests %v, want %s", calls, want)
+	}
+}
+
+func TestClient_ListMembers(t *testing.T) {
+	groupRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/42/members/all", "/api/v4/projects/43/members/all":
+			_, _ = w.Write([]byte(`[
+				{"id": 1, "username": "lead", "access_level": 50},
+				{"id": 2, "username": "dev", "access_level": 30},
+				{"id": 3, "username": "auditor", "access_level": 20}
+			]`))
+		case "/api/v4/groups/team/members/all":
+			groupRequests++
+			_, _ = w.Write([]byte(`[{"id": 1, "username": "lead"}, {"id": 2, "username": "dev"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for _, repo := range []*scm.Repository{{ID: "42", FullPath: "team/api"}, {ID: "43", FullPath: "team/backend/web"}} {
+		members, err := client.ListMembers(context.Background(), repo)
+		if err != nil {
+			t.Fatalf("ListMembers() error = %v", err)
+		}
+		want := []scm.Member{
+			{Username: "lead", Permission: "admin"},
+			{Username: "dev", Permission: "write"},
+			{Username: "auditor", Permission: "read", External: true},
+		}
+		if !reflect.DeepEqual(members, want) {
+			t.Errorf("ListMembers(%s) = %+v, want %+v", repo.FullPath, members, want)
+		}
+	}
+	if groupRequests != 1 {
+		t.Errorf("Expected the group members to be fetched once, got %d requests", groupRequests)
+	}
+
+	// Projects of users have no group to be external to
+	members, err := client.ListMembers(context.Background(), &scm.Repository{ID: "42", FullPath: "alice/notes"})
+	if err != nil || len(members) != 3 || members[2].External {
+		t.Errorf("ListMembers() = %+v, %v", members, err)
+	}
+}
+
+func TestClient_ListAssignedIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v4/issues" || query.Get("scope") != "assigned_to_me" || query.Get("state") != "opened" || query.Get("labels") != "bug,p1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": 907, "iid": 7, "title": "Crash on start", "labels": ["bug", "p1"],
+			"web_url": "https://gitlab.example.com/team/backend/api/-/issues/7", "updated_at": "2026-10-01T10:00:00Z",
+			"author": {"username": "reporter"}, "references": {"full": "team/backend/api#7"}}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	issues, err := client.ListAssignedIssues(context.Background(), scm.IssueFilter{Labels: []string{"bug", "p1"}})
+	if err != nil {
+		t.Fatalf("ListAssignedIssues() error = %v", err)
+	}
+	want := []scm.Issue{{
+		Number:     7,
+		Title:      "Crash on start",
+		Repository: "team/backend/api",
+		Labels:     []string{"bug", "p1"},
+		Author:     "reporter",
+		WebURL:     "https://gitlab.example.com/team/backend/api/-/issues/7",
+		UpdatedAt:  time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		Provider:   "gitlab",
+	}}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("ListAssignedIssues() = %+v, want %+v", issues, want)
+	}
+}
+
+func TestClient_LatestPipeline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("ref") != "main" || query.Get("per_page") != "1" {
+			t.Errorf("Expected the latest pipeline of the default branch, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v4/projects/42/pipelines":
+			_, _ = w.Write([]byte(`[{"id": 9, "status": "manual", "ref": "main",
+				"web_url": "https://gitlab.example.com/team/api/-/pipelines/9", "updated_at": "2026-10-01T10:00:00Z"}]`))
+		case "/api/v4/projects/43/pipelines":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "test-token", false)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	pipeline, err := client.LatestPipeline(context.Background(), &scm.Repository{ID: "42", FullPath: "team/api", DefaultBranch: "main"})
+	if err != nil {
+		t.Fatalf("LatestPipeline() error = %v", err)
+	}
+	want := &scm.Pipeline{
+		Status:    scm.PipelineRunning,
+		WebURL:    "https://gitlab.example.com/team/api/-/pipelines/9",
+		UpdatedAt: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(pipeline, want) {
+		t.Errorf("LatestPipeline() = %+v, want %+v", pipeline, want)
+	}
+
+	pipeline, err = client.LatestPipeline(context.Background(), &scm.Repository{ID: "43", FullPath: "team/docs", DefaultBranch: "main"})
+	if err != nil || pipeline != nil {
+		t.Errorf("Expected no pipeline, got %+v, %v", pipeline, err)
+	}
+}
@@ -0,0 +1,104 @@
+// Package hints suggests a follow-up command when what a command found calls
+// for one, such as cloning repositories that a listing shows are missing.
+// Rules choose the hints from facts the command records. How often each hint
+// was shown is counted in a local file, so hints stop once they have been
+// seen a few times; nothing is sent anywhere.
+package hints
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// MaxShown is how many times a hint is shown before it is retired
+const MaxShown = 3
+
+// Facts are what a command found, such as the number of repositories that
+// are not cloned
+type Facts map[string]int
+
+// Rule suggests Message after Command when When holds for the facts the
+// command recorded
+type Rule struct {
+	ID      string
+	Command string
+	When    func(Facts) bool
+	Message func(Facts) string
+}
+
+// Engine picks the hints to show from its rules
+type Engine struct {
+	rules []Rule
+
+	// seenPath is the file counting how often each hint was shown; empty
+	// shows hints without counting them
+	seenPath string
+}
+
+// New returns an engine using rules that counts the hints it shows in
+// seenPath
+func New(rules []Rule, seenPath string) *Engine {
+	return &Engine{rules: rules, seenPath: seenPath}
+}
+
+// Show writes the hints for command and facts that have not been shown
+// MaxShown times yet to w, and counts them. It returns how many it wrote.
+func (e *Engine) Show(w io.Writer, command string, facts Facts) (int, error) {
+	seen, err := e.load()
+	if err != nil {
+		return 0, err
+	}
+
+	shown := 0
+	for _, rule := range e.rules {
+		if rule.Command != command || seen[rule.ID] >= MaxShown || !rule.When(facts) {
+			continue
+		}
+		fmt.Fprintf(w, "💡 %s\n", rule.Message(facts))
+		seen[rule.ID]++
+		shown++
+	}
+	if shown == 0 {
+		return 0, nil
+	}
+	return shown, e.save(seen)
+}
+
+func (e *Engine) load() (map[string]int, error) {
+	seen := make(map[string]int)
+	if e.seenPath == "" {
+		return seen, nil
+	}
+	data, err := os.ReadFile(e.seenPath)
+	if os.IsNotExist(err) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", e.seenPath, err)
+	}
+	if err := json.Unmarshal(data, &seen); err != nil {
+		// A damaged file only means hints are shown again
+		return make(map[string]int), nil
+	}
+	return seen, nil
+}
+
+func (e *Engine) save(seen map[string]int) error {
+	if e.seenPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.seenPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(e.seenPath), err)
+	}
+	if err := os.WriteFile(e.seenPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.seenPath, err)
+	}
+	return nil
+}
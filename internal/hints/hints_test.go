@@ -0,0 +1,79 @@
+package hints
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testRules() []Rule {
+	return []Rule{
+		{
+			ID:      "clone-missing",
+			Command: "list",
+			When:    func(f Facts) bool { return f["not_cloned"] > 0 },
+			Message: func(f Facts) string { return fmt.Sprintf("%d not cloned", f["not_cloned"]) },
+		},
+		{
+			ID:      "sync-failed",
+			Command: "sync",
+			When:    func(f Facts) bool { return f["failed"] > 0 },
+			Message: func(f Facts) string { return "see last" },
+		},
+	}
+}
+
+func TestShow(t *testing.T) {
+	seenPath := filepath.Join(t.TempDir(), "hints", "seen.json")
+	engine := New(testRules(), seenPath)
+
+	var out bytes.Buffer
+	shown, err := engine.Show(&out, "list", Facts{"not_cloned": 4})
+	if err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if shown != 1 || out.String() != "💡 4 not cloned\n" {
+		t.Errorf("Expected only the list hint, got %d:\n%s", shown, out.String())
+	}
+
+	out.Reset()
+	if shown, _ := engine.Show(&out, "list", Facts{"not_cloned": 0}); shown != 0 || out.Len() != 0 {
+		t.Errorf("Expected no hint when the rule does not hold, got:\n%s", out.String())
+	}
+}
+
+func TestShow_RetiresAfterMaxShown(t *testing.T) {
+	seenPath := filepath.Join(t.TempDir(), "seen.json")
+	for i := 0; i < MaxShown; i++ {
+		// A new engine each time, as each command run starts fresh
+		if shown, err := New(testRules(), seenPath).Show(&bytes.Buffer{}, "list", Facts{"not_cloned": 1}); err != nil || shown != 1 {
+			t.Fatalf("Run %d: shown = %d, error = %v", i, shown, err)
+		}
+	}
+
+	var out bytes.Buffer
+	if shown, _ := New(testRules(), seenPath).Show(&out, "list", Facts{"not_cloned": 1}); shown != 0 {
+		t.Errorf("Expected the hint to be retired, got:\n%s", out.String())
+	}
+	if shown, _ := New(testRules(), seenPath).Show(&out, "sync", Facts{"failed": 1}); shown != 1 {
+		t.Errorf("Expected other hints to still be shown")
+	}
+}
+
+func TestShow_DamagedFile(t *testing.T) {
+	seenPath := filepath.Join(t.TempDir(), "seen.json")
+	if err := os.WriteFile(seenPath, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if shown, err := New(testRules(), seenPath).Show(&out, "list", Facts{"not_cloned": 2}); err != nil || shown != 1 {
+		t.Errorf("Expected a damaged file to be replaced, got %d, %v", shown, err)
+	}
+	data, _ := os.ReadFile(seenPath)
+	if !strings.Contains(string(data), `"clone-missing": 1`) {
+		t.Errorf("Unexpected file contents: %s", data)
+	}
+}
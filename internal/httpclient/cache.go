@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheHeader is set on responses served from the cache. Its value is "hit"
+// for fresh entries and "revalidated" when the server answered 304.
+const CacheHeader = "X-Gitstuff-Cache"
+
+// CacheTransport is a disk-backed caching round tripper. It stores successful
+// GET responses, serves them while they are fresh according to Cache-Control
+// max-age, and revalidates stale entries with If-None-Match/If-Modified-Since.
+// Cache keys include the credentials sent with the request so different
+// tokens never share entries.
+type CacheTransport struct {
+	Base http.RoundTripper
+	Dir  string
+
+	now func() time.Time
+}
+
+type cacheEntry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+func NewCacheTransport(base http.RoundTripper, dir string) *CacheTransport {
+	return &CacheTransport{Base: base, Dir: dir, now: time.Now}
+}
+
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry := t.load(key)
+
+	if entry != nil && !hasDirective(req.Header.Get("Cache-Control"), "no-cache") && t.isFresh(entry) {
+		return entry.response(req, "hit"), nil
+	}
+
+	outReq := req
+	if entry != nil {
+		outReq = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			outReq.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.Base.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		for name, values := range resp.Header {
+			entry.Header[name] = values
+		}
+		entry.StoredAt = t.now()
+		t.store(key, entry)
+		return entry.response(req, "revalidated"), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !isCacheable(resp.Header) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	t.store(key, &cacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		StoredAt:   t.now(),
+	})
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (t *CacheTransport) isFresh(entry *cacheEntry) bool {
+	cacheControl := entry.Header.Get("Cache-Control")
+	if hasDirective(cacheControl, "no-cache") {
+		return false
+	}
+	maxAge, ok := directiveSeconds(cacheControl, "max-age")
+	if !ok {
+		return false
+	}
+	return t.now().Sub(entry.StoredAt) < time.Duration(maxAge)*time.Second
+}
+
+func (t *CacheTransport) path(key string) string {
+	return filepath.Join(t.Dir, key[:2], key+".json")
+}
+
+func (t *CacheTransport) load(key string) *cacheEntry {
+	data, err := os.ReadFile(t.path(key))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// store writes entry to disk. Failures are ignored since the cache is only an
+// optimisation and must never break a request.
+func (t *CacheTransport) store(key string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	path := t.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+func (e *cacheEntry) response(req *http.Request, source string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheHeader, source)
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.URL.String()))
+	for _, name := range []string{"Authorization", "Private-Token", "Accept"} {
+		h.Write([]byte{0})
+		h.Write([]byte(req.Header.Get(name)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isCacheable(header http.Header) bool {
+	cacheControl := header.Get("Cache-Control")
+	if hasDirective(cacheControl, "no-store") {
+		return false
+	}
+	if header.Get("ETag") != "" || header.Get("Last-Modified") != "" {
+		return true
+	}
+	maxAge, ok := directiveSeconds(cacheControl, "max-age")
+	return ok && maxAge > 0
+}
+
+func hasDirective(cacheControl, directive string) bool {
+	for _, part := range strings.Split(cacheControl, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+func directiveSeconds(cacheControl, directive string) (int, bool) {
+	for _, part := range strings.Split(cacheControl, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found || !strings.EqualFold(name, directive) {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return seconds, true
+	}
+	return 0, false
+}
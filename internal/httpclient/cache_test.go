@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestCache(t *testing.T, now *time.Time) *CacheTransport {
+	t.Helper()
+	transport := NewCacheTransport(http.DefaultTransport, t.TempDir())
+	transport.now = func() time.Time { return *now }
+	return transport
+}
+
+func get(t *testing.T, client *http.Client, url string, header http.Header) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	return resp, string(body)
+}
+
+func TestCacheTransport_ServesFreshEntries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "private, max-age=60")
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := &http.Client{Transport: newTestCache(t, &now)}
+
+	resp, body := get(t, client, server.URL, nil)
+	if body != "payload" || resp.Header.Get(CacheHeader) != "" {
+		t.Fatalf("Expected uncached first response, got %q (%s)", body, resp.Header.Get(CacheHeader))
+	}
+
+	resp, body = get(t, client, server.URL, nil)
+	if body != "payload" || resp.Header.Get(CacheHeader) != "hit" {
+		t.Errorf("Expected cache hit, got %q (%s)", body, resp.Header.Get(CacheHeader))
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 request to reach the server, got %d", hits)
+	}
+
+	now = now.Add(2 * time.Minute)
+	get(t, client, server.URL, nil)
+	if hits != 2 {
+		t.Errorf("Expected stale entry to be refetched, server saw %d requests", hits)
+	}
+}
+
+func TestCacheTransport_RevalidatesWithETag(t *testing.T) {
+	var hits, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "max-age=0, private, must-revalidate")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("projects"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := &http.Client{Transport: newTestCache(t, &now)}
+
+	get(t, client, server.URL, nil)
+	resp, body := get(t, client, server.URL, nil)
+
+	if body != "projects" {
+		t.Errorf("Expected cached body, got %q", body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for revalidated entry, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get(CacheHeader) != "revalidated" {
+		t.Errorf("Expected revalidated marker, got %q", resp.Header.Get(CacheHeader))
+	}
+	if hits != 2 || notModified != 1 {
+		t.Errorf("Expected 2 requests with 1 conditional hit, got %d and %d", hits, notModified)
+	}
+}
+
+func TestCacheTransport_RespectsNoStore(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := &http.Client{Transport: newTestCache(t, &now)}
+
+	get(t, client, server.URL, nil)
+	get(t, client, server.URL, nil)
+
+	if hits != 2 {
+		t.Errorf("Expected no-store responses to bypass the cache, server saw %d requests", hits)
+	}
+}
+
+func TestCacheTransport_SeparatesCredentials(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := &http.Client{Transport: newTestCache(t, &now)}
+
+	_, first := get(t, client, server.URL, http.Header{"Authorization": {"token a"}})
+	_, second := get(t, client, server.URL, http.Header{"Authorization": {"token b"}})
+
+	if first != "token a" || second != "token b" {
+		t.Errorf("Expected responses per credential, got %q and %q", first, second)
+	}
+	if hits != 2 {
+		t.Errorf("Expected 2 server requests, got %d", hits)
+	}
+}
+
+func TestCacheTransport_SkipsNonGetAndErrors(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := &http.Client{Transport: newTestCache(t, &now)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(server.URL, "text/plain", nil)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		get(t, client, server.URL+"/missing", nil)
+	}
+
+	if hits != 4 {
+		t.Errorf("Expected POSTs and 404s to bypass the cache, server saw %d requests", hits)
+	}
+}
+
+func TestCacheTransport_RequestNoCacheForcesRevalidation(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := &http.Client{Transport: newTestCache(t, &now)}
+
+	get(t, client, server.URL, nil)
+	get(t, client, server.URL, http.Header{"Cache-Control": {"no-cache"}})
+
+	if hits != 2 {
+		t.Errorf("Expected no-cache request to reach the server, got %d requests", hits)
+	}
+}
+
+func TestDirectiveSeconds(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+		ok     bool
+	}{
+		{"max-age=60", 60, true},
+		{"private, max-age=120", 120, true},
+		{"max-age=\"30\"", 30, true},
+		{"no-cache", 0, false},
+		{"max-age=abc", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := directiveSeconds(tt.header, "max-age")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("directiveSeconds(%q) = %d, %t, want %d, %t", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// Options controls how the HTTP client used by provider clients is built
+type Options struct {
+	// Insecure skips TLS certificate verification (self-signed certificates)
+	Insecure bool
+
+	// CacheDir enables the on-disk response cache when non-empty
+	CacheDir string
+
+	// UserAgent replaces the User-Agent header of every request when non-empty
+	UserAgent string
+}
+
+// New returns an HTTP client with the transport chain described by opts
+func New(opts Options) *http.Client {
+	return &http.Client{Transport: NewTransport(opts)}
+}
+
+// NewTransport builds the round tripper chain described by opts. Callers that
+// add their own authentication transport should wrap the returned value.
+func NewTransport(opts Options) http.RoundTripper {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Insecure {
+		base.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	var transport http.RoundTripper = base
+	if opts.CacheDir != "" {
+		transport = NewCacheTransport(transport, opts.CacheDir)
+	}
+	if opts.UserAgent != "" {
+		transport = &UserAgentTransport{Base: transport, UserAgent: opts.UserAgent}
+	}
+	transport = &TraceTransport{Base: transport}
+	transport = &TimingTransport{Base: transport}
+	// Outermost so every attempt is traced and timed but waits are not
+	transport = &RetryTransport{Base: transport}
+
+	return transport
+}
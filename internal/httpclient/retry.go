@@ -0,0 +1,201 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitstuff/internal/verbosity"
+)
+
+const (
+	// DefaultMaxRetries is how many times a rate-limited request is retried
+	DefaultMaxRetries = 5
+
+	// DefaultMaxWait is the longest single wait for a rate limit to reset.
+	// A request that would have to wait longer fails instead.
+	DefaultMaxWait = 15 * time.Minute
+
+	// retryBaseDelay is the first backoff when the provider does not say
+	// how long to wait
+	retryBaseDelay = time.Second
+)
+
+// RetryTransport retries requests rejected by a provider rate limit, waiting
+// as long as the provider asks through Retry-After or the rate limit reset
+// headers, or backing off exponentially with jitter when it does not say
+type RetryTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	MaxWait    time.Duration
+
+	// sleep waits for d unless ctx is done first; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		warnExhausted(req, resp)
+		if !isRateLimited(resp) || attempt >= t.maxRetries() {
+			return resp, nil
+		}
+
+		delay := retryDelay(resp, attempt, time.Now())
+		if delay > t.maxWait() {
+			verbosity.Info("Rate limited by %s, which asks to wait %s; giving up", req.URL.Host, delay.Round(time.Second))
+			return resp, nil
+		}
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, nil
+		}
+
+		verbosity.Info("Rate limited by %s, retrying in %s (attempt %d of %d)", req.URL.Host, delay.Round(time.Second), attempt+1, t.maxRetries())
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := t.wait(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		req = retry
+	}
+}
+
+func (t *RetryTransport) maxRetries() int {
+	if t.MaxRetries > 0 {
+		return t.MaxRetries
+	}
+	return DefaultMaxRetries
+}
+
+func (t *RetryTransport) maxWait() time.Duration {
+	if t.MaxWait > 0 {
+		return t.MaxWait
+	}
+	return DefaultMaxWait
+}
+
+func (t *RetryTransport) wait(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rewind returns a copy of req that can be sent again, which needs a fresh
+// body when it has one
+func rewind(req *http.Request) (*http.Request, bool) {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry.Body = body
+	return retry, true
+}
+
+// isRateLimited reports whether resp rejected the request because of a
+// rate limit: a 429, or a 403 that GitHub uses for its primary and
+// secondary limits
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		if resp.Header.Get("Retry-After") != "" || rateLimitRemaining(resp.Header) == "0" {
+			return true
+		}
+		return bodyMentionsRateLimit(resp)
+	}
+	return false
+}
+
+// bodyMentionsRateLimit looks for "rate limit" at the start of the body,
+// which is how GitHub describes secondary limits without headers. The body
+// is restored for the caller.
+func bodyMentionsRateLimit(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	return strings.Contains(strings.ToLower(string(head)), "rate limit")
+}
+
+// retryDelay returns how long to wait before retrying: what the provider
+// asked for, or an exponential backoff with jitter
+func retryDelay(resp *http.Response, attempt int, now time.Time) time.Duration {
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0)
+		}
+	}
+	if rateLimitRemaining(resp.Header) == "0" {
+		if reset, ok := rateLimitReset(resp.Header); ok {
+			// A second of slack for clock differences
+			return max(reset.Sub(now), 0) + time.Second
+		}
+	}
+
+	backoff := retryBaseDelay << min(attempt, 10)
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func rateLimitRemaining(header http.Header) string {
+	if value := header.Get("X-RateLimit-Remaining"); value != "" {
+		return value
+	}
+	return header.Get("RateLimit-Remaining")
+}
+
+// rateLimitReset returns when the rate limit resets, from GitHub's
+// X-RateLimit-Reset or GitLab's RateLimit-Reset Unix timestamps
+func rateLimitReset(header http.Header) (time.Time, bool) {
+	value := header.Get("X-RateLimit-Reset")
+	if value == "" {
+		value = header.Get("RateLimit-Reset")
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// warnExhausted tells the user when a successful response used up the rate
+// limit, since the next requests will have to wait for the reset
+func warnExhausted(req *http.Request, resp *http.Response) {
+	if resp.StatusCode >= 300 || rateLimitRemaining(resp.Header) != "0" {
+		return
+	}
+	if reset, ok := rateLimitReset(resp.Header); ok && reset.After(time.Now()) {
+		verbosity.Info("Rate limit for %s used up, further requests wait until %s", req.URL.Host, reset.Format("15:04:05"))
+	}
+}
@@ -0,0 +1,145 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSleeps makes t wait instantly, recording how long it was asked to
+func recordSleeps(t *RetryTransport) *[]time.Duration {
+	var sleeps []time.Duration
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return &sleeps
+}
+
+func TestRetryTransport(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name     string
+		status   int
+		header   map[string]string
+		body     string
+		retried  bool
+		minSleep time.Duration
+		maxSleep time.Duration
+	}{
+		{"429 with Retry-After", http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}, "", true, 7 * time.Second, 7 * time.Second},
+		{"429 without hints", http.StatusTooManyRequests, nil, "", true, retryBaseDelay / 2, retryBaseDelay},
+		{"GitHub primary limit", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}, "", true, 25 * time.Second, 32 * time.Second},
+		{"GitLab limit", http.StatusTooManyRequests, map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": reset}, "", true, 25 * time.Second, 32 * time.Second},
+		{"GitHub secondary limit", http.StatusForbidden, nil, `{"message": "You have exceeded a secondary rate limit."}`, true, retryBaseDelay / 2, retryBaseDelay},
+		{"plain forbidden", http.StatusForbidden, nil, `{"message": "Must have admin rights"}`, false, 0, 0},
+		{"server error", http.StatusInternalServerError, nil, "", false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests > 1 {
+					_, _ = w.Write([]byte("ok"))
+					return
+				}
+				for name, value := range tt.header {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			transport := &RetryTransport{Base: http.DefaultTransport}
+			sleeps := recordSleeps(transport)
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if !tt.retried {
+				if requests != 1 || resp.StatusCode != tt.status || string(body) != tt.body {
+					t.Errorf("Expected the response to be returned untouched, got %d %q after %d requests", resp.StatusCode, body, requests)
+				}
+				return
+			}
+			if requests != 2 || resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected a successful retry, got %d after %d requests", resp.StatusCode, requests)
+			}
+			if len(*sleeps) != 1 || (*sleeps)[0] < tt.minSleep || (*sleeps)[0] > tt.maxSleep {
+				t.Errorf("Expected one wait between %s and %s, got %v", tt.minSleep, tt.maxSleep, *sleeps)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_Limits(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("Expected the body on every attempt, got %q", body)
+		}
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport := &RetryTransport{Base: http.DefaultTransport, MaxRetries: 2}
+	sleeps := recordSleeps(transport)
+	resp, err := (&http.Client{Transport: transport}).Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || requests != 3 || len(*sleeps) != 2 {
+		t.Errorf("Expected to give up after 2 retries, got %d after %d requests", resp.StatusCode, requests)
+	}
+
+	requests = 0
+	transport = &RetryTransport{Base: http.DefaultTransport, MaxWait: 500 * time.Millisecond}
+	recordSleeps(transport)
+	resp, err = (&http.Client{Transport: transport}).Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if requests != 1 {
+		t.Errorf("Expected no retry when the wait exceeds MaxWait, got %d requests", requests)
+	}
+}
+
+func TestRetryTransport_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = (&http.Client{Transport: &RetryTransport{Base: http.DefaultTransport}}).Do(req)
+	if err == nil {
+		t.Fatal("Expected the wait to be cancelled")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected cancellation to interrupt the wait, took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,23 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// TimingTransport records every request in the provider API timing phase
+type TimingTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *TimingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer timing.Track(timing.API, time.Now())
+
+	resp, err := t.Base.RoundTrip(req)
+	if err == nil && resp.Header.Get(CacheHeader) == "hit" {
+		timing.CacheHit()
+	}
+	return resp, err
+}
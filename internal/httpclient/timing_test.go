@@ -0,0 +1,36 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitstuff/internal/timing"
+)
+
+func TestTimingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	timing.Start()
+	client := New(Options{CacheDir: t.TempDir()})
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	report := timing.Snapshot()
+	if api := report.Get(timing.API); api.Count != 3 {
+		t.Errorf("Expected 3 API requests, got %d", api.Count)
+	}
+	if report.CacheHits != 2 {
+		t.Errorf("Expected 2 requests served from cache, got %d", report.CacheHits)
+	}
+}
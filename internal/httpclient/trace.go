@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitstuff/internal/redact"
+	"gitstuff/internal/verbosity"
+)
+
+// headersToTrace are response headers describing pagination, rate limits
+// and caching that are useful when debugging provider behaviour
+var headersToTrace = []string{
+	"Link",
+	"X-Page", "X-Next-Page", "X-Total-Pages", "X-Total",
+	"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-RateLimit-Resource",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+	"Retry-After",
+	CacheHeader,
+}
+
+// TraceTransport logs every request and response at trace verbosity
+type TraceTransport struct {
+	Base http.RoundTripper
+}
+
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !verbosity.IsEnabled(verbosity.TraceLevel) {
+		return t.Base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	verbosity.Trace("HTTP %s %s", req.Method, redactURL(req.URL))
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		verbosity.TraceTiming(start, "HTTP %s %s failed: %v", req.Method, redactURL(req.URL), redact.Error(err))
+		return nil, err
+	}
+
+	verbosity.TraceTiming(start, "HTTP %s %s -> %s%s", req.Method, redactURL(req.URL), resp.Status, formatTraceHeaders(resp.Header))
+	return resp, nil
+}
+
+func formatTraceHeaders(header http.Header) string {
+	var parts []string
+	for _, name := range headersToTrace {
+		if value := header.Get(name); value != "" {
+			parts = append(parts, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, " ") + "]"
+}
+
+// redactURL returns u as a string with credentials masked
+func redactURL(u *url.URL) string {
+	return redact.URL(u)
+}
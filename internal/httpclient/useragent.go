@@ -0,0 +1,17 @@
+package httpclient
+
+import "net/http"
+
+// UserAgentTransport identifies gitstuff to providers and proxies by setting
+// the User-Agent header, overriding the default set by the API libraries
+type UserAgentTransport struct {
+	Base      http.RoundTripper
+	UserAgent string
+}
+
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.UserAgent)
+	return t.Base.RoundTrip(req)
+}
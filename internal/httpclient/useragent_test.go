@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgentTransport(t *testing.T) {
+	var gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAgent = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	client := New(Options{UserAgent: "gitstuff/1.2.3 acme-audit"})
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", "go-gitlab")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAgent != "gitstuff/1.2.3 acme-audit" {
+		t.Errorf("Expected User-Agent to be overridden, got %q", gotAgent)
+	}
+	if req.Header.Get("User-Agent") != "go-gitlab" {
+		t.Error("Expected original request to be left unmodified")
+	}
+}
+
+func TestNew_NoUserAgent(t *testing.T) {
+	var gotAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAgent = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", "go-github")
+
+	resp, err := New(Options{}).Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAgent != "go-github" {
+		t.Errorf("Expected User-Agent to be untouched, got %q", gotAgent)
+	}
+}
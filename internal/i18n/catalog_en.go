@@ -0,0 +1,434 @@
+package i18n
+
+var english = map[string]string{
+	"field.web_url":        "Web URL: %s",
+	"field.ssh_url":        "SSH URL: %s",
+	"field.clone_url":      "Clone URL: %s",
+	"field.default_branch": "Default Branch: %s",
+	"field.provider":       "Provider: %s",
+
+	"list.found":           "Found %d repositories:",
+	"list.tree_header":     "Repository tree structure:",
+	"list.provider_header": "=== %s Provider ===",
+	"list.tree_error":      "Error building tree for %s: %v",
+	"list.filtered_by":     "(filtered by group: %s)",
+	"list.root_repos":      "Root repositories:",
+	"list.group_not_found": "Group '%s' not found in %s",
+
+	"status.label":              "Status:",
+	"status.error_checking":     "Error checking status: %v",
+	"status.error":              "Error: %v",
+	"status.not_cloned":         "Not cloned",
+	"status.not_git_repo_short": "Not a git repo",
+	"status.not_git_repo":       "Directory exists but not a git repository",
+	"status.cloned":             "Cloned",
+	"status.branch":             "(branch: %s)",
+	"status.has_changes":        "Has uncommitted changes",
+	"status.clean":              "clean",
+	"status.uncommitted":        "uncommitted changes",
+	"status.no_upstream":        "no upstream",
+	"status.stashed":            "%d stashed",
+	"status.found_local":        "Found %d local repositories in %s:",
+	"status.summary":            "Summary: %d repositories, %d need attention",
+
+	"clone.found_all":        "Found %d repositories to clone/update",
+	"clone.found_group":      "Found %d repositories in group '%s' to clone/update",
+	"clone.found_provider":   "Found %d repositories in %s provider",
+	"clone.provider_error":   "Error getting repositories from %s provider: %v",
+	"clone.summary":          "Summary: %d successful, %d failed",
+	"clone.processing":       "Processing %s [%s]...",
+	"clone.already_cloned":   "Already cloned (use --update to pull latest changes)",
+	"clone.skipped_dirty":    "Skipped: repository has uncommitted changes",
+	"clone.pulling":          "Pulling latest changes...",
+	"clone.pull_failed":      "Failed to pull: %v",
+	"clone.updated":          "Updated successfully",
+	"clone.cloning":          "Cloning from %s...",
+	"clone.clone_failed":     "Failed to clone: %v",
+	"clone.cloned":           "Cloned successfully",
+	"clone.found_repository": "Found repository: %s [%s]",
+	"clone.repo_updated":     "Repository updated successfully",
+	"clone.repo_exists":      "Repository already cloned at: %s",
+	"clone.use_update":       "Use --update flag to pull latest changes",
+	"clone.cloning_to":       "Cloning from %s to %s...",
+	"clone.repo_cloned":      "Repository cloned successfully",
+
+	"sync.none_found":      "No repositories found",
+	"sync.syncing":         "Syncing %d repositories",
+	"sync.plan_header":     "Sync plan for %d repositories (dry run):",
+	"sync.action_clone":    "clone",
+	"sync.action_pull":     "pull",
+	"sync.action_skip":     "skip",
+	"sync.action_error":    "error",
+	"sync.uncommitted":     "(uncommitted changes)",
+	"sync.plan_summary":    "Would clone %d, pull %d, skip %d, error %d",
+	"sync.summary_header":  "Sync summary:",
+	"sync.summary_cloned":  "Cloned:  %d",
+	"sync.summary_updated": "Updated: %d",
+	"sync.summary_skipped": "Skipped (uncommitted changes): %d",
+	"sync.summary_failed":  "Failed:  %d",
+	"sync.dirty_header":    "Skipped repositories with uncommitted changes:",
+	"sync.failed_header":   "Failed repositories:",
+
+	"verify.header":   "Verifying %d repositories in %s with git fsck:",
+	"verify.ok":       "ok",
+	"verify.corrupt":  "corrupt (%v)",
+	"verify.warnings": "ok with warnings",
+	"verify.summary":  "Summary: %d repositories checked, %d corrupt, %d with warnings",
+
+	"remote.added":   "Added remote %s (%s)",
+	"remote.updated": "Updated remote %s to %s",
+	"remote.failed":  "Could not configure remote %s: %v",
+
+	"clone.protected_refused": "Not pulling: %s is the default branch and has %d local commit(s) that would need a merge commit",
+	"clone.protected_hint":    "Move them to a branch (git switch -c <branch> && git branch -f %s @{upstream}) or rebase them (git pull --rebase)",
+	"clone.rebasing":          "Rebasing %d local commit(s) onto the latest changes...",
+	"sync.local_commits":      "(local commits on default branch)",
+	"sync.summary_protected":  "Skipped (local commits on default branch): %d",
+	"sync.protected_header":   "Skipped repositories with local commits on the default branch:",
+
+	"owners.header":         "Code owners across %d repositories:",
+	"owners.repo_count":     "%d repositories",
+	"owners.owned_header":   "Repositories owned by %s:",
+	"owners.owned_none":     "No repositories are owned by %s",
+	"owners.owned_summary":  "%s owns code in %d of %d repositories",
+	"owners.all_owned":      "All %d repositories have code owners",
+	"owners.unowned_header": "Repositories without owners (%d of %d):",
+	"owners.no_file":        "no CODEOWNERS file",
+	"owners.no_owners":      "%s names no owners",
+	"owners.read_failed":    "CODEOWNERS could not be read (%v)",
+
+	"browse.header":      "gitstuff browse - %d repositories, %d selected",
+	"browse.filter":      "Filter: %s",
+	"browse.no_matches":  "No repositories match the filter",
+	"browse.help":        "↑/↓ move  space select  a select all  / filter  c clone  p pull  o open  q quit",
+	"browse.loading":     "Loading repositories from %d providers...",
+	"browse.none":        "No repositories found",
+	"browse.opening":     "Opening %s",
+	"browse.open_failed": "Could not open %s: %v",
+	"browse.not_cloned":  "Skipping %d repositories that are not cloned yet",
+
+	"scan.header":  "Scanning %d repositories for secrets with %s:",
+	"scan.found":   "%d possible secrets",
+	"scan.failed":  "scan failed (%v)",
+	"scan.clean":   "No secrets found",
+	"scan.summary": "Summary: %d possible secrets in %d of %d repositories",
+
+	"audit.header":     "Auditing files in %d repositories:",
+	"audit.missing":    "missing: %s",
+	"audit.drifted":    "differs from template: %s",
+	"audit.fixed":      "Committed fixes to branch %s",
+	"audit.fix_failed": "Could not create fix-up branch: %v",
+	"audit.clean":      "All repositories match the audit templates",
+	"audit.summary":    "Summary: %d repositories audited, %d with drift, %d not cloned",
+
+	"apply.header":          "Applying changes on branch %s to %d repositories:",
+	"apply.failed":          "failed (%v)",
+	"apply.would_change":    "would change:",
+	"apply.committed":       "committed to %s",
+	"apply.pushed":          "pushed %s",
+	"apply.opened":          "opened %s",
+	"apply.summary":         "Summary: %d changed, %d unchanged, %d not cloned, %d failed",
+	"apply.summary_dry_run": "Dry run: %d would change, %d unchanged, %d not cloned, %d failed",
+	"apply.requests_header": "Opened %d merge requests:",
+
+	"migrate.none":         "No repositories found in the legacy layout",
+	"migrate.plan_header":  "Found %d repositories in the legacy layout:",
+	"migrate.would_move":   "move %s [%s]",
+	"migrate.paths":        "%s -> %s",
+	"migrate.collision":    "skipped, %s already exists",
+	"migrate.ambiguous":    "skipped, %s could belong to more than one repository",
+	"migrate.plan_summary": "Would move %d, skip %d",
+	"migrate.moved":        "%s [%s] moved to %s",
+	"migrate.failed":       "move failed (%v)",
+	"migrate.summary":      "Summary: %d moved, %d skipped, %d failed",
+
+	"tag.not_cloned":  "Skipping %d repositories that are not cloned",
+	"tag.checking":    "Checking %d repositories for tag %s:",
+	"tag.will_create": "tag %s",
+	"tag.will_push":   "push existing tag at %s",
+	"tag.already":     "already has %s",
+	"tag.conflict":    "%s exists at %s, expected %s",
+	"tag.failed":      "cannot be tagged (%v)",
+	"tag.push_failed": "push failed (%v)",
+	"tag.summary":     "Summary for %s: %d tagged, %d already tagged, %d failed",
+
+	"exec.summary":   "Summary: passed in %d repositories, failed in %d",
+	"exec.exit_code": "exit code %d",
+
+	"webhook.header":          "Webhooks for %s on %d repositories:",
+	"webhook.added":           "webhook added",
+	"webhook.would_add":       "would add webhook",
+	"webhook.removed":         "%d webhooks removed",
+	"webhook.would_remove":    "would remove %d webhooks",
+	"webhook.unsupported":     "%s provider cannot manage webhooks",
+	"webhook.failed":          "failed (%v)",
+	"webhook.summary":         "Summary: %d changed, %d already up to date, %d unsupported, %d failed",
+	"webhook.summary_dry_run": "Dry run: %d would change, %d already up to date, %d unsupported, %d failed",
+
+	"prune.none":            "Every local clone belongs to a provider repository",
+	"prune.header":          "Found %d local clones without a provider repository:",
+	"prune.moved":           "moved to %s [%s]",
+	"prune.deleted":         "no longer exists on any provider",
+	"prune.summary":         "Summary: %d deleted, %d moved",
+	"prune.confirm_remove":  "Delete %d clones?",
+	"prune.confirm_archive": "Move %d clones to %s?",
+	"prune.cancelled":       "Cancelled, nothing was changed",
+	"prune.failed":          "failed (%v)",
+	"prune.skipped_dirty":   "skipped, has uncommitted changes, unpushed commits or stashes (use --force)",
+	"prune.removed":         "Removed %d clones, skipped %d, failed %d",
+	"prune.archived":        "Archived %d clones, skipped %d, failed %d",
+
+	"sync.action_move":          "move",
+	"sync.moved_from":           "(from %s)",
+	"sync.plan_moves":           "Would move %d clones of renamed repositories",
+	"relocate.header":           "Found %d repositories that were renamed or moved since they were cloned:",
+	"relocate.confirm":          "Move the %d clones to the new paths and update their remotes?",
+	"relocate.not_asked":        "Leaving them alone (run with --move-renamed to move the clones)",
+	"relocate.moved":            "moved to %s",
+	"relocate.failed":           "could not move clone (%v)",
+	"relocate.state_unreadable": "Ignoring repository state: %v",
+	"relocate.state_unwritable": "Could not save repository state: %v",
+
+	"clone.protocol_retry": "Retrying over %s: %s",
+
+	"interrupt.stopping":        "Interrupted, not starting any more repositories (press Ctrl-C again to abort the ones in progress)",
+	"interrupt.aborting":        "Aborting the git commands still running",
+	"clone.summary_interrupted": "Interrupted: %d repositories were not cloned or updated",
+	"sync.summary_interrupted":  "Interrupted:   %d",
+
+	"browse.readme_loading": "Loading README...",
+	"browse.readme_none":    "No README",
+	"browse.readme_failed":  "Could not load README: %v",
+	"field.readme":          "README:",
+
+	"restructure.none":              "Every clone is at its repository's current path",
+	"restructure.header":            "Found %d clones of repositories that moved on the provider:",
+	"restructure.group_count":       "(%d repositories)",
+	"restructure.apply_hint":        "Run with --apply to move the clones",
+	"restructure.summary":           "Summary: %d moved, %d failed",
+	"sync.moved_to":                 "(moves to %s)",
+	"daemon.listening":              "Serving status at http://%s/status",
+	"daemon.started":                "Syncing every %s",
+	"daemon.next_run":               "Next run at %s",
+	"daemon.run_started":            "Sync run %d started",
+	"daemon.run_failed":             "Sync run %d failed: %s",
+	"daemon.run_empty":              "Sync run %d found no repositories",
+	"daemon.run_finished":           "Sync run %d finished in %s: %d cloned, %d updated, %d skipped, %d failed",
+	"error.provider":                "error from %s provider: %s",
+	"error.unauthorized":            "authentication failed (HTTP %d)",
+	"error.forbidden":               "access denied (HTTP %d)",
+	"error.not_found":               "not found (HTTP %d)",
+	"error.rate_limited":            "rate limited by the provider (HTTP %d)",
+	"error.server":                  "the provider reported a server error (HTTP %d)",
+	"hint.unauthorized":             "The token may be invalid, expired or revoked; update it with 'gitstuff config edit <provider> --token <token>'",
+	"hint.forbidden":                "The token may be missing a scope (read_api on GitLab, repo on GitHub); run 'gitstuff doctor' to see its scopes",
+	"hint.not_found":                "Check the provider URL and the group or organization name, and that the token can see it",
+	"hint.rate_limited":             "Wait for the limit to reset; 'gitstuff doctor' shows when that will be",
+	"hint.server":                   "The provider may be having an outage; try again later",
+	"clone.switching":               "Switching from %s to the default branch %s",
+	"clone.switch_failed":           "Failed to switch branch: %v",
+	"sync.summary_switched":         "Switched to default branch: %d",
+	"checkout.none_cloned":          "No cloned repositories found",
+	"checkout.switching":            "Returning %d repositories to their default branch",
+	"checkout.summary_header":       "Checkout summary:",
+	"checkout.summary_pulled":       "Already on default branch, pulled: %d",
+	"provider.version_unsupported":  "version %s is older than %s, the oldest version gitstuff supports; some commands may fail with errors such as 404 Not Found",
+	"clone.fetching":                "Fetching...",
+	"clone.fetched":                 "Fetched successfully",
+	"clone.fetch_failed":            "Failed to fetch: %v",
+	"sync.summary_fetched":          "Fetched: %d",
+	"branches.default":              "default: %s",
+	"branches.no_default":           "no default branch found",
+	"branches.upstream_gone":        "upstream gone",
+	"branches.is_default":           "default",
+	"branches.merged":               "merged",
+	"branches.unmerged":             "unmerged",
+	"branches.age":                  "%s ago",
+	"branches.none":                 "No branches match the filters",
+	"branches.summary":              "Summary: %d branches in %d repositories, %d not merged into the default branch",
+	"setup.running":                 "Running setup: %s",
+	"setup.failed":                  "Setup failed: %v",
+	"manifest.updating":             "Updating manifest repository %s",
+	"manifest.unmatched":            "The manifest lists %s, which matches no repository in %s",
+	"manifest.selected":             "The manifest of %s selects %d repositories",
+	"setup.done":                    "Setup finished",
+	"search.found":                  "Found %d repositories matching \"%s\":",
+	"search.none":                   "No repositories match \"%s\"",
+	"search.cloned":                 "cloned",
+	"search.not_cloned":             "not cloned",
+	"search.provider_error":         "Could not search %s provider: %s",
+	"search.all_cloned":             "All matching repositories are already cloned",
+	"search.cloning":                "Cloning %d matching repositories",
+	"grep.none":                     "No matches in %d repositories",
+	"grep.summary":                  "%d matching lines in %d files of %d repositories (%d searched)",
+	"grep.summary_files":            "%d matching files in %d repositories (%d searched)",
+	"grep.failed":                   "Search failed in %d repositories",
+	"export.skipped_missing":        "Skipping %d repositories that are not cloned",
+	"export.exporting":              "Exporting %d repositories to %s",
+	"export.summary":                "Exported %d repositories, %d failed; checksums in %s",
+	"maintenance.summary":           "Maintenance changed for %d repositories, %d failed",
+	"maintenance.schedule_failed":   "Could not schedule background maintenance: %v",
+	"maintenance.unschedule_failed": "Could not remove the background maintenance schedule: %v",
+	"maintenance.on":                "enabled",
+	"maintenance.off":               "disabled",
+	"maintenance.status_summary":    "%d of %d repositories have background maintenance",
+	"maintenance.new_clones_failed": "Could not enable background maintenance for %d new clones, run 'gitstuff maintenance enable' to retry",
+	"create.created":                "Created %s (%s)",
+	"create.initialized":            "Initialized a git repository in %s",
+	"create.pushed":                 "Pushed %s to %s",
+	"fork.forked":                   "Forked %s to %s",
+	"fork.no_upstream":              "The provider did not report where %s was forked from, no upstream remote added",
+	"fork.done":                     "Fork ready in %s",
+	"excludes.summary":              "Updated %d repositories, %d already up to date, %d failed",
+	"excludes.failed":               "Could not update the managed excludes of %s: %v",
+	"archive.confirm":               "Archive %d repositories on their provider? They become read-only for everyone",
+	"archive.archived":              "Archived %s [%s]",
+	"archive.unarchived":            "Unarchived %s [%s]",
+	"archive.moved":                 "Moved the clone to %s",
+	"archive.no_clone":              "No local clone to move",
+	"sync.summary_visibility":       "Visibility:    %d changed",
+	"sync.visibility_header":        "Repositories whose visibility changed since the last sync:",
+	"sync.visibility_changed":       "%s [%s]: %s → %s",
+	"sync.visibility_exposed":       "%s (now public, check this was intended)",
+	"access.header_first":           "Taking the first access snapshot of %d repositories",
+	"access.header":                 "Comparing the access to %d repositories with the snapshot of %s",
+	"access.first_snapshot":         "Saved the access snapshot to %s; run again later to see what changed",
+	"access.unchanged":              "✅ No access changes",
+	"access.summary":                "%d repositories changed, %d changes need review, %d new repositories, %d unsupported, %d failed",
+	"access.added":                  "%s: added as %s",
+	"access.removed":                "%s: removed (was %s)",
+	"access.changed":                "%s: %s → %s",
+	"access.external":               "(external)",
+	"wip.files":                     "%d files, +%d -%d",
+	"wip.untracked":                 "(%d untracked)",
+	"wip.oldest":                    "oldest change %s ago: %s",
+	"wip.none":                      "✅ No uncommitted changes",
+	"wip.summary":                   "%d of %d repositories have uncommitted changes",
+	"issues.none":                   "No open issues assigned to you",
+	"issues.found":                  "%d open issues assigned to you:",
+	"issues.updated":                "updated %s ago",
+	"issues.provider_error":         "Could not list %s issues: %s",
+	"ci.label":                      "CI:",
+	"ci.success":                    "passing",
+	"ci.failed":                     "failing",
+	"ci.running":                    "running",
+	"ci.canceled":                   "canceled",
+	"ci.none":                       "no pipelines",
+	"ci.unsupported":                "not supported by this provider",
+	"ci.web_url":                    "Pipeline: %s",
+	"ci.summary":                    "CI: %d passing, %d failing, %d running",
+	"webhook.list_header":           "Webhooks of %d repositories:",
+	"webhook.list_summary":          "Summary: %d webhooks on %d of %d repositories, %d unsupported, %d failed",
+	"clone.reference":               "Borrowing objects from %s",
+	"list.active":                   "active %s ago",
+	"retention.header":              "Retention:",
+	"retention.dry_run_header":      "Retention would remove:",
+	"retention.archived":            "archived, inactive for %s",
+	"retention.old_backup":          "older than the newest %d",
+	"retention.kept_local_work":     "kept as it has uncommitted changes, unpushed commits or stashes",
+	"retention.kept_unreadable":     "kept as its local work could not be checked: %v",
+	"retention.failed":              "could not remove: %v",
+	"sync.summary_removed":          "Removed by retention: %d",
+	"daemon.removed":                "Removed %s (%s)",
+	"last.none":                     "No clone, sync or daemon run recorded in %s yet",
+	"last.header":                   "Last run: %s, started %s, took %s (%s ago)",
+	"last.error":                    "Stopped before processing repositories: %s",
+	"last.skipped":                  "Skipped (already cloned): %d",
+	"last.slowest":                  "Slowest repositories:",
+	"last.unwritable":               "Could not record the summary of this run: %v",
+	"list.stale":                    "%s provider unavailable (%s); using its repositories as cached %s ago, which may be out of date",
+	"list.stale_marker":             "(cached)",
+	"list.bucket":                   "📂 %s (%d)",
+	"list.bucket_no_language":       "(unknown language)",
+	"list.bucket_no_topic":          "(no topics)",
+	"list.bucket_none":              "(none)",
+	"hint.not_cloned":               "%d of %d repositories are not cloned; preview cloning them with 'gitstuff sync --dry-run' or run 'gitstuff clone --all'",
+	"hint.dirty":                    "%d repositories have uncommitted changes; 'gitstuff wip' shows what changed in each",
+	"hint.long_list":                "%d repositories listed; try 'gitstuff list --tree' or 'gitstuff list --group-by owner' to arrange them",
+	"hint.sync_failed":              "'gitstuff last' shows why repositories failed, even after the output has scrolled away",
+	"hint.sync_dirty":               "%d repositories were skipped for uncommitted changes; 'gitstuff sync --autostash' updates them too",
+	"selftest.kept":                 "Keeping the selftest files in %s",
+	"selftest.providers":            "Started fake GitLab and GitHub providers",
+	"selftest.list":                 "Listed repositories",
+	"selftest.clone":                "Cloned repositories",
+	"selftest.sync":                 "Synced a new commit",
+	"selftest.passed":               "All selftest steps passed",
+	"field.note":                    "Note: %s",
+	"field.labels":                  "Labels: %s",
+	"note.none":                     "%s has no note or labels",
+	"note.saved":                    "Updated the note of %s",
+	"note.cleared":                  "Removed the note and labels of %s",
+	"clone.summary_stopped":         "Stopped after the first failure: %d repositories were not cloned or updated",
+	"clone.summary_unwritable":      "Could not write the run summary: %v",
+	"clone.some_failed":             "%d of %d repositories failed",
+	"clone.all_failed":              "all %d repositories failed",
+	"clone.retrying":                "Attempt %d of %d failed, retrying in %s",
+	"sync.retried_header":           "Retried after transient failures:",
+	"sync.retried_attempts":         "%d attempts",
+	"prune.unpushed":                "(%d unpushed commits)",
+	"prune.stashed":                 "(%d stashes)",
+	"config.removed":                "Removed provider %s",
+	"config.updated":                "Updated provider %s",
+	"config.no_providers":           "No providers configured in %s",
+	"config.providers_header":       "Providers in %s:",
+	"config.field_url":              "URL:   %s",
+	"config.field_token":            "Token: %s",
+	"config.field_group":            "Group: %s",
+	"config.field_protocol":         "Protocol: %s",
+	"config.field_ssh_host":         "SSH host: %s",
+	"config.field_insecure":         "Insecure: true (TLS verification disabled)",
+	"config.field_base_dir":         "Base directory: %s",
+	"config.token_keychain":         "(OS keychain)",
+	"config.token_command":          "(output of: %s)",
+	"config.token_not_set":          "(not set)",
+	"token.stored":                  "Stored the new token for provider %s",
+	"token.create_at":               "Create a token with the %s scopes at:",
+	"token.opened":                  "Opened %s",
+	"token.create_there":            "Create the token there; the %s scopes are already selected",
+	"token.paste":                   "Paste the token:",
+	"token.works":                   "The token works for %s",
+	"token.use_anyway":              "Use this token anyway?",
+	"config.no_plaintext_tokens":    "No plaintext tokens to migrate",
+	"config.token_migrated":         "Moved token for %s to the keyring",
+	"config.detect_failed":          "Could not detect the provider type: %v",
+	"config.detected":               "Detected %s at %s",
+	"config.field_workspaces":       "Workspaces: %s",
+	"doctor.checking":               "Checking %d providers:",
+	"doctor.user":                   "User: %s",
+	"doctor.version":                "Version: %s",
+	"doctor.latency":                "Latency: %dms",
+	"doctor.rate_limit":             "Rate limit: %d/%d remaining",
+	"doctor.scopes":                 "Token scopes: %s",
+	"doctor.scopes_none":            "(none)",
+	"doctor.error":                  "Error: %s",
+	"doctor.hint":                   "Hint: %s",
+	"doctor.healthy":                "All providers are reachable",
+	"doctor.unhealthy":              "One or more providers are unreachable",
+	"doctor.lacks_read_api":         "Token lacks the read_api scope; repositories and groups cannot be listed",
+	"doctor.lacks_read_repository":  "Token lacks the read_repository scope; cloning over HTTPS with this token will fail",
+	"doctor.only_public_repo":       "Token only has the public_repo scope; private repositories will not be listed",
+	"doctor.lacks_repo":             "Token lacks the repo scope; private repositories will not be listed or cloned",
+	"doctor.hint_unauthorized":      "The token was rejected; it may be invalid, expired or revoked. Update it with 'gitstuff config edit %s --token <token>'",
+	"doctor.hint_forbidden":         "The token is not allowed to read the current user; check its scopes and that it has not been restricted by an administrator",
+	"doctor.hint_not_found":         "No API was found at this URL; it should point at the instance root, e.g. https://gitlab.example.com",
+	"doctor.hint_rate_limited":      "The provider is rate limiting requests; try again later",
+	"doctor.hint_server":            "The provider reported a server error; try again later or check its status page",
+	"doctor.hint_dns":               "The host name could not be resolved; check the provider URL and your network or VPN connection",
+	"doctor.hint_tls_insecure":      "The TLS handshake failed even with certificate verification disabled",
+	"doctor.hint_tls":               "The server certificate is not trusted; for a self-signed certificate run 'gitstuff config edit %s --insecure'",
+	"doctor.hint_timeout":           "The request timed out; check your network, VPN or proxy settings",
+	"doctor.hint_refused":           "The connection was refused; check the host and port in the provider URL",
+	"config.available_providers":    "Available SCM providers:",
+	"config.select_provider":        "Select a provider (1-2):",
+	"config.prompt_name":            "Provider name (identifier for this %s instance):",
+	"config.prompt_gitlab_url":      "GitLab URL (e.g., https://gitlab.com or gitlab.example.com):",
+	"config.prompt_github_url":      "GitHub URL (leave blank for github.com or enter GitHub Enterprise URL):",
+	"config.prompt_gitlab_token":    "GitLab Access Token (leave blank to create one in the browser):",
+	"config.prompt_github_token":    "GitHub Personal Access Token (leave blank to create one in the browser):",
+	"config.prompt_base_dir":        "Base directory for repositories (default: ~/gitstuff-repos):",
+	"config.prompt_insecure":        "Skip SSL certificate verification?",
+	"config.prompt_gitlab_group":    "Default GitLab group to filter repositories (optional, leave blank for all):",
+	"config.prompt_github_org":      "Default GitHub organization to filter repositories (optional, leave blank for all):",
+	"config.prompt_another":         "Would you like to add another provider?",
+	"config.complete":               "Configuration complete!",
+}
@@ -0,0 +1,434 @@
+package i18n
+
+var spanish = map[string]string{
+	"field.web_url":        "URL web: %s",
+	"field.ssh_url":        "URL SSH: %s",
+	"field.clone_url":      "URL de clonado: %s",
+	"field.default_branch": "Rama por defecto: %s",
+	"field.provider":       "Proveedor: %s",
+
+	"list.found":           "Se encontraron %d repositorios:",
+	"list.tree_header":     "Estructura de repositorios:",
+	"list.provider_header": "=== Proveedor %s ===",
+	"list.tree_error":      "Error al construir el árbol de %s: %v",
+	"list.filtered_by":     "(filtrado por grupo: %s)",
+	"list.root_repos":      "Repositorios raíz:",
+	"list.group_not_found": "No se encontró el grupo '%s' en %s",
+
+	"status.label":              "Estado:",
+	"status.error_checking":     "Error al comprobar el estado: %v",
+	"status.error":              "Error: %v",
+	"status.not_cloned":         "No clonado",
+	"status.not_git_repo_short": "No es un repositorio git",
+	"status.not_git_repo":       "El directorio existe pero no es un repositorio git",
+	"status.cloned":             "Clonado",
+	"status.branch":             "(rama: %s)",
+	"status.has_changes":        "Tiene cambios sin confirmar",
+	"status.clean":              "limpio",
+	"status.uncommitted":        "cambios sin confirmar",
+	"status.no_upstream":        "sin upstream",
+	"status.stashed":            "%d en stash",
+	"status.found_local":        "Se encontraron %d repositorios locales en %s:",
+	"status.summary":            "Resumen: %d repositorios, %d requieren atención",
+
+	"clone.found_all":        "Se encontraron %d repositorios para clonar/actualizar",
+	"clone.found_group":      "Se encontraron %d repositorios en el grupo '%s' para clonar/actualizar",
+	"clone.found_provider":   "Se encontraron %d repositorios en el proveedor %s",
+	"clone.provider_error":   "Error al obtener repositorios del proveedor %s: %v",
+	"clone.summary":          "Resumen: %d correctos, %d fallidos",
+	"clone.processing":       "Procesando %s [%s]...",
+	"clone.already_cloned":   "Ya clonado (use --update para traer los últimos cambios)",
+	"clone.skipped_dirty":    "Omitido: el repositorio tiene cambios sin confirmar",
+	"clone.pulling":          "Trayendo los últimos cambios...",
+	"clone.pull_failed":      "Error al actualizar: %v",
+	"clone.updated":          "Actualizado correctamente",
+	"clone.cloning":          "Clonando desde %s...",
+	"clone.clone_failed":     "Error al clonar: %v",
+	"clone.cloned":           "Clonado correctamente",
+	"clone.found_repository": "Repositorio encontrado: %s [%s]",
+	"clone.repo_updated":     "Repositorio actualizado correctamente",
+	"clone.repo_exists":      "El repositorio ya está clonado en: %s",
+	"clone.use_update":       "Use la opción --update para traer los últimos cambios",
+	"clone.cloning_to":       "Clonando desde %s en %s...",
+	"clone.repo_cloned":      "Repositorio clonado correctamente",
+
+	"sync.none_found":      "No se encontraron repositorios",
+	"sync.syncing":         "Sincronizando %d repositorios",
+	"sync.plan_header":     "Plan de sincronización para %d repositorios (simulación):",
+	"sync.action_clone":    "clonar",
+	"sync.action_pull":     "traer",
+	"sync.action_skip":     "omitir",
+	"sync.action_error":    "error",
+	"sync.uncommitted":     "(cambios sin confirmar)",
+	"sync.plan_summary":    "Se clonarían %d, actualizarían %d, omitirían %d, con error %d",
+	"sync.summary_header":  "Resumen de sincronización:",
+	"sync.summary_cloned":  "Clonados:      %d",
+	"sync.summary_updated": "Actualizados:  %d",
+	"sync.summary_skipped": "Omitidos (cambios sin confirmar): %d",
+	"sync.summary_failed":  "Fallidos:      %d",
+	"sync.dirty_header":    "Repositorios omitidos con cambios sin confirmar:",
+	"sync.failed_header":   "Repositorios fallidos:",
+
+	"verify.header":   "Verificando %d repositorios en %s con git fsck:",
+	"verify.ok":       "correcto",
+	"verify.corrupt":  "dañado (%v)",
+	"verify.warnings": "correcto con advertencias",
+	"verify.summary":  "Resumen: %d repositorios verificados, %d dañados, %d con advertencias",
+
+	"remote.added":   "Remoto %s añadido (%s)",
+	"remote.updated": "Remoto %s actualizado a %s",
+	"remote.failed":  "No se pudo configurar el remoto %s: %v",
+
+	"clone.protected_refused": "Sin pull: %s es la rama por defecto y tiene %d commit(s) locales que requerirían un commit de merge",
+	"clone.protected_hint":    "Muévalos a una rama (git switch -c <rama> && git branch -f %s @{upstream}) o haga rebase (git pull --rebase)",
+	"clone.rebasing":          "Aplicando rebase de %d commit(s) locales sobre los últimos cambios...",
+	"sync.local_commits":      "(commits locales en la rama por defecto)",
+	"sync.summary_protected":  "Omitidos (commits locales en la rama por defecto): %d",
+	"sync.protected_header":   "Repositorios omitidos con commits locales en la rama por defecto:",
+
+	"owners.header":         "Propietarios del código en %d repositorios:",
+	"owners.repo_count":     "%d repositorios",
+	"owners.owned_header":   "Repositorios de %s:",
+	"owners.owned_none":     "Ningún repositorio pertenece a %s",
+	"owners.owned_summary":  "%s es propietario de código en %d de %d repositorios",
+	"owners.all_owned":      "Los %d repositorios tienen propietarios",
+	"owners.unowned_header": "Repositorios sin propietarios (%d de %d):",
+	"owners.no_file":        "sin archivo CODEOWNERS",
+	"owners.no_owners":      "%s no nombra propietarios",
+	"owners.read_failed":    "no se pudo leer CODEOWNERS (%v)",
+
+	"browse.header":      "gitstuff browse - %d repositorios, %d seleccionados",
+	"browse.filter":      "Filtro: %s",
+	"browse.no_matches":  "Ningún repositorio coincide con el filtro",
+	"browse.help":        "↑/↓ mover  espacio seleccionar  a todos  / filtrar  c clonar  p actualizar  o abrir  q salir",
+	"browse.loading":     "Cargando repositorios de %d proveedores...",
+	"browse.none":        "No se encontraron repositorios",
+	"browse.opening":     "Abriendo %s",
+	"browse.open_failed": "No se pudo abrir %s: %v",
+	"browse.not_cloned":  "Omitiendo %d repositorios que aún no están clonados",
+
+	"scan.header":  "Buscando secretos en %d repositorios con %s:",
+	"scan.found":   "%d posibles secretos",
+	"scan.failed":  "error al analizar (%v)",
+	"scan.clean":   "No se encontraron secretos",
+	"scan.summary": "Resumen: %d posibles secretos en %d de %d repositorios",
+
+	"audit.header":     "Auditando archivos en %d repositorios:",
+	"audit.missing":    "falta: %s",
+	"audit.drifted":    "difiere de la plantilla: %s",
+	"audit.fixed":      "Correcciones confirmadas en la rama %s",
+	"audit.fix_failed": "No se pudo crear la rama de corrección: %v",
+	"audit.clean":      "Todos los repositorios coinciden con las plantillas",
+	"audit.summary":    "Resumen: %d repositorios auditados, %d con diferencias, %d sin clonar",
+
+	"apply.header":          "Aplicando cambios en la rama %s a %d repositorios:",
+	"apply.failed":          "falló (%v)",
+	"apply.would_change":    "cambiaría:",
+	"apply.committed":       "confirmado en %s",
+	"apply.pushed":          "%s enviada",
+	"apply.opened":          "abierta %s",
+	"apply.summary":         "Resumen: %d cambiados, %d sin cambios, %d no clonados, %d fallidos",
+	"apply.summary_dry_run": "Simulación: %d cambiarían, %d sin cambios, %d no clonados, %d fallidos",
+	"apply.requests_header": "%d solicitudes de fusión abiertas:",
+
+	"migrate.none":         "No se encontraron repositorios con la estructura antigua",
+	"migrate.plan_header":  "Se encontraron %d repositorios con la estructura antigua:",
+	"migrate.would_move":   "mover %s [%s]",
+	"migrate.paths":        "%s -> %s",
+	"migrate.collision":    "omitido, %s ya existe",
+	"migrate.ambiguous":    "omitido, %s podría pertenecer a más de un repositorio",
+	"migrate.plan_summary": "Se moverían %d, se omitirían %d",
+	"migrate.moved":        "%s [%s] movido a %s",
+	"migrate.failed":       "no se pudo mover (%v)",
+	"migrate.summary":      "Resumen: %d movidos, %d omitidos, %d fallidos",
+
+	"tag.not_cloned":  "Omitiendo %d repositorios que no están clonados",
+	"tag.checking":    "Comprobando %d repositorios para la etiqueta %s:",
+	"tag.will_create": "etiquetar %s",
+	"tag.will_push":   "enviar la etiqueta existente en %s",
+	"tag.already":     "ya tiene %s",
+	"tag.conflict":    "%s existe en %s, se esperaba %s",
+	"tag.failed":      "no se puede etiquetar (%v)",
+	"tag.push_failed": "falló el envío (%v)",
+	"tag.summary":     "Resumen de %s: %d etiquetados, %d ya etiquetados, %d fallidos",
+
+	"exec.summary":   "Resumen: correcto en %d repositorios, falló en %d",
+	"exec.exit_code": "código de salida %d",
+
+	"webhook.header":          "Webhooks para %s en %d repositorios:",
+	"webhook.added":           "webhook añadido",
+	"webhook.would_add":       "se añadiría el webhook",
+	"webhook.removed":         "%d webhooks eliminados",
+	"webhook.would_remove":    "se eliminarían %d webhooks",
+	"webhook.unsupported":     "el proveedor %s no puede gestionar webhooks",
+	"webhook.failed":          "falló (%v)",
+	"webhook.summary":         "Resumen: %d cambiados, %d ya al día, %d no compatibles, %d fallidos",
+	"webhook.summary_dry_run": "Simulación: %d cambiarían, %d ya al día, %d no compatibles, %d fallidos",
+
+	"prune.none":            "Todos los clones locales pertenecen a un repositorio del proveedor",
+	"prune.header":          "Se encontraron %d clones locales sin repositorio en el proveedor:",
+	"prune.moved":           "movido a %s [%s]",
+	"prune.deleted":         "ya no existe en ningún proveedor",
+	"prune.summary":         "Resumen: %d eliminados, %d movidos",
+	"prune.confirm_remove":  "¿Eliminar %d clones?",
+	"prune.confirm_archive": "¿Mover %d clones a %s?",
+	"prune.cancelled":       "Cancelado, no se cambió nada",
+	"prune.failed":          "falló (%v)",
+	"prune.skipped_dirty":   "omitido, tiene cambios sin confirmar, commits sin subir o stashes (use --force)",
+	"prune.removed":         "Eliminados %d clones, omitidos %d, fallidos %d",
+	"prune.archived":        "Archivados %d clones, omitidos %d, fallidos %d",
+
+	"sync.action_move":          "mover",
+	"sync.moved_from":           "(desde %s)",
+	"sync.plan_moves":           "Se moverían %d clones de repositorios renombrados",
+	"relocate.header":           "Se encontraron %d repositorios renombrados o movidos desde que se clonaron:",
+	"relocate.confirm":          "¿Mover los %d clones a las rutas nuevas y actualizar sus remotos?",
+	"relocate.not_asked":        "Se dejan como están (ejecute con --move-renamed para mover los clones)",
+	"relocate.moved":            "movido a %s",
+	"relocate.failed":           "no se pudo mover el clon (%v)",
+	"relocate.state_unreadable": "Se ignora el estado de los repositorios: %v",
+	"relocate.state_unwritable": "No se pudo guardar el estado de los repositorios: %v",
+
+	"clone.protocol_retry": "Reintentando por %s: %s",
+
+	"interrupt.stopping":        "Interrumpido, no se empezarán más repositorios (pulsa Ctrl-C otra vez para abortar los que están en curso)",
+	"interrupt.aborting":        "Abortando los comandos git que siguen en ejecución",
+	"clone.summary_interrupted": "Interrumpido: %d repositorios no se clonaron ni actualizaron",
+	"sync.summary_interrupted":  "Interrumpidos: %d",
+
+	"browse.readme_loading": "Cargando README...",
+	"browse.readme_none":    "Sin README",
+	"browse.readme_failed":  "No se pudo cargar el README: %v",
+	"field.readme":          "README:",
+
+	"restructure.none":              "Todos los clones están en la ruta actual de su repositorio",
+	"restructure.header":            "Se encontraron %d clones de repositorios que se movieron en el proveedor:",
+	"restructure.group_count":       "(%d repositorios)",
+	"restructure.apply_hint":        "Ejecuta con --apply para mover los clones",
+	"restructure.summary":           "Resumen: %d movidos, %d fallidos",
+	"sync.moved_to":                 "(se mueve a %s)",
+	"daemon.listening":              "Estado disponible en http://%s/status",
+	"daemon.started":                "Sincronizando cada %s",
+	"daemon.next_run":               "Próxima ejecución a las %s",
+	"daemon.run_started":            "Sincronización %d iniciada",
+	"daemon.run_failed":             "Sincronización %d fallida: %s",
+	"daemon.run_empty":              "La sincronización %d no encontró repositorios",
+	"daemon.run_finished":           "Sincronización %d terminada en %s: %d clonados, %d actualizados, %d omitidos, %d fallidos",
+	"error.provider":                "error del proveedor %s: %s",
+	"error.unauthorized":            "la autenticación falló (HTTP %d)",
+	"error.forbidden":               "acceso denegado (HTTP %d)",
+	"error.not_found":               "no encontrado (HTTP %d)",
+	"error.rate_limited":            "el proveedor limitó las solicitudes (HTTP %d)",
+	"error.server":                  "el proveedor informó un error del servidor (HTTP %d)",
+	"hint.unauthorized":             "El token puede ser inválido, haber caducado o haber sido revocado; actualízalo con 'gitstuff config edit <proveedor> --token <token>'",
+	"hint.forbidden":                "Al token puede faltarle un permiso (read_api en GitLab, repo en GitHub); ejecuta 'gitstuff doctor' para ver sus permisos",
+	"hint.not_found":                "Comprueba la URL del proveedor y el nombre del grupo u organización, y que el token pueda verlo",
+	"hint.rate_limited":             "Espera a que se restablezca el límite; 'gitstuff doctor' muestra cuándo ocurrirá",
+	"hint.server":                   "El proveedor puede estar teniendo una interrupción; inténtalo más tarde",
+	"clone.switching":               "Cambiando de %s a la rama por defecto %s",
+	"clone.switch_failed":           "No se pudo cambiar de rama: %v",
+	"sync.summary_switched":         "Cambiados a la rama por defecto: %d",
+	"checkout.none_cloned":          "No se encontraron repositorios clonados",
+	"checkout.switching":            "Devolviendo %d repositorios a su rama por defecto",
+	"checkout.summary_header":       "Resumen del checkout:",
+	"checkout.summary_pulled":       "Ya en la rama por defecto, actualizados: %d",
+	"provider.version_unsupported":  "la versión %s es anterior a %s, la más antigua que gitstuff admite; algunos comandos pueden fallar con errores como 404 Not Found",
+	"clone.fetching":                "Obteniendo cambios...",
+	"clone.fetched":                 "Cambios obtenidos correctamente",
+	"clone.fetch_failed":            "No se pudieron obtener los cambios: %v",
+	"sync.summary_fetched":          "Obtenidos: %d",
+	"branches.default":              "predeterminada: %s",
+	"branches.no_default":           "no se encontró la rama predeterminada",
+	"branches.upstream_gone":        "upstream eliminado",
+	"branches.is_default":           "predeterminada",
+	"branches.merged":               "fusionada",
+	"branches.unmerged":             "sin fusionar",
+	"branches.age":                  "hace %s",
+	"branches.none":                 "Ninguna rama coincide con los filtros",
+	"branches.summary":              "Resumen: %d ramas en %d repositorios, %d sin fusionar en la rama predeterminada",
+	"setup.running":                 "Ejecutando la preparación: %s",
+	"setup.failed":                  "La preparación falló: %v",
+	"manifest.updating":             "Actualizando el repositorio del manifiesto %s",
+	"manifest.unmatched":            "El manifiesto incluye %s, que no coincide con ningún repositorio de %s",
+	"manifest.selected":             "El manifiesto de %s selecciona %d repositorios",
+	"setup.done":                    "Preparación terminada",
+	"search.found":                  "Se encontraron %d repositorios que coinciden con \"%s\":",
+	"search.none":                   "Ningún repositorio coincide con \"%s\"",
+	"search.cloned":                 "clonado",
+	"search.not_cloned":             "sin clonar",
+	"search.provider_error":         "No se pudo buscar en el proveedor %s: %s",
+	"search.all_cloned":             "Todos los repositorios encontrados ya están clonados",
+	"search.cloning":                "Clonando %d repositorios encontrados",
+	"grep.none":                     "Sin coincidencias en %d repositorios",
+	"grep.summary":                  "%d líneas coincidentes en %d archivos de %d repositorios (%d buscados)",
+	"grep.summary_files":            "%d archivos coincidentes en %d repositorios (%d buscados)",
+	"grep.failed":                   "La búsqueda falló en %d repositorios",
+	"export.skipped_missing":        "Omitiendo %d repositorios sin clonar",
+	"export.exporting":              "Exportando %d repositorios a %s",
+	"export.summary":                "Se exportaron %d repositorios, %d fallaron; sumas de verificación en %s",
+	"maintenance.summary":           "Mantenimiento cambiado en %d repositorios, %d fallaron",
+	"maintenance.schedule_failed":   "No se pudo programar el mantenimiento en segundo plano: %v",
+	"maintenance.unschedule_failed": "No se pudo quitar la programación del mantenimiento en segundo plano: %v",
+	"maintenance.on":                "activado",
+	"maintenance.off":               "desactivado",
+	"maintenance.status_summary":    "%d de %d repositorios tienen mantenimiento en segundo plano",
+	"maintenance.new_clones_failed": "No se pudo activar el mantenimiento en segundo plano en %d clones nuevos, ejecuta 'gitstuff maintenance enable' para reintentar",
+	"create.created":                "Creado %s (%s)",
+	"create.initialized":            "Inicializado un repositorio git en %s",
+	"create.pushed":                 "Enviada %s a %s",
+	"fork.forked":                   "Bifurcado %s en %s",
+	"fork.no_upstream":              "El proveedor no indicó de dónde se bifurcó %s, no se añadió el remoto upstream",
+	"fork.done":                     "Bifurcación lista en %s",
+	"excludes.summary":              "Actualizados %d repositorios, %d ya al día, %d fallaron",
+	"excludes.failed":               "No se pudieron actualizar las exclusiones gestionadas de %s: %v",
+	"archive.confirm":               "¿Archivar %d repositorios en su proveedor? Quedarán en solo lectura para todos",
+	"archive.archived":              "Archivado %s [%s]",
+	"archive.unarchived":            "Desarchivado %s [%s]",
+	"archive.moved":                 "Clon movido a %s",
+	"archive.no_clone":              "No hay clon local que mover",
+	"sync.summary_visibility":       "Visibilidad:   %d cambiadas",
+	"sync.visibility_header":        "Repositorios cuya visibilidad cambió desde la última sincronización:",
+	"sync.visibility_changed":       "%s [%s]: %s → %s",
+	"sync.visibility_exposed":       "%s (ahora público, compruebe que sea intencionado)",
+	"access.header_first":           "Tomando la primera instantánea de accesos de %d repositorios",
+	"access.header":                 "Comparando el acceso a %d repositorios con la instantánea del %s",
+	"access.first_snapshot":         "Instantánea de accesos guardada en %s; vuelva a ejecutar más tarde para ver los cambios",
+	"access.unchanged":              "✅ Sin cambios de acceso",
+	"access.summary":                "%d repositorios cambiados, %d cambios a revisar, %d repositorios nuevos, %d no soportados, %d fallidos",
+	"access.added":                  "%s: añadido como %s",
+	"access.removed":                "%s: eliminado (era %s)",
+	"access.changed":                "%s: %s → %s",
+	"access.external":               "(externo)",
+	"wip.files":                     "%d archivos, +%d -%d",
+	"wip.untracked":                 "(%d sin seguimiento)",
+	"wip.oldest":                    "cambio más antiguo hace %s: %s",
+	"wip.none":                      "✅ Sin cambios sin confirmar",
+	"wip.summary":                   "%d de %d repositorios tienen cambios sin confirmar",
+	"issues.none":                   "No tiene incidencias abiertas asignadas",
+	"issues.found":                  "%d incidencias abiertas asignadas a usted:",
+	"issues.updated":                "actualizada hace %s",
+	"issues.provider_error":         "No se pudieron listar las incidencias de %s: %s",
+	"ci.label":                      "CI:",
+	"ci.success":                    "correcto",
+	"ci.failed":                     "fallando",
+	"ci.running":                    "en curso",
+	"ci.canceled":                   "cancelado",
+	"ci.none":                       "sin pipelines",
+	"ci.unsupported":                "no disponible en este proveedor",
+	"ci.web_url":                    "Pipeline: %s",
+	"ci.summary":                    "CI: %d correctos, %d fallando, %d en curso",
+	"webhook.list_header":           "Webhooks de %d repositorios:",
+	"webhook.list_summary":          "Resumen: %d webhooks en %d de %d repositorios, %d sin soporte, %d con errores",
+	"clone.reference":               "Tomando prestados los objetos de %s",
+	"list.active":                   "activo hace %s",
+	"retention.header":              "Retención:",
+	"retention.dry_run_header":      "La retención eliminaría:",
+	"retention.archived":            "archivado, sin actividad desde hace %s",
+	"retention.old_backup":          "más antiguo que los %d más recientes",
+	"retention.kept_local_work":     "se conserva porque tiene cambios sin confirmar, commits sin subir o stashes",
+	"retention.kept_unreadable":     "se conserva porque no se pudo comprobar su trabajo local: %v",
+	"retention.failed":              "no se pudo eliminar: %v",
+	"sync.summary_removed":          "Eliminados por retención: %d",
+	"daemon.removed":                "Eliminado %s (%s)",
+	"last.none":                     "Aún no hay ninguna ejecución de clone, sync o daemon registrada en %s",
+	"last.header":                   "Última ejecución: %s, iniciada %s, duró %s (hace %s)",
+	"last.error":                    "Detenida antes de procesar repositorios: %s",
+	"last.skipped":                  "Omitidos (ya clonados): %d",
+	"last.slowest":                  "Repositorios más lentos:",
+	"last.unwritable":               "No se pudo registrar el resumen de esta ejecución: %v",
+	"list.stale":                    "Proveedor %s no disponible (%s); se usan sus repositorios en caché de hace %s, que pueden estar desactualizados",
+	"list.stale_marker":             "(en caché)",
+	"list.bucket":                   "📂 %s (%d)",
+	"list.bucket_no_language":       "(lenguaje desconocido)",
+	"list.bucket_no_topic":          "(sin temas)",
+	"list.bucket_none":              "(ninguno)",
+	"hint.not_cloned":               "%d de %d repositorios no están clonados; previsualiza la clonación con 'gitstuff sync --dry-run' o ejecuta 'gitstuff clone --all'",
+	"hint.dirty":                    "%d repositorios tienen cambios sin confirmar; 'gitstuff wip' muestra qué cambió en cada uno",
+	"hint.long_list":                "%d repositorios listados; prueba 'gitstuff list --tree' o 'gitstuff list --group-by owner' para organizarlos",
+	"hint.sync_failed":              "'gitstuff last' muestra por qué fallaron los repositorios, aunque la salida ya no esté visible",
+	"hint.sync_dirty":               "%d repositorios se omitieron por cambios sin confirmar; 'gitstuff sync --autostash' también los actualiza",
+	"selftest.kept":                 "Se conservan los archivos de la autoprueba en %s",
+	"selftest.providers":            "Proveedores falsos de GitLab y GitHub iniciados",
+	"selftest.list":                 "Repositorios listados",
+	"selftest.clone":                "Repositorios clonados",
+	"selftest.sync":                 "Nueva confirmación sincronizada",
+	"selftest.passed":               "Todos los pasos de la autoprueba se completaron",
+	"field.note":                    "Nota: %s",
+	"field.labels":                  "Etiquetas: %s",
+	"note.none":                     "%s no tiene nota ni etiquetas",
+	"note.saved":                    "Nota de %s actualizada",
+	"note.cleared":                  "Se eliminaron la nota y las etiquetas de %s",
+	"clone.summary_stopped":         "Detenido tras el primer fallo: %d repositorios no se clonaron ni actualizaron",
+	"clone.summary_unwritable":      "No se pudo escribir el resumen de la ejecución: %v",
+	"clone.some_failed":             "fallaron %d de %d repositorios",
+	"clone.all_failed":              "fallaron los %d repositorios",
+	"clone.retrying":                "Falló el intento %d de %d, reintentando en %s",
+	"sync.retried_header":           "Reintentados tras fallos transitorios:",
+	"sync.retried_attempts":         "%d intentos",
+	"prune.unpushed":                "(%d commits sin subir)",
+	"prune.stashed":                 "(%d stashes)",
+	"config.removed":                "Proveedor %s eliminado",
+	"config.updated":                "Proveedor %s actualizado",
+	"config.no_providers":           "No hay proveedores configurados en %s",
+	"config.providers_header":       "Proveedores en %s:",
+	"config.field_url":              "URL:   %s",
+	"config.field_token":            "Token: %s",
+	"config.field_group":            "Grupo: %s",
+	"config.field_protocol":         "Protocolo: %s",
+	"config.field_ssh_host":         "Host SSH: %s",
+	"config.field_insecure":         "Inseguro: sí (verificación TLS desactivada)",
+	"config.field_base_dir":         "Directorio base: %s",
+	"config.token_keychain":         "(llavero del sistema)",
+	"config.token_command":          "(salida de: %s)",
+	"config.token_not_set":          "(sin definir)",
+	"token.stored":                  "Token nuevo guardado para el proveedor %s",
+	"token.create_at":               "Crea un token con los permisos %s en:",
+	"token.opened":                  "Abierto %s",
+	"token.create_there":            "Crea el token allí; los permisos %s ya están seleccionados",
+	"token.paste":                   "Pega el token:",
+	"token.works":                   "El token funciona para %s",
+	"token.use_anyway":              "¿Usar este token de todos modos?",
+	"config.no_plaintext_tokens":    "No hay tokens en texto plano que migrar",
+	"config.token_migrated":         "Token de %s movido al llavero",
+	"config.detect_failed":          "No se pudo detectar el tipo de proveedor: %v",
+	"config.detected":               "Detectado %s en %s",
+	"config.field_workspaces":       "Espacios de trabajo: %s",
+	"doctor.checking":               "Comprobando %d proveedores:",
+	"doctor.user":                   "Usuario: %s",
+	"doctor.version":                "Versión: %s",
+	"doctor.latency":                "Latencia: %dms",
+	"doctor.rate_limit":             "Límite de peticiones: quedan %d/%d",
+	"doctor.scopes":                 "Permisos del token: %s",
+	"doctor.scopes_none":            "(ninguno)",
+	"doctor.error":                  "Error: %s",
+	"doctor.hint":                   "Sugerencia: %s",
+	"doctor.healthy":                "Todos los proveedores son accesibles",
+	"doctor.unhealthy":              "Uno o más proveedores no son accesibles",
+	"doctor.lacks_read_api":         "Al token le falta el permiso read_api; no se pueden listar repositorios ni grupos",
+	"doctor.lacks_read_repository":  "Al token le falta el permiso read_repository; clonar por HTTPS con este token fallará",
+	"doctor.only_public_repo":       "El token solo tiene el permiso public_repo; no se listarán los repositorios privados",
+	"doctor.lacks_repo":             "Al token le falta el permiso repo; no se listarán ni clonarán los repositorios privados",
+	"doctor.hint_unauthorized":      "El token fue rechazado; puede ser inválido, haber caducado o haber sido revocado. Actualízalo con 'gitstuff config edit %s --token <token>'",
+	"doctor.hint_forbidden":         "El token no puede leer el usuario actual; revisa sus permisos y que un administrador no lo haya restringido",
+	"doctor.hint_not_found":         "No se encontró ninguna API en esta URL; debe apuntar a la raíz de la instancia, p. ej. https://gitlab.example.com",
+	"doctor.hint_rate_limited":      "El proveedor está limitando las peticiones; inténtalo más tarde",
+	"doctor.hint_server":            "El proveedor informó de un error del servidor; inténtalo más tarde o consulta su página de estado",
+	"doctor.hint_dns":               "No se pudo resolver el nombre del host; revisa la URL del proveedor y tu conexión de red o VPN",
+	"doctor.hint_tls_insecure":      "La negociación TLS falló incluso con la verificación de certificados desactivada",
+	"doctor.hint_tls":               "El certificado del servidor no es de confianza; para un certificado autofirmado ejecuta 'gitstuff config edit %s --insecure'",
+	"doctor.hint_timeout":           "La petición agotó el tiempo de espera; revisa tu red, VPN o proxy",
+	"doctor.hint_refused":           "La conexión fue rechazada; revisa el host y el puerto de la URL del proveedor",
+	"config.available_providers":    "Proveedores SCM disponibles:",
+	"config.select_provider":        "Elige un proveedor (1-2):",
+	"config.prompt_name":            "Nombre del proveedor (identificador de esta instancia de %s):",
+	"config.prompt_gitlab_url":      "URL de GitLab (p. ej., https://gitlab.com o gitlab.example.com):",
+	"config.prompt_github_url":      "URL de GitHub (déjala en blanco para github.com o indica la URL de GitHub Enterprise):",
+	"config.prompt_gitlab_token":    "Token de acceso de GitLab (déjalo en blanco para crear uno en el navegador):",
+	"config.prompt_github_token":    "Token de acceso personal de GitHub (déjalo en blanco para crear uno en el navegador):",
+	"config.prompt_base_dir":        "Directorio base de los repositorios (por defecto: ~/gitstuff-repos):",
+	"config.prompt_insecure":        "¿Omitir la verificación de certificados SSL?",
+	"config.prompt_gitlab_group":    "Grupo de GitLab por defecto para filtrar repositorios (opcional, en blanco para todos):",
+	"config.prompt_github_org":      "Organización de GitHub por defecto para filtrar repositorios (opcional, en blanco para todas):",
+	"config.prompt_another":         "¿Quieres añadir otro proveedor?",
+	"config.complete":               "¡Configuración completada!",
+}
@@ -0,0 +1,90 @@
+// Package i18n looks up user-facing messages in the catalog for the selected
+// locale, falling back to English for anything that is not translated.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the source language of every message
+const DefaultLocale = "en"
+
+var catalogs = map[string]map[string]string{
+	"en": english,
+	"es": spanish,
+}
+
+var currentLocale = DefaultLocale
+
+// SetLocale selects the catalog used by T. Tags such as "es_ES.UTF-8" or
+// "es-MX" select their base language; unknown locales fall back to English.
+// It returns the locale that was selected.
+func SetLocale(tag string) string {
+	locale := normalize(tag)
+	if _, ok := catalogs[locale]; !ok {
+		locale = DefaultLocale
+	}
+	currentLocale = locale
+	return locale
+}
+
+// GetLocale returns the selected locale
+func GetLocale() string {
+	return currentLocale
+}
+
+// Available returns the supported locales in sorted order
+func Available() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Detect returns the locale to use: an explicit value wins, then the
+// GITSTUFF_LANG, LC_ALL, LC_MESSAGES and LANG environment variables
+func Detect(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	for _, name := range []string{"GITSTUFF_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return DefaultLocale
+}
+
+// T returns the message for key in the selected locale, formatted with args
+func T(key string, args ...interface{}) string {
+	message, ok := catalogs[currentLocale][key]
+	if !ok {
+		message, ok = english[key]
+		if !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+func normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.IndexAny(tag, "_-"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "c" || tag == "posix" {
+		return DefaultLocale
+	}
+	return tag
+}
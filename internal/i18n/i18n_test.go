@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetLocale(t *testing.T) {
+	t.Cleanup(func() { SetLocale(DefaultLocale) })
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "es", want: "es"},
+		{tag: "es_ES.UTF-8", want: "es"},
+		{tag: "es-MX", want: "es"},
+		{tag: "EN_us", want: "en"},
+		{tag: "C", want: "en"},
+		{tag: "POSIX", want: "en"},
+		{tag: "fr_FR.UTF-8", want: "en"},
+		{tag: "", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := SetLocale(tt.tag); got != tt.want {
+				t.Errorf("SetLocale(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+			if GetLocale() != tt.want {
+				t.Errorf("GetLocale() = %q, want %q", GetLocale(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, name := range []string{"GITSTUFF_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(name, "")
+	}
+
+	if got := Detect(""); got != DefaultLocale {
+		t.Errorf("Detect with no environment = %q, want %q", got, DefaultLocale)
+	}
+
+	t.Setenv("LANG", "es_ES.UTF-8")
+	if got := Detect(""); got != "es_ES.UTF-8" {
+		t.Errorf("Detect from LANG = %q", got)
+	}
+
+	t.Setenv("GITSTUFF_LANG", "en")
+	if got := Detect(""); got != "en" {
+		t.Errorf("Expected GITSTUFF_LANG to take precedence, got %q", got)
+	}
+
+	if got := Detect("es"); got != "es" {
+		t.Errorf("Expected explicit value to take precedence, got %q", got)
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Cleanup(func() { SetLocale(DefaultLocale) })
+
+	SetLocale("en")
+	if got := T("clone.summary", 3, 1); got != "Summary: 3 successful, 1 failed" {
+		t.Errorf("English message = %q", got)
+	}
+
+	SetLocale("es")
+	if got := T("clone.summary", 3, 1); got != "Resumen: 3 correctos, 1 fallidos" {
+		t.Errorf("Spanish message = %q", got)
+	}
+
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected unknown key to be returned as-is, got %q", got)
+	}
+}
+
+func TestT_FallsBackToEnglish(t *testing.T) {
+	t.Cleanup(func() {
+		delete(english, "test.only_english")
+		SetLocale(DefaultLocale)
+	})
+
+	english["test.only_english"] = "only in %s"
+	SetLocale("es")
+	if got := T("test.only_english", "English"); got != "only in English" {
+		t.Errorf("Expected English fallback, got %q", got)
+	}
+}
+
+// Every translation must exist in English and use the same format verbs in
+// the same order, or arguments would be printed in the wrong places
+func TestCatalogsConsistent(t *testing.T) {
+	for _, locale := range Available() {
+		for key, message := range catalogs[locale] {
+			source, ok := english[key]
+			if !ok {
+				t.Errorf("%s: key %q has no English source", locale, key)
+				continue
+			}
+			if got, want := formatVerbs(message), formatVerbs(source); got != want {
+				t.Errorf("%s: key %q uses verbs %q, English uses %q", locale, key, got, want)
+			}
+		}
+	}
+
+	for key := range english {
+		if _, ok := spanish[key]; !ok {
+			t.Errorf("es: missing translation for %q", key)
+		}
+	}
+}
+
+func formatVerbs(message string) string {
+	var verbs []string
+	for i := 0; i < len(message)-1; i++ {
+		if message[i] == '%' {
+			verbs = append(verbs, message[i:i+2])
+			i++
+		}
+	}
+	return strings.Join(verbs, " ")
+}
@@ -0,0 +1,61 @@
+// Package manifest reads the onboarding manifest a team keeps in a
+// repository of its group. The manifest lists the group's repositories that
+// make up the team's workspace and how to set each one up after cloning.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the manifest file at the root of its repository
+const FileName = ".gitstuff-manifest.yaml"
+
+type Manifest struct {
+	Repos []Repo `yaml:"repos"`
+}
+
+// Repo is a repository the manifest requires
+type Repo struct {
+	// Path is relative to the manifest's group, e.g. "api", or a glob such
+	// as "libs/*" for several repositories
+	Path string `yaml:"path"`
+
+	// Setup are shell commands run in the clone after it is first cloned
+	Setup []string `yaml:"setup,omitempty"`
+}
+
+// Load reads and checks the manifest at path
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse reads and checks a manifest
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(m.Repos) == 0 {
+		return nil, fmt.Errorf("manifest lists no repositories")
+	}
+	for i, repo := range m.Repos {
+		path := strings.Trim(repo.Path, "/")
+		if path == "" {
+			return nil, fmt.Errorf("manifest repository %d has no path", i+1)
+		}
+		m.Repos[i].Path = path
+	}
+	return &m, nil
+}
@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []Repo
+		wantErr string
+	}{
+		{
+			name: "repos and setup",
+			data: "repos:\n  - path: api\n    setup:\n      - make bootstrap\n  - path: /libs/*/\n",
+			want: []Repo{{Path: "api", Setup: []string{"make bootstrap"}}, {Path: "libs/*"}},
+		},
+		{name: "empty", data: "repos: []\n", wantErr: "no repositories"},
+		{name: "missing path", data: "repos:\n  - setup: [make]\n", wantErr: "repository 1 has no path"},
+		{name: "invalid yaml", data: "repos: [", wantErr: "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(m.Repos) != len(tt.want) {
+				t.Fatalf("Parse() = %+v, want %+v", m.Repos, tt.want)
+			}
+			for i, repo := range tt.want {
+				got := m.Repos[i]
+				if got.Path != repo.Path || strings.Join(got.Setup, "\n") != strings.Join(repo.Setup, "\n") {
+					t.Errorf("Repos[%d] = %+v, want %+v", i, got, repo)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, FileName)); err == nil {
+		t.Error("Expected an error for a missing manifest")
+	}
+
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte("repos:\n  - path: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(m.Repos) != 1 || m.Repos[0].Path != "web" {
+		t.Errorf("Load() = %+v", m.Repos)
+	}
+}
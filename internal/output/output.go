@@ -0,0 +1,94 @@
+// Package output serializes what commands print, so that goroutines working
+// on repositories in parallel can share a writer without their output being
+// interleaved mid-line.
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	// Stdout and Stderr are the process's standard streams behind
+	// serializing writers
+	Stdout = New(os.Stdout)
+	Stderr = New(os.Stderr)
+)
+
+// Writer writes each Write call to the underlying writer whole, holding a
+// lock shared by every writer derived from it through Prefixed
+type Writer struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+// New returns a Writer serializing writes to w
+func New(w io.Writer) *Writer {
+	return &Writer{mu: &sync.Mutex{}, w: w}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// Prefixed returns a writer that starts every line with prefix, such as the
+// repository a worker is busy with. Lines are written to w once they are
+// complete; Flush writes a final line that has no newline.
+func (w *Writer) Prefixed(prefix string) *LineWriter {
+	return &LineWriter{out: w, prefix: []byte(prefix)}
+}
+
+// LineWriter buffers writes into lines and writes each complete line, with a
+// prefix, in a single write to its Writer
+type LineWriter struct {
+	out     *Writer
+	prefix  []byte
+	mu      sync.Mutex
+	partial []byte
+}
+
+func (l *LineWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.partial = append(l.partial, p...)
+	end := bytes.LastIndexByte(l.partial, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	if _, err := l.out.Write(l.prefixLines(l.partial[:end+1])); err != nil {
+		return 0, err
+	}
+	l.partial = append(l.partial[:0], l.partial[end+1:]...)
+	return len(p), nil
+}
+
+// Flush writes what is left of an unterminated line, ending it
+func (l *LineWriter) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.partial) == 0 {
+		return nil
+	}
+	_, err := l.out.Write(l.prefixLines(append(l.partial, '\n')))
+	l.partial = l.partial[:0]
+	return err
+}
+
+// prefixLines returns lines, which end in a newline, with the prefix before
+// each of them
+func (l *LineWriter) prefixLines(lines []byte) []byte {
+	var buf bytes.Buffer
+	for len(lines) > 0 {
+		end := bytes.IndexByte(lines, '\n')
+		buf.Write(l.prefix)
+		buf.Write(lines[:end+1])
+		lines = lines[end+1:]
+	}
+	return buf.Bytes()
+}
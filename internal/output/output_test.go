@@ -0,0 +1,97 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriter_ConcurrentLinesStayWhole(t *testing.T) {
+	var buf bytes.Buffer
+	w := New(&buf)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				fmt.Fprintf(w, "worker %d line %d\n", worker, i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("Expected 800 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var worker, i int
+		if n, err := fmt.Sscanf(line, "worker %d line %d", &worker, &i); n != 2 || err != nil {
+			t.Fatalf("Line was interleaved: %q", line)
+		}
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		flush  bool
+		want   string
+	}{
+		{"complete lines", []string{"one\ntwo\n"}, false, "[api] one\n[api] two\n"},
+		{"line split across writes", []string{"cloning ", "into api", "\n"}, false, "[api] cloning into api\n"},
+		{"partial line held back", []string{"done\nhalf"}, false, "[api] done\n"},
+		{"flush ends the partial line", []string{"done\nhalf"}, true, "[api] done\n[api] half\n"},
+		{"empty lines are prefixed", []string{"\n"}, false, "[api] \n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := New(&buf).Prefixed("[api] ")
+			for _, s := range tt.writes {
+				if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+					t.Fatalf("Write(%q) = %d, %v", s, n, err)
+				}
+			}
+			if tt.flush {
+				if err := w.Flush(); err != nil {
+					t.Fatalf("Flush failed: %v", err)
+				}
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineWriter_SharesLock(t *testing.T) {
+	var buf bytes.Buffer
+	out := New(&buf)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"api", "web", "worker"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := out.Prefixed(name + ": ")
+			for i := 0; i < 50; i++ {
+				fmt.Fprintf(w, "step %d\n", i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		name, rest, ok := strings.Cut(line, ": ")
+		if !ok || (name != "api" && name != "web" && name != "worker") || !strings.HasPrefix(rest, "step ") {
+			t.Fatalf("Line was interleaved: %q", line)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package paths
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"gitstuff/internal/config"
 	"gitstuff/internal/scm"
+	"gitstuff/internal/timing"
 	"gitstuff/internal/verbosity"
 )
 
@@ -13,6 +15,8 @@ import (
 // It first tries the new provider-based structure: {BaseDir}/{Provider}/{FullPath}
 // If that doesn't exist, it falls back to legacy structure: {BaseDir}/{FullPath}
 func ResolveRepositoryPath(cfg *config.Config, repo *scm.Repository) string {
+	defer timing.Track(timing.Filesystem, time.Now())
+
 	// New provider-based structure (current default)
 	providerPath := filepath.Join(cfg.Local.BaseDir, repo.Provider, repo.FullPath)
 
@@ -23,7 +27,7 @@ func ResolveRepositoryPath(cfg *config.Config, repo *scm.Repository) string {
 	}
 
 	// Legacy structure fallback
-	legacyPath := filepath.Join(cfg.Local.BaseDir, repo.FullPath)
+	legacyPath := GetLegacyPath(cfg, repo)
 	verbosity.Trace("Checking legacy path: %s", legacyPath)
 	if _, err := os.Stat(legacyPath); err == nil {
 		verbosity.Debug("Found repository at legacy path: %s", legacyPath)
@@ -42,3 +46,9 @@ func GetClonePath(cfg *config.Config, repo *scm.Repository) string {
 	verbosity.Debug("Clone path for %s: %s", repo.FullPath, path)
 	return path
 }
+
+// GetLegacyPath returns where a repository lived before the provider-based
+// structure was introduced: {BaseDir}/{FullPath}
+func GetLegacyPath(cfg *config.Config, repo *scm.Repository) string {
+	return filepath.Join(cfg.Local.BaseDir, repo.FullPath)
+}
@@ -211,3 +211,12 @@ func TestPathResolutionWithRealDirectories(t *testing.T) {
 		t.Errorf("Expected clone path %s, but got %s", expectedClone, clonePath)
 	}
 }
+
+func TestGetLegacyPath(t *testing.T) {
+	cfg := &config.Config{Local: config.LocalConfig{BaseDir: "/repos"}}
+	repo := &scm.Repository{Provider: "github", FullPath: "octo/tool"}
+
+	if got, want := GetLegacyPath(cfg, repo), filepath.Join("/repos", "octo", "tool"); got != want {
+		t.Errorf("GetLegacyPath() = %q, want %q", got, want)
+	}
+}
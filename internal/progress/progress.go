@@ -0,0 +1,139 @@
+// Package progress draws an overall progress bar with a line per item in
+// progress on a terminal, redrawing it in place as items start and finish.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// eraseBelow clears from the cursor to the end of the screen
+	eraseBelow  = "\x1b[J"
+	minBarWidth = 10
+)
+
+// Bar is a progress display for a fixed number of items. It is safe for
+// concurrent use by parallel workers.
+type Bar struct {
+	mu     sync.Mutex
+	w      io.Writer
+	width  int
+	total  int
+	done   int
+	failed int
+	active []string
+	lines  int // lines currently drawn
+}
+
+// New returns a bar for total items drawn on w, a terminal width columns
+// wide
+func New(w io.Writer, total, width int) *Bar {
+	if width < minBarWidth+20 {
+		width = 80
+	}
+	b := &Bar{w: w, width: width, total: total}
+	b.redraw("")
+	return b
+}
+
+// Start shows name as in progress
+func (b *Bar) Start(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.active = append(b.active, name)
+	b.redraw("")
+}
+
+// Finish counts name as done and removes its in-progress line. Output, if
+// not empty, is printed above the bar, so it stays on screen.
+func (b *Bar) Finish(name string, failed bool, output string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, active := range b.active {
+		if active == name {
+			b.active = append(b.active[:i], b.active[i+1:]...)
+			break
+		}
+	}
+	b.done++
+	if failed {
+		b.failed++
+	}
+	b.redraw(output)
+}
+
+// Close removes the bar from the screen, leaving what was printed above it
+func (b *Bar) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.active = nil
+	fmt.Fprint(b.w, b.clear())
+	b.lines = 0
+}
+
+// redraw replaces the drawn bar with its current state, printing output
+// above it first
+func (b *Bar) redraw(output string) {
+	var s strings.Builder
+	s.WriteString(b.clear())
+	if output != "" {
+		s.WriteString(output)
+		if !strings.HasSuffix(output, "\n") {
+			s.WriteByte('\n')
+		}
+	}
+
+	lines := append([]string{b.summaryLine()}, b.activeLines()...)
+	for _, line := range lines {
+		s.WriteString(line)
+		s.WriteByte('\n')
+	}
+	b.lines = len(lines)
+	fmt.Fprint(b.w, s.String())
+}
+
+// clear moves the cursor back to the start of the drawn bar and erases it
+func (b *Bar) clear() string {
+	if b.lines == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\x1b[%dF%s", b.lines, eraseBelow)
+}
+
+func (b *Bar) summaryLine() string {
+	counts := fmt.Sprintf(" %d/%d", b.done, b.total)
+	if b.failed > 0 {
+		counts += fmt.Sprintf(" (%d failed)", b.failed)
+	}
+	barWidth := b.width - utf8.RuneCountInString(counts) - 3
+	if barWidth < minBarWidth {
+		barWidth = minBarWidth
+	}
+	filled := barWidth
+	if b.total > 0 {
+		filled = barWidth * b.done / b.total
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]" + counts
+}
+
+func (b *Bar) activeLines() []string {
+	lines := make([]string, len(b.active))
+	for i, name := range b.active {
+		lines[i] = truncate("  ⏳ "+name, b.width-1)
+	}
+	return lines
+}
+
+// truncate shortens s to at most width runes, so no line wraps and throws
+// off the redraw
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
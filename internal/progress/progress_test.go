@@ -0,0 +1,68 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := New(&out, 4, 40)
+	bar.Start("group/one")
+	bar.Start("group/two")
+	bar.Finish("group/one", false, "")
+	bar.Finish("group/two", true, "❌ Clone failed\n")
+
+	got := out.String()
+	last := got[strings.LastIndex(got, "\x1b[J")+len("\x1b[J"):]
+	if !strings.HasPrefix(last, "❌ Clone failed\n[") {
+		t.Errorf("expected the failure output above the bar, got %q", last)
+	}
+	if !strings.HasSuffix(last, "] 2/4 (1 failed)\n") {
+		t.Errorf("expected counts at the end of the bar, got %q", last)
+	}
+	if strings.Contains(last, "⏳") {
+		t.Errorf("expected no items in progress, got %q", last)
+	}
+	if n := strings.Count(last, "█"); n != 11 {
+		t.Errorf("expected half of the 22 wide bar filled, got %d in %q", n, last)
+	}
+
+	bar.Close()
+	if !strings.HasSuffix(out.String(), "\x1b[1F\x1b[J") {
+		t.Errorf("expected Close to erase the bar, got %q", out.String())
+	}
+}
+
+func TestBar_ActiveLines(t *testing.T) {
+	var out bytes.Buffer
+	bar := New(&out, 2, 30)
+	bar.Start("a-group/with-a-very-long-repository-name")
+	bar.Start("b")
+
+	got := out.String()
+	last := got[strings.LastIndex(got, "\x1b[J")+len("\x1b[J"):]
+	lines := strings.Split(strings.TrimSuffix(last, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected the bar and 2 items, got %q", lines)
+	}
+	if lines[1] != "  ⏳ a-group/with-a-very-long…" {
+		t.Errorf("expected a truncated line, got %q", lines[1])
+	}
+	if lines[2] != "  ⏳ b" {
+		t.Errorf("got %q", lines[2])
+	}
+	if !strings.HasPrefix(got, "[") || !strings.Contains(got, "\x1b[2F\x1b[J") {
+		t.Errorf("expected the bar to be redrawn in place, got %q", got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("got %q", got)
+	}
+	if got := truncate("abcdefghij", 5); got != "abcd…" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,276 @@
+package ratelimit
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// sshTunnelHeader marks the CONNECT requests of Connect, which carry SSH
+// rather than HTTPS and so never go through an HTTP proxy
+const sshTunnelHeader = "Gitstuff-Tunnel"
+
+const dialTimeout = 30 * time.Second
+
+// Proxy is a local HTTP proxy that throttles all traffic through a shared
+// Limiter. It tunnels CONNECT requests (HTTPS, and SSH via Connect) and
+// forwards plain HTTP requests.
+type Proxy struct {
+	limiter   *Limiter
+	upstream  func(*http.Request) (*url.URL, error)
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+}
+
+// NewProxy starts a proxy on a loopback port. HTTP and HTTPS requests go on
+// through the proxy that upstream returns for them, like
+// http.ProxyFromEnvironment does, or directly when it returns nil or
+// upstream is nil.
+func NewProxy(limiter *Limiter, upstream func(*http.Request) (*url.URL, error)) (*Proxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start rate limiting proxy: %w", err)
+	}
+
+	p := &Proxy{
+		limiter:   limiter,
+		upstream:  upstream,
+		listener:  listener,
+		transport: &http.Transport{Proxy: upstream},
+	}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go func() { _ = p.server.Serve(listener) }()
+	return p, nil
+}
+
+// Addr returns the host:port the proxy listens on
+func (p *Proxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Close stops the proxy
+func (p *Proxy) Close() error {
+	p.transport.CloseIdleConnections()
+	return p.server.Close()
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := p.dial(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		client.Close()
+		upstream.Close()
+		return
+	}
+
+	pipe(client, buffered.Reader, upstream, p.limiter)
+}
+
+// dial connects to the destination of a CONNECT request, through the
+// upstream proxy unless it is an SSH tunnel
+func (p *Proxy) dial(r *http.Request) (net.Conn, error) {
+	if p.upstream == nil || r.Header.Get(sshTunnelHeader) == "ssh" {
+		return net.DialTimeout("tcp", r.Host, dialTimeout)
+	}
+	proxyURL, err := p.upstream(&http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Scheme: "https", Host: r.Host},
+		Host:   r.Host,
+		Header: make(http.Header),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return net.DialTimeout("tcp", r.Host, dialTimeout)
+	}
+	return dialThrough(proxyURL, r.Host)
+}
+
+// dialThrough opens a tunnel to target through the HTTP or HTTPS proxy at
+// proxyURL
+func dialThrough(proxyURL *url.URL, target string) (net.Conn, error) {
+	addr := proxyURL.Host
+	var conn net.Conn
+	var err error
+	switch proxyURL.Scheme {
+	case "http":
+		if proxyURL.Port() == "" {
+			addr = net.JoinHostPort(proxyURL.Hostname(), "80")
+		}
+		conn, err = net.DialTimeout("tcp", addr, dialTimeout)
+	case "https":
+		if proxyURL.Port() == "" {
+			addr = net.JoinHostPort(proxyURL.Hostname(), "443")
+		}
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", addr, &tls.Config{ServerName: proxyURL.Hostname()})
+	default:
+		return nil, fmt.Errorf("unsupported upstream proxy %s", proxyURL.Redacted())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach upstream proxy %s: %w", proxyURL.Redacted(), err)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT to upstream proxy: %w", err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from upstream proxy: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("upstream proxy refused connection to %s: %s", target, resp.Status)
+	}
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn is a connection whose first bytes may already have been read
+// into reader
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *bufferedConn) CloseWrite() error {
+	closeWrite(c.Conn)
+	return nil
+}
+
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	if !r.URL.IsAbs() {
+		http.Error(w, "not a proxy request", http.StatusBadRequest)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	if r.Body != nil {
+		out.Body = io.NopCloser(p.limiter.Reader(r.Body))
+	}
+
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, p.limiter.Reader(resp.Body))
+}
+
+// pipe copies between a client and upstream connection in both directions,
+// throttled by limiter, until either side closes
+func pipe(client net.Conn, clientReader io.Reader, upstream net.Conn, limiter *Limiter) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(upstream, limiter.Reader(clientReader))
+		closeWrite(upstream)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(client, limiter.Reader(upstream))
+		closeWrite(client)
+	}()
+	wg.Wait()
+	client.Close()
+	upstream.Close()
+}
+
+func closeWrite(conn net.Conn) {
+	if half, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = half.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
+
+// Connect opens a tunnel to host:port through the proxy at proxyAddr and
+// copies it to and from the given streams. It is used as ssh's
+// ProxyCommand so SSH transfers share the proxy's limit. The tunnel always
+// connects directly, as ssh does not use HTTP proxies.
+func Connect(proxyAddr, host, port string, stdin io.Reader, stdout io.Writer) error {
+	conn, err := net.DialTimeout("tcp", proxyAddr, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to reach rate limiting proxy: %w", err)
+	}
+	defer conn.Close()
+
+	target := net.JoinHostPort(host, port)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n%s: ssh\r\n\r\n", target, target, sshTunnelHeader); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy refused connection to %s: %s", target, resp.Status)
+	}
+
+	go func() {
+		_, _ = io.Copy(conn, stdin)
+		closeWrite(conn)
+	}()
+	_, err = io.Copy(stdout, reader)
+	return err
+}
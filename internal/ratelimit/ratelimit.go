@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minBurst keeps small rates from stalling on every read
+const minBurst = 16 * 1024
+
+// ParseRate parses a curl-style rate such as "500k" or "2M" into bytes per
+// second. Suffixes k, m and g are powers of 1024. An empty string or zero
+// means unlimited.
+func ParseRate(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	multiplier := 1.0
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		multiplier = 1 << 10
+	case "m":
+		multiplier = 1 << 20
+	case "g":
+		multiplier = 1 << 30
+	}
+	number := s
+	if multiplier != 1 {
+		number = s[:len(s)-1]
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid rate %q (expected bytes per second, e.g. 500k or 2M)", s)
+	}
+	return int64(value * multiplier), nil
+}
+
+// FormatRate formats bytes per second for display
+func FormatRate(bytesPerSecond int64) string {
+	switch {
+	case bytesPerSecond >= 1<<20:
+		return fmt.Sprintf("%.1f MiB/s", float64(bytesPerSecond)/(1<<20))
+	case bytesPerSecond >= 1<<10:
+		return fmt.Sprintf("%.1f KiB/s", float64(bytesPerSecond)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B/s", bytesPerSecond)
+	}
+}
+
+// Limiter is a token bucket shared by every connection it throttles, so the
+// rate applies to the total of all transfers
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a limiter allowing bytesPerSecond on average
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	burst := float64(bytesPerSecond)
+	if burst < minBurst {
+		burst = minBurst
+	}
+	return &Limiter{rate: float64(bytesPerSecond), burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Wait blocks until n bytes may be transferred
+func (l *Limiter) Wait(n int) {
+	for n > 0 {
+		chunk := n
+		if float64(chunk) > l.burst {
+			chunk = int(l.burst)
+		}
+		if delay := l.reserve(chunk); delay > 0 {
+			time.Sleep(delay)
+		}
+		n -= chunk
+	}
+}
+
+// reserve takes n tokens, going into debt if needed, and returns how long
+// the caller has to wait for that debt to be paid back
+func (l *Limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+type reader struct {
+	r io.Reader
+	l *Limiter
+}
+
+// Reader returns a reader whose reads are throttled by l
+func (l *Limiter) Reader(r io.Reader) io.Reader {
+	return &reader{r: r, l: l}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > int(r.l.burst) {
+		p = p[:int(r.l.burst)]
+	}
+	n, err := r.r.Read(p)
+	r.l.Wait(n)
+	return n, err
+}
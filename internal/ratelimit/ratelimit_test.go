@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "0", want: 0},
+		{input: "1500", want: 1500},
+		{input: "500k", want: 500 * 1024},
+		{input: "2M", want: 2 * 1024 * 1024},
+		{input: "1.5m", want: 1536 * 1024},
+		{input: "1G", want: 1 << 30},
+		{input: "fast", wantErr: true},
+		{input: "-1k", wantErr: true},
+		{input: "k", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRate(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	for rate, want := range map[int64]string{512: "512 B/s", 500 * 1024: "500.0 KiB/s", 2 << 20: "2.0 MiB/s"} {
+		if got := FormatRate(rate); got != want {
+			t.Errorf("FormatRate(%d) = %q, want %q", rate, got, want)
+		}
+	}
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := NewLimiter(minBurst)
+
+	if delay := l.reserve(minBurst); delay != 0 {
+		t.Errorf("Expected the initial burst to be free, got %v", delay)
+	}
+	delay := l.reserve(minBurst)
+	if delay < 900*time.Millisecond || delay > 1100*time.Millisecond {
+		t.Errorf("Expected about one second of delay once the burst is spent, got %v", delay)
+	}
+}
+
+func TestProxy_Forward(t *testing.T) {
+	payload := strings.Repeat("x", 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "yes")
+		_, _ = io.WriteString(w, payload)
+	}))
+	defer server.Close()
+
+	proxy, err := NewProxy(NewLimiter(1<<20), nil)
+	if err != nil {
+		t.Fatalf("NewProxy failed: %v", err)
+	}
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse("http://" + proxy.Addr())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	resp, err := client.Get(server.URL + "/info/refs")
+	if err != nil {
+		t.Fatalf("Request through proxy failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != payload || resp.Header.Get("X-Test") != "yes" {
+		t.Errorf("Unexpected proxied response: %d bytes, headers %v", len(body), resp.Header)
+	}
+}
+
+func TestConnect_Tunnel(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		conn, err := echo.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		_, _ = io.WriteString(conn, "echo: "+line)
+	}()
+
+	proxy, err := NewProxy(NewLimiter(1<<20), nil)
+	if err != nil {
+		t.Fatalf("NewProxy failed: %v", err)
+	}
+	defer proxy.Close()
+
+	host, port, _ := net.SplitHostPort(echo.Addr().String())
+	var out bytes.Buffer
+	if err := Connect(proxy.Addr(), host, port, strings.NewReader("SSH-2.0-test\n"), &out); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if out.String() != "echo: SSH-2.0-test\n" {
+		t.Errorf("Unexpected tunnel output %q", out.String())
+	}
+}
+
+func TestConnect_Refused(t *testing.T) {
+	proxy, err := NewProxy(NewLimiter(1<<20), nil)
+	if err != nil {
+		t.Fatalf("NewProxy failed: %v", err)
+	}
+	defer proxy.Close()
+
+	err = Connect(proxy.Addr(), "127.0.0.1", "1", strings.NewReader(""), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "proxy refused") {
+		t.Errorf("Expected refused connection error, got %v", err)
+	}
+}
+
+func TestProxy_Upstream(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "refs")
+	}))
+	defer server.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "refs")
+	}))
+	defer plain.Close()
+
+	// The upstream proxy records where it was asked to go
+	seen := make(chan string, 10)
+	inner := &Proxy{limiter: NewLimiter(1 << 20), transport: &http.Transport{}}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen <- r.Method + " " + r.Host
+		inner.ServeHTTP(w, r)
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+	next := func() string {
+		select {
+		case request := <-seen:
+			return request
+		default:
+			return ""
+		}
+	}
+
+	proxy, err := NewProxy(NewLimiter(1<<20), http.ProxyURL(upstreamURL))
+	if err != nil {
+		t.Fatalf("NewProxy failed: %v", err)
+	}
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse("http://" + proxy.Addr())
+	transport := server.Client().Transport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+	client := &http.Client{Transport: transport}
+	for _, target := range []string{server.URL, plain.URL} {
+		resp, err := client.Get(target + "/info/refs")
+		if err != nil {
+			t.Fatalf("Request to %s through proxy failed: %v", target, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "refs" {
+			t.Errorf("Unexpected response from %s: %q", target, body)
+		}
+	}
+	if got, want := next(), "CONNECT "+strings.TrimPrefix(server.URL, "https://"); got != want {
+		t.Errorf("Expected the upstream proxy to see %q, got %q", want, got)
+	}
+	if got, want := next(), "GET "+strings.TrimPrefix(plain.URL, "http://"); got != want {
+		t.Errorf("Expected the upstream proxy to see %q, got %q", want, got)
+	}
+
+	// SSH tunnels connect directly, as ssh itself would
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		conn, err := echo.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.WriteString(conn, "SSH-2.0-test\n")
+	}()
+	host, port, _ := net.SplitHostPort(echo.Addr().String())
+	var out bytes.Buffer
+	if err := Connect(proxy.Addr(), host, port, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if got := next(); got != "" {
+		t.Errorf("Expected the SSH tunnel to bypass the upstream proxy, it saw %q", got)
+	}
+}
@@ -0,0 +1,191 @@
+// Package redact masks credentials in text before it is logged or shown to
+// the user.
+package redact
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every secret removed from output
+const Mask = "REDACTED"
+
+// minSecretLength keeps very short values from masking unrelated text
+const minSecretLength = 6
+
+// SensitiveParams are query parameters that may carry credentials
+var SensitiveParams = []string{"private_token", "access_token", "token", "job_token", "client_secret"}
+
+var (
+	userInfoPattern = regexp.MustCompile(`(?i)\b(https?://)[^/\s@]+@`)
+	paramPattern    = regexp.MustCompile(`(?i)([?&](?:` + strings.Join(SensitiveParams, "|") + `)=)[^&\s"']+`)
+	headerPattern   = regexp.MustCompile(`(?i)\b((?:authorization|private-token):\s*(?:bearer\s+|token\s+|basic\s+)?)\S+`)
+	tokenPattern    = regexp.MustCompile(`\b(?:glpat-[A-Za-z0-9_\-]{20,}|gl(?:dt|rt|ptt|oas)-[A-Za-z0-9_\-]{20,}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)
+)
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// AddSecret registers a literal value, such as a configured access token,
+// that must be masked wherever it appears
+func AddSecret(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, existing := range secrets {
+		if existing == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+	// Longer secrets first so a secret containing another is masked whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+// ResetSecrets forgets all registered secrets
+func ResetSecrets() {
+	mu.Lock()
+	defer mu.Unlock()
+	secrets = nil
+}
+
+// String masks registered secrets, well-known token formats, credentials
+// embedded in URLs and authentication headers in s
+func String(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+
+	s = userInfoPattern.ReplaceAllString(s, "${1}"+Mask+"@")
+	s = paramPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = headerPattern.ReplaceAllString(s, "${1}"+Mask)
+	s = tokenPattern.ReplaceAllString(s, Mask)
+	return s
+}
+
+// URL returns u as a string with user info and credential query parameters
+// masked
+func URL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(Mask)
+	}
+
+	query := redacted.Query()
+	changed := false
+	for name := range query {
+		for _, sensitive := range SensitiveParams {
+			if strings.EqualFold(name, sensitive) {
+				query.Set(name, Mask)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+
+	return String(redacted.String())
+}
+
+// Error wraps err so that its message is redacted while errors.Is and
+// errors.As still see the original
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &redactedError{err: err}
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return String(e.err.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Writer redacts everything written through it. Output is redacted a line
+// at a time so secrets split across writes are still caught; call Flush
+// once writing is finished to emit any trailing partial line.
+type Writer struct {
+	mu  *sync.Mutex // Shared by the Writers of NewWriters
+	out io.Writer
+	buf []byte
+}
+
+// NewWriter returns a Writer that redacts output before passing it to w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{mu: &sync.Mutex{}, out: w}
+}
+
+// NewWriters returns Writers for the output and error output of a command,
+// which are written at the same time. They are one Writer when stdout and
+// stderr are the same, and otherwise take turns, as both may write to the
+// same place underneath.
+func NewWriters(stdout, stderr io.Writer) (*Writer, *Writer) {
+	out := NewWriter(stdout)
+	if sameWriter(stdout, stderr) {
+		return out, out
+	}
+	return out, &Writer{mu: out.mu, out: stderr}
+}
+
+// sameWriter reports whether a and b are the same writer, and false for
+// writers that cannot be compared
+func sameWriter(a, b io.Writer) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	// git progress output ends lines with carriage returns
+	end := bytes.LastIndexAny(w.buf, "\n\r")
+	if end < 0 {
+		return len(p), nil
+	}
+
+	if _, err := io.WriteString(w.out, String(string(w.buf[:end+1]))); err != nil {
+		return 0, err
+	}
+	w.buf = append(w.buf[:0], w.buf[end+1:]...)
+	return len(p), nil
+}
+
+// Flush writes any buffered partial line
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.out, String(string(w.buf)))
+	w.buf = w.buf[:0]
+	return err
+}
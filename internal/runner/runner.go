@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// Task is a unit of work. Anything the task wants to show the user must be
+// written to w so the runner can keep output from parallel tasks together.
+type Task func(w io.Writer) error
+
+// Result holds the outcome of a single task.
+type Result struct {
+	Index int
+	Err   error
+
+	// Skipped is set for tasks that never started because the context was
+	// done; Err is then the context's error
+	Skipped bool
+}
+
+type Runner struct {
+	jobs int
+}
+
+// New returns a runner that executes at most jobs tasks at a time.
+// Values below one are treated as one.
+func New(jobs int) *Runner {
+	if jobs < 1 {
+		jobs = 1
+	}
+	return &Runner{jobs: jobs}
+}
+
+func (r *Runner) Jobs() int {
+	return r.jobs
+}
+
+// Run executes all tasks and returns their results in task order.
+// With a single job, tasks write straight to out. With more jobs, each
+// task's output is buffered and flushed to out in task order as soon as the
+// task and every task before it have finished.
+func (r *Runner) Run(tasks []Task, out io.Writer) []Result {
+	return r.RunContext(context.Background(), tasks, out)
+}
+
+// RunContext runs tasks like Run, but starts no more of them once ctx is
+// done. Tasks already running are left to finish.
+func (r *Runner) RunContext(ctx context.Context, tasks []Task, out io.Writer) []Result {
+	results := make([]Result, len(tasks))
+	run := func(i int, w io.Writer) {
+		if err := ctx.Err(); err != nil {
+			results[i] = Result{Index: i, Err: err, Skipped: true}
+			return
+		}
+		results[i] = Result{Index: i, Err: tasks[i](w)}
+	}
+
+	if r.jobs == 1 {
+		for i := range tasks {
+			run(i, out)
+		}
+		return results
+	}
+
+	buffers := make([]bytes.Buffer, len(tasks))
+	done := make([]chan struct{}, len(tasks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < r.jobs && w < len(tasks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				run(i, &buffers[i])
+				close(done[i])
+			}
+		}()
+	}
+
+	go func() {
+		for i := range tasks {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	for i := range tasks {
+		<-done[i]
+		_, _ = out.Write(buffers[i].Bytes())
+	}
+
+	wg.Wait()
+	return results
+}
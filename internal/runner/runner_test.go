@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNew_ClampsJobs(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs int
+		want int
+	}{
+		{"negative", -3, 1},
+		{"zero", 0, 1},
+		{"one", 1, 1},
+		{"many", 8, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.jobs).Jobs(); got != tt.want {
+				t.Errorf("New(%d).Jobs() = %d, want %d", tt.jobs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_OrderedOutput(t *testing.T) {
+	for _, jobs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			var tasks []Task
+			for i := 0; i < 10; i++ {
+				i := i
+				tasks = append(tasks, func(w io.Writer) error {
+					// Later tasks finish first to exercise reordering
+					time.Sleep(time.Duration(10-i) * time.Millisecond)
+					fmt.Fprintf(w, "task %d start\n", i)
+					fmt.Fprintf(w, "task %d end\n", i)
+					return nil
+				})
+			}
+
+			var out bytes.Buffer
+			New(jobs).Run(tasks, &out)
+
+			var want strings.Builder
+			for i := 0; i < 10; i++ {
+				fmt.Fprintf(&want, "task %d start\ntask %d end\n", i, i)
+			}
+			if out.String() != want.String() {
+				t.Errorf("Run() output out of order:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRun_CollectsErrors(t *testing.T) {
+	boom := errors.New("boom")
+	tasks := []Task{
+		func(w io.Writer) error { return nil },
+		func(w io.Writer) error { return boom },
+		func(w io.Writer) error { return nil },
+	}
+
+	results := New(2).Run(tasks, io.Discard)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("Result %d has index %d", i, result.Index)
+		}
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("Expected only the second task to fail, got %+v", results)
+	}
+	if !errors.Is(results[1].Err, boom) {
+		t.Errorf("Expected boom error, got %v", results[1].Err)
+	}
+}
+
+func TestRun_BoundedConcurrency(t *testing.T) {
+	var running, peak int32
+	var tasks []Task
+	for i := 0; i < 20; i++ {
+		tasks = append(tasks, func(w io.Writer) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+
+	New(3).Run(tasks, io.Discard)
+
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent tasks, saw %d", peak)
+	}
+	if peak < 2 {
+		t.Errorf("Expected tasks to run concurrently, peak was %d", peak)
+	}
+}
+
+func TestRun_NoTasks(t *testing.T) {
+	results := New(4).Run(nil, io.Discard)
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
+
+func TestRunContext_StopsStartingTasks(t *testing.T) {
+	for _, jobs := range []int{1, 3} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var started int32
+			var tasks []Task
+			for i := 0; i < 10; i++ {
+				tasks = append(tasks, func(w io.Writer) error {
+					if atomic.AddInt32(&started, 1) == 2 {
+						cancel()
+					}
+					return nil
+				})
+			}
+
+			results := New(jobs).RunContext(ctx, tasks, io.Discard)
+
+			skipped := 0
+			for i, result := range results {
+				if result.Index != i {
+					t.Errorf("Result %d has index %d", i, result.Index)
+				}
+				if result.Skipped {
+					skipped++
+					if !errors.Is(result.Err, context.Canceled) {
+						t.Errorf("Expected skipped task %d to report cancellation, got %v", i, result.Err)
+					}
+				} else if result.Err != nil {
+					t.Errorf("Expected task %d to succeed, got %v", i, result.Err)
+				}
+			}
+			if int(started)+skipped != len(tasks) {
+				t.Errorf("Expected every task to run or be skipped, %d ran and %d skipped", started, skipped)
+			}
+			if int(started) > 1+jobs {
+				t.Errorf("Expected no tasks to start after cancellation, %d ran", started)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package scm
 
+import "context"
+
 // ClientFactory interface for creating SCM clients
 type ClientFactory interface {
 	CreateClient(providerType, name, url, token string, insecure bool) (Client, error)
@@ -24,11 +26,11 @@ func (m *MultiClientManager) GetClients() []Client {
 	return m.clients
 }
 
-func (m *MultiClientManager) ListAllRepositories() ([]*Repository, error) {
+func (m *MultiClientManager) ListAllRepositories(ctx context.Context) ([]*Repository, error) {
 	var allRepos []*Repository
 
 	for _, client := range m.clients {
-		repos, err := client.ListAllRepositories()
+		repos, err := client.ListAllRepositories(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -38,11 +40,11 @@ func (m *MultiClientManager) ListAllRepositories() ([]*Repository, error) {
 	return allRepos, nil
 }
 
-func (m *MultiClientManager) ListRepositoriesInGroup(groupPath string) ([]*Repository, error) {
+func (m *MultiClientManager) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*Repository, error) {
 	var allRepos []*Repository
 
 	for _, client := range m.clients {
-		repos, err := client.ListRepositoriesInGroup(groupPath)
+		repos, err := client.ListRepositoriesInGroup(ctx, groupPath)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,138 @@
+package scm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownProvider is returned by DetectProviderType when no known API
+// answers at the URL
+var ErrUnknownProvider = errors.New("could not detect the provider type")
+
+// providerProbe is a well-known, unauthenticated endpoint that identifies a
+// product by the shape of its JSON response
+type providerProbe struct {
+	providerType string
+	path         string
+	matches      func(status int, body map[string]interface{}) bool
+}
+
+var providerProbes = []providerProbe{
+	{
+		providerType: "github",
+		path:         "/api/v3/meta",
+		matches: func(status int, body map[string]interface{}) bool {
+			_, ok := body["verifiable_password_authentication"]
+			return status == http.StatusOK && ok
+		},
+	},
+	{
+		// The version endpoint needs a token, but even the 401 is
+		// recognizably GitLab's
+		providerType: "gitlab",
+		path:         "/api/v4/version",
+		matches: func(status int, body map[string]interface{}) bool {
+			if status == http.StatusUnauthorized {
+				return body["message"] == "401 Unauthorized"
+			}
+			_, hasRevision := body["revision"]
+			return status == http.StatusOK && hasRevision
+		},
+	},
+	{
+		providerType: "gitea",
+		path:         "/api/v1/version",
+		matches: func(status int, body map[string]interface{}) bool {
+			_, ok := body["version"]
+			return status == http.StatusOK && ok && len(body) == 1
+		},
+	},
+}
+
+// DetectProviderType finds out which product runs at rawURL by probing the
+// API endpoints of each. It returns "github", "gitlab" or "gitea", and
+// ErrUnknownProvider when none of them answer.
+func DetectProviderType(ctx context.Context, client *http.Client, rawURL string) (string, error) {
+	base, err := detectionBase(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if base.Host == "github.com" || base.Host == "api.github.com" {
+		return "github", nil
+	}
+
+	var probeErrs []error
+	for _, probe := range providerProbes {
+		matched, err := runProbe(ctx, client, base, probe)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			probeErrs = append(probeErrs, err)
+			continue
+		}
+		if matched {
+			return probe.providerType, nil
+		}
+	}
+	if len(probeErrs) == len(providerProbes) {
+		// Nothing answered at all, which says more about the URL or the
+		// network than about the product
+		return "", fmt.Errorf("%w at %s: %w", ErrUnknownProvider, base, probeErrs[0])
+	}
+	return "", fmt.Errorf("%w at %s", ErrUnknownProvider, base)
+}
+
+// detectionBase returns the instance root of rawURL, accepting a bare host
+// and API URLs as users tend to paste them
+func detectionBase(rawURL string) (*url.URL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, fmt.Errorf("URL cannot be empty")
+	}
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		rawURL = "https://" + rawURL
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	if base.Host == "" {
+		return nil, fmt.Errorf("URL must have a valid host")
+	}
+	for _, suffix := range []string{"/api/v3", "/api/v4", "/api/v1"} {
+		if i := strings.Index(base.Path, suffix); i >= 0 {
+			base.Path = base.Path[:i]
+		}
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+	base.RawQuery = ""
+	base.Fragment = ""
+	return base, nil
+}
+
+func runProbe(ctx context.Context, client *http.Client, base *url.URL, probe providerProbe) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String()+probe.path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		// Not JSON, such as a login page or a proxy's error page
+		return false, nil
+	}
+	return probe.matches(resp.StatusCode, body), nil
+}
@@ -0,0 +1,97 @@
+package scm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectProviderType(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string // path to JSON body, answered with 200
+		status    map[string]int
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "github enterprise",
+			responses: map[string]string{"/api/v3/meta": `{"verifiable_password_authentication": false, "hooks": []}`},
+			want:      "github",
+		},
+		{
+			name:      "gitlab without a token",
+			responses: map[string]string{"/api/v4/version": `{"message": "401 Unauthorized"}`},
+			status:    map[string]int{"/api/v4/version": http.StatusUnauthorized},
+			want:      "gitlab",
+		},
+		{
+			name:      "gitlab with open version endpoint",
+			responses: map[string]string{"/api/v4/version": `{"version": "17.5.0", "revision": "abc123"}`},
+			want:      "gitlab",
+		},
+		{
+			name:      "gitea",
+			responses: map[string]string{"/api/v1/version": `{"version": "1.22.3"}`},
+			want:      "gitea",
+		},
+		{
+			name:      "html everywhere",
+			responses: map[string]string{},
+			wantErr:   ErrUnknownProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, ok := tt.responses[r.URL.Path]
+				if !ok {
+					w.Header().Set("Content-Type", "text/html")
+					_, _ = w.Write([]byte("<html>Sign in</html>"))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if status, ok := tt.status[r.URL.Path]; ok {
+					w.WriteHeader(status)
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			got, err := DetectProviderType(context.Background(), server.Client(), server.URL+"/api/v4/")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %q, %v", tt.wantErr, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectProviderType() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectProviderType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectProviderType_GitHubCom(t *testing.T) {
+	got, err := DetectProviderType(context.Background(), http.DefaultClient, "github.com")
+	if err != nil || got != "github" {
+		t.Errorf("DetectProviderType(github.com) = %q, %v", got, err)
+	}
+}
+
+func TestDetectProviderType_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := DetectProviderType(context.Background(), http.DefaultClient, url)
+	if !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("expected ErrUnknownProvider, got %v", err)
+	}
+}
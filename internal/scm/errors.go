@@ -0,0 +1,73 @@
+package scm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrorKind is the category of a failed provider API request
+type ErrorKind int
+
+const (
+	ErrorOther ErrorKind = iota
+	ErrorUnauthorized
+	ErrorForbidden
+	ErrorNotFound
+	ErrorRateLimited
+	ErrorServer
+)
+
+// ClassifyError returns the category of an error returned by a client,
+// based on the HTTP status of the failed request
+func ClassifyError(err error) ErrorKind {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return ErrorOther
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		return ErrorUnauthorized
+	case apiErr.StatusCode == http.StatusForbidden:
+		// GitHub answers 403 rather than 429 when the rate limit is exhausted
+		if strings.Contains(strings.ToLower(apiErr.Error()), "rate limit") {
+			return ErrorRateLimited
+		}
+		return ErrorForbidden
+	case apiErr.StatusCode == http.StatusNotFound:
+		return ErrorNotFound
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case apiErr.StatusCode >= 500:
+		return ErrorServer
+	}
+	return ErrorOther
+}
+
+// IsUnavailable reports whether err means the provider could not be reached
+// or could not answer, as during an outage, rather than that it refused the
+// request
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch ClassifyError(err) {
+	case ErrorServer, ErrorRateLimited:
+		return true
+	case ErrorOther:
+		// Without an HTTP status the request never got an answer
+		return StatusCode(err) == 0
+	}
+	return false
+}
+
+// StatusCode returns the HTTP status of a failed API request, or 0 when err
+// does not carry one
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package scm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"plain error", errors.New("connection refused"), ErrorOther},
+		{"unauthorized", &APIError{StatusCode: 401, Err: errors.New("Bad credentials")}, ErrorUnauthorized},
+		{"forbidden", &APIError{StatusCode: 403, Err: errors.New("insufficient_scope")}, ErrorForbidden},
+		{"github rate limit", &APIError{StatusCode: 403, Err: errors.New("API rate limit exceeded for user")}, ErrorRateLimited},
+		{"not found", &APIError{StatusCode: 404, Err: errors.New("404 Not Found")}, ErrorNotFound},
+		{"too many requests", &APIError{StatusCode: 429, Err: errors.New("slow down")}, ErrorRateLimited},
+		{"server error", &APIError{StatusCode: 502, Err: errors.New("bad gateway")}, ErrorServer},
+		{"other status", &APIError{StatusCode: 422, Err: errors.New("validation failed")}, ErrorOther},
+		{"wrapped", fmt.Errorf("failed to list repositories: %w", &APIError{StatusCode: 401, Err: errors.New("401")}), ErrorUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", errors.New("dial tcp: connection refused"), true},
+		{"server error", &APIError{StatusCode: 503, Err: errors.New("unavailable")}, true},
+		{"rate limited", &APIError{StatusCode: 429, Err: errors.New("slow down")}, true},
+		{"unauthorized", &APIError{StatusCode: 401, Err: errors.New("Bad credentials")}, false},
+		{"not found", &APIError{StatusCode: 404, Err: errors.New("404 Not Found")}, false},
+		{"other status", &APIError{StatusCode: 422, Err: errors.New("validation failed")}, false},
+		{"cancelled", fmt.Errorf("failed to list projects: %w", context.Canceled), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUnavailable(tt.err); got != tt.want {
+				t.Errorf("IsUnavailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package scm
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 // Repository represents a repository from any SCM provider
 type Repository struct {
 	ID            string
@@ -10,6 +16,25 @@ type Repository struct {
 	DefaultBranch string
 	WebURL        string
 	Provider      string // "gitlab" or "github"
+	Archived      bool
+	Description   string
+	Visibility    string // One of Visibilities, when the provider reports it
+	Topics        []string
+
+	// Language is the primary language, Size the size of the repository in
+	// bytes and LastActivity when it last had a push or other activity, when
+	// the provider reports them
+	Language     string
+	Size         int64
+	Stars        int
+	LastActivity time.Time
+
+	// Fork and the Parent fields describe the repository this one was
+	// forked from, when the provider reports it
+	Fork              bool
+	ParentFullPath    string
+	ParentCloneURL    string
+	ParentSSHCloneURL string
 }
 
 // Group represents a group/organization from any SCM provider
@@ -36,14 +61,213 @@ type GroupNode struct {
 // Client interface that both GitLab and GitHub clients must implement
 type Client interface {
 	// ListAllRepositories returns all repositories the user has access to
-	ListAllRepositories() ([]*Repository, error)
+	ListAllRepositories(ctx context.Context) ([]*Repository, error)
 
 	// ListRepositoriesInGroup returns repositories within a specific group/organization
-	ListRepositoriesInGroup(groupPath string) ([]*Repository, error)
+	ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*Repository, error)
 
 	// BuildRepositoryTree builds a hierarchical tree structure of repositories
-	BuildRepositoryTree() (*RepositoryTree, error)
+	BuildRepositoryTree(ctx context.Context) (*RepositoryTree, error)
 
 	// GetProviderType returns the provider type ("gitlab" or "github")
 	GetProviderType() string
 }
+
+// ProviderHealth describes the result of an authenticated request to a provider
+type ProviderHealth struct {
+	User               string
+	RateLimitLimit     int // 0 when the provider did not report a limit
+	RateLimitRemaining int
+	RateLimitReset     time.Time
+
+	// Scopes granted to the token, or nil when the provider or token type
+	// does not report them
+	Scopes []string
+}
+
+// APIError is an error response from a provider API, kept with its HTTP
+// status so callers can explain the failure
+type APIError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v (HTTP %d)", e.Err, e.StatusCode)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// HealthChecker is implemented by clients that can verify their connection
+// and credentials with a lightweight authenticated API call
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) (*ProviderHealth, error)
+}
+
+// ChangeRequest describes a merge request (GitLab) or pull request (GitHub)
+type ChangeRequest struct {
+	SourceBranch string
+	TargetBranch string
+	Title        string
+	Description  string
+}
+
+// ChangeRequestCreator is implemented by clients that can open merge or
+// pull requests. It returns the web URL of the new request.
+type ChangeRequestCreator interface {
+	CreateChangeRequest(ctx context.Context, repo *Repository, request ChangeRequest) (string, error)
+}
+
+// Unwrap returns the provider client beneath wrappers such as the metadata
+// cache, for detecting optional capabilities
+func Unwrap(client Client) Client {
+	for {
+		wrapper, ok := client.(interface{ Unwrap() Client })
+		if !ok {
+			return client
+		}
+		client = wrapper.Unwrap()
+	}
+}
+
+// RepositoryGetter is implemented by clients that can look up a single
+// repository by full path. Providers that keep redirects for moved
+// repositories return the repository under its current path.
+type RepositoryGetter interface {
+	GetRepository(ctx context.Context, fullPath string) (*Repository, error)
+}
+
+// RepositorySearcher is implemented by clients that can search the
+// repositories visible to the user by name and description, returning at
+// most limit of the best matches
+type RepositorySearcher interface {
+	SearchRepositories(ctx context.Context, query string, limit int) ([]*Repository, error)
+}
+
+// Visibilities are the visibilities a new repository can have. Internal
+// repositories are visible to every user of the instance or enterprise.
+var Visibilities = []string{"private", "internal", "public"}
+
+// NewRepository describes a repository to create
+type NewRepository struct {
+	// FullPath is the namespace and name of the repository, such as
+	// "group/subgroup/name". A path without a namespace is created under
+	// the authenticated user.
+	FullPath    string
+	Description string
+	Visibility  string // One of Visibilities
+
+	// DefaultBranch names the default branch, or is empty for the
+	// provider's default. Providers that cannot set it on an empty
+	// repository take the first branch pushed to it instead.
+	DefaultBranch string
+}
+
+// RepositoryCreator is implemented by clients that can create repositories
+type RepositoryCreator interface {
+	CreateRepository(ctx context.Context, repo NewRepository) (*Repository, error)
+}
+
+// RepositoryForker is implemented by clients that can fork repositories.
+// ForkRepository forks the repository at fullPath into namespace, or into
+// the user's own namespace when it is empty, and returns the fork with its
+// Parent fields set once it can be cloned. A fork that already exists there
+// is returned as is.
+type RepositoryForker interface {
+	ForkRepository(ctx context.Context, fullPath, namespace string) (*Repository, error)
+}
+
+// RepositoryArchiver is implemented by clients that can archive
+// repositories, making them read-only, and unarchive them again
+type RepositoryArchiver interface {
+	SetArchived(ctx context.Context, repo *Repository, archived bool) error
+}
+
+// Webhook is a webhook registered on a repository
+type Webhook struct {
+	ID  string
+	URL string
+}
+
+// WebhookManager is implemented by clients that can manage repository
+// webhooks. Webhooks it creates fire on branch and tag pushes.
+type WebhookManager interface {
+	ListWebhooks(ctx context.Context, repo *Repository) ([]Webhook, error)
+	CreateWebhook(ctx context.Context, repo *Repository, url, secret string) (Webhook, error)
+	DeleteWebhook(ctx context.Context, repo *Repository, id string) error
+}
+
+// Permissions are the levels of access to a repository, from least to most.
+// Provider roles are mapped to the closest one.
+var Permissions = []string{"read", "triage", "write", "maintain", "admin"}
+
+// Member is a user with access to a repository, directly or through a
+// group, team or organization
+type Member struct {
+	Username   string `json:"username"`
+	Permission string `json:"permission"` // One of Permissions
+
+	// External members are outside collaborators on GitHub, and members
+	// of a GitLab project who are not in its top-level group
+	External bool `json:"external,omitempty"`
+}
+
+// MemberLister is implemented by clients that can list who has access to
+// a repository
+type MemberLister interface {
+	ListMembers(ctx context.Context, repo *Repository) ([]Member, error)
+}
+
+// Issue is an open issue of a repository
+type Issue struct {
+	Number     int // Per repository, as in "group/project#12"
+	Title      string
+	Repository string // Full path of the repository
+	Labels     []string
+	Author     string
+	WebURL     string
+	UpdatedAt  time.Time
+	Provider   string
+}
+
+// IssueFilter narrows down the issues to list
+type IssueFilter struct {
+	Labels []string // Issues must have every one of these labels
+}
+
+// IssueLister is implemented by clients that can list the open issues
+// assigned to the authenticated user across all repositories
+type IssueLister interface {
+	ListAssignedIssues(ctx context.Context, filter IssueFilter) ([]Issue, error)
+}
+
+// LanguageDetector is implemented by clients whose repository listings do
+// not include the primary language, which has to be looked up for each
+// repository. It returns an empty string when the repository has no code.
+type LanguageDetector interface {
+	PrimaryLanguage(ctx context.Context, repo *Repository) (string, error)
+}
+
+// Pipeline states, reduced from the many each provider has
+const (
+	PipelineSuccess  = "success"
+	PipelineFailed   = "failed"
+	PipelineRunning  = "running"  // Also queued, pending or waiting for a manual action
+	PipelineCanceled = "canceled" // Also skipped
+)
+
+// Pipeline is the latest CI pipeline, or set of workflow runs, of a
+// repository's default branch
+type Pipeline struct {
+	Status    string // One of the Pipeline* states
+	WebURL    string
+	UpdatedAt time.Time
+}
+
+// PipelineFetcher is implemented by clients that can fetch the CI status of
+// a repository's default branch. It returns nil when nothing ran on it.
+type PipelineFetcher interface {
+	LatestPipeline(ctx context.Context, repo *Repository) (*Pipeline, error)
+}
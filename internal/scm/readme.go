@@ -0,0 +1,46 @@
+package scm
+
+import (
+	"context"
+	"strings"
+)
+
+// ReadmeFetcher is implemented by clients that can fetch the README of a
+// repository's default branch. It returns an empty string when the
+// repository has none.
+type ReadmeFetcher interface {
+	FetchReadme(ctx context.Context, repo *Repository) (string, error)
+}
+
+// ReadmePreview returns up to maxLines lines from the start of a README for
+// showing in a terminal: badges, HTML and heading underlines are skipped,
+// heading markers dropped and runs of blank lines collapsed
+func ReadmePreview(content string, maxLines int) []string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(content, "\n") {
+		if len(lines) >= maxLines {
+			break
+		}
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			blank = len(lines) > 0
+			continue
+		case strings.HasPrefix(trimmed, "<"), strings.HasPrefix(trimmed, "[!["), strings.HasPrefix(trimmed, "!["):
+			continue
+		case strings.Trim(trimmed, "=-~^*#") == "":
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			line = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+		if blank && len(lines) < maxLines-1 {
+			lines = append(lines, "")
+		}
+		blank = false
+		lines = append(lines, line)
+	}
+	return lines
+}
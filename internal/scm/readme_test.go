@@ -0,0 +1,55 @@
+package scm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadmePreview(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxLines int
+		want     []string
+	}{
+		{
+			name:     "empty",
+			content:  "",
+			maxLines: 5,
+			want:     nil,
+		},
+		{
+			name:     "markdown with badges",
+			content:  "[![CI](https://ci/badge.svg)](https://ci)\n\n# Tool\n\nDoes things.\r\nWell.\n\n\n## Usage\n",
+			maxLines: 10,
+			want:     []string{"Tool", "", "Does things.", "Well.", "", "Usage"},
+		},
+		{
+			name:     "html and underlines",
+			content:  "<p align=\"center\"><img src=\"logo.png\"></p>\nTool\n====\n\nIntro",
+			maxLines: 10,
+			want:     []string{"Tool", "", "Intro"},
+		},
+		{
+			name:     "truncated",
+			content:  "one\ntwo\n\nthree\nfour",
+			maxLines: 3,
+			want:     []string{"one", "two", "three"},
+		},
+		{
+			name:     "keeps indentation",
+			content:  "Example:\n\n    go run .",
+			maxLines: 5,
+			want:     []string{"Example:", "", "    go run ."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ReadmePreview(tt.content, tt.maxLines)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("ReadmePreview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package scm
+
+import (
+	"context"
+	"strings"
+)
+
+// ScopedClient restricts a client to the repositories of one group and its
+// subgroups. Listings outside the group are empty.
+type ScopedClient struct {
+	Client
+	group string
+}
+
+// Scope restricts client to group, or returns it as is when group is empty
+func Scope(client Client, group string) Client {
+	group = strings.Trim(group, "/")
+	if group == "" {
+		return client
+	}
+	return &ScopedClient{Client: client, group: group}
+}
+
+// Unwrap returns the client without its scope
+func (c *ScopedClient) Unwrap() Client {
+	return c.Client
+}
+
+// Group returns the group the client is restricted to
+func (c *ScopedClient) Group() string {
+	return c.group
+}
+
+func (c *ScopedClient) ListAllRepositories(ctx context.Context) ([]*Repository, error) {
+	return c.Client.ListRepositoriesInGroup(ctx, c.group)
+}
+
+func (c *ScopedClient) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*Repository, error) {
+	groupPath = strings.Trim(groupPath, "/")
+	switch {
+	case groupPath == "" || withinGroup(c.group, groupPath):
+		// The scope is the part of the group that can be listed
+		return c.Client.ListRepositoriesInGroup(ctx, c.group)
+	case withinGroup(groupPath, c.group):
+		return c.Client.ListRepositoriesInGroup(ctx, groupPath)
+	default:
+		return nil, nil
+	}
+}
+
+// BuildRepositoryTree returns the provider's tree without the groups and
+// repositories outside the scope
+func (c *ScopedClient) BuildRepositoryTree(ctx context.Context) (*RepositoryTree, error) {
+	tree, err := c.Client.BuildRepositoryTree(ctx)
+	if err != nil || tree == nil {
+		return tree, err
+	}
+	tree.Repositories = nil
+	for name, node := range tree.Groups {
+		if !scopeGroupNode(node, c.group) {
+			delete(tree.Groups, name)
+		}
+	}
+	return tree, nil
+}
+
+// scopeGroupNode removes what is outside group from node and reports whether
+// anything is left
+func scopeGroupNode(node *GroupNode, group string) bool {
+	path := node.Group.FullPath
+	if withinGroup(path, group) {
+		return true
+	}
+	if !withinGroup(group, path) {
+		return false
+	}
+	// An ancestor of the scope keeps only the way down to it
+	node.Repositories = nil
+	for name, sub := range node.SubGroups {
+		if !scopeGroupNode(sub, group) {
+			delete(node.SubGroups, name)
+		}
+	}
+	return len(node.SubGroups) > 0
+}
+
+// withinGroup reports whether path is group or below it
+func withinGroup(path, group string) bool {
+	return path == group || strings.HasPrefix(path, group+"/")
+}
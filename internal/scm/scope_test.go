@@ -0,0 +1,136 @@
+package scm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// groupLister lists the repositories whose path is in the requested group
+type groupLister struct {
+	repos []*Repository
+}
+
+func (l *groupLister) ListAllRepositories(ctx context.Context) ([]*Repository, error) {
+	return l.repos, nil
+}
+
+func (l *groupLister) ListRepositoriesInGroup(ctx context.Context, groupPath string) ([]*Repository, error) {
+	var repos []*Repository
+	for _, repo := range l.repos {
+		if strings.HasPrefix(repo.FullPath, groupPath+"/") {
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+func (l *groupLister) BuildRepositoryTree(ctx context.Context) (*RepositoryTree, error) {
+	tree := &RepositoryTree{Groups: make(map[string]*GroupNode)}
+	for _, repo := range l.repos {
+		parts := strings.Split(repo.FullPath, "/")
+		if len(parts) == 1 {
+			tree.Repositories = append(tree.Repositories, repo)
+			continue
+		}
+		groups := tree.Groups
+		var node *GroupNode
+		for i, part := range parts[:len(parts)-1] {
+			if groups[part] == nil {
+				groups[part] = &GroupNode{
+					Group:     &Group{Name: part, FullPath: strings.Join(parts[:i+1], "/")},
+					SubGroups: make(map[string]*GroupNode),
+				}
+			}
+			node = groups[part]
+			groups = node.SubGroups
+		}
+		node.Repositories = append(node.Repositories, repo)
+	}
+	return tree, nil
+}
+
+func (l *groupLister) GetProviderType() string {
+	return "gitlab"
+}
+
+func fullPaths(repos []*Repository) string {
+	var paths []string
+	for _, repo := range repos {
+		paths = append(paths, repo.FullPath)
+	}
+	return strings.Join(paths, ",")
+}
+
+func TestScope(t *testing.T) {
+	inner := &groupLister{repos: []*Repository{
+		{FullPath: "backend/api"},
+		{FullPath: "backend/services/billing"},
+		{FullPath: "frontend/web"},
+		{FullPath: "standalone"},
+	}}
+	if Scope(inner, "") != Client(inner) {
+		t.Error("Expected no scope for an empty group")
+	}
+	scoped := Scope(inner, "/backend/")
+	if Unwrap(scoped) != Client(inner) {
+		t.Error("Expected the scope to unwrap to the provider client")
+	}
+
+	ctx := context.Background()
+	tests := []struct {
+		group string
+		want  string
+	}{
+		{"", "backend/api,backend/services/billing"},
+		{"backend", "backend/api,backend/services/billing"},
+		{"backend/services", "backend/services/billing"},
+		{"frontend", ""},
+	}
+	for _, tt := range tests {
+		var repos []*Repository
+		var err error
+		if tt.group == "" {
+			repos, err = scoped.ListAllRepositories(ctx)
+		} else {
+			repos, err = scoped.ListRepositoriesInGroup(ctx, tt.group)
+		}
+		if err != nil {
+			t.Fatalf("Listing %q failed: %v", tt.group, err)
+		}
+		if got := fullPaths(repos); got != tt.want {
+			t.Errorf("Listing %q: expected %q, got %q", tt.group, tt.want, got)
+		}
+	}
+
+	// A scope below the requested group lists only the scope
+	nested := Scope(inner, "backend/services")
+	repos, _ := nested.ListRepositoriesInGroup(ctx, "backend")
+	if got := fullPaths(repos); got != "backend/services/billing" {
+		t.Errorf("Expected only the scope to be listed, got %q", got)
+	}
+}
+
+func TestScope_Tree(t *testing.T) {
+	inner := &groupLister{repos: []*Repository{
+		{FullPath: "backend/api"},
+		{FullPath: "backend/services/billing"},
+		{FullPath: "frontend/web"},
+		{FullPath: "standalone"},
+	}}
+	tree, err := Scope(inner, "backend/services").BuildRepositoryTree(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Repositories) != 0 || len(tree.Groups) != 1 {
+		t.Fatalf("Expected only the backend group at the root, got %d repositories and %d groups", len(tree.Repositories), len(tree.Groups))
+	}
+	backend := tree.Groups["backend"]
+	if len(backend.Repositories) != 0 {
+		t.Errorf("Expected backend/api to be outside the scope, got %q", fullPaths(backend.Repositories))
+	}
+	services := backend.SubGroups["services"]
+	if services == nil || fullPaths(services.Repositories) != "backend/services/billing" {
+		t.Errorf("Expected backend/services to keep its repositories, got %+v", services)
+	}
+}
@@ -0,0 +1,71 @@
+package scm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// VersionReporter is implemented by clients that can tell which version of
+// the provider software runs on a self-hosted instance
+type VersionReporter interface {
+	// ServerVersion returns the instance's version, or an empty string for
+	// hosted services such as github.com that do not have one
+	ServerVersion(ctx context.Context) (string, error)
+}
+
+// MinimumVersions are the oldest self-hosted versions whose APIs the
+// clients use, by provider type
+var MinimumVersions = map[string]string{
+	"gitlab": "13.0",
+	"github": "3.0", // GitHub Enterprise Server
+}
+
+// VersionSupported reports whether version of the given provider type is
+// at least the minimum the clients support, and returns that minimum. Unknown
+// provider types and versions that cannot be parsed are assumed supported.
+func VersionSupported(providerType, version string) (minimum string, ok bool) {
+	minimum, known := MinimumVersions[providerType]
+	if !known {
+		return minimum, true
+	}
+	return minimum, VersionAtLeast(version, minimum)
+}
+
+// VersionAtLeast reports whether version is minimum or newer. Versions that
+// are empty or cannot be parsed count as new enough.
+func VersionAtLeast(version, minimum string) bool {
+	have, parsed := parseVersion(version)
+	if !parsed {
+		return true
+	}
+	want, _ := parseVersion(minimum)
+	for i := range want {
+		var part int
+		if i < len(have) {
+			part = have[i]
+		}
+		if part != want[i] {
+			return part > want[i]
+		}
+	}
+	return true
+}
+
+// parseVersion reads the leading dotted numbers of versions such as
+// "16.5.1-ee" or "3.12.4"
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if end := strings.IndexFunc(version, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); end >= 0 {
+		version = version[:end]
+	}
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts, len(parts) > 0
+}
@@ -0,0 +1,30 @@
+package scm
+
+import "testing"
+
+func TestVersionSupported(t *testing.T) {
+	tests := []struct {
+		providerType string
+		version      string
+		want         bool
+	}{
+		{"gitlab", "16.5.1-ee", true},
+		{"gitlab", "13.0.0", true},
+		{"gitlab", "13", true},
+		{"gitlab", "12.10.14-ee", false},
+		{"gitlab", "9.5", false},
+		{"github", "3.12.4", true},
+		{"github", "2.22.0", false},
+		{"github", "", true},
+		{"gitlab", "unknown", true},
+		{"gitea", "1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.providerType+" "+tt.version, func(t *testing.T) {
+			if _, got := VersionSupported(tt.providerType, tt.version); got != tt.want {
+				t.Errorf("VersionSupported(%q, %q) = %v, want %v", tt.providerType, tt.version, got, tt.want)
+			}
+		})
+	}
+}
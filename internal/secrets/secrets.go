@@ -0,0 +1,204 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gitstuff/internal/git"
+)
+
+// maxFileSize skips large files, which are rarely hand-written config
+const maxFileSize = 1 << 20
+
+// Finding is a possible secret found in a repository
+type Finding struct {
+	Rule   string `json:"rule"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Match  string `json:"match"`
+	Commit string `json:"commit,omitempty"`
+}
+
+// Scanner looks for secrets in a repository
+type Scanner interface {
+	Name() string
+	Scan(repoPath string) ([]Finding, error)
+}
+
+// Rule is a regular expression for one kind of secret
+type Rule struct {
+	ID      string
+	Pattern *regexp.Regexp
+}
+
+// DefaultRules detect well-known token formats and private keys. They favour
+// precision over recall; use gitleaks for a thorough scan.
+var DefaultRules = []Rule{
+	{ID: "aws-access-key-id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{ID: "github-token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{ID: "github-fine-grained-token", Pattern: regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{60,}\b`)},
+	{ID: "gitlab-token", Pattern: regexp.MustCompile(`\bgl(?:pat|dt|rt|ptt|cbt)-[A-Za-z0-9_\-]{20,}`)},
+	{ID: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{ID: "slack-webhook", Pattern: regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`)},
+	{ID: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{ID: "stripe-secret-key", Pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{ID: "private-key", Pattern: regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY( BLOCK)?-----`)},
+}
+
+// BuiltinScanner matches rules against the tracked files in the working tree
+type BuiltinScanner struct {
+	Rules []Rule
+}
+
+// NewBuiltinScanner returns a scanner using DefaultRules
+func NewBuiltinScanner() *BuiltinScanner {
+	return &BuiltinScanner{Rules: DefaultRules}
+}
+
+func (s *BuiltinScanner) Name() string {
+	return "builtin"
+}
+
+func (s *BuiltinScanner) Scan(repoPath string) ([]Finding, error) {
+	files, err := git.TrackedFiles(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, file := range files {
+		fileFindings, err := s.scanFile(repoPath, file)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, fileFindings...)
+	}
+	return findings, nil
+}
+
+func (s *BuiltinScanner) scanFile(repoPath, file string) ([]Finding, error) {
+	path := filepath.Join(repoPath, filepath.FromSlash(file))
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // Deleted in the working tree
+		}
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil // Binary file
+	}
+
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, rule := range s.Rules {
+			if match := rule.Pattern.FindString(text); match != "" {
+				findings = append(findings, Finding{Rule: rule.ID, File: file, Line: line, Match: Redact(match)})
+			}
+		}
+	}
+	return findings, scanner.Err()
+}
+
+// GitleaksScanner runs gitleaks over the repository, including its history
+type GitleaksScanner struct {
+	Binary string
+}
+
+// FindGitleaks returns a gitleaks scanner if gitleaks is installed
+func FindGitleaks() (*GitleaksScanner, bool) {
+	binary, err := exec.LookPath("gitleaks")
+	if err != nil {
+		return nil, false
+	}
+	return &GitleaksScanner{Binary: binary}, true
+}
+
+func (s *GitleaksScanner) Name() string {
+	return "gitleaks"
+}
+
+type gitleaksFinding struct {
+	RuleID    string
+	File      string
+	StartLine int
+	Secret    string
+	Commit    string
+}
+
+func (s *GitleaksScanner) Scan(repoPath string) ([]Finding, error) {
+	report, err := os.CreateTemp("", "gitstuff-gitleaks-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gitleaks report: %w", err)
+	}
+	report.Close()
+	defer os.Remove(report.Name())
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.Binary, "detect",
+		"--source", repoPath,
+		"--report-format", "json",
+		"--report-path", report.Name(),
+		"--no-banner",
+		"--exit-code", "0",
+	)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gitleaks failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	data, err := os.ReadFile(report.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gitleaks report: %w", err)
+	}
+	return parseGitleaksReport(bytes.NewReader(data))
+}
+
+func parseGitleaksReport(r io.Reader) ([]Finding, error) {
+	var results []gitleaksFinding
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to parse gitleaks report: %w", err)
+	}
+
+	findings := make([]Finding, 0, len(results))
+	for _, result := range results {
+		findings = append(findings, Finding{
+			Rule:   result.RuleID,
+			File:   result.File,
+			Line:   result.StartLine,
+			Match:  Redact(result.Secret),
+			Commit: result.Commit,
+		})
+	}
+	return findings, nil
+}
+
+// Redact keeps only enough of a secret to recognise it
+func Redact(secret string) string {
+	if len(secret) <= 8 {
+		return "****"
+	}
+	return secret[:4] + "****"
+}
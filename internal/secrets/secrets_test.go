@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Fake secrets are assembled at runtime so this file does not trip scanners
+var (
+	fakeGitHubToken = "ghp" + "_" + strings.Repeat("a1B2", 9)
+	fakeGitLabToken = "glpat" + "-" + "xxxxxxxxxxxxxxxxxxxx"
+	fakeAWSKey      = "AKIA" + "IOSFODNN7EXAMPLE"
+)
+
+func TestDefaultRules(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: "aws_access_key_id = " + fakeAWSKey, want: "aws-access-key-id"},
+		{line: `token: "` + fakeGitHubToken + `"`, want: "github-token"},
+		{line: "GITLAB_TOKEN=" + fakeGitLabToken, want: "gitlab-token"},
+		{line: "-----BEGIN RSA " + "PRIVATE KEY-----", want: "private-key"},
+		{line: "-----BEGIN OPENSSH " + "PRIVATE KEY-----", want: "private-key"},
+		{line: "-----BEGIN PUBLIC KEY-----", want: ""},
+		{line: "password: changeme", want: ""},
+		{line: "ghp_short", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got := ""
+			for _, rule := range DefaultRules {
+				if rule.Pattern.MatchString(tt.line) {
+					got = rule.ID
+					break
+				}
+			}
+			if got != tt.want {
+				t.Errorf("matched rule %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v: %s", args, err, out)
+	}
+}
+
+func TestBuiltinScanner(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+
+	repo := filepath.Join(t.TempDir(), "repo")
+	runGit(t, "init", repo)
+	files := map[string]string{
+		"config/settings.yaml": "name: app\ntoken: " + fakeGitLabToken + "\n",
+		"README.md":            "No secrets here\n",
+		"image.bin":            "\x00\x01" + fakeAWSKey,
+		"untracked.env":        "AWS_KEY=" + fakeAWSKey + "\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGit(t, "-C", repo, "add", "config/settings.yaml", "README.md", "image.bin")
+
+	findings, err := NewBuiltinScanner().Scan(repo)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %+v", findings)
+	}
+	want := Finding{Rule: "gitlab-token", File: "config/settings.yaml", Line: 2, Match: "glpa****"}
+	if findings[0] != want {
+		t.Errorf("Finding = %+v, want %+v", findings[0], want)
+	}
+}
+
+func TestParseGitleaksReport(t *testing.T) {
+	report := `[
+		{"RuleID": "aws-access-token", "File": "deploy.sh", "StartLine": 12, "Secret": "` + fakeAWSKey + `", "Commit": "abc123"}
+	]`
+
+	findings, err := parseGitleaksReport(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("parseGitleaksReport failed: %v", err)
+	}
+	want := Finding{Rule: "aws-access-token", File: "deploy.sh", Line: 12, Match: "AKIA****", Commit: "abc123"}
+	if len(findings) != 1 || findings[0] != want {
+		t.Errorf("Findings = %+v, want %+v", findings, want)
+	}
+
+	if findings, err := parseGitleaksReport(strings.NewReader("")); err != nil || len(findings) != 0 {
+		t.Errorf("Expected empty report to parse, got %v (%v)", findings, err)
+	}
+	if _, err := parseGitleaksReport(strings.NewReader("{")); err == nil {
+		t.Error("Expected error for invalid report")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if got := Redact("short"); got != "****" {
+		t.Errorf("Redact(short) = %q", got)
+	}
+	if got := Redact(fakeGitHubToken); got != "ghp_****" {
+		t.Errorf("Redact(token) = %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitstuff/internal/timing"
+)
+
+// RunFileName is the name of the file in the base directory that holds the
+// summary of the last clone, sync or daemon run
+const RunFileName = ".gitstuff-last-run.json"
+
+// Run summarizes one clone, sync or daemon run
+type Run struct {
+	Command  string    `json:"command"`
+	Args     []string  `json:"args,omitempty"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Seconds  float64   `json:"duration_seconds"`
+
+	Repositories int `json:"repositories"`
+	Cloned       int `json:"cloned"`
+	Updated      int `json:"updated"`
+	Fetched      int `json:"fetched"`
+	Skipped      int `json:"skipped"`
+	Dirty        int `json:"dirty"`
+	Protected    int `json:"protected"`
+	Failed       int `json:"failed"`
+	Interrupted  int `json:"interrupted"`
+	Removed      int `json:"removed"`
+
+	// ExitCode is the exit status of the command, see 'gitstuff clone'
+	ExitCode int `json:"exit_code"`
+
+	// Error is why the run stopped before processing repositories
+	Error    string       `json:"error,omitempty"`
+	Failures []RunFailure `json:"failures,omitempty"`
+
+	// Slowest are the repositories that took longest, slowest first
+	Slowest []RunTiming `json:"slowest,omitempty"`
+
+	// Retried are the repositories that needed more than one attempt
+	Retried []RunRetry `json:"retried,omitempty"`
+}
+
+// RunFailure is a repository that failed during a run
+type RunFailure struct {
+	Repository string `json:"repository"`
+	Provider   string `json:"provider,omitempty"`
+	Error      string `json:"error"`
+}
+
+// RunTiming is how long a repository took during a run
+type RunTiming struct {
+	Repository string  `json:"repository"`
+	Provider   string  `json:"provider"`
+	Seconds    float64 `json:"seconds"`
+}
+
+// RunRetry is a repository whose clone, fetch or pull was retried during a
+// run
+type RunRetry struct {
+	Repository string `json:"repository"`
+	Provider   string `json:"provider"`
+	Attempts   int    `json:"attempts"`
+}
+
+// Duration returns how long the run took
+func (r *Run) Duration() time.Duration {
+	return r.Finished.Sub(r.Started)
+}
+
+// SaveRun writes run as the last run of baseDir
+func SaveRun(baseDir string, run *Run) error {
+	return SaveRunFile(filepath.Join(baseDir, RunFileName), run)
+}
+
+// SaveRunFile writes run as JSON to path, such as a report for CI
+func SaveRunFile(path string, run *Run) error {
+	defer timing.Track(timing.Filesystem, time.Now())
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode last run: %w", err)
+	}
+	if err := writeFile(filepath.Dir(path), filepath.Base(path), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write last run: %w", err)
+	}
+	return nil
+}
+
+// LoadRun reads the last run of baseDir. The error wraps os.ErrNotExist
+// when nothing ran yet.
+func LoadRun(baseDir string) (*Run, error) {
+	defer timing.Track(timing.Filesystem, time.Now())
+	path := filepath.Join(baseDir, RunFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last run: %w", err)
+	}
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse last run %s: %w", path, err)
+	}
+	return &run, nil
+}
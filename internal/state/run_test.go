@@ -0,0 +1,53 @@
+package state
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRun_SaveLoad(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "repos")
+	if _, err := LoadRun(baseDir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected a not-exist error before any run, got %v", err)
+	}
+
+	started := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+	run := &Run{
+		Command:  "sync",
+		Started:  started,
+		Finished: started.Add(90 * time.Second),
+		Seconds:  90,
+		Cloned:   1,
+		Failed:   1,
+		Failures: []RunFailure{{Repository: "team/api", Provider: "gitlab", Error: "exit status 128"}},
+		Slowest:  []RunTiming{{Repository: "team/web", Provider: "gitlab", Seconds: 42.5}},
+	}
+	if err := SaveRun(baseDir, run); err != nil {
+		t.Fatalf("SaveRun failed: %v", err)
+	}
+
+	loaded, err := LoadRun(baseDir)
+	if err != nil {
+		t.Fatalf("LoadRun failed: %v", err)
+	}
+	if loaded.Command != "sync" || loaded.Cloned != 1 || loaded.Duration() != 90*time.Second {
+		t.Errorf("Unexpected run %+v", loaded)
+	}
+	if len(loaded.Failures) != 1 || loaded.Failures[0].Repository != "team/api" {
+		t.Errorf("Expected the failure to be kept, got %+v", loaded.Failures)
+	}
+	if len(loaded.Slowest) != 1 || loaded.Slowest[0].Seconds != 42.5 {
+		t.Errorf("Expected the timing to be kept, got %+v", loaded.Slowest)
+	}
+
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the last run file, got %d entries", len(entries))
+	}
+}
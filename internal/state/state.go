@@ -0,0 +1,330 @@
+// Package state records where each repository was cloned, keyed by its
+// provider ID, so a clone can be found again after the repository is renamed
+// or transferred to another group.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gitstuff/internal/scm"
+	"gitstuff/internal/timing"
+)
+
+// FileName is the name of the state file in the base directory
+const FileName = ".gitstuff-state.json"
+
+// Entry is where a repository was last seen locally
+type Entry struct {
+	FullPath   string `json:"full_path"`
+	Path       string `json:"path"` // Relative to the base directory
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// Annotation is the local note and labels attached to a repository
+type Annotation struct {
+	FullPath string   `json:"full_path"`
+	Note     string   `json:"note,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+}
+
+// Empty reports whether the annotation has neither a note nor labels
+func (a Annotation) Empty() bool {
+	return a.Note == "" && len(a.Labels) == 0
+}
+
+// HasLabel reports whether the annotation has label, ignoring case
+func (a Annotation) HasLabel(label string) bool {
+	for _, l := range a.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisibilityChange is a repository whose visibility differs from the one
+// recorded the last time it was synced
+type VisibilityChange struct {
+	Repo *scm.Repository
+	From string
+	To   string
+}
+
+// Exposed reports whether the change made the repository public
+func (c VisibilityChange) Exposed() bool {
+	return c.To == "public"
+}
+
+// State maps repository keys to their local clones. It is safe for
+// concurrent use.
+type State struct {
+	mu           sync.Mutex
+	baseDir      string
+	changed      bool
+	Repositories map[string]Entry `json:"repositories"`
+
+	// Protocols is the clone protocol, "ssh" or "https", that last worked
+	// for each provider instance
+	Protocols map[string]string `json:"protocols,omitempty"`
+
+	// Annotations are the notes and labels attached to repositories, by
+	// the same keys as Repositories so they follow renames
+	Annotations map[string]Annotation `json:"annotations,omitempty"`
+}
+
+// Load reads the state file in baseDir. A missing file gives an empty state.
+func Load(baseDir string) (*State, error) {
+	defer timing.Track(timing.Filesystem, time.Now())
+	s := &State{baseDir: baseDir, Repositories: make(map[string]Entry)}
+
+	data, err := os.ReadFile(s.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, fmt.Errorf("failed to parse state file %s: %w", s.path(), err)
+	}
+	if s.Repositories == nil {
+		s.Repositories = make(map[string]Entry)
+	}
+	return s, nil
+}
+
+// ProviderKey identifies the provider instance hosting repo
+func ProviderKey(repo *scm.Repository) string {
+	host := ""
+	if parsed, err := url.Parse(repo.WebURL); err == nil {
+		host = parsed.Host
+	}
+	return repo.Provider + "|" + host
+}
+
+// Key identifies a repository across renames. Provider IDs are only unique
+// per instance, so the host is part of the key.
+func Key(repo *scm.Repository) string {
+	return ProviderKey(repo) + "|" + repo.ID
+}
+
+// Lookup returns the recorded entry and absolute path of repo's clone
+func (s *State) Lookup(repo *scm.Repository) (Entry, string, bool) {
+	if s == nil || repo.ID == "" {
+		return Entry{}, "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.Repositories[Key(repo)]
+	if !ok {
+		return Entry{}, "", false
+	}
+	return entry, filepath.Join(s.baseDir, filepath.FromSlash(entry.Path)), true
+}
+
+// Find returns the absolute paths of the recorded clones whose full path is
+// name or, when there are none, ends with "/" and name. Case is ignored.
+func (s *State) Find(name string) []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var exact, suffix []string
+	for _, entry := range s.Repositories {
+		path := filepath.Join(s.baseDir, filepath.FromSlash(entry.Path))
+		switch fullPath := strings.ToLower(entry.FullPath); {
+		case fullPath == strings.ToLower(name):
+			exact = append(exact, path)
+		case strings.HasSuffix(fullPath, "/"+strings.ToLower(name)):
+			suffix = append(suffix, path)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return suffix
+}
+
+// Paths returns the absolute paths of every recorded clone
+func (s *State) Paths() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.Repositories))
+	for _, entry := range s.Repositories {
+		paths = append(paths, filepath.Join(s.baseDir, filepath.FromSlash(entry.Path)))
+	}
+	return paths
+}
+
+// Record notes that repo is cloned at path
+func (s *State) Record(repo *scm.Repository, path string) {
+	if s == nil || repo.ID == "" {
+		return
+	}
+	rel, err := filepath.Rel(s.baseDir, path)
+	if err != nil {
+		return
+	}
+	entry := Entry{FullPath: repo.FullPath, Path: filepath.ToSlash(rel), Visibility: repo.Visibility}
+	key := Key(repo)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.Visibility == "" {
+		// Listings without a visibility, like older cached ones, keep the
+		// last known one
+		entry.Visibility = s.Repositories[key].Visibility
+	}
+	if s.Repositories[key] != entry {
+		s.Repositories[key] = entry
+		s.changed = true
+	}
+}
+
+// VisibilityChanges returns the repositories whose visibility differs from
+// the one recorded for their clone. Repositories without a recorded or
+// reported visibility are left out.
+func (s *State) VisibilityChanges(repos []*scm.Repository) []VisibilityChange {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var changes []VisibilityChange
+	for _, repo := range repos {
+		if repo.ID == "" || repo.Visibility == "" {
+			continue
+		}
+		entry, ok := s.Repositories[Key(repo)]
+		if ok && entry.Visibility != "" && entry.Visibility != repo.Visibility {
+			changes = append(changes, VisibilityChange{Repo: repo, From: entry.Visibility, To: repo.Visibility})
+		}
+	}
+	return changes
+}
+
+// Protocol returns the protocol recorded for repo's provider, if any
+func (s *State) Protocol(repo *scm.Repository) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Protocols[ProviderKey(repo)]
+}
+
+// RecordProtocol notes that protocol worked for repo's provider
+func (s *State) RecordProtocol(repo *scm.Repository, protocol string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := ProviderKey(repo)
+	if s.Protocols[key] != protocol {
+		if s.Protocols == nil {
+			s.Protocols = make(map[string]string)
+		}
+		s.Protocols[key] = protocol
+		s.changed = true
+	}
+}
+
+// Annotation returns the note and labels attached to repo
+func (s *State) Annotation(repo *scm.Repository) Annotation {
+	if s == nil || repo.ID == "" {
+		return Annotation{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Annotations[Key(repo)]
+}
+
+// Annotate attaches annotation to repo, replacing the one it had. An empty
+// annotation removes it.
+func (s *State) Annotate(repo *scm.Repository, annotation Annotation) error {
+	if repo.ID == "" {
+		return fmt.Errorf("repository %s has no provider ID to attach notes to", repo.FullPath)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := Key(repo)
+	if annotation.Empty() {
+		if _, ok := s.Annotations[key]; ok {
+			delete(s.Annotations, key)
+			s.changed = true
+		}
+		return nil
+	}
+	if s.Annotations == nil {
+		s.Annotations = make(map[string]Annotation)
+	}
+	annotation.FullPath = repo.FullPath
+	s.Annotations[key] = annotation
+	s.changed = true
+	return nil
+}
+
+// Save writes the state file if anything was recorded since it was loaded
+func (s *State) Save() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.changed {
+		return nil
+	}
+	defer timing.Track(timing.Filesystem, time.Now())
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state file: %w", err)
+	}
+	if err := writeFile(s.baseDir, FileName, data); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	s.changed = false
+	return nil
+}
+
+// writeFile replaces the file name in dir with data at once, so readers
+// never see it half written
+func writeFile(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, name+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *State) path() string {
+	return filepath.Join(s.baseDir, FileName)
+}
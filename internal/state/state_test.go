@@ -0,0 +1,210 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitstuff/internal/scm"
+)
+
+func TestState_RecordSaveLoad(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "repos")
+	repo := &scm.Repository{ID: "42", FullPath: "team/api", Provider: "gitlab", WebURL: "https://gitlab.com/team/api"}
+
+	s, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, _, ok := s.Lookup(repo); ok {
+		t.Error("Expected no entry in a new state")
+	}
+
+	s.Record(repo, filepath.Join(baseDir, "gitlab", "team", "api"))
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// The repository was transferred and renamed, but keeps its ID
+	moved := &scm.Repository{ID: "42", FullPath: "platform/api-server", Provider: "gitlab", WebURL: "https://gitlab.com/platform/api-server"}
+	entry, path, ok := loaded.Lookup(moved)
+	if !ok {
+		t.Fatal("Expected the entry to be found by ID")
+	}
+	if entry.FullPath != "team/api" || path != filepath.Join(baseDir, "gitlab", "team", "api") {
+		t.Errorf("Unexpected entry %+v at %s", entry, path)
+	}
+
+	other := &scm.Repository{ID: "42", Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api"}
+	if _, _, ok := loaded.Lookup(other); ok {
+		t.Error("Expected IDs on other hosts not to match")
+	}
+}
+
+func TestState_SaveUnchanged(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	s.Record(&scm.Repository{FullPath: "no/id"}, filepath.Join(baseDir, "no", "id"))
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, FileName)); !os.IsNotExist(err) {
+		t.Error("Expected no state file when nothing was recorded")
+	}
+
+	var nilState *State
+	nilState.Record(&scm.Repository{ID: "1"}, baseDir)
+	if err := nilState.Save(); err != nil {
+		t.Errorf("Expected a nil state to be a no-op, got %v", err)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, FileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(baseDir)
+	if err == nil {
+		t.Error("Expected an error for a corrupt state file")
+	}
+	if s == nil || s.Repositories == nil {
+		t.Error("Expected a usable empty state alongside the error")
+	}
+}
+
+func TestState_Protocols(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	repo := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/team/api"}
+	sibling := &scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.example.com/other/web"}
+
+	if got := s.Protocol(repo); got != "" {
+		t.Errorf("Expected no protocol in a new state, got %q", got)
+	}
+	s.RecordProtocol(repo, "https")
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := loaded.Protocol(sibling); got != "https" {
+		t.Errorf("Expected the protocol to apply to the whole provider, got %q", got)
+	}
+	if got := loaded.Protocol(&scm.Repository{Provider: "gitlab", WebURL: "https://gitlab.com/team/api"}); got != "" {
+		t.Errorf("Expected other hosts to have no protocol, got %q", got)
+	}
+}
+
+func TestState_VisibilityChanges(t *testing.T) {
+	baseDir := t.TempDir()
+	s, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	repo := func(id, visibility string) *scm.Repository {
+		return &scm.Repository{ID: id, FullPath: "team/" + id, Provider: "gitlab", WebURL: "https://gitlab.com/team/" + id, Visibility: visibility}
+	}
+	s.Record(repo("1", "private"), filepath.Join(baseDir, "one"))
+	s.Record(repo("2", "internal"), filepath.Join(baseDir, "two"))
+	s.Record(repo("3", "public"), filepath.Join(baseDir, "three"))
+	s.Record(repo("4", ""), filepath.Join(baseDir, "four"))
+
+	current := []*scm.Repository{repo("1", "public"), repo("2", "private"), repo("3", "public"), repo("4", "public"), repo("5", "public")}
+	changes := s.VisibilityChanges(current)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Repo.ID != "1" || changes[0].From != "private" || changes[0].To != "public" || !changes[0].Exposed() {
+		t.Errorf("Unexpected first change %+v", changes[0])
+	}
+	if changes[1].Repo.ID != "2" || changes[1].Exposed() {
+		t.Errorf("Unexpected second change %+v", changes[1])
+	}
+
+	// Recording keeps the last known visibility when none is reported
+	s.Record(repo("1", ""), filepath.Join(baseDir, "one"))
+	if changes := s.VisibilityChanges([]*scm.Repository{repo("1", "private")}); len(changes) != 0 {
+		t.Errorf("Expected no change after recording without a visibility, got %+v", changes)
+	}
+	s.Record(repo("1", "public"), filepath.Join(baseDir, "one"))
+	if changes := s.VisibilityChanges([]*scm.Repository{repo("1", "public")}); len(changes) != 0 {
+		t.Errorf("Expected the new visibility to be recorded, got %+v", changes)
+	}
+
+	var nilState *State
+	if changes := nilState.VisibilityChanges(current); changes != nil {
+		t.Errorf("Expected no changes from a nil state, got %+v", changes)
+	}
+}
+
+func TestState_Find(t *testing.T) {
+	baseDir := t.TempDir()
+	s, _ := Load(baseDir)
+	for i, fullPath := range []string{"team/api", "team/backend/api", "team/Web"} {
+		repo := &scm.Repository{ID: string(rune('1' + i)), FullPath: fullPath, Provider: "gitlab", WebURL: "https://gitlab.com/" + fullPath}
+		s.Record(repo, filepath.Join(baseDir, "gitlab", filepath.FromSlash(fullPath)))
+	}
+
+	if got := s.Find("team/api"); len(got) != 1 || got[0] != filepath.Join(baseDir, "gitlab", "team", "api") {
+		t.Errorf("Expected the exact match only, got %v", got)
+	}
+	if got := s.Find("api"); len(got) != 2 {
+		t.Errorf("Expected both suffix matches, got %v", got)
+	}
+	if got := s.Find("web"); len(got) != 1 {
+		t.Errorf("Expected a case-insensitive match, got %v", got)
+	}
+	if got := s.Find("eb"); len(got) != 0 {
+		t.Errorf("Expected only whole path segments to match, got %v", got)
+	}
+}
+
+func TestState_Annotations(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "repos")
+	repo := &scm.Repository{ID: "42", FullPath: "team/api", Provider: "gitlab", WebURL: "https://gitlab.com/team/api"}
+
+	s, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := s.Annotate(repo, Annotation{Note: "do not upgrade", Labels: []string{"oncall"}}); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := Load(baseDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	renamed := &scm.Repository{ID: "42", FullPath: "platform/api", Provider: "gitlab", WebURL: "https://gitlab.com/platform/api"}
+	annotation := loaded.Annotation(renamed)
+	if annotation.Note != "do not upgrade" || !annotation.HasLabel("OnCall") {
+		t.Errorf("Expected the annotation to follow the rename, got %+v", annotation)
+	}
+
+	if err := loaded.Annotate(renamed, Annotation{}); err != nil {
+		t.Fatalf("Annotate failed: %v", err)
+	}
+	if !loaded.Annotation(repo).Empty() || len(loaded.Annotations) != 0 {
+		t.Errorf("Expected an empty annotation to be removed, got %+v", loaded.Annotations)
+	}
+	if err := loaded.Annotate(&scm.Repository{FullPath: "team/web"}, Annotation{Note: "x"}); err == nil {
+		t.Error("Expected repositories without an ID to be refused")
+	}
+}
@@ -0,0 +1,73 @@
+// Package theme holds the symbols that mark the state of repositories in
+// output, so terminals and ticketing systems that mangle emoji can be given
+// plain text instead.
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultName is the theme used when none is configured
+const DefaultName = "emoji"
+
+// Symbols mark the state of a repository
+type Symbols struct {
+	Cloned  string
+	Dirty   string
+	Missing string
+	Warning string
+	Error   string
+}
+
+var themes = map[string]Symbols{
+	"emoji": {Cloned: "✅", Dirty: "🔄", Missing: "❌", Warning: "⚠️", Error: "❌"},
+	"ascii": {Cloned: "[ok]", Dirty: "[dirty]", Missing: "[missing]", Warning: "[warn]", Error: "[error]"},
+}
+
+var current = themes[DefaultName]
+
+// Set selects the named theme, with each symbol set in overrides replacing
+// the theme's own. An empty name selects the default theme.
+func Set(name string, overrides Symbols) error {
+	if name == "" {
+		name = DefaultName
+	}
+	symbols, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(Available(), ", "))
+	}
+	current = symbols.merge(overrides)
+	return nil
+}
+
+// Get returns the selected symbols
+func Get() Symbols {
+	return current
+}
+
+// Available returns the names of the built-in themes in sorted order
+func Available() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s Symbols) merge(overrides Symbols) Symbols {
+	for _, pair := range []struct{ symbol, override *string }{
+		{&s.Cloned, &overrides.Cloned},
+		{&s.Dirty, &overrides.Dirty},
+		{&s.Missing, &overrides.Missing},
+		{&s.Warning, &overrides.Warning},
+		{&s.Error, &overrides.Error},
+	} {
+		if *pair.override != "" {
+			*pair.symbol = *pair.override
+		}
+	}
+	return s
+}
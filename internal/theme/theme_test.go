@@ -0,0 +1,33 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	t.Cleanup(func() { _ = Set(DefaultName, Symbols{}) })
+
+	if err := Set("", Symbols{}); err != nil {
+		t.Fatalf("Set(\"\") error = %v", err)
+	}
+	if got := Get(); got.Cloned != "✅" || got.Dirty != "🔄" {
+		t.Errorf("Expected the emoji theme by default, got %+v", got)
+	}
+
+	if err := Set("ASCII", Symbols{Dirty: "*"}); err != nil {
+		t.Fatalf("Set(\"ASCII\") error = %v", err)
+	}
+	want := Symbols{Cloned: "[ok]", Dirty: "*", Missing: "[missing]", Warning: "[warn]", Error: "[error]"}
+	if got := Get(); got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+
+	err := Set("neon", Symbols{})
+	if err == nil || !strings.Contains(err.Error(), "available: ascii, emoji") {
+		t.Errorf("Expected an unknown theme error listing the themes, got %v", err)
+	}
+	if got := Get(); got != want {
+		t.Errorf("Expected a failed Set to keep the previous theme, got %+v", got)
+	}
+}
@@ -0,0 +1,134 @@
+// Package timing accumulates how long a command spends in provider APIs, git
+// and the filesystem so it can be reported at the end of the run.
+package timing
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Phase is a category of work that is timed separately
+type Phase int
+
+const (
+	API Phase = iota
+	Git
+	Filesystem
+	phaseCount
+)
+
+func (p Phase) String() string {
+	switch p {
+	case API:
+		return "provider API"
+	case Git:
+		return "git"
+	case Filesystem:
+		return "filesystem"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	started   atomic.Int64
+	durations [phaseCount]atomic.Int64
+	counts    [phaseCount]atomic.Int64
+	cacheHits atomic.Int64
+)
+
+// Start resets all counters and marks the beginning of the command
+func Start() {
+	started.Store(time.Now().UnixNano())
+	for i := range durations {
+		durations[i].Store(0)
+		counts[i].Store(0)
+	}
+	cacheHits.Store(0)
+}
+
+// Track adds the time since start to phase and counts one operation. It is
+// meant to be deferred: defer timing.Track(timing.Git, time.Now())
+func Track(phase Phase, start time.Time) {
+	durations[phase].Add(int64(time.Since(start)))
+	counts[phase].Add(1)
+}
+
+// CacheHit counts an API request that was answered from the local cache
+func CacheHit() {
+	cacheHits.Add(1)
+}
+
+// PhaseReport is the accumulated work for one phase
+type PhaseReport struct {
+	Phase    Phase
+	Duration time.Duration
+	Count    int
+}
+
+// Report is a snapshot of all phases
+type Report struct {
+	Total     time.Duration
+	Phases    []PhaseReport
+	CacheHits int
+}
+
+// Snapshot returns the work recorded since Start
+func Snapshot() Report {
+	report := Report{CacheHits: int(cacheHits.Load())}
+	if start := started.Load(); start != 0 {
+		report.Total = time.Since(time.Unix(0, start))
+	}
+	for phase := Phase(0); phase < phaseCount; phase++ {
+		report.Phases = append(report.Phases, PhaseReport{
+			Phase:    phase,
+			Duration: time.Duration(durations[phase].Load()),
+			Count:    int(counts[phase].Load()),
+		})
+	}
+	return report
+}
+
+// Get returns the report for a single phase
+func (r Report) Get(phase Phase) PhaseReport {
+	for _, p := range r.Phases {
+		if p.Phase == phase {
+			return p
+		}
+	}
+	return PhaseReport{Phase: phase}
+}
+
+// String formats the report on one line. Phase durations are summed across
+// parallel workers, so together they can exceed the total.
+func (r Report) String() string {
+	parts := []string{fmt.Sprintf("total %s", round(r.Total))}
+
+	api := r.Get(API)
+	apiPart := fmt.Sprintf("%s %s (%d requests", API, round(api.Duration), api.Count)
+	if r.CacheHits > 0 {
+		apiPart += fmt.Sprintf(", %d from cache", r.CacheHits)
+	}
+	parts = append(parts, apiPart+")")
+
+	gitPhase := r.Get(Git)
+	parts = append(parts, fmt.Sprintf("%s %s (%d operations)", Git, round(gitPhase.Duration), gitPhase.Count))
+
+	fs := r.Get(Filesystem)
+	parts = append(parts, fmt.Sprintf("%s %s", Filesystem, round(fs.Duration)))
+
+	return strings.Join(parts, " | ")
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,80 @@
+package timing
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTrack(t *testing.T) {
+	Start()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Track(API, time.Now().Add(-10*time.Millisecond))
+		}()
+	}
+	wg.Wait()
+	Track(Git, time.Now().Add(-time.Second))
+	CacheHit()
+
+	report := Snapshot()
+	api := report.Get(API)
+	if api.Count != 10 {
+		t.Errorf("Expected 10 API requests, got %d", api.Count)
+	}
+	if api.Duration < 100*time.Millisecond {
+		t.Errorf("Expected at least 100ms of API time, got %v", api.Duration)
+	}
+	if git := report.Get(Git); git.Count != 1 || git.Duration < time.Second {
+		t.Errorf("Unexpected git phase: %+v", git)
+	}
+	if report.CacheHits != 1 {
+		t.Errorf("Expected 1 cache hit, got %d", report.CacheHits)
+	}
+	if report.Total <= 0 {
+		t.Error("Expected total duration since Start")
+	}
+}
+
+func TestStartResets(t *testing.T) {
+	Track(Filesystem, time.Now())
+	CacheHit()
+	Start()
+
+	report := Snapshot()
+	for _, phase := range report.Phases {
+		if phase.Count != 0 || phase.Duration != 0 {
+			t.Errorf("Expected %s to be reset, got %+v", phase.Phase, phase)
+		}
+	}
+	if report.CacheHits != 0 {
+		t.Errorf("Expected cache hits to be reset, got %d", report.CacheHits)
+	}
+}
+
+func TestReportString(t *testing.T) {
+	report := Report{
+		Total: 3210 * time.Millisecond,
+		Phases: []PhaseReport{
+			{Phase: API, Duration: 2100 * time.Millisecond, Count: 42},
+			{Phase: Git, Duration: 900 * time.Millisecond, Count: 120},
+			{Phase: Filesystem, Duration: 1500 * time.Microsecond, Count: 300},
+		},
+		CacheHits: 30,
+	}
+
+	want := "total 3.21s | provider API 2.1s (42 requests, 30 from cache) | git 900ms (120 operations) | filesystem 1.5ms"
+	if got := report.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	report.CacheHits = 0
+	if strings.Contains(report.String(), "from cache") {
+		t.Error("Expected cache hits to be omitted when there are none")
+	}
+}
@@ -0,0 +1,499 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"gitstuff/internal/i18n"
+	"gitstuff/internal/scm"
+)
+
+// RowKind distinguishes the lines of the browse tree
+type RowKind int
+
+const (
+	RowProvider RowKind = iota
+	RowGroup
+	RowRepository
+)
+
+// Row is one line of the tree: a provider, a group or a repository. Rows are
+// listed depth first, so a row's descendants are the rows that follow it
+// with a greater depth.
+type Row struct {
+	Kind   RowKind
+	Depth  int
+	Label  string
+	Repo   *scm.Repository
+	Status string
+}
+
+// Action is what the user asked to do with the selected repositories
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionClone
+	ActionPull
+	ActionOpen
+)
+
+// Result is returned when the browser exits
+type Result struct {
+	Action Action
+	Repos  []*scm.Repository
+}
+
+// StatusUpdate replaces the status shown for a repository while the browser
+// runs, such as when its clone changes on disk
+type StatusUpdate struct {
+	Repo   *scm.Repository
+	Status string
+}
+
+// ReadmeFunc fetches the first lines of a repository's README for the
+// detail pane. It is called outside the UI loop.
+type ReadmeFunc func(repo *scm.Repository) ([]string, error)
+
+// PreviewLines is how many README lines the detail pane shows
+const PreviewLines = 5
+
+// readme is the README preview of one repository, once it has been fetched
+type readme struct {
+	lines  []string
+	err    error
+	loaded bool
+}
+
+// readmeMsg delivers a fetched README preview
+type readmeMsg struct {
+	repo  *scm.Repository
+	lines []string
+	err   error
+}
+
+var (
+	cursorStyle = lipgloss.NewStyle().Reverse(true)
+	headerStyle = lipgloss.NewStyle().Bold(true)
+	helpStyle   = lipgloss.NewStyle().Faint(true)
+)
+
+// Model is the bubbletea model behind gitstuff browse
+type Model struct {
+	rows      []Row
+	visible   []int
+	selected  map[int]bool
+	cursor    int
+	offset    int
+	height    int
+	width     int
+	filter    string
+	filtering bool
+	result    Result
+
+	// readmeFunc fills the detail pane below the tree; without it there is
+	// no pane
+	readmeFunc ReadmeFunc
+	readmes    map[*scm.Repository]*readme
+
+	// updates deliver new statuses for repository rows
+	updates <-chan StatusUpdate
+}
+
+// NewModel returns a browser over rows with nothing selected
+func NewModel(rows []Row) *Model {
+	m := &Model{rows: rows, selected: make(map[int]bool), height: 20}
+	m.applyFilter()
+	return m
+}
+
+// Run shows the browser full screen until the user quits or picks an action.
+// When readmeFunc is not nil, the README of the repository under the cursor
+// is previewed below the tree. Statuses received from updates, which may be
+// nil, replace those of the rows.
+func Run(rows []Row, readmeFunc ReadmeFunc, updates <-chan StatusUpdate) (Result, error) {
+	m := NewModel(rows)
+	m.readmeFunc = readmeFunc
+	m.updates = updates
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run browser: %w", err)
+	}
+	return final.(*Model).result, nil
+}
+
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.loadReadme(), m.waitForUpdate())
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the header, filter line, detail pane and help
+		m.height = max(msg.Height-4-m.paneHeight(), 1)
+		m.width = msg.Width
+		m.scroll()
+	case readmeMsg:
+		m.readmes[msg.repo] = &readme{lines: msg.lines, err: msg.err, loaded: true}
+	case StatusUpdate:
+		for i := range m.rows {
+			if m.rows[i].Repo == msg.Repo {
+				m.rows[i].Status = msg.Status
+			}
+		}
+		return m, m.waitForUpdate()
+	case tea.KeyMsg:
+		var cmd tea.Cmd
+		if m.filtering {
+			cmd = m.updateFilter(msg)
+		} else {
+			cmd = m.updateBrowse(msg)
+		}
+		if cmd == nil {
+			cmd = m.loadReadme()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// waitForUpdate waits for the next status update, if there are any
+func (m *Model) waitForUpdate() tea.Cmd {
+	if m.updates == nil {
+		return nil
+	}
+	updates := m.updates
+	return func() tea.Msg {
+		update, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return update
+	}
+}
+
+// current returns the repository under the cursor, or nil when the cursor
+// is on a provider or group
+func (m *Model) current() *scm.Repository {
+	if len(m.visible) == 0 {
+		return nil
+	}
+	return m.rows[m.visible[m.cursor]].Repo
+}
+
+// loadReadme starts fetching the README of the repository under the cursor
+// unless it has been fetched or requested already
+func (m *Model) loadReadme() tea.Cmd {
+	repo := m.current()
+	if m.readmeFunc == nil || repo == nil {
+		return nil
+	}
+	if m.readmes == nil {
+		m.readmes = make(map[*scm.Repository]*readme)
+	}
+	if _, ok := m.readmes[repo]; ok {
+		return nil
+	}
+	m.readmes[repo] = &readme{}
+	fetch := m.readmeFunc
+	return func() tea.Msg {
+		lines, err := fetch(repo)
+		return readmeMsg{repo: repo, lines: lines, err: err}
+	}
+}
+
+func (m *Model) paneHeight() int {
+	if m.readmeFunc == nil {
+		return 0
+	}
+	return PreviewLines + 1
+}
+
+func (m *Model) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return tea.Quit
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+		m.applyFilter()
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+			m.applyFilter()
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+		m.applyFilter()
+	case tea.KeyUp, tea.KeyDown:
+		return m.updateBrowse(msg)
+	}
+	return nil
+}
+
+func (m *Model) updateBrowse(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return tea.Quit
+	case "esc":
+		if m.filter != "" {
+			m.filter = ""
+			m.applyFilter()
+		}
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.height)
+	case "pgdown":
+		m.move(m.height)
+	case "home", "g":
+		m.move(-len(m.visible))
+	case "end", "G":
+		m.move(len(m.visible))
+	case "/":
+		m.filtering = true
+	case " ", "x":
+		m.toggleCurrent()
+	case "a":
+		m.toggleAll()
+	case "c":
+		return m.finish(ActionClone)
+	case "p":
+		return m.finish(ActionPull)
+	case "o":
+		return m.finish(ActionOpen)
+	}
+	return nil
+}
+
+func (m *Model) move(delta int) {
+	if len(m.visible) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.visible)-1)
+	m.scroll()
+}
+
+func (m *Model) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// applyFilter shows the repositories whose full path contains every word of
+// the filter, along with the providers and groups above them
+func (m *Model) applyFilter() {
+	terms := strings.Fields(strings.ToLower(m.filter))
+
+	keep := make([]bool, len(m.rows))
+	for i, row := range m.rows {
+		if row.Kind != RowRepository || !matchesTerms(row.Repo, terms) {
+			continue
+		}
+		keep[i] = true
+		depth := row.Depth
+		for j := i - 1; j >= 0 && depth > 0; j-- {
+			if m.rows[j].Depth < depth {
+				keep[j] = true
+				depth = m.rows[j].Depth
+			}
+		}
+	}
+
+	m.visible = m.visible[:0]
+	for i, ok := range keep {
+		if ok || len(terms) == 0 {
+			m.visible = append(m.visible, i)
+		}
+	}
+	m.cursor = 0
+	m.offset = 0
+}
+
+func matchesTerms(repo *scm.Repository, terms []string) bool {
+	path := strings.ToLower(repo.FullPath)
+	for _, term := range terms {
+		if !strings.Contains(path, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// visibleRepos returns the visible repository rows at or below the row at
+// visible index i
+func (m *Model) visibleRepos(i int) []int {
+	start := m.visible[i]
+	var repos []int
+	for _, index := range m.visible[i:] {
+		if index != start && m.rows[index].Depth <= m.rows[start].Depth {
+			break
+		}
+		if m.rows[index].Kind == RowRepository {
+			repos = append(repos, index)
+		}
+	}
+	return repos
+}
+
+func (m *Model) toggleCurrent() {
+	if len(m.visible) == 0 {
+		return
+	}
+	m.toggle(m.visibleRepos(m.cursor))
+}
+
+func (m *Model) toggleAll() {
+	var repos []int
+	for _, index := range m.visible {
+		if m.rows[index].Kind == RowRepository {
+			repos = append(repos, index)
+		}
+	}
+	m.toggle(repos)
+}
+
+// toggle selects every repository in repos, or deselects them all when they
+// are already selected
+func (m *Model) toggle(repos []int) {
+	allSelected := true
+	for _, index := range repos {
+		allSelected = allSelected && m.selected[index]
+	}
+	for _, index := range repos {
+		if allSelected {
+			delete(m.selected, index)
+		} else {
+			m.selected[index] = true
+		}
+	}
+}
+
+// finish exits with action applied to the selection, or to the repository
+// under the cursor when nothing is selected
+func (m *Model) finish(action Action) tea.Cmd {
+	var repos []*scm.Repository
+	for i, row := range m.rows {
+		if m.selected[i] {
+			repos = append(repos, row.Repo)
+		}
+	}
+	if len(repos) == 0 && len(m.visible) > 0 {
+		for _, index := range m.visibleRepos(m.cursor) {
+			repos = append(repos, m.rows[index].Repo)
+		}
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+
+	m.result = Result{Action: action, Repos: repos}
+	return tea.Quit
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+
+	repoCount := 0
+	for _, row := range m.rows {
+		if row.Kind == RowRepository {
+			repoCount++
+		}
+	}
+	b.WriteString(headerStyle.Render(i18n.T("browse.header", repoCount, len(m.selected))))
+	b.WriteString("\n")
+
+	switch {
+	case m.filtering:
+		b.WriteString(i18n.T("browse.filter", m.filter+"█"))
+	case m.filter != "":
+		b.WriteString(i18n.T("browse.filter", m.filter))
+	}
+	b.WriteString("\n")
+
+	if len(m.visible) == 0 {
+		b.WriteString(i18n.T("browse.no_matches"))
+		b.WriteString("\n")
+	}
+	end := min(m.offset+m.height, len(m.visible))
+	for i := m.offset; i < end; i++ {
+		line := m.renderRow(m.visible[i])
+		if i == m.cursor {
+			line = cursorStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if m.readmeFunc != nil {
+		b.WriteString(m.renderPane())
+	}
+	b.WriteString(helpStyle.Render(i18n.T("browse.help")))
+	return b.String()
+}
+
+// renderPane shows the README preview of the repository under the cursor,
+// padded to the same height whatever the cursor is on
+func (m *Model) renderPane() string {
+	lines := make([]string, m.paneHeight())
+	if repo := m.current(); repo != nil {
+		lines[0] = headerStyle.Render("📖 " + repo.FullPath)
+		preview := m.readmes[repo]
+		switch {
+		case preview == nil || !preview.loaded:
+			lines[1] = helpStyle.Render(i18n.T("browse.readme_loading"))
+		case preview.err != nil:
+			lines[1] = helpStyle.Render(i18n.T("browse.readme_failed", preview.err))
+		case len(preview.lines) == 0:
+			lines[1] = helpStyle.Render(i18n.T("browse.readme_none"))
+		default:
+			for i, line := range preview.lines[:min(len(preview.lines), PreviewLines)] {
+				lines[i+1] = m.truncate("   " + line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// truncate cuts line to the width of the terminal so a long README line
+// does not wrap and push the tree up
+func (m *Model) truncate(line string) string {
+	runes := []rune(line)
+	if m.width <= 1 || len(runes) < m.width {
+		return line
+	}
+	return string(runes[:m.width-1]) + "…"
+}
+
+func (m *Model) renderRow(index int) string {
+	row := m.rows[index]
+	indent := strings.Repeat("  ", row.Depth)
+
+	switch row.Kind {
+	case RowProvider:
+		return fmt.Sprintf("%s🌐 %s", indent, row.Label)
+	case RowGroup:
+		return fmt.Sprintf("%s📂 %s/", indent, row.Label)
+	}
+
+	check := "[ ]"
+	if m.selected[index] {
+		check = "[x]"
+	}
+	line := fmt.Sprintf("%s%s 📁 %s", indent, check, row.Label)
+	if row.Status != "" {
+		line += " - " + row.Status
+	}
+	return line
+}
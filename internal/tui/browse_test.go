@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"gitstuff/internal/scm"
+)
+
+func testRows() []Row {
+	repo := func(depth int, fullPath string) Row {
+		name := fullPath[strings.LastIndex(fullPath, "/")+1:]
+		return Row{Kind: RowRepository, Depth: depth, Label: name, Repo: &scm.Repository{Name: name, FullPath: fullPath}, Status: "✅"}
+	}
+	return []Row{
+		{Kind: RowProvider, Label: "GITLAB"},
+		{Kind: RowGroup, Depth: 1, Label: "backend"},
+		repo(2, "backend/api"),
+		repo(2, "backend/worker"),
+		{Kind: RowGroup, Depth: 2, Label: "tools"},
+		repo(3, "backend/tools/cli"),
+		{Kind: RowGroup, Depth: 1, Label: "frontend"},
+		repo(2, "frontend/web"),
+	}
+}
+
+func keys(m *Model, input ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, key := range input {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+func repoPaths(repos []*scm.Repository) string {
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.FullPath)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestModel_Filter(t *testing.T) {
+	m := NewModel(testRows())
+	if len(m.visible) != 8 {
+		t.Fatalf("Expected all rows visible, got %d", len(m.visible))
+	}
+
+	keys(m, "/", "t", "o", "o", "l")
+	if !m.filtering || m.filter != "tool" {
+		t.Fatalf("Expected filter mode with 'tool', got %v %q", m.filtering, m.filter)
+	}
+	// Provider, backend, tools and cli
+	if got := m.visible; len(got) != 4 || got[3] != 5 {
+		t.Errorf("Expected cli and its ancestors, got %v", got)
+	}
+
+	keys(m, "backspace", "backspace", "backspace", "backspace", "w", " ", "zz")
+	if m.filter != "w zz" {
+		t.Errorf("Expected filter 'w zz', got %q", m.filter)
+	}
+	if len(m.visible) != 0 {
+		t.Errorf("Expected no matches for 'w zz', got %v", m.visible)
+	}
+
+	keys(m, "esc")
+	if m.filtering || m.filter != "" || len(m.visible) != 8 {
+		t.Errorf("Expected esc to clear the filter, got %v %q %d", m.filtering, m.filter, len(m.visible))
+	}
+}
+
+func TestModel_SelectAndAct(t *testing.T) {
+	m := NewModel(testRows())
+
+	// Select the backend group, then deselect worker
+	keys(m, "down", " ", "down", "down", " ")
+	if len(m.selected) != 2 || !m.selected[2] || !m.selected[5] {
+		t.Fatalf("Expected api and cli selected, got %v", m.selected)
+	}
+
+	cmd := keys(m, "c")
+	if cmd == nil {
+		t.Fatal("Expected clone to quit the browser")
+	}
+	if m.result.Action != ActionClone || repoPaths(m.result.Repos) != "backend/api,backend/tools/cli" {
+		t.Errorf("Unexpected result: %v %s", m.result.Action, repoPaths(m.result.Repos))
+	}
+}
+
+func TestModel_ActOnCursorWithoutSelection(t *testing.T) {
+	m := NewModel(testRows())
+
+	keys(m, "/", "web", "enter")
+	if m.filtering {
+		t.Fatal("Expected enter to leave filter mode")
+	}
+	keys(m, "down", "down")
+	if cmd := keys(m, "o"); cmd == nil {
+		t.Fatal("Expected open to quit the browser")
+	}
+	if m.result.Action != ActionOpen || repoPaths(m.result.Repos) != "frontend/web" {
+		t.Errorf("Unexpected result: %v %s", m.result.Action, repoPaths(m.result.Repos))
+	}
+}
+
+func TestModel_SelectAll(t *testing.T) {
+	m := NewModel(testRows())
+
+	keys(m, "a")
+	if len(m.selected) != 4 {
+		t.Errorf("Expected all 4 repositories selected, got %d", len(m.selected))
+	}
+	keys(m, "a")
+	if len(m.selected) != 0 {
+		t.Errorf("Expected second 'a' to deselect all, got %d", len(m.selected))
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m := NewModel(testRows())
+	keys(m, "down", "down", " ")
+
+	view := m.View()
+	for _, want := range []string{"4 repositories, 1 selected", "📂 backend/", "[x] 📁 api - ✅", "[ ] 📁 worker"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected view to contain %q, got:\n%s", want, view)
+		}
+	}
+}
+
+func TestModel_Scroll(t *testing.T) {
+	m := NewModel(testRows())
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 7})
+	if m.height != 3 {
+		t.Fatalf("Expected 3 rows of tree, got %d", m.height)
+	}
+
+	keys(m, "G")
+	if m.cursor != 7 || m.offset != 5 {
+		t.Errorf("Expected cursor 7 and offset 5, got %d and %d", m.cursor, m.offset)
+	}
+	keys(m, "g")
+	if m.cursor != 0 || m.offset != 0 {
+		t.Errorf("Expected cursor and offset 0, got %d and %d", m.cursor, m.offset)
+	}
+}
+
+func TestModel_ReadmePane(t *testing.T) {
+	fetched := 0
+	m := NewModel(testRows())
+	m.readmeFunc = func(repo *scm.Repository) ([]string, error) {
+		fetched++
+		if repo.FullPath == "backend/worker" {
+			return nil, nil
+		}
+		return []string{"API", "", "Serves " + repo.FullPath}, nil
+	}
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	if m.height != 20-4-PreviewLines-1 {
+		t.Errorf("Expected room for the detail pane, got %d rows of tree", m.height)
+	}
+
+	if cmd := m.Init(); cmd != nil {
+		t.Error("Expected no README to be fetched for a provider row")
+	}
+	cmd := keys(m, "down", "down")
+	if cmd == nil {
+		t.Fatal("Expected the README of the repository under the cursor to be fetched")
+	}
+	if view := m.View(); !strings.Contains(view, "📖 backend/api") || !strings.Contains(view, "Loading README") {
+		t.Errorf("Expected the pane to show the README loading, got:\n%s", view)
+	}
+
+	m.Update(cmd())
+	if view := m.View(); !strings.Contains(view, "   Serves backend/api") {
+		t.Errorf("Expected the README preview in the pane, got:\n%s", view)
+	}
+
+	m.Update(keys(m, "down")())
+	if view := m.View(); !strings.Contains(view, "No README") {
+		t.Errorf("Expected the pane to report a missing README, got:\n%s", view)
+	}
+
+	if cmd := keys(m, "up"); cmd != nil || fetched != 2 {
+		t.Errorf("Expected a fetched README to be reused, fetched %d times", fetched)
+	}
+}
+
+func TestModel_StatusUpdates(t *testing.T) {
+	rows := testRows()
+	updates := make(chan StatusUpdate, 1)
+	m := NewModel(rows)
+	m.updates = updates
+
+	updates <- StatusUpdate{Repo: rows[3].Repo, Status: "📝 uncommitted changes"}
+	msg := m.waitForUpdate()()
+	if _, cmd := m.Update(msg); cmd == nil {
+		t.Error("Expected the browser to keep waiting for updates")
+	}
+	if view := m.View(); !strings.Contains(view, "📁 worker - 📝 uncommitted changes") || !strings.Contains(view, "📁 api - ✅") {
+		t.Errorf("Expected only the updated row to change, got:\n%s", view)
+	}
+}
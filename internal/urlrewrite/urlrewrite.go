@@ -0,0 +1,68 @@
+// Package urlrewrite rewrites the URLs repositories are cloned from before
+// they are handed to git, such as to use a host alias from ~/.ssh/config or
+// an internal mirror.
+package urlrewrite
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SSHHost replaces the host of an SSH URL, in scp-like form
+// (git@gitlab.com:team/api.git) or as an ssh:// URL, with host. The user and
+// any port are kept. Other URLs are returned as they are.
+func SSHHost(rawURL, host string) string {
+	if host == "" {
+		return rawURL
+	}
+	if strings.HasPrefix(rawURL, "ssh://") {
+		parsed, err := url.Parse(rawURL)
+		if err != nil || parsed.Host == "" {
+			return rawURL
+		}
+		if port := parsed.Port(); port != "" {
+			parsed.Host = host + ":" + port
+		} else {
+			parsed.Host = host
+		}
+		return parsed.String()
+	}
+
+	authority, path, ok := scpLike(rawURL)
+	if !ok {
+		return rawURL
+	}
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		return authority[:at+1] + host + ":" + path
+	}
+	return host + ":" + path
+}
+
+// InsteadOf replaces the longest prefix of rawURL found in rewrites with
+// its value, the way git's url.<base>.insteadOf does. URLs matching no
+// prefix are returned as they are.
+func InsteadOf(rawURL string, rewrites map[string]string) string {
+	longest := ""
+	for prefix := range rewrites {
+		if len(prefix) > len(longest) && strings.HasPrefix(rawURL, prefix) {
+			longest = prefix
+		}
+	}
+	if longest == "" {
+		return rawURL
+	}
+	return rewrites[longest] + strings.TrimPrefix(rawURL, longest)
+}
+
+// scpLike splits an scp-like URL such as git@gitlab.com:team/api.git into
+// its user and host, and its path
+func scpLike(rawURL string) (authority, path string, ok bool) {
+	if strings.Contains(rawURL, "://") {
+		return "", "", false
+	}
+	colon := strings.Index(rawURL, ":")
+	if colon <= 0 || strings.Contains(rawURL[:colon], "/") {
+		return "", "", false
+	}
+	return rawURL[:colon], rawURL[colon+1:], true
+}
@@ -0,0 +1,52 @@
+package urlrewrite
+
+import "testing"
+
+func TestSSHHost(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+		want string
+	}{
+		{"git@gitlab.com:team/api.git", "gitlab-work", "git@gitlab-work:team/api.git"},
+		{"gitlab.com:team/api.git", "gitlab-work", "gitlab-work:team/api.git"},
+		{"ssh://git@gitlab.example.com/team/api.git", "gitlab-work", "ssh://git@gitlab-work/team/api.git"},
+		{"ssh://git@gitlab.example.com:2222/team/api.git", "gitlab-work", "ssh://git@gitlab-work:2222/team/api.git"},
+		{"https://gitlab.com/team/api.git", "gitlab-work", "https://gitlab.com/team/api.git"},
+		{"/srv/git/api.git", "gitlab-work", "/srv/git/api.git"},
+		{"git@gitlab.com:team/api.git", "", "git@gitlab.com:team/api.git"},
+	}
+
+	for _, tt := range tests {
+		if got := SSHHost(tt.url, tt.host); got != tt.want {
+			t.Errorf("SSHHost(%q, %q) = %q, want %q", tt.url, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestInsteadOf(t *testing.T) {
+	rewrites := map[string]string{
+		"https://gitlab.com/":      "https://mirror.internal/gitlab/",
+		"https://gitlab.com/team/": "https://team-mirror.internal/",
+		"git@github.com:":          "ssh://git@proxy.internal/github/",
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://gitlab.com/other/tool.git", "https://mirror.internal/gitlab/other/tool.git"},
+		{"https://gitlab.com/team/api.git", "https://team-mirror.internal/api.git"},
+		{"git@github.com:octo/tool.git", "ssh://git@proxy.internal/github/octo/tool.git"},
+		{"https://github.com/octo/tool.git", "https://github.com/octo/tool.git"},
+	}
+
+	for _, tt := range tests {
+		if got := InsteadOf(tt.url, rewrites); got != tt.want {
+			t.Errorf("InsteadOf(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+	if got := InsteadOf(tests[0].url, nil); got != tests[0].url {
+		t.Errorf("Expected no rewrites to leave the URL alone, got %q", got)
+	}
+}
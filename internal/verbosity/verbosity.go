@@ -1,9 +1,14 @@
 package verbosity
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"gitstuff/internal/redact"
 )
 
 type Level int
@@ -39,10 +44,131 @@ func IsEnabled(level Level) bool {
 	return currentLevel >= level
 }
 
-func Print(level Level, format string, args ...interface{}) {
+// Format is how log messages are written
+type Format int
+
+const (
+	// FormatText writes messages for people, with an emoji prefix per level
+	FormatText Format = iota
+	// FormatJSON writes one JSON object per message, for log collectors
+	FormatJSON
+)
+
+var currentFormat = FormatText
+var timestamps bool
+
+// SetFormat selects the log format by name: "text" or "json"
+func SetFormat(name string) error {
+	switch strings.ToLower(name) {
+	case "", "text":
+		currentFormat = FormatText
+	case "json":
+		currentFormat = FormatJSON
+	default:
+		return fmt.Errorf("unknown log format %q (use text or json)", name)
+	}
+	return nil
+}
+
+func GetFormat() Format {
+	return currentFormat
+}
+
+// SetTimestamps prefixes text messages with the time. JSON messages always
+// carry it.
+func SetTimestamps(enabled bool) {
+	timestamps = enabled
+}
+
+func (l Level) String() string {
+	switch l {
+	case Normal:
+		return "notice"
+	case InfoLevel:
+		return "info"
+	case DebugLevel:
+		return "debug"
+	case TraceLevel:
+		return "trace"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Field is a key and value attached to a log message, such as the
+// repository it is about
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger writes messages with a fixed set of fields. The zero value has no
+// fields; the package-level functions use it.
+type Logger struct {
+	fields []Field
+}
+
+// With returns a logger that adds key=value to every message
+func With(key string, value interface{}) *Logger {
+	return (&Logger{}).With(key, value)
+}
+
+// WithRepo returns a logger for messages about one repository
+func WithRepo(fullPath, provider string) *Logger {
+	return With("repo", fullPath).With("provider", provider)
+}
+
+func (l *Logger) With(key string, value interface{}) *Logger {
+	fields := make([]Field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	return &Logger{fields: append(fields, Field{Key: key, Value: value})}
+}
+
+func (l *Logger) Print(level Level, format string, args ...interface{}) {
+	l.print(level, nil, format, args...)
+}
+
+func (l *Logger) Info(format string, args ...interface{}) {
+	l.print(InfoLevel, nil, format, args...)
+}
+
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.print(DebugLevel, nil, format, args...)
+}
+
+func (l *Logger) Trace(format string, args ...interface{}) {
+	l.print(TraceLevel, nil, format, args...)
+}
+
+func (l *Logger) DebugTiming(startTime time.Time, format string, args ...interface{}) {
+	l.printWithTiming(DebugLevel, startTime, format, args...)
+}
+
+func (l *Logger) printWithTiming(level Level, startTime time.Time, format string, args ...interface{}) {
+	if !IsEnabled(level) {
+		return
+	}
+	elapsed := time.Since(startTime)
+	if currentFormat == FormatJSON {
+		l.print(level, []Field{{Key: "elapsed_ms", Value: elapsed.Milliseconds()}}, format, args...)
+		return
+	}
+	l.print(level, nil, "%s (took %v)", fmt.Sprintf(format, args...), elapsed)
+}
+
+func (l *Logger) print(level Level, extra []Field, format string, args ...interface{}) {
 	if !IsEnabled(level) {
 		return
 	}
+	message := redact.String(fmt.Sprintf(format, args...))
+	fields := l.fields
+	if len(extra) > 0 {
+		fields = append(append([]Field(nil), fields...), extra...)
+	}
+	if currentFormat == FormatJSON {
+		// Every level goes to stderr, so stdout stays command output
+		fmt.Fprintln(os.Stderr, formatJSON(time.Now(), level, message, fields))
+		return
+	}
 
 	var prefix string
 	switch level {
@@ -55,15 +181,65 @@ func Print(level Level, format string, args ...interface{}) {
 	case TraceLevel:
 		prefix = "🔍 [TRACE] "
 	}
-
-	message := fmt.Sprintf(format, args...)
+	line := prefix + message + formatTextFields(fields)
+	if timestamps {
+		line = time.Now().Format("2006-01-02 15:04:05.000") + " " + line
+	}
 	if prefix != "" {
-		fmt.Fprintf(os.Stderr, "%s%s\n", prefix, message)
+		fmt.Fprintln(os.Stderr, line)
 	} else {
-		fmt.Println(message)
+		fmt.Println(line)
 	}
 }
 
+func formatTextFields(fields []Field) string {
+	var b strings.Builder
+	for _, field := range fields {
+		value := redact.String(fmt.Sprint(field.Value))
+		if value == "" || strings.ContainsAny(value, " \t\"=") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, " %s=%s", field.Key, value)
+	}
+	return b.String()
+}
+
+func formatJSON(now time.Time, level Level, message string, fields []Field) string {
+	var b strings.Builder
+	b.WriteString(`{"time":`)
+	writeJSON(&b, now.Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeJSON(&b, level.String())
+	b.WriteString(`,"msg":`)
+	writeJSON(&b, message)
+	for _, field := range fields {
+		b.WriteByte(',')
+		writeJSON(&b, field.Key)
+		b.WriteByte(':')
+		if s, ok := field.Value.(string); ok {
+			writeJSON(&b, redact.String(s))
+		} else {
+			writeJSON(&b, field.Value)
+		}
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func writeJSON(b *strings.Builder, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	b.Write(data)
+}
+
+var defaultLogger = &Logger{}
+
+func Print(level Level, format string, args ...interface{}) {
+	defaultLogger.print(level, nil, format, args...)
+}
+
 func Info(format string, args ...interface{}) {
 	Print(InfoLevel, format, args...)
 }
@@ -81,12 +257,7 @@ func Printf(format string, args ...interface{}) {
 }
 
 func PrintWithTiming(level Level, startTime time.Time, format string, args ...interface{}) {
-	if !IsEnabled(level) {
-		return
-	}
-	elapsed := time.Since(startTime)
-	message := fmt.Sprintf(format, args...)
-	Print(level, "%s (took %v)", message, elapsed)
+	defaultLogger.printWithTiming(level, startTime, format, args...)
 }
 
 func DebugTiming(startTime time.Time, format string, args ...interface{}) {
@@ -0,0 +1,217 @@
+// Package watch keeps the status of local clones up to date by watching
+// their files, so status views can show it without running git status over
+// every repository each time they are drawn.
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gitstuff/internal/git"
+	"gitstuff/internal/verbosity"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Debounce is how long a repository must stay quiet after a change before
+// its status is checked again, so a checkout or build that touches many
+// files costs one check
+var Debounce = 250 * time.Millisecond
+
+// Change is the status of a repository after it was checked again
+type Change struct {
+	Path   string
+	Status *git.Status
+	Err    error
+}
+
+// Watcher watches clones and caches their status. It is safe for
+// concurrent use.
+type Watcher struct {
+	fsw *fsnotify.Watcher
+
+	mu     sync.Mutex
+	repos  []string // Clone roots, longest first so nested clones win
+	status map[string]*git.Status
+}
+
+// New watches the clones at repoPaths and checks the status of each once.
+// Paths that are not clones are skipped.
+func New(repoPaths []string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{fsw: fsw, status: make(map[string]*git.Status)}
+	for _, path := range repoPaths {
+		if err := w.Add(path); err != nil {
+			verbosity.Debug("Not watching %s: %v", path, err)
+		}
+	}
+	return w, nil
+}
+
+// Add starts watching the clone at path, if it is not watched yet
+func (w *Watcher) Add(path string) error {
+	path = filepath.Clean(path)
+	w.mu.Lock()
+	_, watched := w.status[path]
+	w.mu.Unlock()
+	if watched {
+		return nil
+	}
+
+	status, err := git.GetRepositoryStatus(path)
+	if err != nil {
+		return err
+	}
+	if !status.Exists || !status.IsGitRepo {
+		return os.ErrNotExist
+	}
+	if err := w.addTree(path); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status[path] = status
+	w.repos = append(w.repos, path)
+	// Longest first, so the innermost clone owns a changed file
+	for i := len(w.repos) - 1; i > 0 && len(w.repos[i]) > len(w.repos[i-1]); i-- {
+		w.repos[i], w.repos[i-1] = w.repos[i-1], w.repos[i]
+	}
+	return nil
+}
+
+// addTree watches dir and the directories below it. Inside .git only the
+// directory itself is watched, where HEAD and the index change.
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if err := w.fsw.Add(path); err != nil {
+			return err
+		}
+		if entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// Status returns the cached status of the clone at path, if it is watched
+func (w *Watcher) Status(path string) (*git.Status, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status, ok := w.status[filepath.Clean(path)]
+	return status, ok
+}
+
+// Statuses returns the cached status of every watched clone, by path
+func (w *Watcher) Statuses() map[string]*git.Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	statuses := make(map[string]*git.Status, len(w.status))
+	for path, status := range w.status {
+		statuses[path] = status
+	}
+	return statuses
+}
+
+// Dirty reports whether the clone at path has uncommitted changes, and
+// whether it is watched at all
+func (w *Watcher) Dirty(path string) (dirty, known bool) {
+	status, ok := w.Status(path)
+	if !ok {
+		return false, false
+	}
+	return status.HasChanges, true
+}
+
+// Run handles file events until ctx is done, checking the status of changed
+// clones again once they are quiet and sending each result to changes,
+// which may be nil
+func (w *Watcher) Run(ctx context.Context, changes chan<- Change) {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			repo := w.repoFor(event.Name)
+			if repo == "" || ignored(repo, event.Name) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && filepath.Base(event.Name) != ".git" {
+					_ = w.addTree(event.Name)
+				}
+			}
+			pending[repo] = true
+			timer.Reset(Debounce)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			verbosity.Debug("File watcher error: %v", err)
+		case <-timer.C:
+			for repo := range pending {
+				change := w.check(repo)
+				if changes != nil {
+					select {
+					case changes <- change:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// check gets the status of the clone at repo again and caches it
+func (w *Watcher) check(repo string) Change {
+	status, err := git.GetRepositoryStatus(repo)
+	if err == nil {
+		w.mu.Lock()
+		w.status[repo] = status
+		w.mu.Unlock()
+	}
+	return Change{Path: repo, Status: status, Err: err}
+}
+
+// repoFor returns the watched clone containing path
+func (w *Watcher) repoFor(path string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, repo := range w.repos {
+		if path == repo || strings.HasPrefix(path, repo+string(filepath.Separator)) {
+			return repo
+		}
+	}
+	return ""
+}
+
+// ignored reports whether a change to path cannot change the status of the
+// clone at repo, like git's own lock files
+func ignored(repo, path string) bool {
+	return filepath.Dir(path) == filepath.Join(repo, ".git") && strings.HasSuffix(path, ".lock")
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fsw.Close()
+}
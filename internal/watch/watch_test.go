@@ -0,0 +1,58 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_MarksDirtyRepositories(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available in PATH")
+	}
+	Debounce = 10 * time.Millisecond
+	t.Cleanup(func() { Debounce = 250 * time.Millisecond })
+
+	repo := filepath.Join(t.TempDir(), "api")
+	if out, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New([]string{repo, filepath.Join(t.TempDir(), "missing")})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer w.Close()
+	if dirty, known := w.Dirty(repo); !known || dirty {
+		t.Fatalf("Expected a clean watched clone, got dirty=%t known=%t", dirty, known)
+	}
+	if len(w.Statuses()) != 1 {
+		t.Errorf("Expected only the clone to be watched, got %v", w.Statuses())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan Change)
+	go w.Run(ctx, changes)
+
+	if err := os.WriteFile(filepath.Join(repo, "src", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case change := <-changes:
+		if change.Path != repo || change.Err != nil || !change.Status.HasChanges {
+			t.Errorf("Unexpected change %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the change to be noticed")
+	}
+	if dirty, _ := w.Dirty(repo); !dirty {
+		t.Error("Expected the cached status to be dirty")
+	}
+}